@@ -17,15 +17,31 @@ type Config struct {
 
 // App config struct
 type App struct {
-	HTTPPort        int64         `yaml:"http_port" env:"APP_HTTP_PORT" env-required:"true"`
-	GRPCPort        int64         `yaml:"grpc_port" env:"APP_GRPC_PORT" env-required:"true"`
-	Env             string        `yaml:"env" env:"APP_ENV" env-required:"true"`
-	IdleTimeout     time.Duration `yaml:"idle_timeout" env:"APP_IDLE_TIMEOUT" env-required:"true"`
-	ReadTimeout     time.Duration `yaml:"read_timeout" env:"APP_READ_TIMEOUT" env-required:"true"`
-	WriteTimeout    time.Duration `yaml:"write_timeout" env:"APP_WRITE_TIMEOUT" env-required:"true"`
-	ShutdownTimeout time.Duration `yaml:"shutdown_timeout" env:"APP_SHUTDOWN_TIMEOUT" env-required:"true"`
-	JWTTokenTTL     time.Duration `yaml:"jwt_token_ttl" env:"JWT_TOKEN_TTL" env-required:"true"`
-	JWTSecretKey    string        `env:"JWT_SECRET_KEY" env-required:"true"`
+	HTTPPort              int64         `yaml:"http_port" env:"APP_HTTP_PORT" env-required:"true"`
+	GRPCPort              int64         `yaml:"grpc_port" env:"APP_GRPC_PORT" env-required:"true"`
+	Env                   string        `yaml:"env" env:"APP_ENV" env-required:"true"`
+	IdleTimeout           time.Duration `yaml:"idle_timeout" env:"APP_IDLE_TIMEOUT" env-required:"true"`
+	ReadTimeout           time.Duration `yaml:"read_timeout" env:"APP_READ_TIMEOUT" env-required:"true"`
+	WriteTimeout          time.Duration `yaml:"write_timeout" env:"APP_WRITE_TIMEOUT" env-required:"true"`
+	ShutdownTimeout       time.Duration `yaml:"shutdown_timeout" env:"APP_SHUTDOWN_TIMEOUT" env-required:"true"`
+	JWTTokenTTL           time.Duration `yaml:"jwt_token_ttl" env:"JWT_TOKEN_TTL" env-required:"true"`
+	RefreshTokenTTL       time.Duration `yaml:"refresh_token_ttl" env:"REFRESH_TOKEN_TTL" env-required:"true"`
+	CacheTTL              time.Duration `yaml:"cache_ttl" env:"APP_CACHE_TTL" env-required:"true"`
+	JWTSecretKey          string        `env:"JWT_SECRET_KEY"`
+	JWTKeysDir            string        `yaml:"jwt_keys_dir" env:"JWT_KEYS_DIR"`
+	JWTSigningKeyID       string        `yaml:"jwt_signing_key_id" env:"JWT_SIGNING_KEY_ID"`
+	JWTHMACFallback       bool          `yaml:"jwt_hmac_fallback" env:"JWT_HMAC_FALLBACK"`
+	ReceptionIdleTimeout  time.Duration `yaml:"reception_idle_timeout" env:"APP_RECEPTION_IDLE_TIMEOUT" env-required:"true"`
+	StaleCheckInterval    time.Duration `yaml:"stale_check_interval" env:"APP_STALE_CHECK_INTERVAL" env-required:"true"`
+	PickupMaxAttempts     int           `yaml:"pickup_max_attempts" env:"APP_PICKUP_MAX_ATTEMPTS" env-required:"true"`
+	PickupLockout         time.Duration `yaml:"pickup_lockout" env:"APP_PICKUP_LOCKOUT" env-required:"true"`
+	LoginMaxAttempts      int           `yaml:"login_max_attempts" env:"APP_LOGIN_MAX_ATTEMPTS" env-required:"true"`
+	LoginMaxAttemptsPerIP int           `yaml:"login_max_attempts_per_ip" env:"APP_LOGIN_MAX_ATTEMPTS_PER_IP" env-required:"true"`
+	LoginLockout          time.Duration `yaml:"login_lockout" env:"APP_LOGIN_LOCKOUT" env-required:"true"`
+	LoginMaxLockout       time.Duration `yaml:"login_max_lockout" env:"APP_LOGIN_MAX_LOCKOUT" env-required:"true"`
+	LoginFailureWindow    time.Duration `yaml:"login_failure_window" env:"APP_LOGIN_FAILURE_WINDOW" env-required:"true"`
+	UtilizationInterval   time.Duration `yaml:"utilization_interval" env:"APP_UTILIZATION_INTERVAL" env-required:"true"`
+	TokenCleanupInterval  time.Duration `yaml:"token_cleanup_interval" env:"APP_TOKEN_CLEANUP_INTERVAL" env-required:"true"`
 }
 
 // PostgreSQL config struct
@@ -17,28 +17,51 @@ const (
 	BearerAuthScopes = "bearerAuth.Scopes"
 )
 
-// Defines values for PVZCity.
+// Defines values for DiscrepancyKind.
 const (
-	Казань         PVZCity = "Казань"
-	Москва         PVZCity = "Москва"
-	СанктПетербург PVZCity = "Санкт-Петербург"
+	Extra          DiscrepancyKind = "extra"
+	MismatchedType DiscrepancyKind = "mismatched_type"
+	Missing        DiscrepancyKind = "missing"
+	Unexpected     DiscrepancyKind = "unexpected"
 )
 
-// Defines values for ProductType.
+// Defines values for PVZStatus.
 const (
-	ProductTypeОбувь       ProductType = "обувь"
-	ProductTypeОдежда      ProductType = "одежда"
-	ProductTypeЭлектроника ProductType = "электроника"
+	Active    PVZStatus = "active"
+	Closed    PVZStatus = "closed"
+	Suspended PVZStatus = "suspended"
+)
+
+// Defines values for ProductStatus.
+const (
+	Issued      ProductStatus = "issued"
+	Received    ProductStatus = "received"
+	Returned    ProductStatus = "returned"
+	Stored      ProductStatus = "stored"
+	Transferred ProductStatus = "transferred"
+)
+
+// Defines values for ProductTransferStatus.
+const (
+	Delivered ProductTransferStatus = "delivered"
+	InTransit ProductTransferStatus = "in_transit"
+)
+
+// Defines values for ReceptionAuditRecordAction.
+const (
+	Reopen ReceptionAuditRecordAction = "reopen"
 )
 
 // Defines values for ReceptionStatus.
 const (
+	Cancelled  ReceptionStatus = "cancelled"
 	Close      ReceptionStatus = "close"
 	InProgress ReceptionStatus = "in_progress"
 )
 
 // Defines values for UserRole.
 const (
+	UserRoleAdmin     UserRole = "admin"
 	UserRoleEmployee  UserRole = "employee"
 	UserRoleModerator UserRole = "moderator"
 )
@@ -49,69 +72,328 @@ const (
 	PostDummyLoginJSONBodyRoleModerator PostDummyLoginJSONBodyRole = "moderator"
 )
 
-// Defines values for PostProductsJSONBodyType.
-const (
-	PostProductsJSONBodyTypeОбувь       PostProductsJSONBodyType = "обувь"
-	PostProductsJSONBodyTypeОдежда      PostProductsJSONBodyType = "одежда"
-	PostProductsJSONBodyTypeЭлектроника PostProductsJSONBodyType = "электроника"
-)
-
 // Defines values for PostRegisterJSONBodyRole.
 const (
 	Employee  PostRegisterJSONBodyRole = "employee"
 	Moderator PostRegisterJSONBodyRole = "moderator"
 )
 
+// Defines values for GetUsersParamsRole.
+const (
+	GetUsersParamsRoleAdmin     GetUsersParamsRole = "admin"
+	GetUsersParamsRoleEmployee  GetUsersParamsRole = "employee"
+	GetUsersParamsRoleModerator GetUsersParamsRole = "moderator"
+)
+
+// Defines values for PatchUsersUserIdJSONBodyRole.
+const (
+	PatchUsersUserIdJSONBodyRoleAdmin     PatchUsersUserIdJSONBodyRole = "admin"
+	PatchUsersUserIdJSONBodyRoleEmployee  PatchUsersUserIdJSONBodyRole = "employee"
+	PatchUsersUserIdJSONBodyRoleModerator PatchUsersUserIdJSONBodyRole = "moderator"
+)
+
+// BarcodeDiscrepancy defines model for BarcodeDiscrepancy.
+type BarcodeDiscrepancy struct {
+	// ActualType Тип принятого товара, нет для непринятого штрихкода
+	ActualType *string `json:"actualType,omitempty"`
+	Barcode    string  `json:"barcode"`
+
+	// ExpectedType Тип товара по манифесту, нет для непредусмотренного штрихкода
+	ExpectedType *string         `json:"expectedType,omitempty"`
+	Kind         DiscrepancyKind `json:"kind"`
+}
+
+// CellOccupancy defines model for CellOccupancy.
+type CellOccupancy struct {
+	// Capacity Суммарная вместимость ячеек ПВЗ
+	Capacity int           `json:"capacity"`
+	Cells    []StorageCell `json:"cells"`
+	Occupied int           `json:"occupied"`
+
+	// Unassigned Товары ПВЗ, не размещенные ни в одной ячейке
+	Unassigned int `json:"unassigned"`
+}
+
+// City defines model for City.
+type City struct {
+	CreatedAt *time.Time          `json:"createdAt,omitempty"`
+	Id        *openapi_types.UUID `json:"id,omitempty"`
+	Name      string              `json:"name"`
+}
+
+// Discrepancy defines model for Discrepancy.
+type Discrepancy struct {
+	Actual   int             `json:"actual"`
+	Expected int             `json:"expected"`
+	Kind     DiscrepancyKind `json:"kind"`
+	Type     string          `json:"type"`
+}
+
+// DiscrepancyKind defines model for DiscrepancyKind.
+type DiscrepancyKind string
+
+// EmployeeAssignment defines model for EmployeeAssignment.
+type EmployeeAssignment struct {
+	AssignedAt *time.Time         `json:"assignedAt,omitempty"`
+	PvzId      openapi_types.UUID `json:"pvzId"`
+	UserId     openapi_types.UUID `json:"userId"`
+}
+
 // Error defines model for Error.
 type Error struct {
 	Message string `json:"message"`
 }
 
+// JWK defines model for JWK.
+type JWK struct {
+	// Alg Алгоритм подписи (RS256 или EdDSA)
+	Alg string  `json:"alg"`
+	Crv *string `json:"crv,omitempty"`
+	E   *string `json:"e,omitempty"`
+	Kid string  `json:"kid"`
+
+	// Kty Тип ключа (RSA или OKP)
+	Kty string  `json:"kty"`
+	N   *string `json:"n,omitempty"`
+	Use string  `json:"use"`
+	X   *string `json:"x,omitempty"`
+}
+
+// JWKS defines model for JWKS.
+type JWKS struct {
+	Keys []JWK `json:"keys"`
+}
+
+// ManifestBarcode defines model for ManifestBarcode.
+type ManifestBarcode struct {
+	Barcode string `json:"barcode"`
+	Type    string `json:"type"`
+}
+
+// ManifestItem defines model for ManifestItem.
+type ManifestItem struct {
+	Count int    `json:"count"`
+	Type  string `json:"type"`
+}
+
 // PVZ defines model for PVZ.
 type PVZ struct {
-	City             PVZCity             `json:"city"`
+	City             string              `json:"city"`
 	Id               *openapi_types.UUID `json:"id,omitempty"`
 	RegistrationDate *time.Time          `json:"registrationDate,omitempty"`
+	Status           *PVZStatus          `json:"status,omitempty"`
+}
+
+// PVZCapacity defines model for PVZCapacity.
+type PVZCapacity struct {
+	// Capacity Максимальное количество товаров в ПВЗ, если не задано — без ограничения
+	Capacity *int               `json:"capacity,omitempty"`
+	PvzId    openapi_types.UUID `json:"pvzId"`
+
+	// ReceptionLimit Максимальное количество товаров в одной приемке, если не задано — без ограничения
+	ReceptionLimit *int `json:"receptionLimit,omitempty"`
+
+	// Stored Количество принятых и хранящихся в ПВЗ товаров
+	Stored int `json:"stored"`
+}
+
+// PVZStatus defines model for PVZStatus.
+type PVZStatus string
+
+// PermissionGrant defines model for PermissionGrant.
+type PermissionGrant struct {
+	GrantedAt time.Time `json:"grantedAt"`
+
+	// GrantedBy Администратор, выдавший право
+	GrantedBy  *openapi_types.UUID `json:"grantedBy,omitempty"`
+	Permission string              `json:"permission"`
+	UserId     openapi_types.UUID  `json:"userId"`
 }
 
-// PVZCity defines model for PVZ.City.
-type PVZCity string
+// PickupCode defines model for PickupCode.
+type PickupCode struct {
+	// Code Одноразовый код получения, показывается только один раз
+	Code string `json:"code"`
+}
 
 // Product defines model for Product.
 type Product struct {
-	DateTime    *time.Time          `json:"dateTime,omitempty"`
-	Id          *openapi_types.UUID `json:"id,omitempty"`
-	ReceptionId openapi_types.UUID  `json:"receptionId"`
-	Type        ProductType         `json:"type"`
+	// Barcode Штрихкод товара, уникален в рамках приемки
+	Barcode *string `json:"barcode,omitempty"`
+
+	// CellNumber Номер ячейки хранения, в которую помещен товар
+	CellNumber *int `json:"cellNumber,omitempty"`
+
+	// CreatedBy Сотрудник, добавивший товар
+	CreatedBy *openapi_types.UUID `json:"createdBy,omitempty"`
+	DateTime  *time.Time          `json:"dateTime,omitempty"`
+	Id        *openapi_types.UUID `json:"id,omitempty"`
+
+	// LineNumber Порядковый номер товара в приемке
+	LineNumber  *int               `json:"lineNumber,omitempty"`
+	ReceptionId openapi_types.UUID `json:"receptionId"`
+	Status      *ProductStatus     `json:"status,omitempty"`
+	Type        string             `json:"type"`
+}
+
+// ProductBatchItem defines model for ProductBatchItem.
+type ProductBatchItem struct {
+	Barcode    *string `json:"barcode,omitempty"`
+	CellNumber *int    `json:"cellNumber,omitempty"`
+	Type       string  `json:"type"`
+}
+
+// ProductBatchResult Результат добавления одного товара пакета, порядок совпадает с порядком в запросе
+type ProductBatchResult struct {
+	// Error Причина, по которой товар не был добавлен
+	Error   *string  `json:"error,omitempty"`
+	Product *Product `json:"product,omitempty"`
+}
+
+// ProductLocation defines model for ProductLocation.
+type ProductLocation struct {
+	Product   Product   `json:"product"`
+	Pvz       PVZ       `json:"pvz"`
+	Reception Reception `json:"reception"`
+}
+
+// ProductPage defines model for ProductPage.
+type ProductPage struct {
+	// NextCursor Курсор следующей страницы, если товаров больше нет — не передается
+	NextCursor *int      `json:"nextCursor,omitempty"`
+	Products   []Product `json:"products"`
+}
+
+// ProductStatus defines model for ProductStatus.
+type ProductStatus string
+
+// ProductTransfer defines model for ProductTransfer.
+type ProductTransfer struct {
+	Barcode   *string    `json:"barcode,omitempty"`
+	CreatedAt *time.Time `json:"createdAt,omitempty"`
+
+	// CreatedBy Сотрудник, отправивший товар
+	CreatedBy *openapi_types.UUID `json:"createdBy,omitempty"`
+
+	// DeliveredProductId Товар, которым перемещение подтверждено в ПВЗ назначения
+	DeliveredProductId *openapi_types.UUID `json:"deliveredProductId,omitempty"`
+	FromPvzId          openapi_types.UUID  `json:"fromPvzId"`
+	Id                 *openapi_types.UUID `json:"id,omitempty"`
+
+	// ProductId Товар, отправленный из ПВЗ
+	ProductId openapi_types.UUID `json:"productId"`
+
+	// ReceptionId Приемка ПВЗ назначения, в поставку которой включен товар
+	ReceptionId *openapi_types.UUID   `json:"receptionId,omitempty"`
+	Status      ProductTransferStatus `json:"status"`
+	ToPvzId     openapi_types.UUID    `json:"toPvzId"`
+	Type        string                `json:"type"`
 }
 
-// ProductType defines model for Product.Type.
-type ProductType string
+// ProductTransferStatus defines model for ProductTransfer.Status.
+type ProductTransferStatus string
+
+// ProductType defines model for ProductType.
+type ProductType struct {
+	DisplayName string              `json:"displayName"`
+	Id          *openapi_types.UUID `json:"id,omitempty"`
+	Name        string              `json:"name"`
+	RetiredAt   *time.Time          `json:"retiredAt,omitempty"`
+}
 
 // Reception defines model for Reception.
 type Reception struct {
-	DateTime time.Time           `json:"dateTime"`
-	Id       *openapi_types.UUID `json:"id,omitempty"`
-	PvzId    openapi_types.UUID  `json:"pvzId"`
-	Status   ReceptionStatus     `json:"status"`
+	// AutoClosed Приемка закрыта автоматически из-за простоя
+	AutoClosed *bool `json:"autoClosed,omitempty"`
+
+	// BarcodeDiscrepancies Расхождения по штрихкодам, рассчитанные при закрытии приемки
+	BarcodeDiscrepancies *[]BarcodeDiscrepancy `json:"barcodeDiscrepancies,omitempty"`
+
+	// ClosedBy Сотрудник, закрывший приемку
+	ClosedBy *openapi_types.UUID `json:"closedBy,omitempty"`
+
+	// CreatedBy Сотрудник, открывший приемку
+	CreatedBy *openapi_types.UUID `json:"createdBy,omitempty"`
+	DateTime  time.Time           `json:"dateTime"`
+
+	// Discrepancies Расхождения с ожидаемой поставкой, рассчитанные при закрытии приемки
+	Discrepancies *[]Discrepancy `json:"discrepancies,omitempty"`
+
+	// ExpectedBarcodes Ожидаемые товары по штрихкодам, переданные при открытии приемки
+	ExpectedBarcodes *[]ManifestBarcode  `json:"expectedBarcodes,omitempty"`
+	Id               *openapi_types.UUID `json:"id,omitempty"`
+
+	// Manifest Ожидаемая поставка, переданная при открытии приемки
+	Manifest *[]ManifestItem    `json:"manifest,omitempty"`
+	PvzId    openapi_types.UUID `json:"pvzId"`
+	Status   ReceptionStatus    `json:"status"`
+}
+
+// ReceptionAuditRecord defines model for ReceptionAuditRecord.
+type ReceptionAuditRecord struct {
+	Action      ReceptionAuditRecordAction `json:"action"`
+	CreatedAt   *time.Time                 `json:"createdAt,omitempty"`
+	Id          *openapi_types.UUID        `json:"id,omitempty"`
+	Reason      string                     `json:"reason"`
+	ReceptionId openapi_types.UUID         `json:"receptionId"`
+	UserId      *openapi_types.UUID        `json:"userId,omitempty"`
 }
 
+// ReceptionAuditRecordAction defines model for ReceptionAuditRecord.Action.
+type ReceptionAuditRecordAction string
+
 // ReceptionStatus defines model for Reception.Status.
 type ReceptionStatus string
 
+// StorageCell defines model for StorageCell.
+type StorageCell struct {
+	Capacity  int                 `json:"capacity"`
+	CreatedAt *time.Time          `json:"createdAt,omitempty"`
+	Id        *openapi_types.UUID `json:"id,omitempty"`
+	Number    int                 `json:"number"`
+
+	// Occupied Количество принятых и хранящихся в ячейке товаров
+	Occupied int                `json:"occupied"`
+	PvzId    openapi_types.UUID `json:"pvzId"`
+}
+
 // Token defines model for Token.
 type Token = string
 
+// TokenPair defines model for TokenPair.
+type TokenPair struct {
+	// RefreshToken Одноразовый токен для получения новой пары токенов
+	RefreshToken string `json:"refreshToken"`
+	Token        Token  `json:"token"`
+}
+
 // User defines model for User.
 type User struct {
-	Email openapi_types.Email `json:"email"`
-	Id    *openapi_types.UUID `json:"id,omitempty"`
-	Role  UserRole            `json:"role"`
+	CreatedAt *time.Time `json:"createdAt,omitempty"`
+
+	// DeactivatedAt Время деактивации, у активных пользователей отсутствует
+	DeactivatedAt *time.Time          `json:"deactivatedAt,omitempty"`
+	Email         openapi_types.Email `json:"email"`
+	Id            *openapi_types.UUID `json:"id,omitempty"`
+
+	// LastLoginAt Время последнего успешного входа
+	LastLoginAt *time.Time `json:"lastLoginAt,omitempty"`
+	Role        UserRole   `json:"role"`
 }
 
 // UserRole defines model for User.Role.
 type UserRole string
 
+// PostCitiesJSONBody defines parameters for PostCities.
+type PostCitiesJSONBody struct {
+	Name string `json:"name"`
+}
+
+// PatchCitiesCityIdJSONBody defines parameters for PatchCitiesCityId.
+type PatchCitiesCityIdJSONBody struct {
+	Name string `json:"name"`
+}
+
 // PostDummyLoginJSONBody defines parameters for PostDummyLogin.
 type PostDummyLoginJSONBody struct {
 	Role PostDummyLoginJSONBodyRole `json:"role"`
@@ -126,14 +408,48 @@ type PostLoginJSONBody struct {
 	Password string              `json:"password"`
 }
 
+// PostLogoutJSONBody defines parameters for PostLogout.
+type PostLogoutJSONBody struct {
+	RefreshToken *string `json:"refreshToken,omitempty"`
+}
+
+// PostProductTypesJSONBody defines parameters for PostProductTypes.
+type PostProductTypesJSONBody struct {
+	DisplayName string `json:"displayName"`
+	Name        string `json:"name"`
+}
+
+// GetProductsParams defines parameters for GetProducts.
+type GetProductsParams struct {
+	// Barcode Штрихкод товара
+	Barcode string `form:"barcode" json:"barcode"`
+}
+
 // PostProductsJSONBody defines parameters for PostProducts.
 type PostProductsJSONBody struct {
-	PvzId openapi_types.UUID       `json:"pvzId"`
-	Type  PostProductsJSONBodyType `json:"type"`
+	Barcode *string `json:"barcode,omitempty"`
+
+	// CellNumber Номер ячейки; если не указан, выбирается первая ячейка со свободным местом
+	CellNumber *int               `json:"cellNumber,omitempty"`
+	PvzId      openapi_types.UUID `json:"pvzId"`
+	Type       string             `json:"type"`
+}
+
+// PostProductsBatchJSONBody defines parameters for PostProductsBatch.
+type PostProductsBatchJSONBody struct {
+	Items []ProductBatchItem `json:"items"`
+	PvzId openapi_types.UUID `json:"pvzId"`
+}
+
+// PostProductsProductIdMoveJSONBody defines parameters for PostProductsProductIdMove.
+type PostProductsProductIdMoveJSONBody struct {
+	CellNumber int `json:"cellNumber"`
 }
 
-// PostProductsJSONBodyType defines parameters for PostProducts.
-type PostProductsJSONBodyType string
+// PostProductsProductIdPickupJSONBody defines parameters for PostProductsProductIdPickup.
+type PostProductsProductIdPickupJSONBody struct {
+	Code string `json:"code"`
+}
 
 // GetPvzParams defines parameters for GetPvz.
 type GetPvzParams struct {
@@ -148,11 +464,71 @@ type GetPvzParams struct {
 
 	// Limit Количество элементов на странице
 	Limit *int `form:"limit,omitempty" json:"limit,omitempty"`
+
+	// ExcludeCancelled Не возвращать отмененные приемки
+	ExcludeCancelled *bool `form:"excludeCancelled,omitempty" json:"excludeCancelled,omitempty"`
+
+	// ProductStatus Возвращать только товары в указанном статусе, приемки и ПВЗ без таких товаров не возвращаются
+	ProductStatus *ProductStatus `form:"productStatus,omitempty" json:"productStatus,omitempty"`
+
+	// ProductCounts Возвращать для приемок только количество товаров вместо их списка
+	ProductCounts *bool `form:"productCounts,omitempty" json:"productCounts,omitempty"`
+}
+
+// PatchPvzPvzIdJSONBody defines parameters for PatchPvzPvzId.
+type PatchPvzPvzIdJSONBody struct {
+	City   *string    `json:"city,omitempty"`
+	Status *PVZStatus `json:"status,omitempty"`
+}
+
+// PutPvzPvzIdCapacityJSONBody defines parameters for PutPvzPvzIdCapacity.
+type PutPvzPvzIdCapacityJSONBody struct {
+	Capacity       *int `json:"capacity,omitempty"`
+	ReceptionLimit *int `json:"receptionLimit,omitempty"`
+}
+
+// PostPvzPvzIdCellsJSONBody defines parameters for PostPvzPvzIdCells.
+type PostPvzPvzIdCellsJSONBody struct {
+	// Capacity Вместимость каждой ячейки
+	Capacity int `json:"capacity"`
+
+	// Count Количество новых ячеек, нумерация продолжает существующие
+	Count int `json:"count"`
+}
+
+// PostPvzPvzIdEmployeesJSONBody defines parameters for PostPvzPvzIdEmployees.
+type PostPvzPvzIdEmployeesJSONBody struct {
+	UserId openapi_types.UUID `json:"userId"`
 }
 
 // PostReceptionsJSONBody defines parameters for PostReceptions.
 type PostReceptionsJSONBody struct {
-	PvzId openapi_types.UUID `json:"pvzId"`
+	ExpectedBarcodes *[]ManifestBarcode `json:"expectedBarcodes,omitempty"`
+	Manifest         *[]ManifestItem    `json:"manifest,omitempty"`
+	PvzId            openapi_types.UUID `json:"pvzId"`
+}
+
+// GetReceptionsReceptionIdProductsParams defines parameters for GetReceptionsReceptionIdProducts.
+type GetReceptionsReceptionIdProductsParams struct {
+	// Type Возвращать только товары указанного типа
+	Type *string `form:"type,omitempty" json:"type,omitempty"`
+
+	// Cursor Порядковый номер товара (lineNumber), после которого начинается страница
+	Cursor *int `form:"cursor,omitempty" json:"cursor,omitempty"`
+
+	// Limit Количество элементов на странице
+	Limit *int `form:"limit,omitempty" json:"limit,omitempty"`
+}
+
+// PostReceptionsReceptionIdReopenJSONBody defines parameters for PostReceptionsReceptionIdReopen.
+type PostReceptionsReceptionIdReopenJSONBody struct {
+	Reason string `json:"reason"`
+}
+
+// PostReceptionsReceptionIdTransfersJSONBody defines parameters for PostReceptionsReceptionIdTransfers.
+type PostReceptionsReceptionIdTransfersJSONBody struct {
+	ProductIds []openapi_types.UUID `json:"productIds"`
+	ToPvzId    openapi_types.UUID   `json:"toPvzId"`
 }
 
 // PostRegisterJSONBody defines parameters for PostRegister.
@@ -165,53 +541,252 @@ type PostRegisterJSONBody struct {
 // PostRegisterJSONBodyRole defines parameters for PostRegister.
 type PostRegisterJSONBodyRole string
 
+// PostTokenRefreshJSONBody defines parameters for PostTokenRefresh.
+type PostTokenRefreshJSONBody struct {
+	RefreshToken string `json:"refreshToken"`
+}
+
+// GetUsersParams defines parameters for GetUsers.
+type GetUsersParams struct {
+	// Search Подстрока email
+	Search *string `form:"search,omitempty" json:"search,omitempty"`
+
+	// Role Роль пользователей
+	Role *GetUsersParamsRole `form:"role,omitempty" json:"role,omitempty"`
+
+	// Page Номер страницы
+	Page *int `form:"page,omitempty" json:"page,omitempty"`
+
+	// Limit Количество элементов на странице
+	Limit *int `form:"limit,omitempty" json:"limit,omitempty"`
+}
+
+// GetUsersParamsRole defines parameters for GetUsers.
+type GetUsersParamsRole string
+
+// PatchUsersUserIdJSONBody defines parameters for PatchUsersUserId.
+type PatchUsersUserIdJSONBody struct {
+	Role PatchUsersUserIdJSONBodyRole `json:"role"`
+}
+
+// PatchUsersUserIdJSONBodyRole defines parameters for PatchUsersUserId.
+type PatchUsersUserIdJSONBodyRole string
+
+// PostCitiesJSONRequestBody defines body for PostCities for application/json ContentType.
+type PostCitiesJSONRequestBody PostCitiesJSONBody
+
+// PatchCitiesCityIdJSONRequestBody defines body for PatchCitiesCityId for application/json ContentType.
+type PatchCitiesCityIdJSONRequestBody PatchCitiesCityIdJSONBody
+
 // PostDummyLoginJSONRequestBody defines body for PostDummyLogin for application/json ContentType.
 type PostDummyLoginJSONRequestBody PostDummyLoginJSONBody
 
 // PostLoginJSONRequestBody defines body for PostLogin for application/json ContentType.
 type PostLoginJSONRequestBody PostLoginJSONBody
 
+// PostLogoutJSONRequestBody defines body for PostLogout for application/json ContentType.
+type PostLogoutJSONRequestBody PostLogoutJSONBody
+
+// PostProductTypesJSONRequestBody defines body for PostProductTypes for application/json ContentType.
+type PostProductTypesJSONRequestBody PostProductTypesJSONBody
+
 // PostProductsJSONRequestBody defines body for PostProducts for application/json ContentType.
 type PostProductsJSONRequestBody PostProductsJSONBody
 
+// PostProductsBatchJSONRequestBody defines body for PostProductsBatch for application/json ContentType.
+type PostProductsBatchJSONRequestBody PostProductsBatchJSONBody
+
+// PostProductsProductIdMoveJSONRequestBody defines body for PostProductsProductIdMove for application/json ContentType.
+type PostProductsProductIdMoveJSONRequestBody PostProductsProductIdMoveJSONBody
+
+// PostProductsProductIdPickupJSONRequestBody defines body for PostProductsProductIdPickup for application/json ContentType.
+type PostProductsProductIdPickupJSONRequestBody PostProductsProductIdPickupJSONBody
+
 // PostPvzJSONRequestBody defines body for PostPvz for application/json ContentType.
 type PostPvzJSONRequestBody = PVZ
 
+// PatchPvzPvzIdJSONRequestBody defines body for PatchPvzPvzId for application/json ContentType.
+type PatchPvzPvzIdJSONRequestBody PatchPvzPvzIdJSONBody
+
+// PutPvzPvzIdCapacityJSONRequestBody defines body for PutPvzPvzIdCapacity for application/json ContentType.
+type PutPvzPvzIdCapacityJSONRequestBody PutPvzPvzIdCapacityJSONBody
+
+// PostPvzPvzIdCellsJSONRequestBody defines body for PostPvzPvzIdCells for application/json ContentType.
+type PostPvzPvzIdCellsJSONRequestBody PostPvzPvzIdCellsJSONBody
+
+// PostPvzPvzIdEmployeesJSONRequestBody defines body for PostPvzPvzIdEmployees for application/json ContentType.
+type PostPvzPvzIdEmployeesJSONRequestBody PostPvzPvzIdEmployeesJSONBody
+
 // PostReceptionsJSONRequestBody defines body for PostReceptions for application/json ContentType.
 type PostReceptionsJSONRequestBody PostReceptionsJSONBody
 
+// PostReceptionsReceptionIdReopenJSONRequestBody defines body for PostReceptionsReceptionIdReopen for application/json ContentType.
+type PostReceptionsReceptionIdReopenJSONRequestBody PostReceptionsReceptionIdReopenJSONBody
+
+// PostReceptionsReceptionIdTransfersJSONRequestBody defines body for PostReceptionsReceptionIdTransfers for application/json ContentType.
+type PostReceptionsReceptionIdTransfersJSONRequestBody PostReceptionsReceptionIdTransfersJSONBody
+
 // PostRegisterJSONRequestBody defines body for PostRegister for application/json ContentType.
 type PostRegisterJSONRequestBody PostRegisterJSONBody
 
+// PostTokenRefreshJSONRequestBody defines body for PostTokenRefresh for application/json ContentType.
+type PostTokenRefreshJSONRequestBody PostTokenRefreshJSONBody
+
+// PatchUsersUserIdJSONRequestBody defines body for PatchUsersUserId for application/json ContentType.
+type PatchUsersUserIdJSONRequestBody PatchUsersUserIdJSONBody
+
 // ServerInterface represents all server handlers.
 type ServerInterface interface {
+	// Публичные ключи для проверки подписи токенов
+	// (GET /.well-known/jwks.json)
+	GetWellKnownJwksJson(ctx echo.Context) error
+	// Получение списка городов, в которых можно открыть ПВЗ
+	// (GET /cities)
+	GetCities(ctx echo.Context) error
+	// Добавление города (только для модераторов)
+	// (POST /cities)
+	PostCities(ctx echo.Context) error
+	// Удаление города, в котором нет ПВЗ (только для модераторов)
+	// (DELETE /cities/{cityId})
+	DeleteCitiesCityId(ctx echo.Context, cityId openapi_types.UUID) error
+	// Переименование города (только для модераторов)
+	// (PATCH /cities/{cityId})
+	PatchCitiesCityId(ctx echo.Context, cityId openapi_types.UUID) error
 	// Получение тестового токена
 	// (POST /dummyLogin)
 	PostDummyLogin(ctx echo.Context) error
 	// Авторизация пользователя
 	// (POST /login)
 	PostLogin(ctx echo.Context) error
+	// Выход с отзывом текущего токена доступа и токена обновления
+	// (POST /logout)
+	PostLogout(ctx echo.Context) error
+	// Получение каталога типов товаров
+	// (GET /product_types)
+	GetProductTypes(ctx echo.Context) error
+	// Добавление типа товара в каталог (только для модераторов)
+	// (POST /product_types)
+	PostProductTypes(ctx echo.Context) error
+	// Вывод типа товара из оборота (только для модераторов)
+	// (DELETE /product_types/{typeId})
+	DeleteProductTypesTypeId(ctx echo.Context, typeId openapi_types.UUID) error
+	// Поиск товара по штрихкоду во всех ПВЗ
+	// (GET /products)
+	GetProducts(ctx echo.Context, params GetProductsParams) error
 	// Добавление товара в текущую приемку (только для сотрудников ПВЗ)
 	// (POST /products)
 	PostProducts(ctx echo.Context) error
+	// Пакетное добавление товаров в текущую приемку (только для сотрудников ПВЗ)
+	// (POST /products/batch)
+	PostProductsBatch(ctx echo.Context) error
+	// Выдача товара получателю (только для сотрудников ПВЗ)
+	// (POST /products/{productId}/issue)
+	PostProductsProductIdIssue(ctx echo.Context, productId openapi_types.UUID) error
+	// Перемещение товара в другую ячейку хранения (только для сотрудников ПВЗ)
+	// (POST /products/{productId}/move)
+	PostProductsProductIdMove(ctx echo.Context, productId openapi_types.UUID) error
+	// Выдача товара получателю по коду получения (только для сотрудников ПВЗ)
+	// (POST /products/{productId}/pickup)
+	PostProductsProductIdPickup(ctx echo.Context, productId openapi_types.UUID) error
+	// Генерация нового кода получения товара (только для сотрудников ПВЗ)
+	// (POST /products/{productId}/pickup_code)
+	PostProductsProductIdPickupCode(ctx echo.Context, productId openapi_types.UUID) error
+	// Возврат товара отправителю (только для сотрудников ПВЗ)
+	// (POST /products/{productId}/return)
+	PostProductsProductIdReturn(ctx echo.Context, productId openapi_types.UUID) error
 	// Получение списка ПВЗ с фильтрацией по дате приемки и пагинацией
 	// (GET /pvz)
 	GetPvz(ctx echo.Context, params GetPvzParams) error
 	// Создание ПВЗ (только для модераторов)
 	// (POST /pvz)
 	PostPvz(ctx echo.Context) error
+	// Получение ПВЗ с текущей открытой приемкой и счетчиками
+	// (GET /pvz/{pvzId})
+	GetPvzPvzId(ctx echo.Context, pvzId openapi_types.UUID) error
+	// Изменение города или статуса ПВЗ (только для модераторов)
+	// (PATCH /pvz/{pvzId})
+	PatchPvzPvzId(ctx echo.Context, pvzId openapi_types.UUID) error
+	// Отмена последней открытой приемки вместе с ее товарами (только для сотрудников ПВЗ)
+	// (POST /pvz/{pvzId}/cancel_last_reception)
+	PostPvzPvzIdCancelLastReception(ctx echo.Context, pvzId openapi_types.UUID) error
+	// Ограничения вместимости ПВЗ и его текущая загрузка
+	// (GET /pvz/{pvzId}/capacity)
+	GetPvzPvzIdCapacity(ctx echo.Context, pvzId openapi_types.UUID) error
+	// Установка ограничений вместимости ПВЗ (только для модераторов)
+	// (PUT /pvz/{pvzId}/capacity)
+	PutPvzPvzIdCapacity(ctx echo.Context, pvzId openapi_types.UUID) error
+	// Заполненность ячеек хранения ПВЗ
+	// (GET /pvz/{pvzId}/cells)
+	GetPvzPvzIdCells(ctx echo.Context, pvzId openapi_types.UUID) error
+	// Добавление ячеек хранения в ПВЗ (только для модераторов)
+	// (POST /pvz/{pvzId}/cells)
+	PostPvzPvzIdCells(ctx echo.Context, pvzId openapi_types.UUID) error
 	// Закрытие последней открытой приемки товаров в рамках ПВЗ
 	// (POST /pvz/{pvzId}/close_last_reception)
 	PostPvzPvzIdCloseLastReception(ctx echo.Context, pvzId openapi_types.UUID) error
 	// Удаление последнего добавленного товара из текущей приемки (LIFO, только для сотрудников ПВЗ)
 	// (POST /pvz/{pvzId}/delete_last_product)
 	PostPvzPvzIdDeleteLastProduct(ctx echo.Context, pvzId openapi_types.UUID) error
+	// Получение списка сотрудников, закрепленных за ПВЗ (только для модераторов)
+	// (GET /pvz/{pvzId}/employees)
+	GetPvzPvzIdEmployees(ctx echo.Context, pvzId openapi_types.UUID) error
+	// Закрепление сотрудника за ПВЗ (только для модераторов)
+	// (POST /pvz/{pvzId}/employees)
+	PostPvzPvzIdEmployees(ctx echo.Context, pvzId openapi_types.UUID) error
+	// Открепление сотрудника от ПВЗ (только для модераторов)
+	// (DELETE /pvz/{pvzId}/employees/{userId})
+	DeletePvzPvzIdEmployeesUserId(ctx echo.Context, pvzId openapi_types.UUID, userId openapi_types.UUID) error
 	// Создание новой приемки товаров (только для сотрудников ПВЗ)
 	// (POST /receptions)
 	PostReceptions(ctx echo.Context) error
+	// Получение приемки с товарами
+	// (GET /receptions/{receptionId})
+	GetReceptionsReceptionId(ctx echo.Context, receptionId openapi_types.UUID) error
+	// История действий с приемкой (только для модераторов)
+	// (GET /receptions/{receptionId}/history)
+	GetReceptionsReceptionIdHistory(ctx echo.Context, receptionId openapi_types.UUID) error
+	// Постраничное получение товаров приемки в порядке добавления
+	// (GET /receptions/{receptionId}/products)
+	GetReceptionsReceptionIdProducts(ctx echo.Context, receptionId openapi_types.UUID, params GetReceptionsReceptionIdProductsParams) error
+	// Удаление конкретного товара из открытой приемки (только для сотрудников ПВЗ)
+	// (DELETE /receptions/{receptionId}/products/{productId})
+	DeleteReceptionsReceptionIdProductsProductId(ctx echo.Context, receptionId openapi_types.UUID, productId openapi_types.UUID) error
+	// Повторное открытие закрытой приемки с указанием причины (только для модераторов)
+	// (POST /receptions/{receptionId}/reopen)
+	PostReceptionsReceptionIdReopen(ctx echo.Context, receptionId openapi_types.UUID) error
+	// Перемещение товаров закрытой приемки в другой ПВЗ (только для сотрудников)
+	// (POST /receptions/{receptionId}/transfers)
+	PostReceptionsReceptionIdTransfers(ctx echo.Context, receptionId openapi_types.UUID) error
 	// Регистрация пользователя
 	// (POST /register)
 	PostRegister(ctx echo.Context) error
+	// Обмен токена обновления на новую пару токенов
+	// (POST /token/refresh)
+	PostTokenRefresh(ctx echo.Context) error
+	// Список пользователей с поиском и пагинацией (только для модераторов)
+	// (GET /users)
+	GetUsers(ctx echo.Context, params GetUsersParams) error
+	// Получение пользователя (только для модераторов)
+	// (GET /users/{userId})
+	GetUsersUserId(ctx echo.Context, userId openapi_types.UUID) error
+	// Изменение роли пользователя, его сессии завершаются (только для модераторов, роль администратора меняют только администраторы)
+	// (PATCH /users/{userId})
+	PatchUsersUserId(ctx echo.Context, userId openapi_types.UUID) error
+	// Повторная активация пользователя (только для модераторов)
+	// (POST /users/{userId}/activate)
+	PostUsersUserIdActivate(ctx echo.Context, userId openapi_types.UUID) error
+	// Деактивация пользователя, его сессии завершаются (только для модераторов)
+	// (POST /users/{userId}/deactivate)
+	PostUsersUserIdDeactivate(ctx echo.Context, userId openapi_types.UUID) error
+	// Список прав, выданных пользователю сверх его роли (только для администраторов)
+	// (GET /users/{userId}/permissions)
+	GetUsersUserIdPermissions(ctx echo.Context, userId openapi_types.UUID) error
+	// Отзыв права у пользователя (только для администраторов)
+	// (DELETE /users/{userId}/permissions/{permission})
+	DeleteUsersUserIdPermissionsPermission(ctx echo.Context, userId openapi_types.UUID, permission string) error
+	// Выдача права пользователю (только для администраторов)
+	// (PUT /users/{userId}/permissions/{permission})
+	PutUsersUserIdPermissionsPermission(ctx echo.Context, userId openapi_types.UUID, permission string) error
 }
 
 // ServerInterfaceWrapper converts echo contexts to parameters.
@@ -219,140 +794,919 @@ type ServerInterfaceWrapper struct {
 	Handler ServerInterface
 }
 
-// PostDummyLogin converts echo context to params.
-func (w *ServerInterfaceWrapper) PostDummyLogin(ctx echo.Context) error {
+// GetWellKnownJwksJson converts echo context to params.
+func (w *ServerInterfaceWrapper) GetWellKnownJwksJson(ctx echo.Context) error {
 	var err error
 
 	// Invoke the callback with all the unmarshaled arguments
-	err = w.Handler.PostDummyLogin(ctx)
+	err = w.Handler.GetWellKnownJwksJson(ctx)
 	return err
 }
 
-// PostLogin converts echo context to params.
-func (w *ServerInterfaceWrapper) PostLogin(ctx echo.Context) error {
+// GetCities converts echo context to params.
+func (w *ServerInterfaceWrapper) GetCities(ctx echo.Context) error {
 	var err error
 
+	ctx.Set(BearerAuthScopes, []string{})
+
 	// Invoke the callback with all the unmarshaled arguments
-	err = w.Handler.PostLogin(ctx)
+	err = w.Handler.GetCities(ctx)
 	return err
 }
 
-// PostProducts converts echo context to params.
-func (w *ServerInterfaceWrapper) PostProducts(ctx echo.Context) error {
+// PostCities converts echo context to params.
+func (w *ServerInterfaceWrapper) PostCities(ctx echo.Context) error {
 	var err error
 
 	ctx.Set(BearerAuthScopes, []string{})
 
 	// Invoke the callback with all the unmarshaled arguments
-	err = w.Handler.PostProducts(ctx)
+	err = w.Handler.PostCities(ctx)
 	return err
 }
 
-// GetPvz converts echo context to params.
-func (w *ServerInterfaceWrapper) GetPvz(ctx echo.Context) error {
+// DeleteCitiesCityId converts echo context to params.
+func (w *ServerInterfaceWrapper) DeleteCitiesCityId(ctx echo.Context) error {
 	var err error
+	// ------------- Path parameter "cityId" -------------
+	var cityId openapi_types.UUID
 
-	ctx.Set(BearerAuthScopes, []string{})
-
-	// Parameter object where we will unmarshal all parameters from the context
-	var params GetPvzParams
-	// ------------- Optional query parameter "startDate" -------------
-
-	err = runtime.BindQueryParameter("form", true, false, "startDate", ctx.QueryParams(), &params.StartDate)
+	err = runtime.BindStyledParameterWithOptions("simple", "cityId", ctx.Param("cityId"), &cityId, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationPath, Explode: false, Required: true})
 	if err != nil {
-		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter startDate: %s", err))
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter cityId: %s", err))
 	}
 
-	// ------------- Optional query parameter "endDate" -------------
+	ctx.Set(BearerAuthScopes, []string{})
 
-	err = runtime.BindQueryParameter("form", true, false, "endDate", ctx.QueryParams(), &params.EndDate)
-	if err != nil {
-		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter endDate: %s", err))
-	}
+	// Invoke the callback with all the unmarshaled arguments
+	err = w.Handler.DeleteCitiesCityId(ctx, cityId)
+	return err
+}
 
-	// ------------- Optional query parameter "page" -------------
+// PatchCitiesCityId converts echo context to params.
+func (w *ServerInterfaceWrapper) PatchCitiesCityId(ctx echo.Context) error {
+	var err error
+	// ------------- Path parameter "cityId" -------------
+	var cityId openapi_types.UUID
 
-	err = runtime.BindQueryParameter("form", true, false, "page", ctx.QueryParams(), &params.Page)
+	err = runtime.BindStyledParameterWithOptions("simple", "cityId", ctx.Param("cityId"), &cityId, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationPath, Explode: false, Required: true})
 	if err != nil {
-		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter page: %s", err))
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter cityId: %s", err))
 	}
 
-	// ------------- Optional query parameter "limit" -------------
+	ctx.Set(BearerAuthScopes, []string{})
 
-	err = runtime.BindQueryParameter("form", true, false, "limit", ctx.QueryParams(), &params.Limit)
-	if err != nil {
-		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter limit: %s", err))
-	}
+	// Invoke the callback with all the unmarshaled arguments
+	err = w.Handler.PatchCitiesCityId(ctx, cityId)
+	return err
+}
+
+// PostDummyLogin converts echo context to params.
+func (w *ServerInterfaceWrapper) PostDummyLogin(ctx echo.Context) error {
+	var err error
 
 	// Invoke the callback with all the unmarshaled arguments
-	err = w.Handler.GetPvz(ctx, params)
+	err = w.Handler.PostDummyLogin(ctx)
 	return err
 }
 
-// PostPvz converts echo context to params.
-func (w *ServerInterfaceWrapper) PostPvz(ctx echo.Context) error {
+// PostLogin converts echo context to params.
+func (w *ServerInterfaceWrapper) PostLogin(ctx echo.Context) error {
+	var err error
+
+	// Invoke the callback with all the unmarshaled arguments
+	err = w.Handler.PostLogin(ctx)
+	return err
+}
+
+// PostLogout converts echo context to params.
+func (w *ServerInterfaceWrapper) PostLogout(ctx echo.Context) error {
 	var err error
 
 	ctx.Set(BearerAuthScopes, []string{})
 
 	// Invoke the callback with all the unmarshaled arguments
-	err = w.Handler.PostPvz(ctx)
+	err = w.Handler.PostLogout(ctx)
 	return err
 }
 
-// PostPvzPvzIdCloseLastReception converts echo context to params.
-func (w *ServerInterfaceWrapper) PostPvzPvzIdCloseLastReception(ctx echo.Context) error {
+// GetProductTypes converts echo context to params.
+func (w *ServerInterfaceWrapper) GetProductTypes(ctx echo.Context) error {
 	var err error
-	// ------------- Path parameter "pvzId" -------------
-	var pvzId openapi_types.UUID
 
-	err = runtime.BindStyledParameterWithOptions("simple", "pvzId", ctx.Param("pvzId"), &pvzId, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationPath, Explode: false, Required: true})
-	if err != nil {
-		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter pvzId: %s", err))
-	}
+	ctx.Set(BearerAuthScopes, []string{})
+
+	// Invoke the callback with all the unmarshaled arguments
+	err = w.Handler.GetProductTypes(ctx)
+	return err
+}
+
+// PostProductTypes converts echo context to params.
+func (w *ServerInterfaceWrapper) PostProductTypes(ctx echo.Context) error {
+	var err error
 
 	ctx.Set(BearerAuthScopes, []string{})
 
 	// Invoke the callback with all the unmarshaled arguments
-	err = w.Handler.PostPvzPvzIdCloseLastReception(ctx, pvzId)
+	err = w.Handler.PostProductTypes(ctx)
 	return err
 }
 
-// PostPvzPvzIdDeleteLastProduct converts echo context to params.
-func (w *ServerInterfaceWrapper) PostPvzPvzIdDeleteLastProduct(ctx echo.Context) error {
+// DeleteProductTypesTypeId converts echo context to params.
+func (w *ServerInterfaceWrapper) DeleteProductTypesTypeId(ctx echo.Context) error {
 	var err error
-	// ------------- Path parameter "pvzId" -------------
-	var pvzId openapi_types.UUID
+	// ------------- Path parameter "typeId" -------------
+	var typeId openapi_types.UUID
 
-	err = runtime.BindStyledParameterWithOptions("simple", "pvzId", ctx.Param("pvzId"), &pvzId, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationPath, Explode: false, Required: true})
+	err = runtime.BindStyledParameterWithOptions("simple", "typeId", ctx.Param("typeId"), &typeId, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationPath, Explode: false, Required: true})
 	if err != nil {
-		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter pvzId: %s", err))
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter typeId: %s", err))
 	}
 
 	ctx.Set(BearerAuthScopes, []string{})
 
 	// Invoke the callback with all the unmarshaled arguments
-	err = w.Handler.PostPvzPvzIdDeleteLastProduct(ctx, pvzId)
+	err = w.Handler.DeleteProductTypesTypeId(ctx, typeId)
 	return err
 }
 
-// PostReceptions converts echo context to params.
-func (w *ServerInterfaceWrapper) PostReceptions(ctx echo.Context) error {
+// GetProducts converts echo context to params.
+func (w *ServerInterfaceWrapper) GetProducts(ctx echo.Context) error {
 	var err error
 
 	ctx.Set(BearerAuthScopes, []string{})
 
+	// Parameter object where we will unmarshal all parameters from the context
+	var params GetProductsParams
+	// ------------- Required query parameter "barcode" -------------
+
+	err = runtime.BindQueryParameter("form", true, true, "barcode", ctx.QueryParams(), &params.Barcode)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter barcode: %s", err))
+	}
+
 	// Invoke the callback with all the unmarshaled arguments
-	err = w.Handler.PostReceptions(ctx)
+	err = w.Handler.GetProducts(ctx, params)
 	return err
 }
 
-// PostRegister converts echo context to params.
-func (w *ServerInterfaceWrapper) PostRegister(ctx echo.Context) error {
+// PostProducts converts echo context to params.
+func (w *ServerInterfaceWrapper) PostProducts(ctx echo.Context) error {
 	var err error
 
+	ctx.Set(BearerAuthScopes, []string{})
+
 	// Invoke the callback with all the unmarshaled arguments
-	err = w.Handler.PostRegister(ctx)
+	err = w.Handler.PostProducts(ctx)
+	return err
+}
+
+// PostProductsBatch converts echo context to params.
+func (w *ServerInterfaceWrapper) PostProductsBatch(ctx echo.Context) error {
+	var err error
+
+	ctx.Set(BearerAuthScopes, []string{})
+
+	// Invoke the callback with all the unmarshaled arguments
+	err = w.Handler.PostProductsBatch(ctx)
+	return err
+}
+
+// PostProductsProductIdIssue converts echo context to params.
+func (w *ServerInterfaceWrapper) PostProductsProductIdIssue(ctx echo.Context) error {
+	var err error
+	// ------------- Path parameter "productId" -------------
+	var productId openapi_types.UUID
+
+	err = runtime.BindStyledParameterWithOptions("simple", "productId", ctx.Param("productId"), &productId, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationPath, Explode: false, Required: true})
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter productId: %s", err))
+	}
+
+	ctx.Set(BearerAuthScopes, []string{})
+
+	// Invoke the callback with all the unmarshaled arguments
+	err = w.Handler.PostProductsProductIdIssue(ctx, productId)
+	return err
+}
+
+// PostProductsProductIdMove converts echo context to params.
+func (w *ServerInterfaceWrapper) PostProductsProductIdMove(ctx echo.Context) error {
+	var err error
+	// ------------- Path parameter "productId" -------------
+	var productId openapi_types.UUID
+
+	err = runtime.BindStyledParameterWithOptions("simple", "productId", ctx.Param("productId"), &productId, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationPath, Explode: false, Required: true})
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter productId: %s", err))
+	}
+
+	ctx.Set(BearerAuthScopes, []string{})
+
+	// Invoke the callback with all the unmarshaled arguments
+	err = w.Handler.PostProductsProductIdMove(ctx, productId)
+	return err
+}
+
+// PostProductsProductIdPickup converts echo context to params.
+func (w *ServerInterfaceWrapper) PostProductsProductIdPickup(ctx echo.Context) error {
+	var err error
+	// ------------- Path parameter "productId" -------------
+	var productId openapi_types.UUID
+
+	err = runtime.BindStyledParameterWithOptions("simple", "productId", ctx.Param("productId"), &productId, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationPath, Explode: false, Required: true})
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter productId: %s", err))
+	}
+
+	ctx.Set(BearerAuthScopes, []string{})
+
+	// Invoke the callback with all the unmarshaled arguments
+	err = w.Handler.PostProductsProductIdPickup(ctx, productId)
+	return err
+}
+
+// PostProductsProductIdPickupCode converts echo context to params.
+func (w *ServerInterfaceWrapper) PostProductsProductIdPickupCode(ctx echo.Context) error {
+	var err error
+	// ------------- Path parameter "productId" -------------
+	var productId openapi_types.UUID
+
+	err = runtime.BindStyledParameterWithOptions("simple", "productId", ctx.Param("productId"), &productId, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationPath, Explode: false, Required: true})
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter productId: %s", err))
+	}
+
+	ctx.Set(BearerAuthScopes, []string{})
+
+	// Invoke the callback with all the unmarshaled arguments
+	err = w.Handler.PostProductsProductIdPickupCode(ctx, productId)
+	return err
+}
+
+// PostProductsProductIdReturn converts echo context to params.
+func (w *ServerInterfaceWrapper) PostProductsProductIdReturn(ctx echo.Context) error {
+	var err error
+	// ------------- Path parameter "productId" -------------
+	var productId openapi_types.UUID
+
+	err = runtime.BindStyledParameterWithOptions("simple", "productId", ctx.Param("productId"), &productId, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationPath, Explode: false, Required: true})
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter productId: %s", err))
+	}
+
+	ctx.Set(BearerAuthScopes, []string{})
+
+	// Invoke the callback with all the unmarshaled arguments
+	err = w.Handler.PostProductsProductIdReturn(ctx, productId)
+	return err
+}
+
+// GetPvz converts echo context to params.
+func (w *ServerInterfaceWrapper) GetPvz(ctx echo.Context) error {
+	var err error
+
+	ctx.Set(BearerAuthScopes, []string{})
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params GetPvzParams
+	// ------------- Optional query parameter "startDate" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "startDate", ctx.QueryParams(), &params.StartDate)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter startDate: %s", err))
+	}
+
+	// ------------- Optional query parameter "endDate" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "endDate", ctx.QueryParams(), &params.EndDate)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter endDate: %s", err))
+	}
+
+	// ------------- Optional query parameter "page" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "page", ctx.QueryParams(), &params.Page)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter page: %s", err))
+	}
+
+	// ------------- Optional query parameter "limit" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "limit", ctx.QueryParams(), &params.Limit)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter limit: %s", err))
+	}
+
+	// ------------- Optional query parameter "excludeCancelled" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "excludeCancelled", ctx.QueryParams(), &params.ExcludeCancelled)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter excludeCancelled: %s", err))
+	}
+
+	// ------------- Optional query parameter "productStatus" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "productStatus", ctx.QueryParams(), &params.ProductStatus)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter productStatus: %s", err))
+	}
+
+	// ------------- Optional query parameter "productCounts" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "productCounts", ctx.QueryParams(), &params.ProductCounts)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter productCounts: %s", err))
+	}
+
+	// Invoke the callback with all the unmarshaled arguments
+	err = w.Handler.GetPvz(ctx, params)
+	return err
+}
+
+// PostPvz converts echo context to params.
+func (w *ServerInterfaceWrapper) PostPvz(ctx echo.Context) error {
+	var err error
+
+	ctx.Set(BearerAuthScopes, []string{})
+
+	// Invoke the callback with all the unmarshaled arguments
+	err = w.Handler.PostPvz(ctx)
+	return err
+}
+
+// GetPvzPvzId converts echo context to params.
+func (w *ServerInterfaceWrapper) GetPvzPvzId(ctx echo.Context) error {
+	var err error
+	// ------------- Path parameter "pvzId" -------------
+	var pvzId openapi_types.UUID
+
+	err = runtime.BindStyledParameterWithOptions("simple", "pvzId", ctx.Param("pvzId"), &pvzId, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationPath, Explode: false, Required: true})
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter pvzId: %s", err))
+	}
+
+	ctx.Set(BearerAuthScopes, []string{})
+
+	// Invoke the callback with all the unmarshaled arguments
+	err = w.Handler.GetPvzPvzId(ctx, pvzId)
+	return err
+}
+
+// PatchPvzPvzId converts echo context to params.
+func (w *ServerInterfaceWrapper) PatchPvzPvzId(ctx echo.Context) error {
+	var err error
+	// ------------- Path parameter "pvzId" -------------
+	var pvzId openapi_types.UUID
+
+	err = runtime.BindStyledParameterWithOptions("simple", "pvzId", ctx.Param("pvzId"), &pvzId, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationPath, Explode: false, Required: true})
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter pvzId: %s", err))
+	}
+
+	ctx.Set(BearerAuthScopes, []string{})
+
+	// Invoke the callback with all the unmarshaled arguments
+	err = w.Handler.PatchPvzPvzId(ctx, pvzId)
+	return err
+}
+
+// PostPvzPvzIdCancelLastReception converts echo context to params.
+func (w *ServerInterfaceWrapper) PostPvzPvzIdCancelLastReception(ctx echo.Context) error {
+	var err error
+	// ------------- Path parameter "pvzId" -------------
+	var pvzId openapi_types.UUID
+
+	err = runtime.BindStyledParameterWithOptions("simple", "pvzId", ctx.Param("pvzId"), &pvzId, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationPath, Explode: false, Required: true})
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter pvzId: %s", err))
+	}
+
+	ctx.Set(BearerAuthScopes, []string{})
+
+	// Invoke the callback with all the unmarshaled arguments
+	err = w.Handler.PostPvzPvzIdCancelLastReception(ctx, pvzId)
+	return err
+}
+
+// GetPvzPvzIdCapacity converts echo context to params.
+func (w *ServerInterfaceWrapper) GetPvzPvzIdCapacity(ctx echo.Context) error {
+	var err error
+	// ------------- Path parameter "pvzId" -------------
+	var pvzId openapi_types.UUID
+
+	err = runtime.BindStyledParameterWithOptions("simple", "pvzId", ctx.Param("pvzId"), &pvzId, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationPath, Explode: false, Required: true})
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter pvzId: %s", err))
+	}
+
+	ctx.Set(BearerAuthScopes, []string{})
+
+	// Invoke the callback with all the unmarshaled arguments
+	err = w.Handler.GetPvzPvzIdCapacity(ctx, pvzId)
+	return err
+}
+
+// PutPvzPvzIdCapacity converts echo context to params.
+func (w *ServerInterfaceWrapper) PutPvzPvzIdCapacity(ctx echo.Context) error {
+	var err error
+	// ------------- Path parameter "pvzId" -------------
+	var pvzId openapi_types.UUID
+
+	err = runtime.BindStyledParameterWithOptions("simple", "pvzId", ctx.Param("pvzId"), &pvzId, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationPath, Explode: false, Required: true})
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter pvzId: %s", err))
+	}
+
+	ctx.Set(BearerAuthScopes, []string{})
+
+	// Invoke the callback with all the unmarshaled arguments
+	err = w.Handler.PutPvzPvzIdCapacity(ctx, pvzId)
+	return err
+}
+
+// GetPvzPvzIdCells converts echo context to params.
+func (w *ServerInterfaceWrapper) GetPvzPvzIdCells(ctx echo.Context) error {
+	var err error
+	// ------------- Path parameter "pvzId" -------------
+	var pvzId openapi_types.UUID
+
+	err = runtime.BindStyledParameterWithOptions("simple", "pvzId", ctx.Param("pvzId"), &pvzId, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationPath, Explode: false, Required: true})
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter pvzId: %s", err))
+	}
+
+	ctx.Set(BearerAuthScopes, []string{})
+
+	// Invoke the callback with all the unmarshaled arguments
+	err = w.Handler.GetPvzPvzIdCells(ctx, pvzId)
+	return err
+}
+
+// PostPvzPvzIdCells converts echo context to params.
+func (w *ServerInterfaceWrapper) PostPvzPvzIdCells(ctx echo.Context) error {
+	var err error
+	// ------------- Path parameter "pvzId" -------------
+	var pvzId openapi_types.UUID
+
+	err = runtime.BindStyledParameterWithOptions("simple", "pvzId", ctx.Param("pvzId"), &pvzId, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationPath, Explode: false, Required: true})
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter pvzId: %s", err))
+	}
+
+	ctx.Set(BearerAuthScopes, []string{})
+
+	// Invoke the callback with all the unmarshaled arguments
+	err = w.Handler.PostPvzPvzIdCells(ctx, pvzId)
+	return err
+}
+
+// PostPvzPvzIdCloseLastReception converts echo context to params.
+func (w *ServerInterfaceWrapper) PostPvzPvzIdCloseLastReception(ctx echo.Context) error {
+	var err error
+	// ------------- Path parameter "pvzId" -------------
+	var pvzId openapi_types.UUID
+
+	err = runtime.BindStyledParameterWithOptions("simple", "pvzId", ctx.Param("pvzId"), &pvzId, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationPath, Explode: false, Required: true})
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter pvzId: %s", err))
+	}
+
+	ctx.Set(BearerAuthScopes, []string{})
+
+	// Invoke the callback with all the unmarshaled arguments
+	err = w.Handler.PostPvzPvzIdCloseLastReception(ctx, pvzId)
+	return err
+}
+
+// PostPvzPvzIdDeleteLastProduct converts echo context to params.
+func (w *ServerInterfaceWrapper) PostPvzPvzIdDeleteLastProduct(ctx echo.Context) error {
+	var err error
+	// ------------- Path parameter "pvzId" -------------
+	var pvzId openapi_types.UUID
+
+	err = runtime.BindStyledParameterWithOptions("simple", "pvzId", ctx.Param("pvzId"), &pvzId, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationPath, Explode: false, Required: true})
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter pvzId: %s", err))
+	}
+
+	ctx.Set(BearerAuthScopes, []string{})
+
+	// Invoke the callback with all the unmarshaled arguments
+	err = w.Handler.PostPvzPvzIdDeleteLastProduct(ctx, pvzId)
+	return err
+}
+
+// GetPvzPvzIdEmployees converts echo context to params.
+func (w *ServerInterfaceWrapper) GetPvzPvzIdEmployees(ctx echo.Context) error {
+	var err error
+	// ------------- Path parameter "pvzId" -------------
+	var pvzId openapi_types.UUID
+
+	err = runtime.BindStyledParameterWithOptions("simple", "pvzId", ctx.Param("pvzId"), &pvzId, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationPath, Explode: false, Required: true})
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter pvzId: %s", err))
+	}
+
+	ctx.Set(BearerAuthScopes, []string{})
+
+	// Invoke the callback with all the unmarshaled arguments
+	err = w.Handler.GetPvzPvzIdEmployees(ctx, pvzId)
+	return err
+}
+
+// PostPvzPvzIdEmployees converts echo context to params.
+func (w *ServerInterfaceWrapper) PostPvzPvzIdEmployees(ctx echo.Context) error {
+	var err error
+	// ------------- Path parameter "pvzId" -------------
+	var pvzId openapi_types.UUID
+
+	err = runtime.BindStyledParameterWithOptions("simple", "pvzId", ctx.Param("pvzId"), &pvzId, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationPath, Explode: false, Required: true})
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter pvzId: %s", err))
+	}
+
+	ctx.Set(BearerAuthScopes, []string{})
+
+	// Invoke the callback with all the unmarshaled arguments
+	err = w.Handler.PostPvzPvzIdEmployees(ctx, pvzId)
+	return err
+}
+
+// DeletePvzPvzIdEmployeesUserId converts echo context to params.
+func (w *ServerInterfaceWrapper) DeletePvzPvzIdEmployeesUserId(ctx echo.Context) error {
+	var err error
+	// ------------- Path parameter "pvzId" -------------
+	var pvzId openapi_types.UUID
+
+	err = runtime.BindStyledParameterWithOptions("simple", "pvzId", ctx.Param("pvzId"), &pvzId, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationPath, Explode: false, Required: true})
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter pvzId: %s", err))
+	}
+
+	// ------------- Path parameter "userId" -------------
+	var userId openapi_types.UUID
+
+	err = runtime.BindStyledParameterWithOptions("simple", "userId", ctx.Param("userId"), &userId, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationPath, Explode: false, Required: true})
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter userId: %s", err))
+	}
+
+	ctx.Set(BearerAuthScopes, []string{})
+
+	// Invoke the callback with all the unmarshaled arguments
+	err = w.Handler.DeletePvzPvzIdEmployeesUserId(ctx, pvzId, userId)
+	return err
+}
+
+// PostReceptions converts echo context to params.
+func (w *ServerInterfaceWrapper) PostReceptions(ctx echo.Context) error {
+	var err error
+
+	ctx.Set(BearerAuthScopes, []string{})
+
+	// Invoke the callback with all the unmarshaled arguments
+	err = w.Handler.PostReceptions(ctx)
+	return err
+}
+
+// GetReceptionsReceptionId converts echo context to params.
+func (w *ServerInterfaceWrapper) GetReceptionsReceptionId(ctx echo.Context) error {
+	var err error
+	// ------------- Path parameter "receptionId" -------------
+	var receptionId openapi_types.UUID
+
+	err = runtime.BindStyledParameterWithOptions("simple", "receptionId", ctx.Param("receptionId"), &receptionId, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationPath, Explode: false, Required: true})
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter receptionId: %s", err))
+	}
+
+	ctx.Set(BearerAuthScopes, []string{})
+
+	// Invoke the callback with all the unmarshaled arguments
+	err = w.Handler.GetReceptionsReceptionId(ctx, receptionId)
+	return err
+}
+
+// GetReceptionsReceptionIdHistory converts echo context to params.
+func (w *ServerInterfaceWrapper) GetReceptionsReceptionIdHistory(ctx echo.Context) error {
+	var err error
+	// ------------- Path parameter "receptionId" -------------
+	var receptionId openapi_types.UUID
+
+	err = runtime.BindStyledParameterWithOptions("simple", "receptionId", ctx.Param("receptionId"), &receptionId, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationPath, Explode: false, Required: true})
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter receptionId: %s", err))
+	}
+
+	ctx.Set(BearerAuthScopes, []string{})
+
+	// Invoke the callback with all the unmarshaled arguments
+	err = w.Handler.GetReceptionsReceptionIdHistory(ctx, receptionId)
+	return err
+}
+
+// GetReceptionsReceptionIdProducts converts echo context to params.
+func (w *ServerInterfaceWrapper) GetReceptionsReceptionIdProducts(ctx echo.Context) error {
+	var err error
+	// ------------- Path parameter "receptionId" -------------
+	var receptionId openapi_types.UUID
+
+	err = runtime.BindStyledParameterWithOptions("simple", "receptionId", ctx.Param("receptionId"), &receptionId, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationPath, Explode: false, Required: true})
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter receptionId: %s", err))
+	}
+
+	ctx.Set(BearerAuthScopes, []string{})
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params GetReceptionsReceptionIdProductsParams
+	// ------------- Optional query parameter "type" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "type", ctx.QueryParams(), &params.Type)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter type: %s", err))
+	}
+
+	// ------------- Optional query parameter "cursor" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "cursor", ctx.QueryParams(), &params.Cursor)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter cursor: %s", err))
+	}
+
+	// ------------- Optional query parameter "limit" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "limit", ctx.QueryParams(), &params.Limit)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter limit: %s", err))
+	}
+
+	// Invoke the callback with all the unmarshaled arguments
+	err = w.Handler.GetReceptionsReceptionIdProducts(ctx, receptionId, params)
+	return err
+}
+
+// DeleteReceptionsReceptionIdProductsProductId converts echo context to params.
+func (w *ServerInterfaceWrapper) DeleteReceptionsReceptionIdProductsProductId(ctx echo.Context) error {
+	var err error
+	// ------------- Path parameter "receptionId" -------------
+	var receptionId openapi_types.UUID
+
+	err = runtime.BindStyledParameterWithOptions("simple", "receptionId", ctx.Param("receptionId"), &receptionId, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationPath, Explode: false, Required: true})
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter receptionId: %s", err))
+	}
+
+	// ------------- Path parameter "productId" -------------
+	var productId openapi_types.UUID
+
+	err = runtime.BindStyledParameterWithOptions("simple", "productId", ctx.Param("productId"), &productId, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationPath, Explode: false, Required: true})
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter productId: %s", err))
+	}
+
+	ctx.Set(BearerAuthScopes, []string{})
+
+	// Invoke the callback with all the unmarshaled arguments
+	err = w.Handler.DeleteReceptionsReceptionIdProductsProductId(ctx, receptionId, productId)
+	return err
+}
+
+// PostReceptionsReceptionIdReopen converts echo context to params.
+func (w *ServerInterfaceWrapper) PostReceptionsReceptionIdReopen(ctx echo.Context) error {
+	var err error
+	// ------------- Path parameter "receptionId" -------------
+	var receptionId openapi_types.UUID
+
+	err = runtime.BindStyledParameterWithOptions("simple", "receptionId", ctx.Param("receptionId"), &receptionId, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationPath, Explode: false, Required: true})
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter receptionId: %s", err))
+	}
+
+	ctx.Set(BearerAuthScopes, []string{})
+
+	// Invoke the callback with all the unmarshaled arguments
+	err = w.Handler.PostReceptionsReceptionIdReopen(ctx, receptionId)
+	return err
+}
+
+// PostReceptionsReceptionIdTransfers converts echo context to params.
+func (w *ServerInterfaceWrapper) PostReceptionsReceptionIdTransfers(ctx echo.Context) error {
+	var err error
+	// ------------- Path parameter "receptionId" -------------
+	var receptionId openapi_types.UUID
+
+	err = runtime.BindStyledParameterWithOptions("simple", "receptionId", ctx.Param("receptionId"), &receptionId, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationPath, Explode: false, Required: true})
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter receptionId: %s", err))
+	}
+
+	ctx.Set(BearerAuthScopes, []string{})
+
+	// Invoke the callback with all the unmarshaled arguments
+	err = w.Handler.PostReceptionsReceptionIdTransfers(ctx, receptionId)
+	return err
+}
+
+// PostRegister converts echo context to params.
+func (w *ServerInterfaceWrapper) PostRegister(ctx echo.Context) error {
+	var err error
+
+	// Invoke the callback with all the unmarshaled arguments
+	err = w.Handler.PostRegister(ctx)
+	return err
+}
+
+// PostTokenRefresh converts echo context to params.
+func (w *ServerInterfaceWrapper) PostTokenRefresh(ctx echo.Context) error {
+	var err error
+
+	// Invoke the callback with all the unmarshaled arguments
+	err = w.Handler.PostTokenRefresh(ctx)
+	return err
+}
+
+// GetUsers converts echo context to params.
+func (w *ServerInterfaceWrapper) GetUsers(ctx echo.Context) error {
+	var err error
+
+	ctx.Set(BearerAuthScopes, []string{})
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params GetUsersParams
+	// ------------- Optional query parameter "search" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "search", ctx.QueryParams(), &params.Search)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter search: %s", err))
+	}
+
+	// ------------- Optional query parameter "role" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "role", ctx.QueryParams(), &params.Role)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter role: %s", err))
+	}
+
+	// ------------- Optional query parameter "page" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "page", ctx.QueryParams(), &params.Page)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter page: %s", err))
+	}
+
+	// ------------- Optional query parameter "limit" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "limit", ctx.QueryParams(), &params.Limit)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter limit: %s", err))
+	}
+
+	// Invoke the callback with all the unmarshaled arguments
+	err = w.Handler.GetUsers(ctx, params)
+	return err
+}
+
+// GetUsersUserId converts echo context to params.
+func (w *ServerInterfaceWrapper) GetUsersUserId(ctx echo.Context) error {
+	var err error
+	// ------------- Path parameter "userId" -------------
+	var userId openapi_types.UUID
+
+	err = runtime.BindStyledParameterWithOptions("simple", "userId", ctx.Param("userId"), &userId, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationPath, Explode: false, Required: true})
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter userId: %s", err))
+	}
+
+	ctx.Set(BearerAuthScopes, []string{})
+
+	// Invoke the callback with all the unmarshaled arguments
+	err = w.Handler.GetUsersUserId(ctx, userId)
+	return err
+}
+
+// PatchUsersUserId converts echo context to params.
+func (w *ServerInterfaceWrapper) PatchUsersUserId(ctx echo.Context) error {
+	var err error
+	// ------------- Path parameter "userId" -------------
+	var userId openapi_types.UUID
+
+	err = runtime.BindStyledParameterWithOptions("simple", "userId", ctx.Param("userId"), &userId, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationPath, Explode: false, Required: true})
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter userId: %s", err))
+	}
+
+	ctx.Set(BearerAuthScopes, []string{})
+
+	// Invoke the callback with all the unmarshaled arguments
+	err = w.Handler.PatchUsersUserId(ctx, userId)
+	return err
+}
+
+// PostUsersUserIdActivate converts echo context to params.
+func (w *ServerInterfaceWrapper) PostUsersUserIdActivate(ctx echo.Context) error {
+	var err error
+	// ------------- Path parameter "userId" -------------
+	var userId openapi_types.UUID
+
+	err = runtime.BindStyledParameterWithOptions("simple", "userId", ctx.Param("userId"), &userId, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationPath, Explode: false, Required: true})
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter userId: %s", err))
+	}
+
+	ctx.Set(BearerAuthScopes, []string{})
+
+	// Invoke the callback with all the unmarshaled arguments
+	err = w.Handler.PostUsersUserIdActivate(ctx, userId)
+	return err
+}
+
+// PostUsersUserIdDeactivate converts echo context to params.
+func (w *ServerInterfaceWrapper) PostUsersUserIdDeactivate(ctx echo.Context) error {
+	var err error
+	// ------------- Path parameter "userId" -------------
+	var userId openapi_types.UUID
+
+	err = runtime.BindStyledParameterWithOptions("simple", "userId", ctx.Param("userId"), &userId, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationPath, Explode: false, Required: true})
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter userId: %s", err))
+	}
+
+	ctx.Set(BearerAuthScopes, []string{})
+
+	// Invoke the callback with all the unmarshaled arguments
+	err = w.Handler.PostUsersUserIdDeactivate(ctx, userId)
+	return err
+}
+
+// GetUsersUserIdPermissions converts echo context to params.
+func (w *ServerInterfaceWrapper) GetUsersUserIdPermissions(ctx echo.Context) error {
+	var err error
+	// ------------- Path parameter "userId" -------------
+	var userId openapi_types.UUID
+
+	err = runtime.BindStyledParameterWithOptions("simple", "userId", ctx.Param("userId"), &userId, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationPath, Explode: false, Required: true})
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter userId: %s", err))
+	}
+
+	ctx.Set(BearerAuthScopes, []string{})
+
+	// Invoke the callback with all the unmarshaled arguments
+	err = w.Handler.GetUsersUserIdPermissions(ctx, userId)
+	return err
+}
+
+// DeleteUsersUserIdPermissionsPermission converts echo context to params.
+func (w *ServerInterfaceWrapper) DeleteUsersUserIdPermissionsPermission(ctx echo.Context) error {
+	var err error
+	// ------------- Path parameter "userId" -------------
+	var userId openapi_types.UUID
+
+	err = runtime.BindStyledParameterWithOptions("simple", "userId", ctx.Param("userId"), &userId, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationPath, Explode: false, Required: true})
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter userId: %s", err))
+	}
+
+	// ------------- Path parameter "permission" -------------
+	var permission string
+
+	err = runtime.BindStyledParameterWithOptions("simple", "permission", ctx.Param("permission"), &permission, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationPath, Explode: false, Required: true})
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter permission: %s", err))
+	}
+
+	ctx.Set(BearerAuthScopes, []string{})
+
+	// Invoke the callback with all the unmarshaled arguments
+	err = w.Handler.DeleteUsersUserIdPermissionsPermission(ctx, userId, permission)
+	return err
+}
+
+// PutUsersUserIdPermissionsPermission converts echo context to params.
+func (w *ServerInterfaceWrapper) PutUsersUserIdPermissionsPermission(ctx echo.Context) error {
+	var err error
+	// ------------- Path parameter "userId" -------------
+	var userId openapi_types.UUID
+
+	err = runtime.BindStyledParameterWithOptions("simple", "userId", ctx.Param("userId"), &userId, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationPath, Explode: false, Required: true})
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter userId: %s", err))
+	}
+
+	// ------------- Path parameter "permission" -------------
+	var permission string
+
+	err = runtime.BindStyledParameterWithOptions("simple", "permission", ctx.Param("permission"), &permission, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationPath, Explode: false, Required: true})
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter permission: %s", err))
+	}
+
+	ctx.Set(BearerAuthScopes, []string{})
+
+	// Invoke the callback with all the unmarshaled arguments
+	err = w.Handler.PutUsersUserIdPermissionsPermission(ctx, userId, permission)
 	return err
 }
 
@@ -384,14 +1738,55 @@ func RegisterHandlersWithBaseURL(router EchoRouter, si ServerInterface, baseURL
 		Handler: si,
 	}
 
+	router.GET(baseURL+"/.well-known/jwks.json", wrapper.GetWellKnownJwksJson)
+	router.GET(baseURL+"/cities", wrapper.GetCities)
+	router.POST(baseURL+"/cities", wrapper.PostCities)
+	router.DELETE(baseURL+"/cities/:cityId", wrapper.DeleteCitiesCityId)
+	router.PATCH(baseURL+"/cities/:cityId", wrapper.PatchCitiesCityId)
 	router.POST(baseURL+"/dummyLogin", wrapper.PostDummyLogin)
 	router.POST(baseURL+"/login", wrapper.PostLogin)
+	router.POST(baseURL+"/logout", wrapper.PostLogout)
+	router.GET(baseURL+"/product_types", wrapper.GetProductTypes)
+	router.POST(baseURL+"/product_types", wrapper.PostProductTypes)
+	router.DELETE(baseURL+"/product_types/:typeId", wrapper.DeleteProductTypesTypeId)
+	router.GET(baseURL+"/products", wrapper.GetProducts)
 	router.POST(baseURL+"/products", wrapper.PostProducts)
+	router.POST(baseURL+"/products/batch", wrapper.PostProductsBatch)
+	router.POST(baseURL+"/products/:productId/issue", wrapper.PostProductsProductIdIssue)
+	router.POST(baseURL+"/products/:productId/move", wrapper.PostProductsProductIdMove)
+	router.POST(baseURL+"/products/:productId/pickup", wrapper.PostProductsProductIdPickup)
+	router.POST(baseURL+"/products/:productId/pickup_code", wrapper.PostProductsProductIdPickupCode)
+	router.POST(baseURL+"/products/:productId/return", wrapper.PostProductsProductIdReturn)
 	router.GET(baseURL+"/pvz", wrapper.GetPvz)
 	router.POST(baseURL+"/pvz", wrapper.PostPvz)
+	router.GET(baseURL+"/pvz/:pvzId", wrapper.GetPvzPvzId)
+	router.PATCH(baseURL+"/pvz/:pvzId", wrapper.PatchPvzPvzId)
+	router.POST(baseURL+"/pvz/:pvzId/cancel_last_reception", wrapper.PostPvzPvzIdCancelLastReception)
+	router.GET(baseURL+"/pvz/:pvzId/capacity", wrapper.GetPvzPvzIdCapacity)
+	router.PUT(baseURL+"/pvz/:pvzId/capacity", wrapper.PutPvzPvzIdCapacity)
+	router.GET(baseURL+"/pvz/:pvzId/cells", wrapper.GetPvzPvzIdCells)
+	router.POST(baseURL+"/pvz/:pvzId/cells", wrapper.PostPvzPvzIdCells)
 	router.POST(baseURL+"/pvz/:pvzId/close_last_reception", wrapper.PostPvzPvzIdCloseLastReception)
 	router.POST(baseURL+"/pvz/:pvzId/delete_last_product", wrapper.PostPvzPvzIdDeleteLastProduct)
+	router.GET(baseURL+"/pvz/:pvzId/employees", wrapper.GetPvzPvzIdEmployees)
+	router.POST(baseURL+"/pvz/:pvzId/employees", wrapper.PostPvzPvzIdEmployees)
+	router.DELETE(baseURL+"/pvz/:pvzId/employees/:userId", wrapper.DeletePvzPvzIdEmployeesUserId)
 	router.POST(baseURL+"/receptions", wrapper.PostReceptions)
+	router.GET(baseURL+"/receptions/:receptionId", wrapper.GetReceptionsReceptionId)
+	router.GET(baseURL+"/receptions/:receptionId/history", wrapper.GetReceptionsReceptionIdHistory)
+	router.GET(baseURL+"/receptions/:receptionId/products", wrapper.GetReceptionsReceptionIdProducts)
+	router.DELETE(baseURL+"/receptions/:receptionId/products/:productId", wrapper.DeleteReceptionsReceptionIdProductsProductId)
+	router.POST(baseURL+"/receptions/:receptionId/reopen", wrapper.PostReceptionsReceptionIdReopen)
+	router.POST(baseURL+"/receptions/:receptionId/transfers", wrapper.PostReceptionsReceptionIdTransfers)
 	router.POST(baseURL+"/register", wrapper.PostRegister)
+	router.POST(baseURL+"/token/refresh", wrapper.PostTokenRefresh)
+	router.GET(baseURL+"/users", wrapper.GetUsers)
+	router.GET(baseURL+"/users/:userId", wrapper.GetUsersUserId)
+	router.PATCH(baseURL+"/users/:userId", wrapper.PatchUsersUserId)
+	router.POST(baseURL+"/users/:userId/activate", wrapper.PostUsersUserIdActivate)
+	router.POST(baseURL+"/users/:userId/deactivate", wrapper.PostUsersUserIdDeactivate)
+	router.GET(baseURL+"/users/:userId/permissions", wrapper.GetUsersUserIdPermissions)
+	router.DELETE(baseURL+"/users/:userId/permissions/:permission", wrapper.DeleteUsersUserIdPermissionsPermission)
+	router.PUT(baseURL+"/users/:userId/permissions/:permission", wrapper.PutUsersUserIdPermissionsPermission)
 
 }
@@ -4,3 +4,9 @@ package dtos
 type Token struct {
 	Value string `json:"token"`
 }
+
+// Access and refresh token pair response struct
+type TokenPair struct {
+	Value        string `json:"token"`
+	RefreshToken string `json:"refreshToken"`
+}
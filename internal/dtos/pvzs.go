@@ -8,8 +8,36 @@ type PVZWithReceptions struct {
 	Receptions []ReceptionWithProducts `json:"receptions"`
 }
 
+// PVZ with receptions and their product counts response struct
+type PVZWithReceptionCounts struct {
+	PVZ        pvzapi.PVZ           `json:"pvz"`
+	Receptions []ReceptionWithCount `json:"receptions"`
+}
+
+// PVZ with its open reception, counters and transfers in transit response struct
+type PVZDetails struct {
+	PVZ               pvzapi.PVZ               `json:"pvz"`
+	OpenReception     *OpenReception           `json:"openReception"`
+	ReceptionsCount   int                      `json:"receptionsCount"`
+	ProductsCount     int                      `json:"productsCount"`
+	InboundTransfers  []pvzapi.ProductTransfer `json:"inboundTransfers"`
+	OutboundTransfers []pvzapi.ProductTransfer `json:"outboundTransfers"`
+}
+
+// Open reception response struct
+type OpenReception struct {
+	Reception     pvzapi.Reception `json:"reception"`
+	ProductsCount int              `json:"productsCount"`
+}
+
 // Reception with products response struct
 type ReceptionWithProducts struct {
 	Reception pvzapi.Reception `json:"reception"`
 	Products  []pvzapi.Product `json:"products"`
 }
+
+// Reception with its product count response struct
+type ReceptionWithCount struct {
+	Reception     pvzapi.Reception `json:"reception"`
+	ProductsCount int              `json:"productsCount"`
+}
@@ -4,25 +4,28 @@ import (
 	"errors"
 	"strings"
 
+	"github.com/google/uuid"
 	"github.com/labstack/echo/v4"
 
 	"github.com/cyansnbrst/pvz-service/pkg/auth"
-	"github.com/cyansnbrst/pvz-service/pkg/auth/jwt"
 	hh "github.com/cyansnbrst/pvz-service/pkg/http_helpers"
 )
 
+// Paths available without a token
+var publicPaths = map[string]bool{
+	"/login":                 true,
+	"/register":              true,
+	"/dummyLogin":            true,
+	"/token/refresh":         true,
+	"/.well-known/jwks.json": true,
+}
+
 // Authentication middleware
 func (mw *Manager) Authenticate(next echo.HandlerFunc) echo.HandlerFunc {
-	excludedPaths := map[string]bool{
-		"/login":      true,
-		"/register":   true,
-		"/dummyLogin": true,
-	}
-
 	return func(c echo.Context) error {
 		currentPath := c.Path()
 
-		if excludedPaths[currentPath] {
+		if publicPaths[currentPath] {
 			return next(c)
 		}
 
@@ -37,7 +40,7 @@ func (mw *Manager) Authenticate(next echo.HandlerFunc) echo.HandlerFunc {
 			return hh.AccessDeniedResponse(c)
 		}
 
-		userRole, err := jwt.ParseJWT(token, mw.cfg.App.JWTSecretKey)
+		claims, err := mw.pvzUC.ParseJWT(c.Request().Context(), token)
 		if err != nil {
 			if errors.Is(err, auth.ErrInvalidToken) {
 				return hh.AccessDeniedResponse(c)
@@ -46,7 +49,37 @@ func (mw *Manager) Authenticate(next echo.HandlerFunc) echo.HandlerFunc {
 			return hh.ServerErrorResponse(c, mw.logger, err)
 		}
 
-		ContextSetUserRole(c, userRole)
+		if claims.ID != uuid.Nil {
+			revoked, err := mw.pvzUC.IsTokenRevoked(c.Request().Context(), claims.ID)
+			if err != nil {
+				return hh.ServerErrorResponse(c, mw.logger, err)
+			}
+			if revoked {
+				return hh.AccessDeniedResponse(c)
+			}
+		}
+
+		if claims.UserID != uuid.Nil {
+			deactivated, err := mw.pvzUC.IsUserDeactivated(c.Request().Context(), claims.UserID)
+			if err != nil {
+				return hh.ServerErrorResponse(c, mw.logger, err)
+			}
+			if deactivated {
+				return hh.AccessDeniedResponse(c)
+			}
+
+			changed, err := mw.pvzUC.IsRoleChanged(c.Request().Context(), claims.UserID, claims.Role)
+			if err != nil {
+				return hh.ServerErrorResponse(c, mw.logger, err)
+			}
+			if changed {
+				return hh.AccessDeniedResponse(c)
+			}
+		}
+
+		ContextSetUserRole(c, claims.Role)
+		ContextSetUserID(c, claims.UserID)
+		ContextSetToken(c, claims.ID, claims.ExpiresAt)
 		return next(c)
 	}
 }
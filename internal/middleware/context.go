@@ -2,13 +2,20 @@ package middleware
 
 import (
 	"errors"
+	"time"
 
+	"github.com/google/uuid"
 	"github.com/labstack/echo/v4"
 
 	"github.com/cyansnbrst/pvz-service/gen/pvzapi"
 )
 
-const RoleContextKey = "role"
+const (
+	RoleContextKey        = "role"
+	UserIDContextKey      = "user_id"
+	TokenIDContextKey     = "token_id"
+	TokenExpiryContextKey = "token_expiry"
+)
 
 // Set user role to the context
 func ContextSetUserRole(c echo.Context, role pvzapi.UserRole) {
@@ -23,3 +30,36 @@ func ContextGetUserRole(c echo.Context) (pvzapi.UserRole, error) {
 	}
 	return role, nil
 }
+
+// Set user id to the context
+func ContextSetUserID(c echo.Context, userID uuid.UUID) {
+	c.Set(UserIDContextKey, userID)
+}
+
+// Get user id from the context
+func ContextGetUserID(c echo.Context) (uuid.UUID, error) {
+	userID, ok := c.Get(UserIDContextKey).(uuid.UUID)
+	if !ok {
+		return uuid.Nil, errors.New("incorrect user id")
+	}
+	return userID, nil
+}
+
+// Set access token id and expiration time to the context
+func ContextSetToken(c echo.Context, tokenID uuid.UUID, expiresAt time.Time) {
+	c.Set(TokenIDContextKey, tokenID)
+	c.Set(TokenExpiryContextKey, expiresAt)
+}
+
+// Get access token id and expiration time from the context
+func ContextGetToken(c echo.Context) (uuid.UUID, time.Time, error) {
+	tokenID, ok := c.Get(TokenIDContextKey).(uuid.UUID)
+	if !ok {
+		return uuid.Nil, time.Time{}, errors.New("incorrect token id")
+	}
+	expiresAt, ok := c.Get(TokenExpiryContextKey).(time.Time)
+	if !ok {
+		return uuid.Nil, time.Time{}, errors.New("incorrect token expiry")
+	}
+	return tokenID, expiresAt, nil
+}
@@ -4,18 +4,21 @@ import (
 	"go.uber.org/zap"
 
 	"github.com/cyansnbrst/pvz-service/config"
+	"github.com/cyansnbrst/pvz-service/internal/pvz"
 )
 
 // Middleware manager struct
 type Manager struct {
 	cfg    *config.Config
 	logger *zap.Logger
+	pvzUC  pvz.UseCase
 }
 
 // Middleware manager constructor
-func NewManager(cfg *config.Config, logger *zap.Logger) *Manager {
+func NewManager(cfg *config.Config, logger *zap.Logger, pvzUC pvz.UseCase) *Manager {
 	return &Manager{
 		cfg:    cfg,
 		logger: logger,
+		pvzUC:  pvzUC,
 	}
 }
@@ -0,0 +1,117 @@
+package middleware
+
+import (
+	"strings"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/cyansnbrst/pvz-service/internal/models"
+	hh "github.com/cyansnbrst/pvz-service/pkg/http_helpers"
+)
+
+// Permission required by each authenticated operation, keyed by method and OpenAPI path.
+// An empty permission lets any authenticated user in, operations missing here are denied
+var operationPermissions = map[string]models.Permission{
+	"POST /logout": "",
+
+	"GET /cities":                    models.PermCitiesRead,
+	"POST /cities":                   models.PermCitiesManage,
+	"PATCH /cities/{cityId}":         models.PermCitiesManage,
+	"DELETE /cities/{cityId}":        models.PermCitiesManage,
+	"GET /product_types":             models.PermProductTypesRead,
+	"POST /product_types":            models.PermProductTypesManage,
+	"DELETE /product_types/{typeId}": models.PermProductTypesManage,
+
+	"GET /pvz":                               models.PermPVZRead,
+	"GET /pvz/{pvzId}":                       models.PermPVZRead,
+	"GET /pvz/{pvzId}/cells":                 models.PermPVZRead,
+	"GET /pvz/{pvzId}/capacity":              models.PermPVZRead,
+	"POST /pvz":                              models.PermPVZManage,
+	"PATCH /pvz/{pvzId}":                     models.PermPVZManage,
+	"POST /pvz/{pvzId}/cells":                models.PermPVZManage,
+	"PUT /pvz/{pvzId}/capacity":              models.PermPVZManage,
+	"GET /pvz/{pvzId}/employees":             models.PermEmployeesManage,
+	"POST /pvz/{pvzId}/employees":            models.PermEmployeesManage,
+	"DELETE /pvz/{pvzId}/employees/{userId}": models.PermEmployeesManage,
+
+	"GET /receptions/{receptionId}":                         models.PermReceptionsRead,
+	"GET /receptions/{receptionId}/products":                models.PermReceptionsRead,
+	"GET /receptions/{receptionId}/history":                 models.PermReceptionsHistory,
+	"POST /receptions/{receptionId}/reopen":                 models.PermReceptionsReopen,
+	"POST /receptions":                                      models.PermReceptionsWrite,
+	"POST /products":                                        models.PermReceptionsWrite,
+	"POST /products/batch":                                  models.PermReceptionsWrite,
+	"POST /pvz/{pvzId}/close_last_reception":                models.PermReceptionsWrite,
+	"POST /pvz/{pvzId}/cancel_last_reception":               models.PermReceptionsWrite,
+	"POST /pvz/{pvzId}/delete_last_product":                 models.PermReceptionsWrite,
+	"DELETE /receptions/{receptionId}/products/{productId}": models.PermReceptionsWrite,
+
+	"GET /products":                            models.PermProductsRead,
+	"POST /products/{productId}/issue":         models.PermProductsHandle,
+	"POST /products/{productId}/return":        models.PermProductsHandle,
+	"POST /products/{productId}/pickup_code":   models.PermProductsHandle,
+	"POST /products/{productId}/pickup":        models.PermProductsHandle,
+	"POST /products/{productId}/move":          models.PermProductsHandle,
+	"POST /receptions/{receptionId}/transfers": models.PermProductsHandle,
+
+	"GET /users":                      models.PermUsersManage,
+	"GET /users/{userId}":             models.PermUsersManage,
+	"PATCH /users/{userId}":           models.PermUsersManage,
+	"POST /users/{userId}/activate":   models.PermUsersManage,
+	"POST /users/{userId}/deactivate": models.PermUsersManage,
+
+	"GET /users/{userId}/permissions":                 models.PermPermissionsManage,
+	"PUT /users/{userId}/permissions/{permission}":    models.PermPermissionsManage,
+	"DELETE /users/{userId}/permissions/{permission}": models.PermPermissionsManage,
+}
+
+// Authorization middleware, checks the permission of the operation against the user role and grants
+func (mw *Manager) Authorize(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		if publicPaths[c.Path()] {
+			return next(c)
+		}
+
+		permission, ok := operationPermissions[operationKey(c.Request().Method, c.Path())]
+		if !ok {
+			return hh.AccessDeniedResponse(c)
+		}
+
+		if permission == "" {
+			return next(c)
+		}
+
+		role, err := ContextGetUserRole(c)
+		if err != nil {
+			return hh.ServerErrorResponse(c, mw.logger, err)
+		}
+
+		userID, err := ContextGetUserID(c)
+		if err != nil {
+			return hh.ServerErrorResponse(c, mw.logger, err)
+		}
+
+		allowed, err := mw.pvzUC.HasPermission(c.Request().Context(), userID, role, permission)
+		if err != nil {
+			return hh.ServerErrorResponse(c, mw.logger, err)
+		}
+
+		if !allowed {
+			return hh.AccessDeniedResponse(c)
+		}
+
+		return next(c)
+	}
+}
+
+// Build the operation key from the method and the echo route path ("/pvz/:pvzId" becomes "/pvz/{pvzId}")
+func operationKey(method, path string) string {
+	segments := strings.Split(path, "/")
+	for i, segment := range segments {
+		if strings.HasPrefix(segment, ":") {
+			segments[i] = "{" + segment[1:] + "}"
+		}
+	}
+
+	return method + " " + strings.Join(segments, "/")
+}
@@ -0,0 +1,14 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// City model struct
+type City struct {
+	ID        uuid.UUID
+	Name      string
+	CreatedAt time.Time
+}
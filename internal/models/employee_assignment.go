@@ -0,0 +1,14 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Employee to pvz assignment model struct
+type EmployeeAssignment struct {
+	UserID     uuid.UUID
+	PvzID      uuid.UUID
+	AssignedAt time.Time
+}
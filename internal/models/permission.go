@@ -0,0 +1,83 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Permission to perform a group of operations
+type Permission string
+
+const (
+	PermCitiesRead         Permission = "cities:read"
+	PermCitiesManage       Permission = "cities:manage"
+	PermProductTypesRead   Permission = "product_types:read"
+	PermProductTypesManage Permission = "product_types:manage"
+	PermPVZRead            Permission = "pvz:read"
+	PermPVZManage          Permission = "pvz:manage"
+	PermEmployeesManage    Permission = "employees:manage"
+	PermUsersManage        Permission = "users:manage"
+	PermReceptionsRead     Permission = "receptions:read"
+	PermReceptionsWrite    Permission = "receptions:write"
+	PermReceptionsHistory  Permission = "receptions:history"
+	PermReceptionsReopen   Permission = "receptions:reopen"
+	PermProductsRead       Permission = "products:read"
+	PermProductsHandle     Permission = "products:handle"
+	PermPermissionsManage  Permission = "permissions:manage"
+)
+
+// Every known permission
+var Permissions = []Permission{
+	PermCitiesRead,
+	PermCitiesManage,
+	PermProductTypesRead,
+	PermProductTypesManage,
+	PermPVZRead,
+	PermPVZManage,
+	PermEmployeesManage,
+	PermUsersManage,
+	PermReceptionsRead,
+	PermReceptionsWrite,
+	PermReceptionsHistory,
+	PermReceptionsReopen,
+	PermProductsRead,
+	PermProductsHandle,
+	PermPermissionsManage,
+}
+
+// Permissions every user of the role has
+var RolePermissions = map[string][]Permission{
+	"employee": {
+		PermCitiesRead,
+		PermProductTypesRead,
+		PermPVZRead,
+		PermReceptionsRead,
+		PermReceptionsWrite,
+		PermProductsRead,
+		PermProductsHandle,
+	},
+	"moderator": {
+		PermCitiesRead,
+		PermCitiesManage,
+		PermProductTypesRead,
+		PermProductTypesManage,
+		PermPVZRead,
+		PermPVZManage,
+		PermEmployeesManage,
+		PermUsersManage,
+		PermReceptionsRead,
+		PermReceptionsHistory,
+		PermReceptionsReopen,
+		PermProductsRead,
+	},
+	"admin": Permissions,
+}
+
+// Permission granted to a user on top of the permissions of the role
+type PermissionGrant struct {
+	UserID     uuid.UUID
+	Permission Permission
+	GrantedBy  *uuid.UUID
+	GrantedAt  time.Time
+}
@@ -8,8 +8,36 @@ import (
 
 // Product model struct
 type Product struct {
-	ID          uuid.UUID
-	Type        string
-	DateTime    time.Time
-	ReceptionID uuid.UUID
+	ID                uuid.UUID
+	Type              string
+	DateTime          time.Time
+	ReceptionID       uuid.UUID
+	LineNumber        int
+	CreatedBy         *uuid.UUID
+	Barcode           *string
+	Status            string
+	PickupCodeHash    *string
+	PickupAttempts    int
+	PickupLockedUntil *time.Time
+	CellID            *uuid.UUID
+	CellNumber        *int
+}
+
+// Result of adding one product of the batch struct
+type ProductBatchResult struct {
+	Product *Product
+	Err     error
+}
+
+// Page of reception products struct
+type ProductPage struct {
+	Products   []Product
+	NextCursor *int
+}
+
+// Product with its reception and pvz struct
+type ProductLocation struct {
+	Product   Product
+	Reception Reception
+	PVZ       PVZ
 }
@@ -0,0 +1,22 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Product transfer between pvzs model struct
+type ProductTransfer struct {
+	ID                 uuid.UUID
+	ProductID          uuid.UUID
+	Type               string
+	Barcode            *string
+	FromPvzID          uuid.UUID
+	ToPvzID            uuid.UUID
+	Status             string
+	ReceptionID        *uuid.UUID
+	DeliveredProductID *uuid.UUID
+	CreatedBy          *uuid.UUID
+	CreatedAt          time.Time
+}
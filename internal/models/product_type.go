@@ -0,0 +1,15 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Product type model struct
+type ProductType struct {
+	ID          uuid.UUID
+	Name        string
+	DisplayName string
+	RetiredAt   *time.Time
+}
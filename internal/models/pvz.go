@@ -11,6 +11,26 @@ type PVZ struct {
 	ID               uuid.UUID
 	City             string
 	RegistrationDate time.Time
+	Status           string
+}
+
+// PVZ capacity limits with the current number of products in it struct
+type PVZCapacity struct {
+	PvzID          uuid.UUID
+	Capacity       *int
+	ReceptionLimit *int
+	Stored         int
+}
+
+// PVZ with its open reception, counters and transfers in transit struct
+type PVZDetails struct {
+	PVZ                        PVZ
+	OpenReception              *Reception
+	OpenReceptionProductsCount int
+	ReceptionsCount            int
+	ProductsCount              int
+	InboundTransfers           []ProductTransfer
+	OutboundTransfers          []ProductTransfer
 }
 
 // PVZ with receptions struct
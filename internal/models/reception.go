@@ -8,14 +8,60 @@ import (
 
 // Reception model struct
 type Reception struct {
-	ID       uuid.UUID
-	DateTime time.Time
-	PvzID    uuid.UUID
-	Status   string
+	ID                   uuid.UUID
+	DateTime             time.Time
+	PvzID                uuid.UUID
+	Status               string
+	CreatedBy            *uuid.UUID
+	ClosedBy             *uuid.UUID
+	AutoClosed           bool
+	Manifest             []ManifestItem
+	Discrepancies        []Discrepancy
+	ExpectedBarcodes     []ManifestBarcode
+	BarcodeDiscrepancies []BarcodeDiscrepancy
+}
+
+// Expected manifest item struct
+type ManifestItem struct {
+	Type  string
+	Count int
+}
+
+// Manifest discrepancy struct
+type Discrepancy struct {
+	Type     string
+	Kind     string
+	Expected int
+	Actual   int
+}
+
+// Expected product of the barcode manifest struct
+type ManifestBarcode struct {
+	Barcode string
+	Type    string
+}
+
+// Barcode manifest discrepancy struct
+type BarcodeDiscrepancy struct {
+	Barcode      string
+	Kind         string
+	ExpectedType *string
+	ActualType   *string
+}
+
+// Reception audit record struct
+type ReceptionAuditRecord struct {
+	ID          uuid.UUID
+	ReceptionID uuid.UUID
+	Action      string
+	Reason      string
+	UserID      *uuid.UUID
+	CreatedAt   time.Time
 }
 
 // Reception with products struct
 type ReceptionWithProducts struct {
-	Reception Reception
-	Products  []*Product
+	Reception     Reception
+	Products      []*Product
+	ProductsCount int
 }
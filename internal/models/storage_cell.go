@@ -0,0 +1,25 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Storage cell model struct
+type StorageCell struct {
+	ID        uuid.UUID
+	PvzID     uuid.UUID
+	Number    int
+	Capacity  int
+	Occupied  int
+	CreatedAt time.Time
+}
+
+// Storage cells of the pvz with their occupancy struct
+type CellOccupancy struct {
+	Cells      []StorageCell
+	Capacity   int
+	Occupied   int
+	Unassigned int
+}
@@ -0,0 +1,23 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Refresh token model struct, only the token hash is stored
+type RefreshToken struct {
+	ID        uuid.UUID
+	UserID    uuid.UUID
+	UserRole  string
+	TokenHash string
+	ExpiresAt time.Time
+	RevokedAt *time.Time
+}
+
+// Access and refresh token pair issued on login and refresh
+type TokenPair struct {
+	AccessToken  string
+	RefreshToken string
+}
@@ -1,11 +1,18 @@
 package models
 
-import "github.com/google/uuid"
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
 
 // User model struct
 type User struct {
-	ID           uuid.UUID
-	Email        string
-	PasswordHash string
-	Role         string
+	ID            uuid.UUID
+	Email         string
+	PasswordHash  string
+	Role          string
+	CreatedAt     time.Time
+	LastLoginAt   *time.Time
+	DeactivatedAt *time.Time
 }
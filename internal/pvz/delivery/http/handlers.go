@@ -4,6 +4,8 @@ import (
 	"errors"
 	"fmt"
 	"net/http"
+	"strings"
+	"unicode/utf8"
 
 	"github.com/google/uuid"
 	"github.com/labstack/echo/v4"
@@ -21,6 +23,54 @@ import (
 	"github.com/cyansnbrst/pvz-service/pkg/metric"
 )
 
+// Max lengths of the text fields accepted from clients
+const (
+	maxCityNameLength        = 50
+	maxTypeNameLength        = 50
+	maxTypeDisplayNameLength = 100
+	maxReopenReasonLength    = 500
+	maxBarcodeLength         = 64
+)
+
+// Max number of items in a single batch, transfer, cells or barcode manifest request
+const maxBatchSize = 1000
+
+// Ways a product can be issued to the customer, used as the metric label
+const (
+	issueMethodManual     = "manual"
+	issueMethodPickupCode = "pickup_code"
+)
+
+// Reasons a login can fail for, used as the metric label
+const (
+	loginFailureInvalidCredentials = "invalid_credentials"
+	loginFailureLocked             = "locked"
+	loginFailureDeactivated        = "deactivated"
+)
+
+// PVZ statuses that can be set by a moderator
+var allowedPVZStatuses = map[pvzapi.PVZStatus]bool{
+	pvzapi.Active:    true,
+	pvzapi.Suspended: true,
+	pvzapi.Closed:    true,
+}
+
+// Roles a user can be given or filtered by
+var userRoles = map[pvzapi.UserRole]bool{
+	pvzapi.UserRoleEmployee:  true,
+	pvzapi.UserRoleModerator: true,
+	pvzapi.UserRoleAdmin:     true,
+}
+
+// Product statuses the pvz list can be filtered by
+var productStatuses = map[pvzapi.ProductStatus]bool{
+	pvzapi.Received:    true,
+	pvzapi.Stored:      true,
+	pvzapi.Issued:      true,
+	pvzapi.Returned:    true,
+	pvzapi.Transferred: true,
+}
+
 // PVZ handlers struct
 type pvzHandlers struct {
 	pvzUC   pvz.UseCase
@@ -53,7 +103,7 @@ func (h *pvzHandlers) PostDummyLogin(c echo.Context) error {
 		return hh.BadRequestResponse(c, usecase.ErrInvalidRole)
 	}
 
-	tokenStr, err := h.pvzUC.GenerateJWT(c.Request().Context(), pvzapi.UserRole(req.Role))
+	tokenStr, err := h.pvzUC.GenerateJWT(c.Request().Context(), uuid.Nil, pvzapi.UserRole(req.Role))
 	if err != nil {
 		return hh.ServerErrorResponse(c, h.logger, err)
 	}
@@ -63,6 +113,18 @@ func (h *pvzHandlers) PostDummyLogin(c echo.Context) error {
 	return c.JSON(http.StatusOK, resp)
 }
 
+// Get the public keys that verify issued tokens
+func (h *pvzHandlers) GetWellKnownJwksJson(c echo.Context) error {
+	keys, err := h.pvzUC.GetJWKS(c.Request().Context())
+	if err != nil {
+		return hh.ServerErrorResponse(c, h.logger, err)
+	}
+
+	resp := converters.ToResponseJWKS(keys)
+
+	return c.JSON(http.StatusOK, resp)
+}
+
 // Register user with the desired role
 func (h *pvzHandlers) PostRegister(c echo.Context) error {
 	var req pvzapi.PostRegisterJSONRequestBody
@@ -104,28 +166,87 @@ func (h *pvzHandlers) PostLogin(c echo.Context) error {
 		return hh.BadRequestResponse(c, fmt.Errorf("missing field(s)"))
 	}
 
-	tokenStr, err := h.pvzUC.Login(c.Request().Context(), string(req.Email), req.Password)
+	tokens, err := h.pvzUC.Login(c.Request().Context(), string(req.Email), req.Password, c.RealIP())
 	if err != nil {
-		if errors.Is(err, db.ErrUserNotFound) || errors.Is(err, usecase.ErrIncorrectPassword) {
-			return hh.BadRequestResponse(c, err)
+		if errors.Is(err, usecase.ErrInvalidCredentials) {
+			if h.metrics != nil {
+				h.metrics.IncLoginFailures(loginFailureInvalidCredentials)
+			}
+			return hh.UnauthorizedResponse(c, err)
+		}
+		if errors.Is(err, usecase.ErrLoginLocked) {
+			if h.metrics != nil {
+				h.metrics.IncLoginFailures(loginFailureLocked)
+			}
+			return hh.TooManyRequestsResponse(c, err)
+		}
+		if errors.Is(err, usecase.ErrUserDeactivated) {
+			if h.metrics != nil {
+				h.metrics.IncLoginFailures(loginFailureDeactivated)
+			}
+			return hh.AccessDeniedResponse(c)
 		}
 		return hh.ServerErrorResponse(c, h.logger, err)
 	}
 
-	return c.JSON(http.StatusOK, map[string]string{"token": tokenStr})
+	resp := &dtos.TokenPair{Value: tokens.AccessToken, RefreshToken: tokens.RefreshToken}
+
+	return c.JSON(http.StatusOK, resp)
 }
 
-// Create a new PVZ (moderator only)
-func (h *pvzHandlers) PostPvz(c echo.Context) error {
-	role, err := middleware.ContextGetUserRole(c)
+// Exchange a refresh token for a new token pair
+func (h *pvzHandlers) PostTokenRefresh(c echo.Context) error {
+	var req pvzapi.PostTokenRefreshJSONRequestBody
+
+	if err := c.Bind(&req); err != nil {
+		return hh.BadRequestResponse(c, err)
+	}
+
+	if req.RefreshToken == "" {
+		return hh.BadRequestResponse(c, fmt.Errorf("missing field(s)"))
+	}
+
+	tokens, err := h.pvzUC.RefreshTokens(c.Request().Context(), req.RefreshToken)
+	if err != nil {
+		if errors.Is(err, usecase.ErrInvalidRefreshToken) {
+			return hh.UnauthorizedResponse(c, err)
+		}
+		return hh.ServerErrorResponse(c, h.logger, err)
+	}
+
+	resp := &dtos.TokenPair{Value: tokens.AccessToken, RefreshToken: tokens.RefreshToken}
+
+	return c.JSON(http.StatusOK, resp)
+}
+
+// Revoke the current access token and the given refresh token
+func (h *pvzHandlers) PostLogout(c echo.Context) error {
+	userID, err := middleware.ContextGetUserID(c)
+	if err != nil {
+		return hh.ServerErrorResponse(c, h.logger, err)
+	}
+
+	tokenID, expiresAt, err := middleware.ContextGetToken(c)
 	if err != nil {
 		return hh.ServerErrorResponse(c, h.logger, err)
 	}
 
-	if role != pvzapi.UserRoleModerator {
-		return hh.AccessDeniedResponse(c)
+	var req pvzapi.PostLogoutJSONRequestBody
+
+	if err := c.Bind(&req); err != nil {
+		return hh.BadRequestResponse(c, err)
+	}
+
+	err = h.pvzUC.Logout(c.Request().Context(), userID, tokenID, expiresAt, req.RefreshToken)
+	if err != nil {
+		return hh.ServerErrorResponse(c, h.logger, err)
 	}
 
+	return c.NoContent(http.StatusNoContent)
+}
+
+// Create a new PVZ
+func (h *pvzHandlers) PostPvz(c echo.Context) error {
 	var req pvzapi.PostPvzJSONRequestBody
 
 	if err := c.Bind(&req); err != nil {
@@ -136,42 +257,218 @@ func (h *pvzHandlers) PostPvz(c echo.Context) error {
 		return hh.BadRequestResponse(c, fmt.Errorf("missing field(s)"))
 	}
 
-	allowedCities := map[pvzapi.PVZCity]bool{
-		pvzapi.Казань:         true,
-		pvzapi.Москва:         true,
-		pvzapi.СанктПетербург: true,
+	pvz, err := h.pvzUC.CreatePVZ(c.Request().Context(), req.Id, req.City, req.RegistrationDate)
+	if err != nil {
+		if errors.Is(err, db.ErrDuplicatePVZ) || errors.Is(err, usecase.ErrInvalidCity) {
+			return hh.BadRequestResponse(c, err)
+		}
+		return hh.ServerErrorResponse(c, h.logger, err)
+	}
+
+	if h.metrics != nil {
+		h.metrics.IncPVZCreated()
+	}
+
+	resp := converters.ToResponsePVZ(pvz)
+
+	return c.JSON(http.StatusCreated, resp)
+}
+
+// Update city or status of the PVZ
+func (h *pvzHandlers) PatchPvzPvzId(c echo.Context, pvzID openapi_types.UUID) error {
+	var req pvzapi.PatchPvzPvzIdJSONRequestBody
+
+	if err := c.Bind(&req); err != nil {
+		return hh.BadRequestResponse(c, err)
+	}
+
+	if req.City == nil && req.Status == nil {
+		return hh.BadRequestResponse(c, fmt.Errorf("missing field(s)"))
+	}
+
+	var status *string
+	if req.Status != nil {
+		if !allowedPVZStatuses[*req.Status] {
+			return hh.BadRequestResponse(c, usecase.ErrInvalidStatus)
+		}
+		s := string(*req.Status)
+		status = &s
+	}
+
+	pvz, err := h.pvzUC.UpdatePVZ(c.Request().Context(), pvzID, req.City, status)
+	if err != nil {
+		if errors.Is(err, db.ErrPVZNotFound) {
+			return hh.NotFoundResponse(c)
+		}
+		if errors.Is(err, usecase.ErrInvalidCity) {
+			return hh.BadRequestResponse(c, err)
+		}
+		return hh.ServerErrorResponse(c, h.logger, err)
+	}
+
+	resp := converters.ToResponsePVZ(pvz)
+
+	return c.JSON(http.StatusOK, resp)
+}
+
+// Get a list of cities
+func (h *pvzHandlers) GetCities(c echo.Context) error {
+	cities, err := h.pvzUC.GetCities(c.Request().Context())
+	if err != nil {
+		return hh.ServerErrorResponse(c, h.logger, err)
+	}
+
+	resp := make([]pvzapi.City, len(cities))
+	for i, city := range cities {
+		resp[i] = converters.ToResponseCity(city)
+	}
+
+	return c.JSON(http.StatusOK, resp)
+}
+
+// Add a new city
+func (h *pvzHandlers) PostCities(c echo.Context) error {
+	var req pvzapi.PostCitiesJSONRequestBody
+
+	if err := c.Bind(&req); err != nil {
+		return hh.BadRequestResponse(c, err)
+	}
+
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		return hh.BadRequestResponse(c, fmt.Errorf("missing field(s)"))
 	}
 
-	if !allowedCities[req.City] {
+	if utf8.RuneCountInString(name) > maxCityNameLength {
 		return hh.BadRequestResponse(c, usecase.ErrInvalidCity)
 	}
 
-	pvz, err := h.pvzUC.CreatePVZ(c.Request().Context(), req.Id, string(req.City), req.RegistrationDate)
+	city, err := h.pvzUC.CreateCity(c.Request().Context(), name)
 	if err != nil {
-		if errors.Is(err, db.ErrDuplicatePVZ) {
+		if errors.Is(err, db.ErrDuplicateCity) {
 			return hh.BadRequestResponse(c, err)
 		}
 		return hh.ServerErrorResponse(c, h.logger, err)
 	}
 
-	if h.metrics != nil {
-		h.metrics.IncPVZCreated()
+	resp := converters.ToResponseCity(city)
+
+	return c.JSON(http.StatusCreated, resp)
+}
+
+// Rename the city
+func (h *pvzHandlers) PatchCitiesCityId(c echo.Context, cityID openapi_types.UUID) error {
+	var req pvzapi.PatchCitiesCityIdJSONRequestBody
+
+	if err := c.Bind(&req); err != nil {
+		return hh.BadRequestResponse(c, err)
 	}
 
-	resp := converters.ToResponsePVZ(pvz)
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		return hh.BadRequestResponse(c, fmt.Errorf("missing field(s)"))
+	}
+
+	if utf8.RuneCountInString(name) > maxCityNameLength {
+		return hh.BadRequestResponse(c, usecase.ErrInvalidCity)
+	}
+
+	city, err := h.pvzUC.UpdateCity(c.Request().Context(), cityID, name)
+	if err != nil {
+		if errors.Is(err, db.ErrCityNotFound) {
+			return hh.NotFoundResponse(c)
+		}
+		if errors.Is(err, db.ErrDuplicateCity) {
+			return hh.BadRequestResponse(c, err)
+		}
+		return hh.ServerErrorResponse(c, h.logger, err)
+	}
+
+	resp := converters.ToResponseCity(city)
+
+	return c.JSON(http.StatusOK, resp)
+}
+
+// Delete the city
+func (h *pvzHandlers) DeleteCitiesCityId(c echo.Context, cityID openapi_types.UUID) error {
+	err := h.pvzUC.DeleteCity(c.Request().Context(), cityID)
+	if err != nil {
+		if errors.Is(err, db.ErrCityNotFound) {
+			return hh.NotFoundResponse(c)
+		}
+		if errors.Is(err, db.ErrCityInUse) {
+			return hh.BadRequestResponse(c, err)
+		}
+		return hh.ServerErrorResponse(c, h.logger, err)
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+// Get the product type catalog
+func (h *pvzHandlers) GetProductTypes(c echo.Context) error {
+	types, err := h.pvzUC.GetProductTypes(c.Request().Context())
+	if err != nil {
+		return hh.ServerErrorResponse(c, h.logger, err)
+	}
+
+	resp := make([]pvzapi.ProductType, len(types))
+	for i, t := range types {
+		resp[i] = converters.ToResponseProductType(t)
+	}
+
+	return c.JSON(http.StatusOK, resp)
+}
+
+// Add a new product type
+func (h *pvzHandlers) PostProductTypes(c echo.Context) error {
+	var req pvzapi.PostProductTypesJSONRequestBody
+
+	if err := c.Bind(&req); err != nil {
+		return hh.BadRequestResponse(c, err)
+	}
+
+	name := strings.TrimSpace(req.Name)
+	displayName := strings.TrimSpace(req.DisplayName)
+	if name == "" || displayName == "" {
+		return hh.BadRequestResponse(c, fmt.Errorf("missing field(s)"))
+	}
+
+	if utf8.RuneCountInString(name) > maxTypeNameLength || utf8.RuneCountInString(displayName) > maxTypeDisplayNameLength {
+		return hh.BadRequestResponse(c, usecase.ErrInvalidType)
+	}
+
+	productType, err := h.pvzUC.CreateProductType(c.Request().Context(), name, displayName)
+	if err != nil {
+		if errors.Is(err, db.ErrDuplicateType) {
+			return hh.BadRequestResponse(c, err)
+		}
+		return hh.ServerErrorResponse(c, h.logger, err)
+	}
+
+	resp := converters.ToResponseProductType(productType)
 
 	return c.JSON(http.StatusCreated, resp)
 }
 
-// Create a new reception (employee only)
-func (h *pvzHandlers) PostReceptions(c echo.Context) error {
-	role, err := middleware.ContextGetUserRole(c)
+// Retire the product type
+func (h *pvzHandlers) DeleteProductTypesTypeId(c echo.Context, typeID openapi_types.UUID) error {
+	err := h.pvzUC.RetireProductType(c.Request().Context(), typeID)
 	if err != nil {
+		if errors.Is(err, db.ErrTypeNotFound) {
+			return hh.NotFoundResponse(c)
+		}
 		return hh.ServerErrorResponse(c, h.logger, err)
 	}
 
-	if role != pvzapi.UserRoleEmployee {
-		return hh.AccessDeniedResponse(c)
+	return c.NoContent(http.StatusNoContent)
+}
+
+// Create a new reception
+func (h *pvzHandlers) PostReceptions(c echo.Context) error {
+	userID, err := middleware.ContextGetUserID(c)
+	if err != nil {
+		return hh.ServerErrorResponse(c, h.logger, err)
 	}
 
 	var req pvzapi.PostReceptionsJSONRequestBody
@@ -184,9 +481,37 @@ func (h *pvzHandlers) PostReceptions(c echo.Context) error {
 		return hh.BadRequestResponse(c, fmt.Errorf("missing field(s)"))
 	}
 
-	reception, err := h.pvzUC.CreateReception(c.Request().Context(), req.PvzId)
+	manifest := converters.ToManifest(req.Manifest)
+	for _, item := range manifest {
+		if item.Type == "" {
+			return hh.BadRequestResponse(c, fmt.Errorf("missing field(s)"))
+		}
+	}
+
+	barcodes := converters.ToManifestBarcodes(req.ExpectedBarcodes)
+	if len(barcodes) > maxBatchSize {
+		return hh.BadRequestResponse(c, fmt.Errorf("too many expected barcodes, max %d", maxBatchSize))
+	}
+
+	for i := range barcodes {
+		barcodes[i].Barcode = strings.TrimSpace(barcodes[i].Barcode)
+		if barcodes[i].Barcode == "" || utf8.RuneCountInString(barcodes[i].Barcode) > maxBarcodeLength {
+			return hh.BadRequestResponse(c, usecase.ErrInvalidBarcode)
+		}
+		if barcodes[i].Type == "" {
+			return hh.BadRequestResponse(c, fmt.Errorf("missing field(s)"))
+		}
+	}
+
+	reception, err := h.pvzUC.CreateReception(c.Request().Context(), userID, req.PvzId, manifest, barcodes)
 	if err != nil {
-		if errors.Is(err, db.ErrReceptionConflict) {
+		if errors.Is(err, usecase.ErrPVZAccessDenied) {
+			return hh.AccessDeniedResponse(c)
+		}
+		if errors.Is(err, usecase.ErrInvalidManifest) || errors.Is(err, usecase.ErrInvalidType) {
+			return hh.BadRequestResponse(c, err)
+		}
+		if errors.Is(err, db.ErrReceptionConflict) || errors.Is(err, db.ErrPVZNotActive) {
 			return hh.BadRequestResponse(c, err)
 		}
 		return hh.ServerErrorResponse(c, h.logger, err)
@@ -201,17 +526,13 @@ func (h *pvzHandlers) PostReceptions(c echo.Context) error {
 	return c.JSON(http.StatusCreated, resp)
 }
 
-// Add a product to the reception (employee only)
+// Add a product to the reception
 func (h *pvzHandlers) PostProducts(c echo.Context) error {
-	role, err := middleware.ContextGetUserRole(c)
+	userID, err := middleware.ContextGetUserID(c)
 	if err != nil {
 		return hh.ServerErrorResponse(c, h.logger, err)
 	}
 
-	if role != pvzapi.UserRoleEmployee {
-		return hh.AccessDeniedResponse(c)
-	}
-
 	var req pvzapi.PostProductsJSONRequestBody
 
 	if err := c.Bind(&req); err != nil {
@@ -222,21 +543,31 @@ func (h *pvzHandlers) PostProducts(c echo.Context) error {
 		return hh.BadRequestResponse(c, fmt.Errorf("missing field(s)"))
 	}
 
-	allowedTypes := map[pvzapi.PostProductsJSONBodyType]bool{
-		pvzapi.PostProductsJSONBodyTypeОбувь:       true,
-		pvzapi.PostProductsJSONBodyTypeОдежда:      true,
-		pvzapi.PostProductsJSONBodyTypeЭлектроника: true,
+	if req.Barcode != nil {
+		barcode := strings.TrimSpace(*req.Barcode)
+		if barcode == "" || utf8.RuneCountInString(barcode) > maxBarcodeLength {
+			return hh.BadRequestResponse(c, usecase.ErrInvalidBarcode)
+		}
+		req.Barcode = &barcode
 	}
 
-	if !allowedTypes[req.Type] {
-		return hh.BadRequestResponse(c, usecase.ErrInvalidType)
+	if req.CellNumber != nil && *req.CellNumber < 1 {
+		return hh.BadRequestResponse(c, usecase.ErrInvalidCellNumber)
 	}
 
-	product, err := h.pvzUC.AddProduct(c.Request().Context(), req.PvzId, string(req.Type))
+	product, err := h.pvzUC.AddProduct(c.Request().Context(), userID, req.PvzId, req.Type, req.Barcode, req.CellNumber)
 	if err != nil {
-		if errors.Is(err, db.ErrNoOpenReception) {
+		if errors.Is(err, usecase.ErrPVZAccessDenied) {
+			return hh.AccessDeniedResponse(c)
+		}
+		if errors.Is(err, db.ErrNoOpenReception) || errors.Is(err, db.ErrPVZNotActive) ||
+			errors.Is(err, usecase.ErrInvalidType) || errors.Is(err, db.ErrDuplicateBarcode) ||
+			errors.Is(err, db.ErrCellNotFound) || errors.Is(err, db.ErrCellFull) {
 			return hh.BadRequestResponse(c, err)
 		}
+		if errors.Is(err, db.ErrCapacityExceeded) || errors.Is(err, db.ErrReceptionLimit) {
+			return hh.ConflictResponse(c, err)
+		}
 		return hh.ServerErrorResponse(c, h.logger, err)
 	}
 
@@ -249,75 +580,810 @@ func (h *pvzHandlers) PostProducts(c echo.Context) error {
 	return c.JSON(http.StatusCreated, resp)
 }
 
-// Delete last product from the reception
-func (h *pvzHandlers) PostPvzPvzIdDeleteLastProduct(c echo.Context, uuid openapi_types.UUID) error {
-	role, err := middleware.ContextGetUserRole(c)
+// Add a batch of products to the reception
+func (h *pvzHandlers) PostProductsBatch(c echo.Context) error {
+	userID, err := middleware.ContextGetUserID(c)
 	if err != nil {
 		return hh.ServerErrorResponse(c, h.logger, err)
 	}
 
-	if role != pvzapi.UserRoleEmployee {
-		return hh.AccessDeniedResponse(c)
+	var req pvzapi.PostProductsBatchJSONRequestBody
+
+	if err := c.Bind(&req); err != nil {
+		return hh.BadRequestResponse(c, err)
+	}
+
+	if req.PvzId == uuid.Nil || len(req.Items) == 0 {
+		return hh.BadRequestResponse(c, fmt.Errorf("missing field(s)"))
+	}
+
+	if len(req.Items) > maxBatchSize {
+		return hh.BadRequestResponse(c, fmt.Errorf("too many items in the batch, max %d", maxBatchSize))
+	}
+
+	for i, item := range req.Items {
+		if item.Type == "" {
+			return hh.BadRequestResponse(c, fmt.Errorf("missing field(s)"))
+		}
+
+		if item.Barcode != nil {
+			barcode := strings.TrimSpace(*item.Barcode)
+			if barcode == "" || utf8.RuneCountInString(barcode) > maxBarcodeLength {
+				return hh.BadRequestResponse(c, usecase.ErrInvalidBarcode)
+			}
+			req.Items[i].Barcode = &barcode
+		}
+
+		if item.CellNumber != nil && *item.CellNumber < 1 {
+			return hh.BadRequestResponse(c, usecase.ErrInvalidCellNumber)
+		}
 	}
 
-	err = h.pvzUC.DeleteLastProduct(c.Request().Context(), uuid)
+	results, err := h.pvzUC.AddProducts(c.Request().Context(), userID, req.PvzId, converters.ToBatchProducts(req.Items))
 	if err != nil {
-		if errors.Is(err, db.ErrNoOpenReception) || errors.Is(err, db.ErrNoProducts) {
+		if errors.Is(err, usecase.ErrPVZAccessDenied) {
+			return hh.AccessDeniedResponse(c)
+		}
+		if errors.Is(err, db.ErrNoOpenReception) || errors.Is(err, db.ErrPVZNotActive) || errors.Is(err, usecase.ErrInvalidType) {
 			return hh.BadRequestResponse(c, err)
 		}
 		return hh.ServerErrorResponse(c, h.logger, err)
 	}
 
-	return c.NoContent(http.StatusOK)
+	resp := make([]pvzapi.ProductBatchResult, len(results))
+	for i, result := range results {
+		if result.Product != nil && h.metrics != nil {
+			h.metrics.IncProductsAdded()
+		}
+		resp[i] = converters.ToResponseProductBatchResult(result)
+	}
+
+	return c.JSON(http.StatusOK, resp)
 }
 
-// Close last reception for the pvz
-func (h *pvzHandlers) PostPvzPvzIdCloseLastReception(c echo.Context, uuid openapi_types.UUID) error {
-	role, err := middleware.ContextGetUserRole(c)
+// Issue the stored product to the customer
+func (h *pvzHandlers) PostProductsProductIdIssue(c echo.Context, productID openapi_types.UUID) error {
+	userID, err := middleware.ContextGetUserID(c)
 	if err != nil {
 		return hh.ServerErrorResponse(c, h.logger, err)
 	}
 
-	if role != pvzapi.UserRoleEmployee {
-		return hh.AccessDeniedResponse(c)
-	}
-
-	reception, err := h.pvzUC.CloseLastReception(c.Request().Context(), uuid)
+	product, err := h.pvzUC.IssueProduct(c.Request().Context(), userID, productID)
 	if err != nil {
-		if errors.Is(err, db.ErrNoOpenReception) {
+		if errors.Is(err, usecase.ErrPVZAccessDenied) {
+			return hh.AccessDeniedResponse(c)
+		}
+		if errors.Is(err, db.ErrProductNotFound) {
+			return hh.NotFoundResponse(c)
+		}
+		if errors.Is(err, db.ErrInvalidTransition) {
 			return hh.BadRequestResponse(c, err)
 		}
 		return hh.ServerErrorResponse(c, h.logger, err)
 	}
 
-	resp := converters.ToResponseReception(reception)
+	if h.metrics != nil {
+		h.metrics.IncProductsIssued(issueMethodManual)
+	}
 
-	return c.JSON(http.StatusOK, resp)
+	return c.JSON(http.StatusOK, converters.ToResponseProduct(product))
 }
 
-// Get a list of pvzs
-func (h *pvzHandlers) GetPvz(c echo.Context, params pvzapi.GetPvzParams) error {
-	role, err := middleware.ContextGetUserRole(c)
+// Generate a new pickup code for the stored product
+func (h *pvzHandlers) PostProductsProductIdPickupCode(c echo.Context, productID openapi_types.UUID) error {
+	userID, err := middleware.ContextGetUserID(c)
 	if err != nil {
 		return hh.ServerErrorResponse(c, h.logger, err)
 	}
 
-	if role != pvzapi.UserRoleEmployee && role != pvzapi.UserRoleModerator {
-		return hh.AccessDeniedResponse(c)
-	}
-
-	pvzs, err := h.pvzUC.GetPVZs(c.Request().Context(), params)
+	code, err := h.pvzUC.GeneratePickupCode(c.Request().Context(), userID, productID)
 	if err != nil {
-		if errors.Is(err, usecase.ErrInvalidDateRange) {
+		if errors.Is(err, usecase.ErrPVZAccessDenied) {
+			return hh.AccessDeniedResponse(c)
+		}
+		if errors.Is(err, db.ErrProductNotFound) {
+			return hh.NotFoundResponse(c)
+		}
+		if errors.Is(err, db.ErrInvalidTransition) {
 			return hh.BadRequestResponse(c, err)
 		}
 		return hh.ServerErrorResponse(c, h.logger, err)
 	}
 
-	resp := make([]dtos.PVZWithReceptions, len(pvzs))
-	for i, pvz := range pvzs {
-		resp[i] = converters.ToResponsePVZWithReceptions(pvz)
+	return c.JSON(http.StatusCreated, pvzapi.PickupCode{Code: code})
+}
+
+// Issue the stored product by its pickup code
+func (h *pvzHandlers) PostProductsProductIdPickup(c echo.Context, productID openapi_types.UUID) error {
+	userID, err := middleware.ContextGetUserID(c)
+	if err != nil {
+		return hh.ServerErrorResponse(c, h.logger, err)
 	}
 
-	return c.JSON(http.StatusOK, resp)
+	var req pvzapi.PostProductsProductIdPickupJSONRequestBody
+
+	if err := c.Bind(&req); err != nil {
+		return hh.BadRequestResponse(c, err)
+	}
+
+	code := strings.TrimSpace(req.Code)
+	if code == "" {
+		return hh.BadRequestResponse(c, fmt.Errorf("missing field(s)"))
+	}
+
+	product, err := h.pvzUC.PickupProduct(c.Request().Context(), userID, productID, code)
+	if err != nil {
+		if errors.Is(err, usecase.ErrPVZAccessDenied) {
+			return hh.AccessDeniedResponse(c)
+		}
+		if errors.Is(err, db.ErrProductNotFound) {
+			return hh.NotFoundResponse(c)
+		}
+		if errors.Is(err, usecase.ErrPickupLocked) {
+			return hh.TooManyRequestsResponse(c, err)
+		}
+		if errors.Is(err, usecase.ErrInvalidPickupCode) || errors.Is(err, db.ErrNoPickupCode) ||
+			errors.Is(err, db.ErrInvalidTransition) {
+			return hh.BadRequestResponse(c, err)
+		}
+		return hh.ServerErrorResponse(c, h.logger, err)
+	}
+
+	if h.metrics != nil {
+		h.metrics.IncProductsIssued(issueMethodPickupCode)
+	}
+
+	return c.JSON(http.StatusOK, converters.ToResponseProduct(product))
+}
+
+// Return the product to the sender
+func (h *pvzHandlers) PostProductsProductIdReturn(c echo.Context, productID openapi_types.UUID) error {
+	userID, err := middleware.ContextGetUserID(c)
+	if err != nil {
+		return hh.ServerErrorResponse(c, h.logger, err)
+	}
+
+	product, err := h.pvzUC.ReturnProduct(c.Request().Context(), userID, productID)
+	if err != nil {
+		if errors.Is(err, usecase.ErrPVZAccessDenied) {
+			return hh.AccessDeniedResponse(c)
+		}
+		if errors.Is(err, db.ErrProductNotFound) {
+			return hh.NotFoundResponse(c)
+		}
+		if errors.Is(err, db.ErrInvalidTransition) {
+			return hh.BadRequestResponse(c, err)
+		}
+		return hh.ServerErrorResponse(c, h.logger, err)
+	}
+
+	return c.JSON(http.StatusOK, converters.ToResponseProduct(product))
+}
+
+// Move the product to another storage cell of its pvz
+func (h *pvzHandlers) PostProductsProductIdMove(c echo.Context, productID openapi_types.UUID) error {
+	userID, err := middleware.ContextGetUserID(c)
+	if err != nil {
+		return hh.ServerErrorResponse(c, h.logger, err)
+	}
+
+	var req pvzapi.PostProductsProductIdMoveJSONRequestBody
+
+	if err := c.Bind(&req); err != nil {
+		return hh.BadRequestResponse(c, err)
+	}
+
+	if req.CellNumber < 1 {
+		return hh.BadRequestResponse(c, usecase.ErrInvalidCellNumber)
+	}
+
+	product, err := h.pvzUC.MoveProduct(c.Request().Context(), userID, productID, req.CellNumber)
+	if err != nil {
+		if errors.Is(err, usecase.ErrPVZAccessDenied) {
+			return hh.AccessDeniedResponse(c)
+		}
+		if errors.Is(err, db.ErrProductNotFound) {
+			return hh.NotFoundResponse(c)
+		}
+		if errors.Is(err, db.ErrInvalidTransition) || errors.Is(err, db.ErrCellNotFound) || errors.Is(err, db.ErrCellFull) {
+			return hh.BadRequestResponse(c, err)
+		}
+		return hh.ServerErrorResponse(c, h.logger, err)
+	}
+
+	return c.JSON(http.StatusOK, converters.ToResponseProduct(product))
+}
+
+// Find products by barcode across all pvzs
+func (h *pvzHandlers) GetProducts(c echo.Context, params pvzapi.GetProductsParams) error {
+	barcode := strings.TrimSpace(params.Barcode)
+	if barcode == "" || utf8.RuneCountInString(barcode) > maxBarcodeLength {
+		return hh.BadRequestResponse(c, usecase.ErrInvalidBarcode)
+	}
+
+	locations, err := h.pvzUC.FindProductsByBarcode(c.Request().Context(), barcode)
+	if err != nil {
+		return hh.ServerErrorResponse(c, h.logger, err)
+	}
+
+	resp := make([]pvzapi.ProductLocation, len(locations))
+	for i, location := range locations {
+		resp[i] = converters.ToResponseProductLocation(location)
+	}
+
+	return c.JSON(http.StatusOK, resp)
+}
+
+// Delete last product from the reception
+func (h *pvzHandlers) PostPvzPvzIdDeleteLastProduct(c echo.Context, uuid openapi_types.UUID) error {
+	userID, err := middleware.ContextGetUserID(c)
+	if err != nil {
+		return hh.ServerErrorResponse(c, h.logger, err)
+	}
+
+	err = h.pvzUC.DeleteLastProduct(c.Request().Context(), userID, uuid)
+	if err != nil {
+		if errors.Is(err, usecase.ErrPVZAccessDenied) {
+			return hh.AccessDeniedResponse(c)
+		}
+		if errors.Is(err, db.ErrNoOpenReception) || errors.Is(err, db.ErrNoProducts) {
+			return hh.BadRequestResponse(c, err)
+		}
+		return hh.ServerErrorResponse(c, h.logger, err)
+	}
+
+	return c.NoContent(http.StatusOK)
+}
+
+// Close last reception for the pvz
+func (h *pvzHandlers) PostPvzPvzIdCloseLastReception(c echo.Context, uuid openapi_types.UUID) error {
+	userID, err := middleware.ContextGetUserID(c)
+	if err != nil {
+		return hh.ServerErrorResponse(c, h.logger, err)
+	}
+
+	reception, err := h.pvzUC.CloseLastReception(c.Request().Context(), userID, uuid)
+	if err != nil {
+		if errors.Is(err, usecase.ErrPVZAccessDenied) {
+			return hh.AccessDeniedResponse(c)
+		}
+		if errors.Is(err, db.ErrNoOpenReception) {
+			return hh.BadRequestResponse(c, err)
+		}
+		return hh.ServerErrorResponse(c, h.logger, err)
+	}
+
+	resp := converters.ToResponseReception(reception)
+
+	return c.JSON(http.StatusOK, resp)
+}
+
+// Cancel the last reception with its products
+func (h *pvzHandlers) PostPvzPvzIdCancelLastReception(c echo.Context, pvzID openapi_types.UUID) error {
+	userID, err := middleware.ContextGetUserID(c)
+	if err != nil {
+		return hh.ServerErrorResponse(c, h.logger, err)
+	}
+
+	reception, err := h.pvzUC.CancelLastReception(c.Request().Context(), userID, pvzID)
+	if err != nil {
+		if errors.Is(err, usecase.ErrPVZAccessDenied) {
+			return hh.AccessDeniedResponse(c)
+		}
+		if errors.Is(err, db.ErrNoOpenReception) {
+			return hh.BadRequestResponse(c, err)
+		}
+		return hh.ServerErrorResponse(c, h.logger, err)
+	}
+
+	resp := converters.ToResponseReception(reception)
+
+	return c.JSON(http.StatusOK, resp)
+}
+
+// Get a list of pvzs
+func (h *pvzHandlers) GetPvz(c echo.Context, params pvzapi.GetPvzParams) error {
+	if params.ProductStatus != nil && !productStatuses[*params.ProductStatus] {
+		return hh.BadRequestResponse(c, usecase.ErrInvalidStatus)
+	}
+
+	pvzs, err := h.pvzUC.GetPVZs(c.Request().Context(), params)
+	if err != nil {
+		if errors.Is(err, usecase.ErrInvalidDateRange) {
+			return hh.BadRequestResponse(c, err)
+		}
+		return hh.ServerErrorResponse(c, h.logger, err)
+	}
+
+	if params.ProductCounts != nil && *params.ProductCounts {
+		resp := make([]dtos.PVZWithReceptionCounts, len(pvzs))
+		for i, pvz := range pvzs {
+			resp[i] = converters.ToResponsePVZWithReceptionCounts(pvz)
+		}
+
+		return c.JSON(http.StatusOK, resp)
+	}
+
+	resp := make([]dtos.PVZWithReceptions, len(pvzs))
+	for i, pvz := range pvzs {
+		resp[i] = converters.ToResponsePVZWithReceptions(pvz)
+	}
+
+	return c.JSON(http.StatusOK, resp)
+}
+
+// Get a single pvz with its open reception and counters
+func (h *pvzHandlers) GetPvzPvzId(c echo.Context, pvzID openapi_types.UUID) error {
+	details, err := h.pvzUC.GetPVZ(c.Request().Context(), pvzID)
+	if err != nil {
+		if errors.Is(err, db.ErrPVZNotFound) {
+			return hh.NotFoundResponse(c)
+		}
+		return hh.ServerErrorResponse(c, h.logger, err)
+	}
+
+	resp := converters.ToResponsePVZDetails(&details)
+
+	return c.JSON(http.StatusOK, resp)
+}
+
+// Get a single reception with its products
+func (h *pvzHandlers) GetReceptionsReceptionId(c echo.Context, receptionID openapi_types.UUID) error {
+	reception, err := h.pvzUC.GetReception(c.Request().Context(), receptionID)
+	if err != nil {
+		if errors.Is(err, db.ErrReceptionNotFound) {
+			return hh.NotFoundResponse(c)
+		}
+		return hh.ServerErrorResponse(c, h.logger, err)
+	}
+
+	resp := converters.ToResponseReceptionWithProducts(&reception)
+
+	return c.JSON(http.StatusOK, resp)
+}
+
+// Get the page of reception products
+func (h *pvzHandlers) GetReceptionsReceptionIdProducts(c echo.Context, receptionID openapi_types.UUID, params pvzapi.GetReceptionsReceptionIdProductsParams) error {
+	page, err := h.pvzUC.GetReceptionProducts(c.Request().Context(), receptionID, params)
+	if err != nil {
+		if errors.Is(err, db.ErrReceptionNotFound) {
+			return hh.NotFoundResponse(c)
+		}
+		return hh.ServerErrorResponse(c, h.logger, err)
+	}
+
+	resp := converters.ToResponseProductPage(page)
+
+	return c.JSON(http.StatusOK, resp)
+}
+
+// Get the audit trail of the reception
+func (h *pvzHandlers) GetReceptionsReceptionIdHistory(c echo.Context, receptionID openapi_types.UUID) error {
+	records, err := h.pvzUC.GetReceptionHistory(c.Request().Context(), receptionID)
+	if err != nil {
+		if errors.Is(err, db.ErrReceptionNotFound) {
+			return hh.NotFoundResponse(c)
+		}
+		return hh.ServerErrorResponse(c, h.logger, err)
+	}
+
+	resp := make([]pvzapi.ReceptionAuditRecord, len(records))
+	for i, record := range records {
+		resp[i] = converters.ToResponseReceptionAuditRecord(record)
+	}
+
+	return c.JSON(http.StatusOK, resp)
+}
+
+// Delete the product from the open reception
+func (h *pvzHandlers) DeleteReceptionsReceptionIdProductsProductId(c echo.Context, receptionID, productID openapi_types.UUID) error {
+	userID, err := middleware.ContextGetUserID(c)
+	if err != nil {
+		return hh.ServerErrorResponse(c, h.logger, err)
+	}
+
+	err = h.pvzUC.DeleteProduct(c.Request().Context(), userID, receptionID, productID)
+	if err != nil {
+		if errors.Is(err, usecase.ErrPVZAccessDenied) {
+			return hh.AccessDeniedResponse(c)
+		}
+		if errors.Is(err, db.ErrReceptionNotFound) || errors.Is(err, db.ErrProductNotFound) {
+			return hh.NotFoundResponse(c)
+		}
+		if errors.Is(err, db.ErrReceptionNotOpen) {
+			return hh.BadRequestResponse(c, err)
+		}
+		return hh.ServerErrorResponse(c, h.logger, err)
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+// Reopen the closed reception
+func (h *pvzHandlers) PostReceptionsReceptionIdReopen(c echo.Context, receptionID openapi_types.UUID) error {
+	userID, err := middleware.ContextGetUserID(c)
+	if err != nil {
+		return hh.ServerErrorResponse(c, h.logger, err)
+	}
+
+	var req pvzapi.PostReceptionsReceptionIdReopenJSONRequestBody
+
+	if err := c.Bind(&req); err != nil {
+		return hh.BadRequestResponse(c, err)
+	}
+
+	reason := strings.TrimSpace(req.Reason)
+	if reason == "" {
+		return hh.BadRequestResponse(c, fmt.Errorf("missing field(s)"))
+	}
+
+	if utf8.RuneCountInString(reason) > maxReopenReasonLength {
+		return hh.BadRequestResponse(c, fmt.Errorf("reason is too long"))
+	}
+
+	reception, err := h.pvzUC.ReopenReception(c.Request().Context(), userID, receptionID, reason)
+	if err != nil {
+		if errors.Is(err, db.ErrReceptionNotFound) {
+			return hh.NotFoundResponse(c)
+		}
+		if errors.Is(err, db.ErrReceptionNotClosed) || errors.Is(err, db.ErrReceptionConflict) ||
+			errors.Is(err, db.ErrPVZNotActive) || errors.Is(err, db.ErrProductsLeftPVZ) {
+			return hh.BadRequestResponse(c, err)
+		}
+		return hh.ServerErrorResponse(c, h.logger, err)
+	}
+
+	resp := converters.ToResponseReception(reception)
+
+	return c.JSON(http.StatusOK, resp)
+}
+
+// Transfer stored products of the closed reception to another pvz
+func (h *pvzHandlers) PostReceptionsReceptionIdTransfers(c echo.Context, receptionID openapi_types.UUID) error {
+	userID, err := middleware.ContextGetUserID(c)
+	if err != nil {
+		return hh.ServerErrorResponse(c, h.logger, err)
+	}
+
+	var req pvzapi.PostReceptionsReceptionIdTransfersJSONRequestBody
+
+	if err := c.Bind(&req); err != nil {
+		return hh.BadRequestResponse(c, err)
+	}
+
+	if req.ToPvzId == uuid.Nil || len(req.ProductIds) == 0 {
+		return hh.BadRequestResponse(c, fmt.Errorf("missing field(s)"))
+	}
+
+	if len(req.ProductIds) > maxBatchSize {
+		return hh.BadRequestResponse(c, fmt.Errorf("too many products in the transfer, max %d", maxBatchSize))
+	}
+
+	transfers, err := h.pvzUC.TransferProducts(c.Request().Context(), userID, receptionID, req.ToPvzId, req.ProductIds)
+	if err != nil {
+		if errors.Is(err, usecase.ErrPVZAccessDenied) {
+			return hh.AccessDeniedResponse(c)
+		}
+		if errors.Is(err, db.ErrReceptionNotFound) || errors.Is(err, db.ErrProductNotFound) {
+			return hh.NotFoundResponse(c)
+		}
+		if errors.Is(err, usecase.ErrSameTransferPVZ) || errors.Is(err, db.ErrReceptionNotClosed) ||
+			errors.Is(err, db.ErrPVZNotFound) || errors.Is(err, db.ErrPVZNotActive) ||
+			errors.Is(err, db.ErrInvalidTransition) || errors.Is(err, db.ErrNoBarcode) {
+			return hh.BadRequestResponse(c, err)
+		}
+		return hh.ServerErrorResponse(c, h.logger, err)
+	}
+
+	resp := converters.ToResponseProductTransfers(transfers)
+
+	return c.JSON(http.StatusCreated, resp)
+}
+
+// Get employees assigned to the pvz
+func (h *pvzHandlers) GetPvzPvzIdEmployees(c echo.Context, pvzID openapi_types.UUID) error {
+	users, err := h.pvzUC.GetPVZEmployees(c.Request().Context(), pvzID)
+	if err != nil {
+		return hh.ServerErrorResponse(c, h.logger, err)
+	}
+
+	resp := make([]pvzapi.User, len(users))
+	for i, user := range users {
+		resp[i] = converters.ToResponseUser(user)
+	}
+
+	return c.JSON(http.StatusOK, resp)
+}
+
+// Assign the employee to the pvz
+func (h *pvzHandlers) PostPvzPvzIdEmployees(c echo.Context, pvzID openapi_types.UUID) error {
+	var req pvzapi.PostPvzPvzIdEmployeesJSONRequestBody
+
+	if err := c.Bind(&req); err != nil {
+		return hh.BadRequestResponse(c, err)
+	}
+
+	if req.UserId == uuid.Nil {
+		return hh.BadRequestResponse(c, fmt.Errorf("missing field(s)"))
+	}
+
+	assignment, err := h.pvzUC.AssignEmployee(c.Request().Context(), pvzID, req.UserId)
+	if err != nil {
+		if errors.Is(err, db.ErrPVZNotFound) || errors.Is(err, db.ErrUserNotFound) {
+			return hh.NotFoundResponse(c)
+		}
+		if errors.Is(err, db.ErrNotEmployee) || errors.Is(err, db.ErrAlreadyAssigned) {
+			return hh.BadRequestResponse(c, err)
+		}
+		return hh.ServerErrorResponse(c, h.logger, err)
+	}
+
+	resp := converters.ToResponseEmployeeAssignment(assignment)
+
+	return c.JSON(http.StatusCreated, resp)
+}
+
+// Remove the employee from the pvz
+func (h *pvzHandlers) DeletePvzPvzIdEmployeesUserId(c echo.Context, pvzID, userID openapi_types.UUID) error {
+	err := h.pvzUC.UnassignEmployee(c.Request().Context(), pvzID, userID)
+	if err != nil {
+		if errors.Is(err, db.ErrNotAssigned) {
+			return hh.NotFoundResponse(c)
+		}
+		return hh.ServerErrorResponse(c, h.logger, err)
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+// Get storage cells of the pvz with their occupancy
+func (h *pvzHandlers) GetPvzPvzIdCells(c echo.Context, pvzID openapi_types.UUID) error {
+	occupancy, err := h.pvzUC.GetCells(c.Request().Context(), pvzID)
+	if err != nil {
+		if errors.Is(err, db.ErrPVZNotFound) {
+			return hh.NotFoundResponse(c)
+		}
+		return hh.ServerErrorResponse(c, h.logger, err)
+	}
+
+	return c.JSON(http.StatusOK, converters.ToResponseCellOccupancy(occupancy))
+}
+
+// Add storage cells to the pvz
+func (h *pvzHandlers) PostPvzPvzIdCells(c echo.Context, pvzID openapi_types.UUID) error {
+	var req pvzapi.PostPvzPvzIdCellsJSONRequestBody
+
+	if err := c.Bind(&req); err != nil {
+		return hh.BadRequestResponse(c, err)
+	}
+
+	if req.Count < 1 || req.Capacity < 1 {
+		return hh.BadRequestResponse(c, fmt.Errorf("count and capacity must be positive"))
+	}
+
+	if req.Count > maxBatchSize {
+		return hh.BadRequestResponse(c, fmt.Errorf("too many cells, max %d", maxBatchSize))
+	}
+
+	cells, err := h.pvzUC.CreateCells(c.Request().Context(), pvzID, req.Count, req.Capacity)
+	if err != nil {
+		if errors.Is(err, db.ErrPVZNotFound) {
+			return hh.NotFoundResponse(c)
+		}
+		return hh.ServerErrorResponse(c, h.logger, err)
+	}
+
+	return c.JSON(http.StatusCreated, converters.ToResponseStorageCells(cells))
+}
+
+// Get capacity limits of the pvz with its current load
+func (h *pvzHandlers) GetPvzPvzIdCapacity(c echo.Context, pvzID openapi_types.UUID) error {
+	capacity, err := h.pvzUC.GetCapacity(c.Request().Context(), pvzID)
+	if err != nil {
+		if errors.Is(err, db.ErrPVZNotFound) {
+			return hh.NotFoundResponse(c)
+		}
+		return hh.ServerErrorResponse(c, h.logger, err)
+	}
+
+	return c.JSON(http.StatusOK, converters.ToResponsePVZCapacity(capacity))
+}
+
+// Set capacity limits of the pvz
+func (h *pvzHandlers) PutPvzPvzIdCapacity(c echo.Context, pvzID openapi_types.UUID) error {
+	var req pvzapi.PutPvzPvzIdCapacityJSONRequestBody
+
+	if err := c.Bind(&req); err != nil {
+		return hh.BadRequestResponse(c, err)
+	}
+
+	capacity, err := h.pvzUC.SetCapacity(c.Request().Context(), pvzID, req.Capacity, req.ReceptionLimit)
+	if err != nil {
+		if errors.Is(err, usecase.ErrInvalidCapacity) {
+			return hh.BadRequestResponse(c, err)
+		}
+		if errors.Is(err, db.ErrPVZNotFound) {
+			return hh.NotFoundResponse(c)
+		}
+		return hh.ServerErrorResponse(c, h.logger, err)
+	}
+
+	return c.JSON(http.StatusOK, converters.ToResponsePVZCapacity(capacity))
+}
+
+// Get a page of users
+func (h *pvzHandlers) GetUsers(c echo.Context, params pvzapi.GetUsersParams) error {
+	if params.Role != nil && !userRoles[pvzapi.UserRole(*params.Role)] {
+		return hh.BadRequestResponse(c, usecase.ErrInvalidRole)
+	}
+
+	users, err := h.pvzUC.GetUsers(c.Request().Context(), params)
+	if err != nil {
+		return hh.ServerErrorResponse(c, h.logger, err)
+	}
+
+	return c.JSON(http.StatusOK, converters.ToResponseUsers(users))
+}
+
+// Get a single user
+func (h *pvzHandlers) GetUsersUserId(c echo.Context, userID openapi_types.UUID) error {
+	user, err := h.pvzUC.GetUser(c.Request().Context(), userID)
+	if err != nil {
+		if errors.Is(err, db.ErrUserNotFound) {
+			return hh.NotFoundResponse(c)
+		}
+		return hh.ServerErrorResponse(c, h.logger, err)
+	}
+
+	return c.JSON(http.StatusOK, converters.ToResponseUser(user))
+}
+
+// Change the role of the user
+func (h *pvzHandlers) PatchUsersUserId(c echo.Context, userID openapi_types.UUID) error {
+	var req pvzapi.PatchUsersUserIdJSONRequestBody
+
+	if err := c.Bind(&req); err != nil {
+		return hh.BadRequestResponse(c, err)
+	}
+
+	if !userRoles[pvzapi.UserRole(req.Role)] {
+		return hh.BadRequestResponse(c, usecase.ErrInvalidRole)
+	}
+
+	actorID, err := middleware.ContextGetUserID(c)
+	if err != nil {
+		return hh.ServerErrorResponse(c, h.logger, err)
+	}
+
+	actorRole, err := middleware.ContextGetUserRole(c)
+	if err != nil {
+		return hh.ServerErrorResponse(c, h.logger, err)
+	}
+
+	user, err := h.pvzUC.ChangeUserRole(c.Request().Context(), actorID, actorRole, userID, string(req.Role))
+	if err != nil {
+		if errors.Is(err, usecase.ErrInvalidRole) {
+			return hh.BadRequestResponse(c, err)
+		}
+		if errors.Is(err, usecase.ErrSelfManagement) {
+			return hh.BadRequestResponse(c, err)
+		}
+		if errors.Is(err, usecase.ErrAdminRequired) {
+			return hh.AccessDeniedResponse(c)
+		}
+		if errors.Is(err, db.ErrUserNotFound) {
+			return hh.NotFoundResponse(c)
+		}
+		return hh.ServerErrorResponse(c, h.logger, err)
+	}
+
+	return c.JSON(http.StatusOK, converters.ToResponseUser(user))
+}
+
+// Reactivate the deactivated user
+func (h *pvzHandlers) PostUsersUserIdActivate(c echo.Context, userID openapi_types.UUID) error {
+	actorID, err := middleware.ContextGetUserID(c)
+	if err != nil {
+		return hh.ServerErrorResponse(c, h.logger, err)
+	}
+
+	actorRole, err := middleware.ContextGetUserRole(c)
+	if err != nil {
+		return hh.ServerErrorResponse(c, h.logger, err)
+	}
+
+	user, err := h.pvzUC.SetUserActive(c.Request().Context(), actorID, actorRole, userID, true)
+	if err != nil {
+		if errors.Is(err, usecase.ErrSelfManagement) {
+			return hh.BadRequestResponse(c, err)
+		}
+		if errors.Is(err, usecase.ErrAdminRequired) {
+			return hh.AccessDeniedResponse(c)
+		}
+		if errors.Is(err, db.ErrUserNotFound) {
+			return hh.NotFoundResponse(c)
+		}
+		return hh.ServerErrorResponse(c, h.logger, err)
+	}
+
+	return c.JSON(http.StatusOK, converters.ToResponseUser(user))
+}
+
+// Deactivate the user and end the user sessions
+func (h *pvzHandlers) PostUsersUserIdDeactivate(c echo.Context, userID openapi_types.UUID) error {
+	actorID, err := middleware.ContextGetUserID(c)
+	if err != nil {
+		return hh.ServerErrorResponse(c, h.logger, err)
+	}
+
+	actorRole, err := middleware.ContextGetUserRole(c)
+	if err != nil {
+		return hh.ServerErrorResponse(c, h.logger, err)
+	}
+
+	user, err := h.pvzUC.SetUserActive(c.Request().Context(), actorID, actorRole, userID, false)
+	if err != nil {
+		if errors.Is(err, usecase.ErrSelfManagement) {
+			return hh.BadRequestResponse(c, err)
+		}
+		if errors.Is(err, usecase.ErrAdminRequired) {
+			return hh.AccessDeniedResponse(c)
+		}
+		if errors.Is(err, db.ErrUserNotFound) {
+			return hh.NotFoundResponse(c)
+		}
+		return hh.ServerErrorResponse(c, h.logger, err)
+	}
+
+	return c.JSON(http.StatusOK, converters.ToResponseUser(user))
+}
+
+// Get permissions granted to the user on top of the role
+func (h *pvzHandlers) GetUsersUserIdPermissions(c echo.Context, userID openapi_types.UUID) error {
+	grants, err := h.pvzUC.GetUserPermissions(c.Request().Context(), userID)
+	if err != nil {
+		if errors.Is(err, db.ErrUserNotFound) {
+			return hh.NotFoundResponse(c)
+		}
+		return hh.ServerErrorResponse(c, h.logger, err)
+	}
+
+	return c.JSON(http.StatusOK, converters.ToResponsePermissionGrants(grants))
+}
+
+// Grant the permission to the user
+func (h *pvzHandlers) PutUsersUserIdPermissionsPermission(c echo.Context, userID openapi_types.UUID, permission string) error {
+	adminID, err := middleware.ContextGetUserID(c)
+	if err != nil {
+		return hh.ServerErrorResponse(c, h.logger, err)
+	}
+
+	grant, err := h.pvzUC.GrantPermission(c.Request().Context(), adminID, userID, permission)
+	if err != nil {
+		if errors.Is(err, usecase.ErrInvalidPermission) {
+			return hh.BadRequestResponse(c, err)
+		}
+		if errors.Is(err, db.ErrUserNotFound) {
+			return hh.NotFoundResponse(c)
+		}
+		return hh.ServerErrorResponse(c, h.logger, err)
+	}
+
+	return c.JSON(http.StatusOK, converters.ToResponsePermissionGrant(grant))
+}
+
+// Revoke the permission from the user
+func (h *pvzHandlers) DeleteUsersUserIdPermissionsPermission(c echo.Context, userID openapi_types.UUID, permission string) error {
+	err := h.pvzUC.RevokePermission(c.Request().Context(), userID, permission)
+	if err != nil {
+		if errors.Is(err, usecase.ErrInvalidPermission) {
+			return hh.BadRequestResponse(c, err)
+		}
+		if errors.Is(err, db.ErrPermissionNotGranted) {
+			return hh.NotFoundResponse(c)
+		}
+		return hh.ServerErrorResponse(c, h.logger, err)
+	}
+
+	return c.NoContent(http.StatusNoContent)
 }
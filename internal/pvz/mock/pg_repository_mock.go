@@ -38,33 +38,153 @@ func (m *MockRepository) EXPECT() *MockRepositoryMockRecorder {
 }
 
 // AddProduct mocks base method.
-func (m *MockRepository) AddProduct(ctx context.Context, productID, pvzID uuid.UUID, productType string) (*models.Product, error) {
+func (m *MockRepository) AddProduct(ctx context.Context, productID, pvzID, userID uuid.UUID, productType string, barcode *string, cellNumber *int) (*models.Product, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "AddProduct", ctx, productID, pvzID, productType)
+	ret := m.ctrl.Call(m, "AddProduct", ctx, productID, pvzID, userID, productType, barcode, cellNumber)
 	ret0, _ := ret[0].(*models.Product)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // AddProduct indicates an expected call of AddProduct.
-func (mr *MockRepositoryMockRecorder) AddProduct(ctx, productID, pvzID, productType interface{}) *gomock.Call {
+func (mr *MockRepositoryMockRecorder) AddProduct(ctx, productID, pvzID, userID, productType, barcode, cellNumber interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddProduct", reflect.TypeOf((*MockRepository)(nil).AddProduct), ctx, productID, pvzID, productType)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddProduct", reflect.TypeOf((*MockRepository)(nil).AddProduct), ctx, productID, pvzID, userID, productType, barcode, cellNumber)
+}
+
+// AddProducts mocks base method.
+func (m *MockRepository) AddProducts(ctx context.Context, pvzID, userID uuid.UUID, products []models.Product) ([]models.ProductBatchResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddProducts", ctx, pvzID, userID, products)
+	ret0, _ := ret[0].([]models.ProductBatchResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AddProducts indicates an expected call of AddProducts.
+func (mr *MockRepositoryMockRecorder) AddProducts(ctx, pvzID, userID, products interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddProducts", reflect.TypeOf((*MockRepository)(nil).AddProducts), ctx, pvzID, userID, products)
+}
+
+// AssignEmployee mocks base method.
+func (m *MockRepository) AssignEmployee(ctx context.Context, pvzID, userID uuid.UUID) (*models.EmployeeAssignment, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AssignEmployee", ctx, pvzID, userID)
+	ret0, _ := ret[0].(*models.EmployeeAssignment)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AssignEmployee indicates an expected call of AssignEmployee.
+func (mr *MockRepositoryMockRecorder) AssignEmployee(ctx, pvzID, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AssignEmployee", reflect.TypeOf((*MockRepository)(nil).AssignEmployee), ctx, pvzID, userID)
+}
+
+// CancelLastReception mocks base method.
+func (m *MockRepository) CancelLastReception(ctx context.Context, pvzID, userID uuid.UUID) (*models.Reception, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CancelLastReception", ctx, pvzID, userID)
+	ret0, _ := ret[0].(*models.Reception)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CancelLastReception indicates an expected call of CancelLastReception.
+func (mr *MockRepositoryMockRecorder) CancelLastReception(ctx, pvzID, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CancelLastReception", reflect.TypeOf((*MockRepository)(nil).CancelLastReception), ctx, pvzID, userID)
+}
+
+// ClaimLoginAttempt mocks base method.
+func (m *MockRepository) ClaimLoginAttempt(ctx context.Context, key string, maxAttempts int, at, resetBefore time.Time) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ClaimLoginAttempt", ctx, key, maxAttempts, at, resetBefore)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ClaimLoginAttempt indicates an expected call of ClaimLoginAttempt.
+func (mr *MockRepositoryMockRecorder) ClaimLoginAttempt(ctx, key, maxAttempts, at, resetBefore interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ClaimLoginAttempt", reflect.TypeOf((*MockRepository)(nil).ClaimLoginAttempt), ctx, key, maxAttempts, at, resetBefore)
+}
+
+// ClaimPickupAttempt mocks base method.
+func (m *MockRepository) ClaimPickupAttempt(ctx context.Context, productID uuid.UUID, maxAttempts int, now time.Time) (*models.Product, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ClaimPickupAttempt", ctx, productID, maxAttempts, now)
+	ret0, _ := ret[0].(*models.Product)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ClaimPickupAttempt indicates an expected call of ClaimPickupAttempt.
+func (mr *MockRepositoryMockRecorder) ClaimPickupAttempt(ctx, productID, maxAttempts, now interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ClaimPickupAttempt", reflect.TypeOf((*MockRepository)(nil).ClaimPickupAttempt), ctx, productID, maxAttempts, now)
 }
 
 // CloseLastReception mocks base method.
-func (m *MockRepository) CloseLastReception(ctx context.Context, pvzID uuid.UUID) (*models.Reception, error) {
+func (m *MockRepository) CloseLastReception(ctx context.Context, pvzID, userID uuid.UUID) (*models.Reception, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "CloseLastReception", ctx, pvzID)
+	ret := m.ctrl.Call(m, "CloseLastReception", ctx, pvzID, userID)
 	ret0, _ := ret[0].(*models.Reception)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // CloseLastReception indicates an expected call of CloseLastReception.
-func (mr *MockRepositoryMockRecorder) CloseLastReception(ctx, pvzID interface{}) *gomock.Call {
+func (mr *MockRepositoryMockRecorder) CloseLastReception(ctx, pvzID, userID interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CloseLastReception", reflect.TypeOf((*MockRepository)(nil).CloseLastReception), ctx, pvzID)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CloseLastReception", reflect.TypeOf((*MockRepository)(nil).CloseLastReception), ctx, pvzID, userID)
+}
+
+// CloseStaleReceptions mocks base method.
+func (m *MockRepository) CloseStaleReceptions(ctx context.Context, idleSince time.Time) ([]models.Reception, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CloseStaleReceptions", ctx, idleSince)
+	ret0, _ := ret[0].([]models.Reception)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CloseStaleReceptions indicates an expected call of CloseStaleReceptions.
+func (mr *MockRepositoryMockRecorder) CloseStaleReceptions(ctx, idleSince interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CloseStaleReceptions", reflect.TypeOf((*MockRepository)(nil).CloseStaleReceptions), ctx, idleSince)
+}
+
+// CreateCells mocks base method.
+func (m *MockRepository) CreateCells(ctx context.Context, pvzID uuid.UUID, count, capacity int) ([]models.StorageCell, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateCells", ctx, pvzID, count, capacity)
+	ret0, _ := ret[0].([]models.StorageCell)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateCells indicates an expected call of CreateCells.
+func (mr *MockRepositoryMockRecorder) CreateCells(ctx, pvzID, count, capacity interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateCells", reflect.TypeOf((*MockRepository)(nil).CreateCells), ctx, pvzID, count, capacity)
+}
+
+// CreateCity mocks base method.
+func (m *MockRepository) CreateCity(ctx context.Context, city models.City) (*models.City, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateCity", ctx, city)
+	ret0, _ := ret[0].(*models.City)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateCity indicates an expected call of CreateCity.
+func (mr *MockRepositoryMockRecorder) CreateCity(ctx, city interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateCity", reflect.TypeOf((*MockRepository)(nil).CreateCity), ctx, city)
 }
 
 // CreatePVZ mocks base method.
@@ -81,19 +201,63 @@ func (mr *MockRepositoryMockRecorder) CreatePVZ(ctx, pvz interface{}) *gomock.Ca
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreatePVZ", reflect.TypeOf((*MockRepository)(nil).CreatePVZ), ctx, pvz)
 }
 
+// CreateProductType mocks base method.
+func (m *MockRepository) CreateProductType(ctx context.Context, productType models.ProductType) (*models.ProductType, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateProductType", ctx, productType)
+	ret0, _ := ret[0].(*models.ProductType)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateProductType indicates an expected call of CreateProductType.
+func (mr *MockRepositoryMockRecorder) CreateProductType(ctx, productType interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateProductType", reflect.TypeOf((*MockRepository)(nil).CreateProductType), ctx, productType)
+}
+
 // CreateReception mocks base method.
-func (m *MockRepository) CreateReception(ctx context.Context, receptionID, pvzID uuid.UUID) (*models.Reception, error) {
+func (m *MockRepository) CreateReception(ctx context.Context, receptionID, pvzID, userID uuid.UUID, manifest []models.ManifestItem, barcodes []models.ManifestBarcode) (*models.Reception, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "CreateReception", ctx, receptionID, pvzID)
+	ret := m.ctrl.Call(m, "CreateReception", ctx, receptionID, pvzID, userID, manifest, barcodes)
 	ret0, _ := ret[0].(*models.Reception)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // CreateReception indicates an expected call of CreateReception.
-func (mr *MockRepositoryMockRecorder) CreateReception(ctx, receptionID, pvzID interface{}) *gomock.Call {
+func (mr *MockRepositoryMockRecorder) CreateReception(ctx, receptionID, pvzID, userID, manifest, barcodes interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateReception", reflect.TypeOf((*MockRepository)(nil).CreateReception), ctx, receptionID, pvzID)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateReception", reflect.TypeOf((*MockRepository)(nil).CreateReception), ctx, receptionID, pvzID, userID, manifest, barcodes)
+}
+
+// CreateRefreshToken mocks base method.
+func (m *MockRepository) CreateRefreshToken(ctx context.Context, token models.RefreshToken) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateRefreshToken", ctx, token)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateRefreshToken indicates an expected call of CreateRefreshToken.
+func (mr *MockRepositoryMockRecorder) CreateRefreshToken(ctx, token interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateRefreshToken", reflect.TypeOf((*MockRepository)(nil).CreateRefreshToken), ctx, token)
+}
+
+// CreateTransfers mocks base method.
+func (m *MockRepository) CreateTransfers(ctx context.Context, receptionID, toPvzID, userID uuid.UUID, productIDs []uuid.UUID) ([]models.ProductTransfer, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateTransfers", ctx, receptionID, toPvzID, userID, productIDs)
+	ret0, _ := ret[0].([]models.ProductTransfer)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateTransfers indicates an expected call of CreateTransfers.
+func (mr *MockRepositoryMockRecorder) CreateTransfers(ctx, receptionID, toPvzID, userID, productIDs interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateTransfers", reflect.TypeOf((*MockRepository)(nil).CreateTransfers), ctx, receptionID, toPvzID, userID, productIDs)
 }
 
 // CreateUser mocks base method.
@@ -110,6 +274,35 @@ func (mr *MockRepositoryMockRecorder) CreateUser(ctx, user interface{}) *gomock.
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateUser", reflect.TypeOf((*MockRepository)(nil).CreateUser), ctx, user)
 }
 
+// DeleteCity mocks base method.
+func (m *MockRepository) DeleteCity(ctx context.Context, cityID uuid.UUID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteCity", ctx, cityID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteCity indicates an expected call of DeleteCity.
+func (mr *MockRepositoryMockRecorder) DeleteCity(ctx, cityID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteCity", reflect.TypeOf((*MockRepository)(nil).DeleteCity), ctx, cityID)
+}
+
+// DeleteExpiredTokens mocks base method.
+func (m *MockRepository) DeleteExpiredTokens(ctx context.Context) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteExpiredTokens", ctx)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteExpiredTokens indicates an expected call of DeleteExpiredTokens.
+func (mr *MockRepositoryMockRecorder) DeleteExpiredTokens(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteExpiredTokens", reflect.TypeOf((*MockRepository)(nil).DeleteExpiredTokens), ctx)
+}
+
 // DeleteLastProduct mocks base method.
 func (m *MockRepository) DeleteLastProduct(ctx context.Context, pvzID uuid.UUID) error {
 	m.ctrl.T.Helper()
@@ -124,6 +317,155 @@ func (mr *MockRepositoryMockRecorder) DeleteLastProduct(ctx, pvzID interface{})
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteLastProduct", reflect.TypeOf((*MockRepository)(nil).DeleteLastProduct), ctx, pvzID)
 }
 
+// DeleteProduct mocks base method.
+func (m *MockRepository) DeleteProduct(ctx context.Context, receptionID, productID uuid.UUID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteProduct", ctx, receptionID, productID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteProduct indicates an expected call of DeleteProduct.
+func (mr *MockRepositoryMockRecorder) DeleteProduct(ctx, receptionID, productID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteProduct", reflect.TypeOf((*MockRepository)(nil).DeleteProduct), ctx, receptionID, productID)
+}
+
+// DeleteStaleLoginAttempts mocks base method.
+func (m *MockRepository) DeleteStaleLoginAttempts(ctx context.Context, before time.Time) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteStaleLoginAttempts", ctx, before)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteStaleLoginAttempts indicates an expected call of DeleteStaleLoginAttempts.
+func (mr *MockRepositoryMockRecorder) DeleteStaleLoginAttempts(ctx, before interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteStaleLoginAttempts", reflect.TypeOf((*MockRepository)(nil).DeleteStaleLoginAttempts), ctx, before)
+}
+
+// FailPickupAttempt mocks base method.
+func (m *MockRepository) FailPickupAttempt(ctx context.Context, productID uuid.UUID, maxAttempts int, lockedUntil time.Time) (*time.Time, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FailPickupAttempt", ctx, productID, maxAttempts, lockedUntil)
+	ret0, _ := ret[0].(*time.Time)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FailPickupAttempt indicates an expected call of FailPickupAttempt.
+func (mr *MockRepositoryMockRecorder) FailPickupAttempt(ctx, productID, maxAttempts, lockedUntil interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FailPickupAttempt", reflect.TypeOf((*MockRepository)(nil).FailPickupAttempt), ctx, productID, maxAttempts, lockedUntil)
+}
+
+// FindProductsByBarcode mocks base method.
+func (m *MockRepository) FindProductsByBarcode(ctx context.Context, barcode string) ([]models.ProductLocation, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindProductsByBarcode", ctx, barcode)
+	ret0, _ := ret[0].([]models.ProductLocation)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindProductsByBarcode indicates an expected call of FindProductsByBarcode.
+func (mr *MockRepositoryMockRecorder) FindProductsByBarcode(ctx, barcode interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindProductsByBarcode", reflect.TypeOf((*MockRepository)(nil).FindProductsByBarcode), ctx, barcode)
+}
+
+// GetCells mocks base method.
+func (m *MockRepository) GetCells(ctx context.Context, pvzID uuid.UUID) (*models.CellOccupancy, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetCells", ctx, pvzID)
+	ret0, _ := ret[0].(*models.CellOccupancy)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetCells indicates an expected call of GetCells.
+func (mr *MockRepositoryMockRecorder) GetCells(ctx, pvzID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCells", reflect.TypeOf((*MockRepository)(nil).GetCells), ctx, pvzID)
+}
+
+// GetCities mocks base method.
+func (m *MockRepository) GetCities(ctx context.Context) ([]models.City, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetCities", ctx)
+	ret0, _ := ret[0].([]models.City)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetCities indicates an expected call of GetCities.
+func (mr *MockRepositoryMockRecorder) GetCities(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCities", reflect.TypeOf((*MockRepository)(nil).GetCities), ctx)
+}
+
+// GetDeactivatedUsers mocks base method.
+func (m *MockRepository) GetDeactivatedUsers(ctx context.Context) ([]uuid.UUID, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetDeactivatedUsers", ctx)
+	ret0, _ := ret[0].([]uuid.UUID)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetDeactivatedUsers indicates an expected call of GetDeactivatedUsers.
+func (mr *MockRepositoryMockRecorder) GetDeactivatedUsers(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetDeactivatedUsers", reflect.TypeOf((*MockRepository)(nil).GetDeactivatedUsers), ctx)
+}
+
+// GetPVZ mocks base method.
+func (m *MockRepository) GetPVZ(ctx context.Context, pvzID uuid.UUID) (*models.PVZDetails, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPVZ", ctx, pvzID)
+	ret0, _ := ret[0].(*models.PVZDetails)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPVZ indicates an expected call of GetPVZ.
+func (mr *MockRepositoryMockRecorder) GetPVZ(ctx, pvzID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPVZ", reflect.TypeOf((*MockRepository)(nil).GetPVZ), ctx, pvzID)
+}
+
+// GetPVZCapacity mocks base method.
+func (m *MockRepository) GetPVZCapacity(ctx context.Context, pvzID uuid.UUID) (*models.PVZCapacity, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPVZCapacity", ctx, pvzID)
+	ret0, _ := ret[0].(*models.PVZCapacity)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPVZCapacity indicates an expected call of GetPVZCapacity.
+func (mr *MockRepositoryMockRecorder) GetPVZCapacity(ctx, pvzID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPVZCapacity", reflect.TypeOf((*MockRepository)(nil).GetPVZCapacity), ctx, pvzID)
+}
+
+// GetPVZEmployees mocks base method.
+func (m *MockRepository) GetPVZEmployees(ctx context.Context, pvzID uuid.UUID) ([]models.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPVZEmployees", ctx, pvzID)
+	ret0, _ := ret[0].([]models.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPVZEmployees indicates an expected call of GetPVZEmployees.
+func (mr *MockRepositoryMockRecorder) GetPVZEmployees(ctx, pvzID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPVZEmployees", reflect.TypeOf((*MockRepository)(nil).GetPVZEmployees), ctx, pvzID)
+}
+
 // GetPVZList mocks base method.
 func (m *MockRepository) GetPVZList(ctx context.Context) ([]models.PVZ, error) {
 	m.ctrl.T.Helper()
@@ -139,19 +481,184 @@ func (mr *MockRepositoryMockRecorder) GetPVZList(ctx interface{}) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPVZList", reflect.TypeOf((*MockRepository)(nil).GetPVZList), ctx)
 }
 
+// GetPVZUtilization mocks base method.
+func (m *MockRepository) GetPVZUtilization(ctx context.Context) ([]models.PVZCapacity, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPVZUtilization", ctx)
+	ret0, _ := ret[0].([]models.PVZCapacity)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPVZUtilization indicates an expected call of GetPVZUtilization.
+func (mr *MockRepositoryMockRecorder) GetPVZUtilization(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPVZUtilization", reflect.TypeOf((*MockRepository)(nil).GetPVZUtilization), ctx)
+}
+
 // GetPVZs mocks base method.
-func (m *MockRepository) GetPVZs(ctx context.Context, startDate, endDate *time.Time, limit, offset uint64) ([]*models.PVZWithReceptions, error) {
+func (m *MockRepository) GetPVZs(ctx context.Context, startDate, endDate *time.Time, excludeCancelled bool, productStatus *string, productCounts bool, limit, offset uint64) ([]*models.PVZWithReceptions, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetPVZs", ctx, startDate, endDate, limit, offset)
+	ret := m.ctrl.Call(m, "GetPVZs", ctx, startDate, endDate, excludeCancelled, productStatus, productCounts, limit, offset)
 	ret0, _ := ret[0].([]*models.PVZWithReceptions)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // GetPVZs indicates an expected call of GetPVZs.
-func (mr *MockRepositoryMockRecorder) GetPVZs(ctx, startDate, endDate, limit, offset interface{}) *gomock.Call {
+func (mr *MockRepositoryMockRecorder) GetPVZs(ctx, startDate, endDate, excludeCancelled, productStatus, productCounts, limit, offset interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPVZs", reflect.TypeOf((*MockRepository)(nil).GetPVZs), ctx, startDate, endDate, limit, offset)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPVZs", reflect.TypeOf((*MockRepository)(nil).GetPVZs), ctx, startDate, endDate, excludeCancelled, productStatus, productCounts, limit, offset)
+}
+
+// GetPermissionGrants mocks base method.
+func (m *MockRepository) GetPermissionGrants(ctx context.Context) ([]models.PermissionGrant, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPermissionGrants", ctx)
+	ret0, _ := ret[0].([]models.PermissionGrant)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPermissionGrants indicates an expected call of GetPermissionGrants.
+func (mr *MockRepositoryMockRecorder) GetPermissionGrants(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPermissionGrants", reflect.TypeOf((*MockRepository)(nil).GetPermissionGrants), ctx)
+}
+
+// GetProductPVZID mocks base method.
+func (m *MockRepository) GetProductPVZID(ctx context.Context, productID uuid.UUID) (uuid.UUID, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetProductPVZID", ctx, productID)
+	ret0, _ := ret[0].(uuid.UUID)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetProductPVZID indicates an expected call of GetProductPVZID.
+func (mr *MockRepositoryMockRecorder) GetProductPVZID(ctx, productID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetProductPVZID", reflect.TypeOf((*MockRepository)(nil).GetProductPVZID), ctx, productID)
+}
+
+// GetProductTypes mocks base method.
+func (m *MockRepository) GetProductTypes(ctx context.Context) ([]models.ProductType, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetProductTypes", ctx)
+	ret0, _ := ret[0].([]models.ProductType)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetProductTypes indicates an expected call of GetProductTypes.
+func (mr *MockRepositoryMockRecorder) GetProductTypes(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetProductTypes", reflect.TypeOf((*MockRepository)(nil).GetProductTypes), ctx)
+}
+
+// GetReception mocks base method.
+func (m *MockRepository) GetReception(ctx context.Context, receptionID uuid.UUID) (*models.ReceptionWithProducts, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetReception", ctx, receptionID)
+	ret0, _ := ret[0].(*models.ReceptionWithProducts)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetReception indicates an expected call of GetReception.
+func (mr *MockRepositoryMockRecorder) GetReception(ctx, receptionID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetReception", reflect.TypeOf((*MockRepository)(nil).GetReception), ctx, receptionID)
+}
+
+// GetReceptionHistory mocks base method.
+func (m *MockRepository) GetReceptionHistory(ctx context.Context, receptionID uuid.UUID) ([]models.ReceptionAuditRecord, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetReceptionHistory", ctx, receptionID)
+	ret0, _ := ret[0].([]models.ReceptionAuditRecord)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetReceptionHistory indicates an expected call of GetReceptionHistory.
+func (mr *MockRepositoryMockRecorder) GetReceptionHistory(ctx, receptionID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetReceptionHistory", reflect.TypeOf((*MockRepository)(nil).GetReceptionHistory), ctx, receptionID)
+}
+
+// GetReceptionPVZID mocks base method.
+func (m *MockRepository) GetReceptionPVZID(ctx context.Context, receptionID uuid.UUID) (uuid.UUID, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetReceptionPVZID", ctx, receptionID)
+	ret0, _ := ret[0].(uuid.UUID)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetReceptionPVZID indicates an expected call of GetReceptionPVZID.
+func (mr *MockRepositoryMockRecorder) GetReceptionPVZID(ctx, receptionID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetReceptionPVZID", reflect.TypeOf((*MockRepository)(nil).GetReceptionPVZID), ctx, receptionID)
+}
+
+// GetReceptionProducts mocks base method.
+func (m *MockRepository) GetReceptionProducts(ctx context.Context, receptionID uuid.UUID, productType *string, cursor, limit int) ([]models.Product, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetReceptionProducts", ctx, receptionID, productType, cursor, limit)
+	ret0, _ := ret[0].([]models.Product)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetReceptionProducts indicates an expected call of GetReceptionProducts.
+func (mr *MockRepositoryMockRecorder) GetReceptionProducts(ctx, receptionID, productType, cursor, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetReceptionProducts", reflect.TypeOf((*MockRepository)(nil).GetReceptionProducts), ctx, receptionID, productType, cursor, limit)
+}
+
+// GetRefreshToken mocks base method.
+func (m *MockRepository) GetRefreshToken(ctx context.Context, tokenHash string) (*models.RefreshToken, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetRefreshToken", ctx, tokenHash)
+	ret0, _ := ret[0].(*models.RefreshToken)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetRefreshToken indicates an expected call of GetRefreshToken.
+func (mr *MockRepositoryMockRecorder) GetRefreshToken(ctx, tokenHash interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRefreshToken", reflect.TypeOf((*MockRepository)(nil).GetRefreshToken), ctx, tokenHash)
+}
+
+// GetRevokedAccessTokens mocks base method.
+func (m *MockRepository) GetRevokedAccessTokens(ctx context.Context) ([]uuid.UUID, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetRevokedAccessTokens", ctx)
+	ret0, _ := ret[0].([]uuid.UUID)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetRevokedAccessTokens indicates an expected call of GetRevokedAccessTokens.
+func (mr *MockRepositoryMockRecorder) GetRevokedAccessTokens(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRevokedAccessTokens", reflect.TypeOf((*MockRepository)(nil).GetRevokedAccessTokens), ctx)
+}
+
+// GetUser mocks base method.
+func (m *MockRepository) GetUser(ctx context.Context, userID uuid.UUID) (*models.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUser", ctx, userID)
+	ret0, _ := ret[0].(*models.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUser indicates an expected call of GetUser.
+func (mr *MockRepositoryMockRecorder) GetUser(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUser", reflect.TypeOf((*MockRepository)(nil).GetUser), ctx, userID)
 }
 
 // GetUserByEmail mocks base method.
@@ -168,3 +675,381 @@ func (mr *MockRepositoryMockRecorder) GetUserByEmail(ctx, email interface{}) *go
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserByEmail", reflect.TypeOf((*MockRepository)(nil).GetUserByEmail), ctx, email)
 }
+
+// GetUserPermissions mocks base method.
+func (m *MockRepository) GetUserPermissions(ctx context.Context, userID uuid.UUID) ([]models.PermissionGrant, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUserPermissions", ctx, userID)
+	ret0, _ := ret[0].([]models.PermissionGrant)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUserPermissions indicates an expected call of GetUserPermissions.
+func (mr *MockRepositoryMockRecorder) GetUserPermissions(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserPermissions", reflect.TypeOf((*MockRepository)(nil).GetUserPermissions), ctx, userID)
+}
+
+// GetUserRoles mocks base method.
+func (m *MockRepository) GetUserRoles(ctx context.Context) ([]models.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUserRoles", ctx)
+	ret0, _ := ret[0].([]models.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUserRoles indicates an expected call of GetUserRoles.
+func (mr *MockRepositoryMockRecorder) GetUserRoles(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserRoles", reflect.TypeOf((*MockRepository)(nil).GetUserRoles), ctx)
+}
+
+// GetUsers mocks base method.
+func (m *MockRepository) GetUsers(ctx context.Context, search, role *string, limit, offset uint64) ([]models.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUsers", ctx, search, role, limit, offset)
+	ret0, _ := ret[0].([]models.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUsers indicates an expected call of GetUsers.
+func (mr *MockRepositoryMockRecorder) GetUsers(ctx, search, role, limit, offset interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUsers", reflect.TypeOf((*MockRepository)(nil).GetUsers), ctx, search, role, limit, offset)
+}
+
+// GrantPermission mocks base method.
+func (m *MockRepository) GrantPermission(ctx context.Context, userID uuid.UUID, permission models.Permission, grantedBy uuid.UUID) (*models.PermissionGrant, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GrantPermission", ctx, userID, permission, grantedBy)
+	ret0, _ := ret[0].(*models.PermissionGrant)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GrantPermission indicates an expected call of GrantPermission.
+func (mr *MockRepositoryMockRecorder) GrantPermission(ctx, userID, permission, grantedBy interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GrantPermission", reflect.TypeOf((*MockRepository)(nil).GrantPermission), ctx, userID, permission, grantedBy)
+}
+
+// IsEmployeeAssigned mocks base method.
+func (m *MockRepository) IsEmployeeAssigned(ctx context.Context, pvzID, userID uuid.UUID) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "IsEmployeeAssigned", ctx, pvzID, userID)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// IsEmployeeAssigned indicates an expected call of IsEmployeeAssigned.
+func (mr *MockRepositoryMockRecorder) IsEmployeeAssigned(ctx, pvzID, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IsEmployeeAssigned", reflect.TypeOf((*MockRepository)(nil).IsEmployeeAssigned), ctx, pvzID, userID)
+}
+
+// IssueWithPickupCode mocks base method.
+func (m *MockRepository) IssueWithPickupCode(ctx context.Context, productID uuid.UUID, codeHash string, now time.Time) (*models.Product, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "IssueWithPickupCode", ctx, productID, codeHash, now)
+	ret0, _ := ret[0].(*models.Product)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// IssueWithPickupCode indicates an expected call of IssueWithPickupCode.
+func (mr *MockRepositoryMockRecorder) IssueWithPickupCode(ctx, productID, codeHash, now interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IssueWithPickupCode", reflect.TypeOf((*MockRepository)(nil).IssueWithPickupCode), ctx, productID, codeHash, now)
+}
+
+// LockLoginAttempts mocks base method.
+func (m *MockRepository) LockLoginAttempts(ctx context.Context, key string, lockedUntil time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "LockLoginAttempts", ctx, key, lockedUntil)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// LockLoginAttempts indicates an expected call of LockLoginAttempts.
+func (mr *MockRepositoryMockRecorder) LockLoginAttempts(ctx, key, lockedUntil interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LockLoginAttempts", reflect.TypeOf((*MockRepository)(nil).LockLoginAttempts), ctx, key, lockedUntil)
+}
+
+// MoveProduct mocks base method.
+func (m *MockRepository) MoveProduct(ctx context.Context, productID uuid.UUID, cellNumber int) (*models.Product, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MoveProduct", ctx, productID, cellNumber)
+	ret0, _ := ret[0].(*models.Product)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// MoveProduct indicates an expected call of MoveProduct.
+func (mr *MockRepositoryMockRecorder) MoveProduct(ctx, productID, cellNumber interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MoveProduct", reflect.TypeOf((*MockRepository)(nil).MoveProduct), ctx, productID, cellNumber)
+}
+
+// ReleaseLoginAttempt mocks base method.
+func (m *MockRepository) ReleaseLoginAttempt(ctx context.Context, key string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReleaseLoginAttempt", ctx, key)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ReleaseLoginAttempt indicates an expected call of ReleaseLoginAttempt.
+func (mr *MockRepositoryMockRecorder) ReleaseLoginAttempt(ctx, key interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReleaseLoginAttempt", reflect.TypeOf((*MockRepository)(nil).ReleaseLoginAttempt), ctx, key)
+}
+
+// ReopenReception mocks base method.
+func (m *MockRepository) ReopenReception(ctx context.Context, receptionID, userID uuid.UUID, reason string) (*models.Reception, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReopenReception", ctx, receptionID, userID, reason)
+	ret0, _ := ret[0].(*models.Reception)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ReopenReception indicates an expected call of ReopenReception.
+func (mr *MockRepositoryMockRecorder) ReopenReception(ctx, receptionID, userID, reason interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReopenReception", reflect.TypeOf((*MockRepository)(nil).ReopenReception), ctx, receptionID, userID, reason)
+}
+
+// ResetLoginFailures mocks base method.
+func (m *MockRepository) ResetLoginFailures(ctx context.Context, key string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ResetLoginFailures", ctx, key)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ResetLoginFailures indicates an expected call of ResetLoginFailures.
+func (mr *MockRepositoryMockRecorder) ResetLoginFailures(ctx, key interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ResetLoginFailures", reflect.TypeOf((*MockRepository)(nil).ResetLoginFailures), ctx, key)
+}
+
+// RetireProductType mocks base method.
+func (m *MockRepository) RetireProductType(ctx context.Context, typeID uuid.UUID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RetireProductType", ctx, typeID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RetireProductType indicates an expected call of RetireProductType.
+func (mr *MockRepositoryMockRecorder) RetireProductType(ctx, typeID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RetireProductType", reflect.TypeOf((*MockRepository)(nil).RetireProductType), ctx, typeID)
+}
+
+// RevokeAccessToken mocks base method.
+func (m *MockRepository) RevokeAccessToken(ctx context.Context, tokenID uuid.UUID, expiresAt time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RevokeAccessToken", ctx, tokenID, expiresAt)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RevokeAccessToken indicates an expected call of RevokeAccessToken.
+func (mr *MockRepositoryMockRecorder) RevokeAccessToken(ctx, tokenID, expiresAt interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokeAccessToken", reflect.TypeOf((*MockRepository)(nil).RevokeAccessToken), ctx, tokenID, expiresAt)
+}
+
+// RevokePermission mocks base method.
+func (m *MockRepository) RevokePermission(ctx context.Context, userID uuid.UUID, permission models.Permission) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RevokePermission", ctx, userID, permission)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RevokePermission indicates an expected call of RevokePermission.
+func (mr *MockRepositoryMockRecorder) RevokePermission(ctx, userID, permission interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokePermission", reflect.TypeOf((*MockRepository)(nil).RevokePermission), ctx, userID, permission)
+}
+
+// RevokeRefreshToken mocks base method.
+func (m *MockRepository) RevokeRefreshToken(ctx context.Context, userID uuid.UUID, tokenHash string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RevokeRefreshToken", ctx, userID, tokenHash)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RevokeRefreshToken indicates an expected call of RevokeRefreshToken.
+func (mr *MockRepositoryMockRecorder) RevokeRefreshToken(ctx, userID, tokenHash interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokeRefreshToken", reflect.TypeOf((*MockRepository)(nil).RevokeRefreshToken), ctx, userID, tokenHash)
+}
+
+// RevokeUserRefreshTokens mocks base method.
+func (m *MockRepository) RevokeUserRefreshTokens(ctx context.Context, userID uuid.UUID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RevokeUserRefreshTokens", ctx, userID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RevokeUserRefreshTokens indicates an expected call of RevokeUserRefreshTokens.
+func (mr *MockRepositoryMockRecorder) RevokeUserRefreshTokens(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokeUserRefreshTokens", reflect.TypeOf((*MockRepository)(nil).RevokeUserRefreshTokens), ctx, userID)
+}
+
+// RotateRefreshToken mocks base method.
+func (m *MockRepository) RotateRefreshToken(ctx context.Context, oldID uuid.UUID, token models.RefreshToken) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RotateRefreshToken", ctx, oldID, token)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RotateRefreshToken indicates an expected call of RotateRefreshToken.
+func (mr *MockRepositoryMockRecorder) RotateRefreshToken(ctx, oldID, token interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RotateRefreshToken", reflect.TypeOf((*MockRepository)(nil).RotateRefreshToken), ctx, oldID, token)
+}
+
+// SetPVZCapacity mocks base method.
+func (m *MockRepository) SetPVZCapacity(ctx context.Context, pvzID uuid.UUID, capacity, receptionLimit *int) (*models.PVZCapacity, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetPVZCapacity", ctx, pvzID, capacity, receptionLimit)
+	ret0, _ := ret[0].(*models.PVZCapacity)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SetPVZCapacity indicates an expected call of SetPVZCapacity.
+func (mr *MockRepositoryMockRecorder) SetPVZCapacity(ctx, pvzID, capacity, receptionLimit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetPVZCapacity", reflect.TypeOf((*MockRepository)(nil).SetPVZCapacity), ctx, pvzID, capacity, receptionLimit)
+}
+
+// SetPickupCode mocks base method.
+func (m *MockRepository) SetPickupCode(ctx context.Context, productID uuid.UUID, codeHash string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetPickupCode", ctx, productID, codeHash)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetPickupCode indicates an expected call of SetPickupCode.
+func (mr *MockRepositoryMockRecorder) SetPickupCode(ctx, productID, codeHash interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetPickupCode", reflect.TypeOf((*MockRepository)(nil).SetPickupCode), ctx, productID, codeHash)
+}
+
+// SetUserDeactivated mocks base method.
+func (m *MockRepository) SetUserDeactivated(ctx context.Context, userID uuid.UUID, deactivated bool) (*models.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetUserDeactivated", ctx, userID, deactivated)
+	ret0, _ := ret[0].(*models.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SetUserDeactivated indicates an expected call of SetUserDeactivated.
+func (mr *MockRepositoryMockRecorder) SetUserDeactivated(ctx, userID, deactivated interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetUserDeactivated", reflect.TypeOf((*MockRepository)(nil).SetUserDeactivated), ctx, userID, deactivated)
+}
+
+// UnassignEmployee mocks base method.
+func (m *MockRepository) UnassignEmployee(ctx context.Context, pvzID, userID uuid.UUID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UnassignEmployee", ctx, pvzID, userID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UnassignEmployee indicates an expected call of UnassignEmployee.
+func (mr *MockRepositoryMockRecorder) UnassignEmployee(ctx, pvzID, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UnassignEmployee", reflect.TypeOf((*MockRepository)(nil).UnassignEmployee), ctx, pvzID, userID)
+}
+
+// UpdateCity mocks base method.
+func (m *MockRepository) UpdateCity(ctx context.Context, cityID uuid.UUID, name string) (*models.City, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateCity", ctx, cityID, name)
+	ret0, _ := ret[0].(*models.City)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateCity indicates an expected call of UpdateCity.
+func (mr *MockRepositoryMockRecorder) UpdateCity(ctx, cityID, name interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateCity", reflect.TypeOf((*MockRepository)(nil).UpdateCity), ctx, cityID, name)
+}
+
+// UpdateLastLogin mocks base method.
+func (m *MockRepository) UpdateLastLogin(ctx context.Context, userID uuid.UUID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateLastLogin", ctx, userID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateLastLogin indicates an expected call of UpdateLastLogin.
+func (mr *MockRepositoryMockRecorder) UpdateLastLogin(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateLastLogin", reflect.TypeOf((*MockRepository)(nil).UpdateLastLogin), ctx, userID)
+}
+
+// UpdatePVZ mocks base method.
+func (m *MockRepository) UpdatePVZ(ctx context.Context, pvzID uuid.UUID, city, status *string) (*models.PVZ, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdatePVZ", ctx, pvzID, city, status)
+	ret0, _ := ret[0].(*models.PVZ)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdatePVZ indicates an expected call of UpdatePVZ.
+func (mr *MockRepositoryMockRecorder) UpdatePVZ(ctx, pvzID, city, status interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdatePVZ", reflect.TypeOf((*MockRepository)(nil).UpdatePVZ), ctx, pvzID, city, status)
+}
+
+// UpdateProductStatus mocks base method.
+func (m *MockRepository) UpdateProductStatus(ctx context.Context, productID uuid.UUID, allowed []string, status string) (*models.Product, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateProductStatus", ctx, productID, allowed, status)
+	ret0, _ := ret[0].(*models.Product)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateProductStatus indicates an expected call of UpdateProductStatus.
+func (mr *MockRepositoryMockRecorder) UpdateProductStatus(ctx, productID, allowed, status interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateProductStatus", reflect.TypeOf((*MockRepository)(nil).UpdateProductStatus), ctx, productID, allowed, status)
+}
+
+// UpdateUserRole mocks base method.
+func (m *MockRepository) UpdateUserRole(ctx context.Context, userID uuid.UUID, role string) (*models.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateUserRole", ctx, userID, role)
+	ret0, _ := ret[0].(*models.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateUserRole indicates an expected call of UpdateUserRole.
+func (mr *MockRepositoryMockRecorder) UpdateUserRole(ctx, userID, role interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateUserRole", reflect.TypeOf((*MockRepository)(nil).UpdateUserRole), ctx, userID, role)
+}
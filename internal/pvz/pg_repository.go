@@ -13,11 +13,71 @@ import (
 type Repository interface {
 	GetUserByEmail(ctx context.Context, email string) (*models.User, error)
 	CreateUser(ctx context.Context, user models.User) error
+	GetUser(ctx context.Context, userID uuid.UUID) (*models.User, error)
+	GetUsers(ctx context.Context, search, role *string, limit, offset uint64) ([]models.User, error)
+	UpdateUserRole(ctx context.Context, userID uuid.UUID, role string) (*models.User, error)
+	SetUserDeactivated(ctx context.Context, userID uuid.UUID, deactivated bool) (*models.User, error)
+	UpdateLastLogin(ctx context.Context, userID uuid.UUID) error
+	GetDeactivatedUsers(ctx context.Context) ([]uuid.UUID, error)
+	GetUserRoles(ctx context.Context) ([]models.User, error)
+	CreateRefreshToken(ctx context.Context, token models.RefreshToken) error
+	GetRefreshToken(ctx context.Context, tokenHash string) (*models.RefreshToken, error)
+	RotateRefreshToken(ctx context.Context, oldID uuid.UUID, token models.RefreshToken) error
+	RevokeRefreshToken(ctx context.Context, userID uuid.UUID, tokenHash string) error
+	RevokeUserRefreshTokens(ctx context.Context, userID uuid.UUID) error
+	RevokeAccessToken(ctx context.Context, tokenID uuid.UUID, expiresAt time.Time) error
+	GetRevokedAccessTokens(ctx context.Context) ([]uuid.UUID, error)
+	DeleteExpiredTokens(ctx context.Context) (int64, error)
+	ClaimLoginAttempt(ctx context.Context, key string, maxAttempts int, at, resetBefore time.Time) (int, error)
+	LockLoginAttempts(ctx context.Context, key string, lockedUntil time.Time) error
+	ReleaseLoginAttempt(ctx context.Context, key string) error
+	ResetLoginFailures(ctx context.Context, key string) error
+	DeleteStaleLoginAttempts(ctx context.Context, before time.Time) (int64, error)
+	GrantPermission(ctx context.Context, userID uuid.UUID, permission models.Permission, grantedBy uuid.UUID) (*models.PermissionGrant, error)
+	RevokePermission(ctx context.Context, userID uuid.UUID, permission models.Permission) error
+	GetUserPermissions(ctx context.Context, userID uuid.UUID) ([]models.PermissionGrant, error)
+	GetPermissionGrants(ctx context.Context) ([]models.PermissionGrant, error)
 	CreatePVZ(ctx context.Context, pvz models.PVZ) error
-	CreateReception(ctx context.Context, receptionID, pvzID uuid.UUID) (*models.Reception, error)
-	AddProduct(ctx context.Context, productID, pvzID uuid.UUID, productType string) (*models.Product, error)
+	UpdatePVZ(ctx context.Context, pvzID uuid.UUID, city, status *string) (*models.PVZ, error)
+	GetCities(ctx context.Context) ([]models.City, error)
+	CreateCity(ctx context.Context, city models.City) (*models.City, error)
+	UpdateCity(ctx context.Context, cityID uuid.UUID, name string) (*models.City, error)
+	DeleteCity(ctx context.Context, cityID uuid.UUID) error
+	GetProductTypes(ctx context.Context) ([]models.ProductType, error)
+	CreateProductType(ctx context.Context, productType models.ProductType) (*models.ProductType, error)
+	RetireProductType(ctx context.Context, typeID uuid.UUID) error
+	CreateReception(ctx context.Context, receptionID, pvzID, userID uuid.UUID, manifest []models.ManifestItem, barcodes []models.ManifestBarcode) (*models.Reception, error)
+	AddProduct(ctx context.Context, productID, pvzID, userID uuid.UUID, productType string, barcode *string, cellNumber *int) (*models.Product, error)
+	AddProducts(ctx context.Context, pvzID, userID uuid.UUID, products []models.Product) ([]models.ProductBatchResult, error)
 	DeleteLastProduct(ctx context.Context, pvzID uuid.UUID) error
-	CloseLastReception(ctx context.Context, pvzID uuid.UUID) (*models.Reception, error)
-	GetPVZs(ctx context.Context, startDate, endDate *time.Time, limit, offset uint64) ([]*models.PVZWithReceptions, error)
+	DeleteProduct(ctx context.Context, receptionID, productID uuid.UUID) error
+	CloseLastReception(ctx context.Context, pvzID, userID uuid.UUID) (*models.Reception, error)
+	CloseStaleReceptions(ctx context.Context, idleSince time.Time) ([]models.Reception, error)
+	CancelLastReception(ctx context.Context, pvzID, userID uuid.UUID) (*models.Reception, error)
+	ReopenReception(ctx context.Context, receptionID, userID uuid.UUID, reason string) (*models.Reception, error)
+	GetPVZs(ctx context.Context, startDate, endDate *time.Time, excludeCancelled bool, productStatus *string, productCounts bool, limit, offset uint64) ([]*models.PVZWithReceptions, error)
 	GetPVZList(ctx context.Context) ([]models.PVZ, error)
+	GetPVZ(ctx context.Context, pvzID uuid.UUID) (*models.PVZDetails, error)
+	GetReception(ctx context.Context, receptionID uuid.UUID) (*models.ReceptionWithProducts, error)
+	GetReceptionProducts(ctx context.Context, receptionID uuid.UUID, productType *string, cursor, limit int) ([]models.Product, error)
+	GetReceptionPVZID(ctx context.Context, receptionID uuid.UUID) (uuid.UUID, error)
+	GetReceptionHistory(ctx context.Context, receptionID uuid.UUID) ([]models.ReceptionAuditRecord, error)
+	GetProductPVZID(ctx context.Context, productID uuid.UUID) (uuid.UUID, error)
+	UpdateProductStatus(ctx context.Context, productID uuid.UUID, allowed []string, status string) (*models.Product, error)
+	SetPickupCode(ctx context.Context, productID uuid.UUID, codeHash string) error
+	ClaimPickupAttempt(ctx context.Context, productID uuid.UUID, maxAttempts int, now time.Time) (*models.Product, error)
+	FailPickupAttempt(ctx context.Context, productID uuid.UUID, maxAttempts int, lockedUntil time.Time) (*time.Time, error)
+	IssueWithPickupCode(ctx context.Context, productID uuid.UUID, codeHash string, now time.Time) (*models.Product, error)
+	CreateTransfers(ctx context.Context, receptionID, toPvzID, userID uuid.UUID, productIDs []uuid.UUID) ([]models.ProductTransfer, error)
+	MoveProduct(ctx context.Context, productID uuid.UUID, cellNumber int) (*models.Product, error)
+	FindProductsByBarcode(ctx context.Context, barcode string) ([]models.ProductLocation, error)
+	AssignEmployee(ctx context.Context, pvzID, userID uuid.UUID) (*models.EmployeeAssignment, error)
+	UnassignEmployee(ctx context.Context, pvzID, userID uuid.UUID) error
+	GetPVZEmployees(ctx context.Context, pvzID uuid.UUID) ([]models.User, error)
+	IsEmployeeAssigned(ctx context.Context, pvzID, userID uuid.UUID) (bool, error)
+	CreateCells(ctx context.Context, pvzID uuid.UUID, count, capacity int) ([]models.StorageCell, error)
+	GetCells(ctx context.Context, pvzID uuid.UUID) (*models.CellOccupancy, error)
+	GetPVZCapacity(ctx context.Context, pvzID uuid.UUID) (*models.PVZCapacity, error)
+	SetPVZCapacity(ctx context.Context, pvzID uuid.UUID, capacity, receptionLimit *int) (*models.PVZCapacity, error)
+	GetPVZUtilization(ctx context.Context) ([]models.PVZCapacity, error)
 }
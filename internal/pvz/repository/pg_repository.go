@@ -5,6 +5,9 @@ import (
 	"errors"
 	"fmt"
 	"log"
+	"slices"
+	"sort"
+	"strings"
 	"time"
 
 	sq "github.com/Masterminds/squirrel"
@@ -24,6 +27,12 @@ type DB interface {
 	Begin(ctx context.Context) (pgx.Tx, error)
 }
 
+// Product statuses of the products that are still kept in the pvz
+var productsInPVZ = []string{string(pvzapi.Received), string(pvzapi.Stored)}
+
+// Escapes LIKE wildcards so the search string is matched literally
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
 // PVZ repository struct
 type pvzRepo struct {
 	db DB
@@ -39,7 +48,7 @@ func (r *pvzRepo) GetUserByEmail(ctx context.Context, email string) (*models.Use
 	const op = "repository.GetUserByEmail"
 
 	query := `
-		SELECT id, email, password_hash, role
+		SELECT id, email, password_hash, role, deactivated_at
 		FROM users
 		WHERE email = $1
 	`
@@ -50,6 +59,7 @@ func (r *pvzRepo) GetUserByEmail(ctx context.Context, email string) (*models.Use
 		&user.Email,
 		&user.PasswordHash,
 		&user.Role,
+		&user.DeactivatedAt,
 	)
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
@@ -89,73 +99,93 @@ func (r *pvzRepo) CreateUser(ctx context.Context, user models.User) error {
 	return nil
 }
 
-// Create a new pvz
-func (r *pvzRepo) CreatePVZ(ctx context.Context, pvz models.PVZ) error {
-	const op = "repository.CreatePVZ"
+// Get the user by id
+func (r *pvzRepo) GetUser(ctx context.Context, userID uuid.UUID) (*models.User, error) {
+	const op = "repository.GetUser"
 
 	query := `
-        INSERT INTO pvzs (id, city, registration_date)
-        VALUES ($1, $2, $3)
-        ON CONFLICT (id) DO NOTHING
-        RETURNING id
-    `
-
-	var id string
-	err := r.db.QueryRow(ctx, query,
-		pvz.ID,
-		pvz.City,
-		pvz.RegistrationDate,
-	).Scan(&id)
+		SELECT id, email, role, created_at, last_login_at, deactivated_at
+		FROM users
+		WHERE id = $1
+	`
 
+	var user models.User
+	err := r.db.QueryRow(ctx, query, userID).Scan(
+		&user.ID,
+		&user.Email,
+		&user.Role,
+		&user.CreatedAt,
+		&user.LastLoginAt,
+		&user.DeactivatedAt,
+	)
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
-			return db.ErrDuplicatePVZ
+			return nil, db.ErrUserNotFound
 		}
-		return fmt.Errorf("%s: %w", op, err)
+		return nil, fmt.Errorf("%s: %w", op, err)
 	}
 
-	return nil
+	return &user, nil
 }
 
-// Create a new reception
-func (r *pvzRepo) CreateReception(ctx context.Context, receptionID, pvzID uuid.UUID) (*models.Reception, error) {
-	const op = "repository.CreateReception"
+// Get a page of users ordered by registration, optionally filtered by email substring and role
+func (r *pvzRepo) GetUsers(ctx context.Context, search, role *string, limit, offset uint64) ([]models.User, error) {
+	const op = "repository.GetUsers"
 
-	query := `
-		INSERT INTO receptions (id, pvz_id, status)
-		SELECT $1, $2, $3::VARCHAR
-		WHERE EXISTS (
-			SELECT 1 FROM pvzs WHERE id = $2
-		)
-		AND NOT EXISTS (
-			SELECT 1 FROM receptions WHERE pvz_id = $2 AND status = $3::VARCHAR
-		)
-		RETURNING id, date_time, pvz_id, status
-	`
+	queryBuilder := sq.
+		Select("id", "email", "role", "created_at", "last_login_at", "deactivated_at").
+		From("users")
 
-	defaultStatus := string(pvzapi.InProgress)
+	if search != nil {
+		queryBuilder = queryBuilder.Where(sq.ILike{"email": "%" + likeEscaper.Replace(*search) + "%"})
+	}
+	if role != nil {
+		queryBuilder = queryBuilder.Where(sq.Eq{"role": *role})
+	}
 
-	var reception models.Reception
-	err := r.db.QueryRow(ctx, query, receptionID, pvzID, defaultStatus).Scan(
-		&reception.ID,
-		&reception.DateTime,
-		&reception.PvzID,
-		&reception.Status,
-	)
+	query, args, err := queryBuilder.
+		OrderBy("created_at", "id").
+		Limit(limit).
+		Offset(offset).
+		PlaceholderFormat(sq.Dollar).
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
 
+	rows, err := r.db.Query(ctx, query, args...)
 	if err != nil {
-		if errors.Is(err, pgx.ErrNoRows) {
-			return nil, db.ErrReceptionConflict
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer rows.Close()
+
+	users := []models.User{}
+	for rows.Next() {
+		var user models.User
+		err := rows.Scan(
+			&user.ID,
+			&user.Email,
+			&user.Role,
+			&user.CreatedAt,
+			&user.LastLoginAt,
+			&user.DeactivatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
 		}
+		users = append(users, user)
+	}
+
+	if err := rows.Err(); err != nil {
 		return nil, fmt.Errorf("%s: %w", op, err)
 	}
 
-	return &reception, nil
+	return users, nil
 }
 
-// Add a product in the reception
-func (r *pvzRepo) AddProduct(ctx context.Context, productID, pvzID uuid.UUID, productType string) (*models.Product, error) {
-	const op = "repository.AddProduct"
+// Change the role of the user, pvz assignments are dropped when the user stops being an employee
+func (r *pvzRepo) UpdateUserRole(ctx context.Context, userID uuid.UUID, role string) (*models.User, error) {
+	const op = "repository.UpdateUserRole"
 
 	tx, err := r.db.Begin(ctx)
 	if err != nil {
@@ -170,130 +200,208 @@ func (r *pvzRepo) AddProduct(ctx context.Context, productID, pvzID uuid.UUID, pr
 	}()
 
 	query := `
-		SELECT id 
-		FROM receptions 
-        WHERE pvz_id = $1 AND status = $2::VARCHAR
-        LIMIT 1
-		FOR UPDATE
+		UPDATE users
+		SET role = $2
+		WHERE id = $1
+		RETURNING id, email, role, created_at, last_login_at, deactivated_at
 	`
 
-	allowedStatus := string(pvzapi.InProgress)
+	var user models.User
+	err = tx.QueryRow(ctx, query, userID, role).Scan(
+		&user.ID,
+		&user.Email,
+		&user.Role,
+		&user.CreatedAt,
+		&user.LastLoginAt,
+		&user.DeactivatedAt,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, db.ErrUserNotFound
+		}
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
 
-	var receptionID uuid.UUID
-	err = tx.QueryRow(ctx, query,
-		pvzID,
-		allowedStatus,
-	).Scan(&receptionID)
+	if role != string(pvzapi.UserRoleEmployee) {
+		query = `DELETE FROM employee_pvz WHERE user_id = $1`
+
+		if _, err = tx.Exec(ctx, query, userID); err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return &user, nil
+}
+
+// Deactivate or reactivate the user, deactivating an inactive user keeps the original time
+func (r *pvzRepo) SetUserDeactivated(ctx context.Context, userID uuid.UUID, deactivated bool) (*models.User, error) {
+	const op = "repository.SetUserDeactivated"
+
+	query := `
+		UPDATE users
+		SET deactivated_at = CASE WHEN $2 THEN COALESCE(deactivated_at, CURRENT_TIMESTAMP) END
+		WHERE id = $1
+		RETURNING id, email, role, created_at, last_login_at, deactivated_at
+	`
 
+	var user models.User
+	err := r.db.QueryRow(ctx, query, userID, deactivated).Scan(
+		&user.ID,
+		&user.Email,
+		&user.Role,
+		&user.CreatedAt,
+		&user.LastLoginAt,
+		&user.DeactivatedAt,
+	)
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
-			return nil, db.ErrNoOpenReception
+			return nil, db.ErrUserNotFound
 		}
 		return nil, fmt.Errorf("%s: %w", op, err)
 	}
 
-	query = `
-		INSERT INTO products (id, type, reception_id)
-		VALUES ($1, $2, $3)
-		RETURNING id, date_time, type, reception_id
+	return &user, nil
+}
+
+// Record a successful login of the user
+func (r *pvzRepo) UpdateLastLogin(ctx context.Context, userID uuid.UUID) error {
+	const op = "repository.UpdateLastLogin"
+
+	query := `
+		UPDATE users
+		SET last_login_at = CURRENT_TIMESTAMP
+		WHERE id = $1
+		RETURNING id
 	`
 
-	var product models.Product
-	err = tx.QueryRow(ctx, query,
-		productID,
-		productType,
-		receptionID,
-	).Scan(
-		&product.ID,
-		&product.DateTime,
-		&product.Type,
-		&product.ReceptionID,
-	)
+	var id uuid.UUID
+	err := r.db.QueryRow(ctx, query, userID).Scan(&id)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return db.ErrUserNotFound
+		}
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+// Get ids of the deactivated users
+func (r *pvzRepo) GetDeactivatedUsers(ctx context.Context) ([]uuid.UUID, error) {
+	const op = "repository.GetDeactivatedUsers"
+
+	query := `SELECT id FROM users WHERE deactivated_at IS NOT NULL`
+
+	rows, err := r.db.Query(ctx, query)
 	if err != nil {
 		return nil, fmt.Errorf("%s: %w", op, err)
 	}
+	defer rows.Close()
 
-	if err := tx.Commit(ctx); err != nil {
+	userIDs := []uuid.UUID{}
+	for rows.Next() {
+		var id uuid.UUID
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+		userIDs = append(userIDs, id)
+	}
+
+	if err := rows.Err(); err != nil {
 		return nil, fmt.Errorf("%s: %w", op, err)
 	}
 
-	return &product, nil
+	return userIDs, nil
 }
 
-// Delete the last product from the reception
-func (r *pvzRepo) DeleteLastProduct(ctx context.Context, pvzID uuid.UUID) error {
-	const op = "repository.DeleteLastProduct"
+// Get the current role of every user, only the id and the role are filled
+func (r *pvzRepo) GetUserRoles(ctx context.Context) ([]models.User, error) {
+	const op = "repository.GetUserRoles"
 
-	tx, err := r.db.Begin(ctx)
+	query := `SELECT id, role FROM users`
+
+	rows, err := r.db.Query(ctx, query)
 	if err != nil {
-		return fmt.Errorf("%s: %w", op, err)
+		return nil, fmt.Errorf("%s: %w", op, err)
 	}
-	defer func() {
-		if err != nil {
-			if rbErr := tx.Rollback(ctx); rbErr != nil && !errors.Is(rbErr, pgx.ErrTxClosed) {
-				log.Printf("%s: failed to rollback transaction: %v", op, rbErr)
-			}
+	defer rows.Close()
+
+	users := []models.User{}
+	for rows.Next() {
+		var user models.User
+		if err := rows.Scan(&user.ID, &user.Role); err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
 		}
-	}()
+		users = append(users, user)
+	}
 
-	query := `
-        SELECT id 
-        FROM receptions 
-        WHERE pvz_id = $1 AND status = $2::VARCHAR
-        LIMIT 1
-        FOR UPDATE
-    `
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
 
-	allowedStatus := string(pvzapi.InProgress)
+	return users, nil
+}
 
-	var receptionID uuid.UUID
-	err = tx.QueryRow(ctx, query,
-		pvzID,
-		allowedStatus,
-	).Scan(&receptionID)
+// Store a new refresh token
+func (r *pvzRepo) CreateRefreshToken(ctx context.Context, token models.RefreshToken) error {
+	const op = "repository.CreateRefreshToken"
+
+	query := `
+		INSERT INTO refresh_tokens (id, user_id, token_hash, expires_at)
+		VALUES ($1, $2, $3, $4)
+		RETURNING id
+	`
 
+	var id uuid.UUID
+	err := r.db.QueryRow(ctx, query, token.ID, token.UserID, token.TokenHash, token.ExpiresAt).Scan(&id)
 	if err != nil {
-		if errors.Is(err, pgx.ErrNoRows) {
-			return db.ErrNoOpenReception
-		}
 		return fmt.Errorf("%s: %w", op, err)
 	}
 
-	query = `
-        DELETE FROM products
-        WHERE id = (
-            SELECT id 
-            FROM products 
-            WHERE reception_id = $1
-            ORDER BY date_time DESC
-            LIMIT 1
-        )
-        RETURNING id
-    `
+	return nil
+}
 
-	var deletedID uuid.UUID
-	err = tx.QueryRow(ctx, query, receptionID).Scan(&deletedID)
+// Get the refresh token by its hash together with the role of its owner
+func (r *pvzRepo) GetRefreshToken(ctx context.Context, tokenHash string) (*models.RefreshToken, error) {
+	const op = "repository.GetRefreshToken"
+
+	query := `
+		SELECT t.id, t.user_id, u.role, t.token_hash, t.expires_at, t.revoked_at
+		FROM refresh_tokens t
+		JOIN users u ON u.id = t.user_id
+		WHERE t.token_hash = $1
+	`
+
+	var token models.RefreshToken
+	err := r.db.QueryRow(ctx, query, tokenHash).Scan(
+		&token.ID,
+		&token.UserID,
+		&token.UserRole,
+		&token.TokenHash,
+		&token.ExpiresAt,
+		&token.RevokedAt,
+	)
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
-			return db.ErrNoProducts
+			return nil, db.ErrRefreshTokenNotFound
 		}
-		return fmt.Errorf("%s: %w", op, err)
-	}
-
-	if err := tx.Commit(ctx); err != nil {
-		return fmt.Errorf("%s: %w", op, err)
+		return nil, fmt.Errorf("%s: %w", op, err)
 	}
 
-	return nil
+	return &token, nil
 }
 
-// Close the last reception in pvz
-func (r *pvzRepo) CloseLastReception(ctx context.Context, pvzID uuid.UUID) (*models.Reception, error) {
-	const op = "repository.CloseLastReception"
+// Revoke the refresh token and store its replacement, fails if the token was already used
+func (r *pvzRepo) RotateRefreshToken(ctx context.Context, oldID uuid.UUID, token models.RefreshToken) error {
+	const op = "repository.RotateRefreshToken"
 
 	tx, err := r.db.Begin(ctx)
 	if err != nil {
-		return nil, fmt.Errorf("%s: %w", op, err)
+		return fmt.Errorf("%s: %w", op, err)
 	}
 	defer func() {
 		if err != nil {
@@ -304,240 +412,3777 @@ func (r *pvzRepo) CloseLastReception(ctx context.Context, pvzID uuid.UUID) (*mod
 	}()
 
 	query := `
-        SELECT id, date_time, pvz_id
-        FROM receptions 
-        WHERE pvz_id = $1 AND status = $2::VARCHAR
-        LIMIT 1
-        FOR UPDATE
-    `
-
-	allowedStatus := string(pvzapi.InProgress)
-
-	var reception models.Reception
-	err = tx.QueryRow(ctx, query,
-		pvzID,
-		allowedStatus,
-	).Scan(
-		&reception.ID,
-		&reception.DateTime,
-		&reception.PvzID,
-	)
+		INSERT INTO refresh_tokens (id, user_id, token_hash, expires_at)
+		VALUES ($1, $2, $3, $4)
+	`
 
+	_, err = tx.Exec(ctx, query, token.ID, token.UserID, token.TokenHash, token.ExpiresAt)
 	if err != nil {
-		if errors.Is(err, pgx.ErrNoRows) {
-			return nil, db.ErrNoOpenReception
-		}
-		return nil, fmt.Errorf("%s: %w", op, err)
+		return fmt.Errorf("%s: %w", op, err)
 	}
 
 	query = `
-        UPDATE receptions
-        SET status = $1
-        WHERE id = $2
-    `
-
-	newStatus := string(pvzapi.Close)
+		UPDATE refresh_tokens
+		SET revoked_at = CURRENT_TIMESTAMP, replaced_by = $2
+		WHERE id = $1 AND revoked_at IS NULL
+	`
 
-	_, err = tx.Exec(ctx, query, newStatus, reception.ID)
+	tag, err := tx.Exec(ctx, query, oldID, token.ID)
 	if err != nil {
-		return nil, fmt.Errorf("%s: %w", op, err)
+		return fmt.Errorf("%s: %w", op, err)
 	}
 
-	reception.Status = newStatus
+	if tag.RowsAffected() == 0 {
+		err = db.ErrRefreshTokenRevoked
+		return err
+	}
 
 	if err := tx.Commit(ctx); err != nil {
-		return nil, fmt.Errorf("%s: %w", op, err)
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+// Revoke the refresh token of the user, unknown or already revoked tokens are ignored
+func (r *pvzRepo) RevokeRefreshToken(ctx context.Context, userID uuid.UUID, tokenHash string) error {
+	const op = "repository.RevokeRefreshToken"
+
+	query := `
+		WITH revoked AS (
+			UPDATE refresh_tokens
+			SET revoked_at = CURRENT_TIMESTAMP
+			WHERE user_id = $1 AND token_hash = $2 AND revoked_at IS NULL
+			RETURNING id
+		)
+		SELECT COUNT(*) FROM revoked
+	`
+
+	var revoked int
+	err := r.db.QueryRow(ctx, query, userID, tokenHash).Scan(&revoked)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+// Revoke every active refresh token of the user
+func (r *pvzRepo) RevokeUserRefreshTokens(ctx context.Context, userID uuid.UUID) error {
+	const op = "repository.RevokeUserRefreshTokens"
+
+	query := `
+		WITH revoked AS (
+			UPDATE refresh_tokens
+			SET revoked_at = CURRENT_TIMESTAMP
+			WHERE user_id = $1 AND revoked_at IS NULL
+			RETURNING id
+		)
+		SELECT COUNT(*) FROM revoked
+	`
+
+	var revoked int
+	err := r.db.QueryRow(ctx, query, userID).Scan(&revoked)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+// Add the access token id to the denylist until the token expires
+func (r *pvzRepo) RevokeAccessToken(ctx context.Context, tokenID uuid.UUID, expiresAt time.Time) error {
+	const op = "repository.RevokeAccessToken"
+
+	query := `
+		INSERT INTO revoked_tokens (jti, expires_at)
+		VALUES ($1, $2)
+		ON CONFLICT (jti) DO NOTHING
+		RETURNING jti
+	`
+
+	var id uuid.UUID
+	err := r.db.QueryRow(ctx, query, tokenID, expiresAt).Scan(&id)
+	if err != nil {
+		// The token is already in the denylist
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil
+		}
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+// Get ids of the revoked access tokens that have not expired yet
+func (r *pvzRepo) GetRevokedAccessTokens(ctx context.Context) ([]uuid.UUID, error) {
+	const op = "repository.GetRevokedAccessTokens"
+
+	query := `
+		SELECT jti
+		FROM revoked_tokens
+		WHERE expires_at > CURRENT_TIMESTAMP
+	`
+
+	rows, err := r.db.Query(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer rows.Close()
+
+	tokenIDs := []uuid.UUID{}
+	for rows.Next() {
+		var tokenID uuid.UUID
+		if err := rows.Scan(&tokenID); err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+		tokenIDs = append(tokenIDs, tokenID)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return tokenIDs, nil
+}
+
+// Delete expired refresh tokens and denylist entries, returns the number of deleted rows
+func (r *pvzRepo) DeleteExpiredTokens(ctx context.Context) (int64, error) {
+	const op = "repository.DeleteExpiredTokens"
+
+	query := `
+		WITH refresh AS (
+			DELETE FROM refresh_tokens
+			WHERE expires_at <= CURRENT_TIMESTAMP
+			RETURNING 1
+		), revoked AS (
+			DELETE FROM revoked_tokens
+			WHERE expires_at <= CURRENT_TIMESTAMP
+			RETURNING 1
+		)
+		SELECT (SELECT COUNT(*) FROM refresh) + (SELECT COUNT(*) FROM revoked)
+	`
+
+	var deleted int64
+	err := r.db.QueryRow(ctx, query).Scan(&deleted)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return deleted, nil
+}
+
+// Count a login attempt for the key before the password is checked, so parallel attempts cannot exceed the limit.
+// The counter starts over if the previous attempt happened before resetBefore.
+func (r *pvzRepo) ClaimLoginAttempt(ctx context.Context, key string, maxAttempts int, at, resetBefore time.Time) (int, error) {
+	const op = "repository.ClaimLoginAttempt"
+
+	// Once the limit is reached only the first attempt after the lockout is admitted
+	query := `
+		INSERT INTO login_attempts (key, failures, last_attempt_at)
+		VALUES ($1, 1, $2)
+		ON CONFLICT (key) DO UPDATE
+		SET failures = CASE WHEN login_attempts.last_attempt_at < $3 THEN 1 ELSE login_attempts.failures + 1 END,
+			last_attempt_at = EXCLUDED.last_attempt_at,
+			locked_until = NULL
+		WHERE (login_attempts.locked_until IS NULL OR login_attempts.locked_until <= $2)
+			AND (login_attempts.locked_until IS NOT NULL OR login_attempts.failures < $4 OR login_attempts.last_attempt_at < $3)
+		RETURNING failures
+	`
+
+	var failures int
+	err := r.db.QueryRow(ctx, query, key, at, resetBefore, maxAttempts).Scan(&failures)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return 0, db.ErrNoLoginAttemptsLeft
+		}
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return failures, nil
+}
+
+// Lock the key until the given time, a longer lock set in parallel is kept
+func (r *pvzRepo) LockLoginAttempts(ctx context.Context, key string, lockedUntil time.Time) error {
+	const op = "repository.LockLoginAttempts"
+
+	query := `
+		UPDATE login_attempts
+		SET locked_until = GREATEST(COALESCE(locked_until, $2), $2)
+		WHERE key = $1
+		RETURNING key
+	`
+
+	var locked string
+	err := r.db.QueryRow(ctx, query, key, lockedUntil).Scan(&locked)
+	if err != nil {
+		// The attempts were reset by a successful login in the meantime
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil
+		}
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+// Give back an attempt claimed for the key that did not turn out to be a failure
+func (r *pvzRepo) ReleaseLoginAttempt(ctx context.Context, key string) error {
+	const op = "repository.ReleaseLoginAttempt"
+
+	query := `
+		UPDATE login_attempts
+		SET failures = failures - 1
+		WHERE key = $1 AND failures > 0
+		RETURNING key
+	`
+
+	var released string
+	err := r.db.QueryRow(ctx, query, key).Scan(&released)
+	if err != nil {
+		// The attempts were reset by a successful login in the meantime
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil
+		}
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+// Forget failed logins of the key
+func (r *pvzRepo) ResetLoginFailures(ctx context.Context, key string) error {
+	const op = "repository.ResetLoginFailures"
+
+	query := `
+		DELETE FROM login_attempts
+		WHERE key = $1
+		RETURNING key
+	`
+
+	var deleted string
+	err := r.db.QueryRow(ctx, query, key).Scan(&deleted)
+	if err != nil {
+		// There were no failures to forget
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil
+		}
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+// Delete login attempts whose last attempt happened before the given time, returns the number of deleted rows
+func (r *pvzRepo) DeleteStaleLoginAttempts(ctx context.Context, before time.Time) (int64, error) {
+	const op = "repository.DeleteStaleLoginAttempts"
+
+	query := `
+		WITH stale AS (
+			DELETE FROM login_attempts
+			WHERE last_attempt_at < $1 AND (locked_until IS NULL OR locked_until < $1)
+			RETURNING 1
+		)
+		SELECT COUNT(*) FROM stale
+	`
+
+	var deleted int64
+	err := r.db.QueryRow(ctx, query, before).Scan(&deleted)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return deleted, nil
+}
+
+// Grant the permission to the user, granting it again keeps the original grant
+func (r *pvzRepo) GrantPermission(ctx context.Context, userID uuid.UUID, permission models.Permission, grantedBy uuid.UUID) (*models.PermissionGrant, error) {
+	const op = "repository.GrantPermission"
+
+	query := `
+		INSERT INTO user_permissions (user_id, permission, granted_by)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (user_id, permission) DO UPDATE SET permission = EXCLUDED.permission
+		RETURNING user_id, permission, granted_by, granted_at
+	`
+
+	var grant models.PermissionGrant
+	err := r.db.QueryRow(ctx, query, userID, string(permission), actorID(grantedBy)).Scan(
+		&grant.UserID,
+		&grant.Permission,
+		&grant.GrantedBy,
+		&grant.GrantedAt,
+	)
+	if err != nil {
+		if db.IsForeignKeyViolation(err) {
+			return nil, db.ErrUserNotFound
+		}
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return &grant, nil
+}
+
+// Revoke the permission granted to the user
+func (r *pvzRepo) RevokePermission(ctx context.Context, userID uuid.UUID, permission models.Permission) error {
+	const op = "repository.RevokePermission"
+
+	query := `
+		DELETE FROM user_permissions
+		WHERE user_id = $1 AND permission = $2
+		RETURNING user_id
+	`
+
+	var id uuid.UUID
+	err := r.db.QueryRow(ctx, query, userID, string(permission)).Scan(&id)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return db.ErrPermissionNotGranted
+		}
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+// Get the permissions granted to the user
+func (r *pvzRepo) GetUserPermissions(ctx context.Context, userID uuid.UUID) ([]models.PermissionGrant, error) {
+	const op = "repository.GetUserPermissions"
+
+	query := `SELECT EXISTS (SELECT 1 FROM users WHERE id = $1)`
+
+	var exists bool
+	if err := r.db.QueryRow(ctx, query, userID).Scan(&exists); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	if !exists {
+		return nil, db.ErrUserNotFound
+	}
+
+	query = `
+		SELECT user_id, permission, granted_by, granted_at
+		FROM user_permissions
+		WHERE user_id = $1
+		ORDER BY permission
+	`
+
+	rows, err := r.db.Query(ctx, query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	grants, err := scanPermissionGrants(rows)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return grants, nil
+}
+
+// Get the permissions granted to all users
+func (r *pvzRepo) GetPermissionGrants(ctx context.Context) ([]models.PermissionGrant, error) {
+	const op = "repository.GetPermissionGrants"
+
+	query := `
+		SELECT user_id, permission, granted_by, granted_at
+		FROM user_permissions
+	`
+
+	rows, err := r.db.Query(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	grants, err := scanPermissionGrants(rows)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return grants, nil
+}
+
+// Create a new pvz
+func (r *pvzRepo) CreatePVZ(ctx context.Context, pvz models.PVZ) error {
+	const op = "repository.CreatePVZ"
+
+	query := `
+        INSERT INTO pvzs (id, city, registration_date, status)
+        VALUES ($1, $2, $3, $4)
+        ON CONFLICT (id) DO NOTHING
+        RETURNING id
+    `
+
+	var id string
+	err := r.db.QueryRow(ctx, query,
+		pvz.ID,
+		pvz.City,
+		pvz.RegistrationDate,
+		pvz.Status,
+	).Scan(&id)
+
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return db.ErrDuplicatePVZ
+		}
+		if db.IsForeignKeyViolation(err) {
+			return db.ErrCityNotFound
+		}
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+// Update pvz city and/or status
+func (r *pvzRepo) UpdatePVZ(ctx context.Context, pvzID uuid.UUID, city, status *string) (*models.PVZ, error) {
+	const op = "repository.UpdatePVZ"
+
+	query := `
+        UPDATE pvzs
+        SET city = COALESCE($2, city),
+            status = COALESCE($3, status)
+        WHERE id = $1
+        RETURNING id, city, registration_date, status
+    `
+
+	var pvz models.PVZ
+	err := r.db.QueryRow(ctx, query, pvzID, city, status).Scan(
+		&pvz.ID,
+		&pvz.City,
+		&pvz.RegistrationDate,
+		&pvz.Status,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, db.ErrPVZNotFound
+		}
+		if db.IsForeignKeyViolation(err) {
+			return nil, db.ErrCityNotFound
+		}
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return &pvz, nil
+}
+
+// Create a new reception
+func (r *pvzRepo) CreateReception(ctx context.Context, receptionID, pvzID, userID uuid.UUID, manifest []models.ManifestItem, barcodes []models.ManifestBarcode) (*models.Reception, error) {
+	const op = "repository.CreateReception"
+
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer func() {
+		if err != nil {
+			if rbErr := tx.Rollback(ctx); rbErr != nil && !errors.Is(rbErr, pgx.ErrTxClosed) {
+				log.Printf("%s: failed to rollback transaction: %v", op, rbErr)
+			}
+		}
+	}()
+
+	query := `
+		SELECT status
+		FROM pvzs
+		WHERE id = $1
+		FOR SHARE
+	`
+
+	var pvzStatus string
+	err = tx.QueryRow(ctx, query, pvzID).Scan(&pvzStatus)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, db.ErrReceptionConflict
+		}
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	if pvzStatus != string(pvzapi.Active) {
+		err = db.ErrPVZNotActive
+		return nil, err
+	}
+
+	query = `
+		INSERT INTO receptions (id, pvz_id, status, created_by)
+		SELECT $1, $2, $3::VARCHAR, $4
+		WHERE NOT EXISTS (
+			SELECT 1 FROM receptions WHERE pvz_id = $2 AND status = $3::VARCHAR
+		)
+		RETURNING id, date_time, pvz_id, status, created_by
+	`
+
+	defaultStatus := string(pvzapi.InProgress)
+
+	var reception models.Reception
+	err = tx.QueryRow(ctx, query, receptionID, pvzID, defaultStatus, actorID(userID)).Scan(
+		&reception.ID,
+		&reception.DateTime,
+		&reception.PvzID,
+		&reception.Status,
+		&reception.CreatedBy,
+	)
+
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, db.ErrReceptionConflict
+		}
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	if len(manifest) > 0 {
+		types := make([]string, len(manifest))
+		counts := make([]int, len(manifest))
+		for i, item := range manifest {
+			types[i] = item.Type
+			counts[i] = item.Count
+		}
+
+		query = `
+			INSERT INTO reception_manifest (reception_id, type, expected_count)
+			SELECT $1, unnest($2::VARCHAR[]), unnest($3::INTEGER[])
+		`
+
+		_, err = tx.Exec(ctx, query, reception.ID, types, counts)
+		if err != nil {
+			if db.IsForeignKeyViolation(err) {
+				err = db.ErrTypeNotFound
+				return nil, err
+			}
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+
+		reception.Manifest = manifest
+	}
+
+	if len(barcodes) > 0 {
+		codes := make([]string, len(barcodes))
+		types := make([]string, len(barcodes))
+		for i, item := range barcodes {
+			codes[i] = item.Barcode
+			types[i] = item.Type
+		}
+
+		query = `
+			INSERT INTO reception_manifest_barcodes (reception_id, barcode, type)
+			SELECT $1, unnest($2::VARCHAR[]), unnest($3::VARCHAR[])
+		`
+
+		_, err = tx.Exec(ctx, query, reception.ID, codes, types)
+		if err != nil {
+			if db.IsForeignKeyViolation(err) {
+				err = db.ErrTypeNotFound
+				return nil, err
+			}
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+
+		reception.ExpectedBarcodes = barcodes
+	}
+
+	expected, err := expectTransfers(ctx, tx, reception.ID, pvzID)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	if expected {
+		reception.Manifest, err = getManifest(ctx, tx, reception.ID)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return &reception, nil
+}
+
+// Add a product in the reception
+func (r *pvzRepo) AddProduct(ctx context.Context, productID, pvzID, userID uuid.UUID, productType string, barcode *string, cellNumber *int) (*models.Product, error) {
+	const op = "repository.AddProduct"
+
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer func() {
+		if err != nil {
+			if rbErr := tx.Rollback(ctx); rbErr != nil && !errors.Is(rbErr, pgx.ErrTxClosed) {
+				log.Printf("%s: failed to rollback transaction: %v", op, rbErr)
+			}
+		}
+	}()
+
+	query := `
+		SELECT r.id, p.status, p.capacity, p.reception_limit
+		FROM receptions r
+		JOIN pvzs p ON p.id = r.pvz_id
+        WHERE r.pvz_id = $1 AND r.status = $2::VARCHAR
+        LIMIT 1
+		FOR UPDATE OF r
+		FOR SHARE OF p
+	`
+
+	allowedStatus := string(pvzapi.InProgress)
+
+	var (
+		receptionID    uuid.UUID
+		pvzStatus      string
+		capacity       *int
+		receptionLimit *int
+	)
+	err = tx.QueryRow(ctx, query,
+		pvzID,
+		allowedStatus,
+	).Scan(&receptionID, &pvzStatus, &capacity, &receptionLimit)
+
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, db.ErrNoOpenReception
+		}
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	if pvzStatus != string(pvzapi.Active) {
+		err = db.ErrPVZNotActive
+		return nil, err
+	}
+
+	room, err := getIntakeRoom(ctx, tx, pvzID, receptionID, capacity, receptionLimit)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	if err = room.check(); err != nil {
+		return nil, err
+	}
+
+	cells, err := lockCells(ctx, tx, pvzID)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	cell, err := assignCell(cells, cellNumber)
+	if err != nil {
+		return nil, err
+	}
+
+	var cellID *uuid.UUID
+	if cell != nil {
+		cellID = &cell.ID
+	}
+
+	query = `
+		UPDATE receptions
+		SET last_line_number = last_line_number + 1, last_activity_at = CURRENT_TIMESTAMP
+		WHERE id = $1
+		RETURNING last_line_number
+	`
+
+	var lineNumber int
+	err = tx.QueryRow(ctx, query, receptionID).Scan(&lineNumber)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	query = `
+		INSERT INTO products (id, type, reception_id, created_by, barcode, cell_id, line_number)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING id, date_time, type, reception_id, line_number, created_by, barcode, status, cell_id
+	`
+
+	var product models.Product
+	err = tx.QueryRow(ctx, query,
+		productID,
+		productType,
+		receptionID,
+		actorID(userID),
+		barcode,
+		cellID,
+		lineNumber,
+	).Scan(
+		&product.ID,
+		&product.DateTime,
+		&product.Type,
+		&product.ReceptionID,
+		&product.LineNumber,
+		&product.CreatedBy,
+		&product.Barcode,
+		&product.Status,
+		&product.CellID,
+	)
+	if err != nil {
+		if db.IsForeignKeyViolation(err) {
+			err = db.ErrTypeNotFound
+			return nil, err
+		}
+		if db.IsUniqueViolation(err) {
+			err = db.ErrDuplicateBarcode
+			return nil, err
+		}
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	if barcode != nil {
+		err = deliverTransfers(ctx, tx, receptionID, pvzID)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	if cell != nil {
+		product.CellNumber = &cell.Number
+	}
+
+	return &product, nil
+}
+
+// Delete the last product from the reception
+func (r *pvzRepo) DeleteLastProduct(ctx context.Context, pvzID uuid.UUID) error {
+	const op = "repository.DeleteLastProduct"
+
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	defer func() {
+		if err != nil {
+			if rbErr := tx.Rollback(ctx); rbErr != nil && !errors.Is(rbErr, pgx.ErrTxClosed) {
+				log.Printf("%s: failed to rollback transaction: %v", op, rbErr)
+			}
+		}
+	}()
+
+	query := `
+        SELECT id 
+        FROM receptions 
+        WHERE pvz_id = $1 AND status = $2::VARCHAR
+        LIMIT 1
+        FOR UPDATE
+    `
+
+	allowedStatus := string(pvzapi.InProgress)
+
+	var receptionID uuid.UUID
+	err = tx.QueryRow(ctx, query,
+		pvzID,
+		allowedStatus,
+	).Scan(&receptionID)
+
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return db.ErrNoOpenReception
+		}
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	query = `
+        DELETE FROM products
+        WHERE id = (
+            SELECT id 
+            FROM products 
+            WHERE reception_id = $1
+            ORDER BY line_number DESC
+            LIMIT 1
+        )
+        RETURNING id
+    `
+
+	var deletedID uuid.UUID
+	err = tx.QueryRow(ctx, query, receptionID).Scan(&deletedID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return db.ErrNoProducts
+		}
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+// Add the batch of products to the open reception with a single copy
+func (r *pvzRepo) AddProducts(ctx context.Context, pvzID, userID uuid.UUID, products []models.Product) ([]models.ProductBatchResult, error) {
+	const op = "repository.AddProducts"
+
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer func() {
+		if err != nil {
+			if rbErr := tx.Rollback(ctx); rbErr != nil && !errors.Is(rbErr, pgx.ErrTxClosed) {
+				log.Printf("%s: failed to rollback transaction: %v", op, rbErr)
+			}
+		}
+	}()
+
+	query := `
+		SELECT r.id, p.status, p.capacity, p.reception_limit
+		FROM receptions r
+		JOIN pvzs p ON p.id = r.pvz_id
+		WHERE r.pvz_id = $1 AND r.status = $2::VARCHAR
+		LIMIT 1
+		FOR UPDATE OF r
+		FOR SHARE OF p
+	`
+
+	var (
+		receptionID    uuid.UUID
+		pvzStatus      string
+		capacity       *int
+		receptionLimit *int
+	)
+	err = tx.QueryRow(ctx, query,
+		pvzID,
+		string(pvzapi.InProgress),
+	).Scan(&receptionID, &pvzStatus, &capacity, &receptionLimit)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, db.ErrNoOpenReception
+		}
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	if pvzStatus != string(pvzapi.Active) {
+		err = db.ErrPVZNotActive
+		return nil, err
+	}
+
+	var barcodes []string
+	for _, product := range products {
+		if product.Barcode != nil {
+			barcodes = append(barcodes, *product.Barcode)
+		}
+	}
+
+	seen := make(map[string]struct{}, len(barcodes))
+	if len(barcodes) > 0 {
+		query = `
+			SELECT barcode
+			FROM products
+			WHERE reception_id = $1 AND barcode = ANY($2::VARCHAR[])
+		`
+
+		var rows pgx.Rows
+		rows, err = tx.Query(ctx, query, receptionID, barcodes)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+
+		for rows.Next() {
+			var barcode string
+			if err = rows.Scan(&barcode); err != nil {
+				rows.Close()
+				return nil, fmt.Errorf("%s: %w", op, err)
+			}
+			seen[barcode] = struct{}{}
+		}
+		rows.Close()
+
+		if err = rows.Err(); err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+	}
+
+	room, err := getIntakeRoom(ctx, tx, pvzID, receptionID, capacity, receptionLimit)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	cells, err := lockCells(ctx, tx, pvzID)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	// Copied rows are stamped with the database clock, as single products are by the column default
+	query = `
+		SELECT last_line_number, CURRENT_TIMESTAMP
+		FROM receptions
+		WHERE id = $1
+	`
+
+	var (
+		lastLine int
+		now      time.Time
+	)
+	err = tx.QueryRow(ctx, query, receptionID).Scan(&lastLine, &now)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	results := make([]models.ProductBatchResult, len(products))
+	rows := make([][]any, 0, len(products))
+	for i, product := range products {
+		if product.Barcode != nil {
+			if _, ok := seen[*product.Barcode]; ok {
+				results[i].Err = db.ErrDuplicateBarcode
+				continue
+			}
+			seen[*product.Barcode] = struct{}{}
+		}
+
+		if roomErr := room.check(); roomErr != nil {
+			results[i].Err = roomErr
+			continue
+		}
+
+		cell, cellErr := assignCell(cells, product.CellNumber)
+		if cellErr != nil {
+			results[i].Err = cellErr
+			continue
+		}
+		room.take()
+
+		product.CellID, product.CellNumber = nil, nil
+		if cell != nil {
+			product.CellID, product.CellNumber = &cell.ID, &cell.Number
+		}
+
+		product.DateTime = now
+		product.ReceptionID = receptionID
+		product.LineNumber = lastLine + len(rows) + 1
+		product.CreatedBy = actorID(userID)
+		product.Status = string(pvzapi.Received)
+
+		rows = append(rows, []any{
+			product.ID,
+			product.DateTime,
+			product.Type,
+			product.ReceptionID,
+			product.LineNumber,
+			product.CreatedBy,
+			product.Barcode,
+			product.CellID,
+		})
+		results[i].Product = &product
+	}
+
+	if len(rows) > 0 {
+		_, err = tx.CopyFrom(ctx,
+			pgx.Identifier{"products"},
+			[]string{"id", "date_time", "type", "reception_id", "line_number", "created_by", "barcode", "cell_id"},
+			pgx.CopyFromRows(rows),
+		)
+		if err != nil {
+			if db.IsForeignKeyViolation(err) {
+				err = db.ErrTypeNotFound
+				return nil, err
+			}
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+
+		query = `
+			UPDATE receptions
+			SET last_line_number = $2, last_activity_at = CURRENT_TIMESTAMP
+			WHERE id = $1
+		`
+
+		_, err = tx.Exec(ctx, query, receptionID, lastLine+len(rows))
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+
+		if len(barcodes) > 0 {
+			err = deliverTransfers(ctx, tx, receptionID, pvzID)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", op, err)
+			}
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return results, nil
+}
+
+// Delete the product from the open reception
+func (r *pvzRepo) DeleteProduct(ctx context.Context, receptionID, productID uuid.UUID) error {
+	const op = "repository.DeleteProduct"
+
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	defer func() {
+		if err != nil {
+			if rbErr := tx.Rollback(ctx); rbErr != nil && !errors.Is(rbErr, pgx.ErrTxClosed) {
+				log.Printf("%s: failed to rollback transaction: %v", op, rbErr)
+			}
+		}
+	}()
+
+	query := `
+		SELECT status
+		FROM receptions
+		WHERE id = $1
+		FOR UPDATE
+	`
+
+	var status string
+	err = tx.QueryRow(ctx, query, receptionID).Scan(&status)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return db.ErrReceptionNotFound
+		}
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	if status != string(pvzapi.InProgress) {
+		err = db.ErrReceptionNotOpen
+		return err
+	}
+
+	query = `
+		DELETE FROM products
+		WHERE id = $1 AND reception_id = $2
+		RETURNING id
+	`
+
+	var deletedID uuid.UUID
+	err = tx.QueryRow(ctx, query, productID, receptionID).Scan(&deletedID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return db.ErrProductNotFound
+		}
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+// Close the last reception in pvz
+func (r *pvzRepo) CloseLastReception(ctx context.Context, pvzID, userID uuid.UUID) (*models.Reception, error) {
+	const op = "repository.CloseLastReception"
+
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer func() {
+		if err != nil {
+			if rbErr := tx.Rollback(ctx); rbErr != nil && !errors.Is(rbErr, pgx.ErrTxClosed) {
+				log.Printf("%s: failed to rollback transaction: %v", op, rbErr)
+			}
+		}
+	}()
+
+	query := `
+        SELECT id, date_time, pvz_id, created_by
+        FROM receptions 
+        WHERE pvz_id = $1 AND status = $2::VARCHAR
+        LIMIT 1
+        FOR UPDATE
+    `
+
+	allowedStatus := string(pvzapi.InProgress)
+
+	var reception models.Reception
+	err = tx.QueryRow(ctx, query,
+		pvzID,
+		allowedStatus,
+	).Scan(
+		&reception.ID,
+		&reception.DateTime,
+		&reception.PvzID,
+		&reception.CreatedBy,
+	)
+
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, db.ErrNoOpenReception
+		}
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	query = `
+        UPDATE receptions
+        SET status = $1, closed_by = $2
+        WHERE id = $3
+    `
+
+	newStatus := string(pvzapi.Close)
+
+	reception.ClosedBy = actorID(userID)
+
+	_, err = tx.Exec(ctx, query, newStatus, reception.ClosedBy, reception.ID)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	reception.Status = newStatus
+
+	err = storeProducts(ctx, tx, reception.ID)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	err = reconcileManifest(ctx, tx, &reception)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return &reception, nil
+}
+
+// Cancel the open reception of the pvz and delete its products
+func (r *pvzRepo) CancelLastReception(ctx context.Context, pvzID, userID uuid.UUID) (*models.Reception, error) {
+	const op = "repository.CancelLastReception"
+
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer func() {
+		if err != nil {
+			if rbErr := tx.Rollback(ctx); rbErr != nil && !errors.Is(rbErr, pgx.ErrTxClosed) {
+				log.Printf("%s: failed to rollback transaction: %v", op, rbErr)
+			}
+		}
+	}()
+
+	query := `
+		SELECT id, date_time, pvz_id, created_by
+		FROM receptions
+		WHERE pvz_id = $1 AND status = $2::VARCHAR
+		LIMIT 1
+		FOR UPDATE
+	`
+
+	var reception models.Reception
+	err = tx.QueryRow(ctx, query,
+		pvzID,
+		string(pvzapi.InProgress),
+	).Scan(
+		&reception.ID,
+		&reception.DateTime,
+		&reception.PvzID,
+		&reception.CreatedBy,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, db.ErrNoOpenReception
+		}
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	query = `
+		DELETE FROM products
+		WHERE reception_id = $1
+	`
+
+	_, err = tx.Exec(ctx, query, reception.ID)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	query = `
+		UPDATE receptions
+		SET status = $1, closed_by = $2
+		WHERE id = $3
+	`
+
+	newStatus := string(pvzapi.Cancelled)
+
+	reception.ClosedBy = actorID(userID)
+
+	_, err = tx.Exec(ctx, query, newStatus, reception.ClosedBy, reception.ID)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	reception.Status = newStatus
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return &reception, nil
+}
+
+// Close in-progress receptions without new products since the given time
+func (r *pvzRepo) CloseStaleReceptions(ctx context.Context, idleSince time.Time) ([]models.Reception, error) {
+	const op = "repository.CloseStaleReceptions"
+
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer func() {
+		if err != nil {
+			if rbErr := tx.Rollback(ctx); rbErr != nil && !errors.Is(rbErr, pgx.ErrTxClosed) {
+				log.Printf("%s: failed to rollback transaction: %v", op, rbErr)
+			}
+		}
+	}()
+
+	// Skip receptions locked by a concurrent intake, they are not idle
+	query := `
+		SELECT r.id
+		FROM receptions r
+		WHERE r.status = $1::VARCHAR
+			AND GREATEST(
+				r.last_activity_at,
+				(SELECT MAX(p.date_time) FROM products p WHERE p.reception_id = r.id),
+				r.date_time
+			) < $2
+		FOR UPDATE SKIP LOCKED
+	`
+
+	rows, err := tx.Query(ctx, query,
+		string(pvzapi.InProgress),
+		idleSince,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	var receptionIDs []uuid.UUID
+	for rows.Next() {
+		var receptionID uuid.UUID
+		if err = rows.Scan(&receptionID); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+		receptionIDs = append(receptionIDs, receptionID)
+	}
+	rows.Close()
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	if len(receptionIDs) == 0 {
+		if err := tx.Commit(ctx); err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+		return nil, nil
+	}
+
+	// Check idleness again, products committed before the lock are not in the first snapshot
+	query = `
+		UPDATE receptions r
+		SET status = $1, auto_closed = TRUE
+		WHERE r.id = ANY($2::UUID[])
+			AND r.status = $3::VARCHAR
+			AND GREATEST(
+				r.last_activity_at,
+				(SELECT MAX(p.date_time) FROM products p WHERE p.reception_id = r.id),
+				r.date_time
+			) < $4
+		RETURNING r.id, r.date_time, r.pvz_id, r.status, r.created_by, r.auto_closed
+	`
+
+	rows, err = tx.Query(ctx, query,
+		string(pvzapi.Close),
+		receptionIDs,
+		string(pvzapi.InProgress),
+		idleSince,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	var receptions []models.Reception
+	for rows.Next() {
+		var reception models.Reception
+		err = rows.Scan(
+			&reception.ID,
+			&reception.DateTime,
+			&reception.PvzID,
+			&reception.Status,
+			&reception.CreatedBy,
+			&reception.AutoClosed,
+		)
+		if err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+		receptions = append(receptions, reception)
+	}
+	rows.Close()
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	for i := range receptions {
+		err = storeProducts(ctx, tx, receptions[i].ID)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+
+		err = reconcileManifest(ctx, tx, &receptions[i])
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return receptions, nil
+}
+
+// Reopen the closed reception and record the action in its audit trail
+func (r *pvzRepo) ReopenReception(ctx context.Context, receptionID, userID uuid.UUID, reason string) (*models.Reception, error) {
+	const op = "repository.ReopenReception"
+
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer func() {
+		if err != nil {
+			if rbErr := tx.Rollback(ctx); rbErr != nil && !errors.Is(rbErr, pgx.ErrTxClosed) {
+				log.Printf("%s: failed to rollback transaction: %v", op, rbErr)
+			}
+		}
+	}()
+
+	query := `
+		SELECT r.status, p.status
+		FROM receptions r
+		JOIN pvzs p ON p.id = r.pvz_id
+		WHERE r.id = $1
+		FOR UPDATE OF r
+		FOR SHARE OF p
+	`
+
+	var receptionStatus, pvzStatus string
+	err = tx.QueryRow(ctx, query, receptionID).Scan(&receptionStatus, &pvzStatus)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, db.ErrReceptionNotFound
+		}
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	if receptionStatus != string(pvzapi.Close) {
+		err = db.ErrReceptionNotClosed
+		return nil, err
+	}
+
+	if pvzStatus != string(pvzapi.Active) {
+		err = db.ErrPVZNotActive
+		return nil, err
+	}
+
+	query = `
+		SELECT EXISTS (
+			SELECT 1 FROM products WHERE reception_id = $1 AND status <> $2::VARCHAR
+		)
+	`
+
+	var productsLeft bool
+	err = tx.QueryRow(ctx, query, receptionID, string(pvzapi.Stored)).Scan(&productsLeft)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	if productsLeft {
+		err = db.ErrProductsLeftPVZ
+		return nil, err
+	}
+
+	query = `
+		UPDATE receptions r
+		SET status = $2::VARCHAR, closed_by = NULL, auto_closed = FALSE, last_activity_at = CURRENT_TIMESTAMP
+		WHERE r.id = $1 AND NOT EXISTS (
+			SELECT 1 FROM receptions WHERE pvz_id = r.pvz_id AND status = $2::VARCHAR
+		)
+		RETURNING id, date_time, pvz_id, status, created_by
+	`
+
+	var reception models.Reception
+	err = tx.QueryRow(ctx, query, receptionID, string(pvzapi.InProgress)).Scan(
+		&reception.ID,
+		&reception.DateTime,
+		&reception.PvzID,
+		&reception.Status,
+		&reception.CreatedBy,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) || db.IsUniqueViolation(err) {
+			err = db.ErrReceptionConflict
+			return nil, err
+		}
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	query = `
+		UPDATE products
+		SET status = $2
+		WHERE reception_id = $1
+	`
+
+	_, err = tx.Exec(ctx, query, receptionID, string(pvzapi.Received))
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	query = `
+		DELETE FROM reception_discrepancies
+		WHERE reception_id = $1
+	`
+
+	_, err = tx.Exec(ctx, query, receptionID)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	query = `
+		DELETE FROM reception_barcode_discrepancies
+		WHERE reception_id = $1
+	`
+
+	_, err = tx.Exec(ctx, query, receptionID)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	query = `
+		INSERT INTO reception_audit (reception_id, action, reason, user_id)
+		VALUES ($1, $2, $3, $4)
+	`
+
+	_, err = tx.Exec(ctx, query, receptionID, string(pvzapi.Reopen), reason, actorID(userID))
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	reception.Manifest, err = getManifest(ctx, tx, receptionID)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	reception.ExpectedBarcodes, err = getManifestBarcodes(ctx, tx, receptionID)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return &reception, nil
+}
+
+// Get the list of pvzs with their receptions and products with pagination by PVZ count
+func (r *pvzRepo) GetPVZs(ctx context.Context, startDate, endDate *time.Time, excludeCancelled bool, productStatus *string, productCounts bool, limit, offset uint64) ([]*models.PVZWithReceptions, error) {
+	const op = "repository.GetPVZs"
+
+	receptionsJoin := "receptions r ON r.pvz_id = p.id"
+	var receptionsJoinArgs []any
+	if excludeCancelled {
+		receptionsJoin += " AND r.status <> ?"
+		receptionsJoinArgs = append(receptionsJoinArgs, string(pvzapi.Cancelled))
+	}
+
+	productsJoin := "products pr ON pr.reception_id = r.id"
+	var productsJoinArgs []any
+	if productStatus != nil {
+		productsJoin += " AND pr.status = ?"
+		productsJoinArgs = append(productsJoinArgs, *productStatus)
+	}
+
+	pvzQueryBuilder := sq.
+		Select("DISTINCT p.id").
+		From("pvzs p")
+
+	// With a product status only receptions that have such products and their pvzs are listed
+	if productStatus != nil {
+		pvzQueryBuilder = pvzQueryBuilder.
+			Join(receptionsJoin, receptionsJoinArgs...).
+			Join(productsJoin, productsJoinArgs...)
+	} else {
+		pvzQueryBuilder = pvzQueryBuilder.LeftJoin(receptionsJoin, receptionsJoinArgs...)
+	}
+
+	var conditions sq.And
+	if startDate != nil {
+		conditions = append(conditions, sq.GtOrEq{"r.date_time": *startDate})
+	}
+	if endDate != nil {
+		conditions = append(conditions, sq.LtOrEq{"r.date_time": *endDate})
+	}
+
+	if len(conditions) > 0 {
+		pvzQueryBuilder = pvzQueryBuilder.Where(conditions)
+	}
+
+	pvzQueryBuilder = pvzQueryBuilder.
+		Limit(limit).
+		Offset(offset)
+
+	pvzQuery, pvzArgs, err := pvzQueryBuilder.PlaceholderFormat(sq.Dollar).ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	rows, err := r.db.Query(ctx, pvzQuery, pvzArgs...)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	var pvzIDs []uuid.UUID
+	for rows.Next() {
+		var id uuid.UUID
+		if err := rows.Scan(&id); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+		pvzIDs = append(pvzIDs, id)
+	}
+	rows.Close()
+
+	if len(pvzIDs) == 0 {
+		return []*models.PVZWithReceptions{}, nil
+	}
+
+	columns := []string{
+		"p.id", "p.city", "p.registration_date", "p.status",
+		"r.id", "r.date_time", "r.status", "r.created_by", "r.closed_by", "r.auto_closed",
+	}
+	if productCounts {
+		columns = append(columns, "COUNT(pr.id)")
+	} else {
+		columns = append(columns, "pr.id", "pr.type", "pr.line_number", "pr.date_time", "pr.created_by", "pr.barcode", "pr.status")
+	}
+
+	queryBuilder := sq.
+		Select(columns...).
+		From("pvzs p")
+
+	if productStatus != nil {
+		queryBuilder = queryBuilder.
+			Join(receptionsJoin, receptionsJoinArgs...).
+			Join(productsJoin, productsJoinArgs...)
+	} else {
+		queryBuilder = queryBuilder.
+			LeftJoin(receptionsJoin, receptionsJoinArgs...).
+			LeftJoin(productsJoin, productsJoinArgs...)
+	}
+
+	queryBuilder = queryBuilder.Where(sq.Eq{"p.id": pvzIDs})
+
+	if productCounts {
+		queryBuilder = queryBuilder.
+			GroupBy("p.id", "r.id").
+			OrderBy("r.date_time DESC", "r.id")
+	} else {
+		queryBuilder = queryBuilder.OrderBy("r.date_time DESC", "r.id", "pr.line_number")
+	}
+
+	if len(conditions) > 0 {
+		queryBuilder = queryBuilder.Where(conditions)
+	}
+
+	query, args, err := queryBuilder.PlaceholderFormat(sq.Dollar).ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	rows, err = r.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer rows.Close()
+
+	var result []*models.PVZWithReceptions
+	var currentPVZ *models.PVZWithReceptions
+	var currentReception *models.ReceptionWithProducts
+
+	for rows.Next() {
+		var (
+			pvzID           uuid.UUID
+			pvzCity         string
+			pvzRegistration time.Time
+			pvzStatus       string
+			receptionID     *uuid.UUID
+			receptionDate   *time.Time
+			receptionStatus *string
+			receptionOpener *uuid.UUID
+			receptionCloser *uuid.UUID
+			receptionAuto   *bool
+			productID       *uuid.UUID
+			productType     *string
+			productLine     *int
+			productDate     *time.Time
+			productCreator  *uuid.UUID
+			productBarcode  *string
+			productStatus   *string
+			productsCount   int
+		)
+
+		dest := []any{
+			&pvzID,
+			&pvzCity,
+			&pvzRegistration,
+			&pvzStatus,
+			&receptionID,
+			&receptionDate,
+			&receptionStatus,
+			&receptionOpener,
+			&receptionCloser,
+			&receptionAuto,
+		}
+		if productCounts {
+			dest = append(dest, &productsCount)
+		} else {
+			dest = append(dest,
+				&productID,
+				&productType,
+				&productLine,
+				&productDate,
+				&productCreator,
+				&productBarcode,
+				&productStatus,
+			)
+		}
+
+		if err := rows.Scan(dest...); err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+
+		if currentPVZ == nil || currentPVZ.PVZ.ID != pvzID {
+			currentPVZ = &models.PVZWithReceptions{
+				PVZ: models.PVZ{
+					ID:               pvzID,
+					City:             pvzCity,
+					RegistrationDate: pvzRegistration,
+					Status:           pvzStatus,
+				},
+				Receptions: []*models.ReceptionWithProducts{},
+			}
+			result = append(result, currentPVZ)
+			currentReception = nil
+		}
+
+		if receptionID != nil {
+			if currentReception == nil || currentReception.Reception.ID != *receptionID {
+				currentReception = &models.ReceptionWithProducts{
+					Reception: models.Reception{
+						ID:         *receptionID,
+						PvzID:      pvzID,
+						DateTime:   *receptionDate,
+						Status:     *receptionStatus,
+						CreatedBy:  receptionOpener,
+						ClosedBy:   receptionCloser,
+						AutoClosed: receptionAuto != nil && *receptionAuto,
+					},
+					Products: []*models.Product{},
+				}
+				if productCounts {
+					currentReception.Products = nil
+					currentReception.ProductsCount = productsCount
+				}
+				currentPVZ.Receptions = append(currentPVZ.Receptions, currentReception)
+			}
+
+			if productID != nil {
+				product := &models.Product{
+					ID:          *productID,
+					DateTime:    *productDate,
+					Type:        *productType,
+					ReceptionID: *receptionID,
+					LineNumber:  *productLine,
+					CreatedBy:   productCreator,
+					Barcode:     productBarcode,
+					Status:      *productStatus,
+				}
+				currentReception.Products = append(currentReception.Products, product)
+			}
+		}
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return result, nil
+}
+
+// Get list of all pvzs
+func (r *pvzRepo) GetPVZList(ctx context.Context) ([]models.PVZ, error) {
+	const op = "repository.GetPVZList"
+
+	query := `
+		SELECT id, city, registration_date, status
+		FROM pvzs
+	`
+
+	rows, err := r.db.Query(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer rows.Close()
+
+	var pvzs []models.PVZ
+	for rows.Next() {
+		var pvz models.PVZ
+		err := rows.Scan(
+			&pvz.ID,
+			&pvz.City,
+			&pvz.RegistrationDate,
+			&pvz.Status,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+		pvzs = append(pvzs, pvz)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return pvzs, nil
+}
+
+// Get the pvz with its open reception, counters and transfers in transit
+func (r *pvzRepo) GetPVZ(ctx context.Context, pvzID uuid.UUID) (*models.PVZDetails, error) {
+	const op = "repository.GetPVZ"
+
+	query := `
+		SELECT p.id, p.city, p.registration_date, p.status,
+			r.id, r.date_time, r.status, r.created_by,
+			(SELECT COUNT(*) FROM products WHERE reception_id = r.id),
+			(SELECT COUNT(*) FROM receptions WHERE pvz_id = p.id),
+			(SELECT COUNT(*) FROM products pr JOIN receptions rr ON rr.id = pr.reception_id WHERE rr.pvz_id = p.id)
+		FROM pvzs p
+		LEFT JOIN receptions r ON r.pvz_id = p.id AND r.status = $2::VARCHAR
+		WHERE p.id = $1
+	`
+
+	var (
+		details         models.PVZDetails
+		receptionID     *uuid.UUID
+		receptionDate   *time.Time
+		receptionStatus *string
+		receptionOpener *uuid.UUID
+	)
+	err := r.db.QueryRow(ctx, query,
+		pvzID,
+		string(pvzapi.InProgress),
+	).Scan(
+		&details.PVZ.ID,
+		&details.PVZ.City,
+		&details.PVZ.RegistrationDate,
+		&details.PVZ.Status,
+		&receptionID,
+		&receptionDate,
+		&receptionStatus,
+		&receptionOpener,
+		&details.OpenReceptionProductsCount,
+		&details.ReceptionsCount,
+		&details.ProductsCount,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, db.ErrPVZNotFound
+		}
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	if receptionID != nil {
+		details.OpenReception = &models.Reception{
+			ID:        *receptionID,
+			DateTime:  *receptionDate,
+			PvzID:     details.PVZ.ID,
+			Status:    *receptionStatus,
+			CreatedBy: receptionOpener,
+		}
+	}
+
+	transfers, err := getTransfers(ctx, r.db, pvzID)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	for _, t := range transfers {
+		if t.ToPvzID == pvzID {
+			details.InboundTransfers = append(details.InboundTransfers, t)
+		} else {
+			details.OutboundTransfers = append(details.OutboundTransfers, t)
+		}
+	}
+
+	return &details, nil
+}
+
+// Get the reception with its products
+func (r *pvzRepo) GetReception(ctx context.Context, receptionID uuid.UUID) (*models.ReceptionWithProducts, error) {
+	const op = "repository.GetReception"
+
+	query := `
+		SELECT id, date_time, pvz_id, status, created_by, closed_by, auto_closed
+		FROM receptions
+		WHERE id = $1
+	`
+
+	var reception models.ReceptionWithProducts
+	err := r.db.QueryRow(ctx, query, receptionID).Scan(
+		&reception.Reception.ID,
+		&reception.Reception.DateTime,
+		&reception.Reception.PvzID,
+		&reception.Reception.Status,
+		&reception.Reception.CreatedBy,
+		&reception.Reception.ClosedBy,
+		&reception.Reception.AutoClosed,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, db.ErrReceptionNotFound
+		}
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	query = `
+		SELECT pr.id, pr.date_time, pr.type, pr.reception_id, pr.line_number, pr.created_by, pr.barcode,
+			pr.status, pr.cell_id, c.number
+		FROM products pr
+		LEFT JOIN storage_cells c ON c.id = pr.cell_id
+		WHERE pr.reception_id = $1
+		ORDER BY pr.line_number
+	`
+
+	rows, err := r.db.Query(ctx, query, receptionID)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer rows.Close()
+
+	reception.Products = []*models.Product{}
+	for rows.Next() {
+		var product models.Product
+		err := rows.Scan(
+			&product.ID,
+			&product.DateTime,
+			&product.Type,
+			&product.ReceptionID,
+			&product.LineNumber,
+			&product.CreatedBy,
+			&product.Barcode,
+			&product.Status,
+			&product.CellID,
+			&product.CellNumber,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+		reception.Products = append(reception.Products, &product)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	reception.Reception.Manifest, err = getManifest(ctx, r.db, receptionID)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	if len(reception.Reception.Manifest) > 0 && reception.Reception.Status == string(pvzapi.Close) {
+		reception.Reception.Discrepancies, err = getDiscrepancies(ctx, r.db, receptionID)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+	}
+
+	reception.Reception.ExpectedBarcodes, err = getManifestBarcodes(ctx, r.db, receptionID)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	if len(reception.Reception.ExpectedBarcodes) > 0 && reception.Reception.Status == string(pvzapi.Close) {
+		reception.Reception.BarcodeDiscrepancies, err = getBarcodeDiscrepancies(ctx, r.db, receptionID)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
 	}
 
 	return &reception, nil
 }
 
-// Get the list of pvzs with their receptions and products with pagination by PVZ count
-func (r *pvzRepo) GetPVZs(ctx context.Context, startDate, endDate *time.Time, limit, offset uint64) ([]*models.PVZWithReceptions, error) {
-	const op = "repository.GetPVZs"
+// Get the page of reception products after the line number
+func (r *pvzRepo) GetReceptionProducts(ctx context.Context, receptionID uuid.UUID, productType *string, cursor, limit int) ([]models.Product, error) {
+	const op = "repository.GetReceptionProducts"
+
+	query := `
+		SELECT EXISTS (SELECT 1 FROM receptions WHERE id = $1)
+	`
+
+	var exists bool
+	err := r.db.QueryRow(ctx, query, receptionID).Scan(&exists)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	if !exists {
+		return nil, db.ErrReceptionNotFound
+	}
+
+	query = `
+		SELECT pr.id, pr.date_time, pr.type, pr.reception_id, pr.line_number, pr.created_by, pr.barcode,
+			pr.status, pr.cell_id, c.number
+		FROM products pr
+		LEFT JOIN storage_cells c ON c.id = pr.cell_id
+		WHERE pr.reception_id = $1 AND pr.line_number > $2 AND ($3::VARCHAR IS NULL OR pr.type = $3)
+		ORDER BY pr.line_number
+		LIMIT $4
+	`
+
+	rows, err := r.db.Query(ctx, query, receptionID, cursor, productType, limit)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer rows.Close()
+
+	products := []models.Product{}
+	for rows.Next() {
+		var product models.Product
+		if err := rows.Scan(
+			&product.ID,
+			&product.DateTime,
+			&product.Type,
+			&product.ReceptionID,
+			&product.LineNumber,
+			&product.CreatedBy,
+			&product.Barcode,
+			&product.Status,
+			&product.CellID,
+			&product.CellNumber,
+		); err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+		products = append(products, product)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return products, nil
+}
+
+// Get the id of the pvz the reception belongs to
+func (r *pvzRepo) GetReceptionPVZID(ctx context.Context, receptionID uuid.UUID) (uuid.UUID, error) {
+	const op = "repository.GetReceptionPVZID"
+
+	query := `
+		SELECT pvz_id
+		FROM receptions
+		WHERE id = $1
+	`
+
+	var pvzID uuid.UUID
+	err := r.db.QueryRow(ctx, query, receptionID).Scan(&pvzID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return uuid.Nil, db.ErrReceptionNotFound
+		}
+		return uuid.Nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return pvzID, nil
+}
+
+// Get the id of the pvz the product was received in
+func (r *pvzRepo) GetProductPVZID(ctx context.Context, productID uuid.UUID) (uuid.UUID, error) {
+	const op = "repository.GetProductPVZID"
+
+	query := `
+		SELECT r.pvz_id
+		FROM products pr
+		JOIN receptions r ON r.id = pr.reception_id
+		WHERE pr.id = $1
+	`
+
+	var pvzID uuid.UUID
+	err := r.db.QueryRow(ctx, query, productID).Scan(&pvzID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return uuid.Nil, db.ErrProductNotFound
+		}
+		return uuid.Nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return pvzID, nil
+}
+
+// Move the product to the new status if its current status is one of the allowed ones
+func (r *pvzRepo) UpdateProductStatus(ctx context.Context, productID uuid.UUID, allowed []string, status string) (*models.Product, error) {
+	const op = "repository.UpdateProductStatus"
+
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer func() {
+		if err != nil {
+			if rbErr := tx.Rollback(ctx); rbErr != nil && !errors.Is(rbErr, pgx.ErrTxClosed) {
+				log.Printf("%s: failed to rollback transaction: %v", op, rbErr)
+			}
+		}
+	}()
+
+	query := `
+		SELECT status
+		FROM products
+		WHERE id = $1
+		FOR UPDATE
+	`
+
+	var current string
+	err = tx.QueryRow(ctx, query, productID).Scan(&current)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, db.ErrProductNotFound
+		}
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	if !slices.Contains(allowed, current) {
+		err = db.ErrInvalidTransition
+		return nil, err
+	}
+
+	query = `
+		UPDATE products
+		SET status = $2
+		WHERE id = $1
+		RETURNING id, date_time, type, reception_id, line_number, created_by, barcode, status
+	`
+
+	var product models.Product
+	err = tx.QueryRow(ctx, query, productID, status).Scan(
+		&product.ID,
+		&product.DateTime,
+		&product.Type,
+		&product.ReceptionID,
+		&product.LineNumber,
+		&product.CreatedBy,
+		&product.Barcode,
+		&product.Status,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return &product, nil
+}
+
+// Replace the pickup code of the stored product and reset failed attempts
+func (r *pvzRepo) SetPickupCode(ctx context.Context, productID uuid.UUID, codeHash string) error {
+	const op = "repository.SetPickupCode"
+
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	defer func() {
+		if err != nil {
+			if rbErr := tx.Rollback(ctx); rbErr != nil && !errors.Is(rbErr, pgx.ErrTxClosed) {
+				log.Printf("%s: failed to rollback transaction: %v", op, rbErr)
+			}
+		}
+	}()
+
+	query := `
+		SELECT status
+		FROM products
+		WHERE id = $1
+		FOR UPDATE
+	`
+
+	var status string
+	err = tx.QueryRow(ctx, query, productID).Scan(&status)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return db.ErrProductNotFound
+		}
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	if status != string(pvzapi.Stored) {
+		err = db.ErrInvalidTransition
+		return err
+	}
+
+	query = `
+		UPDATE products
+		SET pickup_code_hash = $2, pickup_attempts = 0, pickup_locked_until = NULL
+		WHERE id = $1
+	`
+
+	_, err = tx.Exec(ctx, query, productID, codeHash)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+// Count a pickup code attempt before the code is checked, so parallel attempts cannot exceed the limit
+func (r *pvzRepo) ClaimPickupAttempt(ctx context.Context, productID uuid.UUID, maxAttempts int, now time.Time) (*models.Product, error) {
+	const op = "repository.ClaimPickupAttempt"
+
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer func() {
+		if err != nil {
+			if rbErr := tx.Rollback(ctx); rbErr != nil && !errors.Is(rbErr, pgx.ErrTxClosed) {
+				log.Printf("%s: failed to rollback transaction: %v", op, rbErr)
+			}
+		}
+	}()
+
+	query := `
+		SELECT id, status, pickup_code_hash, pickup_attempts, pickup_locked_until
+		FROM products
+		WHERE id = $1
+		FOR UPDATE
+	`
+
+	var product models.Product
+	err = tx.QueryRow(ctx, query, productID).Scan(
+		&product.ID,
+		&product.Status,
+		&product.PickupCodeHash,
+		&product.PickupAttempts,
+		&product.PickupLockedUntil,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, db.ErrProductNotFound
+		}
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	if product.Status != string(pvzapi.Stored) {
+		err = db.ErrInvalidTransition
+		return nil, err
+	}
+
+	if product.PickupCodeHash == nil {
+		err = db.ErrNoPickupCode
+		return nil, err
+	}
+
+	if product.PickupLockedUntil != nil {
+		if now.Before(*product.PickupLockedUntil) {
+			err = db.ErrNoPickupAttemptsLeft
+			return nil, err
+		}
+		// The lockout is over, attempts are counted from scratch
+		product.PickupAttempts = 0
+		product.PickupLockedUntil = nil
+	}
+
+	// Attempts that are still being checked have used up the limit
+	if product.PickupAttempts >= maxAttempts {
+		err = db.ErrNoPickupAttemptsLeft
+		return nil, err
+	}
+	product.PickupAttempts++
+
+	query = `
+		UPDATE products
+		SET pickup_attempts = $2, pickup_locked_until = NULL
+		WHERE id = $1
+	`
+
+	_, err = tx.Exec(ctx, query, productID, product.PickupAttempts)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return &product, nil
+}
+
+// Record that the claimed attempt had a wrong code, the product is locked once the attempts reach the limit
+func (r *pvzRepo) FailPickupAttempt(ctx context.Context, productID uuid.UUID, maxAttempts int, lockedUntil time.Time) (*time.Time, error) {
+	const op = "repository.FailPickupAttempt"
+
+	query := `
+		UPDATE products
+		SET pickup_locked_until = CASE
+			WHEN pickup_attempts >= $2 THEN COALESCE(pickup_locked_until, $3::TIMESTAMPTZ)
+			ELSE pickup_locked_until
+		END
+		WHERE id = $1
+		RETURNING pickup_locked_until
+	`
+
+	var locked *time.Time
+	err := r.db.QueryRow(ctx, query, productID, maxAttempts, lockedUntil).Scan(&locked)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, db.ErrProductNotFound
+		}
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return locked, nil
+}
+
+// Issue the stored product if its pickup code has not changed since it was checked and it is not locked
+func (r *pvzRepo) IssueWithPickupCode(ctx context.Context, productID uuid.UUID, codeHash string, now time.Time) (*models.Product, error) {
+	const op = "repository.IssueWithPickupCode"
+
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer func() {
+		if err != nil {
+			if rbErr := tx.Rollback(ctx); rbErr != nil && !errors.Is(rbErr, pgx.ErrTxClosed) {
+				log.Printf("%s: failed to rollback transaction: %v", op, rbErr)
+			}
+		}
+	}()
+
+	query := `
+		SELECT status, pickup_code_hash, pickup_locked_until
+		FROM products
+		WHERE id = $1
+		FOR UPDATE
+	`
+
+	var (
+		status      string
+		currentHash *string
+		lockedUntil *time.Time
+	)
+	err = tx.QueryRow(ctx, query, productID).Scan(&status, &currentHash, &lockedUntil)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, db.ErrInvalidTransition
+		}
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	if status != string(pvzapi.Stored) || currentHash == nil || *currentHash != codeHash {
+		err = db.ErrInvalidTransition
+		return nil, err
+	}
+
+	// Wrong codes checked in parallel locked the product while this one was being checked
+	if lockedUntil != nil && now.Before(*lockedUntil) {
+		err = db.ErrNoPickupAttemptsLeft
+		return nil, err
+	}
+
+	query = `
+		UPDATE products
+		SET status = $2, pickup_code_hash = NULL, pickup_attempts = 0, pickup_locked_until = NULL
+		WHERE id = $1
+		RETURNING id, date_time, type, reception_id, line_number, created_by, barcode, status
+	`
+
+	var product models.Product
+	err = tx.QueryRow(ctx, query, productID, string(pvzapi.Issued)).Scan(
+		&product.ID,
+		&product.DateTime,
+		&product.Type,
+		&product.ReceptionID,
+		&product.LineNumber,
+		&product.CreatedBy,
+		&product.Barcode,
+		&product.Status,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return &product, nil
+}
+
+// Transfer the stored products of the closed reception to another pvz
+func (r *pvzRepo) CreateTransfers(ctx context.Context, receptionID, toPvzID, userID uuid.UUID, productIDs []uuid.UUID) ([]models.ProductTransfer, error) {
+	const op = "repository.CreateTransfers"
+
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer func() {
+		if err != nil {
+			if rbErr := tx.Rollback(ctx); rbErr != nil && !errors.Is(rbErr, pgx.ErrTxClosed) {
+				log.Printf("%s: failed to rollback transaction: %v", op, rbErr)
+			}
+		}
+	}()
+
+	query := `
+		SELECT pvz_id, status
+		FROM receptions
+		WHERE id = $1
+		FOR SHARE
+	`
+
+	var (
+		fromPvzID       uuid.UUID
+		receptionStatus string
+	)
+	err = tx.QueryRow(ctx, query, receptionID).Scan(&fromPvzID, &receptionStatus)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, db.ErrReceptionNotFound
+		}
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	if receptionStatus != string(pvzapi.Close) {
+		err = db.ErrReceptionNotClosed
+		return nil, err
+	}
+
+	query = `
+		SELECT status
+		FROM pvzs
+		WHERE id = $1
+		FOR SHARE
+	`
+
+	var pvzStatus string
+	err = tx.QueryRow(ctx, query, toPvzID).Scan(&pvzStatus)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, db.ErrPVZNotFound
+		}
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	if pvzStatus != string(pvzapi.Active) {
+		err = db.ErrPVZNotActive
+		return nil, err
+	}
+
+	query = `
+		SELECT status, barcode IS NOT NULL
+		FROM products
+		WHERE reception_id = $1 AND id = ANY($2::UUID[])
+		FOR UPDATE
+	`
+
+	rows, err := tx.Query(ctx, query, receptionID, productIDs)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	var (
+		found     int
+		notStored bool
+		noBarcode bool
+	)
+	for rows.Next() {
+		var (
+			status     string
+			hasBarcode bool
+		)
+		if err = rows.Scan(&status, &hasBarcode); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+		found++
+		notStored = notStored || status != string(pvzapi.Stored)
+		noBarcode = noBarcode || !hasBarcode
+	}
+	rows.Close()
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	if found < len(productIDs) {
+		err = db.ErrProductNotFound
+		return nil, err
+	}
+
+	if notStored {
+		err = db.ErrInvalidTransition
+		return nil, err
+	}
+
+	// The receiving pvz accepts transferred products by barcode only
+	if noBarcode {
+		err = db.ErrNoBarcode
+		return nil, err
+	}
+
+	query = `
+		UPDATE products
+		SET status = $2
+		WHERE id = ANY($1::UUID[])
+	`
+
+	_, err = tx.Exec(ctx, query, productIDs, string(pvzapi.Transferred))
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	query = `
+		WITH created AS (
+			INSERT INTO product_transfers (product_id, from_pvz_id, to_pvz_id, created_by)
+			SELECT unnest($1::UUID[]), $2, $3, $4
+			RETURNING id, product_id, from_pvz_id, to_pvz_id, reception_id, delivered_product_id, created_by, created_at
+		)
+		SELECT c.id, c.product_id, pr.type, pr.barcode, c.from_pvz_id, c.to_pvz_id,
+			c.reception_id, c.delivered_product_id, c.created_by, c.created_at
+		FROM created c
+		JOIN products pr ON pr.id = c.product_id
+		ORDER BY array_position($1::UUID[], c.product_id)
+	`
+
+	rows, err = tx.Query(ctx, query, productIDs, fromPvzID, toPvzID, actorID(userID))
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	transfers, err := scanTransfers(rows)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return transfers, nil
+}
+
+// Move the product that is still in the pvz to another storage cell
+func (r *pvzRepo) MoveProduct(ctx context.Context, productID uuid.UUID, cellNumber int) (*models.Product, error) {
+	const op = "repository.MoveProduct"
+
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer func() {
+		if err != nil {
+			if rbErr := tx.Rollback(ctx); rbErr != nil && !errors.Is(rbErr, pgx.ErrTxClosed) {
+				log.Printf("%s: failed to rollback transaction: %v", op, rbErr)
+			}
+		}
+	}()
+
+	query := `
+		SELECT pr.status, r.pvz_id
+		FROM products pr
+		JOIN receptions r ON r.id = pr.reception_id
+		WHERE pr.id = $1
+		FOR UPDATE OF pr
+	`
+
+	var (
+		status string
+		pvzID  uuid.UUID
+	)
+	err = tx.QueryRow(ctx, query, productID).Scan(&status, &pvzID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, db.ErrProductNotFound
+		}
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	if !slices.Contains(productsInPVZ, status) {
+		err = db.ErrInvalidTransition
+		return nil, err
+	}
+
+	query = `
+		SELECT c.id, c.capacity,
+			(SELECT COUNT(*) FROM products WHERE cell_id = c.id AND id <> $3 AND status = ANY($4::VARCHAR[]))
+		FROM storage_cells c
+		WHERE c.pvz_id = $1 AND c.number = $2
+		FOR UPDATE
+	`
+
+	var (
+		cellID   uuid.UUID
+		capacity int
+		occupied int
+	)
+	err = tx.QueryRow(ctx, query, pvzID, cellNumber, productID, productsInPVZ).Scan(&cellID, &capacity, &occupied)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			err = db.ErrCellNotFound
+			return nil, err
+		}
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	if occupied >= capacity {
+		err = db.ErrCellFull
+		return nil, err
+	}
+
+	query = `
+		UPDATE products
+		SET cell_id = $2
+		WHERE id = $1
+		RETURNING id, date_time, type, reception_id, line_number, created_by, barcode, status, cell_id
+	`
+
+	var product models.Product
+	err = tx.QueryRow(ctx, query, productID, cellID).Scan(
+		&product.ID,
+		&product.DateTime,
+		&product.Type,
+		&product.ReceptionID,
+		&product.LineNumber,
+		&product.CreatedBy,
+		&product.Barcode,
+		&product.Status,
+		&product.CellID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	product.CellNumber = &cellNumber
+
+	return &product, nil
+}
+
+// Find products with the barcode across all pvzs
+func (r *pvzRepo) FindProductsByBarcode(ctx context.Context, barcode string) ([]models.ProductLocation, error) {
+	const op = "repository.FindProductsByBarcode"
+
+	query := `
+		SELECT pr.id, pr.date_time, pr.type, pr.line_number, pr.created_by, pr.barcode, pr.status, pr.cell_id, c.number,
+			r.id, r.date_time, r.status, r.created_by, r.closed_by, r.auto_closed,
+			p.id, p.city, p.registration_date, p.status
+		FROM products pr
+		JOIN receptions r ON r.id = pr.reception_id
+		JOIN pvzs p ON p.id = r.pvz_id
+		LEFT JOIN storage_cells c ON c.id = pr.cell_id
+		WHERE pr.barcode = $1
+		ORDER BY pr.date_time
+	`
+
+	rows, err := r.db.Query(ctx, query, barcode)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer rows.Close()
+
+	locations := []models.ProductLocation{}
+	for rows.Next() {
+		var location models.ProductLocation
+		if err := rows.Scan(
+			&location.Product.ID,
+			&location.Product.DateTime,
+			&location.Product.Type,
+			&location.Product.LineNumber,
+			&location.Product.CreatedBy,
+			&location.Product.Barcode,
+			&location.Product.Status,
+			&location.Product.CellID,
+			&location.Product.CellNumber,
+			&location.Reception.ID,
+			&location.Reception.DateTime,
+			&location.Reception.Status,
+			&location.Reception.CreatedBy,
+			&location.Reception.ClosedBy,
+			&location.Reception.AutoClosed,
+			&location.PVZ.ID,
+			&location.PVZ.City,
+			&location.PVZ.RegistrationDate,
+			&location.PVZ.Status,
+		); err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+		location.Product.ReceptionID = location.Reception.ID
+		location.Reception.PvzID = location.PVZ.ID
+		locations = append(locations, location)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return locations, nil
+}
+
+// Get the audit trail of the reception
+func (r *pvzRepo) GetReceptionHistory(ctx context.Context, receptionID uuid.UUID) ([]models.ReceptionAuditRecord, error) {
+	const op = "repository.GetReceptionHistory"
+
+	query := `
+		SELECT EXISTS (SELECT 1 FROM receptions WHERE id = $1)
+	`
+
+	var exists bool
+	err := r.db.QueryRow(ctx, query, receptionID).Scan(&exists)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	if !exists {
+		return nil, db.ErrReceptionNotFound
+	}
+
+	query = `
+		SELECT id, reception_id, action, reason, user_id, created_at
+		FROM reception_audit
+		WHERE reception_id = $1
+		ORDER BY created_at
+	`
+
+	rows, err := r.db.Query(ctx, query, receptionID)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer rows.Close()
+
+	records := []models.ReceptionAuditRecord{}
+	for rows.Next() {
+		var record models.ReceptionAuditRecord
+		if err := rows.Scan(
+			&record.ID,
+			&record.ReceptionID,
+			&record.Action,
+			&record.Reason,
+			&record.UserID,
+			&record.CreatedAt,
+		); err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+		records = append(records, record)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return records, nil
+}
+
+// Move the received products of the closed reception to storage
+func storeProducts(ctx context.Context, tx pgx.Tx, receptionID uuid.UUID) error {
+	query := `
+		UPDATE products
+		SET status = $2
+		WHERE reception_id = $1 AND status = $3::VARCHAR
+	`
+
+	_, err := tx.Exec(ctx, query, receptionID, string(pvzapi.Stored), string(pvzapi.Received))
+	return err
+}
+
+// Free places left in the pvz and its open reception, nil means there is no limit
+type intakeRoom struct {
+	pvz       *int
+	reception *int
+}
+
+// Tokens from dummy login carry no user id, their actions are recorded without an author
+func actorID(userID uuid.UUID) *uuid.UUID {
+	if userID == uuid.Nil {
+		return nil
+	}
+	return &userID
+}
+
+// Count free places left in the pvz and its open reception
+func getIntakeRoom(ctx context.Context, tx pgx.Tx, pvzID, receptionID uuid.UUID, capacity, receptionLimit *int) (intakeRoom, error) {
+	var room intakeRoom
+	if capacity == nil && receptionLimit == nil {
+		return room, nil
+	}
+
+	query := `
+		SELECT
+			(SELECT COUNT(*)
+			FROM products pr
+			JOIN receptions r ON r.id = pr.reception_id
+			WHERE r.pvz_id = $1 AND pr.status = ANY($3::VARCHAR[])),
+			(SELECT COUNT(*) FROM products WHERE reception_id = $2)
+	`
+
+	var stored, received int
+	if err := tx.QueryRow(ctx, query, pvzID, receptionID, productsInPVZ).Scan(&stored, &received); err != nil {
+		return room, err
+	}
+
+	if capacity != nil {
+		free := *capacity - stored
+		room.pvz = &free
+	}
+	if receptionLimit != nil {
+		free := *receptionLimit - received
+		room.reception = &free
+	}
+
+	return room, nil
+}
+
+// Check that one more product fits into the pvz and its open reception
+func (r intakeRoom) check() error {
+	if r.reception != nil && *r.reception <= 0 {
+		return db.ErrReceptionLimit
+	}
+	if r.pvz != nil && *r.pvz <= 0 {
+		return db.ErrCapacityExceeded
+	}
+
+	return nil
+}
+
+// Take a place for one more product
+func (r intakeRoom) take() {
+	if r.pvz != nil {
+		*r.pvz--
+	}
+	if r.reception != nil {
+		*r.reception--
+	}
+}
+
+// Lock the storage cells of the pvz and count the products they hold
+func lockCells(ctx context.Context, tx pgx.Tx, pvzID uuid.UUID) ([]models.StorageCell, error) {
+	query := `
+		SELECT c.id, c.number, c.capacity,
+			(SELECT COUNT(*) FROM products WHERE cell_id = c.id AND status = ANY($2::VARCHAR[]))
+		FROM storage_cells c
+		WHERE c.pvz_id = $1
+		ORDER BY c.number
+		FOR UPDATE
+	`
+
+	rows, err := tx.Query(ctx, query, pvzID, productsInPVZ)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var cells []models.StorageCell
+	for rows.Next() {
+		cell := models.StorageCell{PvzID: pvzID}
+		if err := rows.Scan(&cell.ID, &cell.Number, &cell.Capacity, &cell.Occupied); err != nil {
+			return nil, err
+		}
+		cells = append(cells, cell)
+	}
+
+	return cells, rows.Err()
+}
+
+// Take a place in the requested cell or in the first cell with free space, nil means no free cell
+func assignCell(cells []models.StorageCell, number *int) (*models.StorageCell, error) {
+	for i := range cells {
+		cell := &cells[i]
+		if number != nil && cell.Number != *number {
+			continue
+		}
+
+		if cell.Occupied < cell.Capacity {
+			cell.Occupied++
+			return cell, nil
+		}
+
+		if number != nil {
+			return nil, db.ErrCellFull
+		}
+	}
+
+	if number != nil {
+		return nil, db.ErrCellNotFound
+	}
+
+	return nil, nil
+}
+
+// Add the transfers in transit to the pvz to the manifest of its new reception
+func expectTransfers(ctx context.Context, tx pgx.Tx, receptionID, pvzID uuid.UUID) (bool, error) {
+	query := `
+		WITH expected AS (
+			UPDATE product_transfers t
+			SET reception_id = $1
+			FROM products pr
+			WHERE pr.id = t.product_id AND t.to_pvz_id = $2 AND t.delivered_product_id IS NULL
+			RETURNING pr.type
+		)
+		INSERT INTO reception_manifest (reception_id, type, expected_count)
+		SELECT $1, type, COUNT(*) FROM expected GROUP BY type
+		ON CONFLICT (reception_id, type)
+		DO UPDATE SET expected_count = reception_manifest.expected_count + EXCLUDED.expected_count
+	`
+
+	tag, err := tx.Exec(ctx, query, receptionID, pvzID)
+	if err != nil {
+		return false, err
+	}
+
+	return tag.RowsAffected() > 0, nil
+}
+
+// Deliver the transfers in transit to the pvz whose products were scanned into the reception
+func deliverTransfers(ctx context.Context, tx pgx.Tx, receptionID, pvzID uuid.UUID) error {
+	query := `
+		UPDATE product_transfers t
+		SET delivered_product_id = m.product_id
+		FROM (
+			SELECT DISTINCT ON (pr.id) pr.id AS product_id, pt.id AS transfer_id
+			FROM products pr
+			JOIN products src ON src.barcode = pr.barcode AND src.type = pr.type
+			JOIN product_transfers pt ON pt.product_id = src.id
+			WHERE pr.reception_id = $1 AND pt.to_pvz_id = $2 AND pt.delivered_product_id IS NULL
+				AND NOT EXISTS (SELECT 1 FROM product_transfers d WHERE d.delivered_product_id = pr.id)
+			ORDER BY pr.id, pt.created_at
+		) m
+		WHERE t.id = m.transfer_id
+	`
+
+	_, err := tx.Exec(ctx, query, receptionID, pvzID)
+	return err
+}
+
+// Get the transfers in transit from and to the pvz
+func getTransfers(ctx context.Context, q DB, pvzID uuid.UUID) ([]models.ProductTransfer, error) {
+	query := `
+		SELECT t.id, t.product_id, pr.type, pr.barcode, t.from_pvz_id, t.to_pvz_id,
+			t.reception_id, t.delivered_product_id, t.created_by, t.created_at
+		FROM product_transfers t
+		JOIN products pr ON pr.id = t.product_id
+		WHERE (t.from_pvz_id = $1 OR t.to_pvz_id = $1) AND t.delivered_product_id IS NULL
+		ORDER BY t.created_at
+	`
+
+	rows, err := q.Query(ctx, query, pvzID)
+	if err != nil {
+		return nil, err
+	}
+
+	return scanTransfers(rows)
+}
+
+// Scan transfer rows, a transfer is delivered once a product at the destination confirms it
+func scanTransfers(rows pgx.Rows) ([]models.ProductTransfer, error) {
+	defer rows.Close()
+
+	transfers := []models.ProductTransfer{}
+	for rows.Next() {
+		var t models.ProductTransfer
+		err := rows.Scan(
+			&t.ID,
+			&t.ProductID,
+			&t.Type,
+			&t.Barcode,
+			&t.FromPvzID,
+			&t.ToPvzID,
+			&t.ReceptionID,
+			&t.DeliveredProductID,
+			&t.CreatedBy,
+			&t.CreatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		t.Status = string(pvzapi.InTransit)
+		if t.DeliveredProductID != nil {
+			t.Status = string(pvzapi.Delivered)
+		}
+
+		transfers = append(transfers, t)
+	}
+
+	return transfers, rows.Err()
+}
+
+// Scan permission grant rows
+func scanPermissionGrants(rows pgx.Rows) ([]models.PermissionGrant, error) {
+	defer rows.Close()
+
+	grants := []models.PermissionGrant{}
+	for rows.Next() {
+		var grant models.PermissionGrant
+		err := rows.Scan(
+			&grant.UserID,
+			&grant.Permission,
+			&grant.GrantedBy,
+			&grant.GrantedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		grants = append(grants, grant)
+	}
+
+	return grants, rows.Err()
+}
+
+// Attach the manifests to the closed reception and store their discrepancy reports
+func reconcileManifest(ctx context.Context, q DB, reception *models.Reception) error {
+	manifest, err := getManifest(ctx, q, reception.ID)
+	if err != nil {
+		return err
+	}
+
+	reception.Manifest = manifest
+	if len(manifest) > 0 {
+		reception.Discrepancies, err = saveDiscrepancies(ctx, q, reception.ID)
+		if err != nil {
+			return err
+		}
+	}
+
+	barcodes, err := getManifestBarcodes(ctx, q, reception.ID)
+	if err != nil {
+		return err
+	}
+
+	reception.ExpectedBarcodes = barcodes
+	if len(barcodes) == 0 {
+		return nil
+	}
+
+	reception.BarcodeDiscrepancies, err = saveBarcodeDiscrepancies(ctx, q, reception.ID)
+
+	return err
+}
+
+// Get the expected manifest of the reception
+func getManifest(ctx context.Context, q DB, receptionID uuid.UUID) ([]models.ManifestItem, error) {
+	query := `
+		SELECT type, expected_count
+		FROM reception_manifest
+		WHERE reception_id = $1
+		ORDER BY type
+	`
+
+	rows, err := q.Query(ctx, query, receptionID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var manifest []models.ManifestItem
+	for rows.Next() {
+		var item models.ManifestItem
+		if err := rows.Scan(&item.Type, &item.Count); err != nil {
+			return nil, err
+		}
+		manifest = append(manifest, item)
+	}
+
+	return manifest, rows.Err()
+}
+
+// Compare the received products with the manifest and store the differences
+func saveDiscrepancies(ctx context.Context, q DB, receptionID uuid.UUID) ([]models.Discrepancy, error) {
+	query := `
+		INSERT INTO reception_discrepancies (reception_id, type, kind, expected_count, actual_count)
+		SELECT $1, COALESCE(m.type, p.type),
+			CASE
+				WHEN m.type IS NULL THEN $2::VARCHAR
+				WHEN COALESCE(p.actual, 0) < m.expected_count THEN $3::VARCHAR
+				ELSE $4::VARCHAR
+			END,
+			COALESCE(m.expected_count, 0), COALESCE(p.actual, 0)
+		FROM (
+			SELECT type, expected_count FROM reception_manifest WHERE reception_id = $1
+		) m
+		FULL JOIN (
+			SELECT type, COUNT(*) AS actual FROM products WHERE reception_id = $1 GROUP BY type
+		) p ON p.type = m.type
+		WHERE COALESCE(m.expected_count, 0) <> COALESCE(p.actual, 0)
+		RETURNING type, kind, expected_count, actual_count
+	`
+
+	rows, err := q.Query(ctx, query, receptionID,
+		string(pvzapi.Unexpected),
+		string(pvzapi.Missing),
+		string(pvzapi.Extra),
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	discrepancies, err := scanDiscrepancies(rows)
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(discrepancies, func(i, j int) bool {
+		return discrepancies[i].Type < discrepancies[j].Type
+	})
+
+	return discrepancies, nil
+}
+
+// Get the expected barcodes of the reception
+func getManifestBarcodes(ctx context.Context, q DB, receptionID uuid.UUID) ([]models.ManifestBarcode, error) {
+	query := `
+		SELECT barcode, type
+		FROM reception_manifest_barcodes
+		WHERE reception_id = $1
+		ORDER BY barcode
+	`
+
+	rows, err := q.Query(ctx, query, receptionID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var barcodes []models.ManifestBarcode
+	for rows.Next() {
+		var item models.ManifestBarcode
+		if err := rows.Scan(&item.Barcode, &item.Type); err != nil {
+			return nil, err
+		}
+		barcodes = append(barcodes, item)
+	}
+
+	return barcodes, rows.Err()
+}
+
+// Compare the received barcodes with the expected ones and store the differences
+func saveBarcodeDiscrepancies(ctx context.Context, q DB, receptionID uuid.UUID) ([]models.BarcodeDiscrepancy, error) {
+	query := `
+		INSERT INTO reception_barcode_discrepancies (reception_id, barcode, kind, expected_type, actual_type)
+		SELECT $1, COALESCE(m.barcode, p.barcode),
+			CASE
+				WHEN p.barcode IS NULL THEN $2::VARCHAR
+				WHEN m.barcode IS NULL THEN $3::VARCHAR
+				ELSE $4::VARCHAR
+			END,
+			m.type, p.type
+		FROM (
+			SELECT barcode, type FROM reception_manifest_barcodes WHERE reception_id = $1
+		) m
+		FULL JOIN (
+			SELECT barcode, type FROM products WHERE reception_id = $1 AND barcode IS NOT NULL
+		) p ON p.barcode = m.barcode
+		WHERE m.barcode IS NULL OR p.barcode IS NULL OR m.type <> p.type
+		RETURNING barcode, kind, expected_type, actual_type
+	`
+
+	rows, err := q.Query(ctx, query, receptionID,
+		string(pvzapi.Missing),
+		string(pvzapi.Extra),
+		string(pvzapi.MismatchedType),
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	discrepancies, err := scanBarcodeDiscrepancies(rows)
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(discrepancies, func(i, j int) bool {
+		return discrepancies[i].Barcode < discrepancies[j].Barcode
+	})
+
+	return discrepancies, nil
+}
+
+// Get the stored barcode discrepancy report of the reception
+func getBarcodeDiscrepancies(ctx context.Context, q DB, receptionID uuid.UUID) ([]models.BarcodeDiscrepancy, error) {
+	query := `
+		SELECT barcode, kind, expected_type, actual_type
+		FROM reception_barcode_discrepancies
+		WHERE reception_id = $1
+		ORDER BY barcode
+	`
+
+	rows, err := q.Query(ctx, query, receptionID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanBarcodeDiscrepancies(rows)
+}
+
+// Scan barcode discrepancy rows, an empty report means all expected barcodes were received
+func scanBarcodeDiscrepancies(rows pgx.Rows) ([]models.BarcodeDiscrepancy, error) {
+	discrepancies := []models.BarcodeDiscrepancy{}
+	for rows.Next() {
+		var d models.BarcodeDiscrepancy
+		if err := rows.Scan(&d.Barcode, &d.Kind, &d.ExpectedType, &d.ActualType); err != nil {
+			return nil, err
+		}
+		discrepancies = append(discrepancies, d)
+	}
+
+	return discrepancies, rows.Err()
+}
+
+// Get the stored discrepancy report of the reception
+func getDiscrepancies(ctx context.Context, q DB, receptionID uuid.UUID) ([]models.Discrepancy, error) {
+	query := `
+		SELECT type, kind, expected_count, actual_count
+		FROM reception_discrepancies
+		WHERE reception_id = $1
+		ORDER BY type
+	`
+
+	rows, err := q.Query(ctx, query, receptionID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanDiscrepancies(rows)
+}
+
+// Scan discrepancy rows, an empty report means the reception matches its manifest
+func scanDiscrepancies(rows pgx.Rows) ([]models.Discrepancy, error) {
+	discrepancies := []models.Discrepancy{}
+	for rows.Next() {
+		var d models.Discrepancy
+		if err := rows.Scan(&d.Type, &d.Kind, &d.Expected, &d.Actual); err != nil {
+			return nil, err
+		}
+		discrepancies = append(discrepancies, d)
+	}
+
+	return discrepancies, rows.Err()
+}
+
+// Get list of all cities
+func (r *pvzRepo) GetCities(ctx context.Context) ([]models.City, error) {
+	const op = "repository.GetCities"
+
+	query := `
+		SELECT id, name, created_at
+		FROM cities
+		ORDER BY name
+	`
+
+	rows, err := r.db.Query(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer rows.Close()
+
+	var cities []models.City
+	for rows.Next() {
+		var city models.City
+		err := rows.Scan(
+			&city.ID,
+			&city.Name,
+			&city.CreatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+		cities = append(cities, city)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return cities, nil
+}
+
+// Create a new city
+func (r *pvzRepo) CreateCity(ctx context.Context, city models.City) (*models.City, error) {
+	const op = "repository.CreateCity"
+
+	query := `
+        INSERT INTO cities (id, name)
+        VALUES ($1, $2)
+        ON CONFLICT DO NOTHING
+        RETURNING id, name, created_at
+    `
+
+	var created models.City
+	err := r.db.QueryRow(ctx, query, city.ID, city.Name).Scan(
+		&created.ID,
+		&created.Name,
+		&created.CreatedAt,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, db.ErrDuplicateCity
+		}
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return &created, nil
+}
+
+// Rename the city, pvzs follow the new name via ON UPDATE CASCADE
+func (r *pvzRepo) UpdateCity(ctx context.Context, cityID uuid.UUID, name string) (*models.City, error) {
+	const op = "repository.UpdateCity"
+
+	query := `
+        UPDATE cities
+        SET name = $2
+        WHERE id = $1
+        RETURNING id, name, created_at
+    `
+
+	var city models.City
+	err := r.db.QueryRow(ctx, query, cityID, name).Scan(
+		&city.ID,
+		&city.Name,
+		&city.CreatedAt,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, db.ErrCityNotFound
+		}
+		if db.IsUniqueViolation(err) {
+			return nil, db.ErrDuplicateCity
+		}
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return &city, nil
+}
+
+// Delete the city if there are no pvzs in it
+func (r *pvzRepo) DeleteCity(ctx context.Context, cityID uuid.UUID) error {
+	const op = "repository.DeleteCity"
+
+	query := `
+        DELETE FROM cities
+        WHERE id = $1
+        RETURNING id
+    `
+
+	var id uuid.UUID
+	err := r.db.QueryRow(ctx, query, cityID).Scan(&id)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return db.ErrCityNotFound
+		}
+		if db.IsForeignKeyViolation(err) {
+			return db.ErrCityInUse
+		}
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+// Get the product type catalog including retired types
+func (r *pvzRepo) GetProductTypes(ctx context.Context) ([]models.ProductType, error) {
+	const op = "repository.GetProductTypes"
+
+	query := `
+		SELECT id, name, display_name, retired_at
+		FROM product_types
+		ORDER BY name
+	`
+
+	rows, err := r.db.Query(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer rows.Close()
+
+	var types []models.ProductType
+	for rows.Next() {
+		var t models.ProductType
+		err := rows.Scan(
+			&t.ID,
+			&t.Name,
+			&t.DisplayName,
+			&t.RetiredAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+		types = append(types, t)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return types, nil
+}
+
+// Add a product type to the catalog, a retired type with the same name is brought back
+func (r *pvzRepo) CreateProductType(ctx context.Context, productType models.ProductType) (*models.ProductType, error) {
+	const op = "repository.CreateProductType"
+
+	query := `
+        INSERT INTO product_types (id, name, display_name)
+        VALUES ($1, $2, $3)
+        ON CONFLICT (name) DO UPDATE
+        SET display_name = EXCLUDED.display_name,
+            retired_at = NULL
+        WHERE product_types.retired_at IS NOT NULL
+        RETURNING id, name, display_name, retired_at
+    `
+
+	var created models.ProductType
+	err := r.db.QueryRow(ctx, query,
+		productType.ID,
+		productType.Name,
+		productType.DisplayName,
+	).Scan(
+		&created.ID,
+		&created.Name,
+		&created.DisplayName,
+		&created.RetiredAt,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, db.ErrDuplicateType
+		}
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return &created, nil
+}
+
+// Retire the product type so it can no longer be used for new products
+func (r *pvzRepo) RetireProductType(ctx context.Context, typeID uuid.UUID) error {
+	const op = "repository.RetireProductType"
 
-	pvzQueryBuilder := sq.
-		Select("DISTINCT p.id").
-		From("pvzs p").
-		LeftJoin("receptions r ON r.pvz_id = p.id")
+	query := `
+        UPDATE product_types
+        SET retired_at = COALESCE(retired_at, CURRENT_TIMESTAMP)
+        WHERE id = $1
+        RETURNING id
+    `
 
-	var conditions sq.And
-	if startDate != nil {
-		conditions = append(conditions, sq.GtOrEq{"r.date_time": *startDate})
+	var id uuid.UUID
+	err := r.db.QueryRow(ctx, query, typeID).Scan(&id)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return db.ErrTypeNotFound
+		}
+		return fmt.Errorf("%s: %w", op, err)
 	}
-	if endDate != nil {
-		conditions = append(conditions, sq.LtOrEq{"r.date_time": *endDate})
+
+	return nil
+}
+
+// Assign the employee to the pvz
+func (r *pvzRepo) AssignEmployee(ctx context.Context, pvzID, userID uuid.UUID) (*models.EmployeeAssignment, error) {
+	const op = "repository.AssignEmployee"
+
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer func() {
+		if err != nil {
+			if rbErr := tx.Rollback(ctx); rbErr != nil && !errors.Is(rbErr, pgx.ErrTxClosed) {
+				log.Printf("%s: failed to rollback transaction: %v", op, rbErr)
+			}
+		}
+	}()
+
+	query := `
+		SELECT role
+		FROM users
+		WHERE id = $1
+		FOR SHARE
+	`
+
+	var role string
+	err = tx.QueryRow(ctx, query, userID).Scan(&role)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, db.ErrUserNotFound
+		}
+		return nil, fmt.Errorf("%s: %w", op, err)
 	}
 
-	if len(conditions) > 0 {
-		pvzQueryBuilder = pvzQueryBuilder.Where(conditions)
+	if role != string(pvzapi.UserRoleEmployee) {
+		err = db.ErrNotEmployee
+		return nil, err
 	}
 
-	pvzQueryBuilder = pvzQueryBuilder.
-		Limit(limit).
-		Offset(offset)
+	query = `
+		INSERT INTO employee_pvz (user_id, pvz_id)
+		VALUES ($1, $2)
+		ON CONFLICT DO NOTHING
+		RETURNING user_id, pvz_id, assigned_at
+	`
 
-	pvzQuery, pvzArgs, err := pvzQueryBuilder.PlaceholderFormat(sq.Dollar).ToSql()
+	var assignment models.EmployeeAssignment
+	err = tx.QueryRow(ctx, query, userID, pvzID).Scan(
+		&assignment.UserID,
+		&assignment.PvzID,
+		&assignment.AssignedAt,
+	)
 	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, db.ErrAlreadyAssigned
+		}
+		if db.IsForeignKeyViolation(err) {
+			return nil, db.ErrPVZNotFound
+		}
 		return nil, fmt.Errorf("%s: %w", op, err)
 	}
 
-	rows, err := r.db.Query(ctx, pvzQuery, pvzArgs...)
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return &assignment, nil
+}
+
+// Remove the employee from the pvz
+func (r *pvzRepo) UnassignEmployee(ctx context.Context, pvzID, userID uuid.UUID) error {
+	const op = "repository.UnassignEmployee"
+
+	query := `
+		DELETE FROM employee_pvz
+		WHERE user_id = $1 AND pvz_id = $2
+		RETURNING user_id
+	`
+
+	var id uuid.UUID
+	err := r.db.QueryRow(ctx, query, userID, pvzID).Scan(&id)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return db.ErrNotAssigned
+		}
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+// Get employees assigned to the pvz
+func (r *pvzRepo) GetPVZEmployees(ctx context.Context, pvzID uuid.UUID) ([]models.User, error) {
+	const op = "repository.GetPVZEmployees"
+
+	query := `
+		SELECT u.id, u.email, u.role
+		FROM employee_pvz ep
+		JOIN users u ON u.id = ep.user_id
+		WHERE ep.pvz_id = $1
+		ORDER BY u.email
+	`
+
+	rows, err := r.db.Query(ctx, query, pvzID)
 	if err != nil {
 		return nil, fmt.Errorf("%s: %w", op, err)
 	}
+	defer rows.Close()
 
-	var pvzIDs []uuid.UUID
+	var users []models.User
 	for rows.Next() {
-		var id uuid.UUID
-		if err := rows.Scan(&id); err != nil {
-			rows.Close()
+		var user models.User
+		err := rows.Scan(
+			&user.ID,
+			&user.Email,
+			&user.Role,
+		)
+		if err != nil {
 			return nil, fmt.Errorf("%s: %w", op, err)
 		}
-		pvzIDs = append(pvzIDs, id)
+		users = append(users, user)
 	}
-	rows.Close()
 
-	if len(pvzIDs) == 0 {
-		return []*models.PVZWithReceptions{}, nil
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
 	}
 
-	queryBuilder := sq.
-		Select(
-			"p.id", "p.city", "p.registration_date",
-			"r.id", "r.date_time", "r.status",
-			"pr.id", "pr.type", "pr.date_time",
-		).
-		From("pvzs p").
-		LeftJoin("receptions r ON r.pvz_id = p.id").
-		LeftJoin("products pr ON pr.reception_id = r.id").
-		Where(sq.Eq{"p.id": pvzIDs}).
-		OrderBy("r.date_time DESC")
+	return users, nil
+}
 
-	if len(conditions) > 0 {
-		queryBuilder = queryBuilder.Where(conditions)
+// Check that the employee is assigned to the pvz
+func (r *pvzRepo) IsEmployeeAssigned(ctx context.Context, pvzID, userID uuid.UUID) (bool, error) {
+	const op = "repository.IsEmployeeAssigned"
+
+	query := `
+		SELECT EXISTS (
+			SELECT 1 FROM employee_pvz WHERE user_id = $1 AND pvz_id = $2
+		)
+	`
+
+	var assigned bool
+	err := r.db.QueryRow(ctx, query, userID, pvzID).Scan(&assigned)
+	if err != nil {
+		return false, fmt.Errorf("%s: %w", op, err)
 	}
 
-	query, args, err := queryBuilder.PlaceholderFormat(sq.Dollar).ToSql()
+	return assigned, nil
+}
+
+// Add storage cells to the pvz numbered after the existing ones
+func (r *pvzRepo) CreateCells(ctx context.Context, pvzID uuid.UUID, count, capacity int) ([]models.StorageCell, error) {
+	const op = "repository.CreateCells"
+
+	tx, err := r.db.Begin(ctx)
 	if err != nil {
 		return nil, fmt.Errorf("%s: %w", op, err)
 	}
+	defer func() {
+		if err != nil {
+			if rbErr := tx.Rollback(ctx); rbErr != nil && !errors.Is(rbErr, pgx.ErrTxClosed) {
+				log.Printf("%s: failed to rollback transaction: %v", op, rbErr)
+			}
+		}
+	}()
 
-	rows, err = r.db.Query(ctx, query, args...)
+	query := `
+		SELECT id
+		FROM pvzs
+		WHERE id = $1
+		FOR UPDATE
+	`
+
+	var id uuid.UUID
+	err = tx.QueryRow(ctx, query, pvzID).Scan(&id)
 	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, db.ErrPVZNotFound
+		}
 		return nil, fmt.Errorf("%s: %w", op, err)
 	}
-	defer rows.Close()
 
-	var result []*models.PVZWithReceptions
-	var currentPVZ *models.PVZWithReceptions
-	var currentReception *models.ReceptionWithProducts
+	query = `
+		INSERT INTO storage_cells (pvz_id, number, capacity)
+		SELECT $1, COALESCE((SELECT MAX(number) FROM storage_cells WHERE pvz_id = $1), 0) + n, $3
+		FROM generate_series(1, $2::INTEGER) n
+		RETURNING id, pvz_id, number, capacity, created_at
+	`
+
+	rows, err := tx.Query(ctx, query, pvzID, count, capacity)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
 
+	cells := make([]models.StorageCell, 0, count)
 	for rows.Next() {
-		var (
-			pvzID           uuid.UUID
-			pvzCity         string
-			pvzRegistration time.Time
-			receptionID     *uuid.UUID
-			receptionDate   *time.Time
-			receptionStatus *string
-			productID       *uuid.UUID
-			productType     *string
-			productDate     *time.Time
-		)
+		var cell models.StorageCell
+		if err = rows.Scan(&cell.ID, &cell.PvzID, &cell.Number, &cell.Capacity, &cell.CreatedAt); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+		cells = append(cells, cell)
+	}
+	rows.Close()
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return cells, nil
+}
+
+// Get storage cells of the pvz with their occupancy
+func (r *pvzRepo) GetCells(ctx context.Context, pvzID uuid.UUID) (*models.CellOccupancy, error) {
+	const op = "repository.GetCells"
 
+	query := `
+		SELECT
+			(SELECT COUNT(*)
+			FROM products pr
+			JOIN receptions r ON r.id = pr.reception_id
+			WHERE r.pvz_id = p.id AND pr.cell_id IS NULL AND pr.status = ANY($2::VARCHAR[]))
+		FROM pvzs p
+		WHERE p.id = $1
+	`
+
+	var occupancy models.CellOccupancy
+	err := r.db.QueryRow(ctx, query, pvzID, productsInPVZ).Scan(&occupancy.Unassigned)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, db.ErrPVZNotFound
+		}
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	query = `
+		SELECT c.id, c.pvz_id, c.number, c.capacity, c.created_at, COUNT(pr.id)
+		FROM storage_cells c
+		LEFT JOIN products pr ON pr.cell_id = c.id AND pr.status = ANY($2::VARCHAR[])
+		WHERE c.pvz_id = $1
+		GROUP BY c.id
+		ORDER BY c.number
+	`
+
+	rows, err := r.db.Query(ctx, query, pvzID, productsInPVZ)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer rows.Close()
+
+	occupancy.Cells = []models.StorageCell{}
+	for rows.Next() {
+		var cell models.StorageCell
 		if err := rows.Scan(
-			&pvzID,
-			&pvzCity,
-			&pvzRegistration,
-			&receptionID,
-			&receptionDate,
-			&receptionStatus,
-			&productID,
-			&productType,
-			&productDate,
+			&cell.ID,
+			&cell.PvzID,
+			&cell.Number,
+			&cell.Capacity,
+			&cell.CreatedAt,
+			&cell.Occupied,
 		); err != nil {
 			return nil, fmt.Errorf("%s: %w", op, err)
 		}
+		occupancy.Capacity += cell.Capacity
+		occupancy.Occupied += cell.Occupied
+		occupancy.Cells = append(occupancy.Cells, cell)
+	}
 
-		if currentPVZ == nil || currentPVZ.PVZ.ID != pvzID {
-			currentPVZ = &models.PVZWithReceptions{
-				PVZ: models.PVZ{
-					ID:               pvzID,
-					City:             pvzCity,
-					RegistrationDate: pvzRegistration,
-				},
-				Receptions: []*models.ReceptionWithProducts{},
-			}
-			result = append(result, currentPVZ)
-			currentReception = nil
-		}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
 
-		if receptionID != nil {
-			if currentReception == nil || currentReception.Reception.ID != *receptionID {
-				currentReception = &models.ReceptionWithProducts{
-					Reception: models.Reception{
-						ID:       *receptionID,
-						PvzID:    pvzID,
-						DateTime: *receptionDate,
-						Status:   *receptionStatus,
-					},
-					Products: []*models.Product{},
-				}
-				currentPVZ.Receptions = append(currentPVZ.Receptions, currentReception)
-			}
+	return &occupancy, nil
+}
 
-			if productID != nil {
-				product := &models.Product{
-					ID:          *productID,
-					DateTime:    *productDate,
-					Type:        *productType,
-					ReceptionID: *receptionID,
-				}
-				currentReception.Products = append(currentReception.Products, product)
-			}
+// Get capacity limits of the pvz with the number of products in it
+func (r *pvzRepo) GetPVZCapacity(ctx context.Context, pvzID uuid.UUID) (*models.PVZCapacity, error) {
+	const op = "repository.GetPVZCapacity"
+
+	query := `
+		SELECT p.id, p.capacity, p.reception_limit,
+			(SELECT COUNT(*)
+			FROM products pr
+			JOIN receptions r ON r.id = pr.reception_id
+			WHERE r.pvz_id = p.id AND pr.status = ANY($2::VARCHAR[]))
+		FROM pvzs p
+		WHERE p.id = $1
+	`
+
+	var capacity models.PVZCapacity
+	err := r.db.QueryRow(ctx, query, pvzID, productsInPVZ).Scan(
+		&capacity.PvzID,
+		&capacity.Capacity,
+		&capacity.ReceptionLimit,
+		&capacity.Stored,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, db.ErrPVZNotFound
 		}
+		return nil, fmt.Errorf("%s: %w", op, err)
 	}
 
-	if err := rows.Err(); err != nil {
+	return &capacity, nil
+}
+
+// Set capacity limits of the pvz, nil removes the limit
+func (r *pvzRepo) SetPVZCapacity(ctx context.Context, pvzID uuid.UUID, capacity, receptionLimit *int) (*models.PVZCapacity, error) {
+	const op = "repository.SetPVZCapacity"
+
+	query := `
+		UPDATE pvzs p
+		SET capacity = $2, reception_limit = $3
+		WHERE p.id = $1
+		RETURNING p.id, p.capacity, p.reception_limit,
+			(SELECT COUNT(*)
+			FROM products pr
+			JOIN receptions r ON r.id = pr.reception_id
+			WHERE r.pvz_id = p.id AND pr.status = ANY($4::VARCHAR[]))
+	`
+
+	var result models.PVZCapacity
+	err := r.db.QueryRow(ctx, query, pvzID, capacity, receptionLimit, productsInPVZ).Scan(
+		&result.PvzID,
+		&result.Capacity,
+		&result.ReceptionLimit,
+		&result.Stored,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, db.ErrPVZNotFound
+		}
 		return nil, fmt.Errorf("%s: %w", op, err)
 	}
 
-	return result, nil
+	return &result, nil
 }
 
-// Get list of all pvzs
-func (r *pvzRepo) GetPVZList(ctx context.Context) ([]models.PVZ, error) {
-	const op = "repository.GetPVZList"
+// Get the number of products in every pvz with a capacity limit
+func (r *pvzRepo) GetPVZUtilization(ctx context.Context) ([]models.PVZCapacity, error) {
+	const op = "repository.GetPVZUtilization"
 
 	query := `
-		SELECT id, city, registration_date
-		FROM pvzs
+		SELECT p.id, p.capacity, p.reception_limit, COUNT(pr.id)
+		FROM pvzs p
+		LEFT JOIN receptions r ON r.pvz_id = p.id
+		LEFT JOIN products pr ON pr.reception_id = r.id AND pr.status = ANY($1::VARCHAR[])
+		WHERE p.capacity IS NOT NULL
+		GROUP BY p.id
 	`
 
-	rows, err := r.db.Query(ctx, query)
+	rows, err := r.db.Query(ctx, query, productsInPVZ)
 	if err != nil {
 		return nil, fmt.Errorf("%s: %w", op, err)
 	}
 	defer rows.Close()
 
-	var pvzs []models.PVZ
+	utilization := []models.PVZCapacity{}
 	for rows.Next() {
-		var pvz models.PVZ
-		err := rows.Scan(
-			&pvz.ID,
-			&pvz.City,
-			&pvz.RegistrationDate,
-		)
-		if err != nil {
+		var capacity models.PVZCapacity
+		if err := rows.Scan(
+			&capacity.PvzID,
+			&capacity.Capacity,
+			&capacity.ReceptionLimit,
+			&capacity.Stored,
+		); err != nil {
 			return nil, fmt.Errorf("%s: %w", op, err)
 		}
-		pvzs = append(pvzs, pvz)
+		utilization = append(utilization, capacity)
 	}
 
 	if err := rows.Err(); err != nil {
 		return nil, fmt.Errorf("%s: %w", op, err)
 	}
 
-	return pvzs, nil
+	return utilization, nil
 }
@@ -9,6 +9,7 @@ import (
 
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/pashagolub/pgxmock/v4"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -45,10 +46,10 @@ func TestPVZRepo_GetUserByEmail(t *testing.T) {
 			name:  "user found",
 			email: testUser.Email,
 			mockSetup: func() {
-				rows := pgxmock.NewRows([]string{"id", "email", "password_hash", "role"}).
-					AddRow(testUser.ID, testUser.Email, testUser.PasswordHash, testUser.Role)
+				rows := pgxmock.NewRows([]string{"id", "email", "password_hash", "role", "deactivated_at"}).
+					AddRow(testUser.ID, testUser.Email, testUser.PasswordHash, testUser.Role, testUser.DeactivatedAt)
 
-				dbMock.ExpectQuery("SELECT id, email, password_hash, role FROM users WHERE email = \\$1").
+				dbMock.ExpectQuery("SELECT id, email, password_hash, role, deactivated_at FROM users WHERE email = \\$1").
 					WithArgs(testUser.Email).
 					WillReturnRows(rows)
 			},
@@ -59,7 +60,7 @@ func TestPVZRepo_GetUserByEmail(t *testing.T) {
 			name:  "user not found",
 			email: "test@example.com",
 			mockSetup: func() {
-				dbMock.ExpectQuery("SELECT id, email, password_hash, role FROM users WHERE email = \\$1").
+				dbMock.ExpectQuery("SELECT id, email, password_hash, role, deactivated_at FROM users WHERE email = \\$1").
 					WithArgs("test@example.com").
 					WillReturnError(pgx.ErrNoRows)
 			},
@@ -70,7 +71,7 @@ func TestPVZRepo_GetUserByEmail(t *testing.T) {
 			name:  "query error",
 			email: "test@example.com",
 			mockSetup: func() {
-				dbMock.ExpectQuery("SELECT id, email, password_hash, role FROM users WHERE email = \\$1").
+				dbMock.ExpectQuery("SELECT id, email, password_hash, role, deactivated_at FROM users WHERE email = \\$1").
 					WithArgs("test@example.com").
 					WillReturnError(ErrRandomError)
 			},
@@ -164,53 +165,61 @@ func TestPVZRepo_CreateUser(t *testing.T) {
 	}
 }
 
-func TestPVZRepo_CreatePVZ(t *testing.T) {
+func TestPVZRepo_GetUsers(t *testing.T) {
 	dbMock, err := pgxmock.NewPool()
 	require.NoError(t, err)
 	defer dbMock.Close()
 
 	repo := NewPVZRepo(dbMock)
 
-	testPVZ := models.PVZ{
-		ID:               uuid.New(),
-		City:             "Москва",
-		RegistrationDate: time.Now(),
+	createdAt := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	user := models.User{
+		ID:        uuid.New(),
+		Email:     "test_1@example.com",
+		Role:      "employee",
+		CreatedAt: createdAt,
 	}
+	columns := []string{"id", "email", "role", "created_at", "last_login_at", "deactivated_at"}
+	search := "test_1"
+	role := "employee"
 
 	tests := []struct {
 		name          string
-		pvz           models.PVZ
+		search        *string
+		role          *string
 		mockSetup     func()
+		expected      []models.User
 		expectedError error
 	}{
 		{
-			name: "successful creation",
-			pvz:  testPVZ,
+			name: "all users",
 			mockSetup: func() {
-				rows := pgxmock.NewRows([]string{"id"}).
-					AddRow(testPVZ.ID.String())
-				dbMock.ExpectQuery("INSERT INTO pvzs.*RETURNING id").
-					WithArgs(testPVZ.ID, testPVZ.City, testPVZ.RegistrationDate).
-					WillReturnRows(rows)
+				dbMock.ExpectQuery(regexp.QuoteMeta(
+					"SELECT id, email, role, created_at, last_login_at, deactivated_at FROM users ORDER BY created_at, id LIMIT 20 OFFSET 40",
+				)).
+					WillReturnRows(pgxmock.NewRows(columns).AddRow(user.ID, user.Email, user.Role, createdAt, nil, nil))
 			},
+			expected:      []models.User{user},
 			expectedError: nil,
 		},
 		{
-			name: "duplicate PVZ",
-			pvz:  testPVZ,
+			name:   "filtered by escaped search and role",
+			search: &search,
+			role:   &role,
 			mockSetup: func() {
-				dbMock.ExpectQuery("INSERT INTO pvzs.*RETURNING id").
-					WithArgs(testPVZ.ID, testPVZ.City, testPVZ.RegistrationDate).
-					WillReturnError(pgx.ErrNoRows)
+				dbMock.ExpectQuery(regexp.QuoteMeta(
+					"SELECT id, email, role, created_at, last_login_at, deactivated_at FROM users WHERE email ILIKE $1 AND role = $2 ORDER BY created_at, id LIMIT 20 OFFSET 40",
+				)).
+					WithArgs(`%test\_1%`, role).
+					WillReturnRows(pgxmock.NewRows(columns))
 			},
-			expectedError: db.ErrDuplicatePVZ,
+			expected:      []models.User{},
+			expectedError: nil,
 		},
 		{
 			name: "query error",
-			pvz:  testPVZ,
 			mockSetup: func() {
-				dbMock.ExpectQuery("INSERT INTO pvzs.*RETURNING id").
-					WithArgs(testPVZ.ID, testPVZ.City, testPVZ.RegistrationDate).
+				dbMock.ExpectQuery("SELECT id, email, role, created_at, last_login_at, deactivated_at FROM users").
 					WillReturnError(ErrRandomError)
 			},
 			expectedError: ErrRandomError,
@@ -221,79 +230,101 @@ func TestPVZRepo_CreatePVZ(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			tt.mockSetup()
 
-			err := repo.CreatePVZ(context.Background(), tt.pvz)
+			result, err := repo.GetUsers(context.Background(), tt.search, tt.role, 20, 40)
 
 			if tt.expectedError != nil {
 				assert.ErrorIs(t, err, tt.expectedError)
 			} else {
 				assert.NoError(t, err)
+				assert.Equal(t, tt.expected, result)
 			}
+			assert.NoError(t, dbMock.ExpectationsWereMet())
 		})
 	}
 }
 
-func TestPVZRepo_CreateReception(t *testing.T) {
+func TestPVZRepo_UpdateUserRole(t *testing.T) {
 	dbMock, err := pgxmock.NewPool()
 	require.NoError(t, err)
 	defer dbMock.Close()
 
 	repo := NewPVZRepo(dbMock)
 
-	receptionID := uuid.New()
-	pvzID := uuid.New()
-	defaultStatus := string(pvzapi.InProgress)
-
-	expectedReception := &models.Reception{
-		ID:       receptionID,
-		PvzID:    pvzID,
-		Status:   defaultStatus,
-		DateTime: time.Now(),
-	}
+	userID := uuid.New()
+	createdAt := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	columns := []string{"id", "email", "role", "created_at", "last_login_at", "deactivated_at"}
 
 	tests := []struct {
 		name          string
-		receptionID   uuid.UUID
-		pvzID         uuid.UUID
+		role          string
 		mockSetup     func()
-		expected      *models.Reception
+		expected      *models.User
 		expectedError error
 	}{
 		{
-			name:        "successful creation",
-			receptionID: receptionID,
-			pvzID:       pvzID,
+			name: "promoted employee loses assignments",
+			role: "moderator",
 			mockSetup: func() {
-				rows := pgxmock.NewRows([]string{"id", "date_time", "pvz_id", "status"}).
-					AddRow(expectedReception.ID, expectedReception.DateTime, expectedReception.PvzID, expectedReception.Status)
-				dbMock.ExpectQuery("INSERT INTO receptions.*RETURNING id, date_time, pvz_id, status").
-					WithArgs(receptionID, pvzID, defaultStatus).
-					WillReturnRows(rows)
+				dbMock.ExpectBegin()
+				dbMock.ExpectQuery("UPDATE users SET role = \\$2 WHERE id = \\$1").
+					WithArgs(userID, "moderator").
+					WillReturnRows(pgxmock.NewRows(columns).AddRow(userID, "test@example.com", "moderator", createdAt, nil, nil))
+				dbMock.ExpectExec("DELETE FROM employee_pvz WHERE user_id = \\$1").
+					WithArgs(userID).
+					WillReturnResult(pgxmock.NewResult("DELETE", 2))
+				dbMock.ExpectCommit()
+			},
+			expected: &models.User{
+				ID:        userID,
+				Email:     "test@example.com",
+				Role:      "moderator",
+				CreatedAt: createdAt,
 			},
-			expected:      expectedReception,
 			expectedError: nil,
 		},
 		{
-			name:        "conflict (already exists)",
-			receptionID: receptionID,
-			pvzID:       pvzID,
+			name: "employee keeps assignments",
+			role: "employee",
 			mockSetup: func() {
-				dbMock.ExpectQuery("INSERT INTO receptions.*RETURNING id, date_time, pvz_id, status").
-					WithArgs(receptionID, pvzID, defaultStatus).
+				dbMock.ExpectBegin()
+				dbMock.ExpectQuery("UPDATE users SET role = \\$2 WHERE id = \\$1").
+					WithArgs(userID, "employee").
+					WillReturnRows(pgxmock.NewRows(columns).AddRow(userID, "test@example.com", "employee", createdAt, nil, nil))
+				dbMock.ExpectCommit()
+			},
+			expected: &models.User{
+				ID:        userID,
+				Email:     "test@example.com",
+				Role:      "employee",
+				CreatedAt: createdAt,
+			},
+			expectedError: nil,
+		},
+		{
+			name: "user not found",
+			role: "moderator",
+			mockSetup: func() {
+				dbMock.ExpectBegin()
+				dbMock.ExpectQuery("UPDATE users").
+					WithArgs(userID, "moderator").
 					WillReturnError(pgx.ErrNoRows)
+				dbMock.ExpectRollback()
 			},
-			expected:      nil,
-			expectedError: db.ErrReceptionConflict,
+			expectedError: db.ErrUserNotFound,
 		},
 		{
-			name:        "query error",
-			receptionID: receptionID,
-			pvzID:       pvzID,
+			name: "delete assignments error",
+			role: "moderator",
 			mockSetup: func() {
-				dbMock.ExpectQuery("INSERT INTO receptions.*RETURNING id, date_time, pvz_id, status").
-					WithArgs(receptionID, pvzID, defaultStatus).
+				dbMock.ExpectBegin()
+				dbMock.ExpectQuery("UPDATE users").
+					WithArgs(userID, "moderator").
+					WillReturnRows(pgxmock.NewRows(columns).AddRow(userID, "test@example.com", "moderator", createdAt, nil, nil))
+				dbMock.ExpectExec("DELETE FROM employee_pvz").
+					WithArgs(userID).
 					WillReturnError(ErrRandomError)
+				dbMock.ExpectRollback()
 			},
-			expected:      nil,
 			expectedError: ErrRandomError,
 		},
 	}
@@ -302,7 +333,7 @@ func TestPVZRepo_CreateReception(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			tt.mockSetup()
 
-			result, err := repo.CreateReception(context.Background(), tt.receptionID, tt.pvzID)
+			result, err := repo.UpdateUserRole(context.Background(), userID, tt.role)
 
 			if tt.expectedError != nil {
 				assert.ErrorIs(t, err, tt.expectedError)
@@ -310,116 +341,65 @@ func TestPVZRepo_CreateReception(t *testing.T) {
 				assert.NoError(t, err)
 				assert.Equal(t, tt.expected, result)
 			}
+			assert.NoError(t, dbMock.ExpectationsWereMet())
 		})
 	}
 }
 
-func TestPVZRepo_AddProduct(t *testing.T) {
+func TestPVZRepo_SetUserDeactivated(t *testing.T) {
 	dbMock, err := pgxmock.NewPool()
 	require.NoError(t, err)
 	defer dbMock.Close()
 
 	repo := NewPVZRepo(dbMock)
 
-	productID := uuid.New()
-	pvzID := uuid.New()
-	productType := "обувь"
-	receptionID := uuid.New()
-	now := time.Now()
+	userID := uuid.New()
+	createdAt := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	deactivatedAt := time.Date(2025, 2, 1, 12, 0, 0, 0, time.UTC)
+	columns := []string{"id", "email", "role", "created_at", "last_login_at", "deactivated_at"}
+	query := "UPDATE users SET deactivated_at = CASE WHEN \\$2 THEN COALESCE\\(deactivated_at, CURRENT_TIMESTAMP\\) END WHERE id = \\$1"
 
 	tests := []struct {
 		name          string
+		deactivated   bool
 		mockSetup     func()
-		expected      *models.Product
+		expected      *models.User
 		expectedError error
 	}{
 		{
-			name: "success",
+			name:        "user deactivated",
+			deactivated: true,
 			mockSetup: func() {
-				dbMock.ExpectBegin()
-
-				rowsReception := pgxmock.NewRows([]string{"id"}).
-					AddRow(receptionID)
-				dbMock.ExpectQuery("SELECT id FROM receptions.*FOR UPDATE").
-					WithArgs(pvzID, string(pvzapi.InProgress)).
-					WillReturnRows(rowsReception)
-
-				rowsProduct := pgxmock.NewRows([]string{"id", "date_time", "type", "reception_id"}).
-					AddRow(productID, now, productType, receptionID)
-				dbMock.ExpectQuery("INSERT INTO products.*RETURNING id, date_time, type, reception_id").
-					WithArgs(productID, productType, receptionID).
-					WillReturnRows(rowsProduct)
-
-				dbMock.ExpectCommit()
+				dbMock.ExpectQuery(query).
+					WithArgs(userID, true).
+					WillReturnRows(pgxmock.NewRows(columns).AddRow(userID, "test@example.com", "employee", createdAt, nil, &deactivatedAt))
 			},
-			expected: &models.Product{
-				ID:          productID,
-				DateTime:    now,
-				Type:        productType,
-				ReceptionID: receptionID,
+			expected: &models.User{
+				ID:            userID,
+				Email:         "test@example.com",
+				Role:          "employee",
+				CreatedAt:     createdAt,
+				DeactivatedAt: &deactivatedAt,
 			},
 			expectedError: nil,
 		},
 		{
-			name: "no open reception",
+			name:        "user not found",
+			deactivated: false,
 			mockSetup: func() {
-				dbMock.ExpectBegin()
-
-				dbMock.ExpectQuery("SELECT id FROM receptions.*FOR UPDATE").
-					WithArgs(pvzID, string(pvzapi.InProgress)).
+				dbMock.ExpectQuery(query).
+					WithArgs(userID, false).
 					WillReturnError(pgx.ErrNoRows)
-
-				dbMock.ExpectRollback()
 			},
-			expected:      nil,
-			expectedError: db.ErrNoOpenReception,
+			expectedError: db.ErrUserNotFound,
 		},
 		{
-			name: "insert product error",
+			name:        "query error",
+			deactivated: true,
 			mockSetup: func() {
-				dbMock.ExpectBegin()
-
-				rowsReception := pgxmock.NewRows([]string{"id"}).
-					AddRow(receptionID)
-				dbMock.ExpectQuery("SELECT id FROM receptions.*FOR UPDATE").
-					WithArgs(pvzID, string(pvzapi.InProgress)).
-					WillReturnRows(rowsReception)
-
-				dbMock.ExpectQuery("INSERT INTO products.*RETURNING id, date_time, type, reception_id").
-					WithArgs(productID, productType, receptionID).
+				dbMock.ExpectQuery(query).
+					WithArgs(userID, true).
 					WillReturnError(ErrRandomError)
-
-				dbMock.ExpectRollback()
-			},
-			expected:      nil,
-			expectedError: ErrRandomError,
-		},
-		{
-			name: "begin transaction error",
-			mockSetup: func() {
-				dbMock.ExpectBegin().WillReturnError(ErrRandomError)
-			},
-			expected:      nil,
-			expectedError: ErrRandomError,
-		},
-		{
-			name: "commit transaction error",
-			mockSetup: func() {
-				dbMock.ExpectBegin()
-
-				rowsReception := pgxmock.NewRows([]string{"id"}).
-					AddRow(receptionID)
-				dbMock.ExpectQuery("SELECT id FROM receptions.*FOR UPDATE").
-					WithArgs(pvzID, string(pvzapi.InProgress)).
-					WillReturnRows(rowsReception)
-
-				rowsProduct := pgxmock.NewRows([]string{"id", "date_time", "type", "reception_id"}).
-					AddRow(productID, now, productType, receptionID)
-				dbMock.ExpectQuery("INSERT INTO products.*RETURNING id, date_time, type, reception_id").
-					WithArgs(productID, productType, receptionID).
-					WillReturnRows(rowsProduct)
-
-				dbMock.ExpectCommit().WillReturnError(ErrRandomError)
 			},
 			expectedError: ErrRandomError,
 		},
@@ -429,29 +409,28 @@ func TestPVZRepo_AddProduct(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			tt.mockSetup()
 
-			result, err := repo.AddProduct(context.Background(), productID, pvzID, productType)
+			result, err := repo.SetUserDeactivated(context.Background(), userID, tt.deactivated)
 
 			if tt.expectedError != nil {
 				assert.ErrorIs(t, err, tt.expectedError)
-				assert.Nil(t, result)
 			} else {
 				assert.NoError(t, err)
 				assert.Equal(t, tt.expected, result)
 			}
+			assert.NoError(t, dbMock.ExpectationsWereMet())
 		})
 	}
 }
 
-func TestPVZRepo_DeleteLastProduct(t *testing.T) {
+func TestPVZRepo_UpdateLastLogin(t *testing.T) {
 	dbMock, err := pgxmock.NewPool()
 	require.NoError(t, err)
 	defer dbMock.Close()
 
 	repo := NewPVZRepo(dbMock)
 
-	pvzID := uuid.New()
-	receptionID := uuid.New()
-	productID := uuid.New()
+	userID := uuid.New()
+	query := "UPDATE users SET last_login_at = CURRENT_TIMESTAMP WHERE id = \\$1 RETURNING id"
 
 	tests := []struct {
 		name          string
@@ -459,90 +438,89 @@ func TestPVZRepo_DeleteLastProduct(t *testing.T) {
 		expectedError error
 	}{
 		{
-			name: "success",
+			name: "login recorded",
 			mockSetup: func() {
-				dbMock.ExpectBegin()
-
-				dbMock.ExpectQuery("SELECT id FROM receptions.*FOR UPDATE").
-					WithArgs(pvzID, string(pvzapi.InProgress)).
-					WillReturnRows(pgxmock.NewRows([]string{"id"}).AddRow(receptionID))
-
-				dbMock.ExpectQuery("DELETE FROM products.*RETURNING id").
-					WithArgs(receptionID).
-					WillReturnRows(pgxmock.NewRows([]string{"id"}).AddRow(productID))
-
-				dbMock.ExpectCommit()
+				dbMock.ExpectQuery(query).
+					WithArgs(userID).
+					WillReturnRows(pgxmock.NewRows([]string{"id"}).AddRow(userID))
 			},
 			expectedError: nil,
 		},
 		{
-			name: "no open reception",
+			name: "user not found",
 			mockSetup: func() {
-				dbMock.ExpectBegin()
-
-				dbMock.ExpectQuery("SELECT id FROM receptions.*FOR UPDATE").
-					WithArgs(pvzID, string(pvzapi.InProgress)).
+				dbMock.ExpectQuery(query).
+					WithArgs(userID).
 					WillReturnError(pgx.ErrNoRows)
-
-				dbMock.ExpectRollback()
 			},
-			expectedError: db.ErrNoOpenReception,
+			expectedError: db.ErrUserNotFound,
 		},
 		{
-			name: "no products to delete",
+			name: "query error",
 			mockSetup: func() {
-				dbMock.ExpectBegin()
+				dbMock.ExpectQuery(query).
+					WithArgs(userID).
+					WillReturnError(ErrRandomError)
+			},
+			expectedError: ErrRandomError,
+		},
+	}
 
-				dbMock.ExpectQuery("SELECT id FROM receptions.*FOR UPDATE").
-					WithArgs(pvzID, string(pvzapi.InProgress)).
-					WillReturnRows(pgxmock.NewRows([]string{"id"}).AddRow(receptionID))
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockSetup()
 
-				dbMock.ExpectQuery("DELETE FROM products.*RETURNING id").
-					WithArgs(receptionID).
-					WillReturnError(pgx.ErrNoRows)
+			err := repo.UpdateLastLogin(context.Background(), userID)
 
-				dbMock.ExpectRollback()
-			},
-			expectedError: db.ErrNoProducts,
-		},
+			if tt.expectedError != nil {
+				assert.ErrorIs(t, err, tt.expectedError)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.NoError(t, dbMock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestPVZRepo_GetDeactivatedUsers(t *testing.T) {
+	dbMock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer dbMock.Close()
+
+	repo := NewPVZRepo(dbMock)
+
+	userIDs := []uuid.UUID{uuid.New(), uuid.New()}
+	query := "SELECT id FROM users WHERE deactivated_at IS NOT NULL"
+
+	tests := []struct {
+		name          string
+		mockSetup     func()
+		expected      []uuid.UUID
+		expectedError error
+	}{
 		{
-			name: "begin transaction error",
+			name: "users found",
 			mockSetup: func() {
-				dbMock.ExpectBegin().WillReturnError(ErrRandomError)
+				dbMock.ExpectQuery(query).
+					WillReturnRows(pgxmock.NewRows([]string{"id"}).AddRow(userIDs[0]).AddRow(userIDs[1]))
 			},
-			expectedError: ErrRandomError,
+			expected:      userIDs,
+			expectedError: nil,
 		},
 		{
-			name: "commit transaction error",
+			name: "no users",
 			mockSetup: func() {
-				dbMock.ExpectBegin()
-
-				dbMock.ExpectQuery("SELECT id FROM receptions.*FOR UPDATE").
-					WithArgs(pvzID, string(pvzapi.InProgress)).
-					WillReturnRows(pgxmock.NewRows([]string{"id"}).AddRow(receptionID))
-
-				dbMock.ExpectQuery("DELETE FROM products.*RETURNING id").
-					WithArgs(receptionID).
-					WillReturnRows(pgxmock.NewRows([]string{"id"}).AddRow(productID))
-
-				dbMock.ExpectCommit().WillReturnError(ErrRandomError)
+				dbMock.ExpectQuery(query).
+					WillReturnRows(pgxmock.NewRows([]string{"id"}))
 			},
-			expectedError: ErrRandomError,
+			expected:      []uuid.UUID{},
+			expectedError: nil,
 		},
 		{
-			name: "delete product error",
+			name: "query error",
 			mockSetup: func() {
-				dbMock.ExpectBegin()
-
-				dbMock.ExpectQuery("SELECT id FROM receptions.*FOR UPDATE").
-					WithArgs(pvzID, string(pvzapi.InProgress)).
-					WillReturnRows(pgxmock.NewRows([]string{"id"}).AddRow(receptionID))
-
-				dbMock.ExpectQuery("DELETE FROM products.*RETURNING id").
-					WithArgs(receptionID).
+				dbMock.ExpectQuery(query).
 					WillReturnError(ErrRandomError)
-
-				dbMock.ExpectRollback()
 			},
 			expectedError: ErrRandomError,
 		},
@@ -552,90 +530,6156 @@ func TestPVZRepo_DeleteLastProduct(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			tt.mockSetup()
 
-			err := repo.DeleteLastProduct(context.Background(), pvzID)
+			result, err := repo.GetDeactivatedUsers(context.Background())
 
 			if tt.expectedError != nil {
 				assert.ErrorIs(t, err, tt.expectedError)
 			} else {
 				assert.NoError(t, err)
+				assert.Equal(t, tt.expected, result)
 			}
+			assert.NoError(t, dbMock.ExpectationsWereMet())
 		})
 	}
 }
 
-func TestPVZRepo_CloseLastReception(t *testing.T) {
+func TestPVZRepo_CreateRefreshToken(t *testing.T) {
 	dbMock, err := pgxmock.NewPool()
 	require.NoError(t, err)
 	defer dbMock.Close()
 
 	repo := NewPVZRepo(dbMock)
 
-	pvzID := uuid.New()
-	receptionID := uuid.New()
+	token := models.RefreshToken{
+		ID:        uuid.New(),
+		UserID:    uuid.New(),
+		TokenHash: "token_hash",
+		ExpiresAt: time.Now().Add(time.Hour),
+	}
 
-	now := time.Now()
+	query := "INSERT INTO refresh_tokens \\(id, user_id, token_hash, expires_at\\) VALUES \\(\\$1, \\$2, \\$3, \\$4\\) RETURNING id"
 
 	tests := []struct {
 		name          string
 		mockSetup     func()
 		expectedError error
-		expected      *models.Reception
 	}{
 		{
-			name: "success",
+			name: "token stored",
 			mockSetup: func() {
-				dbMock.ExpectBegin()
-
-				dbMock.ExpectQuery("SELECT id, date_time, pvz_id FROM receptions.*FOR UPDATE").
-					WithArgs(pvzID, string(pvzapi.InProgress)).
-					WillReturnRows(pgxmock.NewRows([]string{"id", "date_time", "pvz_id"}).
-						AddRow(receptionID, now, pvzID))
-
-				dbMock.ExpectExec("UPDATE receptions SET status =.*").
-					WithArgs(string(pvzapi.Close), receptionID).
-					WillReturnResult(pgxmock.NewResult("UPDATE", 1))
-
-				dbMock.ExpectCommit()
-			},
-			expected: &models.Reception{
-				ID:       receptionID,
-				DateTime: now,
-				PvzID:    pvzID,
-				Status:   string(pvzapi.Close),
+				dbMock.ExpectQuery(query).
+					WithArgs(token.ID, token.UserID, token.TokenHash, token.ExpiresAt).
+					WillReturnRows(pgxmock.NewRows([]string{"id"}).AddRow(token.ID))
 			},
 			expectedError: nil,
 		},
 		{
-			name: "no open reception",
+			name: "query error",
 			mockSetup: func() {
-				dbMock.ExpectBegin()
-				dbMock.ExpectQuery("SELECT id, date_time, pvz_id FROM receptions.*FOR UPDATE").
+				dbMock.ExpectQuery(query).
+					WithArgs(token.ID, token.UserID, token.TokenHash, token.ExpiresAt).
+					WillReturnError(ErrRandomError)
+			},
+			expectedError: ErrRandomError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockSetup()
+
+			err := repo.CreateRefreshToken(context.Background(), token)
+
+			if tt.expectedError != nil {
+				assert.ErrorIs(t, err, tt.expectedError)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.NoError(t, dbMock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestPVZRepo_GetRefreshToken(t *testing.T) {
+	dbMock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer dbMock.Close()
+
+	repo := NewPVZRepo(dbMock)
+
+	revokedAt := time.Now()
+	testToken := &models.RefreshToken{
+		ID:        uuid.New(),
+		UserID:    uuid.New(),
+		UserRole:  "employee",
+		TokenHash: "token_hash",
+		ExpiresAt: time.Now().Add(time.Hour),
+		RevokedAt: &revokedAt,
+	}
+
+	query := "SELECT t.id, t.user_id, u.role, t.token_hash, t.expires_at, t.revoked_at FROM refresh_tokens t JOIN users u ON u.id = t.user_id WHERE t.token_hash = \\$1"
+
+	tests := []struct {
+		name          string
+		mockSetup     func()
+		expected      *models.RefreshToken
+		expectedError error
+	}{
+		{
+			name: "token found",
+			mockSetup: func() {
+				rows := pgxmock.NewRows([]string{"id", "user_id", "role", "token_hash", "expires_at", "revoked_at"}).
+					AddRow(testToken.ID, testToken.UserID, testToken.UserRole, testToken.TokenHash, testToken.ExpiresAt, testToken.RevokedAt)
+
+				dbMock.ExpectQuery(query).
+					WithArgs(testToken.TokenHash).
+					WillReturnRows(rows)
+			},
+			expected:      testToken,
+			expectedError: nil,
+		},
+		{
+			name: "token not found",
+			mockSetup: func() {
+				dbMock.ExpectQuery(query).
+					WithArgs(testToken.TokenHash).
+					WillReturnError(pgx.ErrNoRows)
+			},
+			expected:      nil,
+			expectedError: db.ErrRefreshTokenNotFound,
+		},
+		{
+			name: "query error",
+			mockSetup: func() {
+				dbMock.ExpectQuery(query).
+					WithArgs(testToken.TokenHash).
+					WillReturnError(ErrRandomError)
+			},
+			expected:      nil,
+			expectedError: ErrRandomError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockSetup()
+
+			result, err := repo.GetRefreshToken(context.Background(), testToken.TokenHash)
+
+			if tt.expectedError != nil {
+				assert.ErrorIs(t, err, tt.expectedError)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.expected, result)
+			}
+			assert.NoError(t, dbMock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestPVZRepo_RotateRefreshToken(t *testing.T) {
+	dbMock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer dbMock.Close()
+
+	repo := NewPVZRepo(dbMock)
+
+	oldID := uuid.New()
+	newToken := models.RefreshToken{
+		ID:        uuid.New(),
+		UserID:    uuid.New(),
+		TokenHash: "new_hash",
+		ExpiresAt: time.Now().Add(time.Hour),
+	}
+
+	tests := []struct {
+		name          string
+		mockSetup     func()
+		expectedError error
+	}{
+		{
+			name: "token rotated",
+			mockSetup: func() {
+				dbMock.ExpectBegin()
+				dbMock.ExpectExec("INSERT INTO refresh_tokens").
+					WithArgs(newToken.ID, newToken.UserID, newToken.TokenHash, newToken.ExpiresAt).
+					WillReturnResult(pgxmock.NewResult("INSERT", 1))
+				dbMock.ExpectExec("UPDATE refresh_tokens SET revoked_at = CURRENT_TIMESTAMP, replaced_by = \\$2 WHERE id = \\$1 AND revoked_at IS NULL").
+					WithArgs(oldID, newToken.ID).
+					WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+				dbMock.ExpectCommit()
+			},
+			expectedError: nil,
+		},
+		{
+			name: "token already used",
+			mockSetup: func() {
+				dbMock.ExpectBegin()
+				dbMock.ExpectExec("INSERT INTO refresh_tokens").
+					WithArgs(newToken.ID, newToken.UserID, newToken.TokenHash, newToken.ExpiresAt).
+					WillReturnResult(pgxmock.NewResult("INSERT", 1))
+				dbMock.ExpectExec("UPDATE refresh_tokens").
+					WithArgs(oldID, newToken.ID).
+					WillReturnResult(pgxmock.NewResult("UPDATE", 0))
+				dbMock.ExpectRollback()
+			},
+			expectedError: db.ErrRefreshTokenRevoked,
+		},
+		{
+			name: "insert error",
+			mockSetup: func() {
+				dbMock.ExpectBegin()
+				dbMock.ExpectExec("INSERT INTO refresh_tokens").
+					WithArgs(newToken.ID, newToken.UserID, newToken.TokenHash, newToken.ExpiresAt).
+					WillReturnError(ErrRandomError)
+				dbMock.ExpectRollback()
+			},
+			expectedError: ErrRandomError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockSetup()
+
+			err := repo.RotateRefreshToken(context.Background(), oldID, newToken)
+
+			if tt.expectedError != nil {
+				assert.ErrorIs(t, err, tt.expectedError)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.NoError(t, dbMock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestPVZRepo_RevokeRefreshToken(t *testing.T) {
+	dbMock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer dbMock.Close()
+
+	repo := NewPVZRepo(dbMock)
+
+	userID := uuid.New()
+	tokenHash := "token_hash"
+	query := "UPDATE refresh_tokens SET revoked_at = CURRENT_TIMESTAMP WHERE user_id = \\$1 AND token_hash = \\$2 AND revoked_at IS NULL"
+
+	tests := []struct {
+		name          string
+		mockSetup     func()
+		expectedError error
+	}{
+		{
+			name: "token revoked",
+			mockSetup: func() {
+				dbMock.ExpectQuery(query).
+					WithArgs(userID, tokenHash).
+					WillReturnRows(pgxmock.NewRows([]string{"count"}).AddRow(1))
+			},
+			expectedError: nil,
+		},
+		{
+			name: "unknown or already revoked token",
+			mockSetup: func() {
+				dbMock.ExpectQuery(query).
+					WithArgs(userID, tokenHash).
+					WillReturnRows(pgxmock.NewRows([]string{"count"}).AddRow(0))
+			},
+			expectedError: nil,
+		},
+		{
+			name: "query error",
+			mockSetup: func() {
+				dbMock.ExpectQuery(query).
+					WithArgs(userID, tokenHash).
+					WillReturnError(ErrRandomError)
+			},
+			expectedError: ErrRandomError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockSetup()
+
+			err := repo.RevokeRefreshToken(context.Background(), userID, tokenHash)
+
+			if tt.expectedError != nil {
+				assert.ErrorIs(t, err, tt.expectedError)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.NoError(t, dbMock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestPVZRepo_RevokeUserRefreshTokens(t *testing.T) {
+	dbMock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer dbMock.Close()
+
+	repo := NewPVZRepo(dbMock)
+
+	userID := uuid.New()
+	query := "UPDATE refresh_tokens SET revoked_at = CURRENT_TIMESTAMP WHERE user_id = \\$1 AND revoked_at IS NULL"
+
+	tests := []struct {
+		name          string
+		mockSetup     func()
+		expectedError error
+	}{
+		{
+			name: "tokens revoked",
+			mockSetup: func() {
+				dbMock.ExpectQuery(query).
+					WithArgs(userID).
+					WillReturnRows(pgxmock.NewRows([]string{"count"}).AddRow(2))
+			},
+			expectedError: nil,
+		},
+		{
+			name: "query error",
+			mockSetup: func() {
+				dbMock.ExpectQuery(query).
+					WithArgs(userID).
+					WillReturnError(ErrRandomError)
+			},
+			expectedError: ErrRandomError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockSetup()
+
+			err := repo.RevokeUserRefreshTokens(context.Background(), userID)
+
+			if tt.expectedError != nil {
+				assert.ErrorIs(t, err, tt.expectedError)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.NoError(t, dbMock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestPVZRepo_RevokeAccessToken(t *testing.T) {
+	dbMock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer dbMock.Close()
+
+	repo := NewPVZRepo(dbMock)
+
+	tokenID := uuid.New()
+	expiresAt := time.Now().Add(time.Minute)
+
+	tests := []struct {
+		name          string
+		mockSetup     func()
+		expectedError error
+	}{
+		{
+			name: "token revoked",
+			mockSetup: func() {
+				dbMock.ExpectQuery("INSERT INTO revoked_tokens.*ON CONFLICT \\(jti\\) DO NOTHING RETURNING jti").
+					WithArgs(tokenID, expiresAt).
+					WillReturnRows(pgxmock.NewRows([]string{"jti"}).AddRow(tokenID))
+			},
+			expectedError: nil,
+		},
+		{
+			name: "token already revoked",
+			mockSetup: func() {
+				dbMock.ExpectQuery("INSERT INTO revoked_tokens").
+					WithArgs(tokenID, expiresAt).
+					WillReturnError(pgx.ErrNoRows)
+			},
+			expectedError: nil,
+		},
+		{
+			name: "query error",
+			mockSetup: func() {
+				dbMock.ExpectQuery("INSERT INTO revoked_tokens").
+					WithArgs(tokenID, expiresAt).
+					WillReturnError(ErrRandomError)
+			},
+			expectedError: ErrRandomError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockSetup()
+
+			err := repo.RevokeAccessToken(context.Background(), tokenID, expiresAt)
+
+			if tt.expectedError != nil {
+				assert.ErrorIs(t, err, tt.expectedError)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.NoError(t, dbMock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestPVZRepo_GetRevokedAccessTokens(t *testing.T) {
+	dbMock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer dbMock.Close()
+
+	repo := NewPVZRepo(dbMock)
+
+	tokenIDs := []uuid.UUID{uuid.New(), uuid.New()}
+	query := "SELECT jti FROM revoked_tokens WHERE expires_at > CURRENT_TIMESTAMP"
+
+	tests := []struct {
+		name          string
+		mockSetup     func()
+		expected      []uuid.UUID
+		expectedError error
+	}{
+		{
+			name: "tokens found",
+			mockSetup: func() {
+				dbMock.ExpectQuery(query).
+					WillReturnRows(pgxmock.NewRows([]string{"jti"}).AddRow(tokenIDs[0]).AddRow(tokenIDs[1]))
+			},
+			expected:      tokenIDs,
+			expectedError: nil,
+		},
+		{
+			name: "no tokens",
+			mockSetup: func() {
+				dbMock.ExpectQuery(query).
+					WillReturnRows(pgxmock.NewRows([]string{"jti"}))
+			},
+			expected:      []uuid.UUID{},
+			expectedError: nil,
+		},
+		{
+			name: "query error",
+			mockSetup: func() {
+				dbMock.ExpectQuery(query).
+					WillReturnError(ErrRandomError)
+			},
+			expectedError: ErrRandomError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockSetup()
+
+			result, err := repo.GetRevokedAccessTokens(context.Background())
+
+			if tt.expectedError != nil {
+				assert.ErrorIs(t, err, tt.expectedError)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.expected, result)
+			}
+			assert.NoError(t, dbMock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestPVZRepo_DeleteExpiredTokens(t *testing.T) {
+	dbMock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer dbMock.Close()
+
+	repo := NewPVZRepo(dbMock)
+
+	query := "DELETE FROM refresh_tokens WHERE expires_at <= CURRENT_TIMESTAMP.*DELETE FROM revoked_tokens WHERE expires_at <= CURRENT_TIMESTAMP"
+
+	tests := []struct {
+		name          string
+		mockSetup     func()
+		expected      int64
+		expectedError error
+	}{
+		{
+			name: "tokens deleted",
+			mockSetup: func() {
+				dbMock.ExpectQuery(query).
+					WillReturnRows(pgxmock.NewRows([]string{"deleted"}).AddRow(int64(3)))
+			},
+			expected:      3,
+			expectedError: nil,
+		},
+		{
+			name: "query error",
+			mockSetup: func() {
+				dbMock.ExpectQuery(query).
+					WillReturnError(ErrRandomError)
+			},
+			expectedError: ErrRandomError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockSetup()
+
+			deleted, err := repo.DeleteExpiredTokens(context.Background())
+
+			if tt.expectedError != nil {
+				assert.ErrorIs(t, err, tt.expectedError)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.expected, deleted)
+			}
+			assert.NoError(t, dbMock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestPVZRepo_GetUserRoles(t *testing.T) {
+	dbMock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer dbMock.Close()
+
+	repo := NewPVZRepo(dbMock)
+
+	employeeID, moderatorID := uuid.New(), uuid.New()
+	query := "SELECT id, role FROM users"
+
+	tests := []struct {
+		name          string
+		mockSetup     func()
+		expected      []models.User
+		expectedError error
+	}{
+		{
+			name: "users found",
+			mockSetup: func() {
+				dbMock.ExpectQuery(query).
+					WillReturnRows(pgxmock.NewRows([]string{"id", "role"}).
+						AddRow(employeeID, "employee").
+						AddRow(moderatorID, "moderator"))
+			},
+			expected: []models.User{
+				{ID: employeeID, Role: "employee"},
+				{ID: moderatorID, Role: "moderator"},
+			},
+			expectedError: nil,
+		},
+		{
+			name: "query error",
+			mockSetup: func() {
+				dbMock.ExpectQuery(query).
+					WillReturnError(ErrRandomError)
+			},
+			expectedError: ErrRandomError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockSetup()
+
+			result, err := repo.GetUserRoles(context.Background())
+
+			if tt.expectedError != nil {
+				assert.ErrorIs(t, err, tt.expectedError)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.expected, result)
+			}
+			assert.NoError(t, dbMock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestPVZRepo_ClaimLoginAttempt(t *testing.T) {
+	dbMock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer dbMock.Close()
+
+	repo := NewPVZRepo(dbMock)
+
+	key := "account:test@example.com"
+	at := time.Now()
+	resetBefore := at.Add(-time.Hour)
+
+	tests := []struct {
+		name          string
+		mockSetup     func()
+		expected      int
+		expectedError error
+	}{
+		{
+			name: "attempt claimed",
+			mockSetup: func() {
+				dbMock.ExpectQuery("INSERT INTO login_attempts.*ON CONFLICT \\(key\\) DO UPDATE.*WHERE.*RETURNING failures").
+					WithArgs(key, at, resetBefore, 5).
+					WillReturnRows(pgxmock.NewRows([]string{"failures"}).AddRow(4))
+			},
+			expected:      4,
+			expectedError: nil,
+		},
+		{
+			name: "no attempts left",
+			mockSetup: func() {
+				dbMock.ExpectQuery("INSERT INTO login_attempts").
+					WithArgs(key, at, resetBefore, 5).
+					WillReturnError(pgx.ErrNoRows)
+			},
+			expectedError: db.ErrNoLoginAttemptsLeft,
+		},
+		{
+			name: "query error",
+			mockSetup: func() {
+				dbMock.ExpectQuery("INSERT INTO login_attempts").
+					WithArgs(key, at, resetBefore, 5).
+					WillReturnError(ErrRandomError)
+			},
+			expectedError: ErrRandomError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockSetup()
+
+			failures, err := repo.ClaimLoginAttempt(context.Background(), key, 5, at, resetBefore)
+
+			if tt.expectedError != nil {
+				assert.ErrorIs(t, err, tt.expectedError)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.expected, failures)
+			}
+			assert.NoError(t, dbMock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestPVZRepo_LockLoginAttempts(t *testing.T) {
+	dbMock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer dbMock.Close()
+
+	repo := NewPVZRepo(dbMock)
+
+	key := "account:test@example.com"
+	lockedUntil := time.Now().Add(time.Minute)
+	query := "UPDATE login_attempts SET locked_until = GREATEST\\(COALESCE\\(locked_until, \\$2\\), \\$2\\) WHERE key = \\$1 RETURNING key"
+
+	tests := []struct {
+		name          string
+		mockSetup     func()
+		expectedError error
+	}{
+		{
+			name: "attempts locked",
+			mockSetup: func() {
+				dbMock.ExpectQuery(query).
+					WithArgs(key, lockedUntil).
+					WillReturnRows(pgxmock.NewRows([]string{"key"}).AddRow(key))
+			},
+			expectedError: nil,
+		},
+		{
+			name: "attempts already reset",
+			mockSetup: func() {
+				dbMock.ExpectQuery(query).
+					WithArgs(key, lockedUntil).
+					WillReturnError(pgx.ErrNoRows)
+			},
+			expectedError: nil,
+		},
+		{
+			name: "query error",
+			mockSetup: func() {
+				dbMock.ExpectQuery(query).
+					WithArgs(key, lockedUntil).
+					WillReturnError(ErrRandomError)
+			},
+			expectedError: ErrRandomError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockSetup()
+
+			err := repo.LockLoginAttempts(context.Background(), key, lockedUntil)
+
+			if tt.expectedError != nil {
+				assert.ErrorIs(t, err, tt.expectedError)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.NoError(t, dbMock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestPVZRepo_ReleaseLoginAttempt(t *testing.T) {
+	dbMock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer dbMock.Close()
+
+	repo := NewPVZRepo(dbMock)
+
+	key := "ip:10.0.0.1"
+	query := "UPDATE login_attempts SET failures = failures - 1 WHERE key = \\$1 AND failures > 0 RETURNING key"
+
+	tests := []struct {
+		name          string
+		mockSetup     func()
+		expectedError error
+	}{
+		{
+			name: "attempt released",
+			mockSetup: func() {
+				dbMock.ExpectQuery(query).
+					WithArgs(key).
+					WillReturnRows(pgxmock.NewRows([]string{"key"}).AddRow(key))
+			},
+			expectedError: nil,
+		},
+		{
+			name: "attempts already reset",
+			mockSetup: func() {
+				dbMock.ExpectQuery(query).
+					WithArgs(key).
+					WillReturnError(pgx.ErrNoRows)
+			},
+			expectedError: nil,
+		},
+		{
+			name: "query error",
+			mockSetup: func() {
+				dbMock.ExpectQuery(query).
+					WithArgs(key).
+					WillReturnError(ErrRandomError)
+			},
+			expectedError: ErrRandomError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockSetup()
+
+			err := repo.ReleaseLoginAttempt(context.Background(), key)
+
+			if tt.expectedError != nil {
+				assert.ErrorIs(t, err, tt.expectedError)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.NoError(t, dbMock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestPVZRepo_ResetLoginFailures(t *testing.T) {
+	dbMock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer dbMock.Close()
+
+	repo := NewPVZRepo(dbMock)
+
+	key := "account:test@example.com"
+
+	tests := []struct {
+		name          string
+		mockSetup     func()
+		expectedError error
+	}{
+		{
+			name: "failures reset",
+			mockSetup: func() {
+				dbMock.ExpectQuery("DELETE FROM login_attempts WHERE key = \\$1 RETURNING key").
+					WithArgs(key).
+					WillReturnRows(pgxmock.NewRows([]string{"key"}).AddRow(key))
+			},
+			expectedError: nil,
+		},
+		{
+			name: "no failures",
+			mockSetup: func() {
+				dbMock.ExpectQuery("DELETE FROM login_attempts").
+					WithArgs(key).
+					WillReturnError(pgx.ErrNoRows)
+			},
+			expectedError: nil,
+		},
+		{
+			name: "query error",
+			mockSetup: func() {
+				dbMock.ExpectQuery("DELETE FROM login_attempts").
+					WithArgs(key).
+					WillReturnError(ErrRandomError)
+			},
+			expectedError: ErrRandomError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockSetup()
+
+			err := repo.ResetLoginFailures(context.Background(), key)
+
+			if tt.expectedError != nil {
+				assert.ErrorIs(t, err, tt.expectedError)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.NoError(t, dbMock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestPVZRepo_DeleteStaleLoginAttempts(t *testing.T) {
+	dbMock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer dbMock.Close()
+
+	repo := NewPVZRepo(dbMock)
+
+	before := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	query := "DELETE FROM login_attempts WHERE last_attempt_at < \\$1 AND \\(locked_until IS NULL OR locked_until < \\$1\\)"
+
+	tests := []struct {
+		name          string
+		mockSetup     func()
+		expected      int64
+		expectedError error
+	}{
+		{
+			name: "attempts deleted",
+			mockSetup: func() {
+				dbMock.ExpectQuery(query).
+					WithArgs(before).
+					WillReturnRows(pgxmock.NewRows([]string{"count"}).AddRow(int64(2)))
+			},
+			expected:      2,
+			expectedError: nil,
+		},
+		{
+			name: "query error",
+			mockSetup: func() {
+				dbMock.ExpectQuery(query).
+					WithArgs(before).
+					WillReturnError(ErrRandomError)
+			},
+			expectedError: ErrRandomError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockSetup()
+
+			deleted, err := repo.DeleteStaleLoginAttempts(context.Background(), before)
+
+			if tt.expectedError != nil {
+				assert.ErrorIs(t, err, tt.expectedError)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.expected, deleted)
+			}
+			assert.NoError(t, dbMock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestPVZRepo_GrantPermission(t *testing.T) {
+	dbMock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer dbMock.Close()
+
+	repo := NewPVZRepo(dbMock)
+
+	userID := uuid.New()
+	adminID := uuid.New()
+	grantedAt := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	columns := []string{"user_id", "permission", "granted_by", "granted_at"}
+
+	tests := []struct {
+		name          string
+		grantedBy     uuid.UUID
+		mockSetup     func()
+		expected      *models.PermissionGrant
+		expectedError error
+	}{
+		{
+			name:      "permission granted",
+			grantedBy: adminID,
+			mockSetup: func() {
+				dbMock.ExpectQuery("INSERT INTO user_permissions.*ON CONFLICT \\(user_id, permission\\) DO UPDATE").
+					WithArgs(userID, "products:handle", &adminID).
+					WillReturnRows(pgxmock.NewRows(columns).AddRow(userID, models.PermProductsHandle, &adminID, grantedAt))
+			},
+			expected: &models.PermissionGrant{
+				UserID:     userID,
+				Permission: models.PermProductsHandle,
+				GrantedBy:  &adminID,
+				GrantedAt:  grantedAt,
+			},
+			expectedError: nil,
+		},
+		{
+			name:      "granted by dummy token",
+			grantedBy: uuid.Nil,
+			mockSetup: func() {
+				dbMock.ExpectQuery("INSERT INTO user_permissions").
+					WithArgs(userID, "products:handle", (*uuid.UUID)(nil)).
+					WillReturnRows(pgxmock.NewRows(columns).AddRow(userID, models.PermProductsHandle, nil, grantedAt))
+			},
+			expected: &models.PermissionGrant{
+				UserID:     userID,
+				Permission: models.PermProductsHandle,
+				GrantedAt:  grantedAt,
+			},
+			expectedError: nil,
+		},
+		{
+			name:      "user not found",
+			grantedBy: adminID,
+			mockSetup: func() {
+				dbMock.ExpectQuery("INSERT INTO user_permissions").
+					WithArgs(userID, "products:handle", &adminID).
+					WillReturnError(&pgconn.PgError{Code: "23503"})
+			},
+			expectedError: db.ErrUserNotFound,
+		},
+		{
+			name:      "query error",
+			grantedBy: adminID,
+			mockSetup: func() {
+				dbMock.ExpectQuery("INSERT INTO user_permissions").
+					WithArgs(userID, "products:handle", &adminID).
+					WillReturnError(ErrRandomError)
+			},
+			expectedError: ErrRandomError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockSetup()
+
+			result, err := repo.GrantPermission(context.Background(), userID, models.PermProductsHandle, tt.grantedBy)
+
+			if tt.expectedError != nil {
+				assert.ErrorIs(t, err, tt.expectedError)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.expected, result)
+			}
+			assert.NoError(t, dbMock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestPVZRepo_RevokePermission(t *testing.T) {
+	dbMock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer dbMock.Close()
+
+	repo := NewPVZRepo(dbMock)
+
+	userID := uuid.New()
+
+	tests := []struct {
+		name          string
+		mockSetup     func()
+		expectedError error
+	}{
+		{
+			name: "permission revoked",
+			mockSetup: func() {
+				dbMock.ExpectQuery("DELETE FROM user_permissions").
+					WithArgs(userID, "products:handle").
+					WillReturnRows(pgxmock.NewRows([]string{"user_id"}).AddRow(userID))
+			},
+			expectedError: nil,
+		},
+		{
+			name: "permission not granted",
+			mockSetup: func() {
+				dbMock.ExpectQuery("DELETE FROM user_permissions").
+					WithArgs(userID, "products:handle").
+					WillReturnError(pgx.ErrNoRows)
+			},
+			expectedError: db.ErrPermissionNotGranted,
+		},
+		{
+			name: "query error",
+			mockSetup: func() {
+				dbMock.ExpectQuery("DELETE FROM user_permissions").
+					WithArgs(userID, "products:handle").
+					WillReturnError(ErrRandomError)
+			},
+			expectedError: ErrRandomError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockSetup()
+
+			err := repo.RevokePermission(context.Background(), userID, models.PermProductsHandle)
+
+			if tt.expectedError != nil {
+				assert.ErrorIs(t, err, tt.expectedError)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.NoError(t, dbMock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestPVZRepo_GetUserPermissions(t *testing.T) {
+	dbMock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer dbMock.Close()
+
+	repo := NewPVZRepo(dbMock)
+
+	userID := uuid.New()
+	grantedAt := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	existsQuery := "SELECT EXISTS \\(SELECT 1 FROM users WHERE id = \\$1\\)"
+	grantsQuery := "SELECT user_id, permission, granted_by, granted_at.*FROM user_permissions.*WHERE user_id = \\$1.*ORDER BY permission"
+	columns := []string{"user_id", "permission", "granted_by", "granted_at"}
+
+	tests := []struct {
+		name          string
+		mockSetup     func()
+		expected      []models.PermissionGrant
+		expectedError error
+	}{
+		{
+			name: "permissions found",
+			mockSetup: func() {
+				dbMock.ExpectQuery(existsQuery).
+					WithArgs(userID).
+					WillReturnRows(pgxmock.NewRows([]string{"exists"}).AddRow(true))
+				dbMock.ExpectQuery(grantsQuery).
+					WithArgs(userID).
+					WillReturnRows(pgxmock.NewRows(columns).
+						AddRow(userID, models.PermProductsHandle, nil, grantedAt).
+						AddRow(userID, models.PermReceptionsWrite, nil, grantedAt))
+			},
+			expected: []models.PermissionGrant{
+				{UserID: userID, Permission: models.PermProductsHandle, GrantedAt: grantedAt},
+				{UserID: userID, Permission: models.PermReceptionsWrite, GrantedAt: grantedAt},
+			},
+			expectedError: nil,
+		},
+		{
+			name: "user not found",
+			mockSetup: func() {
+				dbMock.ExpectQuery(existsQuery).
+					WithArgs(userID).
+					WillReturnRows(pgxmock.NewRows([]string{"exists"}).AddRow(false))
+			},
+			expectedError: db.ErrUserNotFound,
+		},
+		{
+			name: "query error",
+			mockSetup: func() {
+				dbMock.ExpectQuery(existsQuery).
+					WithArgs(userID).
+					WillReturnRows(pgxmock.NewRows([]string{"exists"}).AddRow(true))
+				dbMock.ExpectQuery(grantsQuery).
+					WithArgs(userID).
+					WillReturnError(ErrRandomError)
+			},
+			expectedError: ErrRandomError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockSetup()
+
+			result, err := repo.GetUserPermissions(context.Background(), userID)
+
+			if tt.expectedError != nil {
+				assert.ErrorIs(t, err, tt.expectedError)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.expected, result)
+			}
+			assert.NoError(t, dbMock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestPVZRepo_GetPermissionGrants(t *testing.T) {
+	dbMock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer dbMock.Close()
+
+	repo := NewPVZRepo(dbMock)
+
+	userID, adminID := uuid.New(), uuid.New()
+	grantedAt := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	query := "SELECT user_id, permission, granted_by, granted_at FROM user_permissions"
+	columns := []string{"user_id", "permission", "granted_by", "granted_at"}
+
+	tests := []struct {
+		name          string
+		mockSetup     func()
+		expected      []models.PermissionGrant
+		expectedError error
+	}{
+		{
+			name: "grants found",
+			mockSetup: func() {
+				dbMock.ExpectQuery(query).
+					WillReturnRows(pgxmock.NewRows(columns).
+						AddRow(userID, models.PermProductsHandle, &adminID, grantedAt).
+						AddRow(userID, models.PermReceptionsWrite, nil, grantedAt))
+			},
+			expected: []models.PermissionGrant{
+				{UserID: userID, Permission: models.PermProductsHandle, GrantedBy: &adminID, GrantedAt: grantedAt},
+				{UserID: userID, Permission: models.PermReceptionsWrite, GrantedAt: grantedAt},
+			},
+			expectedError: nil,
+		},
+		{
+			name: "no grants",
+			mockSetup: func() {
+				dbMock.ExpectQuery(query).
+					WillReturnRows(pgxmock.NewRows(columns))
+			},
+			expected:      []models.PermissionGrant{},
+			expectedError: nil,
+		},
+		{
+			name: "rows error",
+			mockSetup: func() {
+				dbMock.ExpectQuery(query).
+					WillReturnRows(pgxmock.NewRows(columns).
+						AddRow(userID, models.PermProductsHandle, nil, grantedAt).
+						RowError(0, ErrRandomError))
+			},
+			expectedError: ErrRandomError,
+		},
+		{
+			name: "query error",
+			mockSetup: func() {
+				dbMock.ExpectQuery(query).
+					WillReturnError(ErrRandomError)
+			},
+			expectedError: ErrRandomError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockSetup()
+
+			result, err := repo.GetPermissionGrants(context.Background())
+
+			if tt.expectedError != nil {
+				assert.ErrorIs(t, err, tt.expectedError)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.expected, result)
+			}
+			assert.NoError(t, dbMock.ExpectationsWereMet())
+		})
+	}
+
+	t.Run("scan error", func(t *testing.T) {
+		dbMock.ExpectQuery(query).
+			WillReturnRows(pgxmock.NewRows(columns).
+				AddRow("not-a-uuid", models.PermProductsHandle, nil, grantedAt))
+
+		result, err := repo.GetPermissionGrants(context.Background())
+
+		assert.ErrorContains(t, err, "repository.GetPermissionGrants")
+		assert.Nil(t, result)
+		assert.NoError(t, dbMock.ExpectationsWereMet())
+	})
+}
+
+func TestPVZRepo_CreatePVZ(t *testing.T) {
+	dbMock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer dbMock.Close()
+
+	repo := NewPVZRepo(dbMock)
+
+	testPVZ := models.PVZ{
+		ID:               uuid.New(),
+		City:             "Москва",
+		RegistrationDate: time.Now(),
+		Status:           string(pvzapi.Active),
+	}
+
+	tests := []struct {
+		name          string
+		pvz           models.PVZ
+		mockSetup     func()
+		expectedError error
+	}{
+		{
+			name: "successful creation",
+			pvz:  testPVZ,
+			mockSetup: func() {
+				rows := pgxmock.NewRows([]string{"id"}).
+					AddRow(testPVZ.ID.String())
+				dbMock.ExpectQuery("INSERT INTO pvzs.*RETURNING id").
+					WithArgs(testPVZ.ID, testPVZ.City, testPVZ.RegistrationDate, testPVZ.Status).
+					WillReturnRows(rows)
+			},
+			expectedError: nil,
+		},
+		{
+			name: "duplicate PVZ",
+			pvz:  testPVZ,
+			mockSetup: func() {
+				dbMock.ExpectQuery("INSERT INTO pvzs.*RETURNING id").
+					WithArgs(testPVZ.ID, testPVZ.City, testPVZ.RegistrationDate, testPVZ.Status).
+					WillReturnError(pgx.ErrNoRows)
+			},
+			expectedError: db.ErrDuplicatePVZ,
+		},
+		{
+			name: "query error",
+			pvz:  testPVZ,
+			mockSetup: func() {
+				dbMock.ExpectQuery("INSERT INTO pvzs.*RETURNING id").
+					WithArgs(testPVZ.ID, testPVZ.City, testPVZ.RegistrationDate, testPVZ.Status).
+					WillReturnError(ErrRandomError)
+			},
+			expectedError: ErrRandomError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockSetup()
+
+			err := repo.CreatePVZ(context.Background(), tt.pvz)
+
+			if tt.expectedError != nil {
+				assert.ErrorIs(t, err, tt.expectedError)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestPVZRepo_UpdatePVZ(t *testing.T) {
+	dbMock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer dbMock.Close()
+
+	repo := NewPVZRepo(dbMock)
+
+	pvzID := uuid.New()
+	regDate := time.Now()
+	newStatus := string(pvzapi.Suspended)
+
+	tests := []struct {
+		name          string
+		mockSetup     func()
+		expected      *models.PVZ
+		expectedError error
+	}{
+		{
+			name: "successful update",
+			mockSetup: func() {
+				rows := pgxmock.NewRows([]string{"id", "city", "registration_date", "status"}).
+					AddRow(pvzID, "Москва", regDate, newStatus)
+				dbMock.ExpectQuery("UPDATE pvzs.*RETURNING id, city, registration_date, status").
+					WithArgs(pvzID, (*string)(nil), &newStatus).
+					WillReturnRows(rows)
+			},
+			expected: &models.PVZ{
+				ID:               pvzID,
+				City:             "Москва",
+				RegistrationDate: regDate,
+				Status:           newStatus,
+			},
+			expectedError: nil,
+		},
+		{
+			name: "pvz not found",
+			mockSetup: func() {
+				dbMock.ExpectQuery("UPDATE pvzs.*RETURNING id, city, registration_date, status").
+					WithArgs(pvzID, (*string)(nil), &newStatus).
+					WillReturnError(pgx.ErrNoRows)
+			},
+			expected:      nil,
+			expectedError: db.ErrPVZNotFound,
+		},
+		{
+			name: "query error",
+			mockSetup: func() {
+				dbMock.ExpectQuery("UPDATE pvzs.*RETURNING id, city, registration_date, status").
+					WithArgs(pvzID, (*string)(nil), &newStatus).
+					WillReturnError(ErrRandomError)
+			},
+			expected:      nil,
+			expectedError: ErrRandomError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockSetup()
+
+			result, err := repo.UpdatePVZ(context.Background(), pvzID, nil, &newStatus)
+
+			if tt.expectedError != nil {
+				assert.ErrorIs(t, err, tt.expectedError)
+				assert.Nil(t, result)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.expected, result)
+			}
+		})
+	}
+}
+
+func TestPVZRepo_CreateReception(t *testing.T) {
+	dbMock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer dbMock.Close()
+
+	repo := NewPVZRepo(dbMock)
+
+	receptionID := uuid.New()
+	pvzID := uuid.New()
+	userID := uuid.New()
+	defaultStatus := string(pvzapi.InProgress)
+
+	expectedReception := &models.Reception{
+		ID:        receptionID,
+		PvzID:     pvzID,
+		Status:    defaultStatus,
+		DateTime:  time.Now(),
+		CreatedBy: &userID,
+	}
+
+	manifest := []models.ManifestItem{
+		{Type: "обувь", Count: 3},
+		{Type: "одежда", Count: 1},
+	}
+	receptionWithManifest := *expectedReception
+	receptionWithManifest.Manifest = manifest
+
+	barcodes := []models.ManifestBarcode{
+		{Barcode: "4600000000017", Type: "обувь"},
+		{Barcode: "4600000000024", Type: "одежда"},
+	}
+	receptionWithBarcodes := *expectedReception
+	receptionWithBarcodes.ExpectedBarcodes = barcodes
+
+	tests := []struct {
+		name          string
+		receptionID   uuid.UUID
+		pvzID         uuid.UUID
+		manifest      []models.ManifestItem
+		barcodes      []models.ManifestBarcode
+		mockSetup     func()
+		expected      *models.Reception
+		expectedError error
+	}{
+		{
+			name:        "successful creation",
+			receptionID: receptionID,
+			pvzID:       pvzID,
+			mockSetup: func() {
+				dbMock.ExpectBegin()
+
+				dbMock.ExpectQuery("SELECT status FROM pvzs.*FOR SHARE").
+					WithArgs(pvzID).
+					WillReturnRows(pgxmock.NewRows([]string{"status"}).AddRow(string(pvzapi.Active)))
+
+				rows := pgxmock.NewRows([]string{"id", "date_time", "pvz_id", "status", "created_by"}).
+					AddRow(expectedReception.ID, expectedReception.DateTime, expectedReception.PvzID, expectedReception.Status, &userID)
+				dbMock.ExpectQuery("INSERT INTO receptions.*RETURNING id, date_time, pvz_id, status").
+					WithArgs(receptionID, pvzID, defaultStatus, &userID).
+					WillReturnRows(rows)
+
+				dbMock.ExpectExec("UPDATE product_transfers.*INSERT INTO reception_manifest").
+					WithArgs(receptionID, pvzID).
+					WillReturnResult(pgxmock.NewResult("INSERT", 0))
+
+				dbMock.ExpectCommit()
+			},
+			expected:      expectedReception,
+			expectedError: nil,
+		},
+		{
+			name:        "successful creation with manifest",
+			receptionID: receptionID,
+			pvzID:       pvzID,
+			manifest:    manifest,
+			mockSetup: func() {
+				dbMock.ExpectBegin()
+
+				dbMock.ExpectQuery("SELECT status FROM pvzs.*FOR SHARE").
+					WithArgs(pvzID).
+					WillReturnRows(pgxmock.NewRows([]string{"status"}).AddRow(string(pvzapi.Active)))
+
+				rows := pgxmock.NewRows([]string{"id", "date_time", "pvz_id", "status", "created_by"}).
+					AddRow(expectedReception.ID, expectedReception.DateTime, expectedReception.PvzID, expectedReception.Status, &userID)
+				dbMock.ExpectQuery("INSERT INTO receptions.*RETURNING id, date_time, pvz_id, status").
+					WithArgs(receptionID, pvzID, defaultStatus, &userID).
+					WillReturnRows(rows)
+
+				dbMock.ExpectExec("INSERT INTO reception_manifest").
+					WithArgs(receptionID, []string{"обувь", "одежда"}, []int{3, 1}).
+					WillReturnResult(pgxmock.NewResult("INSERT", 2))
+
+				dbMock.ExpectExec("UPDATE product_transfers.*INSERT INTO reception_manifest").
+					WithArgs(receptionID, pvzID).
+					WillReturnResult(pgxmock.NewResult("INSERT", 0))
+
+				dbMock.ExpectCommit()
+			},
+			expected:      &receptionWithManifest,
+			expectedError: nil,
+		},
+		{
+			name:        "transfers in transit are added to the manifest",
+			receptionID: receptionID,
+			pvzID:       pvzID,
+			manifest:    manifest,
+			mockSetup: func() {
+				dbMock.ExpectBegin()
+
+				dbMock.ExpectQuery("SELECT status FROM pvzs.*FOR SHARE").
+					WithArgs(pvzID).
+					WillReturnRows(pgxmock.NewRows([]string{"status"}).AddRow(string(pvzapi.Active)))
+
+				rows := pgxmock.NewRows([]string{"id", "date_time", "pvz_id", "status", "created_by"}).
+					AddRow(expectedReception.ID, expectedReception.DateTime, expectedReception.PvzID, expectedReception.Status, &userID)
+				dbMock.ExpectQuery("INSERT INTO receptions.*RETURNING id, date_time, pvz_id, status").
+					WithArgs(receptionID, pvzID, defaultStatus, &userID).
+					WillReturnRows(rows)
+
+				dbMock.ExpectExec("INSERT INTO reception_manifest").
+					WithArgs(receptionID, []string{"обувь", "одежда"}, []int{3, 1}).
+					WillReturnResult(pgxmock.NewResult("INSERT", 2))
+
+				dbMock.ExpectExec("UPDATE product_transfers.*INSERT INTO reception_manifest").
+					WithArgs(receptionID, pvzID).
+					WillReturnResult(pgxmock.NewResult("INSERT", 2))
+
+				dbMock.ExpectQuery("SELECT type, expected_count FROM reception_manifest").
+					WithArgs(receptionID).
+					WillReturnRows(pgxmock.NewRows([]string{"type", "expected_count"}).
+						AddRow("обувь", 4).
+						AddRow("одежда", 1).
+						AddRow("электроника", 1))
+
+				dbMock.ExpectCommit()
+			},
+			expected: &models.Reception{
+				ID:        receptionID,
+				PvzID:     pvzID,
+				Status:    defaultStatus,
+				DateTime:  expectedReception.DateTime,
+				CreatedBy: &userID,
+				Manifest: []models.ManifestItem{
+					{Type: "обувь", Count: 4},
+					{Type: "одежда", Count: 1},
+					{Type: "электроника", Count: 1},
+				},
+			},
+			expectedError: nil,
+		},
+		{
+			name:        "unknown manifest type",
+			receptionID: receptionID,
+			pvzID:       pvzID,
+			manifest:    manifest,
+			mockSetup: func() {
+				dbMock.ExpectBegin()
+
+				dbMock.ExpectQuery("SELECT status FROM pvzs.*FOR SHARE").
+					WithArgs(pvzID).
+					WillReturnRows(pgxmock.NewRows([]string{"status"}).AddRow(string(pvzapi.Active)))
+
+				rows := pgxmock.NewRows([]string{"id", "date_time", "pvz_id", "status", "created_by"}).
+					AddRow(expectedReception.ID, expectedReception.DateTime, expectedReception.PvzID, expectedReception.Status, &userID)
+				dbMock.ExpectQuery("INSERT INTO receptions.*RETURNING id, date_time, pvz_id, status").
+					WithArgs(receptionID, pvzID, defaultStatus, &userID).
+					WillReturnRows(rows)
+
+				dbMock.ExpectExec("INSERT INTO reception_manifest").
+					WithArgs(receptionID, []string{"обувь", "одежда"}, []int{3, 1}).
+					WillReturnError(&pgconn.PgError{Code: "23503"})
+
+				dbMock.ExpectRollback()
+			},
+			expected:      nil,
+			expectedError: db.ErrTypeNotFound,
+		},
+		{
+			name:        "successful creation with barcode manifest",
+			receptionID: receptionID,
+			pvzID:       pvzID,
+			barcodes:    barcodes,
+			mockSetup: func() {
+				dbMock.ExpectBegin()
+
+				dbMock.ExpectQuery("SELECT status FROM pvzs.*FOR SHARE").
+					WithArgs(pvzID).
+					WillReturnRows(pgxmock.NewRows([]string{"status"}).AddRow(string(pvzapi.Active)))
+
+				rows := pgxmock.NewRows([]string{"id", "date_time", "pvz_id", "status", "created_by"}).
+					AddRow(expectedReception.ID, expectedReception.DateTime, expectedReception.PvzID, expectedReception.Status, &userID)
+				dbMock.ExpectQuery("INSERT INTO receptions.*RETURNING id, date_time, pvz_id, status").
+					WithArgs(receptionID, pvzID, defaultStatus, &userID).
+					WillReturnRows(rows)
+
+				dbMock.ExpectExec("INSERT INTO reception_manifest_barcodes").
+					WithArgs(receptionID, []string{"4600000000017", "4600000000024"}, []string{"обувь", "одежда"}).
+					WillReturnResult(pgxmock.NewResult("INSERT", 2))
+
+				dbMock.ExpectExec("UPDATE product_transfers.*INSERT INTO reception_manifest").
+					WithArgs(receptionID, pvzID).
+					WillReturnResult(pgxmock.NewResult("INSERT", 0))
+
+				dbMock.ExpectCommit()
+			},
+			expected:      &receptionWithBarcodes,
+			expectedError: nil,
+		},
+		{
+			name:        "unknown barcode manifest type",
+			receptionID: receptionID,
+			pvzID:       pvzID,
+			barcodes:    barcodes,
+			mockSetup: func() {
+				dbMock.ExpectBegin()
+
+				dbMock.ExpectQuery("SELECT status FROM pvzs.*FOR SHARE").
+					WithArgs(pvzID).
+					WillReturnRows(pgxmock.NewRows([]string{"status"}).AddRow(string(pvzapi.Active)))
+
+				rows := pgxmock.NewRows([]string{"id", "date_time", "pvz_id", "status", "created_by"}).
+					AddRow(expectedReception.ID, expectedReception.DateTime, expectedReception.PvzID, expectedReception.Status, &userID)
+				dbMock.ExpectQuery("INSERT INTO receptions.*RETURNING id, date_time, pvz_id, status").
+					WithArgs(receptionID, pvzID, defaultStatus, &userID).
+					WillReturnRows(rows)
+
+				dbMock.ExpectExec("INSERT INTO reception_manifest_barcodes").
+					WithArgs(receptionID, []string{"4600000000017", "4600000000024"}, []string{"обувь", "одежда"}).
+					WillReturnError(&pgconn.PgError{Code: "23503"})
+
+				dbMock.ExpectRollback()
+			},
+			expected:      nil,
+			expectedError: db.ErrTypeNotFound,
+		},
+		{
+			name:        "pvz not found",
+			receptionID: receptionID,
+			pvzID:       pvzID,
+			mockSetup: func() {
+				dbMock.ExpectBegin()
+
+				dbMock.ExpectQuery("SELECT status FROM pvzs.*FOR SHARE").
+					WithArgs(pvzID).
+					WillReturnError(pgx.ErrNoRows)
+
+				dbMock.ExpectRollback()
+			},
+			expected:      nil,
+			expectedError: db.ErrReceptionConflict,
+		},
+		{
+			name:        "pvz is not active",
+			receptionID: receptionID,
+			pvzID:       pvzID,
+			mockSetup: func() {
+				dbMock.ExpectBegin()
+
+				dbMock.ExpectQuery("SELECT status FROM pvzs.*FOR SHARE").
+					WithArgs(pvzID).
+					WillReturnRows(pgxmock.NewRows([]string{"status"}).AddRow(string(pvzapi.Suspended)))
+
+				dbMock.ExpectRollback()
+			},
+			expected:      nil,
+			expectedError: db.ErrPVZNotActive,
+		},
+		{
+			name:        "conflict (already exists)",
+			receptionID: receptionID,
+			pvzID:       pvzID,
+			mockSetup: func() {
+				dbMock.ExpectBegin()
+
+				dbMock.ExpectQuery("SELECT status FROM pvzs.*FOR SHARE").
+					WithArgs(pvzID).
+					WillReturnRows(pgxmock.NewRows([]string{"status"}).AddRow(string(pvzapi.Active)))
+
+				dbMock.ExpectQuery("INSERT INTO receptions.*RETURNING id, date_time, pvz_id, status").
+					WithArgs(receptionID, pvzID, defaultStatus, &userID).
+					WillReturnError(pgx.ErrNoRows)
+
+				dbMock.ExpectRollback()
+			},
+			expected:      nil,
+			expectedError: db.ErrReceptionConflict,
+		},
+		{
+			name:        "query error",
+			receptionID: receptionID,
+			pvzID:       pvzID,
+			mockSetup: func() {
+				dbMock.ExpectBegin()
+
+				dbMock.ExpectQuery("SELECT status FROM pvzs.*FOR SHARE").
+					WithArgs(pvzID).
+					WillReturnRows(pgxmock.NewRows([]string{"status"}).AddRow(string(pvzapi.Active)))
+
+				dbMock.ExpectQuery("INSERT INTO receptions.*RETURNING id, date_time, pvz_id, status").
+					WithArgs(receptionID, pvzID, defaultStatus, &userID).
+					WillReturnError(ErrRandomError)
+
+				dbMock.ExpectRollback()
+			},
+			expected:      nil,
+			expectedError: ErrRandomError,
+		},
+		{
+			name:        "begin transaction error",
+			receptionID: receptionID,
+			pvzID:       pvzID,
+			mockSetup: func() {
+				dbMock.ExpectBegin().WillReturnError(ErrRandomError)
+			},
+			expected:      nil,
+			expectedError: ErrRandomError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockSetup()
+
+			result, err := repo.CreateReception(context.Background(), tt.receptionID, tt.pvzID, userID, tt.manifest, tt.barcodes)
+
+			if tt.expectedError != nil {
+				assert.ErrorIs(t, err, tt.expectedError)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.expected, result)
+			}
+		})
+	}
+}
+
+func TestPVZRepo_AddProduct(t *testing.T) {
+	dbMock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer dbMock.Close()
+
+	repo := NewPVZRepo(dbMock)
+
+	productID := uuid.New()
+	pvzID := uuid.New()
+	userID := uuid.New()
+	productType := "обувь"
+	barcode := "4600000000017"
+	receptionID := uuid.New()
+	now := time.Now()
+	fullCellID := uuid.New()
+	cellID := uuid.New()
+	cellNumber := 2
+	var noCell *uuid.UUID
+	capacity, receptionLimit := 10, 5
+
+	tests := []struct {
+		name          string
+		cellNumber    *int
+		mockSetup     func()
+		expected      *models.Product
+		expectedError error
+	}{
+		{
+			name: "success",
+			mockSetup: func() {
+				dbMock.ExpectBegin()
+
+				rowsReception := pgxmock.NewRows([]string{"id", "status", "capacity", "reception_limit"}).
+					AddRow(receptionID, string(pvzapi.Active), nil, nil)
+				dbMock.ExpectQuery("SELECT r.id, p.status, p.capacity, p.reception_limit FROM receptions r.*FOR UPDATE OF r").
+					WithArgs(pvzID, string(pvzapi.InProgress)).
+					WillReturnRows(rowsReception)
+
+				rowsCells := pgxmock.NewRows([]string{"id", "number", "capacity", "occupied"}).
+					AddRow(fullCellID, 1, 1, 1).
+					AddRow(cellID, cellNumber, 2, 1)
+				dbMock.ExpectQuery("SELECT c.id, c.number, c.capacity").
+					WithArgs(pvzID, productsInPVZ).
+					WillReturnRows(rowsCells)
+
+				dbMock.ExpectQuery("UPDATE receptions SET last_line_number = last_line_number \\+ 1").
+					WithArgs(receptionID).
+					WillReturnRows(pgxmock.NewRows([]string{"last_line_number"}).AddRow(1))
+
+				rowsProduct := pgxmock.NewRows([]string{"id", "date_time", "type", "reception_id", "line_number", "created_by", "barcode", "status", "cell_id"}).
+					AddRow(productID, now, productType, receptionID, 1, &userID, &barcode, string(pvzapi.Received), &cellID)
+				dbMock.ExpectQuery("INSERT INTO products.*RETURNING id, date_time, type, reception_id").
+					WithArgs(productID, productType, receptionID, &userID, &barcode, &cellID, 1).
+					WillReturnRows(rowsProduct)
+
+				dbMock.ExpectExec("UPDATE product_transfers t SET delivered_product_id").
+					WithArgs(receptionID, pvzID).
+					WillReturnResult(pgxmock.NewResult("UPDATE", 0))
+
+				dbMock.ExpectCommit()
+			},
+			expected: &models.Product{
+				ID:          productID,
+				DateTime:    now,
+				Type:        productType,
+				ReceptionID: receptionID,
+				LineNumber:  1,
+				CreatedBy:   &userID,
+				Barcode:     &barcode,
+				Status:      string(pvzapi.Received),
+				CellID:      &cellID,
+				CellNumber:  &cellNumber,
+			},
+			expectedError: nil,
+		},
+		{
+			name:       "requested cell not found",
+			cellNumber: &cellNumber,
+			mockSetup: func() {
+				dbMock.ExpectBegin()
+
+				rowsReception := pgxmock.NewRows([]string{"id", "status", "capacity", "reception_limit"}).
+					AddRow(receptionID, string(pvzapi.Active), nil, nil)
+				dbMock.ExpectQuery("SELECT r.id, p.status, p.capacity, p.reception_limit FROM receptions r.*FOR UPDATE OF r").
+					WithArgs(pvzID, string(pvzapi.InProgress)).
+					WillReturnRows(rowsReception)
+
+				rowsCells := pgxmock.NewRows([]string{"id", "number", "capacity", "occupied"}).
+					AddRow(fullCellID, 1, 1, 0)
+				dbMock.ExpectQuery("SELECT c.id, c.number, c.capacity").
+					WithArgs(pvzID, productsInPVZ).
+					WillReturnRows(rowsCells)
+
+				dbMock.ExpectRollback()
+			},
+			expected:      nil,
+			expectedError: db.ErrCellNotFound,
+		},
+		{
+			name:       "requested cell is full",
+			cellNumber: &cellNumber,
+			mockSetup: func() {
+				dbMock.ExpectBegin()
+
+				rowsReception := pgxmock.NewRows([]string{"id", "status", "capacity", "reception_limit"}).
+					AddRow(receptionID, string(pvzapi.Active), nil, nil)
+				dbMock.ExpectQuery("SELECT r.id, p.status, p.capacity, p.reception_limit FROM receptions r.*FOR UPDATE OF r").
+					WithArgs(pvzID, string(pvzapi.InProgress)).
+					WillReturnRows(rowsReception)
+
+				rowsCells := pgxmock.NewRows([]string{"id", "number", "capacity", "occupied"}).
+					AddRow(fullCellID, 1, 1, 0).
+					AddRow(cellID, cellNumber, 2, 2)
+				dbMock.ExpectQuery("SELECT c.id, c.number, c.capacity").
+					WithArgs(pvzID, productsInPVZ).
+					WillReturnRows(rowsCells)
+
+				dbMock.ExpectRollback()
+			},
+			expected:      nil,
+			expectedError: db.ErrCellFull,
+		},
+		{
+			name: "lock cells error",
+			mockSetup: func() {
+				dbMock.ExpectBegin()
+
+				rowsReception := pgxmock.NewRows([]string{"id", "status", "capacity", "reception_limit"}).
+					AddRow(receptionID, string(pvzapi.Active), nil, nil)
+				dbMock.ExpectQuery("SELECT r.id, p.status, p.capacity, p.reception_limit FROM receptions r.*FOR UPDATE OF r").
+					WithArgs(pvzID, string(pvzapi.InProgress)).
+					WillReturnRows(rowsReception)
+
+				dbMock.ExpectQuery("SELECT c.id, c.number, c.capacity").
+					WithArgs(pvzID, productsInPVZ).
+					WillReturnError(ErrRandomError)
+
+				dbMock.ExpectRollback()
+			},
+			expected:      nil,
+			expectedError: ErrRandomError,
+		},
+		{
+			name: "no open reception",
+			mockSetup: func() {
+				dbMock.ExpectBegin()
+
+				dbMock.ExpectQuery("SELECT r.id, p.status, p.capacity, p.reception_limit FROM receptions r.*FOR UPDATE OF r").
+					WithArgs(pvzID, string(pvzapi.InProgress)).
+					WillReturnError(pgx.ErrNoRows)
+
+				dbMock.ExpectRollback()
+			},
+			expected:      nil,
+			expectedError: db.ErrNoOpenReception,
+		},
+		{
+			name: "pvz is not active",
+			mockSetup: func() {
+				dbMock.ExpectBegin()
+
+				rowsReception := pgxmock.NewRows([]string{"id", "status", "capacity", "reception_limit"}).
+					AddRow(receptionID, string(pvzapi.Closed), nil, nil)
+				dbMock.ExpectQuery("SELECT r.id, p.status, p.capacity, p.reception_limit FROM receptions r.*FOR UPDATE OF r").
+					WithArgs(pvzID, string(pvzapi.InProgress)).
+					WillReturnRows(rowsReception)
+
+				dbMock.ExpectRollback()
+			},
+			expected:      nil,
+			expectedError: db.ErrPVZNotActive,
+		},
+		{
+			name: "pvz is full",
+			mockSetup: func() {
+				dbMock.ExpectBegin()
+
+				rowsReception := pgxmock.NewRows([]string{"id", "status", "capacity", "reception_limit"}).
+					AddRow(receptionID, string(pvzapi.Active), &capacity, nil)
+				dbMock.ExpectQuery("SELECT r.id, p.status, p.capacity, p.reception_limit FROM receptions r.*FOR UPDATE OF r").
+					WithArgs(pvzID, string(pvzapi.InProgress)).
+					WillReturnRows(rowsReception)
+
+				dbMock.ExpectQuery("SELECT \\(SELECT COUNT\\(\\*\\) FROM products pr JOIN receptions r").
+					WithArgs(pvzID, receptionID, productsInPVZ).
+					WillReturnRows(pgxmock.NewRows([]string{"stored", "received"}).AddRow(10, 0))
+
+				dbMock.ExpectRollback()
+			},
+			expected:      nil,
+			expectedError: db.ErrCapacityExceeded,
+		},
+		{
+			name: "reception limit reached",
+			mockSetup: func() {
+				dbMock.ExpectBegin()
+
+				rowsReception := pgxmock.NewRows([]string{"id", "status", "capacity", "reception_limit"}).
+					AddRow(receptionID, string(pvzapi.Active), &capacity, &receptionLimit)
+				dbMock.ExpectQuery("SELECT r.id, p.status, p.capacity, p.reception_limit FROM receptions r.*FOR UPDATE OF r").
+					WithArgs(pvzID, string(pvzapi.InProgress)).
+					WillReturnRows(rowsReception)
+
+				dbMock.ExpectQuery("SELECT \\(SELECT COUNT\\(\\*\\) FROM products pr JOIN receptions r").
+					WithArgs(pvzID, receptionID, productsInPVZ).
+					WillReturnRows(pgxmock.NewRows([]string{"stored", "received"}).AddRow(3, 5))
+
+				dbMock.ExpectRollback()
+			},
+			expected:      nil,
+			expectedError: db.ErrReceptionLimit,
+		},
+		{
+			name: "count products error",
+			mockSetup: func() {
+				dbMock.ExpectBegin()
+
+				rowsReception := pgxmock.NewRows([]string{"id", "status", "capacity", "reception_limit"}).
+					AddRow(receptionID, string(pvzapi.Active), &capacity, nil)
+				dbMock.ExpectQuery("SELECT r.id, p.status, p.capacity, p.reception_limit FROM receptions r.*FOR UPDATE OF r").
+					WithArgs(pvzID, string(pvzapi.InProgress)).
+					WillReturnRows(rowsReception)
+
+				dbMock.ExpectQuery("SELECT \\(SELECT COUNT\\(\\*\\) FROM products pr JOIN receptions r").
+					WithArgs(pvzID, receptionID, productsInPVZ).
+					WillReturnError(ErrRandomError)
+
+				dbMock.ExpectRollback()
+			},
+			expected:      nil,
+			expectedError: ErrRandomError,
+		},
+		{
+			name: "type not in catalog",
+			mockSetup: func() {
+				dbMock.ExpectBegin()
+
+				rowsReception := pgxmock.NewRows([]string{"id", "status", "capacity", "reception_limit"}).
+					AddRow(receptionID, string(pvzapi.Active), nil, nil)
+				dbMock.ExpectQuery("SELECT r.id, p.status, p.capacity, p.reception_limit FROM receptions r.*FOR UPDATE OF r").
+					WithArgs(pvzID, string(pvzapi.InProgress)).
+					WillReturnRows(rowsReception)
+
+				dbMock.ExpectQuery("SELECT c.id, c.number, c.capacity").
+					WithArgs(pvzID, productsInPVZ).
+					WillReturnRows(pgxmock.NewRows([]string{"id", "number", "capacity", "occupied"}))
+
+				dbMock.ExpectQuery("UPDATE receptions SET last_line_number = last_line_number \\+ 1").
+					WithArgs(receptionID).
+					WillReturnRows(pgxmock.NewRows([]string{"last_line_number"}).AddRow(1))
+
+				dbMock.ExpectQuery("INSERT INTO products.*RETURNING id, date_time, type, reception_id").
+					WithArgs(productID, productType, receptionID, &userID, &barcode, noCell, 1).
+					WillReturnError(&pgconn.PgError{Code: "23503"})
+
+				dbMock.ExpectRollback()
+			},
+			expected:      nil,
+			expectedError: db.ErrTypeNotFound,
+		},
+		{
+			name: "duplicate barcode in reception",
+			mockSetup: func() {
+				dbMock.ExpectBegin()
+
+				rowsReception := pgxmock.NewRows([]string{"id", "status", "capacity", "reception_limit"}).
+					AddRow(receptionID, string(pvzapi.Active), nil, nil)
+				dbMock.ExpectQuery("SELECT r.id, p.status, p.capacity, p.reception_limit FROM receptions r.*FOR UPDATE OF r").
+					WithArgs(pvzID, string(pvzapi.InProgress)).
+					WillReturnRows(rowsReception)
+
+				dbMock.ExpectQuery("SELECT c.id, c.number, c.capacity").
+					WithArgs(pvzID, productsInPVZ).
+					WillReturnRows(pgxmock.NewRows([]string{"id", "number", "capacity", "occupied"}))
+
+				dbMock.ExpectQuery("UPDATE receptions SET last_line_number = last_line_number \\+ 1").
+					WithArgs(receptionID).
+					WillReturnRows(pgxmock.NewRows([]string{"last_line_number"}).AddRow(1))
+
+				dbMock.ExpectQuery("INSERT INTO products.*RETURNING id, date_time, type, reception_id").
+					WithArgs(productID, productType, receptionID, &userID, &barcode, noCell, 1).
+					WillReturnError(&pgconn.PgError{Code: "23505"})
+
+				dbMock.ExpectRollback()
+			},
+			expected:      nil,
+			expectedError: db.ErrDuplicateBarcode,
+		},
+		{
+			name: "insert product error",
+			mockSetup: func() {
+				dbMock.ExpectBegin()
+
+				rowsReception := pgxmock.NewRows([]string{"id", "status", "capacity", "reception_limit"}).
+					AddRow(receptionID, string(pvzapi.Active), nil, nil)
+				dbMock.ExpectQuery("SELECT r.id, p.status, p.capacity, p.reception_limit FROM receptions r.*FOR UPDATE OF r").
+					WithArgs(pvzID, string(pvzapi.InProgress)).
+					WillReturnRows(rowsReception)
+
+				dbMock.ExpectQuery("SELECT c.id, c.number, c.capacity").
+					WithArgs(pvzID, productsInPVZ).
+					WillReturnRows(pgxmock.NewRows([]string{"id", "number", "capacity", "occupied"}))
+
+				dbMock.ExpectQuery("UPDATE receptions SET last_line_number = last_line_number \\+ 1").
+					WithArgs(receptionID).
+					WillReturnRows(pgxmock.NewRows([]string{"last_line_number"}).AddRow(1))
+
+				dbMock.ExpectQuery("INSERT INTO products.*RETURNING id, date_time, type, reception_id").
+					WithArgs(productID, productType, receptionID, &userID, &barcode, noCell, 1).
+					WillReturnError(ErrRandomError)
+
+				dbMock.ExpectRollback()
+			},
+			expected:      nil,
+			expectedError: ErrRandomError,
+		},
+		{
+			name: "line counter error",
+			mockSetup: func() {
+				dbMock.ExpectBegin()
+
+				rowsReception := pgxmock.NewRows([]string{"id", "status", "capacity", "reception_limit"}).
+					AddRow(receptionID, string(pvzapi.Active), nil, nil)
+				dbMock.ExpectQuery("SELECT r.id, p.status, p.capacity, p.reception_limit FROM receptions r.*FOR UPDATE OF r").
+					WithArgs(pvzID, string(pvzapi.InProgress)).
+					WillReturnRows(rowsReception)
+
+				dbMock.ExpectQuery("SELECT c.id, c.number, c.capacity").
+					WithArgs(pvzID, productsInPVZ).
+					WillReturnRows(pgxmock.NewRows([]string{"id", "number", "capacity", "occupied"}))
+
+				dbMock.ExpectQuery("UPDATE receptions SET last_line_number = last_line_number \\+ 1").
+					WithArgs(receptionID).
+					WillReturnError(ErrRandomError)
+
+				dbMock.ExpectRollback()
+			},
+			expected:      nil,
+			expectedError: ErrRandomError,
+		},
+		{
+			name: "deliver transfers error",
+			mockSetup: func() {
+				dbMock.ExpectBegin()
+
+				rowsReception := pgxmock.NewRows([]string{"id", "status", "capacity", "reception_limit"}).
+					AddRow(receptionID, string(pvzapi.Active), nil, nil)
+				dbMock.ExpectQuery("SELECT r.id, p.status, p.capacity, p.reception_limit FROM receptions r.*FOR UPDATE OF r").
+					WithArgs(pvzID, string(pvzapi.InProgress)).
+					WillReturnRows(rowsReception)
+
+				dbMock.ExpectQuery("SELECT c.id, c.number, c.capacity").
+					WithArgs(pvzID, productsInPVZ).
+					WillReturnRows(pgxmock.NewRows([]string{"id", "number", "capacity", "occupied"}))
+
+				dbMock.ExpectQuery("UPDATE receptions SET last_line_number = last_line_number \\+ 1").
+					WithArgs(receptionID).
+					WillReturnRows(pgxmock.NewRows([]string{"last_line_number"}).AddRow(1))
+
+				rowsProduct := pgxmock.NewRows([]string{"id", "date_time", "type", "reception_id", "line_number", "created_by", "barcode", "status", "cell_id"}).
+					AddRow(productID, now, productType, receptionID, 1, &userID, &barcode, string(pvzapi.Received), noCell)
+				dbMock.ExpectQuery("INSERT INTO products.*RETURNING id, date_time, type, reception_id").
+					WithArgs(productID, productType, receptionID, &userID, &barcode, noCell, 1).
+					WillReturnRows(rowsProduct)
+
+				dbMock.ExpectExec("UPDATE product_transfers t SET delivered_product_id").
+					WithArgs(receptionID, pvzID).
+					WillReturnError(ErrRandomError)
+
+				dbMock.ExpectRollback()
+			},
+			expected:      nil,
+			expectedError: ErrRandomError,
+		},
+		{
+			name: "begin transaction error",
+			mockSetup: func() {
+				dbMock.ExpectBegin().WillReturnError(ErrRandomError)
+			},
+			expected:      nil,
+			expectedError: ErrRandomError,
+		},
+		{
+			name: "commit transaction error",
+			mockSetup: func() {
+				dbMock.ExpectBegin()
+
+				rowsReception := pgxmock.NewRows([]string{"id", "status", "capacity", "reception_limit"}).
+					AddRow(receptionID, string(pvzapi.Active), nil, nil)
+				dbMock.ExpectQuery("SELECT r.id, p.status, p.capacity, p.reception_limit FROM receptions r.*FOR UPDATE OF r").
+					WithArgs(pvzID, string(pvzapi.InProgress)).
+					WillReturnRows(rowsReception)
+
+				dbMock.ExpectQuery("SELECT c.id, c.number, c.capacity").
+					WithArgs(pvzID, productsInPVZ).
+					WillReturnRows(pgxmock.NewRows([]string{"id", "number", "capacity", "occupied"}))
+
+				dbMock.ExpectQuery("UPDATE receptions SET last_line_number = last_line_number \\+ 1").
+					WithArgs(receptionID).
+					WillReturnRows(pgxmock.NewRows([]string{"last_line_number"}).AddRow(1))
+
+				rowsProduct := pgxmock.NewRows([]string{"id", "date_time", "type", "reception_id", "line_number", "created_by", "barcode", "status", "cell_id"}).
+					AddRow(productID, now, productType, receptionID, 1, &userID, &barcode, string(pvzapi.Received), noCell)
+				dbMock.ExpectQuery("INSERT INTO products.*RETURNING id, date_time, type, reception_id").
+					WithArgs(productID, productType, receptionID, &userID, &barcode, noCell, 1).
+					WillReturnRows(rowsProduct)
+
+				dbMock.ExpectExec("UPDATE product_transfers t SET delivered_product_id").
+					WithArgs(receptionID, pvzID).
+					WillReturnResult(pgxmock.NewResult("UPDATE", 0))
+
+				dbMock.ExpectCommit().WillReturnError(ErrRandomError)
+			},
+			expectedError: ErrRandomError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockSetup()
+
+			result, err := repo.AddProduct(context.Background(), productID, pvzID, userID, productType, &barcode, tt.cellNumber)
+
+			if tt.expectedError != nil {
+				assert.ErrorIs(t, err, tt.expectedError)
+				assert.Nil(t, result)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.expected, result)
+			}
+		})
+	}
+}
+
+func TestPVZRepo_AddProducts(t *testing.T) {
+	dbMock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer dbMock.Close()
+
+	repo := NewPVZRepo(dbMock)
+
+	pvzID := uuid.New()
+	userID := uuid.New()
+	receptionID := uuid.New()
+	firstID, secondID, thirdID, fourthID := uuid.New(), uuid.New(), uuid.New(), uuid.New()
+	existing, fresh := "4600000000017", "4600000000024"
+	cellID := uuid.New()
+	cellNumber, missingCell := 1, 5
+	capacity, lastLine := 20, 4
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	products := []models.Product{
+		{ID: firstID, Type: "обувь", Barcode: &existing},
+		{ID: secondID, Type: "одежда", Barcode: &fresh},
+		{ID: thirdID, Type: "обувь", Barcode: &fresh},
+		{ID: fourthID, Type: "обувь", CellNumber: &missingCell},
+	}
+
+	copyTable := pgx.Identifier{"products"}
+	copyColumns := []string{"id", "date_time", "type", "reception_id", "line_number", "created_by", "barcode", "cell_id"}
+
+	expectReception := func(status string) {
+		dbMock.ExpectQuery("SELECT r.id, p.status, p.capacity, p.reception_limit FROM receptions r.*FOR UPDATE OF r").
+			WithArgs(pvzID, string(pvzapi.InProgress)).
+			WillReturnRows(pgxmock.NewRows([]string{"id", "status", "capacity", "reception_limit"}).AddRow(receptionID, status, nil, nil))
+	}
+	expectBarcodes := func() {
+		dbMock.ExpectQuery("SELECT barcode FROM products WHERE reception_id = \\$1 AND barcode = ANY").
+			WithArgs(receptionID, []string{existing, fresh, fresh}).
+			WillReturnRows(pgxmock.NewRows([]string{"barcode"}).AddRow(existing))
+	}
+	expectCells := func() {
+		dbMock.ExpectQuery("SELECT c.id, c.number, c.capacity").
+			WithArgs(pvzID, productsInPVZ).
+			WillReturnRows(pgxmock.NewRows([]string{"id", "number", "capacity", "occupied"}).AddRow(cellID, cellNumber, 1, 0))
+	}
+	expectLastLine := func() {
+		dbMock.ExpectQuery("SELECT last_line_number, CURRENT_TIMESTAMP FROM receptions WHERE id = \\$1").
+			WithArgs(receptionID).
+			WillReturnRows(pgxmock.NewRows([]string{"last_line_number", "current_timestamp"}).AddRow(lastLine, now))
+	}
+	expectLineCounter := func() {
+		dbMock.ExpectExec("UPDATE receptions SET last_line_number = \\$2, last_activity_at = CURRENT_TIMESTAMP WHERE id = \\$1").
+			WithArgs(receptionID, lastLine+1).
+			WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+	}
+
+	tests := []struct {
+		name          string
+		mockSetup     func()
+		expected      []models.ProductBatchResult
+		expectedError error
+	}{
+		{
+			name: "duplicates and missing cells are skipped, the rest is copied",
+			mockSetup: func() {
+				dbMock.ExpectBegin()
+				expectReception(string(pvzapi.Active))
+				expectBarcodes()
+				expectCells()
+				expectLastLine()
+				dbMock.ExpectCopyFrom(copyTable, copyColumns).WillReturnResult(1)
+				expectLineCounter()
+				dbMock.ExpectExec("UPDATE product_transfers t SET delivered_product_id").
+					WithArgs(receptionID, pvzID).
+					WillReturnResult(pgxmock.NewResult("UPDATE", 0))
+				dbMock.ExpectCommit()
+			},
+			expected: []models.ProductBatchResult{
+				{Err: db.ErrDuplicateBarcode},
+				{Product: &models.Product{ID: secondID, DateTime: now, Type: "одежда", ReceptionID: receptionID, LineNumber: lastLine + 1, CreatedBy: &userID, Barcode: &fresh, Status: string(pvzapi.Received), CellID: &cellID, CellNumber: &cellNumber}},
+				{Err: db.ErrDuplicateBarcode},
+				{Err: db.ErrCellNotFound},
+			},
+			expectedError: nil,
+		},
+		{
+			name: "products beyond the pvz capacity are rejected",
+			mockSetup: func() {
+				dbMock.ExpectBegin()
+				dbMock.ExpectQuery("SELECT r.id, p.status, p.capacity, p.reception_limit FROM receptions r.*FOR UPDATE OF r").
+					WithArgs(pvzID, string(pvzapi.InProgress)).
+					WillReturnRows(pgxmock.NewRows([]string{"id", "status", "capacity", "reception_limit"}).
+						AddRow(receptionID, string(pvzapi.Active), &capacity, nil))
+				expectBarcodes()
+				dbMock.ExpectQuery("SELECT \\(SELECT COUNT\\(\\*\\) FROM products pr JOIN receptions r").
+					WithArgs(pvzID, receptionID, productsInPVZ).
+					WillReturnRows(pgxmock.NewRows([]string{"stored", "received"}).AddRow(capacity-1, 0))
+				expectCells()
+				expectLastLine()
+				dbMock.ExpectCopyFrom(copyTable, copyColumns).WillReturnResult(1)
+				expectLineCounter()
+				dbMock.ExpectExec("UPDATE product_transfers t SET delivered_product_id").
+					WithArgs(receptionID, pvzID).
+					WillReturnResult(pgxmock.NewResult("UPDATE", 0))
+				dbMock.ExpectCommit()
+			},
+			expected: []models.ProductBatchResult{
+				{Err: db.ErrDuplicateBarcode},
+				{Product: &models.Product{ID: secondID, DateTime: now, Type: "одежда", ReceptionID: receptionID, LineNumber: lastLine + 1, CreatedBy: &userID, Barcode: &fresh, Status: string(pvzapi.Received), CellID: &cellID, CellNumber: &cellNumber}},
+				{Err: db.ErrDuplicateBarcode},
+				{Err: db.ErrCapacityExceeded},
+			},
+			expectedError: nil,
+		},
+		{
+			name: "no open reception",
+			mockSetup: func() {
+				dbMock.ExpectBegin()
+				dbMock.ExpectQuery("SELECT r.id, p.status, p.capacity, p.reception_limit FROM receptions r.*FOR UPDATE OF r").
+					WithArgs(pvzID, string(pvzapi.InProgress)).
+					WillReturnError(pgx.ErrNoRows)
+				dbMock.ExpectRollback()
+			},
+			expected:      nil,
+			expectedError: db.ErrNoOpenReception,
+		},
+		{
+			name: "pvz is not active",
+			mockSetup: func() {
+				dbMock.ExpectBegin()
+				expectReception(string(pvzapi.Suspended))
+				dbMock.ExpectRollback()
+			},
+			expected:      nil,
+			expectedError: db.ErrPVZNotActive,
+		},
+		{
+			name: "type not in catalog",
+			mockSetup: func() {
+				dbMock.ExpectBegin()
+				expectReception(string(pvzapi.Active))
+				expectBarcodes()
+				expectCells()
+				expectLastLine()
+				dbMock.ExpectCopyFrom(copyTable, copyColumns).WillReturnError(&pgconn.PgError{Code: "23503"})
+				dbMock.ExpectRollback()
+			},
+			expected:      nil,
+			expectedError: db.ErrTypeNotFound,
+		},
+		{
+			name: "lock cells error",
+			mockSetup: func() {
+				dbMock.ExpectBegin()
+				expectReception(string(pvzapi.Active))
+				expectBarcodes()
+				dbMock.ExpectQuery("SELECT c.id, c.number, c.capacity").
+					WithArgs(pvzID, productsInPVZ).
+					WillReturnError(ErrRandomError)
+				dbMock.ExpectRollback()
+			},
+			expected:      nil,
+			expectedError: ErrRandomError,
+		},
+		{
+			name: "last line number error",
+			mockSetup: func() {
+				dbMock.ExpectBegin()
+				expectReception(string(pvzapi.Active))
+				expectBarcodes()
+				expectCells()
+				dbMock.ExpectQuery("SELECT last_line_number, CURRENT_TIMESTAMP FROM receptions").
+					WithArgs(receptionID).
+					WillReturnError(ErrRandomError)
+				dbMock.ExpectRollback()
+			},
+			expected:      nil,
+			expectedError: ErrRandomError,
+		},
+		{
+			name: "line counter error",
+			mockSetup: func() {
+				dbMock.ExpectBegin()
+				expectReception(string(pvzapi.Active))
+				expectBarcodes()
+				expectCells()
+				expectLastLine()
+				dbMock.ExpectCopyFrom(copyTable, copyColumns).WillReturnResult(1)
+				dbMock.ExpectExec("UPDATE receptions SET last_line_number").
+					WithArgs(receptionID, lastLine+1).
+					WillReturnError(ErrRandomError)
+				dbMock.ExpectRollback()
+			},
+			expected:      nil,
+			expectedError: ErrRandomError,
+		},
+		{
+			name: "copy error",
+			mockSetup: func() {
+				dbMock.ExpectBegin()
+				expectReception(string(pvzapi.Active))
+				expectBarcodes()
+				expectCells()
+				expectLastLine()
+				dbMock.ExpectCopyFrom(copyTable, copyColumns).WillReturnError(ErrRandomError)
+				dbMock.ExpectRollback()
+			},
+			expected:      nil,
+			expectedError: ErrRandomError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockSetup()
+
+			result, err := repo.AddProducts(context.Background(), pvzID, userID, products)
+
+			if tt.expectedError != nil {
+				assert.ErrorIs(t, err, tt.expectedError)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, tt.expected, result)
+			assert.NoError(t, dbMock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestPVZRepo_DeleteLastProduct(t *testing.T) {
+	dbMock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer dbMock.Close()
+
+	repo := NewPVZRepo(dbMock)
+
+	pvzID := uuid.New()
+	receptionID := uuid.New()
+	productID := uuid.New()
+
+	tests := []struct {
+		name          string
+		mockSetup     func()
+		expectedError error
+	}{
+		{
+			name: "success",
+			mockSetup: func() {
+				dbMock.ExpectBegin()
+
+				dbMock.ExpectQuery("SELECT id FROM receptions.*FOR UPDATE").
+					WithArgs(pvzID, string(pvzapi.InProgress)).
+					WillReturnRows(pgxmock.NewRows([]string{"id"}).AddRow(receptionID))
+
+				dbMock.ExpectQuery("DELETE FROM products.*ORDER BY line_number DESC.*RETURNING id").
+					WithArgs(receptionID).
+					WillReturnRows(pgxmock.NewRows([]string{"id"}).AddRow(productID))
+
+				dbMock.ExpectCommit()
+			},
+			expectedError: nil,
+		},
+		{
+			name: "no open reception",
+			mockSetup: func() {
+				dbMock.ExpectBegin()
+
+				dbMock.ExpectQuery("SELECT id FROM receptions.*FOR UPDATE").
+					WithArgs(pvzID, string(pvzapi.InProgress)).
+					WillReturnError(pgx.ErrNoRows)
+
+				dbMock.ExpectRollback()
+			},
+			expectedError: db.ErrNoOpenReception,
+		},
+		{
+			name: "no products to delete",
+			mockSetup: func() {
+				dbMock.ExpectBegin()
+
+				dbMock.ExpectQuery("SELECT id FROM receptions.*FOR UPDATE").
+					WithArgs(pvzID, string(pvzapi.InProgress)).
+					WillReturnRows(pgxmock.NewRows([]string{"id"}).AddRow(receptionID))
+
+				dbMock.ExpectQuery("DELETE FROM products.*RETURNING id").
+					WithArgs(receptionID).
+					WillReturnError(pgx.ErrNoRows)
+
+				dbMock.ExpectRollback()
+			},
+			expectedError: db.ErrNoProducts,
+		},
+		{
+			name: "begin transaction error",
+			mockSetup: func() {
+				dbMock.ExpectBegin().WillReturnError(ErrRandomError)
+			},
+			expectedError: ErrRandomError,
+		},
+		{
+			name: "commit transaction error",
+			mockSetup: func() {
+				dbMock.ExpectBegin()
+
+				dbMock.ExpectQuery("SELECT id FROM receptions.*FOR UPDATE").
+					WithArgs(pvzID, string(pvzapi.InProgress)).
+					WillReturnRows(pgxmock.NewRows([]string{"id"}).AddRow(receptionID))
+
+				dbMock.ExpectQuery("DELETE FROM products.*RETURNING id").
+					WithArgs(receptionID).
+					WillReturnRows(pgxmock.NewRows([]string{"id"}).AddRow(productID))
+
+				dbMock.ExpectCommit().WillReturnError(ErrRandomError)
+			},
+			expectedError: ErrRandomError,
+		},
+		{
+			name: "delete product error",
+			mockSetup: func() {
+				dbMock.ExpectBegin()
+
+				dbMock.ExpectQuery("SELECT id FROM receptions.*FOR UPDATE").
+					WithArgs(pvzID, string(pvzapi.InProgress)).
+					WillReturnRows(pgxmock.NewRows([]string{"id"}).AddRow(receptionID))
+
+				dbMock.ExpectQuery("DELETE FROM products.*RETURNING id").
+					WithArgs(receptionID).
+					WillReturnError(ErrRandomError)
+
+				dbMock.ExpectRollback()
+			},
+			expectedError: ErrRandomError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockSetup()
+
+			err := repo.DeleteLastProduct(context.Background(), pvzID)
+
+			if tt.expectedError != nil {
+				assert.ErrorIs(t, err, tt.expectedError)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestPVZRepo_DeleteProduct(t *testing.T) {
+	dbMock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer dbMock.Close()
+
+	repo := NewPVZRepo(dbMock)
+
+	receptionID := uuid.New()
+	productID := uuid.New()
+
+	tests := []struct {
+		name          string
+		mockSetup     func()
+		expectedError error
+	}{
+		{
+			name: "success",
+			mockSetup: func() {
+				dbMock.ExpectBegin()
+
+				dbMock.ExpectQuery("SELECT status FROM receptions WHERE id = \\$1 FOR UPDATE").
+					WithArgs(receptionID).
+					WillReturnRows(pgxmock.NewRows([]string{"status"}).AddRow(string(pvzapi.InProgress)))
+
+				dbMock.ExpectQuery("DELETE FROM products WHERE id = \\$1 AND reception_id = \\$2").
+					WithArgs(productID, receptionID).
+					WillReturnRows(pgxmock.NewRows([]string{"id"}).AddRow(productID))
+
+				dbMock.ExpectCommit()
+			},
+			expectedError: nil,
+		},
+		{
+			name: "reception not found",
+			mockSetup: func() {
+				dbMock.ExpectBegin()
+
+				dbMock.ExpectQuery("SELECT status FROM receptions WHERE id = \\$1 FOR UPDATE").
+					WithArgs(receptionID).
+					WillReturnError(pgx.ErrNoRows)
+
+				dbMock.ExpectRollback()
+			},
+			expectedError: db.ErrReceptionNotFound,
+		},
+		{
+			name: "reception is closed",
+			mockSetup: func() {
+				dbMock.ExpectBegin()
+
+				dbMock.ExpectQuery("SELECT status FROM receptions WHERE id = \\$1 FOR UPDATE").
+					WithArgs(receptionID).
+					WillReturnRows(pgxmock.NewRows([]string{"status"}).AddRow(string(pvzapi.Close)))
+
+				dbMock.ExpectRollback()
+			},
+			expectedError: db.ErrReceptionNotOpen,
+		},
+		{
+			name: "product not in reception",
+			mockSetup: func() {
+				dbMock.ExpectBegin()
+
+				dbMock.ExpectQuery("SELECT status FROM receptions WHERE id = \\$1 FOR UPDATE").
+					WithArgs(receptionID).
+					WillReturnRows(pgxmock.NewRows([]string{"status"}).AddRow(string(pvzapi.InProgress)))
+
+				dbMock.ExpectQuery("DELETE FROM products WHERE id = \\$1 AND reception_id = \\$2").
+					WithArgs(productID, receptionID).
+					WillReturnError(pgx.ErrNoRows)
+
+				dbMock.ExpectRollback()
+			},
+			expectedError: db.ErrProductNotFound,
+		},
+		{
+			name: "commit transaction error",
+			mockSetup: func() {
+				dbMock.ExpectBegin()
+
+				dbMock.ExpectQuery("SELECT status FROM receptions WHERE id = \\$1 FOR UPDATE").
+					WithArgs(receptionID).
+					WillReturnRows(pgxmock.NewRows([]string{"status"}).AddRow(string(pvzapi.InProgress)))
+
+				dbMock.ExpectQuery("DELETE FROM products WHERE id = \\$1 AND reception_id = \\$2").
+					WithArgs(productID, receptionID).
+					WillReturnRows(pgxmock.NewRows([]string{"id"}).AddRow(productID))
+
+				dbMock.ExpectCommit().WillReturnError(ErrRandomError)
+			},
+			expectedError: ErrRandomError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockSetup()
+
+			err := repo.DeleteProduct(context.Background(), receptionID, productID)
+
+			if tt.expectedError != nil {
+				assert.ErrorIs(t, err, tt.expectedError)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestPVZRepo_CloseLastReception(t *testing.T) {
+	dbMock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer dbMock.Close()
+
+	repo := NewPVZRepo(dbMock)
+
+	pvzID := uuid.New()
+	receptionID := uuid.New()
+	openerID := uuid.New()
+	userID := uuid.New()
+
+	now := time.Now()
+
+	manifestColumns := []string{"type", "expected_count"}
+	discrepancyColumns := []string{"type", "kind", "expected_count", "actual_count"}
+
+	shoes, clothes, electronics := "обувь", "одежда", "электроника"
+	missingBarcode, extraBarcode, mismatchedBarcode := "4600000000017", "4600000000024", "4600000000031"
+
+	tests := []struct {
+		name          string
+		mockSetup     func()
+		expectedError error
+		expected      *models.Reception
+	}{
+		{
+			name: "success",
+			mockSetup: func() {
+				dbMock.ExpectBegin()
+
+				dbMock.ExpectQuery("SELECT id, date_time, pvz_id, created_by FROM receptions.*FOR UPDATE").
+					WithArgs(pvzID, string(pvzapi.InProgress)).
+					WillReturnRows(pgxmock.NewRows([]string{"id", "date_time", "pvz_id", "created_by"}).
+						AddRow(receptionID, now, pvzID, &openerID))
+
+				dbMock.ExpectExec("UPDATE receptions SET status =.*").
+					WithArgs(string(pvzapi.Close), &userID, receptionID).
+					WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+
+				dbMock.ExpectExec("UPDATE products SET status = \\$2 WHERE reception_id = \\$1").
+					WithArgs(receptionID, string(pvzapi.Stored), string(pvzapi.Received)).
+					WillReturnResult(pgxmock.NewResult("UPDATE", 2))
+
+				dbMock.ExpectQuery("SELECT type, expected_count FROM reception_manifest").
+					WithArgs(receptionID).
+					WillReturnRows(pgxmock.NewRows([]string{"type", "expected_count"}))
+
+				dbMock.ExpectQuery("SELECT barcode, type FROM reception_manifest_barcodes").
+					WithArgs(receptionID).
+					WillReturnRows(pgxmock.NewRows([]string{"barcode", "type"}))
+
+				dbMock.ExpectCommit()
+			},
+			expected: &models.Reception{
+				ID:        receptionID,
+				DateTime:  now,
+				PvzID:     pvzID,
+				Status:    string(pvzapi.Close),
+				CreatedBy: &openerID,
+				ClosedBy:  &userID,
+			},
+			expectedError: nil,
+		},
+		{
+			name: "success with manifest",
+			mockSetup: func() {
+				dbMock.ExpectBegin()
+
+				dbMock.ExpectQuery("SELECT id, date_time, pvz_id, created_by FROM receptions.*FOR UPDATE").
+					WithArgs(pvzID, string(pvzapi.InProgress)).
+					WillReturnRows(pgxmock.NewRows([]string{"id", "date_time", "pvz_id", "created_by"}).
+						AddRow(receptionID, now, pvzID, &openerID))
+
+				dbMock.ExpectExec("UPDATE receptions SET status =.*").
+					WithArgs(string(pvzapi.Close), &userID, receptionID).
+					WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+
+				dbMock.ExpectExec("UPDATE products SET status = \\$2 WHERE reception_id = \\$1").
+					WithArgs(receptionID, string(pvzapi.Stored), string(pvzapi.Received)).
+					WillReturnResult(pgxmock.NewResult("UPDATE", 2))
+
+				dbMock.ExpectQuery("SELECT type, expected_count FROM reception_manifest").
+					WithArgs(receptionID).
+					WillReturnRows(pgxmock.NewRows(manifestColumns).
+						AddRow("обувь", 3).
+						AddRow("одежда", 2))
+
+				dbMock.ExpectQuery("INSERT INTO reception_discrepancies.*RETURNING type, kind, expected_count, actual_count").
+					WithArgs(receptionID, string(pvzapi.Unexpected), string(pvzapi.Missing), string(pvzapi.Extra)).
+					WillReturnRows(pgxmock.NewRows(discrepancyColumns).
+						AddRow("электроника", string(pvzapi.Unexpected), 0, 1).
+						AddRow("обувь", string(pvzapi.Missing), 3, 2))
+
+				dbMock.ExpectQuery("SELECT barcode, type FROM reception_manifest_barcodes").
+					WithArgs(receptionID).
+					WillReturnRows(pgxmock.NewRows([]string{"barcode", "type"}))
+
+				dbMock.ExpectCommit()
+			},
+			expected: &models.Reception{
+				ID:        receptionID,
+				DateTime:  now,
+				PvzID:     pvzID,
+				Status:    string(pvzapi.Close),
+				CreatedBy: &openerID,
+				ClosedBy:  &userID,
+				Manifest: []models.ManifestItem{
+					{Type: "обувь", Count: 3},
+					{Type: "одежда", Count: 2},
+				},
+				Discrepancies: []models.Discrepancy{
+					{Type: "обувь", Kind: string(pvzapi.Missing), Expected: 3, Actual: 2},
+					{Type: "электроника", Kind: string(pvzapi.Unexpected), Expected: 0, Actual: 1},
+				},
+			},
+			expectedError: nil,
+		},
+		{
+			name: "success with barcode manifest",
+			mockSetup: func() {
+				dbMock.ExpectBegin()
+
+				dbMock.ExpectQuery("SELECT id, date_time, pvz_id, created_by FROM receptions.*FOR UPDATE").
+					WithArgs(pvzID, string(pvzapi.InProgress)).
+					WillReturnRows(pgxmock.NewRows([]string{"id", "date_time", "pvz_id", "created_by"}).
+						AddRow(receptionID, now, pvzID, &openerID))
+
+				dbMock.ExpectExec("UPDATE receptions SET status =.*").
+					WithArgs(string(pvzapi.Close), &userID, receptionID).
+					WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+
+				dbMock.ExpectExec("UPDATE products SET status = \\$2 WHERE reception_id = \\$1").
+					WithArgs(receptionID, string(pvzapi.Stored), string(pvzapi.Received)).
+					WillReturnResult(pgxmock.NewResult("UPDATE", 2))
+
+				dbMock.ExpectQuery("SELECT type, expected_count FROM reception_manifest").
+					WithArgs(receptionID).
+					WillReturnRows(pgxmock.NewRows(manifestColumns))
+
+				dbMock.ExpectQuery("SELECT barcode, type FROM reception_manifest_barcodes").
+					WithArgs(receptionID).
+					WillReturnRows(pgxmock.NewRows([]string{"barcode", "type"}).
+						AddRow(missingBarcode, shoes).
+						AddRow(mismatchedBarcode, clothes))
+
+				dbMock.ExpectQuery("INSERT INTO reception_barcode_discrepancies.*RETURNING barcode, kind, expected_type, actual_type").
+					WithArgs(receptionID, string(pvzapi.Missing), string(pvzapi.Extra), string(pvzapi.MismatchedType)).
+					WillReturnRows(pgxmock.NewRows([]string{"barcode", "kind", "expected_type", "actual_type"}).
+						AddRow(mismatchedBarcode, string(pvzapi.MismatchedType), &clothes, &electronics).
+						AddRow(missingBarcode, string(pvzapi.Missing), &shoes, nil).
+						AddRow(extraBarcode, string(pvzapi.Extra), nil, &shoes))
+
+				dbMock.ExpectCommit()
+			},
+			expected: &models.Reception{
+				ID:        receptionID,
+				DateTime:  now,
+				PvzID:     pvzID,
+				Status:    string(pvzapi.Close),
+				CreatedBy: &openerID,
+				ClosedBy:  &userID,
+				ExpectedBarcodes: []models.ManifestBarcode{
+					{Barcode: missingBarcode, Type: shoes},
+					{Barcode: mismatchedBarcode, Type: clothes},
+				},
+				BarcodeDiscrepancies: []models.BarcodeDiscrepancy{
+					{Barcode: missingBarcode, Kind: string(pvzapi.Missing), ExpectedType: &shoes},
+					{Barcode: extraBarcode, Kind: string(pvzapi.Extra), ActualType: &shoes},
+					{Barcode: mismatchedBarcode, Kind: string(pvzapi.MismatchedType), ExpectedType: &clothes, ActualType: &electronics},
+				},
+			},
+			expectedError: nil,
+		},
+		{
+			name: "barcode discrepancy report error",
+			mockSetup: func() {
+				dbMock.ExpectBegin()
+
+				dbMock.ExpectQuery("SELECT id, date_time, pvz_id, created_by FROM receptions.*FOR UPDATE").
+					WithArgs(pvzID, string(pvzapi.InProgress)).
+					WillReturnRows(pgxmock.NewRows([]string{"id", "date_time", "pvz_id", "created_by"}).
+						AddRow(receptionID, now, pvzID, &openerID))
+
+				dbMock.ExpectExec("UPDATE receptions SET status =.*").
+					WithArgs(string(pvzapi.Close), &userID, receptionID).
+					WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+
+				dbMock.ExpectExec("UPDATE products SET status = \\$2 WHERE reception_id = \\$1").
+					WithArgs(receptionID, string(pvzapi.Stored), string(pvzapi.Received)).
+					WillReturnResult(pgxmock.NewResult("UPDATE", 2))
+
+				dbMock.ExpectQuery("SELECT type, expected_count FROM reception_manifest").
+					WithArgs(receptionID).
+					WillReturnRows(pgxmock.NewRows(manifestColumns))
+
+				dbMock.ExpectQuery("SELECT barcode, type FROM reception_manifest_barcodes").
+					WithArgs(receptionID).
+					WillReturnRows(pgxmock.NewRows([]string{"barcode", "type"}).AddRow(missingBarcode, shoes))
+
+				dbMock.ExpectQuery("INSERT INTO reception_barcode_discrepancies").
+					WithArgs(receptionID, string(pvzapi.Missing), string(pvzapi.Extra), string(pvzapi.MismatchedType)).
+					WillReturnError(ErrRandomError)
+
+				dbMock.ExpectRollback()
+			},
+			expected:      nil,
+			expectedError: ErrRandomError,
+		},
+		{
+			name: "discrepancy report error",
+			mockSetup: func() {
+				dbMock.ExpectBegin()
+
+				dbMock.ExpectQuery("SELECT id, date_time, pvz_id, created_by FROM receptions.*FOR UPDATE").
+					WithArgs(pvzID, string(pvzapi.InProgress)).
+					WillReturnRows(pgxmock.NewRows([]string{"id", "date_time", "pvz_id", "created_by"}).
+						AddRow(receptionID, now, pvzID, &openerID))
+
+				dbMock.ExpectExec("UPDATE receptions SET status =.*").
+					WithArgs(string(pvzapi.Close), &userID, receptionID).
+					WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+
+				dbMock.ExpectExec("UPDATE products SET status = \\$2 WHERE reception_id = \\$1").
+					WithArgs(receptionID, string(pvzapi.Stored), string(pvzapi.Received)).
+					WillReturnResult(pgxmock.NewResult("UPDATE", 2))
+
+				dbMock.ExpectQuery("SELECT type, expected_count FROM reception_manifest").
+					WithArgs(receptionID).
+					WillReturnRows(pgxmock.NewRows(manifestColumns).AddRow("обувь", 3))
+
+				dbMock.ExpectQuery("INSERT INTO reception_discrepancies").
+					WithArgs(receptionID, string(pvzapi.Unexpected), string(pvzapi.Missing), string(pvzapi.Extra)).
+					WillReturnError(ErrRandomError)
+
+				dbMock.ExpectRollback()
+			},
+			expected:      nil,
+			expectedError: ErrRandomError,
+		},
+		{
+			name: "no open reception",
+			mockSetup: func() {
+				dbMock.ExpectBegin()
+				dbMock.ExpectQuery("SELECT id, date_time, pvz_id, created_by FROM receptions.*FOR UPDATE").
+					WithArgs(pvzID, string(pvzapi.InProgress)).
+					WillReturnError(pgx.ErrNoRows)
+				dbMock.ExpectRollback()
+			},
+			expected:      nil,
+			expectedError: db.ErrNoOpenReception,
+		},
+		{
+			name: "begin transaction error",
+			mockSetup: func() {
+				dbMock.ExpectBegin().WillReturnError(ErrRandomError)
+			},
+			expected:      nil,
+			expectedError: ErrRandomError,
+		},
+		{
+			name: "update reception error",
+			mockSetup: func() {
+				dbMock.ExpectBegin()
+				dbMock.ExpectQuery("SELECT id, date_time, pvz_id, created_by FROM receptions.*FOR UPDATE").
+					WithArgs(pvzID, string(pvzapi.InProgress)).
+					WillReturnRows(pgxmock.NewRows([]string{"id", "date_time", "pvz_id", "created_by"}).
+						AddRow(receptionID, time.Now(), pvzID, &openerID))
+
+				dbMock.ExpectExec("UPDATE receptions SET status =.*").
+					WithArgs(string(pvzapi.Close), &userID, receptionID).
+					WillReturnError(ErrRandomError)
+
+				dbMock.ExpectRollback()
+			},
+			expected:      nil,
+			expectedError: ErrRandomError,
+		},
+		{
+			name: "store products error",
+			mockSetup: func() {
+				dbMock.ExpectBegin()
+				dbMock.ExpectQuery("SELECT id, date_time, pvz_id, created_by FROM receptions.*FOR UPDATE").
+					WithArgs(pvzID, string(pvzapi.InProgress)).
+					WillReturnRows(pgxmock.NewRows([]string{"id", "date_time", "pvz_id", "created_by"}).
+						AddRow(receptionID, time.Now(), pvzID, &openerID))
+
+				dbMock.ExpectExec("UPDATE receptions SET status =.*").
+					WithArgs(string(pvzapi.Close), &userID, receptionID).
+					WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+
+				dbMock.ExpectExec("UPDATE products SET status = \\$2 WHERE reception_id = \\$1").
+					WithArgs(receptionID, string(pvzapi.Stored), string(pvzapi.Received)).
+					WillReturnError(ErrRandomError)
+
+				dbMock.ExpectRollback()
+			},
+			expected:      nil,
+			expectedError: ErrRandomError,
+		},
+		{
+			name: "commit transaction error",
+			mockSetup: func() {
+				dbMock.ExpectBegin()
+
+				dbMock.ExpectQuery("SELECT id, date_time, pvz_id, created_by FROM receptions.*FOR UPDATE").
+					WithArgs(pvzID, string(pvzapi.InProgress)).
+					WillReturnRows(pgxmock.NewRows([]string{"id", "date_time", "pvz_id", "created_by"}).
+						AddRow(receptionID, time.Now(), pvzID, &openerID))
+
+				dbMock.ExpectExec("UPDATE receptions SET status =.*").
+					WithArgs(string(pvzapi.Close), &userID, receptionID).
+					WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+
+				dbMock.ExpectExec("UPDATE products SET status = \\$2 WHERE reception_id = \\$1").
+					WithArgs(receptionID, string(pvzapi.Stored), string(pvzapi.Received)).
+					WillReturnResult(pgxmock.NewResult("UPDATE", 2))
+
+				dbMock.ExpectQuery("SELECT type, expected_count FROM reception_manifest").
+					WithArgs(receptionID).
+					WillReturnRows(pgxmock.NewRows([]string{"type", "expected_count"}))
+
+				dbMock.ExpectQuery("SELECT barcode, type FROM reception_manifest_barcodes").
+					WithArgs(receptionID).
+					WillReturnRows(pgxmock.NewRows([]string{"barcode", "type"}))
+
+				dbMock.ExpectCommit().WillReturnError(ErrRandomError)
+			},
+			expected:      nil,
+			expectedError: ErrRandomError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockSetup()
+
+			result, err := repo.CloseLastReception(context.Background(), pvzID, userID)
+
+			if tt.expectedError != nil {
+				assert.ErrorIs(t, err, tt.expectedError)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.expected, result)
+			}
+		})
+	}
+}
+
+func TestPVZRepo_CancelLastReception(t *testing.T) {
+	dbMock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer dbMock.Close()
+
+	repo := NewPVZRepo(dbMock)
+
+	pvzID := uuid.New()
+	receptionID := uuid.New()
+	openerID := uuid.New()
+	userID := uuid.New()
+	now := time.Now()
+
+	columns := []string{"id", "date_time", "pvz_id", "created_by"}
+
+	tests := []struct {
+		name          string
+		mockSetup     func()
+		expected      *models.Reception
+		expectedError error
+	}{
+		{
+			name: "success",
+			mockSetup: func() {
+				dbMock.ExpectBegin()
+
+				dbMock.ExpectQuery("SELECT id, date_time, pvz_id, created_by FROM receptions.*FOR UPDATE").
+					WithArgs(pvzID, string(pvzapi.InProgress)).
+					WillReturnRows(pgxmock.NewRows(columns).AddRow(receptionID, now, pvzID, &openerID))
+
+				dbMock.ExpectExec("DELETE FROM products WHERE reception_id = \\$1").
+					WithArgs(receptionID).
+					WillReturnResult(pgxmock.NewResult("DELETE", 3))
+
+				dbMock.ExpectExec("UPDATE receptions SET status =.*").
+					WithArgs(string(pvzapi.Cancelled), &userID, receptionID).
+					WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+
+				dbMock.ExpectCommit()
+			},
+			expected: &models.Reception{
+				ID:        receptionID,
+				DateTime:  now,
+				PvzID:     pvzID,
+				Status:    string(pvzapi.Cancelled),
+				CreatedBy: &openerID,
+				ClosedBy:  &userID,
+			},
+			expectedError: nil,
+		},
+		{
+			name: "no open reception",
+			mockSetup: func() {
+				dbMock.ExpectBegin()
+
+				dbMock.ExpectQuery("SELECT id, date_time, pvz_id, created_by FROM receptions.*FOR UPDATE").
+					WithArgs(pvzID, string(pvzapi.InProgress)).
+					WillReturnError(pgx.ErrNoRows)
+
+				dbMock.ExpectRollback()
+			},
+			expected:      nil,
+			expectedError: db.ErrNoOpenReception,
+		},
+		{
+			name: "delete products error",
+			mockSetup: func() {
+				dbMock.ExpectBegin()
+
+				dbMock.ExpectQuery("SELECT id, date_time, pvz_id, created_by FROM receptions.*FOR UPDATE").
+					WithArgs(pvzID, string(pvzapi.InProgress)).
+					WillReturnRows(pgxmock.NewRows(columns).AddRow(receptionID, now, pvzID, &openerID))
+
+				dbMock.ExpectExec("DELETE FROM products WHERE reception_id = \\$1").
+					WithArgs(receptionID).
+					WillReturnError(ErrRandomError)
+
+				dbMock.ExpectRollback()
+			},
+			expected:      nil,
+			expectedError: ErrRandomError,
+		},
+		{
+			name: "commit transaction error",
+			mockSetup: func() {
+				dbMock.ExpectBegin()
+
+				dbMock.ExpectQuery("SELECT id, date_time, pvz_id, created_by FROM receptions.*FOR UPDATE").
+					WithArgs(pvzID, string(pvzapi.InProgress)).
+					WillReturnRows(pgxmock.NewRows(columns).AddRow(receptionID, now, pvzID, &openerID))
+
+				dbMock.ExpectExec("DELETE FROM products WHERE reception_id = \\$1").
+					WithArgs(receptionID).
+					WillReturnResult(pgxmock.NewResult("DELETE", 0))
+
+				dbMock.ExpectExec("UPDATE receptions SET status =.*").
+					WithArgs(string(pvzapi.Cancelled), &userID, receptionID).
+					WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+
+				dbMock.ExpectCommit().WillReturnError(ErrRandomError)
+			},
+			expected:      nil,
+			expectedError: ErrRandomError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockSetup()
+
+			result, err := repo.CancelLastReception(context.Background(), pvzID, userID)
+
+			if tt.expectedError != nil {
+				assert.ErrorIs(t, err, tt.expectedError)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, tt.expected, result)
+		})
+	}
+}
+
+func TestPVZRepo_CloseStaleReceptions(t *testing.T) {
+	dbMock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer dbMock.Close()
+
+	repo := NewPVZRepo(dbMock)
+
+	pvzID := uuid.New()
+	receptionID := uuid.New()
+	openerID := uuid.New()
+	now := time.Now()
+	idleSince := now.Add(-time.Hour)
+
+	columns := []string{"id", "date_time", "pvz_id", "status", "created_by", "auto_closed"}
+
+	expectCandidates := func() {
+		dbMock.ExpectQuery("SELECT r.id FROM receptions r WHERE r.status = \\$1::VARCHAR.*FOR UPDATE SKIP LOCKED").
+			WithArgs(string(pvzapi.InProgress), idleSince).
+			WillReturnRows(pgxmock.NewRows([]string{"id"}).AddRow(receptionID))
+	}
+
+	tests := []struct {
+		name          string
+		mockSetup     func()
+		expected      []models.Reception
+		expectedError error
+	}{
+		{
+			name: "success",
+			mockSetup: func() {
+				dbMock.ExpectBegin()
+
+				expectCandidates()
+
+				dbMock.ExpectQuery("UPDATE receptions r SET status = \\$1, auto_closed = TRUE WHERE r.id = ANY").
+					WithArgs(string(pvzapi.Close), []uuid.UUID{receptionID}, string(pvzapi.InProgress), idleSince).
+					WillReturnRows(pgxmock.NewRows(columns).
+						AddRow(receptionID, now, pvzID, string(pvzapi.Close), &openerID, true))
+
+				dbMock.ExpectExec("UPDATE products SET status = \\$2 WHERE reception_id = \\$1").
+					WithArgs(receptionID, string(pvzapi.Stored), string(pvzapi.Received)).
+					WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+
+				dbMock.ExpectQuery("SELECT type, expected_count FROM reception_manifest").
+					WithArgs(receptionID).
+					WillReturnRows(pgxmock.NewRows([]string{"type", "expected_count"}).AddRow("обувь", 2))
+
+				dbMock.ExpectQuery("INSERT INTO reception_discrepancies").
+					WithArgs(receptionID, string(pvzapi.Unexpected), string(pvzapi.Missing), string(pvzapi.Extra)).
+					WillReturnRows(pgxmock.NewRows([]string{"type", "kind", "expected_count", "actual_count"}).
+						AddRow("обувь", string(pvzapi.Missing), 2, 1))
+
+				dbMock.ExpectQuery("SELECT barcode, type FROM reception_manifest_barcodes").
+					WithArgs(receptionID).
+					WillReturnRows(pgxmock.NewRows([]string{"barcode", "type"}))
+
+				dbMock.ExpectCommit()
+			},
+			expected: []models.Reception{
+				{
+					ID:         receptionID,
+					DateTime:   now,
+					PvzID:      pvzID,
+					Status:     string(pvzapi.Close),
+					CreatedBy:  &openerID,
+					AutoClosed: true,
+					Manifest:   []models.ManifestItem{{Type: "обувь", Count: 2}},
+					Discrepancies: []models.Discrepancy{
+						{Type: "обувь", Kind: string(pvzapi.Missing), Expected: 2, Actual: 1},
+					},
+				},
+			},
+			expectedError: nil,
+		},
+		{
+			name: "nothing to close",
+			mockSetup: func() {
+				dbMock.ExpectBegin()
+
+				dbMock.ExpectQuery("SELECT r.id FROM receptions r WHERE r.status = \\$1::VARCHAR.*FOR UPDATE SKIP LOCKED").
+					WithArgs(string(pvzapi.InProgress), idleSince).
+					WillReturnRows(pgxmock.NewRows([]string{"id"}))
+
+				dbMock.ExpectCommit()
+			},
+			expected:      nil,
+			expectedError: nil,
+		},
+		{
+			name: "reopened reception is not idle",
+			mockSetup: func() {
+				dbMock.ExpectBegin()
+
+				dbMock.ExpectQuery("SELECT r.id FROM receptions r WHERE r.status = \\$1::VARCHAR AND GREATEST\\( r.last_activity_at, \\(SELECT MAX\\(p.date_time\\).*FOR UPDATE SKIP LOCKED").
+					WithArgs(string(pvzapi.InProgress), idleSince).
+					WillReturnRows(pgxmock.NewRows([]string{"id"}))
+
+				dbMock.ExpectCommit()
+			},
+			expected:      nil,
+			expectedError: nil,
+		},
+		{
+			name: "product added before the lock",
+			mockSetup: func() {
+				dbMock.ExpectBegin()
+
+				expectCandidates()
+
+				dbMock.ExpectQuery("UPDATE receptions r SET status = \\$1, auto_closed = TRUE WHERE r.id = ANY").
+					WithArgs(string(pvzapi.Close), []uuid.UUID{receptionID}, string(pvzapi.InProgress), idleSince).
+					WillReturnRows(pgxmock.NewRows(columns))
+
+				dbMock.ExpectCommit()
+			},
+			expected:      nil,
+			expectedError: nil,
+		},
+		{
+			name: "select candidates error",
+			mockSetup: func() {
+				dbMock.ExpectBegin()
+
+				dbMock.ExpectQuery("SELECT r.id FROM receptions r WHERE r.status = \\$1::VARCHAR.*FOR UPDATE SKIP LOCKED").
+					WithArgs(string(pvzapi.InProgress), idleSince).
+					WillReturnError(ErrRandomError)
+
+				dbMock.ExpectRollback()
+			},
+			expected:      nil,
+			expectedError: ErrRandomError,
+		},
+		{
+			name: "update error",
+			mockSetup: func() {
+				dbMock.ExpectBegin()
+
+				expectCandidates()
+
+				dbMock.ExpectQuery("UPDATE receptions r SET status = \\$1, auto_closed = TRUE WHERE r.id = ANY").
+					WithArgs(string(pvzapi.Close), []uuid.UUID{receptionID}, string(pvzapi.InProgress), idleSince).
+					WillReturnError(ErrRandomError)
+
+				dbMock.ExpectRollback()
+			},
+			expected:      nil,
+			expectedError: ErrRandomError,
+		},
+		{
+			name: "manifest query error",
+			mockSetup: func() {
+				dbMock.ExpectBegin()
+
+				expectCandidates()
+
+				dbMock.ExpectQuery("UPDATE receptions r SET status = \\$1, auto_closed = TRUE WHERE r.id = ANY").
+					WithArgs(string(pvzapi.Close), []uuid.UUID{receptionID}, string(pvzapi.InProgress), idleSince).
+					WillReturnRows(pgxmock.NewRows(columns).
+						AddRow(receptionID, now, pvzID, string(pvzapi.Close), &openerID, true))
+
+				dbMock.ExpectExec("UPDATE products SET status = \\$2 WHERE reception_id = \\$1").
+					WithArgs(receptionID, string(pvzapi.Stored), string(pvzapi.Received)).
+					WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+
+				dbMock.ExpectQuery("SELECT type, expected_count FROM reception_manifest").
+					WithArgs(receptionID).
+					WillReturnError(ErrRandomError)
+
+				dbMock.ExpectRollback()
+			},
+			expected:      nil,
+			expectedError: ErrRandomError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockSetup()
+
+			result, err := repo.CloseStaleReceptions(context.Background(), idleSince)
+
+			if tt.expectedError != nil {
+				assert.ErrorIs(t, err, tt.expectedError)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, tt.expected, result)
+			assert.NoError(t, dbMock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestPVZRepo_ReopenReception(t *testing.T) {
+	dbMock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer dbMock.Close()
+
+	repo := NewPVZRepo(dbMock)
+
+	pvzID := uuid.New()
+	receptionID := uuid.New()
+	openerID := uuid.New()
+	userID := uuid.New()
+	reason := "closed by mistake"
+	now := time.Now()
+
+	statusColumns := []string{"status", "status"}
+	columns := []string{"id", "date_time", "pvz_id", "status", "created_by"}
+
+	expectProductsLeft := func(left bool) {
+		dbMock.ExpectQuery("SELECT EXISTS .*FROM products WHERE reception_id = \\$1 AND status <> \\$2").
+			WithArgs(receptionID, string(pvzapi.Stored)).
+			WillReturnRows(pgxmock.NewRows([]string{"exists"}).AddRow(left))
+	}
+
+	tests := []struct {
+		name          string
+		mockSetup     func()
+		expected      *models.Reception
+		expectedError error
+	}{
+		{
+			name: "success",
+			mockSetup: func() {
+				dbMock.ExpectBegin()
+
+				dbMock.ExpectQuery("SELECT r.status, p.status FROM receptions r.*FOR UPDATE OF r").
+					WithArgs(receptionID).
+					WillReturnRows(pgxmock.NewRows(statusColumns).AddRow(string(pvzapi.Close), string(pvzapi.Active)))
+
+				expectProductsLeft(false)
+
+				dbMock.ExpectQuery("UPDATE receptions r SET status =.*RETURNING").
+					WithArgs(receptionID, string(pvzapi.InProgress)).
+					WillReturnRows(pgxmock.NewRows(columns).AddRow(receptionID, now, pvzID, string(pvzapi.InProgress), &openerID))
+
+				dbMock.ExpectExec("UPDATE products SET status = \\$2 WHERE reception_id = \\$1").
+					WithArgs(receptionID, string(pvzapi.Received)).
+					WillReturnResult(pgxmock.NewResult("UPDATE", 2))
+
+				dbMock.ExpectExec("DELETE FROM reception_discrepancies WHERE reception_id = \\$1").
+					WithArgs(receptionID).
+					WillReturnResult(pgxmock.NewResult("DELETE", 1))
+
+				dbMock.ExpectExec("DELETE FROM reception_barcode_discrepancies WHERE reception_id = \\$1").
+					WithArgs(receptionID).
+					WillReturnResult(pgxmock.NewResult("DELETE", 0))
+
+				dbMock.ExpectExec("INSERT INTO reception_audit").
+					WithArgs(receptionID, string(pvzapi.Reopen), reason, &userID).
+					WillReturnResult(pgxmock.NewResult("INSERT", 1))
+
+				dbMock.ExpectQuery("SELECT type, expected_count FROM reception_manifest").
+					WithArgs(receptionID).
+					WillReturnRows(pgxmock.NewRows([]string{"type", "expected_count"}).AddRow("обувь", 2))
+
+				dbMock.ExpectQuery("SELECT barcode, type FROM reception_manifest_barcodes").
+					WithArgs(receptionID).
+					WillReturnRows(pgxmock.NewRows([]string{"barcode", "type"}))
+
+				dbMock.ExpectCommit()
+			},
+			expected: &models.Reception{
+				ID:        receptionID,
+				DateTime:  now,
+				PvzID:     pvzID,
+				Status:    string(pvzapi.InProgress),
+				CreatedBy: &openerID,
+				Manifest:  []models.ManifestItem{{Type: "обувь", Count: 2}},
+			},
+			expectedError: nil,
+		},
+		{
+			name: "reception not found",
+			mockSetup: func() {
+				dbMock.ExpectBegin()
+
+				dbMock.ExpectQuery("SELECT r.status, p.status FROM receptions r.*FOR UPDATE OF r").
+					WithArgs(receptionID).
+					WillReturnError(pgx.ErrNoRows)
+
+				dbMock.ExpectRollback()
+			},
+			expected:      nil,
+			expectedError: db.ErrReceptionNotFound,
+		},
+		{
+			name: "reception not closed",
+			mockSetup: func() {
+				dbMock.ExpectBegin()
+
+				dbMock.ExpectQuery("SELECT r.status, p.status FROM receptions r.*FOR UPDATE OF r").
+					WithArgs(receptionID).
+					WillReturnRows(pgxmock.NewRows(statusColumns).AddRow(string(pvzapi.Cancelled), string(pvzapi.Active)))
+
+				dbMock.ExpectRollback()
+			},
+			expected:      nil,
+			expectedError: db.ErrReceptionNotClosed,
+		},
+		{
+			name: "pvz not active",
+			mockSetup: func() {
+				dbMock.ExpectBegin()
+
+				dbMock.ExpectQuery("SELECT r.status, p.status FROM receptions r.*FOR UPDATE OF r").
+					WithArgs(receptionID).
+					WillReturnRows(pgxmock.NewRows(statusColumns).AddRow(string(pvzapi.Close), string(pvzapi.Suspended)))
+
+				dbMock.ExpectRollback()
+			},
+			expected:      nil,
+			expectedError: db.ErrPVZNotActive,
+		},
+		{
+			name: "products already left the pvz",
+			mockSetup: func() {
+				dbMock.ExpectBegin()
+
+				dbMock.ExpectQuery("SELECT r.status, p.status FROM receptions r.*FOR UPDATE OF r").
+					WithArgs(receptionID).
+					WillReturnRows(pgxmock.NewRows(statusColumns).AddRow(string(pvzapi.Close), string(pvzapi.Active)))
+
+				expectProductsLeft(true)
+
+				dbMock.ExpectRollback()
+			},
+			expected:      nil,
+			expectedError: db.ErrProductsLeftPVZ,
+		},
+		{
+			name: "another reception is open",
+			mockSetup: func() {
+				dbMock.ExpectBegin()
+
+				dbMock.ExpectQuery("SELECT r.status, p.status FROM receptions r.*FOR UPDATE OF r").
+					WithArgs(receptionID).
+					WillReturnRows(pgxmock.NewRows(statusColumns).AddRow(string(pvzapi.Close), string(pvzapi.Active)))
+
+				expectProductsLeft(false)
+
+				dbMock.ExpectQuery("UPDATE receptions r SET status =.*RETURNING").
+					WithArgs(receptionID, string(pvzapi.InProgress)).
+					WillReturnError(pgx.ErrNoRows)
+
+				dbMock.ExpectRollback()
+			},
+			expected:      nil,
+			expectedError: db.ErrReceptionConflict,
+		},
+		{
+			name: "audit insert error",
+			mockSetup: func() {
+				dbMock.ExpectBegin()
+
+				dbMock.ExpectQuery("SELECT r.status, p.status FROM receptions r.*FOR UPDATE OF r").
+					WithArgs(receptionID).
+					WillReturnRows(pgxmock.NewRows(statusColumns).AddRow(string(pvzapi.Close), string(pvzapi.Active)))
+
+				expectProductsLeft(false)
+
+				dbMock.ExpectQuery("UPDATE receptions r SET status =.*RETURNING").
+					WithArgs(receptionID, string(pvzapi.InProgress)).
+					WillReturnRows(pgxmock.NewRows(columns).AddRow(receptionID, now, pvzID, string(pvzapi.InProgress), &openerID))
+
+				dbMock.ExpectExec("UPDATE products SET status = \\$2 WHERE reception_id = \\$1").
+					WithArgs(receptionID, string(pvzapi.Received)).
+					WillReturnResult(pgxmock.NewResult("UPDATE", 2))
+
+				dbMock.ExpectExec("DELETE FROM reception_discrepancies WHERE reception_id = \\$1").
+					WithArgs(receptionID).
+					WillReturnResult(pgxmock.NewResult("DELETE", 0))
+
+				dbMock.ExpectExec("DELETE FROM reception_barcode_discrepancies WHERE reception_id = \\$1").
+					WithArgs(receptionID).
+					WillReturnResult(pgxmock.NewResult("DELETE", 0))
+
+				dbMock.ExpectExec("INSERT INTO reception_audit").
+					WithArgs(receptionID, string(pvzapi.Reopen), reason, &userID).
+					WillReturnError(ErrRandomError)
+
+				dbMock.ExpectRollback()
+			},
+			expected:      nil,
+			expectedError: ErrRandomError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockSetup()
+
+			result, err := repo.ReopenReception(context.Background(), receptionID, userID, reason)
+
+			if tt.expectedError != nil {
+				assert.ErrorIs(t, err, tt.expectedError)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, tt.expected, result)
+		})
+	}
+}
+
+func TestPVZRepo_GetPVZs(t *testing.T) {
+	dbMock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer dbMock.Close()
+
+	repo := &pvzRepo{db: dbMock}
+
+	startDate := time.Now().Add(2 * time.Hour)
+	endDate := startDate.Add(-2 * time.Hour)
+	var limit uint64 = 10
+	var offset uint64 = 0
+
+	pvzID := uuid.New()
+	receptionID := uuid.New()
+	productID := uuid.New()
+
+	regDate := time.Now().AddDate(-1, 0, 0)
+	recDate := time.Now().Add(-2 * time.Hour)
+	prodDate := time.Now().Add(-1 * time.Hour)
+
+	status := "in_progress"
+	productType := "обувь"
+	userID := uuid.New()
+	autoClosed := false
+	lineNumber := 1
+
+	productStatus := string(pvzapi.Stored)
+
+	tests := []struct {
+		name             string
+		excludeCancelled bool
+		productStatus    *string
+		productCounts    bool
+		mockSetup        func()
+		expected         []*models.PVZWithReceptions
+		expectedError    error
+	}{
+		{
+			name: "success",
+			mockSetup: func() {
+				dbMock.ExpectQuery(regexp.QuoteMeta(`
+					SELECT DISTINCT p.id FROM pvzs p 
+					LEFT JOIN receptions r ON r.pvz_id = p.id 
+					WHERE (r.date_time >= $1 AND r.date_time <= $2) 
+					LIMIT 10 OFFSET 0
+				`)).
+					WithArgs(startDate, endDate).
+					WillReturnRows(pgxmock.NewRows([]string{"id"}).AddRow(pvzID))
+
+				dbMock.ExpectQuery(regexp.QuoteMeta(`
+					SELECT p.id, p.city, p.registration_date, p.status, 
+						   r.id, r.date_time, r.status, r.created_by, r.closed_by, r.auto_closed, 
+						   pr.id, pr.type, pr.line_number, pr.date_time, pr.created_by, pr.barcode, pr.status 
+					FROM pvzs p 
+					LEFT JOIN receptions r ON r.pvz_id = p.id 
+					LEFT JOIN products pr ON pr.reception_id = r.id 
+					WHERE p.id IN ($1) AND (r.date_time >= $2 AND r.date_time <= $3) 
+					ORDER BY r.date_time DESC, r.id, pr.line_number
+				`)).
+					WithArgs(pvzID, startDate, endDate).
+					WillReturnRows(pgxmock.NewRows([]string{
+						"p.id", "p.city", "p.registration_date", "p.status",
+						"r.id", "r.date_time", "r.status", "r.created_by", "r.closed_by", "r.auto_closed",
+						"pr.id", "pr.type", "pr.line_number", "pr.date_time", "pr.created_by", "pr.barcode", "pr.status",
+					}).AddRow(
+						pvzID, "Москва", regDate, "active",
+						&receptionID, &recDate, &status, &userID, nil, &autoClosed,
+						&productID, &productType, &lineNumber, &prodDate, &userID, nil, &productStatus,
+					))
+			},
+			expected: []*models.PVZWithReceptions{
+				{
+					PVZ: models.PVZ{
+						ID:               pvzID,
+						City:             "Москва",
+						RegistrationDate: regDate,
+						Status:           "active",
+					},
+					Receptions: []*models.ReceptionWithProducts{
+						{
+							Reception: models.Reception{
+								ID:        receptionID,
+								DateTime:  recDate,
+								Status:    status,
+								PvzID:     pvzID,
+								CreatedBy: &userID,
+							},
+							Products: []*models.Product{
+								{
+									ID:          productID,
+									Type:        productType,
+									DateTime:    prodDate,
+									ReceptionID: receptionID,
+									LineNumber:  1,
+									CreatedBy:   &userID,
+									Status:      productStatus,
+								},
+							},
+						},
+					},
+				},
+			},
+			expectedError: nil,
+		},
+		{
+			name:          "filter by product status",
+			productStatus: &productStatus,
+			mockSetup: func() {
+				dbMock.ExpectQuery(regexp.QuoteMeta(`
+					SELECT DISTINCT p.id FROM pvzs p 
+					JOIN receptions r ON r.pvz_id = p.id 
+					JOIN products pr ON pr.reception_id = r.id AND pr.status = $1 
+					WHERE (r.date_time >= $2 AND r.date_time <= $3) 
+					LIMIT 10 OFFSET 0
+				`)).
+					WithArgs(productStatus, startDate, endDate).
+					WillReturnRows(pgxmock.NewRows([]string{"id"}).AddRow(pvzID))
+
+				dbMock.ExpectQuery(regexp.QuoteMeta(`
+					SELECT p.id, p.city, p.registration_date, p.status, 
+						   r.id, r.date_time, r.status, r.created_by, r.closed_by, r.auto_closed, 
+						   pr.id, pr.type, pr.line_number, pr.date_time, pr.created_by, pr.barcode, pr.status 
+					FROM pvzs p 
+					JOIN receptions r ON r.pvz_id = p.id 
+					JOIN products pr ON pr.reception_id = r.id AND pr.status = $1 
+					WHERE p.id IN ($2) AND (r.date_time >= $3 AND r.date_time <= $4) 
+					ORDER BY r.date_time DESC, r.id, pr.line_number
+				`)).
+					WithArgs(productStatus, pvzID, startDate, endDate).
+					WillReturnRows(pgxmock.NewRows([]string{
+						"p.id", "p.city", "p.registration_date", "p.status",
+						"r.id", "r.date_time", "r.status", "r.created_by", "r.closed_by", "r.auto_closed",
+						"pr.id", "pr.type", "pr.line_number", "pr.date_time", "pr.created_by", "pr.barcode", "pr.status",
+					}).AddRow(
+						pvzID, "Москва", regDate, "active",
+						&receptionID, &recDate, &status, &userID, nil, &autoClosed,
+						&productID, &productType, &lineNumber, &prodDate, &userID, nil, &productStatus,
+					))
+			},
+			expected: []*models.PVZWithReceptions{
+				{
+					PVZ: models.PVZ{
+						ID:               pvzID,
+						City:             "Москва",
+						RegistrationDate: regDate,
+						Status:           "active",
+					},
+					Receptions: []*models.ReceptionWithProducts{
+						{
+							Reception: models.Reception{
+								ID:        receptionID,
+								DateTime:  recDate,
+								Status:    status,
+								PvzID:     pvzID,
+								CreatedBy: &userID,
+							},
+							Products: []*models.Product{
+								{
+									ID:          productID,
+									Type:        productType,
+									DateTime:    prodDate,
+									ReceptionID: receptionID,
+									LineNumber:  1,
+									CreatedBy:   &userID,
+									Status:      productStatus,
+								},
+							},
+						},
+					},
+				},
+			},
+			expectedError: nil,
+		},
+		{
+			name:          "no products in the status",
+			productStatus: &productStatus,
+			mockSetup: func() {
+				dbMock.ExpectQuery(regexp.QuoteMeta(`
+					SELECT DISTINCT p.id FROM pvzs p 
+					JOIN receptions r ON r.pvz_id = p.id 
+					JOIN products pr ON pr.reception_id = r.id AND pr.status = $1 
+					WHERE (r.date_time >= $2 AND r.date_time <= $3) 
+					LIMIT 10 OFFSET 0
+				`)).
+					WithArgs(productStatus, startDate, endDate).
+					WillReturnRows(pgxmock.NewRows([]string{"id"}))
+			},
+			expected:      []*models.PVZWithReceptions{},
+			expectedError: nil,
+		},
+		{
+			name:          "product counts instead of products",
+			productCounts: true,
+			mockSetup: func() {
+				dbMock.ExpectQuery(regexp.QuoteMeta(`
+					SELECT DISTINCT p.id FROM pvzs p 
+					LEFT JOIN receptions r ON r.pvz_id = p.id 
+					WHERE (r.date_time >= $1 AND r.date_time <= $2) 
+					LIMIT 10 OFFSET 0
+				`)).
+					WithArgs(startDate, endDate).
+					WillReturnRows(pgxmock.NewRows([]string{"id"}).AddRow(pvzID))
+
+				dbMock.ExpectQuery(regexp.QuoteMeta(`
+					SELECT p.id, p.city, p.registration_date, p.status, 
+						   r.id, r.date_time, r.status, r.created_by, r.closed_by, r.auto_closed, 
+						   COUNT(pr.id) 
+					FROM pvzs p 
+					LEFT JOIN receptions r ON r.pvz_id = p.id 
+					LEFT JOIN products pr ON pr.reception_id = r.id 
+					WHERE p.id IN ($1) AND (r.date_time >= $2 AND r.date_time <= $3) 
+					GROUP BY p.id, r.id 
+					ORDER BY r.date_time DESC, r.id
+				`)).
+					WithArgs(pvzID, startDate, endDate).
+					WillReturnRows(pgxmock.NewRows([]string{
+						"p.id", "p.city", "p.registration_date", "p.status",
+						"r.id", "r.date_time", "r.status", "r.created_by", "r.closed_by", "r.auto_closed",
+						"count",
+					}).AddRow(
+						pvzID, "Москва", regDate, "active",
+						&receptionID, &recDate, &status, &userID, nil, &autoClosed,
+						42,
+					))
+			},
+			expected: []*models.PVZWithReceptions{
+				{
+					PVZ: models.PVZ{
+						ID:               pvzID,
+						City:             "Москва",
+						RegistrationDate: regDate,
+						Status:           "active",
+					},
+					Receptions: []*models.ReceptionWithProducts{
+						{
+							Reception: models.Reception{
+								ID:        receptionID,
+								DateTime:  recDate,
+								Status:    status,
+								PvzID:     pvzID,
+								CreatedBy: &userID,
+							},
+							ProductsCount: 42,
+						},
+					},
+				},
+			},
+			expectedError: nil,
+		},
+		{
+			name:             "exclude cancelled receptions",
+			excludeCancelled: true,
+			mockSetup: func() {
+				dbMock.ExpectQuery(regexp.QuoteMeta(`
+					SELECT DISTINCT p.id FROM pvzs p 
+					LEFT JOIN receptions r ON r.pvz_id = p.id AND r.status <> $1 
+					WHERE (r.date_time >= $2 AND r.date_time <= $3) 
+					LIMIT 10 OFFSET 0
+				`)).
+					WithArgs(string(pvzapi.Cancelled), startDate, endDate).
+					WillReturnRows(pgxmock.NewRows([]string{"id"}))
+			},
+			expected:      []*models.PVZWithReceptions{},
+			expectedError: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockSetup()
+
+			ctx := context.Background()
+			result, err := repo.GetPVZs(ctx, &startDate, &endDate, tt.excludeCancelled, tt.productStatus, tt.productCounts, limit, offset)
+
+			if tt.expectedError != nil {
+				assert.ErrorIs(t, err, tt.expectedError)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.expected, result)
+			}
+		})
+	}
+}
+
+func TestPVZRepo_GetPVZList(t *testing.T) {
+	dbMock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer dbMock.Close()
+
+	repo := NewPVZRepo(dbMock)
+
+	testPVZs := []models.PVZ{
+		{
+			ID:               uuid.New(),
+			City:             "Москва",
+			RegistrationDate: time.Now(),
+			Status:           "active",
+		},
+		{
+			ID:               uuid.New(),
+			City:             "Казань",
+			RegistrationDate: time.Now().Add(-24 * time.Hour),
+			Status:           "closed",
+		},
+	}
+
+	tests := []struct {
+		name           string
+		mockSetup      func()
+		expectedResult []models.PVZ
+		expectedError  error
+	}{
+		{
+			name: "successful get list",
+			mockSetup: func() {
+				rows := pgxmock.NewRows([]string{"id", "city", "registration_date", "status"}).
+					AddRow(testPVZs[0].ID, testPVZs[0].City, testPVZs[0].RegistrationDate, testPVZs[0].Status).
+					AddRow(testPVZs[1].ID, testPVZs[1].City, testPVZs[1].RegistrationDate, testPVZs[1].Status)
+				dbMock.ExpectQuery("SELECT id, city, registration_date, status FROM pvzs").
+					WillReturnRows(rows)
+			},
+			expectedResult: testPVZs,
+			expectedError:  nil,
+		},
+		{
+			name: "empty list",
+			mockSetup: func() {
+				rows := pgxmock.NewRows([]string{"id", "city", "registration_date", "status"})
+				dbMock.ExpectQuery("SELECT id, city, registration_date, status FROM pvzs").
+					WillReturnRows(rows)
+			},
+			expectedResult: []models.PVZ(nil),
+			expectedError:  nil,
+		},
+		{
+			name: "database error",
+			mockSetup: func() {
+				dbMock.ExpectQuery("SELECT id, city, registration_date, status FROM pvzs").
+					WillReturnError(ErrRandomError)
+			},
+			expectedResult: nil,
+			expectedError:  ErrRandomError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockSetup()
+
+			result, err := repo.GetPVZList(context.Background())
+
+			if tt.expectedError != nil {
+				assert.Error(t, err)
+				assert.ErrorIs(t, err, tt.expectedError)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.expectedResult, result)
+			}
+		})
+	}
+}
+
+func TestPVZRepo_GetCities(t *testing.T) {
+	dbMock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer dbMock.Close()
+
+	repo := NewPVZRepo(dbMock)
+
+	testCities := []models.City{
+		{ID: uuid.New(), Name: "Казань", CreatedAt: time.Now()},
+		{ID: uuid.New(), Name: "Москва", CreatedAt: time.Now()},
+	}
+
+	tests := []struct {
+		name          string
+		mockSetup     func()
+		expected      []models.City
+		expectedError error
+	}{
+		{
+			name: "successful get list",
+			mockSetup: func() {
+				rows := pgxmock.NewRows([]string{"id", "name", "created_at"}).
+					AddRow(testCities[0].ID, testCities[0].Name, testCities[0].CreatedAt).
+					AddRow(testCities[1].ID, testCities[1].Name, testCities[1].CreatedAt)
+				dbMock.ExpectQuery("SELECT id, name, created_at FROM cities ORDER BY name").
+					WillReturnRows(rows)
+			},
+			expected:      testCities,
+			expectedError: nil,
+		},
+		{
+			name: "database error",
+			mockSetup: func() {
+				dbMock.ExpectQuery("SELECT id, name, created_at FROM cities ORDER BY name").
+					WillReturnError(ErrRandomError)
+			},
+			expected:      nil,
+			expectedError: ErrRandomError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockSetup()
+
+			result, err := repo.GetCities(context.Background())
+
+			if tt.expectedError != nil {
+				assert.ErrorIs(t, err, tt.expectedError)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.expected, result)
+			}
+		})
+	}
+}
+
+func TestPVZRepo_CreateCity(t *testing.T) {
+	dbMock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer dbMock.Close()
+
+	repo := NewPVZRepo(dbMock)
+
+	testCity := models.City{
+		ID:        uuid.New(),
+		Name:      "Астана",
+		CreatedAt: time.Now(),
+	}
+
+	tests := []struct {
+		name          string
+		mockSetup     func()
+		expected      *models.City
+		expectedError error
+	}{
+		{
+			name: "successful creation",
+			mockSetup: func() {
+				rows := pgxmock.NewRows([]string{"id", "name", "created_at"}).
+					AddRow(testCity.ID, testCity.Name, testCity.CreatedAt)
+				dbMock.ExpectQuery("INSERT INTO cities.*RETURNING id, name, created_at").
+					WithArgs(testCity.ID, testCity.Name).
+					WillReturnRows(rows)
+			},
+			expected:      &testCity,
+			expectedError: nil,
+		},
+		{
+			name: "duplicate city",
+			mockSetup: func() {
+				dbMock.ExpectQuery("INSERT INTO cities.*RETURNING id, name, created_at").
+					WithArgs(testCity.ID, testCity.Name).
+					WillReturnError(pgx.ErrNoRows)
+			},
+			expected:      nil,
+			expectedError: db.ErrDuplicateCity,
+		},
+		{
+			name: "query error",
+			mockSetup: func() {
+				dbMock.ExpectQuery("INSERT INTO cities.*RETURNING id, name, created_at").
+					WithArgs(testCity.ID, testCity.Name).
+					WillReturnError(ErrRandomError)
+			},
+			expected:      nil,
+			expectedError: ErrRandomError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockSetup()
+
+			result, err := repo.CreateCity(context.Background(), models.City{ID: testCity.ID, Name: testCity.Name})
+
+			if tt.expectedError != nil {
+				assert.ErrorIs(t, err, tt.expectedError)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, tt.expected, result)
+		})
+	}
+}
+
+func TestPVZRepo_UpdateCity(t *testing.T) {
+	dbMock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer dbMock.Close()
+
+	repo := NewPVZRepo(dbMock)
+
+	testCity := models.City{
+		ID:        uuid.New(),
+		Name:      "Нур-Султан",
+		CreatedAt: time.Now(),
+	}
+
+	tests := []struct {
+		name          string
+		mockSetup     func()
+		expected      *models.City
+		expectedError error
+	}{
+		{
+			name: "successful rename",
+			mockSetup: func() {
+				rows := pgxmock.NewRows([]string{"id", "name", "created_at"}).
+					AddRow(testCity.ID, testCity.Name, testCity.CreatedAt)
+				dbMock.ExpectQuery("UPDATE cities.*RETURNING id, name, created_at").
+					WithArgs(testCity.ID, testCity.Name).
+					WillReturnRows(rows)
+			},
+			expected:      &testCity,
+			expectedError: nil,
+		},
+		{
+			name: "city not found",
+			mockSetup: func() {
+				dbMock.ExpectQuery("UPDATE cities.*RETURNING id, name, created_at").
+					WithArgs(testCity.ID, testCity.Name).
+					WillReturnError(pgx.ErrNoRows)
+			},
+			expected:      nil,
+			expectedError: db.ErrCityNotFound,
+		},
+		{
+			name: "duplicate name",
+			mockSetup: func() {
+				dbMock.ExpectQuery("UPDATE cities.*RETURNING id, name, created_at").
+					WithArgs(testCity.ID, testCity.Name).
+					WillReturnError(&pgconn.PgError{Code: "23505"})
+			},
+			expected:      nil,
+			expectedError: db.ErrDuplicateCity,
+		},
+		{
+			name: "query error",
+			mockSetup: func() {
+				dbMock.ExpectQuery("UPDATE cities.*RETURNING id, name, created_at").
+					WithArgs(testCity.ID, testCity.Name).
+					WillReturnError(ErrRandomError)
+			},
+			expected:      nil,
+			expectedError: ErrRandomError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockSetup()
+
+			result, err := repo.UpdateCity(context.Background(), testCity.ID, testCity.Name)
+
+			if tt.expectedError != nil {
+				assert.ErrorIs(t, err, tt.expectedError)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, tt.expected, result)
+		})
+	}
+}
+
+func TestPVZRepo_DeleteCity(t *testing.T) {
+	dbMock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer dbMock.Close()
+
+	repo := NewPVZRepo(dbMock)
+
+	cityID := uuid.New()
+
+	tests := []struct {
+		name          string
+		mockSetup     func()
+		expectedError error
+	}{
+		{
+			name: "successful deletion",
+			mockSetup: func() {
+				dbMock.ExpectQuery("DELETE FROM cities.*RETURNING id").
+					WithArgs(cityID).
+					WillReturnRows(pgxmock.NewRows([]string{"id"}).AddRow(cityID))
+			},
+			expectedError: nil,
+		},
+		{
+			name: "city not found",
+			mockSetup: func() {
+				dbMock.ExpectQuery("DELETE FROM cities.*RETURNING id").
+					WithArgs(cityID).
+					WillReturnError(pgx.ErrNoRows)
+			},
+			expectedError: db.ErrCityNotFound,
+		},
+		{
+			name: "city in use",
+			mockSetup: func() {
+				dbMock.ExpectQuery("DELETE FROM cities.*RETURNING id").
+					WithArgs(cityID).
+					WillReturnError(&pgconn.PgError{Code: "23503"})
+			},
+			expectedError: db.ErrCityInUse,
+		},
+		{
+			name: "query error",
+			mockSetup: func() {
+				dbMock.ExpectQuery("DELETE FROM cities.*RETURNING id").
+					WithArgs(cityID).
+					WillReturnError(ErrRandomError)
+			},
+			expectedError: ErrRandomError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockSetup()
+
+			err := repo.DeleteCity(context.Background(), cityID)
+
+			if tt.expectedError != nil {
+				assert.ErrorIs(t, err, tt.expectedError)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestPVZRepo_GetProductTypes(t *testing.T) {
+	dbMock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer dbMock.Close()
+
+	repo := NewPVZRepo(dbMock)
+
+	retiredAt := time.Now()
+	testTypes := []models.ProductType{
+		{ID: uuid.New(), Name: "обувь", DisplayName: "Обувь"},
+		{ID: uuid.New(), Name: "посуда", DisplayName: "Посуда", RetiredAt: &retiredAt},
+	}
+
+	tests := []struct {
+		name          string
+		mockSetup     func()
+		expected      []models.ProductType
+		expectedError error
+	}{
+		{
+			name: "successful get list",
+			mockSetup: func() {
+				rows := pgxmock.NewRows([]string{"id", "name", "display_name", "retired_at"}).
+					AddRow(testTypes[0].ID, testTypes[0].Name, testTypes[0].DisplayName, testTypes[0].RetiredAt).
+					AddRow(testTypes[1].ID, testTypes[1].Name, testTypes[1].DisplayName, testTypes[1].RetiredAt)
+				dbMock.ExpectQuery("SELECT id, name, display_name, retired_at FROM product_types ORDER BY name").
+					WillReturnRows(rows)
+			},
+			expected:      testTypes,
+			expectedError: nil,
+		},
+		{
+			name: "database error",
+			mockSetup: func() {
+				dbMock.ExpectQuery("SELECT id, name, display_name, retired_at FROM product_types ORDER BY name").
+					WillReturnError(ErrRandomError)
+			},
+			expected:      nil,
+			expectedError: ErrRandomError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockSetup()
+
+			result, err := repo.GetProductTypes(context.Background())
+
+			if tt.expectedError != nil {
+				assert.ErrorIs(t, err, tt.expectedError)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.expected, result)
+			}
+		})
+	}
+}
+
+func TestPVZRepo_CreateProductType(t *testing.T) {
+	dbMock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer dbMock.Close()
+
+	repo := NewPVZRepo(dbMock)
+
+	testType := models.ProductType{
+		ID:          uuid.New(),
+		Name:        "бытовая техника",
+		DisplayName: "Бытовая техника",
+	}
+
+	tests := []struct {
+		name          string
+		mockSetup     func()
+		expected      *models.ProductType
+		expectedError error
+	}{
+		{
+			name: "successful creation",
+			mockSetup: func() {
+				rows := pgxmock.NewRows([]string{"id", "name", "display_name", "retired_at"}).
+					AddRow(testType.ID, testType.Name, testType.DisplayName, testType.RetiredAt)
+				dbMock.ExpectQuery("INSERT INTO product_types.*ON CONFLICT.*RETURNING id, name, display_name, retired_at").
+					WithArgs(testType.ID, testType.Name, testType.DisplayName).
+					WillReturnRows(rows)
+			},
+			expected:      &testType,
+			expectedError: nil,
+		},
+		{
+			name: "duplicate type",
+			mockSetup: func() {
+				dbMock.ExpectQuery("INSERT INTO product_types.*ON CONFLICT.*RETURNING id, name, display_name, retired_at").
+					WithArgs(testType.ID, testType.Name, testType.DisplayName).
+					WillReturnError(pgx.ErrNoRows)
+			},
+			expected:      nil,
+			expectedError: db.ErrDuplicateType,
+		},
+		{
+			name: "query error",
+			mockSetup: func() {
+				dbMock.ExpectQuery("INSERT INTO product_types.*ON CONFLICT.*RETURNING id, name, display_name, retired_at").
+					WithArgs(testType.ID, testType.Name, testType.DisplayName).
+					WillReturnError(ErrRandomError)
+			},
+			expected:      nil,
+			expectedError: ErrRandomError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockSetup()
+
+			result, err := repo.CreateProductType(context.Background(), testType)
+
+			if tt.expectedError != nil {
+				assert.ErrorIs(t, err, tt.expectedError)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, tt.expected, result)
+		})
+	}
+}
+
+func TestPVZRepo_RetireProductType(t *testing.T) {
+	dbMock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer dbMock.Close()
+
+	repo := NewPVZRepo(dbMock)
+
+	typeID := uuid.New()
+
+	tests := []struct {
+		name          string
+		mockSetup     func()
+		expectedError error
+	}{
+		{
+			name: "successful retirement",
+			mockSetup: func() {
+				rows := pgxmock.NewRows([]string{"id"}).AddRow(typeID)
+				dbMock.ExpectQuery("UPDATE product_types SET retired_at").
+					WithArgs(typeID).
+					WillReturnRows(rows)
+			},
+			expectedError: nil,
+		},
+		{
+			name: "type not found",
+			mockSetup: func() {
+				dbMock.ExpectQuery("UPDATE product_types SET retired_at").
+					WithArgs(typeID).
+					WillReturnError(pgx.ErrNoRows)
+			},
+			expectedError: db.ErrTypeNotFound,
+		},
+		{
+			name: "query error",
+			mockSetup: func() {
+				dbMock.ExpectQuery("UPDATE product_types SET retired_at").
+					WithArgs(typeID).
+					WillReturnError(ErrRandomError)
+			},
+			expectedError: ErrRandomError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockSetup()
+
+			err := repo.RetireProductType(context.Background(), typeID)
+
+			if tt.expectedError != nil {
+				assert.ErrorIs(t, err, tt.expectedError)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestPVZRepo_GetPVZ(t *testing.T) {
+	dbMock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer dbMock.Close()
+
+	repo := NewPVZRepo(dbMock)
+
+	pvzID := uuid.New()
+	receptionID := uuid.New()
+	receptionStatus := string(pvzapi.InProgress)
+	userID := uuid.New()
+	now := time.Now()
+
+	columns := []string{
+		"id", "city", "registration_date", "status",
+		"id", "date_time", "status", "created_by",
+		"count", "count", "count",
+	}
+
+	otherPvzID := uuid.New()
+	inboundID, outboundID := uuid.New(), uuid.New()
+	inboundProductID, outboundProductID := uuid.New(), uuid.New()
+
+	transferColumns := []string{
+		"id", "product_id", "type", "barcode", "from_pvz_id", "to_pvz_id",
+		"reception_id", "delivered_product_id", "created_by", "created_at",
+	}
+
+	tests := []struct {
+		name          string
+		mockSetup     func()
+		expected      *models.PVZDetails
+		expectedError error
+	}{
+		{
+			name: "pvz with open reception",
+			mockSetup: func() {
+				rows := pgxmock.NewRows(columns).
+					AddRow(pvzID, "Москва", now, string(pvzapi.Active),
+						&receptionID, &now, &receptionStatus, &userID,
+						3, 2, 10)
+				dbMock.ExpectQuery("SELECT p.id, p.city, p.registration_date, p.status.*FROM pvzs p").
+					WithArgs(pvzID, string(pvzapi.InProgress)).
+					WillReturnRows(rows)
+
+				transfers := pgxmock.NewRows(transferColumns).
+					AddRow(inboundID, inboundProductID, "обувь", nil, otherPvzID, pvzID, &receptionID, nil, &userID, now).
+					AddRow(outboundID, outboundProductID, "одежда", nil, pvzID, otherPvzID, nil, nil, &userID, now)
+				dbMock.ExpectQuery("SELECT t.id, t.product_id, pr.type.*FROM product_transfers t").
+					WithArgs(pvzID).
+					WillReturnRows(transfers)
+			},
+			expected: &models.PVZDetails{
+				PVZ: models.PVZ{
+					ID:               pvzID,
+					City:             "Москва",
+					RegistrationDate: now,
+					Status:           string(pvzapi.Active),
+				},
+				OpenReception: &models.Reception{
+					ID:        receptionID,
+					DateTime:  now,
+					PvzID:     pvzID,
+					Status:    string(pvzapi.InProgress),
+					CreatedBy: &userID,
+				},
+				OpenReceptionProductsCount: 3,
+				ReceptionsCount:            2,
+				ProductsCount:              10,
+				InboundTransfers: []models.ProductTransfer{
+					{
+						ID:          inboundID,
+						ProductID:   inboundProductID,
+						Type:        "обувь",
+						FromPvzID:   otherPvzID,
+						ToPvzID:     pvzID,
+						Status:      string(pvzapi.InTransit),
+						ReceptionID: &receptionID,
+						CreatedBy:   &userID,
+						CreatedAt:   now,
+					},
+				},
+				OutboundTransfers: []models.ProductTransfer{
+					{
+						ID:        outboundID,
+						ProductID: outboundProductID,
+						Type:      "одежда",
+						FromPvzID: pvzID,
+						ToPvzID:   otherPvzID,
+						Status:    string(pvzapi.InTransit),
+						CreatedBy: &userID,
+						CreatedAt: now,
+					},
+				},
+			},
+			expectedError: nil,
+		},
+		{
+			name: "pvz without open reception",
+			mockSetup: func() {
+				rows := pgxmock.NewRows(columns).
+					AddRow(pvzID, "Москва", now, string(pvzapi.Active),
+						nil, nil, nil, nil,
+						0, 1, 5)
+				dbMock.ExpectQuery("SELECT p.id, p.city, p.registration_date, p.status.*FROM pvzs p").
+					WithArgs(pvzID, string(pvzapi.InProgress)).
+					WillReturnRows(rows)
+
+				dbMock.ExpectQuery("SELECT t.id, t.product_id, pr.type.*FROM product_transfers t").
+					WithArgs(pvzID).
+					WillReturnRows(pgxmock.NewRows(transferColumns))
+			},
+			expected: &models.PVZDetails{
+				PVZ: models.PVZ{
+					ID:               pvzID,
+					City:             "Москва",
+					RegistrationDate: now,
+					Status:           string(pvzapi.Active),
+				},
+				ReceptionsCount: 1,
+				ProductsCount:   5,
+			},
+			expectedError: nil,
+		},
+		{
+			name: "pvz not found",
+			mockSetup: func() {
+				dbMock.ExpectQuery("SELECT p.id, p.city, p.registration_date, p.status.*FROM pvzs p").
+					WithArgs(pvzID, string(pvzapi.InProgress)).
+					WillReturnError(pgx.ErrNoRows)
+			},
+			expected:      nil,
+			expectedError: db.ErrPVZNotFound,
+		},
+		{
+			name: "query error",
+			mockSetup: func() {
+				dbMock.ExpectQuery("SELECT p.id, p.city, p.registration_date, p.status.*FROM pvzs p").
 					WithArgs(pvzID, string(pvzapi.InProgress)).
+					WillReturnError(ErrRandomError)
+			},
+			expected:      nil,
+			expectedError: ErrRandomError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockSetup()
+
+			result, err := repo.GetPVZ(context.Background(), pvzID)
+
+			if tt.expectedError != nil {
+				assert.ErrorIs(t, err, tt.expectedError)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, tt.expected, result)
+		})
+	}
+}
+
+func TestPVZRepo_GetReception(t *testing.T) {
+	dbMock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer dbMock.Close()
+
+	repo := NewPVZRepo(dbMock)
+
+	pvzID := uuid.New()
+	receptionID := uuid.New()
+	productID := uuid.New()
+	openerID := uuid.New()
+	closerID := uuid.New()
+	barcode := "4600000000017"
+	expectedType := "обувь"
+	cellID := uuid.New()
+	cellNumber := 3
+	now := time.Now()
+
+	tests := []struct {
+		name          string
+		mockSetup     func()
+		expected      *models.ReceptionWithProducts
+		expectedError error
+	}{
+		{
+			name: "successful get",
+			mockSetup: func() {
+				rows := pgxmock.NewRows([]string{"id", "date_time", "pvz_id", "status", "created_by", "closed_by", "auto_closed"}).
+					AddRow(receptionID, now, pvzID, string(pvzapi.Close), &openerID, &closerID, false)
+				dbMock.ExpectQuery("SELECT id, date_time, pvz_id, status, created_by, closed_by, auto_closed FROM receptions").
+					WithArgs(receptionID).
+					WillReturnRows(rows)
+
+				productRows := pgxmock.NewRows([]string{"id", "date_time", "type", "reception_id", "line_number", "created_by", "barcode", "status", "cell_id", "number"}).
+					AddRow(productID, now, "обувь", receptionID, 1, &openerID, &barcode, string(pvzapi.Stored), &cellID, &cellNumber)
+				dbMock.ExpectQuery("SELECT pr.id, pr.date_time, pr.type, pr.reception_id, pr.line_number, pr.created_by, pr.barcode, pr.status, pr.cell_id, c.number FROM products pr LEFT JOIN storage_cells c").
+					WithArgs(receptionID).
+					WillReturnRows(productRows)
+
+				dbMock.ExpectQuery("SELECT type, expected_count FROM reception_manifest").
+					WithArgs(receptionID).
+					WillReturnRows(pgxmock.NewRows([]string{"type", "expected_count"}))
+
+				dbMock.ExpectQuery("SELECT barcode, type FROM reception_manifest_barcodes").
+					WithArgs(receptionID).
+					WillReturnRows(pgxmock.NewRows([]string{"barcode", "type"}))
+			},
+			expected: &models.ReceptionWithProducts{
+				Reception: models.Reception{
+					ID:        receptionID,
+					DateTime:  now,
+					PvzID:     pvzID,
+					Status:    string(pvzapi.Close),
+					CreatedBy: &openerID,
+					ClosedBy:  &closerID,
+				},
+				Products: []*models.Product{
+					{ID: productID, DateTime: now, Type: "обувь", ReceptionID: receptionID, LineNumber: 1, CreatedBy: &openerID, Barcode: &barcode, Status: string(pvzapi.Stored), CellID: &cellID, CellNumber: &cellNumber},
+				},
+			},
+			expectedError: nil,
+		},
+		{
+			name: "closed reception with manifest",
+			mockSetup: func() {
+				rows := pgxmock.NewRows([]string{"id", "date_time", "pvz_id", "status", "created_by", "closed_by", "auto_closed"}).
+					AddRow(receptionID, now, pvzID, string(pvzapi.Close), &openerID, &closerID, false)
+				dbMock.ExpectQuery("SELECT id, date_time, pvz_id, status, created_by, closed_by, auto_closed FROM receptions").
+					WithArgs(receptionID).
+					WillReturnRows(rows)
+
+				dbMock.ExpectQuery("SELECT pr.id, pr.date_time, pr.type, pr.reception_id, pr.line_number, pr.created_by, pr.barcode, pr.status, pr.cell_id, c.number FROM products pr LEFT JOIN storage_cells c").
+					WithArgs(receptionID).
+					WillReturnRows(pgxmock.NewRows([]string{"id", "date_time", "type", "reception_id", "line_number", "created_by", "barcode", "status", "cell_id", "number"}))
+
+				dbMock.ExpectQuery("SELECT type, expected_count FROM reception_manifest").
+					WithArgs(receptionID).
+					WillReturnRows(pgxmock.NewRows([]string{"type", "expected_count"}).AddRow("обувь", 2))
+
+				dbMock.ExpectQuery("SELECT type, kind, expected_count, actual_count FROM reception_discrepancies").
+					WithArgs(receptionID).
+					WillReturnRows(pgxmock.NewRows([]string{"type", "kind", "expected_count", "actual_count"}).
+						AddRow("обувь", string(pvzapi.Missing), 2, 0))
+
+				dbMock.ExpectQuery("SELECT barcode, type FROM reception_manifest_barcodes").
+					WithArgs(receptionID).
+					WillReturnRows(pgxmock.NewRows([]string{"barcode", "type"}).AddRow(barcode, "обувь"))
+
+				dbMock.ExpectQuery("SELECT barcode, kind, expected_type, actual_type FROM reception_barcode_discrepancies").
+					WithArgs(receptionID).
+					WillReturnRows(pgxmock.NewRows([]string{"barcode", "kind", "expected_type", "actual_type"}).
+						AddRow(barcode, string(pvzapi.Missing), &expectedType, nil))
+			},
+			expected: &models.ReceptionWithProducts{
+				Reception: models.Reception{
+					ID:               receptionID,
+					DateTime:         now,
+					PvzID:            pvzID,
+					Status:           string(pvzapi.Close),
+					CreatedBy:        &openerID,
+					ClosedBy:         &closerID,
+					Manifest:         []models.ManifestItem{{Type: "обувь", Count: 2}},
+					Discrepancies:    []models.Discrepancy{{Type: "обувь", Kind: string(pvzapi.Missing), Expected: 2, Actual: 0}},
+					ExpectedBarcodes: []models.ManifestBarcode{{Barcode: barcode, Type: "обувь"}},
+					BarcodeDiscrepancies: []models.BarcodeDiscrepancy{
+						{Barcode: barcode, Kind: string(pvzapi.Missing), ExpectedType: &expectedType},
+					},
+				},
+				Products: []*models.Product{},
+			},
+			expectedError: nil,
+		},
+		{
+			name: "reception not found",
+			mockSetup: func() {
+				dbMock.ExpectQuery("SELECT id, date_time, pvz_id, status, created_by, closed_by, auto_closed FROM receptions").
+					WithArgs(receptionID).
+					WillReturnError(pgx.ErrNoRows)
+			},
+			expected:      nil,
+			expectedError: db.ErrReceptionNotFound,
+		},
+		{
+			name: "products query error",
+			mockSetup: func() {
+				rows := pgxmock.NewRows([]string{"id", "date_time", "pvz_id", "status", "created_by", "closed_by", "auto_closed"}).
+					AddRow(receptionID, now, pvzID, string(pvzapi.Close), &openerID, &closerID, false)
+				dbMock.ExpectQuery("SELECT id, date_time, pvz_id, status, created_by, closed_by, auto_closed FROM receptions").
+					WithArgs(receptionID).
+					WillReturnRows(rows)
+
+				dbMock.ExpectQuery("SELECT pr.id, pr.date_time, pr.type, pr.reception_id, pr.line_number, pr.created_by, pr.barcode, pr.status, pr.cell_id, c.number FROM products pr LEFT JOIN storage_cells c").
+					WithArgs(receptionID).
+					WillReturnError(ErrRandomError)
+			},
+			expected:      nil,
+			expectedError: ErrRandomError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockSetup()
+
+			result, err := repo.GetReception(context.Background(), receptionID)
+
+			if tt.expectedError != nil {
+				assert.ErrorIs(t, err, tt.expectedError)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, tt.expected, result)
+		})
+	}
+}
+
+func TestPVZRepo_GetReceptionProducts(t *testing.T) {
+	dbMock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer dbMock.Close()
+
+	repo := NewPVZRepo(dbMock)
+
+	receptionID := uuid.New()
+	productID := uuid.New()
+	userID := uuid.New()
+	cellID := uuid.New()
+	cellNumber := 3
+	productType := "обувь"
+	now := time.Now()
+
+	columns := []string{"id", "date_time", "type", "reception_id", "line_number", "created_by", "barcode", "status", "cell_id", "number"}
+
+	tests := []struct {
+		name          string
+		productType   *string
+		mockSetup     func()
+		expected      []models.Product
+		expectedError error
+	}{
+		{
+			name:        "products after the cursor",
+			productType: &productType,
+			mockSetup: func() {
+				dbMock.ExpectQuery("SELECT EXISTS \\(SELECT 1 FROM receptions WHERE id = \\$1\\)").
+					WithArgs(receptionID).
+					WillReturnRows(pgxmock.NewRows([]string{"exists"}).AddRow(true))
+
+				dbMock.ExpectQuery("SELECT pr.id, .* FROM products pr .* WHERE pr.reception_id = \\$1 AND pr.line_number > \\$2 .* ORDER BY pr.line_number LIMIT \\$4").
+					WithArgs(receptionID, 10, &productType, 21).
+					WillReturnRows(pgxmock.NewRows(columns).
+						AddRow(productID, now, productType, receptionID, 11, &userID, nil, string(pvzapi.Received), &cellID, &cellNumber))
+			},
+			expected: []models.Product{
+				{ID: productID, DateTime: now, Type: productType, ReceptionID: receptionID, LineNumber: 11, CreatedBy: &userID, Status: string(pvzapi.Received), CellID: &cellID, CellNumber: &cellNumber},
+			},
+			expectedError: nil,
+		},
+		{
+			name: "no more products",
+			mockSetup: func() {
+				dbMock.ExpectQuery("SELECT EXISTS").
+					WithArgs(receptionID).
+					WillReturnRows(pgxmock.NewRows([]string{"exists"}).AddRow(true))
+
+				dbMock.ExpectQuery("SELECT pr.id, .* FROM products pr").
+					WithArgs(receptionID, 10, (*string)(nil), 21).
+					WillReturnRows(pgxmock.NewRows(columns))
+			},
+			expected:      []models.Product{},
+			expectedError: nil,
+		},
+		{
+			name: "reception not found",
+			mockSetup: func() {
+				dbMock.ExpectQuery("SELECT EXISTS").
+					WithArgs(receptionID).
+					WillReturnRows(pgxmock.NewRows([]string{"exists"}).AddRow(false))
+			},
+			expected:      nil,
+			expectedError: db.ErrReceptionNotFound,
+		},
+		{
+			name: "products query error",
+			mockSetup: func() {
+				dbMock.ExpectQuery("SELECT EXISTS").
+					WithArgs(receptionID).
+					WillReturnRows(pgxmock.NewRows([]string{"exists"}).AddRow(true))
+
+				dbMock.ExpectQuery("SELECT pr.id, .* FROM products pr").
+					WithArgs(receptionID, 10, (*string)(nil), 21).
+					WillReturnError(ErrRandomError)
+			},
+			expected:      nil,
+			expectedError: ErrRandomError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockSetup()
+
+			result, err := repo.GetReceptionProducts(context.Background(), receptionID, tt.productType, 10, 21)
+
+			if tt.expectedError != nil {
+				assert.ErrorIs(t, err, tt.expectedError)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, tt.expected, result)
+			assert.NoError(t, dbMock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestPVZRepo_GetReceptionPVZID(t *testing.T) {
+	dbMock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer dbMock.Close()
+
+	repo := NewPVZRepo(dbMock)
+
+	receptionID := uuid.New()
+	pvzID := uuid.New()
+
+	tests := []struct {
+		name          string
+		mockSetup     func()
+		expected      uuid.UUID
+		expectedError error
+	}{
+		{
+			name: "success",
+			mockSetup: func() {
+				dbMock.ExpectQuery("SELECT pvz_id FROM receptions WHERE id = \\$1").
+					WithArgs(receptionID).
+					WillReturnRows(pgxmock.NewRows([]string{"pvz_id"}).AddRow(pvzID))
+			},
+			expected:      pvzID,
+			expectedError: nil,
+		},
+		{
+			name: "reception not found",
+			mockSetup: func() {
+				dbMock.ExpectQuery("SELECT pvz_id FROM receptions WHERE id = \\$1").
+					WithArgs(receptionID).
+					WillReturnError(pgx.ErrNoRows)
+			},
+			expected:      uuid.Nil,
+			expectedError: db.ErrReceptionNotFound,
+		},
+		{
+			name: "query error",
+			mockSetup: func() {
+				dbMock.ExpectQuery("SELECT pvz_id FROM receptions WHERE id = \\$1").
+					WithArgs(receptionID).
+					WillReturnError(ErrRandomError)
+			},
+			expected:      uuid.Nil,
+			expectedError: ErrRandomError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockSetup()
+
+			result, err := repo.GetReceptionPVZID(context.Background(), receptionID)
+
+			if tt.expectedError != nil {
+				assert.ErrorIs(t, err, tt.expectedError)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, tt.expected, result)
+		})
+	}
+}
+
+func TestPVZRepo_GetProductPVZID(t *testing.T) {
+	dbMock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer dbMock.Close()
+
+	repo := NewPVZRepo(dbMock)
+
+	productID := uuid.New()
+	pvzID := uuid.New()
+
+	tests := []struct {
+		name          string
+		mockSetup     func()
+		expected      uuid.UUID
+		expectedError error
+	}{
+		{
+			name: "success",
+			mockSetup: func() {
+				dbMock.ExpectQuery("SELECT r.pvz_id FROM products pr JOIN receptions r .* WHERE pr.id = \\$1").
+					WithArgs(productID).
+					WillReturnRows(pgxmock.NewRows([]string{"pvz_id"}).AddRow(pvzID))
+			},
+			expected:      pvzID,
+			expectedError: nil,
+		},
+		{
+			name: "product not found",
+			mockSetup: func() {
+				dbMock.ExpectQuery("SELECT r.pvz_id FROM products pr").
+					WithArgs(productID).
+					WillReturnError(pgx.ErrNoRows)
+			},
+			expected:      uuid.Nil,
+			expectedError: db.ErrProductNotFound,
+		},
+		{
+			name: "query error",
+			mockSetup: func() {
+				dbMock.ExpectQuery("SELECT r.pvz_id FROM products pr").
+					WithArgs(productID).
+					WillReturnError(ErrRandomError)
+			},
+			expected:      uuid.Nil,
+			expectedError: ErrRandomError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockSetup()
+
+			result, err := repo.GetProductPVZID(context.Background(), productID)
+
+			if tt.expectedError != nil {
+				assert.ErrorIs(t, err, tt.expectedError)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, tt.expected, result)
+		})
+	}
+}
+
+func TestPVZRepo_UpdateProductStatus(t *testing.T) {
+	dbMock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer dbMock.Close()
+
+	repo := NewPVZRepo(dbMock)
+
+	productID := uuid.New()
+	receptionID := uuid.New()
+	userID := uuid.New()
+	now := time.Now()
+
+	allowed := []string{string(pvzapi.Stored)}
+	columns := []string{"id", "date_time", "type", "reception_id", "line_number", "created_by", "barcode", "status"}
+
+	expectCurrent := func(status string) {
+		dbMock.ExpectQuery("SELECT status FROM products WHERE id = \\$1 FOR UPDATE").
+			WithArgs(productID).
+			WillReturnRows(pgxmock.NewRows([]string{"status"}).AddRow(status))
+	}
+
+	tests := []struct {
+		name          string
+		mockSetup     func()
+		expected      *models.Product
+		expectedError error
+	}{
+		{
+			name: "success",
+			mockSetup: func() {
+				dbMock.ExpectBegin()
+				expectCurrent(string(pvzapi.Stored))
+
+				dbMock.ExpectQuery("UPDATE products SET status = \\$2 WHERE id = \\$1 RETURNING").
+					WithArgs(productID, string(pvzapi.Issued)).
+					WillReturnRows(pgxmock.NewRows(columns).
+						AddRow(productID, now, "обувь", receptionID, 1, &userID, nil, string(pvzapi.Issued)))
+
+				dbMock.ExpectCommit()
+			},
+			expected: &models.Product{
+				ID:          productID,
+				DateTime:    now,
+				Type:        "обувь",
+				ReceptionID: receptionID,
+				LineNumber:  1,
+				CreatedBy:   &userID,
+				Status:      string(pvzapi.Issued),
+			},
+			expectedError: nil,
+		},
+		{
+			name: "product not found",
+			mockSetup: func() {
+				dbMock.ExpectBegin()
+				dbMock.ExpectQuery("SELECT status FROM products WHERE id = \\$1 FOR UPDATE").
+					WithArgs(productID).
+					WillReturnError(pgx.ErrNoRows)
+				dbMock.ExpectRollback()
+			},
+			expected:      nil,
+			expectedError: db.ErrProductNotFound,
+		},
+		{
+			name: "transition not allowed",
+			mockSetup: func() {
+				dbMock.ExpectBegin()
+				expectCurrent(string(pvzapi.Received))
+				dbMock.ExpectRollback()
+			},
+			expected:      nil,
+			expectedError: db.ErrInvalidTransition,
+		},
+		{
+			name: "update error",
+			mockSetup: func() {
+				dbMock.ExpectBegin()
+				expectCurrent(string(pvzapi.Stored))
+
+				dbMock.ExpectQuery("UPDATE products SET status = \\$2 WHERE id = \\$1 RETURNING").
+					WithArgs(productID, string(pvzapi.Issued)).
+					WillReturnError(ErrRandomError)
+
+				dbMock.ExpectRollback()
+			},
+			expected:      nil,
+			expectedError: ErrRandomError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockSetup()
+
+			result, err := repo.UpdateProductStatus(context.Background(), productID, allowed, string(pvzapi.Issued))
+
+			if tt.expectedError != nil {
+				assert.ErrorIs(t, err, tt.expectedError)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, tt.expected, result)
+			assert.NoError(t, dbMock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestPVZRepo_SetPickupCode(t *testing.T) {
+	dbMock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer dbMock.Close()
+
+	repo := NewPVZRepo(dbMock)
+
+	productID := uuid.New()
+	codeHash := "$argon2id$v=19$m=65536,t=1,p=2$c2FsdA$aGFzaA"
+
+	expectStatus := func(status string) {
+		dbMock.ExpectQuery("SELECT status FROM products WHERE id = \\$1 FOR UPDATE").
+			WithArgs(productID).
+			WillReturnRows(pgxmock.NewRows([]string{"status"}).AddRow(status))
+	}
+
+	tests := []struct {
+		name          string
+		mockSetup     func()
+		expectedError error
+	}{
+		{
+			name: "success",
+			mockSetup: func() {
+				dbMock.ExpectBegin()
+				expectStatus(string(pvzapi.Stored))
+				dbMock.ExpectExec("UPDATE products SET pickup_code_hash = \\$2, pickup_attempts = 0, pickup_locked_until = NULL").
+					WithArgs(productID, codeHash).
+					WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+				dbMock.ExpectCommit()
+			},
+			expectedError: nil,
+		},
+		{
+			name: "product not found",
+			mockSetup: func() {
+				dbMock.ExpectBegin()
+				dbMock.ExpectQuery("SELECT status FROM products WHERE id = \\$1 FOR UPDATE").
+					WithArgs(productID).
+					WillReturnError(pgx.ErrNoRows)
+				dbMock.ExpectRollback()
+			},
+			expectedError: db.ErrProductNotFound,
+		},
+		{
+			name: "product is not stored",
+			mockSetup: func() {
+				dbMock.ExpectBegin()
+				expectStatus(string(pvzapi.Issued))
+				dbMock.ExpectRollback()
+			},
+			expectedError: db.ErrInvalidTransition,
+		},
+		{
+			name: "update error",
+			mockSetup: func() {
+				dbMock.ExpectBegin()
+				expectStatus(string(pvzapi.Stored))
+				dbMock.ExpectExec("UPDATE products SET pickup_code_hash").
+					WithArgs(productID, codeHash).
+					WillReturnError(ErrRandomError)
+				dbMock.ExpectRollback()
+			},
+			expectedError: ErrRandomError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockSetup()
+
+			err := repo.SetPickupCode(context.Background(), productID, codeHash)
+
+			if tt.expectedError != nil {
+				assert.ErrorIs(t, err, tt.expectedError)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.NoError(t, dbMock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestPVZRepo_ClaimPickupAttempt(t *testing.T) {
+	dbMock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer dbMock.Close()
+
+	repo := NewPVZRepo(dbMock)
+
+	productID := uuid.New()
+	codeHash := "$argon2id$v=19$m=65536,t=1,p=2$c2FsdA$aGFzaA"
+	now := time.Now()
+	activeLock := now.Add(time.Minute)
+	expiredLock := now.Add(-time.Minute)
+
+	columns := []string{"id", "status", "pickup_code_hash", "pickup_attempts", "pickup_locked_until"}
+	selectQuery := "SELECT id, status, pickup_code_hash, pickup_attempts, pickup_locked_until FROM products WHERE id = \\$1 FOR UPDATE"
+	updateQuery := "UPDATE products SET pickup_attempts = \\$2, pickup_locked_until = NULL WHERE id = \\$1"
+
+	expectProduct := func(status string, hash *string, attempts int, lockedUntil *time.Time) {
+		dbMock.ExpectQuery(selectQuery).
+			WithArgs(productID).
+			WillReturnRows(pgxmock.NewRows(columns).AddRow(productID, status, hash, attempts, lockedUntil))
+	}
+
+	tests := []struct {
+		name          string
+		mockSetup     func()
+		expected      *models.Product
+		expectedError error
+	}{
+		{
+			name: "attempt claimed",
+			mockSetup: func() {
+				dbMock.ExpectBegin()
+				expectProduct(string(pvzapi.Stored), &codeHash, 1, nil)
+				dbMock.ExpectExec(updateQuery).
+					WithArgs(productID, 2).
+					WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+				dbMock.ExpectCommit()
+			},
+			expected: &models.Product{
+				ID:             productID,
+				Status:         string(pvzapi.Stored),
+				PickupCodeHash: &codeHash,
+				PickupAttempts: 2,
+			},
+			expectedError: nil,
+		},
+		{
+			name: "expired lockout starts the count over",
+			mockSetup: func() {
+				dbMock.ExpectBegin()
+				expectProduct(string(pvzapi.Stored), &codeHash, 5, &expiredLock)
+				dbMock.ExpectExec(updateQuery).
+					WithArgs(productID, 1).
+					WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+				dbMock.ExpectCommit()
+			},
+			expected: &models.Product{
+				ID:             productID,
+				Status:         string(pvzapi.Stored),
+				PickupCodeHash: &codeHash,
+				PickupAttempts: 1,
+			},
+			expectedError: nil,
+		},
+		{
+			name: "product locked",
+			mockSetup: func() {
+				dbMock.ExpectBegin()
+				expectProduct(string(pvzapi.Stored), &codeHash, 5, &activeLock)
+				dbMock.ExpectRollback()
+			},
+			expectedError: db.ErrNoPickupAttemptsLeft,
+		},
+		{
+			name: "attempts in progress used up the limit",
+			mockSetup: func() {
+				dbMock.ExpectBegin()
+				expectProduct(string(pvzapi.Stored), &codeHash, 5, nil)
+				dbMock.ExpectRollback()
+			},
+			expectedError: db.ErrNoPickupAttemptsLeft,
+		},
+		{
+			name: "no pickup code",
+			mockSetup: func() {
+				dbMock.ExpectBegin()
+				expectProduct(string(pvzapi.Stored), nil, 0, nil)
+				dbMock.ExpectRollback()
+			},
+			expectedError: db.ErrNoPickupCode,
+		},
+		{
+			name: "product is not stored",
+			mockSetup: func() {
+				dbMock.ExpectBegin()
+				expectProduct(string(pvzapi.Issued), nil, 0, nil)
+				dbMock.ExpectRollback()
+			},
+			expectedError: db.ErrInvalidTransition,
+		},
+		{
+			name: "product not found",
+			mockSetup: func() {
+				dbMock.ExpectBegin()
+				dbMock.ExpectQuery(selectQuery).
+					WithArgs(productID).
+					WillReturnError(pgx.ErrNoRows)
+				dbMock.ExpectRollback()
+			},
+			expectedError: db.ErrProductNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockSetup()
+
+			result, err := repo.ClaimPickupAttempt(context.Background(), productID, 5, now)
+
+			if tt.expectedError != nil {
+				assert.ErrorIs(t, err, tt.expectedError)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, tt.expected, result)
+			assert.NoError(t, dbMock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestPVZRepo_FailPickupAttempt(t *testing.T) {
+	dbMock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer dbMock.Close()
+
+	repo := NewPVZRepo(dbMock)
+
+	productID := uuid.New()
+	lockedUntil := time.Now().Add(15 * time.Minute)
+	query := "UPDATE products SET pickup_locked_until = CASE WHEN pickup_attempts >= \\$2 THEN COALESCE\\(pickup_locked_until, \\$3::TIMESTAMPTZ\\)"
+
+	tests := []struct {
+		name          string
+		mockSetup     func()
+		expected      *time.Time
+		expectedError error
+	}{
+		{
+			name: "limit not reached",
+			mockSetup: func() {
+				dbMock.ExpectQuery(query).
+					WithArgs(productID, 5, lockedUntil).
+					WillReturnRows(pgxmock.NewRows([]string{"pickup_locked_until"}).AddRow(nil))
+			},
+			expected:      nil,
+			expectedError: nil,
+		},
+		{
+			name: "product locked",
+			mockSetup: func() {
+				dbMock.ExpectQuery(query).
+					WithArgs(productID, 5, lockedUntil).
+					WillReturnRows(pgxmock.NewRows([]string{"pickup_locked_until"}).AddRow(&lockedUntil))
+			},
+			expected:      &lockedUntil,
+			expectedError: nil,
+		},
+		{
+			name: "product not found",
+			mockSetup: func() {
+				dbMock.ExpectQuery("UPDATE products SET pickup_locked_until").
+					WithArgs(productID, 5, lockedUntil).
+					WillReturnError(pgx.ErrNoRows)
+			},
+			expected:      nil,
+			expectedError: db.ErrProductNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockSetup()
+
+			result, err := repo.FailPickupAttempt(context.Background(), productID, 5, lockedUntil)
+
+			if tt.expectedError != nil {
+				assert.ErrorIs(t, err, tt.expectedError)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, tt.expected, result)
+		})
+	}
+}
+
+func TestPVZRepo_IssueWithPickupCode(t *testing.T) {
+	dbMock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer dbMock.Close()
+
+	repo := NewPVZRepo(dbMock)
+
+	productID := uuid.New()
+	receptionID := uuid.New()
+	userID := uuid.New()
+	codeHash := "$argon2id$v=19$m=65536,t=1,p=2$c2FsdA$aGFzaA"
+	otherHash := "$argon2id$v=19$m=65536,t=1,p=2$c2FsdA$b3RoZXI"
+	now := time.Now()
+	activeLock := now.Add(time.Minute)
+
+	columns := []string{"id", "date_time", "type", "reception_id", "line_number", "created_by", "barcode", "status"}
+	selectQuery := "SELECT status, pickup_code_hash, pickup_locked_until FROM products WHERE id = \\$1 FOR UPDATE"
+
+	expectPickup := func(status string, hash *string, lockedUntil *time.Time) {
+		dbMock.ExpectQuery(selectQuery).
+			WithArgs(productID).
+			WillReturnRows(pgxmock.NewRows([]string{"status", "pickup_code_hash", "pickup_locked_until"}).
+				AddRow(status, hash, lockedUntil))
+	}
+
+	tests := []struct {
+		name          string
+		mockSetup     func()
+		expected      *models.Product
+		expectedError error
+	}{
+		{
+			name: "success",
+			mockSetup: func() {
+				dbMock.ExpectBegin()
+				expectPickup(string(pvzapi.Stored), &codeHash, nil)
+				dbMock.ExpectQuery("UPDATE products SET status = \\$2, pickup_code_hash = NULL.*RETURNING").
+					WithArgs(productID, string(pvzapi.Issued)).
+					WillReturnRows(pgxmock.NewRows(columns).
+						AddRow(productID, now, "обувь", receptionID, 1, &userID, nil, string(pvzapi.Issued)))
+				dbMock.ExpectCommit()
+			},
+			expected: &models.Product{
+				ID:          productID,
+				DateTime:    now,
+				Type:        "обувь",
+				ReceptionID: receptionID,
+				LineNumber:  1,
+				CreatedBy:   &userID,
+				Status:      string(pvzapi.Issued),
+			},
+			expectedError: nil,
+		},
+		{
+			name: "code changed",
+			mockSetup: func() {
+				dbMock.ExpectBegin()
+				expectPickup(string(pvzapi.Stored), &otherHash, nil)
+				dbMock.ExpectRollback()
+			},
+			expected:      nil,
+			expectedError: db.ErrInvalidTransition,
+		},
+		{
+			name: "product already issued",
+			mockSetup: func() {
+				dbMock.ExpectBegin()
+				expectPickup(string(pvzapi.Issued), nil, nil)
+				dbMock.ExpectRollback()
+			},
+			expected:      nil,
+			expectedError: db.ErrInvalidTransition,
+		},
+		{
+			name: "product locked",
+			mockSetup: func() {
+				dbMock.ExpectBegin()
+				expectPickup(string(pvzapi.Stored), &codeHash, &activeLock)
+				dbMock.ExpectRollback()
+			},
+			expected:      nil,
+			expectedError: db.ErrNoPickupAttemptsLeft,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockSetup()
+
+			result, err := repo.IssueWithPickupCode(context.Background(), productID, codeHash, now)
+
+			if tt.expectedError != nil {
+				assert.ErrorIs(t, err, tt.expectedError)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, tt.expected, result)
+			assert.NoError(t, dbMock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestPVZRepo_CreateTransfers(t *testing.T) {
+	dbMock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer dbMock.Close()
+
+	repo := NewPVZRepo(dbMock)
+
+	receptionID := uuid.New()
+	fromPvzID := uuid.New()
+	toPvzID := uuid.New()
+	userID := uuid.New()
+	transferID := uuid.New()
+	productID := uuid.New()
+	productIDs := []uuid.UUID{productID}
+	barcode := "4600000000001"
+	now := time.Now()
+
+	expectReception := func(status string) {
+		dbMock.ExpectQuery("SELECT pvz_id, status FROM receptions WHERE id = \\$1 FOR SHARE").
+			WithArgs(receptionID).
+			WillReturnRows(pgxmock.NewRows([]string{"pvz_id", "status"}).AddRow(fromPvzID, status))
+	}
+	expectDestination := func(status string) {
+		dbMock.ExpectQuery("SELECT status FROM pvzs WHERE id = \\$1 FOR SHARE").
+			WithArgs(toPvzID).
+			WillReturnRows(pgxmock.NewRows([]string{"status"}).AddRow(status))
+	}
+	expectProducts := func(hasBarcode bool, statuses ...string) {
+		rows := pgxmock.NewRows([]string{"status", "has_barcode"})
+		for _, status := range statuses {
+			rows.AddRow(status, hasBarcode)
+		}
+		dbMock.ExpectQuery("SELECT status, barcode IS NOT NULL FROM products WHERE reception_id = \\$1 AND id = ANY").
+			WithArgs(receptionID, productIDs).
+			WillReturnRows(rows)
+	}
+
+	tests := []struct {
+		name          string
+		mockSetup     func()
+		expected      []models.ProductTransfer
+		expectedError error
+	}{
+		{
+			name: "success",
+			mockSetup: func() {
+				dbMock.ExpectBegin()
+				expectReception(string(pvzapi.Close))
+				expectDestination(string(pvzapi.Active))
+				expectProducts(true, string(pvzapi.Stored))
+				dbMock.ExpectExec("UPDATE products SET status = \\$2 WHERE id = ANY").
+					WithArgs(productIDs, string(pvzapi.Transferred)).
+					WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+				dbMock.ExpectQuery("INSERT INTO product_transfers.*FROM created c").
+					WithArgs(productIDs, fromPvzID, toPvzID, &userID).
+					WillReturnRows(pgxmock.NewRows([]string{
+						"id", "product_id", "type", "barcode", "from_pvz_id", "to_pvz_id",
+						"reception_id", "delivered_product_id", "created_by", "created_at",
+					}).AddRow(transferID, productID, "обувь", &barcode, fromPvzID, toPvzID, nil, nil, &userID, now))
+				dbMock.ExpectCommit()
+			},
+			expected: []models.ProductTransfer{
+				{
+					ID:        transferID,
+					ProductID: productID,
+					Type:      "обувь",
+					Barcode:   &barcode,
+					FromPvzID: fromPvzID,
+					ToPvzID:   toPvzID,
+					Status:    string(pvzapi.InTransit),
+					CreatedBy: &userID,
+					CreatedAt: now,
+				},
+			},
+			expectedError: nil,
+		},
+		{
+			name: "reception not found",
+			mockSetup: func() {
+				dbMock.ExpectBegin()
+				dbMock.ExpectQuery("SELECT pvz_id, status FROM receptions").
+					WithArgs(receptionID).
+					WillReturnError(pgx.ErrNoRows)
+				dbMock.ExpectRollback()
+			},
+			expected:      nil,
+			expectedError: db.ErrReceptionNotFound,
+		},
+		{
+			name: "reception is not closed",
+			mockSetup: func() {
+				dbMock.ExpectBegin()
+				expectReception(string(pvzapi.InProgress))
+				dbMock.ExpectRollback()
+			},
+			expected:      nil,
+			expectedError: db.ErrReceptionNotClosed,
+		},
+		{
+			name: "destination pvz not found",
+			mockSetup: func() {
+				dbMock.ExpectBegin()
+				expectReception(string(pvzapi.Close))
+				dbMock.ExpectQuery("SELECT status FROM pvzs").
+					WithArgs(toPvzID).
+					WillReturnError(pgx.ErrNoRows)
+				dbMock.ExpectRollback()
+			},
+			expected:      nil,
+			expectedError: db.ErrPVZNotFound,
+		},
+		{
+			name: "destination pvz is not active",
+			mockSetup: func() {
+				dbMock.ExpectBegin()
+				expectReception(string(pvzapi.Close))
+				expectDestination(string(pvzapi.Suspended))
+				dbMock.ExpectRollback()
+			},
+			expected:      nil,
+			expectedError: db.ErrPVZNotActive,
+		},
+		{
+			name: "product is not in the reception",
+			mockSetup: func() {
+				dbMock.ExpectBegin()
+				expectReception(string(pvzapi.Close))
+				expectDestination(string(pvzapi.Active))
+				expectProducts(true)
+				dbMock.ExpectRollback()
+			},
+			expected:      nil,
+			expectedError: db.ErrProductNotFound,
+		},
+		{
+			name: "product is not stored",
+			mockSetup: func() {
+				dbMock.ExpectBegin()
+				expectReception(string(pvzapi.Close))
+				expectDestination(string(pvzapi.Active))
+				expectProducts(true, string(pvzapi.Issued))
+				dbMock.ExpectRollback()
+			},
+			expected:      nil,
+			expectedError: db.ErrInvalidTransition,
+		},
+		{
+			name: "product has no barcode",
+			mockSetup: func() {
+				dbMock.ExpectBegin()
+				expectReception(string(pvzapi.Close))
+				expectDestination(string(pvzapi.Active))
+				expectProducts(false, string(pvzapi.Stored))
+				dbMock.ExpectRollback()
+			},
+			expected:      nil,
+			expectedError: db.ErrNoBarcode,
+		},
+		{
+			name: "insert error",
+			mockSetup: func() {
+				dbMock.ExpectBegin()
+				expectReception(string(pvzapi.Close))
+				expectDestination(string(pvzapi.Active))
+				expectProducts(true, string(pvzapi.Stored))
+				dbMock.ExpectExec("UPDATE products SET status").
+					WithArgs(productIDs, string(pvzapi.Transferred)).
+					WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+				dbMock.ExpectQuery("INSERT INTO product_transfers").
+					WithArgs(productIDs, fromPvzID, toPvzID, &userID).
+					WillReturnError(ErrRandomError)
+				dbMock.ExpectRollback()
+			},
+			expected:      nil,
+			expectedError: ErrRandomError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockSetup()
+
+			result, err := repo.CreateTransfers(context.Background(), receptionID, toPvzID, userID, productIDs)
+
+			if tt.expectedError != nil {
+				assert.ErrorIs(t, err, tt.expectedError)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, tt.expected, result)
+			assert.NoError(t, dbMock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestPVZRepo_MoveProduct(t *testing.T) {
+	dbMock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer dbMock.Close()
+
+	repo := NewPVZRepo(dbMock)
+
+	productID := uuid.New()
+	pvzID := uuid.New()
+	receptionID := uuid.New()
+	cellID := uuid.New()
+	cellNumber := 4
+	now := time.Now()
+
+	expectProduct := func(status string) {
+		dbMock.ExpectQuery("SELECT pr.status, r.pvz_id FROM products pr JOIN receptions r .* FOR UPDATE OF pr").
+			WithArgs(productID).
+			WillReturnRows(pgxmock.NewRows([]string{"status", "pvz_id"}).AddRow(status, pvzID))
+	}
+	expectCell := func(capacity, occupied int) {
+		dbMock.ExpectQuery("SELECT c.id, c.capacity, .* FROM storage_cells c WHERE c.pvz_id = \\$1 AND c.number = \\$2").
+			WithArgs(pvzID, cellNumber, productID, productsInPVZ).
+			WillReturnRows(pgxmock.NewRows([]string{"id", "capacity", "occupied"}).AddRow(cellID, capacity, occupied))
+	}
+
+	tests := []struct {
+		name          string
+		mockSetup     func()
+		expected      *models.Product
+		expectedError error
+	}{
+		{
+			name: "product moved",
+			mockSetup: func() {
+				dbMock.ExpectBegin()
+				expectProduct(string(pvzapi.Stored))
+				expectCell(2, 1)
+
+				dbMock.ExpectQuery("UPDATE products SET cell_id = \\$2 WHERE id = \\$1 RETURNING").
+					WithArgs(productID, cellID).
+					WillReturnRows(pgxmock.NewRows([]string{"id", "date_time", "type", "reception_id", "line_number", "created_by", "barcode", "status", "cell_id"}).
+						AddRow(productID, now, "обувь", receptionID, 1, nil, nil, string(pvzapi.Stored), &cellID))
+
+				dbMock.ExpectCommit()
+			},
+			expected: &models.Product{
+				ID:          productID,
+				DateTime:    now,
+				Type:        "обувь",
+				ReceptionID: receptionID,
+				LineNumber:  1,
+				Status:      string(pvzapi.Stored),
+				CellID:      &cellID,
+				CellNumber:  &cellNumber,
+			},
+			expectedError: nil,
+		},
+		{
+			name: "product not found",
+			mockSetup: func() {
+				dbMock.ExpectBegin()
+				dbMock.ExpectQuery("SELECT pr.status, r.pvz_id FROM products pr").
+					WithArgs(productID).
+					WillReturnError(pgx.ErrNoRows)
+				dbMock.ExpectRollback()
+			},
+			expected:      nil,
+			expectedError: db.ErrProductNotFound,
+		},
+		{
+			name: "product already left the pvz",
+			mockSetup: func() {
+				dbMock.ExpectBegin()
+				expectProduct(string(pvzapi.Issued))
+				dbMock.ExpectRollback()
+			},
+			expected:      nil,
+			expectedError: db.ErrInvalidTransition,
+		},
+		{
+			name: "cell not found",
+			mockSetup: func() {
+				dbMock.ExpectBegin()
+				expectProduct(string(pvzapi.Received))
+				dbMock.ExpectQuery("SELECT c.id, c.capacity, .* FROM storage_cells c").
+					WithArgs(pvzID, cellNumber, productID, productsInPVZ).
+					WillReturnError(pgx.ErrNoRows)
+				dbMock.ExpectRollback()
+			},
+			expected:      nil,
+			expectedError: db.ErrCellNotFound,
+		},
+		{
+			name: "cell is full",
+			mockSetup: func() {
+				dbMock.ExpectBegin()
+				expectProduct(string(pvzapi.Stored))
+				expectCell(2, 2)
+				dbMock.ExpectRollback()
+			},
+			expected:      nil,
+			expectedError: db.ErrCellFull,
+		},
+		{
+			name: "update error",
+			mockSetup: func() {
+				dbMock.ExpectBegin()
+				expectProduct(string(pvzapi.Stored))
+				expectCell(2, 0)
+
+				dbMock.ExpectQuery("UPDATE products SET cell_id = \\$2 WHERE id = \\$1 RETURNING").
+					WithArgs(productID, cellID).
+					WillReturnError(ErrRandomError)
+
+				dbMock.ExpectRollback()
+			},
+			expected:      nil,
+			expectedError: ErrRandomError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockSetup()
+
+			result, err := repo.MoveProduct(context.Background(), productID, cellNumber)
+
+			if tt.expectedError != nil {
+				assert.ErrorIs(t, err, tt.expectedError)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, tt.expected, result)
+			assert.NoError(t, dbMock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestPVZRepo_FindProductsByBarcode(t *testing.T) {
+	dbMock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer dbMock.Close()
+
+	repo := NewPVZRepo(dbMock)
+
+	pvzID := uuid.New()
+	receptionID := uuid.New()
+	productID := uuid.New()
+	userID := uuid.New()
+	barcode := "4600000000017"
+	now := time.Now()
+
+	columns := []string{
+		"pr.id", "pr.date_time", "pr.type", "pr.line_number", "pr.created_by", "pr.barcode", "pr.status", "pr.cell_id", "c.number",
+		"r.id", "r.date_time", "r.status", "r.created_by", "r.closed_by", "r.auto_closed",
+		"p.id", "p.city", "p.registration_date", "p.status",
+	}
+
+	tests := []struct {
+		name          string
+		mockSetup     func()
+		expected      []models.ProductLocation
+		expectedError error
+	}{
+		{
+			name: "product found",
+			mockSetup: func() {
+				dbMock.ExpectQuery("SELECT pr.id, .* FROM products pr JOIN receptions r .* JOIN pvzs p .* WHERE pr.barcode = \\$1").
+					WithArgs(barcode).
+					WillReturnRows(pgxmock.NewRows(columns).AddRow(
+						productID, now, "обувь", 1, &userID, &barcode, string(pvzapi.Issued), nil, nil,
+						receptionID, now, string(pvzapi.Close), &userID, &userID, false,
+						pvzID, "Москва", now, string(pvzapi.Active),
+					))
+			},
+			expected: []models.ProductLocation{
+				{
+					Product: models.Product{
+						ID:          productID,
+						DateTime:    now,
+						Type:        "обувь",
+						ReceptionID: receptionID,
+						LineNumber:  1,
+						CreatedBy:   &userID,
+						Barcode:     &barcode,
+						Status:      string(pvzapi.Issued),
+					},
+					Reception: models.Reception{
+						ID:        receptionID,
+						DateTime:  now,
+						PvzID:     pvzID,
+						Status:    string(pvzapi.Close),
+						CreatedBy: &userID,
+						ClosedBy:  &userID,
+					},
+					PVZ: models.PVZ{
+						ID:               pvzID,
+						City:             "Москва",
+						RegistrationDate: now,
+						Status:           string(pvzapi.Active),
+					},
+				},
+			},
+			expectedError: nil,
+		},
+		{
+			name: "nothing found",
+			mockSetup: func() {
+				dbMock.ExpectQuery("SELECT pr.id, .* FROM products pr").
+					WithArgs(barcode).
+					WillReturnRows(pgxmock.NewRows(columns))
+			},
+			expected:      []models.ProductLocation{},
+			expectedError: nil,
+		},
+		{
+			name: "query error",
+			mockSetup: func() {
+				dbMock.ExpectQuery("SELECT pr.id, .* FROM products pr").
+					WithArgs(barcode).
+					WillReturnError(ErrRandomError)
+			},
+			expected:      nil,
+			expectedError: ErrRandomError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockSetup()
+
+			result, err := repo.FindProductsByBarcode(context.Background(), barcode)
+
+			if tt.expectedError != nil {
+				assert.ErrorIs(t, err, tt.expectedError)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, tt.expected, result)
+		})
+	}
+}
+
+func TestPVZRepo_GetReceptionHistory(t *testing.T) {
+	dbMock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer dbMock.Close()
+
+	repo := NewPVZRepo(dbMock)
+
+	receptionID := uuid.New()
+	recordID := uuid.New()
+	userID := uuid.New()
+	now := time.Now()
+
+	columns := []string{"id", "reception_id", "action", "reason", "user_id", "created_at"}
+
+	tests := []struct {
+		name          string
+		mockSetup     func()
+		expected      []models.ReceptionAuditRecord
+		expectedError error
+	}{
+		{
+			name: "success",
+			mockSetup: func() {
+				dbMock.ExpectQuery("SELECT EXISTS").
+					WithArgs(receptionID).
+					WillReturnRows(pgxmock.NewRows([]string{"exists"}).AddRow(true))
+
+				dbMock.ExpectQuery("SELECT id, reception_id, action, reason, user_id, created_at FROM reception_audit").
+					WithArgs(receptionID).
+					WillReturnRows(pgxmock.NewRows(columns).
+						AddRow(recordID, receptionID, string(pvzapi.Reopen), "closed by mistake", &userID, now))
+			},
+			expected: []models.ReceptionAuditRecord{
+				{
+					ID:          recordID,
+					ReceptionID: receptionID,
+					Action:      string(pvzapi.Reopen),
+					Reason:      "closed by mistake",
+					UserID:      &userID,
+					CreatedAt:   now,
+				},
+			},
+			expectedError: nil,
+		},
+		{
+			name: "empty history",
+			mockSetup: func() {
+				dbMock.ExpectQuery("SELECT EXISTS").
+					WithArgs(receptionID).
+					WillReturnRows(pgxmock.NewRows([]string{"exists"}).AddRow(true))
+
+				dbMock.ExpectQuery("SELECT id, reception_id, action, reason, user_id, created_at FROM reception_audit").
+					WithArgs(receptionID).
+					WillReturnRows(pgxmock.NewRows(columns))
+			},
+			expected:      []models.ReceptionAuditRecord{},
+			expectedError: nil,
+		},
+		{
+			name: "reception not found",
+			mockSetup: func() {
+				dbMock.ExpectQuery("SELECT EXISTS").
+					WithArgs(receptionID).
+					WillReturnRows(pgxmock.NewRows([]string{"exists"}).AddRow(false))
+			},
+			expected:      nil,
+			expectedError: db.ErrReceptionNotFound,
+		},
+		{
+			name: "query error",
+			mockSetup: func() {
+				dbMock.ExpectQuery("SELECT EXISTS").
+					WithArgs(receptionID).
+					WillReturnRows(pgxmock.NewRows([]string{"exists"}).AddRow(true))
+
+				dbMock.ExpectQuery("SELECT id, reception_id, action, reason, user_id, created_at FROM reception_audit").
+					WithArgs(receptionID).
+					WillReturnError(ErrRandomError)
+			},
+			expected:      nil,
+			expectedError: ErrRandomError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockSetup()
+
+			result, err := repo.GetReceptionHistory(context.Background(), receptionID)
+
+			if tt.expectedError != nil {
+				assert.ErrorIs(t, err, tt.expectedError)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, tt.expected, result)
+		})
+	}
+}
+
+func TestPVZRepo_AssignEmployee(t *testing.T) {
+	dbMock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer dbMock.Close()
+
+	repo := NewPVZRepo(dbMock)
+
+	pvzID := uuid.New()
+	userID := uuid.New()
+	now := time.Now()
+
+	tests := []struct {
+		name          string
+		mockSetup     func()
+		expected      *models.EmployeeAssignment
+		expectedError error
+	}{
+		{
+			name: "success",
+			mockSetup: func() {
+				dbMock.ExpectBegin()
+
+				dbMock.ExpectQuery("SELECT role FROM users").
+					WithArgs(userID).
+					WillReturnRows(pgxmock.NewRows([]string{"role"}).AddRow(string(pvzapi.UserRoleEmployee)))
+
+				dbMock.ExpectQuery("INSERT INTO employee_pvz.*RETURNING user_id, pvz_id, assigned_at").
+					WithArgs(userID, pvzID).
+					WillReturnRows(pgxmock.NewRows([]string{"user_id", "pvz_id", "assigned_at"}).AddRow(userID, pvzID, now))
+
+				dbMock.ExpectCommit()
+			},
+			expected: &models.EmployeeAssignment{
+				UserID:     userID,
+				PvzID:      pvzID,
+				AssignedAt: now,
+			},
+			expectedError: nil,
+		},
+		{
+			name: "user not found",
+			mockSetup: func() {
+				dbMock.ExpectBegin()
+
+				dbMock.ExpectQuery("SELECT role FROM users").
+					WithArgs(userID).
+					WillReturnError(pgx.ErrNoRows)
+
+				dbMock.ExpectRollback()
+			},
+			expected:      nil,
+			expectedError: db.ErrUserNotFound,
+		},
+		{
+			name: "user is not an employee",
+			mockSetup: func() {
+				dbMock.ExpectBegin()
+
+				dbMock.ExpectQuery("SELECT role FROM users").
+					WithArgs(userID).
+					WillReturnRows(pgxmock.NewRows([]string{"role"}).AddRow(string(pvzapi.UserRoleModerator)))
+
+				dbMock.ExpectRollback()
+			},
+			expected:      nil,
+			expectedError: db.ErrNotEmployee,
+		},
+		{
+			name: "already assigned",
+			mockSetup: func() {
+				dbMock.ExpectBegin()
+
+				dbMock.ExpectQuery("SELECT role FROM users").
+					WithArgs(userID).
+					WillReturnRows(pgxmock.NewRows([]string{"role"}).AddRow(string(pvzapi.UserRoleEmployee)))
+
+				dbMock.ExpectQuery("INSERT INTO employee_pvz.*RETURNING user_id, pvz_id, assigned_at").
+					WithArgs(userID, pvzID).
 					WillReturnError(pgx.ErrNoRows)
+
+				dbMock.ExpectRollback()
+			},
+			expected:      nil,
+			expectedError: db.ErrAlreadyAssigned,
+		},
+		{
+			name: "pvz not found",
+			mockSetup: func() {
+				dbMock.ExpectBegin()
+
+				dbMock.ExpectQuery("SELECT role FROM users").
+					WithArgs(userID).
+					WillReturnRows(pgxmock.NewRows([]string{"role"}).AddRow(string(pvzapi.UserRoleEmployee)))
+
+				dbMock.ExpectQuery("INSERT INTO employee_pvz.*RETURNING user_id, pvz_id, assigned_at").
+					WithArgs(userID, pvzID).
+					WillReturnError(&pgconn.PgError{Code: "23503"})
+
 				dbMock.ExpectRollback()
 			},
-			expected:      nil,
-			expectedError: db.ErrNoOpenReception,
+			expected:      nil,
+			expectedError: db.ErrPVZNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockSetup()
+
+			result, err := repo.AssignEmployee(context.Background(), pvzID, userID)
+
+			if tt.expectedError != nil {
+				assert.ErrorIs(t, err, tt.expectedError)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, tt.expected, result)
+			assert.NoError(t, dbMock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestPVZRepo_UnassignEmployee(t *testing.T) {
+	dbMock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer dbMock.Close()
+
+	repo := NewPVZRepo(dbMock)
+
+	pvzID := uuid.New()
+	userID := uuid.New()
+
+	tests := []struct {
+		name          string
+		mockSetup     func()
+		expectedError error
+	}{
+		{
+			name: "success",
+			mockSetup: func() {
+				dbMock.ExpectQuery("DELETE FROM employee_pvz").
+					WithArgs(userID, pvzID).
+					WillReturnRows(pgxmock.NewRows([]string{"user_id"}).AddRow(userID))
+			},
+			expectedError: nil,
+		},
+		{
+			name: "not assigned",
+			mockSetup: func() {
+				dbMock.ExpectQuery("DELETE FROM employee_pvz").
+					WithArgs(userID, pvzID).
+					WillReturnError(pgx.ErrNoRows)
+			},
+			expectedError: db.ErrNotAssigned,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockSetup()
+
+			err := repo.UnassignEmployee(context.Background(), pvzID, userID)
+
+			if tt.expectedError != nil {
+				assert.ErrorIs(t, err, tt.expectedError)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestPVZRepo_GetPVZEmployees(t *testing.T) {
+	dbMock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer dbMock.Close()
+
+	repo := NewPVZRepo(dbMock)
+
+	pvzID := uuid.New()
+	firstID, secondID := uuid.New(), uuid.New()
+
+	tests := []struct {
+		name          string
+		mockSetup     func()
+		expected      []models.User
+		expectedError error
+	}{
+		{
+			name: "success",
+			mockSetup: func() {
+				dbMock.ExpectQuery("SELECT u.id, u.email, u.role FROM employee_pvz ep JOIN users u").
+					WithArgs(pvzID).
+					WillReturnRows(pgxmock.NewRows([]string{"id", "email", "role"}).
+						AddRow(firstID, "a@example.com", "employee").
+						AddRow(secondID, "b@example.com", "employee"))
+			},
+			expected: []models.User{
+				{ID: firstID, Email: "a@example.com", Role: "employee"},
+				{ID: secondID, Email: "b@example.com", Role: "employee"},
+			},
+		},
+		{
+			name: "no employees",
+			mockSetup: func() {
+				dbMock.ExpectQuery("SELECT u.id, u.email, u.role FROM employee_pvz ep JOIN users u").
+					WithArgs(pvzID).
+					WillReturnRows(pgxmock.NewRows([]string{"id", "email", "role"}))
+			},
+			expected: nil,
+		},
+		{
+			name: "rows error",
+			mockSetup: func() {
+				dbMock.ExpectQuery("SELECT u.id, u.email, u.role FROM employee_pvz ep JOIN users u").
+					WithArgs(pvzID).
+					WillReturnRows(pgxmock.NewRows([]string{"id", "email", "role"}).
+						AddRow(firstID, "a@example.com", "employee").
+						RowError(0, ErrRandomError))
+			},
+			expectedError: ErrRandomError,
+		},
+		{
+			name: "query error",
+			mockSetup: func() {
+				dbMock.ExpectQuery("SELECT u.id, u.email, u.role FROM employee_pvz ep JOIN users u").
+					WithArgs(pvzID).
+					WillReturnError(ErrRandomError)
+			},
+			expectedError: ErrRandomError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockSetup()
+
+			result, err := repo.GetPVZEmployees(context.Background(), pvzID)
+
+			if tt.expectedError != nil {
+				assert.ErrorIs(t, err, tt.expectedError)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, tt.expected, result)
+			assert.NoError(t, dbMock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestPVZRepo_IsEmployeeAssigned(t *testing.T) {
+	dbMock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer dbMock.Close()
+
+	repo := NewPVZRepo(dbMock)
+
+	pvzID := uuid.New()
+	userID := uuid.New()
+
+	dbMock.ExpectQuery("SELECT EXISTS").
+		WithArgs(userID, pvzID).
+		WillReturnRows(pgxmock.NewRows([]string{"exists"}).AddRow(true))
+
+	assigned, err := repo.IsEmployeeAssigned(context.Background(), pvzID, userID)
+	assert.NoError(t, err)
+	assert.True(t, assigned)
+
+	dbMock.ExpectQuery("SELECT EXISTS").
+		WithArgs(userID, pvzID).
+		WillReturnError(ErrRandomError)
+
+	_, err = repo.IsEmployeeAssigned(context.Background(), pvzID, userID)
+	assert.ErrorIs(t, err, ErrRandomError)
+}
+
+func TestPVZRepo_CreateCells(t *testing.T) {
+	dbMock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer dbMock.Close()
+
+	repo := NewPVZRepo(dbMock)
+
+	pvzID := uuid.New()
+	firstID, secondID := uuid.New(), uuid.New()
+	now := time.Now()
+
+	expectPVZ := func() {
+		dbMock.ExpectQuery("SELECT id FROM pvzs WHERE id = \\$1 FOR UPDATE").
+			WithArgs(pvzID).
+			WillReturnRows(pgxmock.NewRows([]string{"id"}).AddRow(pvzID))
+	}
+
+	tests := []struct {
+		name          string
+		mockSetup     func()
+		expected      []models.StorageCell
+		expectedError error
+	}{
+		{
+			name: "cells numbered after existing ones",
+			mockSetup: func() {
+				dbMock.ExpectBegin()
+				expectPVZ()
+
+				dbMock.ExpectQuery("INSERT INTO storage_cells .* generate_series").
+					WithArgs(pvzID, 2, 10).
+					WillReturnRows(pgxmock.NewRows([]string{"id", "pvz_id", "number", "capacity", "created_at"}).
+						AddRow(firstID, pvzID, 3, 10, now).
+						AddRow(secondID, pvzID, 4, 10, now))
+
+				dbMock.ExpectCommit()
+			},
+			expected: []models.StorageCell{
+				{ID: firstID, PvzID: pvzID, Number: 3, Capacity: 10, CreatedAt: now},
+				{ID: secondID, PvzID: pvzID, Number: 4, Capacity: 10, CreatedAt: now},
+			},
+			expectedError: nil,
 		},
 		{
-			name: "begin transaction error",
+			name: "pvz not found",
 			mockSetup: func() {
-				dbMock.ExpectBegin().WillReturnError(ErrRandomError)
+				dbMock.ExpectBegin()
+				dbMock.ExpectQuery("SELECT id FROM pvzs WHERE id = \\$1 FOR UPDATE").
+					WithArgs(pvzID).
+					WillReturnError(pgx.ErrNoRows)
+				dbMock.ExpectRollback()
 			},
 			expected:      nil,
-			expectedError: ErrRandomError,
+			expectedError: db.ErrPVZNotFound,
 		},
 		{
-			name: "update reception error",
+			name: "insert error",
 			mockSetup: func() {
 				dbMock.ExpectBegin()
-				dbMock.ExpectQuery("SELECT id, date_time, pvz_id FROM receptions.*FOR UPDATE").
-					WithArgs(pvzID, string(pvzapi.InProgress)).
-					WillReturnRows(pgxmock.NewRows([]string{"id", "date_time", "pvz_id"}).
-						AddRow(receptionID, time.Now(), pvzID))
+				expectPVZ()
 
-				dbMock.ExpectExec("UPDATE receptions SET status =.*").
-					WithArgs(string(pvzapi.Close), receptionID).
+				dbMock.ExpectQuery("INSERT INTO storage_cells").
+					WithArgs(pvzID, 2, 10).
 					WillReturnError(ErrRandomError)
 
 				dbMock.ExpectRollback()
@@ -643,21 +6687,104 @@ func TestPVZRepo_CloseLastReception(t *testing.T) {
 			expected:      nil,
 			expectedError: ErrRandomError,
 		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockSetup()
+
+			result, err := repo.CreateCells(context.Background(), pvzID, 2, 10)
+
+			if tt.expectedError != nil {
+				assert.ErrorIs(t, err, tt.expectedError)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, tt.expected, result)
+			assert.NoError(t, dbMock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestPVZRepo_GetCells(t *testing.T) {
+	dbMock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer dbMock.Close()
+
+	repo := NewPVZRepo(dbMock)
+
+	pvzID := uuid.New()
+	firstID, secondID := uuid.New(), uuid.New()
+	now := time.Now()
+
+	columns := []string{"id", "pvz_id", "number", "capacity", "created_at", "count"}
+
+	tests := []struct {
+		name          string
+		mockSetup     func()
+		expected      *models.CellOccupancy
+		expectedError error
+	}{
 		{
-			name: "commit transaction error",
+			name: "cells with totals",
 			mockSetup: func() {
-				dbMock.ExpectBegin()
+				dbMock.ExpectQuery("SELECT \\(SELECT COUNT\\(\\*\\) FROM products pr .* pr.cell_id IS NULL").
+					WithArgs(pvzID, productsInPVZ).
+					WillReturnRows(pgxmock.NewRows([]string{"count"}).AddRow(1))
 
-				dbMock.ExpectQuery("SELECT id, date_time, pvz_id FROM receptions.*FOR UPDATE").
-					WithArgs(pvzID, string(pvzapi.InProgress)).
-					WillReturnRows(pgxmock.NewRows([]string{"id", "date_time", "pvz_id"}).
-						AddRow(receptionID, time.Now(), pvzID))
+				dbMock.ExpectQuery("SELECT c.id, c.pvz_id, c.number, c.capacity, c.created_at, COUNT\\(pr.id\\) FROM storage_cells c").
+					WithArgs(pvzID, productsInPVZ).
+					WillReturnRows(pgxmock.NewRows(columns).
+						AddRow(firstID, pvzID, 1, 5, now, 5).
+						AddRow(secondID, pvzID, 2, 10, now, 3))
+			},
+			expected: &models.CellOccupancy{
+				Cells: []models.StorageCell{
+					{ID: firstID, PvzID: pvzID, Number: 1, Capacity: 5, Occupied: 5, CreatedAt: now},
+					{ID: secondID, PvzID: pvzID, Number: 2, Capacity: 10, Occupied: 3, CreatedAt: now},
+				},
+				Capacity:   15,
+				Occupied:   8,
+				Unassigned: 1,
+			},
+			expectedError: nil,
+		},
+		{
+			name: "pvz without cells",
+			mockSetup: func() {
+				dbMock.ExpectQuery("SELECT \\(SELECT COUNT\\(\\*\\) FROM products pr").
+					WithArgs(pvzID, productsInPVZ).
+					WillReturnRows(pgxmock.NewRows([]string{"count"}).AddRow(0))
 
-				dbMock.ExpectExec("UPDATE receptions SET status =.*").
-					WithArgs(string(pvzapi.Close), receptionID).
-					WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+				dbMock.ExpectQuery("SELECT c.id, c.pvz_id, c.number, c.capacity, c.created_at").
+					WithArgs(pvzID, productsInPVZ).
+					WillReturnRows(pgxmock.NewRows(columns))
+			},
+			expected: &models.CellOccupancy{
+				Cells: []models.StorageCell{},
+			},
+			expectedError: nil,
+		},
+		{
+			name: "pvz not found",
+			mockSetup: func() {
+				dbMock.ExpectQuery("SELECT \\(SELECT COUNT\\(\\*\\) FROM products pr").
+					WithArgs(pvzID, productsInPVZ).
+					WillReturnError(pgx.ErrNoRows)
+			},
+			expected:      nil,
+			expectedError: db.ErrPVZNotFound,
+		},
+		{
+			name: "cells query error",
+			mockSetup: func() {
+				dbMock.ExpectQuery("SELECT \\(SELECT COUNT\\(\\*\\) FROM products pr").
+					WithArgs(pvzID, productsInPVZ).
+					WillReturnRows(pgxmock.NewRows([]string{"count"}).AddRow(0))
 
-				dbMock.ExpectCommit().WillReturnError(ErrRandomError)
+				dbMock.ExpectQuery("SELECT c.id, c.pvz_id, c.number, c.capacity, c.created_at").
+					WithArgs(pvzID, productsInPVZ).
+					WillReturnError(ErrRandomError)
 			},
 			expected:      nil,
 			expectedError: ErrRandomError,
@@ -668,184 +6795,221 @@ func TestPVZRepo_CloseLastReception(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			tt.mockSetup()
 
-			result, err := repo.CloseLastReception(context.Background(), pvzID)
+			result, err := repo.GetCells(context.Background(), pvzID)
 
 			if tt.expectedError != nil {
 				assert.ErrorIs(t, err, tt.expectedError)
 			} else {
 				assert.NoError(t, err)
-				assert.Equal(t, tt.expected, result)
 			}
+			assert.Equal(t, tt.expected, result)
+			assert.NoError(t, dbMock.ExpectationsWereMet())
 		})
 	}
 }
 
-func TestPVZRepo_GetPVZs(t *testing.T) {
+func TestPVZRepo_GetPVZCapacity(t *testing.T) {
 	dbMock, err := pgxmock.NewPool()
 	require.NoError(t, err)
 	defer dbMock.Close()
 
-	repo := &pvzRepo{db: dbMock}
-
-	startDate := time.Now().Add(2 * time.Hour)
-	endDate := startDate.Add(-2 * time.Hour)
-	var limit uint64 = 10
-	var offset uint64 = 0
+	repo := NewPVZRepo(dbMock)
 
 	pvzID := uuid.New()
-	receptionID := uuid.New()
-	productID := uuid.New()
-
-	regDate := time.Now().AddDate(-1, 0, 0)
-	recDate := time.Now().Add(-2 * time.Hour)
-	prodDate := time.Now().Add(-1 * time.Hour)
+	capacity := 100
 
-	status := "in_progress"
-	productType := "обувь"
+	columns := []string{"id", "capacity", "reception_limit", "count"}
 
 	tests := []struct {
 		name          string
 		mockSetup     func()
-		expected      []*models.PVZWithReceptions
+		expected      *models.PVZCapacity
 		expectedError error
 	}{
 		{
-			name: "success",
+			name: "pvz with capacity",
 			mockSetup: func() {
-				dbMock.ExpectQuery(regexp.QuoteMeta(`
-					SELECT DISTINCT p.id FROM pvzs p 
-					LEFT JOIN receptions r ON r.pvz_id = p.id 
-					WHERE (r.date_time >= $1 AND r.date_time <= $2) 
-					LIMIT 10 OFFSET 0
-				`)).
-					WithArgs(startDate, endDate).
-					WillReturnRows(pgxmock.NewRows([]string{"id"}).AddRow(pvzID))
-
-				dbMock.ExpectQuery(regexp.QuoteMeta(`
-					SELECT p.id, p.city, p.registration_date, 
-						   r.id, r.date_time, r.status, 
-						   pr.id, pr.type, pr.date_time 
-					FROM pvzs p 
-					LEFT JOIN receptions r ON r.pvz_id = p.id 
-					LEFT JOIN products pr ON pr.reception_id = r.id 
-					WHERE p.id IN ($1) AND (r.date_time >= $2 AND r.date_time <= $3) 
-					ORDER BY r.date_time DESC
-				`)).
-					WithArgs(pvzID, startDate, endDate).
-					WillReturnRows(pgxmock.NewRows([]string{
-						"p.id", "p.city", "p.registration_date",
-						"r.id", "r.date_time", "r.status",
-						"pr.id", "pr.type", "pr.date_time",
-					}).AddRow(
-						pvzID, "Москва", regDate,
-						&receptionID, &recDate, &status,
-						&productID, &productType, &prodDate,
-					))
-			},
-			expected: []*models.PVZWithReceptions{
-				{
-					PVZ: models.PVZ{
-						ID:               pvzID,
-						City:             "Москва",
-						RegistrationDate: regDate,
-					},
-					Receptions: []*models.ReceptionWithProducts{
-						{
-							Reception: models.Reception{
-								ID:       receptionID,
-								DateTime: recDate,
-								Status:   status,
-								PvzID:    pvzID,
-							},
-							Products: []*models.Product{
-								{
-									ID:          productID,
-									Type:        productType,
-									DateTime:    prodDate,
-									ReceptionID: receptionID,
-								},
-							},
-						},
-					},
-				},
+				dbMock.ExpectQuery("SELECT p.id, p.capacity, p.reception_limit, \\(SELECT COUNT\\(\\*\\) FROM products pr .* FROM pvzs p WHERE p.id = \\$1").
+					WithArgs(pvzID, productsInPVZ).
+					WillReturnRows(pgxmock.NewRows(columns).AddRow(pvzID, &capacity, nil, 42))
 			},
+			expected:      &models.PVZCapacity{PvzID: pvzID, Capacity: &capacity, Stored: 42},
 			expectedError: nil,
 		},
+		{
+			name: "pvz not found",
+			mockSetup: func() {
+				dbMock.ExpectQuery("SELECT p.id, p.capacity, p.reception_limit").
+					WithArgs(pvzID, productsInPVZ).
+					WillReturnError(pgx.ErrNoRows)
+			},
+			expected:      nil,
+			expectedError: db.ErrPVZNotFound,
+		},
+		{
+			name: "query error",
+			mockSetup: func() {
+				dbMock.ExpectQuery("SELECT p.id, p.capacity, p.reception_limit").
+					WithArgs(pvzID, productsInPVZ).
+					WillReturnError(ErrRandomError)
+			},
+			expected:      nil,
+			expectedError: ErrRandomError,
+		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			tt.mockSetup()
 
-			ctx := context.Background()
-			result, err := repo.GetPVZs(ctx, &startDate, &endDate, limit, offset)
+			result, err := repo.GetPVZCapacity(context.Background(), pvzID)
 
 			if tt.expectedError != nil {
 				assert.ErrorIs(t, err, tt.expectedError)
 			} else {
 				assert.NoError(t, err)
-				assert.Equal(t, tt.expected, result)
 			}
+			assert.Equal(t, tt.expected, result)
+			assert.NoError(t, dbMock.ExpectationsWereMet())
 		})
 	}
 }
 
-func TestPVZRepo_GetPVZList(t *testing.T) {
+func TestPVZRepo_SetPVZCapacity(t *testing.T) {
 	dbMock, err := pgxmock.NewPool()
 	require.NoError(t, err)
 	defer dbMock.Close()
 
 	repo := NewPVZRepo(dbMock)
 
-	testPVZs := []models.PVZ{
+	pvzID := uuid.New()
+	capacity, receptionLimit := 100, 20
+
+	columns := []string{"id", "capacity", "reception_limit", "count"}
+
+	tests := []struct {
+		name           string
+		capacity       *int
+		receptionLimit *int
+		mockSetup      func()
+		expected       *models.PVZCapacity
+		expectedError  error
+	}{
 		{
-			ID:               uuid.New(),
-			City:             "Москва",
-			RegistrationDate: time.Now(),
+			name:           "set limits",
+			capacity:       &capacity,
+			receptionLimit: &receptionLimit,
+			mockSetup: func() {
+				dbMock.ExpectQuery("UPDATE pvzs p SET capacity = \\$2, reception_limit = \\$3 WHERE p.id = \\$1 RETURNING").
+					WithArgs(pvzID, &capacity, &receptionLimit, productsInPVZ).
+					WillReturnRows(pgxmock.NewRows(columns).AddRow(pvzID, &capacity, &receptionLimit, 7))
+			},
+			expected:      &models.PVZCapacity{PvzID: pvzID, Capacity: &capacity, ReceptionLimit: &receptionLimit, Stored: 7},
+			expectedError: nil,
 		},
 		{
-			ID:               uuid.New(),
-			City:             "Казань",
-			RegistrationDate: time.Now().Add(-24 * time.Hour),
+			name: "remove limits",
+			mockSetup: func() {
+				dbMock.ExpectQuery("UPDATE pvzs p SET capacity = \\$2, reception_limit = \\$3").
+					WithArgs(pvzID, (*int)(nil), (*int)(nil), productsInPVZ).
+					WillReturnRows(pgxmock.NewRows(columns).AddRow(pvzID, nil, nil, 7))
+			},
+			expected:      &models.PVZCapacity{PvzID: pvzID, Stored: 7},
+			expectedError: nil,
+		},
+		{
+			name:     "pvz not found",
+			capacity: &capacity,
+			mockSetup: func() {
+				dbMock.ExpectQuery("UPDATE pvzs p SET capacity = \\$2, reception_limit = \\$3").
+					WithArgs(pvzID, &capacity, (*int)(nil), productsInPVZ).
+					WillReturnError(pgx.ErrNoRows)
+			},
+			expected:      nil,
+			expectedError: db.ErrPVZNotFound,
 		},
+		{
+			name:     "query error",
+			capacity: &capacity,
+			mockSetup: func() {
+				dbMock.ExpectQuery("UPDATE pvzs p SET capacity = \\$2, reception_limit = \\$3").
+					WithArgs(pvzID, &capacity, (*int)(nil), productsInPVZ).
+					WillReturnError(ErrRandomError)
+			},
+			expected:      nil,
+			expectedError: ErrRandomError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockSetup()
+
+			result, err := repo.SetPVZCapacity(context.Background(), pvzID, tt.capacity, tt.receptionLimit)
+
+			if tt.expectedError != nil {
+				assert.ErrorIs(t, err, tt.expectedError)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, tt.expected, result)
+			assert.NoError(t, dbMock.ExpectationsWereMet())
+		})
 	}
+}
+
+func TestPVZRepo_GetPVZUtilization(t *testing.T) {
+	dbMock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer dbMock.Close()
+
+	repo := NewPVZRepo(dbMock)
+
+	firstID, secondID := uuid.New(), uuid.New()
+	firstCapacity, secondCapacity, receptionLimit := 100, 10, 5
+
+	columns := []string{"id", "capacity", "reception_limit", "count"}
 
 	tests := []struct {
-		name           string
-		mockSetup      func()
-		expectedResult []models.PVZ
-		expectedError  error
+		name          string
+		mockSetup     func()
+		expected      []models.PVZCapacity
+		expectedError error
 	}{
 		{
-			name: "successful get list",
+			name: "pvzs with capacity",
 			mockSetup: func() {
-				rows := pgxmock.NewRows([]string{"id", "city", "registration_date"}).
-					AddRow(testPVZs[0].ID, testPVZs[0].City, testPVZs[0].RegistrationDate).
-					AddRow(testPVZs[1].ID, testPVZs[1].City, testPVZs[1].RegistrationDate)
-				dbMock.ExpectQuery("SELECT id, city, registration_date FROM pvzs").
-					WillReturnRows(rows)
+				dbMock.ExpectQuery("SELECT p.id, p.capacity, p.reception_limit, COUNT\\(pr.id\\) FROM pvzs p .* WHERE p.capacity IS NOT NULL GROUP BY p.id").
+					WithArgs(productsInPVZ).
+					WillReturnRows(pgxmock.NewRows(columns).
+						AddRow(firstID, &firstCapacity, nil, 50).
+						AddRow(secondID, &secondCapacity, &receptionLimit, 0))
 			},
-			expectedResult: testPVZs,
-			expectedError:  nil,
+			expected: []models.PVZCapacity{
+				{PvzID: firstID, Capacity: &firstCapacity, Stored: 50},
+				{PvzID: secondID, Capacity: &secondCapacity, ReceptionLimit: &receptionLimit, Stored: 0},
+			},
+			expectedError: nil,
 		},
 		{
-			name: "empty list",
+			name: "no pvzs with capacity",
 			mockSetup: func() {
-				rows := pgxmock.NewRows([]string{"id", "city", "registration_date"})
-				dbMock.ExpectQuery("SELECT id, city, registration_date FROM pvzs").
-					WillReturnRows(rows)
+				dbMock.ExpectQuery("SELECT p.id, p.capacity, p.reception_limit, COUNT\\(pr.id\\)").
+					WithArgs(productsInPVZ).
+					WillReturnRows(pgxmock.NewRows(columns))
 			},
-			expectedResult: []models.PVZ(nil),
-			expectedError:  nil,
+			expected:      []models.PVZCapacity{},
+			expectedError: nil,
 		},
 		{
-			name: "database error",
+			name: "query error",
 			mockSetup: func() {
-				dbMock.ExpectQuery("SELECT id, city, registration_date FROM pvzs").
+				dbMock.ExpectQuery("SELECT p.id, p.capacity, p.reception_limit, COUNT\\(pr.id\\)").
+					WithArgs(productsInPVZ).
 					WillReturnError(ErrRandomError)
 			},
-			expectedResult: nil,
-			expectedError:  ErrRandomError,
+			expected:      nil,
+			expectedError: ErrRandomError,
 		},
 	}
 
@@ -853,15 +7017,15 @@ func TestPVZRepo_GetPVZList(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			tt.mockSetup()
 
-			result, err := repo.GetPVZList(context.Background())
+			result, err := repo.GetPVZUtilization(context.Background())
 
 			if tt.expectedError != nil {
-				assert.Error(t, err)
 				assert.ErrorIs(t, err, tt.expectedError)
 			} else {
 				assert.NoError(t, err)
-				assert.Equal(t, tt.expectedResult, result)
 			}
+			assert.Equal(t, tt.expected, result)
+			assert.NoError(t, dbMock.ExpectationsWereMet())
 		})
 	}
 }
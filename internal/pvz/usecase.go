@@ -8,18 +8,68 @@ import (
 
 	"github.com/cyansnbrst/pvz-service/gen/pvzapi"
 	"github.com/cyansnbrst/pvz-service/internal/models"
+	"github.com/cyansnbrst/pvz-service/pkg/auth/jwt"
 )
 
 // PVZ usecase interface
 type UseCase interface {
-	GenerateJWT(ctx context.Context, role pvzapi.UserRole) (string, error)
+	GenerateJWT(ctx context.Context, userID uuid.UUID, role pvzapi.UserRole) (string, error)
+	ParseJWT(ctx context.Context, token string) (jwt.Claims, error)
+	GetJWKS(ctx context.Context) ([]jwt.JWK, error)
 	Register(ctx context.Context, email, password, role string) (models.User, error)
-	Login(ctx context.Context, email, password string) (string, error)
+	Login(ctx context.Context, email, password, ip string) (models.TokenPair, error)
+	RefreshTokens(ctx context.Context, refreshToken string) (models.TokenPair, error)
+	Logout(ctx context.Context, userID, tokenID uuid.UUID, expiresAt time.Time, refreshToken *string) error
+	IsTokenRevoked(ctx context.Context, tokenID uuid.UUID) (bool, error)
+	DeleteExpiredTokens(ctx context.Context) (int64, error)
+	DeleteStaleLoginAttempts(ctx context.Context) (int64, error)
+	GetUsers(ctx context.Context, params pvzapi.GetUsersParams) ([]models.User, error)
+	GetUser(ctx context.Context, userID uuid.UUID) (models.User, error)
+	ChangeUserRole(ctx context.Context, actorID uuid.UUID, actorRole pvzapi.UserRole, userID uuid.UUID, role string) (models.User, error)
+	SetUserActive(ctx context.Context, actorID uuid.UUID, actorRole pvzapi.UserRole, userID uuid.UUID, active bool) (models.User, error)
+	IsUserDeactivated(ctx context.Context, userID uuid.UUID) (bool, error)
+	IsRoleChanged(ctx context.Context, userID uuid.UUID, role pvzapi.UserRole) (bool, error)
+	HasPermission(ctx context.Context, userID uuid.UUID, role pvzapi.UserRole, permission models.Permission) (bool, error)
+	GrantPermission(ctx context.Context, adminID, userID uuid.UUID, permission string) (models.PermissionGrant, error)
+	RevokePermission(ctx context.Context, userID uuid.UUID, permission string) error
+	GetUserPermissions(ctx context.Context, userID uuid.UUID) ([]models.PermissionGrant, error)
 	CreatePVZ(ctx context.Context, id *uuid.UUID, city string, registrationDate *time.Time) (models.PVZ, error)
-	CreateReception(ctx context.Context, pvzID uuid.UUID) (models.Reception, error)
-	AddProduct(ctx context.Context, pvzID uuid.UUID, productType string) (models.Product, error)
-	DeleteLastProduct(ctx context.Context, pvzID uuid.UUID) error
-	CloseLastReception(ctx context.Context, pvzID uuid.UUID) (models.Reception, error)
+	UpdatePVZ(ctx context.Context, pvzID uuid.UUID, city, status *string) (models.PVZ, error)
+	GetCities(ctx context.Context) ([]models.City, error)
+	CreateCity(ctx context.Context, name string) (models.City, error)
+	UpdateCity(ctx context.Context, cityID uuid.UUID, name string) (models.City, error)
+	DeleteCity(ctx context.Context, cityID uuid.UUID) error
+	GetProductTypes(ctx context.Context) ([]models.ProductType, error)
+	CreateProductType(ctx context.Context, name, displayName string) (models.ProductType, error)
+	RetireProductType(ctx context.Context, typeID uuid.UUID) error
+	CreateReception(ctx context.Context, userID, pvzID uuid.UUID, manifest []models.ManifestItem, barcodes []models.ManifestBarcode) (models.Reception, error)
+	AddProduct(ctx context.Context, userID, pvzID uuid.UUID, productType string, barcode *string, cellNumber *int) (models.Product, error)
+	AddProducts(ctx context.Context, userID, pvzID uuid.UUID, products []models.Product) ([]models.ProductBatchResult, error)
+	DeleteLastProduct(ctx context.Context, userID, pvzID uuid.UUID) error
+	DeleteProduct(ctx context.Context, userID, receptionID, productID uuid.UUID) error
+	IssueProduct(ctx context.Context, userID, productID uuid.UUID) (models.Product, error)
+	ReturnProduct(ctx context.Context, userID, productID uuid.UUID) (models.Product, error)
+	GeneratePickupCode(ctx context.Context, userID, productID uuid.UUID) (string, error)
+	PickupProduct(ctx context.Context, userID, productID uuid.UUID, code string) (models.Product, error)
+	TransferProducts(ctx context.Context, userID, receptionID, toPvzID uuid.UUID, productIDs []uuid.UUID) ([]models.ProductTransfer, error)
+	MoveProduct(ctx context.Context, userID, productID uuid.UUID, cellNumber int) (models.Product, error)
+	CloseLastReception(ctx context.Context, userID, pvzID uuid.UUID) (models.Reception, error)
+	CloseStaleReceptions(ctx context.Context) ([]models.Reception, error)
+	CancelLastReception(ctx context.Context, userID, pvzID uuid.UUID) (models.Reception, error)
+	ReopenReception(ctx context.Context, userID, receptionID uuid.UUID, reason string) (models.Reception, error)
 	GetPVZs(ctx context.Context, params pvzapi.GetPvzParams) ([]*models.PVZWithReceptions, error)
 	GetPVZList(ctx context.Context) ([]models.PVZ, error)
+	GetPVZ(ctx context.Context, pvzID uuid.UUID) (models.PVZDetails, error)
+	GetReception(ctx context.Context, receptionID uuid.UUID) (models.ReceptionWithProducts, error)
+	GetReceptionProducts(ctx context.Context, receptionID uuid.UUID, params pvzapi.GetReceptionsReceptionIdProductsParams) (models.ProductPage, error)
+	GetReceptionHistory(ctx context.Context, receptionID uuid.UUID) ([]models.ReceptionAuditRecord, error)
+	FindProductsByBarcode(ctx context.Context, barcode string) ([]models.ProductLocation, error)
+	AssignEmployee(ctx context.Context, pvzID, userID uuid.UUID) (models.EmployeeAssignment, error)
+	UnassignEmployee(ctx context.Context, pvzID, userID uuid.UUID) error
+	GetPVZEmployees(ctx context.Context, pvzID uuid.UUID) ([]models.User, error)
+	CreateCells(ctx context.Context, pvzID uuid.UUID, count, capacity int) ([]models.StorageCell, error)
+	GetCells(ctx context.Context, pvzID uuid.UUID) (models.CellOccupancy, error)
+	GetCapacity(ctx context.Context, pvzID uuid.UUID) (models.PVZCapacity, error)
+	SetCapacity(ctx context.Context, pvzID uuid.UUID, capacity, receptionLimit *int) (models.PVZCapacity, error)
+	GetUtilization(ctx context.Context) ([]models.PVZCapacity, error)
 }
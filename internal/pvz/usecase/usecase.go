@@ -2,8 +2,16 @@ package usecase
 
 import (
 	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
 	"errors"
 	"fmt"
+	"math/big"
+	"slices"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/alexedwards/argon2id"
@@ -14,44 +22,117 @@ import (
 	"github.com/cyansnbrst/pvz-service/gen/pvzapi"
 	"github.com/cyansnbrst/pvz-service/internal/models"
 	"github.com/cyansnbrst/pvz-service/internal/pvz"
+	authjwt "github.com/cyansnbrst/pvz-service/pkg/auth/jwt"
+	"github.com/cyansnbrst/pvz-service/pkg/cache"
 	"github.com/cyansnbrst/pvz-service/pkg/db"
 )
 
 // PVZ usecase struct
 type pvzUC struct {
-	cfg     *config.Config
-	pvzRepo pvz.Repository
+	cfg         *config.Config
+	pvzRepo     pvz.Repository
+	cities      *cache.Value[map[string]bool]
+	types       *cache.Value[map[string]bool]
+	revoked     *cache.Value[map[uuid.UUID]bool]
+	keys        *cache.Value[*authjwt.KeySet]
+	grants      *cache.Value[map[uuid.UUID]map[models.Permission]bool]
+	deactivated *cache.Value[map[uuid.UUID]bool]
+	roles       *cache.Value[map[uuid.UUID]pvzapi.UserRole]
+	now         func() time.Time
 }
 
 var (
 	ErrIncorrectPassword = errors.New("incorrect password")
 	ErrInvalidRole       = errors.New("invalid role")
 	ErrInvalidCity       = errors.New("invalid city")
+	ErrInvalidStatus     = errors.New("invalid status")
 	ErrInvalidType       = errors.New("invalid type")
 	ErrInvalidDateRange  = errors.New("invalid date range")
+	ErrInvalidManifest   = errors.New("invalid manifest")
+	ErrInvalidBarcode    = errors.New("invalid barcode")
+	ErrPVZAccessDenied   = errors.New("employee is not assigned to the pvz")
+	ErrInvalidPickupCode = errors.New("invalid pickup code")
+	ErrPickupLocked      = errors.New("too many wrong pickup codes, try again later")
+	ErrSameTransferPVZ   = errors.New("products cannot be transferred to their own pvz")
+	ErrInvalidCellNumber = errors.New("invalid cell number")
+	ErrInvalidCapacity   = errors.New("capacity limits must be positive")
+
+	ErrInvalidRefreshToken = errors.New("refresh token is invalid, expired or already used")
+	ErrInvalidPermission   = errors.New("invalid permission")
+	ErrUserDeactivated     = errors.New("user is deactivated")
+	ErrSelfManagement      = errors.New("users cannot change their own role or deactivate themselves")
+	ErrAdminRequired       = errors.New("only admins can manage admin accounts")
+	ErrInvalidCredentials  = errors.New("invalid email or password")
+	ErrLoginLocked         = errors.New("too many failed login attempts, try again later")
+)
+
+// Number of distinct six-digit pickup codes
+const pickupCodeSpace = 1_000_000
+
+// Number of random bytes in a refresh token
+const refreshTokenSize = 32
+
+// Prefixes of the keys failed logins are counted by
+const (
+	loginAccountKeyPrefix = "account:"
+	loginIPKeyPrefix      = "ip:"
 )
 
+// Hash checked against when the email is unknown, so such logins take as long as wrong passwords
+var dummyPasswordHash = sync.OnceValues(func() (string, error) {
+	return argon2id.CreateHash("dummy-password", argon2id.DefaultParams)
+})
+
+// Failed login counter key with the number of failures that locks it
+type loginLimit struct {
+	key         string
+	maxAttempts int
+}
+
+// Product statuses each status can be reached from
+var productTransitions = map[pvzapi.ProductStatus][]string{
+	pvzapi.Issued:   {string(pvzapi.Stored)},
+	pvzapi.Returned: {string(pvzapi.Stored), string(pvzapi.Issued)},
+}
+
 // PVZ usecase constructor
 func NewPVZUseCase(cfg *config.Config, pvzRepo pvz.Repository) pvz.UseCase {
-	return &pvzUC{
+	u := &pvzUC{
 		cfg:     cfg,
 		pvzRepo: pvzRepo,
+		now:     time.Now,
 	}
+	u.cities = cache.NewValue(cfg.App.CacheTTL, u.loadCities)
+	u.types = cache.NewValue(cfg.App.CacheTTL, u.loadProductTypes)
+	u.revoked = cache.NewValue(cfg.App.CacheTTL, u.loadRevokedTokens)
+	u.keys = cache.NewValue(cfg.App.CacheTTL, u.loadKeys)
+	u.grants = cache.NewValue(cfg.App.CacheTTL, u.loadPermissionGrants)
+	u.deactivated = cache.NewValue(cfg.App.CacheTTL, u.loadDeactivatedUsers)
+	u.roles = cache.NewValue(cfg.App.CacheTTL, u.loadUserRoles)
+
+	return u
 }
 
-// Generates JWT token for the given role
-func (u *pvzUC) GenerateJWT(ctx context.Context, role pvzapi.UserRole) (string, error) {
+// Generates JWT token for the given user and role, uuid.Nil user produces a token without subject
+func (u *pvzUC) GenerateJWT(ctx context.Context, userID uuid.UUID, role pvzapi.UserRole) (string, error) {
 	const op = "PVZ.GenerateJWT"
 
 	expirationTime := jwt.NewNumericDate(time.Now().Add(u.cfg.App.JWTTokenTTL))
 	claims := jwt.MapClaims{
+		"jti":  uuid.New().String(),
 		"role": role,
 		"exp":  expirationTime,
 	}
+	if userID != uuid.Nil {
+		claims["sub"] = userID.String()
+	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	keys, err := u.keys.Get(ctx)
+	if err != nil {
+		return "", fmt.Errorf("%s: %w", op, err)
+	}
 
-	signedToken, err := token.SignedString([]byte(u.cfg.App.JWTSecretKey))
+	signedToken, err := authjwt.SignJWT(claims, keys)
 	if err != nil {
 		return "", fmt.Errorf("%s: %w", op, err)
 	}
@@ -59,6 +140,35 @@ func (u *pvzUC) GenerateJWT(ctx context.Context, role pvzapi.UserRole) (string,
 	return signedToken, nil
 }
 
+// Parse and verify JWT token with the current key set
+func (u *pvzUC) ParseJWT(ctx context.Context, token string) (authjwt.Claims, error) {
+	const op = "PVZ.ParseJWT"
+
+	keys, err := u.keys.Get(ctx)
+	if err != nil {
+		return authjwt.Claims{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return authjwt.ParseJWT(token, keys)
+}
+
+// Get the public verification keys
+func (u *pvzUC) GetJWKS(ctx context.Context) ([]authjwt.JWK, error) {
+	const op = "PVZ.GetJWKS"
+
+	keys, err := u.keys.Get(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return keys.JWKS(), nil
+}
+
+// Load the signing and verification keys, reloading picks up rotated key files
+func (u *pvzUC) loadKeys(ctx context.Context) (*authjwt.KeySet, error) {
+	return authjwt.LoadKeySet(u.cfg.App.JWTSecretKey, u.cfg.App.JWTKeysDir, u.cfg.App.JWTSigningKeyID, u.cfg.App.JWTHMACFallback)
+}
+
 // Register user
 func (u *pvzUC) Register(ctx context.Context, email, password, role string) (models.User, error) {
 	const op = "PVZ.Register"
@@ -87,189 +197,1619 @@ func (u *pvzUC) Register(ctx context.Context, email, password, role string) (mod
 	return newUser, nil
 }
 
-// Login user
-func (u *pvzUC) Login(ctx context.Context, email, password string) (string, error) {
+// Login user, issues an access token and a refresh token
+func (u *pvzUC) Login(ctx context.Context, email, password, ip string) (models.TokenPair, error) {
 	const op = "PVZ.Login"
 
+	now := u.now()
+	limits := u.loginLimits(email, ip)
+
+	// Attempts are counted before the password is checked, so parallel guesses are bounded by the limits.
+	// Locked logins are rejected without checking the password and do not extend the lockout.
+	failures, err := u.claimLoginAttempts(ctx, limits, now)
+	if err != nil {
+		if errors.Is(err, ErrLoginLocked) {
+			return models.TokenPair{}, err
+		}
+		return models.TokenPair{}, fmt.Errorf("%s: %w", op, err)
+	}
+
 	user, err := u.pvzRepo.GetUserByEmail(ctx, email)
 	if err != nil {
 		if errors.Is(err, db.ErrUserNotFound) {
-			return "", err
+			hash, err := dummyPasswordHash()
+			if err != nil {
+				return models.TokenPair{}, fmt.Errorf("%s: %w", op, err)
+			}
+			if _, err := argon2id.ComparePasswordAndHash(password, hash); err != nil {
+				return models.TokenPair{}, fmt.Errorf("%s: %w", op, err)
+			}
+			return models.TokenPair{}, u.failLogin(ctx, limits, failures, now)
 		}
-		return "", fmt.Errorf("%s: %w", op, err)
+		return models.TokenPair{}, fmt.Errorf("%s: %w", op, err)
 	}
 
 	err = u.validatePassword(user, password)
 	if err != nil {
 		if errors.Is(err, ErrIncorrectPassword) {
-			return "", err
+			return models.TokenPair{}, u.failLogin(ctx, limits, failures, now)
 		}
-		return "", fmt.Errorf("%s: %w", op, err)
+		return models.TokenPair{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	if user.DeactivatedAt != nil {
+		if err := u.releaseLoginAttempts(ctx, limits); err != nil {
+			return models.TokenPair{}, fmt.Errorf("%s: %w", op, err)
+		}
+		return models.TokenPair{}, ErrUserDeactivated
+	}
+
+	// The account counter is reset, earlier failures from the ip keep counting towards its limit
+	if err := u.pvzRepo.ResetLoginFailures(ctx, limits[0].key); err != nil {
+		return models.TokenPair{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	if err := u.releaseLoginAttempts(ctx, limits[1:]); err != nil {
+		return models.TokenPair{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	if err := u.pvzRepo.UpdateLastLogin(ctx, user.ID); err != nil {
+		return models.TokenPair{}, fmt.Errorf("%s: %w", op, err)
 	}
 
-	return u.GenerateJWT(ctx, pvzapi.UserRole(user.Role))
+	accessToken, err := u.GenerateJWT(ctx, user.ID, pvzapi.UserRole(user.Role))
+	if err != nil {
+		return models.TokenPair{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	token, refreshToken, err := u.newRefreshToken(user.ID)
+	if err != nil {
+		return models.TokenPair{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	if err := u.pvzRepo.CreateRefreshToken(ctx, token); err != nil {
+		return models.TokenPair{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return models.TokenPair{AccessToken: accessToken, RefreshToken: refreshToken}, nil
 }
 
-// Validate password
-func (u *pvzUC) validatePassword(user *models.User, password string) error {
-	const op = "PVZ.ValidatePassword"
+// Exchange the refresh token for a new token pair, the presented token is revoked
+func (u *pvzUC) RefreshTokens(ctx context.Context, refreshToken string) (models.TokenPair, error) {
+	const op = "PVZ.RefreshTokens"
 
-	match, err := argon2id.ComparePasswordAndHash(password, user.PasswordHash)
+	token, err := u.pvzRepo.GetRefreshToken(ctx, hashRefreshToken(refreshToken))
 	if err != nil {
-		return fmt.Errorf("%s: %w", op, err)
+		if errors.Is(err, db.ErrRefreshTokenNotFound) {
+			return models.TokenPair{}, ErrInvalidRefreshToken
+		}
+		return models.TokenPair{}, fmt.Errorf("%s: %w", op, err)
 	}
 
-	if !match {
-		return ErrIncorrectPassword
+	// A used token presented again is treated as stolen, so every session of the user is ended
+	if token.RevokedAt != nil {
+		return models.TokenPair{}, u.revokeUserRefreshTokens(ctx, token.UserID)
+	}
+
+	if !u.now().Before(token.ExpiresAt) {
+		return models.TokenPair{}, ErrInvalidRefreshToken
+	}
+
+	newToken, newRefreshToken, err := u.newRefreshToken(token.UserID)
+	if err != nil {
+		return models.TokenPair{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	err = u.pvzRepo.RotateRefreshToken(ctx, token.ID, newToken)
+	if err != nil {
+		if errors.Is(err, db.ErrRefreshTokenRevoked) {
+			return models.TokenPair{}, u.revokeUserRefreshTokens(ctx, token.UserID)
+		}
+		return models.TokenPair{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	accessToken, err := u.GenerateJWT(ctx, token.UserID, pvzapi.UserRole(token.UserRole))
+	if err != nil {
+		return models.TokenPair{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return models.TokenPair{AccessToken: accessToken, RefreshToken: newRefreshToken}, nil
+}
+
+// Revoke the access token and, if given, the refresh token of the user
+func (u *pvzUC) Logout(ctx context.Context, userID, tokenID uuid.UUID, expiresAt time.Time, refreshToken *string) error {
+	const op = "PVZ.Logout"
+
+	// Tokens issued before token ids were introduced cannot be denylisted and simply expire
+	if tokenID != uuid.Nil {
+		if err := u.pvzRepo.RevokeAccessToken(ctx, tokenID, expiresAt); err != nil {
+			return fmt.Errorf("%s: %w", op, err)
+		}
+		u.revoked.Invalidate()
+	}
+
+	if refreshToken != nil && userID != uuid.Nil {
+		if err := u.pvzRepo.RevokeRefreshToken(ctx, userID, hashRefreshToken(*refreshToken)); err != nil {
+			return fmt.Errorf("%s: %w", op, err)
+		}
 	}
 
 	return nil
 }
 
-// Create PVZ
-func (u *pvzUC) CreatePVZ(ctx context.Context, id *uuid.UUID, city string, registrationDate *time.Time) (models.PVZ, error) {
-	const op = "PVZ.CreatePVZ"
+// Check whether the access token id is in the denylist
+func (u *pvzUC) IsTokenRevoked(ctx context.Context, tokenID uuid.UUID) (bool, error) {
+	const op = "PVZ.IsTokenRevoked"
 
-	var pvzID uuid.UUID
-	if id == nil {
-		pvzID = uuid.New()
-	} else {
-		pvzID = *id
+	revoked, err := u.revoked.Get(ctx)
+	if err != nil {
+		return false, fmt.Errorf("%s: %w", op, err)
 	}
 
-	var regDate time.Time
-	if registrationDate == nil {
-		regDate = time.Now()
-	} else {
-		regDate = *registrationDate
+	return revoked[tokenID], nil
+}
+
+// Delete expired refresh tokens and denylist entries
+func (u *pvzUC) DeleteExpiredTokens(ctx context.Context) (int64, error) {
+	const op = "PVZ.DeleteExpiredTokens"
+
+	deleted, err := u.pvzRepo.DeleteExpiredTokens(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
 	}
 
-	newPVZ := models.PVZ{
-		ID:               pvzID,
-		City:             city,
-		RegistrationDate: regDate,
+	return deleted, nil
+}
+
+// Delete failed login attempts older than the failure window, returns the number of deleted attempts
+func (u *pvzUC) DeleteStaleLoginAttempts(ctx context.Context) (int64, error) {
+	const op = "PVZ.DeleteStaleLoginAttempts"
+
+	deleted, err := u.pvzRepo.DeleteStaleLoginAttempts(ctx, u.now().Add(-u.cfg.App.LoginFailureWindow))
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
 	}
 
-	err := u.pvzRepo.CreatePVZ(ctx, newPVZ)
+	return deleted, nil
+}
+
+// Get a page of users, optionally filtered by email substring and role
+func (u *pvzUC) GetUsers(ctx context.Context, params pvzapi.GetUsersParams) ([]models.User, error) {
+	const op = "PVZ.GetUsers"
+
+	page, limit := 1, 20
+	if params.Page != nil && *params.Page > 0 {
+		page = *params.Page
+	}
+	if params.Limit != nil && *params.Limit > 0 && *params.Limit <= 100 {
+		limit = *params.Limit
+	}
+
+	var search *string
+	if params.Search != nil && *params.Search != "" {
+		search = params.Search
+	}
+
+	var role *string
+	if params.Role != nil {
+		r := string(*params.Role)
+		role = &r
+	}
+
+	offset := uint64((page - 1) * limit) //nolint:gosec
+
+	users, err := u.pvzRepo.GetUsers(ctx, search, role, uint64(limit), offset)
 	if err != nil {
-		if errors.Is(err, db.ErrDuplicatePVZ) {
-			return models.PVZ{}, err
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return users, nil
+}
+
+// Get the user by id
+func (u *pvzUC) GetUser(ctx context.Context, userID uuid.UUID) (models.User, error) {
+	const op = "PVZ.GetUser"
+
+	user, err := u.pvzRepo.GetUser(ctx, userID)
+	if err != nil {
+		if errors.Is(err, db.ErrUserNotFound) {
+			return models.User{}, err
 		}
-		return models.PVZ{}, fmt.Errorf("%s: %w", op, err)
+		return models.User{}, fmt.Errorf("%s: %w", op, err)
 	}
 
-	return newPVZ, nil
+	return *user, nil
 }
 
-// Create a new reception for the pvz
-func (u *pvzUC) CreateReception(ctx context.Context, pvzID uuid.UUID) (models.Reception, error) {
-	const op = "PVZ.CreateReception"
+// Change the role of the user on behalf of the actor, tokens issued for the old role stop working
+func (u *pvzUC) ChangeUserRole(ctx context.Context, actorID uuid.UUID, actorRole pvzapi.UserRole, userID uuid.UUID, role string) (models.User, error) {
+	const op = "PVZ.ChangeUserRole"
 
-	uuid := uuid.New()
+	if _, ok := models.RolePermissions[role]; !ok {
+		return models.User{}, ErrInvalidRole
+	}
+
+	if err := u.checkUserManagement(ctx, actorID, actorRole, userID, role); err != nil {
+		if errors.Is(err, ErrSelfManagement) || errors.Is(err, ErrAdminRequired) || errors.Is(err, db.ErrUserNotFound) {
+			return models.User{}, err
+		}
+		return models.User{}, fmt.Errorf("%s: %w", op, err)
+	}
 
-	reception, err := u.pvzRepo.CreateReception(ctx, uuid, pvzID)
+	user, err := u.pvzRepo.UpdateUserRole(ctx, userID, role)
 	if err != nil {
-		if errors.Is(err, db.ErrReceptionConflict) {
-			return models.Reception{}, err
+		if errors.Is(err, db.ErrUserNotFound) {
+			return models.User{}, err
 		}
-		return models.Reception{}, fmt.Errorf("%s: %w", op, err)
+		return models.User{}, fmt.Errorf("%s: %w", op, err)
 	}
 
-	return *reception, nil
+	if err := u.pvzRepo.RevokeUserRefreshTokens(ctx, userID); err != nil {
+		return models.User{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	u.roles.Invalidate()
+
+	return *user, nil
 }
 
-// Add a new product for the reception
-func (u *pvzUC) AddProduct(ctx context.Context, pvzID uuid.UUID, productType string) (models.Product, error) {
-	const op = "PVZ.AddProduct"
+// Activate or deactivate the user on behalf of the actor, deactivation ends every session of the user
+func (u *pvzUC) SetUserActive(ctx context.Context, actorID uuid.UUID, actorRole pvzapi.UserRole, userID uuid.UUID, active bool) (models.User, error) {
+	const op = "PVZ.SetUserActive"
 
-	uuid := uuid.New()
+	if err := u.checkUserManagement(ctx, actorID, actorRole, userID, ""); err != nil {
+		if errors.Is(err, ErrSelfManagement) || errors.Is(err, ErrAdminRequired) || errors.Is(err, db.ErrUserNotFound) {
+			return models.User{}, err
+		}
+		return models.User{}, fmt.Errorf("%s: %w", op, err)
+	}
 
-	product, err := u.pvzRepo.AddProduct(ctx, uuid, pvzID, productType)
+	user, err := u.pvzRepo.SetUserDeactivated(ctx, userID, !active)
 	if err != nil {
-		if errors.Is(err, db.ErrNoOpenReception) {
-			return models.Product{}, err
+		if errors.Is(err, db.ErrUserNotFound) {
+			return models.User{}, err
 		}
-		return models.Product{}, fmt.Errorf("%s: %w", op, err)
+		return models.User{}, fmt.Errorf("%s: %w", op, err)
 	}
 
-	return *product, nil
+	if !active {
+		if err := u.pvzRepo.RevokeUserRefreshTokens(ctx, userID); err != nil {
+			return models.User{}, fmt.Errorf("%s: %w", op, err)
+		}
+	}
+
+	u.deactivated.Invalidate()
+
+	return *user, nil
 }
 
-// Delete the last product from the reception
-func (u *pvzUC) DeleteLastProduct(ctx context.Context, pvzID uuid.UUID) error {
-	const op = "PVZ.DeleteLastProduct"
+// Check whether the user account is deactivated
+func (u *pvzUC) IsUserDeactivated(ctx context.Context, userID uuid.UUID) (bool, error) {
+	const op = "PVZ.IsUserDeactivated"
 
-	err := u.pvzRepo.DeleteLastProduct(ctx, pvzID)
+	deactivated, err := u.deactivated.Get(ctx)
 	if err != nil {
-		if errors.Is(err, db.ErrNoOpenReception) || errors.Is(err, db.ErrNoProducts) {
-			return err
-		}
-		return fmt.Errorf("%s: %w", op, err)
+		return false, fmt.Errorf("%s: %w", op, err)
 	}
 
-	return nil
+	return deactivated[userID], nil
 }
 
-// Close the last reception in the pvz
-func (u *pvzUC) CloseLastReception(ctx context.Context, pvzID uuid.UUID) (models.Reception, error) {
-	const op = "PVZ.CloseLastReception"
+// Check whether the role of the user differs from the role the token was issued for
+func (u *pvzUC) IsRoleChanged(ctx context.Context, userID uuid.UUID, role pvzapi.UserRole) (bool, error) {
+	const op = "PVZ.IsRoleChanged"
 
-	reception, err := u.pvzRepo.CloseLastReception(ctx, pvzID)
+	roles, err := u.roles.Get(ctx)
 	if err != nil {
-		if errors.Is(err, db.ErrNoOpenReception) {
-			return models.Reception{}, err
-		}
-		return models.Reception{}, fmt.Errorf("%s: %w", op, err)
+		return false, fmt.Errorf("%s: %w", op, err)
 	}
 
-	return *reception, nil
+	current, ok := roles[userID]
+	return ok && current != role, nil
 }
 
-// List of PVZs with their receptions and products
-func (u *pvzUC) GetPVZs(ctx context.Context, params pvzapi.GetPvzParams) ([]*models.PVZWithReceptions, error) {
-	const op = "PVZ.GetPVZs"
+// Check whether the user has the permission through the role or an individual grant
+func (u *pvzUC) HasPermission(ctx context.Context, userID uuid.UUID, role pvzapi.UserRole, permission models.Permission) (bool, error) {
+	const op = "PVZ.HasPermission"
 
-	if params.Page == nil {
-		defaultPage := 1
-		params.Page = &defaultPage
+	if slices.Contains(models.RolePermissions[string(role)], permission) {
+		return true, nil
 	}
-	if params.Limit == nil {
-		defaultLimit := 10
-		params.Limit = &defaultLimit
+
+	// Tokens from dummy login carry no user id and get the permissions of the role only
+	if userID == uuid.Nil {
+		return false, nil
 	}
 
-	if *params.Page < 1 {
-		*params.Page = 1
+	grants, err := u.grants.Get(ctx)
+	if err != nil {
+		return false, fmt.Errorf("%s: %w", op, err)
 	}
-	if *params.Limit < 1 || *params.Limit > 30 {
-		*params.Limit = 10
+
+	return grants[userID][permission], nil
+}
+
+// Grant the permission to the user
+func (u *pvzUC) GrantPermission(ctx context.Context, adminID, userID uuid.UUID, permission string) (models.PermissionGrant, error) {
+	const op = "PVZ.GrantPermission"
+
+	if !slices.Contains(models.Permissions, models.Permission(permission)) {
+		return models.PermissionGrant{}, ErrInvalidPermission
 	}
 
-	if params.StartDate != nil && params.EndDate != nil && params.StartDate.After(*params.EndDate) {
-		return nil, ErrInvalidDateRange
+	grant, err := u.pvzRepo.GrantPermission(ctx, userID, models.Permission(permission), adminID)
+	if err != nil {
+		if errors.Is(err, db.ErrUserNotFound) {
+			return models.PermissionGrant{}, err
+		}
+		return models.PermissionGrant{}, fmt.Errorf("%s: %w", op, err)
 	}
 
-	offset := (*params.Page - 1) * *params.Limit
+	u.grants.Invalidate()
 
-	limitU := uint64(*params.Limit)
-	offsetU := uint64(offset) //nolint:gosec
+	return *grant, nil
+}
+
+// Revoke the permission granted to the user
+func (u *pvzUC) RevokePermission(ctx context.Context, userID uuid.UUID, permission string) error {
+	const op = "PVZ.RevokePermission"
+
+	if !slices.Contains(models.Permissions, models.Permission(permission)) {
+		return ErrInvalidPermission
+	}
 
-	pvzs, err := u.pvzRepo.GetPVZs(ctx, params.StartDate, params.EndDate, limitU, offsetU)
+	err := u.pvzRepo.RevokePermission(ctx, userID, models.Permission(permission))
 	if err != nil {
-		return nil, fmt.Errorf("%s: %w", op, err)
+		if errors.Is(err, db.ErrPermissionNotGranted) {
+			return err
+		}
+		return fmt.Errorf("%s: %w", op, err)
 	}
 
-	return pvzs, nil
+	u.grants.Invalidate()
+
+	return nil
 }
 
-// List of all created PVZs
-func (u *pvzUC) GetPVZList(ctx context.Context) ([]models.PVZ, error) {
-	const op = "PVZ.GetPVZList"
+// Get the permissions granted to the user
+func (u *pvzUC) GetUserPermissions(ctx context.Context, userID uuid.UUID) ([]models.PermissionGrant, error) {
+	const op = "PVZ.GetUserPermissions"
 
-	pvzs, err := u.pvzRepo.GetPVZList(ctx)
+	grants, err := u.pvzRepo.GetUserPermissions(ctx, userID)
 	if err != nil {
+		if errors.Is(err, db.ErrUserNotFound) {
+			return nil, err
+		}
 		return nil, fmt.Errorf("%s: %w", op, err)
 	}
 
-	return pvzs, nil
+	return grants, nil
+}
+
+// Load the permissions granted to users
+func (u *pvzUC) loadPermissionGrants(ctx context.Context) (map[uuid.UUID]map[models.Permission]bool, error) {
+	list, err := u.pvzRepo.GetPermissionGrants(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	grants := make(map[uuid.UUID]map[models.Permission]bool)
+	for _, g := range list {
+		if grants[g.UserID] == nil {
+			grants[g.UserID] = make(map[models.Permission]bool)
+		}
+		grants[g.UserID][g.Permission] = true
+	}
+
+	return grants, nil
+}
+
+// Create a refresh token for the user, returns the stored token and its plain value
+func (u *pvzUC) newRefreshToken(userID uuid.UUID) (models.RefreshToken, string, error) {
+	buf := make([]byte, refreshTokenSize)
+	if _, err := rand.Read(buf); err != nil {
+		return models.RefreshToken{}, "", err
+	}
+	value := base64.RawURLEncoding.EncodeToString(buf)
+
+	token := models.RefreshToken{
+		ID:        uuid.New(),
+		UserID:    userID,
+		TokenHash: hashRefreshToken(value),
+		ExpiresAt: u.now().Add(u.cfg.App.RefreshTokenTTL),
+	}
+
+	return token, value, nil
+}
+
+// Revoke every refresh token of the user after a reused token was presented
+func (u *pvzUC) revokeUserRefreshTokens(ctx context.Context, userID uuid.UUID) error {
+	const op = "PVZ.revokeUserRefreshTokens"
+
+	if err := u.pvzRepo.RevokeUserRefreshTokens(ctx, userID); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return ErrInvalidRefreshToken
+}
+
+// Load ids of the revoked access tokens
+func (u *pvzUC) loadRevokedTokens(ctx context.Context) (map[uuid.UUID]bool, error) {
+	tokenIDs, err := u.pvzRepo.GetRevokedAccessTokens(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	revoked := make(map[uuid.UUID]bool, len(tokenIDs))
+	for _, id := range tokenIDs {
+		revoked[id] = true
+	}
+
+	return revoked, nil
+}
+
+// Load ids of the deactivated users
+func (u *pvzUC) loadDeactivatedUsers(ctx context.Context) (map[uuid.UUID]bool, error) {
+	userIDs, err := u.pvzRepo.GetDeactivatedUsers(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	deactivated := make(map[uuid.UUID]bool, len(userIDs))
+	for _, id := range userIDs {
+		deactivated[id] = true
+	}
+
+	return deactivated, nil
+}
+
+// Load the current roles of the users
+func (u *pvzUC) loadUserRoles(ctx context.Context) (map[uuid.UUID]pvzapi.UserRole, error) {
+	users, err := u.pvzRepo.GetUserRoles(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	roles := make(map[uuid.UUID]pvzapi.UserRole, len(users))
+	for _, user := range users {
+		roles[user.ID] = pvzapi.UserRole(user.Role)
+	}
+
+	return roles, nil
+}
+
+// Check that the actor may manage the user, admin accounts and the admin role are managed by admins only
+func (u *pvzUC) checkUserManagement(ctx context.Context, actorID uuid.UUID, actorRole pvzapi.UserRole, userID uuid.UUID, role string) error {
+	if actorID == userID {
+		return ErrSelfManagement
+	}
+
+	user, err := u.pvzRepo.GetUser(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	if user.Role != string(pvzapi.UserRoleAdmin) && role != string(pvzapi.UserRoleAdmin) {
+		return nil
+	}
+
+	allowed, err := u.HasPermission(ctx, actorID, actorRole, models.PermPermissionsManage)
+	if err != nil {
+		return err
+	}
+	if !allowed {
+		return ErrAdminRequired
+	}
+
+	return nil
+}
+
+// Hash the refresh token for storage, tokens are random so a fast hash is enough
+func hashRefreshToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// Validate password
+func (u *pvzUC) validatePassword(user *models.User, password string) error {
+	const op = "PVZ.ValidatePassword"
+
+	match, err := argon2id.ComparePasswordAndHash(password, user.PasswordHash)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	if !match {
+		return ErrIncorrectPassword
+	}
+
+	return nil
+}
+
+// Failed login counters of the account and the client ip, the account comes first
+func (u *pvzUC) loginLimits(email, ip string) []loginLimit {
+	limits := []loginLimit{{
+		key:         loginAccountKeyPrefix + strings.ToLower(email),
+		maxAttempts: u.cfg.App.LoginMaxAttempts,
+	}}
+	if ip != "" {
+		limits = append(limits, loginLimit{
+			key:         loginIPKeyPrefix + ip,
+			maxAttempts: u.cfg.App.LoginMaxAttemptsPerIP,
+		})
+	}
+
+	return limits
+}
+
+// Claim an attempt on every login counter, returns the failures counted so far including the claimed attempt
+func (u *pvzUC) claimLoginAttempts(ctx context.Context, limits []loginLimit, now time.Time) ([]int, error) {
+	const op = "PVZ.ClaimLoginAttempts"
+
+	failures := make([]int, 0, len(limits))
+	for i, limit := range limits {
+		count, err := u.pvzRepo.ClaimLoginAttempt(ctx, limit.key, limit.maxAttempts, now, now.Add(-u.cfg.App.LoginFailureWindow))
+		if err != nil {
+			if errors.Is(err, db.ErrNoLoginAttemptsLeft) {
+				// Attempts already claimed on the other counters are given back
+				if err := u.releaseLoginAttempts(ctx, limits[:i]); err != nil {
+					return nil, fmt.Errorf("%s: %w", op, err)
+				}
+				return nil, ErrLoginLocked
+			}
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+		failures = append(failures, count)
+	}
+
+	return failures, nil
+}
+
+// Give back the attempts claimed on the login counters
+func (u *pvzUC) releaseLoginAttempts(ctx context.Context, limits []loginLimit) error {
+	const op = "PVZ.ReleaseLoginAttempts"
+
+	for _, limit := range limits {
+		if err := u.pvzRepo.ReleaseLoginAttempt(ctx, limit.key); err != nil {
+			return fmt.Errorf("%s: %w", op, err)
+		}
+	}
+
+	return nil
+}
+
+// Lock the counters whose claimed attempts reached the limit, returns ErrLoginLocked if any of them got locked
+func (u *pvzUC) failLogin(ctx context.Context, limits []loginLimit, failures []int, now time.Time) error {
+	const op = "PVZ.FailLogin"
+
+	locked := false
+	for i, limit := range limits {
+		lockedUntil := u.loginLockedUntil(failures[i], limit.maxAttempts, now)
+		if !now.Before(lockedUntil) {
+			continue
+		}
+		if err := u.pvzRepo.LockLoginAttempts(ctx, limit.key, lockedUntil); err != nil {
+			return fmt.Errorf("%s: %w", op, err)
+		}
+		locked = true
+	}
+
+	if locked {
+		return ErrLoginLocked
+	}
+
+	return ErrInvalidCredentials
+}
+
+// Lockout end for the counter, the lockout doubles with every failure past the limit up to the maximum
+func (u *pvzUC) loginLockedUntil(failures, maxAttempts int, failedAt time.Time) time.Time {
+	if failures < maxAttempts {
+		return time.Time{}
+	}
+
+	lockout := u.cfg.App.LoginLockout
+	for i := maxAttempts; i < failures && lockout < u.cfg.App.LoginMaxLockout; i++ {
+		lockout *= 2
+	}
+
+	return failedAt.Add(min(lockout, u.cfg.App.LoginMaxLockout))
+}
+
+// Create PVZ
+func (u *pvzUC) CreatePVZ(ctx context.Context, id *uuid.UUID, city string, registrationDate *time.Time) (models.PVZ, error) {
+	const op = "PVZ.CreatePVZ"
+
+	var pvzID uuid.UUID
+	if id == nil {
+		pvzID = uuid.New()
+	} else {
+		pvzID = *id
+	}
+
+	var regDate time.Time
+	if registrationDate == nil {
+		regDate = time.Now()
+	} else {
+		regDate = *registrationDate
+	}
+
+	if err := u.validateCity(ctx, city); err != nil {
+		return models.PVZ{}, err
+	}
+
+	newPVZ := models.PVZ{
+		ID:               pvzID,
+		City:             city,
+		RegistrationDate: regDate,
+		Status:           string(pvzapi.Active),
+	}
+
+	err := u.pvzRepo.CreatePVZ(ctx, newPVZ)
+	if err != nil {
+		if errors.Is(err, db.ErrDuplicatePVZ) {
+			return models.PVZ{}, err
+		}
+		if errors.Is(err, db.ErrCityNotFound) {
+			u.cities.Invalidate()
+			return models.PVZ{}, ErrInvalidCity
+		}
+		return models.PVZ{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return newPVZ, nil
+}
+
+// Update PVZ city and/or status
+func (u *pvzUC) UpdatePVZ(ctx context.Context, pvzID uuid.UUID, city, status *string) (models.PVZ, error) {
+	const op = "PVZ.UpdatePVZ"
+
+	if city != nil {
+		if err := u.validateCity(ctx, *city); err != nil {
+			return models.PVZ{}, err
+		}
+	}
+
+	pvz, err := u.pvzRepo.UpdatePVZ(ctx, pvzID, city, status)
+	if err != nil {
+		if errors.Is(err, db.ErrPVZNotFound) {
+			return models.PVZ{}, err
+		}
+		if errors.Is(err, db.ErrCityNotFound) {
+			u.cities.Invalidate()
+			return models.PVZ{}, ErrInvalidCity
+		}
+		return models.PVZ{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return *pvz, nil
+}
+
+// List of all cities
+func (u *pvzUC) GetCities(ctx context.Context) ([]models.City, error) {
+	const op = "PVZ.GetCities"
+
+	cities, err := u.pvzRepo.GetCities(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return cities, nil
+}
+
+// Create a new city
+func (u *pvzUC) CreateCity(ctx context.Context, name string) (models.City, error) {
+	const op = "PVZ.CreateCity"
+
+	city, err := u.pvzRepo.CreateCity(ctx, models.City{
+		ID:   uuid.New(),
+		Name: name,
+	})
+	if err != nil {
+		if errors.Is(err, db.ErrDuplicateCity) {
+			return models.City{}, err
+		}
+		return models.City{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	u.cities.Invalidate()
+
+	return *city, nil
+}
+
+// Rename the city
+func (u *pvzUC) UpdateCity(ctx context.Context, cityID uuid.UUID, name string) (models.City, error) {
+	const op = "PVZ.UpdateCity"
+
+	city, err := u.pvzRepo.UpdateCity(ctx, cityID, name)
+	if err != nil {
+		if errors.Is(err, db.ErrCityNotFound) || errors.Is(err, db.ErrDuplicateCity) {
+			return models.City{}, err
+		}
+		return models.City{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	u.cities.Invalidate()
+
+	return *city, nil
+}
+
+// Delete the city
+func (u *pvzUC) DeleteCity(ctx context.Context, cityID uuid.UUID) error {
+	const op = "PVZ.DeleteCity"
+
+	err := u.pvzRepo.DeleteCity(ctx, cityID)
+	if err != nil {
+		if errors.Is(err, db.ErrCityNotFound) || errors.Is(err, db.ErrCityInUse) {
+			return err
+		}
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	u.cities.Invalidate()
+
+	return nil
+}
+
+// Load the set of city names for the cache
+func (u *pvzUC) loadCities(ctx context.Context) (map[string]bool, error) {
+	cities, err := u.pvzRepo.GetCities(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	names := make(map[string]bool, len(cities))
+	for _, c := range cities {
+		names[c.Name] = true
+	}
+
+	return names, nil
+}
+
+// Check that the city is known
+func (u *pvzUC) validateCity(ctx context.Context, city string) error {
+	const op = "PVZ.ValidateCity"
+
+	cities, err := u.cities.Get(ctx)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	if !cities[city] {
+		return ErrInvalidCity
+	}
+
+	return nil
+}
+
+// Product type catalog including retired types
+func (u *pvzUC) GetProductTypes(ctx context.Context) ([]models.ProductType, error) {
+	const op = "PVZ.GetProductTypes"
+
+	types, err := u.pvzRepo.GetProductTypes(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return types, nil
+}
+
+// Add a new product type to the catalog
+func (u *pvzUC) CreateProductType(ctx context.Context, name, displayName string) (models.ProductType, error) {
+	const op = "PVZ.CreateProductType"
+
+	productType, err := u.pvzRepo.CreateProductType(ctx, models.ProductType{
+		ID:          uuid.New(),
+		Name:        name,
+		DisplayName: displayName,
+	})
+	if err != nil {
+		if errors.Is(err, db.ErrDuplicateType) {
+			return models.ProductType{}, err
+		}
+		return models.ProductType{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	u.types.Invalidate()
+
+	return *productType, nil
+}
+
+// Retire the product type
+func (u *pvzUC) RetireProductType(ctx context.Context, typeID uuid.UUID) error {
+	const op = "PVZ.RetireProductType"
+
+	err := u.pvzRepo.RetireProductType(ctx, typeID)
+	if err != nil {
+		if errors.Is(err, db.ErrTypeNotFound) {
+			return err
+		}
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	u.types.Invalidate()
+
+	return nil
+}
+
+// Load the set of active product type names for the cache
+func (u *pvzUC) loadProductTypes(ctx context.Context) (map[string]bool, error) {
+	types, err := u.pvzRepo.GetProductTypes(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	names := make(map[string]bool, len(types))
+	for _, t := range types {
+		if t.RetiredAt == nil {
+			names[t.Name] = true
+		}
+	}
+
+	return names, nil
+}
+
+// Check that the product type is in the catalog and not retired
+func (u *pvzUC) validateProductType(ctx context.Context, productType string) error {
+	const op = "PVZ.ValidateProductType"
+
+	types, err := u.types.Get(ctx)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	if !types[productType] {
+		return ErrInvalidType
+	}
+
+	return nil
+}
+
+// Validate the expected manifests: positive counts, known types, each type and barcode listed once
+func (u *pvzUC) validateManifest(ctx context.Context, manifest []models.ManifestItem, barcodes []models.ManifestBarcode) error {
+	seen := make(map[string]bool, len(manifest))
+	for _, item := range manifest {
+		if item.Count <= 0 || seen[item.Type] {
+			return ErrInvalidManifest
+		}
+		seen[item.Type] = true
+
+		if err := u.validateProductType(ctx, item.Type); err != nil {
+			return err
+		}
+	}
+
+	seen = make(map[string]bool, len(barcodes))
+	for _, item := range barcodes {
+		if seen[item.Barcode] {
+			return ErrInvalidManifest
+		}
+		seen[item.Barcode] = true
+
+		if err := u.validateProductType(ctx, item.Type); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Check that the employee is allowed to operate the pvz
+func (u *pvzUC) checkAssignment(ctx context.Context, userID, pvzID uuid.UUID) error {
+	const op = "PVZ.CheckAssignment"
+
+	// Tokens from dummy login carry no user id and are not bound to pvzs
+	if userID == uuid.Nil {
+		return nil
+	}
+
+	assigned, err := u.pvzRepo.IsEmployeeAssigned(ctx, pvzID, userID)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	if !assigned {
+		return ErrPVZAccessDenied
+	}
+
+	return nil
+}
+
+// Create a new reception for the pvz
+func (u *pvzUC) CreateReception(ctx context.Context, userID, pvzID uuid.UUID, manifest []models.ManifestItem, barcodes []models.ManifestBarcode) (models.Reception, error) {
+	const op = "PVZ.CreateReception"
+
+	if err := u.checkAssignment(ctx, userID, pvzID); err != nil {
+		return models.Reception{}, err
+	}
+
+	if err := u.validateManifest(ctx, manifest, barcodes); err != nil {
+		return models.Reception{}, err
+	}
+
+	uuid := uuid.New()
+
+	reception, err := u.pvzRepo.CreateReception(ctx, uuid, pvzID, userID, manifest, barcodes)
+	if err != nil {
+		if errors.Is(err, db.ErrReceptionConflict) || errors.Is(err, db.ErrPVZNotActive) {
+			return models.Reception{}, err
+		}
+		if errors.Is(err, db.ErrTypeNotFound) {
+			u.types.Invalidate()
+			return models.Reception{}, ErrInvalidType
+		}
+		return models.Reception{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return *reception, nil
+}
+
+// Add a new product for the reception
+func (u *pvzUC) AddProduct(ctx context.Context, userID, pvzID uuid.UUID, productType string, barcode *string, cellNumber *int) (models.Product, error) {
+	const op = "PVZ.AddProduct"
+
+	if err := u.checkAssignment(ctx, userID, pvzID); err != nil {
+		return models.Product{}, err
+	}
+
+	if err := u.validateProductType(ctx, productType); err != nil {
+		return models.Product{}, err
+	}
+
+	uuid := uuid.New()
+
+	product, err := u.pvzRepo.AddProduct(ctx, uuid, pvzID, userID, productType, barcode, cellNumber)
+	if err != nil {
+		if errors.Is(err, db.ErrNoOpenReception) || errors.Is(err, db.ErrPVZNotActive) ||
+			errors.Is(err, db.ErrDuplicateBarcode) || errors.Is(err, db.ErrCellNotFound) ||
+			errors.Is(err, db.ErrCellFull) || errors.Is(err, db.ErrCapacityExceeded) ||
+			errors.Is(err, db.ErrReceptionLimit) {
+			return models.Product{}, err
+		}
+		if errors.Is(err, db.ErrTypeNotFound) {
+			u.types.Invalidate()
+			return models.Product{}, ErrInvalidType
+		}
+		return models.Product{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return *product, nil
+}
+
+// Add the batch of products to the reception, invalid items are reported in the results
+func (u *pvzUC) AddProducts(ctx context.Context, userID, pvzID uuid.UUID, products []models.Product) ([]models.ProductBatchResult, error) {
+	const op = "PVZ.AddProducts"
+
+	if err := u.checkAssignment(ctx, userID, pvzID); err != nil {
+		return nil, err
+	}
+
+	types, err := u.types.Get(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	results := make([]models.ProductBatchResult, len(products))
+	valid := make([]models.Product, 0, len(products))
+	indexes := make([]int, 0, len(products))
+	for i, product := range products {
+		if !types[product.Type] {
+			results[i].Err = ErrInvalidType
+			continue
+		}
+
+		product.ID = uuid.New()
+		valid = append(valid, product)
+		indexes = append(indexes, i)
+	}
+
+	if len(valid) == 0 {
+		return results, nil
+	}
+
+	added, err := u.pvzRepo.AddProducts(ctx, pvzID, userID, valid)
+	if err != nil {
+		if errors.Is(err, db.ErrNoOpenReception) || errors.Is(err, db.ErrPVZNotActive) {
+			return nil, err
+		}
+		if errors.Is(err, db.ErrTypeNotFound) {
+			u.types.Invalidate()
+			return nil, ErrInvalidType
+		}
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	for i, result := range added {
+		results[indexes[i]] = result
+	}
+
+	return results, nil
+}
+
+// Delete the last product from the reception
+func (u *pvzUC) DeleteLastProduct(ctx context.Context, userID, pvzID uuid.UUID) error {
+	const op = "PVZ.DeleteLastProduct"
+
+	if err := u.checkAssignment(ctx, userID, pvzID); err != nil {
+		return err
+	}
+
+	err := u.pvzRepo.DeleteLastProduct(ctx, pvzID)
+	if err != nil {
+		if errors.Is(err, db.ErrNoOpenReception) || errors.Is(err, db.ErrNoProducts) {
+			return err
+		}
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+// Delete the product from the open reception
+func (u *pvzUC) DeleteProduct(ctx context.Context, userID, receptionID, productID uuid.UUID) error {
+	const op = "PVZ.DeleteProduct"
+
+	pvzID, err := u.pvzRepo.GetReceptionPVZID(ctx, receptionID)
+	if err != nil {
+		if errors.Is(err, db.ErrReceptionNotFound) {
+			return err
+		}
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	if err := u.checkAssignment(ctx, userID, pvzID); err != nil {
+		return err
+	}
+
+	err = u.pvzRepo.DeleteProduct(ctx, receptionID, productID)
+	if err != nil {
+		if errors.Is(err, db.ErrReceptionNotFound) || errors.Is(err, db.ErrReceptionNotOpen) ||
+			errors.Is(err, db.ErrProductNotFound) {
+			return err
+		}
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+// Issue the stored product to the customer
+func (u *pvzUC) IssueProduct(ctx context.Context, userID, productID uuid.UUID) (models.Product, error) {
+	return u.changeProductStatus(ctx, userID, productID, pvzapi.Issued)
+}
+
+// Return the stored or issued product to the sender
+func (u *pvzUC) ReturnProduct(ctx context.Context, userID, productID uuid.UUID) (models.Product, error) {
+	return u.changeProductStatus(ctx, userID, productID, pvzapi.Returned)
+}
+
+// Move the product of the employee's pvz to the new status
+func (u *pvzUC) changeProductStatus(ctx context.Context, userID, productID uuid.UUID, status pvzapi.ProductStatus) (models.Product, error) {
+	const op = "PVZ.ChangeProductStatus"
+
+	pvzID, err := u.pvzRepo.GetProductPVZID(ctx, productID)
+	if err != nil {
+		if errors.Is(err, db.ErrProductNotFound) {
+			return models.Product{}, err
+		}
+		return models.Product{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	if err := u.checkAssignment(ctx, userID, pvzID); err != nil {
+		return models.Product{}, err
+	}
+
+	product, err := u.pvzRepo.UpdateProductStatus(ctx, productID, productTransitions[status], string(status))
+	if err != nil {
+		if errors.Is(err, db.ErrProductNotFound) || errors.Is(err, db.ErrInvalidTransition) {
+			return models.Product{}, err
+		}
+		return models.Product{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return *product, nil
+}
+
+// Generate a new pickup code for the stored product, only its hash is kept
+func (u *pvzUC) GeneratePickupCode(ctx context.Context, userID, productID uuid.UUID) (string, error) {
+	const op = "PVZ.GeneratePickupCode"
+
+	pvzID, err := u.pvzRepo.GetProductPVZID(ctx, productID)
+	if err != nil {
+		if errors.Is(err, db.ErrProductNotFound) {
+			return "", err
+		}
+		return "", fmt.Errorf("%s: %w", op, err)
+	}
+
+	if err := u.checkAssignment(ctx, userID, pvzID); err != nil {
+		return "", err
+	}
+
+	n, err := rand.Int(rand.Reader, big.NewInt(pickupCodeSpace))
+	if err != nil {
+		return "", fmt.Errorf("%s: %w", op, err)
+	}
+	code := fmt.Sprintf("%06d", n.Int64())
+
+	codeHash, err := argon2id.CreateHash(code, argon2id.DefaultParams)
+	if err != nil {
+		return "", fmt.Errorf("%s: %w", op, err)
+	}
+
+	err = u.pvzRepo.SetPickupCode(ctx, productID, codeHash)
+	if err != nil {
+		if errors.Is(err, db.ErrProductNotFound) || errors.Is(err, db.ErrInvalidTransition) {
+			return "", err
+		}
+		return "", fmt.Errorf("%s: %w", op, err)
+	}
+
+	return code, nil
+}
+
+// Issue the stored product to the customer who presented its pickup code
+func (u *pvzUC) PickupProduct(ctx context.Context, userID, productID uuid.UUID, code string) (models.Product, error) {
+	const op = "PVZ.PickupProduct"
+
+	pvzID, err := u.pvzRepo.GetProductPVZID(ctx, productID)
+	if err != nil {
+		if errors.Is(err, db.ErrProductNotFound) {
+			return models.Product{}, err
+		}
+		return models.Product{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	if err := u.checkAssignment(ctx, userID, pvzID); err != nil {
+		return models.Product{}, err
+	}
+
+	// The attempt is counted before the code is checked, so parallel guesses are bounded by the limit
+	now := u.now()
+	pickup, err := u.pvzRepo.ClaimPickupAttempt(ctx, productID, u.cfg.App.PickupMaxAttempts, now)
+	if err != nil {
+		if errors.Is(err, db.ErrNoPickupAttemptsLeft) {
+			return models.Product{}, ErrPickupLocked
+		}
+		if errors.Is(err, db.ErrProductNotFound) || errors.Is(err, db.ErrInvalidTransition) || errors.Is(err, db.ErrNoPickupCode) {
+			return models.Product{}, err
+		}
+		return models.Product{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	match, err := argon2id.ComparePasswordAndHash(code, *pickup.PickupCodeHash)
+	if err != nil {
+		return models.Product{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	if !match {
+		lockedUntil, err := u.pvzRepo.FailPickupAttempt(ctx, productID, u.cfg.App.PickupMaxAttempts, now.Add(u.cfg.App.PickupLockout))
+		if err != nil {
+			return models.Product{}, fmt.Errorf("%s: %w", op, err)
+		}
+		if lockedUntil != nil {
+			return models.Product{}, ErrPickupLocked
+		}
+		return models.Product{}, ErrInvalidPickupCode
+	}
+
+	product, err := u.pvzRepo.IssueWithPickupCode(ctx, productID, *pickup.PickupCodeHash, now)
+	if err != nil {
+		if errors.Is(err, db.ErrNoPickupAttemptsLeft) {
+			return models.Product{}, ErrPickupLocked
+		}
+		if errors.Is(err, db.ErrInvalidTransition) {
+			return models.Product{}, err
+		}
+		return models.Product{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return *product, nil
+}
+
+// Transfer the stored products of the closed reception to another pvz
+func (u *pvzUC) TransferProducts(ctx context.Context, userID, receptionID, toPvzID uuid.UUID, productIDs []uuid.UUID) ([]models.ProductTransfer, error) {
+	const op = "PVZ.TransferProducts"
+
+	pvzID, err := u.pvzRepo.GetReceptionPVZID(ctx, receptionID)
+	if err != nil {
+		if errors.Is(err, db.ErrReceptionNotFound) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	if err := u.checkAssignment(ctx, userID, pvzID); err != nil {
+		return nil, err
+	}
+
+	if toPvzID == pvzID {
+		return nil, ErrSameTransferPVZ
+	}
+
+	unique := make([]uuid.UUID, 0, len(productIDs))
+	seen := make(map[uuid.UUID]struct{}, len(productIDs))
+	for _, id := range productIDs {
+		if _, ok := seen[id]; ok {
+			continue
+		}
+		seen[id] = struct{}{}
+		unique = append(unique, id)
+	}
+
+	transfers, err := u.pvzRepo.CreateTransfers(ctx, receptionID, toPvzID, userID, unique)
+	if err != nil {
+		if errors.Is(err, db.ErrReceptionNotFound) || errors.Is(err, db.ErrReceptionNotClosed) ||
+			errors.Is(err, db.ErrPVZNotFound) || errors.Is(err, db.ErrPVZNotActive) ||
+			errors.Is(err, db.ErrProductNotFound) || errors.Is(err, db.ErrInvalidTransition) ||
+			errors.Is(err, db.ErrNoBarcode) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return transfers, nil
+}
+
+// Move the product kept in the pvz to another storage cell
+func (u *pvzUC) MoveProduct(ctx context.Context, userID, productID uuid.UUID, cellNumber int) (models.Product, error) {
+	const op = "PVZ.MoveProduct"
+
+	pvzID, err := u.pvzRepo.GetProductPVZID(ctx, productID)
+	if err != nil {
+		if errors.Is(err, db.ErrProductNotFound) {
+			return models.Product{}, err
+		}
+		return models.Product{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	if err := u.checkAssignment(ctx, userID, pvzID); err != nil {
+		return models.Product{}, err
+	}
+
+	product, err := u.pvzRepo.MoveProduct(ctx, productID, cellNumber)
+	if err != nil {
+		if errors.Is(err, db.ErrProductNotFound) || errors.Is(err, db.ErrInvalidTransition) ||
+			errors.Is(err, db.ErrCellNotFound) || errors.Is(err, db.ErrCellFull) {
+			return models.Product{}, err
+		}
+		return models.Product{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return *product, nil
+}
+
+// Close the last reception in the pvz
+func (u *pvzUC) CloseLastReception(ctx context.Context, userID, pvzID uuid.UUID) (models.Reception, error) {
+	const op = "PVZ.CloseLastReception"
+
+	if err := u.checkAssignment(ctx, userID, pvzID); err != nil {
+		return models.Reception{}, err
+	}
+
+	reception, err := u.pvzRepo.CloseLastReception(ctx, pvzID, userID)
+	if err != nil {
+		if errors.Is(err, db.ErrNoOpenReception) {
+			return models.Reception{}, err
+		}
+		return models.Reception{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return *reception, nil
+}
+
+// Cancel the open reception in the pvz together with its products
+func (u *pvzUC) CancelLastReception(ctx context.Context, userID, pvzID uuid.UUID) (models.Reception, error) {
+	const op = "PVZ.CancelLastReception"
+
+	if err := u.checkAssignment(ctx, userID, pvzID); err != nil {
+		return models.Reception{}, err
+	}
+
+	reception, err := u.pvzRepo.CancelLastReception(ctx, pvzID, userID)
+	if err != nil {
+		if errors.Is(err, db.ErrNoOpenReception) {
+			return models.Reception{}, err
+		}
+		return models.Reception{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return *reception, nil
+}
+
+// Reopen the closed reception on behalf of the moderator
+func (u *pvzUC) ReopenReception(ctx context.Context, userID, receptionID uuid.UUID, reason string) (models.Reception, error) {
+	const op = "PVZ.ReopenReception"
+
+	reception, err := u.pvzRepo.ReopenReception(ctx, receptionID, userID, reason)
+	if err != nil {
+		if errors.Is(err, db.ErrReceptionNotFound) || errors.Is(err, db.ErrReceptionNotClosed) ||
+			errors.Is(err, db.ErrReceptionConflict) || errors.Is(err, db.ErrPVZNotActive) ||
+			errors.Is(err, db.ErrProductsLeftPVZ) {
+			return models.Reception{}, err
+		}
+		return models.Reception{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return *reception, nil
+}
+
+// Close receptions without new products for longer than the configured idle timeout
+func (u *pvzUC) CloseStaleReceptions(ctx context.Context) ([]models.Reception, error) {
+	const op = "PVZ.CloseStaleReceptions"
+
+	idleSince := time.Now().Add(-u.cfg.App.ReceptionIdleTimeout)
+
+	receptions, err := u.pvzRepo.CloseStaleReceptions(ctx, idleSince)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return receptions, nil
+}
+
+// List of PVZs with their receptions and products
+func (u *pvzUC) GetPVZs(ctx context.Context, params pvzapi.GetPvzParams) ([]*models.PVZWithReceptions, error) {
+	const op = "PVZ.GetPVZs"
+
+	if params.Page == nil {
+		defaultPage := 1
+		params.Page = &defaultPage
+	}
+	if params.Limit == nil {
+		defaultLimit := 10
+		params.Limit = &defaultLimit
+	}
+
+	if *params.Page < 1 {
+		*params.Page = 1
+	}
+	if *params.Limit < 1 || *params.Limit > 30 {
+		*params.Limit = 10
+	}
+
+	if params.StartDate != nil && params.EndDate != nil && params.StartDate.After(*params.EndDate) {
+		return nil, ErrInvalidDateRange
+	}
+
+	offset := (*params.Page - 1) * *params.Limit
+
+	limitU := uint64(*params.Limit)
+	offsetU := uint64(offset) //nolint:gosec
+
+	excludeCancelled := params.ExcludeCancelled != nil && *params.ExcludeCancelled
+
+	var productStatus *string
+	if params.ProductStatus != nil {
+		status := string(*params.ProductStatus)
+		productStatus = &status
+	}
+
+	productCounts := params.ProductCounts != nil && *params.ProductCounts
+
+	pvzs, err := u.pvzRepo.GetPVZs(ctx, params.StartDate, params.EndDate, excludeCancelled, productStatus, productCounts, limitU, offsetU)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return pvzs, nil
+}
+
+// List of all created PVZs
+func (u *pvzUC) GetPVZList(ctx context.Context) ([]models.PVZ, error) {
+	const op = "PVZ.GetPVZList"
+
+	pvzs, err := u.pvzRepo.GetPVZList(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return pvzs, nil
+}
+
+// Get the pvz with its open reception and counters
+func (u *pvzUC) GetPVZ(ctx context.Context, pvzID uuid.UUID) (models.PVZDetails, error) {
+	const op = "PVZ.GetPVZ"
+
+	details, err := u.pvzRepo.GetPVZ(ctx, pvzID)
+	if err != nil {
+		if errors.Is(err, db.ErrPVZNotFound) {
+			return models.PVZDetails{}, err
+		}
+		return models.PVZDetails{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return *details, nil
+}
+
+// Get the reception with its products
+func (u *pvzUC) GetReception(ctx context.Context, receptionID uuid.UUID) (models.ReceptionWithProducts, error) {
+	const op = "PVZ.GetReception"
+
+	reception, err := u.pvzRepo.GetReception(ctx, receptionID)
+	if err != nil {
+		if errors.Is(err, db.ErrReceptionNotFound) {
+			return models.ReceptionWithProducts{}, err
+		}
+		return models.ReceptionWithProducts{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return *reception, nil
+}
+
+// Get the page of reception products in the order they were added
+func (u *pvzUC) GetReceptionProducts(ctx context.Context, receptionID uuid.UUID, params pvzapi.GetReceptionsReceptionIdProductsParams) (models.ProductPage, error) {
+	const op = "PVZ.GetReceptionProducts"
+
+	cursor := 0
+	if params.Cursor != nil && *params.Cursor > 0 {
+		cursor = *params.Cursor
+	}
+
+	limit := 20
+	if params.Limit != nil && *params.Limit >= 1 && *params.Limit <= 100 {
+		limit = *params.Limit
+	}
+
+	// One extra product tells whether there is a next page
+	products, err := u.pvzRepo.GetReceptionProducts(ctx, receptionID, params.Type, cursor, limit+1)
+	if err != nil {
+		if errors.Is(err, db.ErrReceptionNotFound) {
+			return models.ProductPage{}, err
+		}
+		return models.ProductPage{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	page := models.ProductPage{Products: products}
+	if len(products) > limit {
+		page.Products = products[:limit]
+		page.NextCursor = &page.Products[limit-1].LineNumber
+	}
+
+	return page, nil
+}
+
+// Get the audit trail of the reception
+func (u *pvzUC) GetReceptionHistory(ctx context.Context, receptionID uuid.UUID) ([]models.ReceptionAuditRecord, error) {
+	const op = "PVZ.GetReceptionHistory"
+
+	records, err := u.pvzRepo.GetReceptionHistory(ctx, receptionID)
+	if err != nil {
+		if errors.Is(err, db.ErrReceptionNotFound) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return records, nil
+}
+
+// Find products with the barcode across all pvzs
+func (u *pvzUC) FindProductsByBarcode(ctx context.Context, barcode string) ([]models.ProductLocation, error) {
+	const op = "PVZ.FindProductsByBarcode"
+
+	locations, err := u.pvzRepo.FindProductsByBarcode(ctx, barcode)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return locations, nil
+}
+
+// Assign the employee to the pvz
+func (u *pvzUC) AssignEmployee(ctx context.Context, pvzID, userID uuid.UUID) (models.EmployeeAssignment, error) {
+	const op = "PVZ.AssignEmployee"
+
+	assignment, err := u.pvzRepo.AssignEmployee(ctx, pvzID, userID)
+	if err != nil {
+		if errors.Is(err, db.ErrUserNotFound) || errors.Is(err, db.ErrPVZNotFound) ||
+			errors.Is(err, db.ErrNotEmployee) || errors.Is(err, db.ErrAlreadyAssigned) {
+			return models.EmployeeAssignment{}, err
+		}
+		return models.EmployeeAssignment{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return *assignment, nil
+}
+
+// Remove the employee from the pvz
+func (u *pvzUC) UnassignEmployee(ctx context.Context, pvzID, userID uuid.UUID) error {
+	const op = "PVZ.UnassignEmployee"
+
+	err := u.pvzRepo.UnassignEmployee(ctx, pvzID, userID)
+	if err != nil {
+		if errors.Is(err, db.ErrNotAssigned) {
+			return err
+		}
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+// List of employees assigned to the pvz
+func (u *pvzUC) GetPVZEmployees(ctx context.Context, pvzID uuid.UUID) ([]models.User, error) {
+	const op = "PVZ.GetPVZEmployees"
+
+	users, err := u.pvzRepo.GetPVZEmployees(ctx, pvzID)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return users, nil
+}
+
+// Add storage cells to the pvz
+func (u *pvzUC) CreateCells(ctx context.Context, pvzID uuid.UUID, count, capacity int) ([]models.StorageCell, error) {
+	const op = "PVZ.CreateCells"
+
+	cells, err := u.pvzRepo.CreateCells(ctx, pvzID, count, capacity)
+	if err != nil {
+		if errors.Is(err, db.ErrPVZNotFound) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return cells, nil
+}
+
+// Get storage cells of the pvz with their occupancy
+func (u *pvzUC) GetCells(ctx context.Context, pvzID uuid.UUID) (models.CellOccupancy, error) {
+	const op = "PVZ.GetCells"
+
+	occupancy, err := u.pvzRepo.GetCells(ctx, pvzID)
+	if err != nil {
+		if errors.Is(err, db.ErrPVZNotFound) {
+			return models.CellOccupancy{}, err
+		}
+		return models.CellOccupancy{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return *occupancy, nil
+}
+
+// Get capacity limits of the pvz with the number of products in it
+func (u *pvzUC) GetCapacity(ctx context.Context, pvzID uuid.UUID) (models.PVZCapacity, error) {
+	const op = "PVZ.GetCapacity"
+
+	capacity, err := u.pvzRepo.GetPVZCapacity(ctx, pvzID)
+	if err != nil {
+		if errors.Is(err, db.ErrPVZNotFound) {
+			return models.PVZCapacity{}, err
+		}
+		return models.PVZCapacity{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return *capacity, nil
+}
+
+// Set capacity limits of the pvz, nil removes the limit
+func (u *pvzUC) SetCapacity(ctx context.Context, pvzID uuid.UUID, capacity, receptionLimit *int) (models.PVZCapacity, error) {
+	const op = "PVZ.SetCapacity"
+
+	if (capacity != nil && *capacity < 1) || (receptionLimit != nil && *receptionLimit < 1) {
+		return models.PVZCapacity{}, ErrInvalidCapacity
+	}
+
+	result, err := u.pvzRepo.SetPVZCapacity(ctx, pvzID, capacity, receptionLimit)
+	if err != nil {
+		if errors.Is(err, db.ErrPVZNotFound) {
+			return models.PVZCapacity{}, err
+		}
+		return models.PVZCapacity{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return *result, nil
+}
+
+// Get the number of products in every pvz with a capacity limit
+func (u *pvzUC) GetUtilization(ctx context.Context) ([]models.PVZCapacity, error) {
+	const op = "PVZ.GetUtilization"
+
+	utilization, err := u.pvzRepo.GetPVZUtilization(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return utilization, nil
 }
@@ -2,7 +2,14 @@ package usecase
 
 import (
 	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
 	"errors"
+	"os"
+	"path/filepath"
 	"testing"
 	"time"
 
@@ -11,16 +18,32 @@ import (
 	"github.com/golang/mock/gomock"
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 
 	"github.com/cyansnbrst/pvz-service/config"
 	"github.com/cyansnbrst/pvz-service/gen/pvzapi"
 	"github.com/cyansnbrst/pvz-service/internal/models"
+	"github.com/cyansnbrst/pvz-service/internal/pvz"
 	mock_pvz "github.com/cyansnbrst/pvz-service/internal/pvz/mock"
+	"github.com/cyansnbrst/pvz-service/pkg/auth"
 	"github.com/cyansnbrst/pvz-service/pkg/db"
 )
 
 var ErrRandomError = errors.New("random error")
 
+var testCities = []models.City{
+	{ID: uuid.New(), Name: "Москва"},
+	{ID: uuid.New(), Name: "Казань"},
+}
+
+var retiredAt = time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+
+var testProductTypes = []models.ProductType{
+	{ID: uuid.New(), Name: "обувь", DisplayName: "Обувь"},
+	{ID: uuid.New(), Name: "одежда", DisplayName: "Одежда"},
+	{ID: uuid.New(), Name: "посуда", DisplayName: "Посуда", RetiredAt: &retiredAt},
+}
+
 func TestPVZUC_GenerateJWT(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
@@ -35,10 +58,11 @@ func TestPVZUC_GenerateJWT(t *testing.T) {
 	pvzUC := NewPVZUseCase(cfg, nil)
 
 	user := &models.User{
+		ID:   uuid.New(),
 		Role: "moderator",
 	}
 
-	token, err := pvzUC.GenerateJWT(context.Background(), pvzapi.UserRole(user.Role))
+	token, err := pvzUC.GenerateJWT(context.Background(), user.ID, pvzapi.UserRole(user.Role))
 	assert.NoError(t, err)
 	assert.NotEmpty(t, token)
 
@@ -47,6 +71,139 @@ func TestPVZUC_GenerateJWT(t *testing.T) {
 	})
 	assert.NoError(t, err)
 	assert.True(t, parsedToken.Valid)
+
+	sub, err := parsedToken.Claims.GetSubject()
+	assert.NoError(t, err)
+	assert.Equal(t, user.ID.String(), sub)
+
+	jti, ok := parsedToken.Claims.(jwt.MapClaims)["jti"].(string)
+	assert.True(t, ok)
+	_, err = uuid.Parse(jti)
+	assert.NoError(t, err)
+}
+
+func TestPVZUC_GenerateJWT_SigningKeys(t *testing.T) {
+	dir := t.TempDir()
+
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	_, edKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	writeKey := func(name, blockType string, key any) {
+		var der []byte
+		if blockType == "PUBLIC KEY" {
+			der, err = x509.MarshalPKIXPublicKey(key)
+		} else {
+			der, err = x509.MarshalPKCS8PrivateKey(key)
+		}
+		require.NoError(t, err)
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name+".pem"), pem.EncodeToMemory(&pem.Block{Type: blockType, Bytes: der}), 0o600))
+	}
+	writeKey("rsa-1", "PRIVATE KEY", rsaKey)
+	writeKey("ed-1", "PRIVATE KEY", edKey)
+
+	newUC := func(signingKeyID, secret string) pvz.UseCase {
+		return NewPVZUseCase(&config.Config{
+			App: config.App{
+				JWTSecretKey:    secret,
+				JWTKeysDir:      dir,
+				JWTSigningKeyID: signingKeyID,
+				JWTTokenTTL:     time.Hour,
+			},
+		}, nil)
+	}
+	newFallbackUC := func(signingKeyID, secret string) pvz.UseCase {
+		return NewPVZUseCase(&config.Config{
+			App: config.App{
+				JWTSecretKey:    secret,
+				JWTKeysDir:      dir,
+				JWTSigningKeyID: signingKeyID,
+				JWTHMACFallback: true,
+				JWTTokenTTL:     time.Hour,
+			},
+		}, nil)
+	}
+
+	userID := uuid.New()
+
+	hmacToken, err := newUC("", "secret").GenerateJWT(context.Background(), userID, pvzapi.UserRoleEmployee)
+	require.NoError(t, err)
+
+	for _, tt := range []struct {
+		kid string
+		alg string
+		kty string
+	}{
+		{kid: "rsa-1", alg: "RS256", kty: "RSA"},
+		{kid: "ed-1", alg: "EdDSA", kty: "OKP"},
+	} {
+		t.Run(tt.alg, func(t *testing.T) {
+			pvzUC := newUC(tt.kid, "secret")
+
+			token, err := pvzUC.GenerateJWT(context.Background(), userID, pvzapi.UserRoleModerator)
+			require.NoError(t, err)
+
+			parsed, _, err := jwt.NewParser().ParseUnverified(token, jwt.MapClaims{})
+			require.NoError(t, err)
+			assert.Equal(t, tt.kid, parsed.Header["kid"])
+			assert.Equal(t, tt.alg, parsed.Method.Alg())
+
+			claims, err := pvzUC.ParseJWT(context.Background(), token)
+			assert.NoError(t, err)
+			assert.Equal(t, userID, claims.UserID)
+			assert.Equal(t, pvzapi.UserRoleModerator, claims.Role)
+
+			// Tokens without a kid are verified with the HMAC secret only while the fallback is on
+			_, err = newFallbackUC(tt.kid, "secret").ParseJWT(context.Background(), hmacToken)
+			assert.NoError(t, err)
+			_, err = pvzUC.ParseJWT(context.Background(), hmacToken)
+			assert.ErrorIs(t, err, auth.ErrInvalidToken)
+			_, err = newUC(tt.kid, "").ParseJWT(context.Background(), hmacToken)
+			assert.ErrorIs(t, err, auth.ErrInvalidToken)
+
+			keys, err := pvzUC.GetJWKS(context.Background())
+			require.NoError(t, err)
+			require.Len(t, keys, 2)
+			for _, k := range keys {
+				if k.Kid == tt.kid {
+					assert.Equal(t, tt.kty, k.Kty)
+					assert.Equal(t, tt.alg, k.Alg)
+				}
+			}
+		})
+	}
+
+	t.Run("rotated key still verifies its tokens", func(t *testing.T) {
+		token, err := newUC("rsa-1", "").GenerateJWT(context.Background(), userID, pvzapi.UserRoleEmployee)
+		require.NoError(t, err)
+
+		// Only the public part of the retired key is kept
+		writeKey("rsa-1", "PUBLIC KEY", &rsaKey.PublicKey)
+
+		_, err = newUC("ed-1", "").ParseJWT(context.Background(), token)
+		assert.NoError(t, err)
+
+		require.NoError(t, os.Remove(filepath.Join(dir, "rsa-1.pem")))
+
+		_, err = newUC("ed-1", "").ParseJWT(context.Background(), token)
+		assert.ErrorIs(t, err, auth.ErrInvalidToken)
+	})
+
+	t.Run("misconfigured keys", func(t *testing.T) {
+		_, err := newUC("missing", "").GenerateJWT(context.Background(), userID, pvzapi.UserRoleEmployee)
+		assert.Error(t, err)
+
+		writeKey("public-only", "PUBLIC KEY", edKey.Public())
+		_, err = newUC("public-only", "").GenerateJWT(context.Background(), userID, pvzapi.UserRoleEmployee)
+		assert.Error(t, err)
+
+		_, err = newUC("", "").GenerateJWT(context.Background(), userID, pvzapi.UserRoleEmployee)
+		assert.Error(t, err)
+
+		_, err = newFallbackUC("ed-1", "").GenerateJWT(context.Background(), userID, pvzapi.UserRoleEmployee)
+		assert.Error(t, err, "fallback without a secret")
+	})
 }
 
 func TestPVZUC_Register(t *testing.T) {
@@ -134,18 +291,45 @@ func TestPVZUC_Login(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
 
-	cfg := &config.Config{}
+	cfg := &config.Config{
+		App: config.App{
+			JWTSecretKey:          "secret",
+			JWTTokenTTL:           time.Minute * 15,
+			RefreshTokenTTL:       time.Hour * 24,
+			LoginMaxAttempts:      3,
+			LoginMaxAttemptsPerIP: 10,
+			LoginLockout:          time.Minute,
+			LoginMaxLockout:       time.Hour,
+			LoginFailureWindow:    time.Hour * 24,
+		},
+	}
 
 	mockRepo := mock_pvz.NewMockRepository(ctrl)
-	pvzUC := NewPVZUseCase(cfg, mockRepo)
+	pvzUC := NewPVZUseCase(cfg, mockRepo).(*pvzUC)
+
+	now := time.Date(2025, 4, 10, 12, 0, 0, 0, time.UTC)
+	pvzUC.now = func() time.Time { return now }
 
 	correctPassword := "password123"
 	hashedPassword, _ := argon2id.CreateHash(correctPassword, argon2id.DefaultParams)
 	testUser := &models.User{
+		ID:           uuid.New(),
 		Email:        "test@example.com",
 		PasswordHash: hashedPassword,
 		Role:         "employee",
 	}
+	deactivatedAt := time.Now()
+	deactivatedUser := *testUser
+	deactivatedUser.DeactivatedAt = &deactivatedAt
+
+	accountKey := "account:test@example.com"
+	ipKey := "ip:10.0.0.1"
+	resetBefore := now.Add(-cfg.App.LoginFailureWindow)
+
+	expectClaims := func(accountFailures, ipFailures int) {
+		mockRepo.EXPECT().ClaimLoginAttempt(gomock.Any(), accountKey, 3, now, resetBefore).Return(accountFailures, nil)
+		mockRepo.EXPECT().ClaimLoginAttempt(gomock.Any(), ipKey, 10, now, resetBefore).Return(ipFailures, nil)
+	}
 
 	tests := []struct {
 		name          string
@@ -160,42 +344,209 @@ func TestPVZUC_Login(t *testing.T) {
 			email:    "test@example.com",
 			password: correctPassword,
 			mockSetup: func() {
+				expectClaims(1, 1)
 				mockRepo.EXPECT().
 					GetUserByEmail(gomock.Any(), "test@example.com").
 					Return(testUser, nil)
+				mockRepo.EXPECT().ResetLoginFailures(gomock.Any(), accountKey).Return(nil)
+				mockRepo.EXPECT().ReleaseLoginAttempt(gomock.Any(), ipKey).Return(nil)
+				mockRepo.EXPECT().UpdateLastLogin(gomock.Any(), testUser.ID).Return(nil)
+				mockRepo.EXPECT().CreateRefreshToken(gomock.Any(), gomock.Any()).DoAndReturn(
+					func(ctx context.Context, token models.RefreshToken) error {
+						assert.Equal(t, testUser.ID, token.UserID)
+						assert.NotEmpty(t, token.TokenHash)
+						assert.Equal(t, now.Add(cfg.App.RefreshTokenTTL), token.ExpiresAt)
+						return nil
+					})
+			},
+			expectToken:   true,
+			expectedError: nil,
+		},
+		{
+			name:     "email is counted case-insensitively",
+			email:    "Test@Example.com",
+			password: correctPassword,
+			mockSetup: func() {
+				expectClaims(1, 1)
+				mockRepo.EXPECT().
+					GetUserByEmail(gomock.Any(), "Test@Example.com").
+					Return(testUser, nil)
+				mockRepo.EXPECT().ResetLoginFailures(gomock.Any(), accountKey).Return(nil)
+				mockRepo.EXPECT().ReleaseLoginAttempt(gomock.Any(), ipKey).Return(nil)
+				mockRepo.EXPECT().UpdateLastLogin(gomock.Any(), testUser.ID).Return(nil)
+				mockRepo.EXPECT().CreateRefreshToken(gomock.Any(), gomock.Any()).Return(nil)
 			},
 			expectToken:   true,
 			expectedError: nil,
 		},
+		{
+			name:     "store refresh token error",
+			email:    "test@example.com",
+			password: correctPassword,
+			mockSetup: func() {
+				expectClaims(1, 1)
+				mockRepo.EXPECT().
+					GetUserByEmail(gomock.Any(), "test@example.com").
+					Return(testUser, nil)
+				mockRepo.EXPECT().ResetLoginFailures(gomock.Any(), accountKey).Return(nil)
+				mockRepo.EXPECT().ReleaseLoginAttempt(gomock.Any(), ipKey).Return(nil)
+				mockRepo.EXPECT().UpdateLastLogin(gomock.Any(), testUser.ID).Return(nil)
+				mockRepo.EXPECT().CreateRefreshToken(gomock.Any(), gomock.Any()).Return(ErrRandomError)
+			},
+			expectToken:   false,
+			expectedError: ErrRandomError,
+		},
+		{
+			name:     "update last login error",
+			email:    "test@example.com",
+			password: correctPassword,
+			mockSetup: func() {
+				expectClaims(1, 1)
+				mockRepo.EXPECT().
+					GetUserByEmail(gomock.Any(), "test@example.com").
+					Return(testUser, nil)
+				mockRepo.EXPECT().ResetLoginFailures(gomock.Any(), accountKey).Return(nil)
+				mockRepo.EXPECT().ReleaseLoginAttempt(gomock.Any(), ipKey).Return(nil)
+				mockRepo.EXPECT().UpdateLastLogin(gomock.Any(), testUser.ID).Return(ErrRandomError)
+			},
+			expectToken:   false,
+			expectedError: ErrRandomError,
+		},
+		{
+			name:     "reset login failures error",
+			email:    "test@example.com",
+			password: correctPassword,
+			mockSetup: func() {
+				expectClaims(1, 1)
+				mockRepo.EXPECT().
+					GetUserByEmail(gomock.Any(), "test@example.com").
+					Return(testUser, nil)
+				mockRepo.EXPECT().ResetLoginFailures(gomock.Any(), accountKey).Return(ErrRandomError)
+			},
+			expectToken:   false,
+			expectedError: ErrRandomError,
+		},
+		{
+			name:     "deactivated user",
+			email:    "test@example.com",
+			password: correctPassword,
+			mockSetup: func() {
+				expectClaims(1, 1)
+				mockRepo.EXPECT().
+					GetUserByEmail(gomock.Any(), "test@example.com").
+					Return(&deactivatedUser, nil)
+				mockRepo.EXPECT().ReleaseLoginAttempt(gomock.Any(), accountKey).Return(nil)
+				mockRepo.EXPECT().ReleaseLoginAttempt(gomock.Any(), ipKey).Return(nil)
+			},
+			expectToken:   false,
+			expectedError: ErrUserDeactivated,
+		},
 		{
 			name:     "user not found",
 			email:    "test@example.com",
 			password: correctPassword,
 			mockSetup: func() {
+				expectClaims(1, 1)
 				mockRepo.EXPECT().
 					GetUserByEmail(gomock.Any(), "test@example.com").
 					Return(nil, db.ErrUserNotFound)
 			},
 			expectToken:   false,
-			expectedError: db.ErrUserNotFound,
+			expectedError: ErrInvalidCredentials,
 		},
 		{
 			name:     "incorrect password",
 			email:    "test@example.com",
 			password: "password",
 			mockSetup: func() {
+				expectClaims(1, 1)
+				mockRepo.EXPECT().
+					GetUserByEmail(gomock.Any(), "test@example.com").
+					Return(testUser, nil)
+			},
+			expectToken:   false,
+			expectedError: ErrInvalidCredentials,
+		},
+		{
+			name:     "incorrect password reaches the account limit",
+			email:    "test@example.com",
+			password: "password",
+			mockSetup: func() {
+				expectClaims(3, 3)
+				mockRepo.EXPECT().
+					GetUserByEmail(gomock.Any(), "test@example.com").
+					Return(testUser, nil)
+				mockRepo.EXPECT().LockLoginAttempts(gomock.Any(), accountKey, now.Add(time.Minute)).Return(nil)
+			},
+			expectToken:   false,
+			expectedError: ErrLoginLocked,
+		},
+		{
+			name:     "incorrect password reaches the ip limit",
+			email:    "test@example.com",
+			password: "password",
+			mockSetup: func() {
+				expectClaims(1, 10)
+				mockRepo.EXPECT().
+					GetUserByEmail(gomock.Any(), "test@example.com").
+					Return(testUser, nil)
+				mockRepo.EXPECT().LockLoginAttempts(gomock.Any(), ipKey, now.Add(time.Minute)).Return(nil)
+			},
+			expectToken:   false,
+			expectedError: ErrLoginLocked,
+		},
+		{
+			name:     "locked account rejects the correct password",
+			email:    "test@example.com",
+			password: correctPassword,
+			mockSetup: func() {
+				mockRepo.EXPECT().ClaimLoginAttempt(gomock.Any(), accountKey, 3, now, resetBefore).Return(0, db.ErrNoLoginAttemptsLeft)
+			},
+			expectToken:   false,
+			expectedError: ErrLoginLocked,
+		},
+		{
+			name:     "locked ip gives back the account attempt",
+			email:    "test@example.com",
+			password: correctPassword,
+			mockSetup: func() {
+				mockRepo.EXPECT().ClaimLoginAttempt(gomock.Any(), accountKey, 3, now, resetBefore).Return(1, nil)
+				mockRepo.EXPECT().ClaimLoginAttempt(gomock.Any(), ipKey, 10, now, resetBefore).Return(0, db.ErrNoLoginAttemptsLeft)
+				mockRepo.EXPECT().ReleaseLoginAttempt(gomock.Any(), accountKey).Return(nil)
+			},
+			expectToken:   false,
+			expectedError: ErrLoginLocked,
+		},
+		{
+			name:     "claim login attempt error",
+			email:    "test@example.com",
+			password: correctPassword,
+			mockSetup: func() {
+				mockRepo.EXPECT().ClaimLoginAttempt(gomock.Any(), accountKey, 3, now, resetBefore).Return(0, ErrRandomError)
+			},
+			expectToken:   false,
+			expectedError: ErrRandomError,
+		},
+		{
+			name:     "lock login attempts error",
+			email:    "test@example.com",
+			password: "password",
+			mockSetup: func() {
+				expectClaims(3, 1)
 				mockRepo.EXPECT().
 					GetUserByEmail(gomock.Any(), "test@example.com").
 					Return(testUser, nil)
+				mockRepo.EXPECT().LockLoginAttempts(gomock.Any(), accountKey, now.Add(time.Minute)).Return(ErrRandomError)
 			},
 			expectToken:   false,
-			expectedError: ErrIncorrectPassword,
+			expectedError: ErrRandomError,
 		},
 		{
 			name:     "repository error",
 			email:    "test@example.com",
 			password: "password",
 			mockSetup: func() {
+				expectClaims(1, 1)
 				mockRepo.EXPECT().
 					GetUserByEmail(gomock.Any(), "test@example.com").
 					Return(nil, ErrRandomError)
@@ -209,99 +560,172 @@ func TestPVZUC_Login(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			tt.mockSetup()
 
-			token, err := pvzUC.Login(context.Background(), tt.email, tt.password)
+			tokens, err := pvzUC.Login(context.Background(), tt.email, tt.password, "10.0.0.1")
 
 			if tt.expectedError != nil {
 				assert.ErrorIs(t, err, tt.expectedError)
-				assert.Empty(t, token)
+				assert.Empty(t, tokens)
 			} else {
 				assert.NoError(t, err)
-				assert.NotEmpty(t, token)
+				assert.NotEmpty(t, tokens.AccessToken)
+				assert.NotEmpty(t, tokens.RefreshToken)
 			}
 		})
 	}
 }
 
-func TestPVZUC_CreatePVZ(t *testing.T) {
+func TestPVZUC_LoginLockout(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
 
-	cfg := &config.Config{}
+	cfg := &config.Config{
+		App: config.App{
+			LoginMaxAttempts:      3,
+			LoginMaxAttemptsPerIP: 10,
+			LoginLockout:          time.Minute,
+			LoginMaxLockout:       10 * time.Minute,
+			LoginFailureWindow:    time.Hour * 24,
+		},
+	}
 
 	mockRepo := mock_pvz.NewMockRepository(ctrl)
-	pvzUC := NewPVZUseCase(cfg, mockRepo)
+	uc := NewPVZUseCase(cfg, mockRepo).(*pvzUC)
 
-	testUUID := uuid.MustParse("a1b2c3d4-e5f6-7890-1234-567890abcdef")
-	testTime := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	now := time.Date(2025, 4, 10, 12, 0, 0, 0, time.UTC)
+	uc.now = func() time.Time { return now }
+
+	tests := []struct {
+		name     string
+		failures int
+		lockout  time.Duration
+	}{
+		{name: "below the limit", failures: 2, lockout: 0},
+		{name: "limit reached", failures: 3, lockout: time.Minute},
+		{name: "lockout doubles", failures: 4, lockout: 2 * time.Minute},
+		{name: "lockout keeps doubling", failures: 6, lockout: 8 * time.Minute},
+		{name: "lockout is capped", failures: 50, lockout: 10 * time.Minute},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo.EXPECT().
+				ClaimLoginAttempt(gomock.Any(), "account:test@example.com", 3, now, gomock.Any()).
+				Return(tt.failures, nil)
+			mockRepo.EXPECT().
+				GetUserByEmail(gomock.Any(), "test@example.com").
+				Return(nil, db.ErrUserNotFound)
+			if tt.lockout > 0 {
+				mockRepo.EXPECT().
+					LockLoginAttempts(gomock.Any(), "account:test@example.com", now.Add(tt.lockout)).
+					Return(nil)
+			}
+
+			_, err := uc.Login(context.Background(), "test@example.com", "password", "")
+
+			if tt.lockout > 0 {
+				assert.ErrorIs(t, err, ErrLoginLocked)
+			} else {
+				assert.ErrorIs(t, err, ErrInvalidCredentials)
+			}
+		})
+	}
+}
+
+func TestPVZUC_RefreshTokens(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	cfg := &config.Config{
+		App: config.App{
+			JWTSecretKey:    "secret",
+			JWTTokenTTL:     time.Minute * 15,
+			RefreshTokenTTL: time.Hour * 24,
+		},
+	}
+
+	mockRepo := mock_pvz.NewMockRepository(ctrl)
+	pvzUC := NewPVZUseCase(cfg, mockRepo).(*pvzUC)
+
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	pvzUC.now = func() time.Time { return now }
+
+	refreshToken := "refresh-token"
+	revokedAt := now.Add(-time.Minute)
+	activeToken := &models.RefreshToken{
+		ID:        uuid.New(),
+		UserID:    uuid.New(),
+		UserRole:  "employee",
+		TokenHash: hashRefreshToken(refreshToken),
+		ExpiresAt: now.Add(time.Hour),
+	}
+	usedToken := *activeToken
+	usedToken.RevokedAt = &revokedAt
+	expiredToken := *activeToken
+	expiredToken.ExpiresAt = now
 
 	tests := []struct {
 		name          string
-		inputID       *uuid.UUID
-		inputCity     string
-		inputRegDate  *time.Time
 		mockSetup     func()
-		expectedPVZ   models.PVZ
 		expectedError error
 	}{
 		{
-			name:         "success with generated ID and time",
-			inputID:      nil,
-			inputCity:    "Казань",
-			inputRegDate: nil,
+			name: "successful refresh",
 			mockSetup: func() {
-				mockRepo.EXPECT().
-					CreatePVZ(gomock.Any(), gomock.Any()).
-					DoAndReturn(func(ctx context.Context, pvz models.PVZ) error {
-						assert.NotEqual(t, uuid.Nil, pvz.ID)
-						assert.Equal(t, "Казань", pvz.City)
-						assert.False(t, pvz.RegistrationDate.IsZero())
+				mockRepo.EXPECT().GetRefreshToken(gomock.Any(), hashRefreshToken(refreshToken)).Return(activeToken, nil)
+				mockRepo.EXPECT().RotateRefreshToken(gomock.Any(), activeToken.ID, gomock.Any()).DoAndReturn(
+					func(ctx context.Context, oldID uuid.UUID, token models.RefreshToken) error {
+						assert.Equal(t, activeToken.UserID, token.UserID)
+						assert.NotEqual(t, activeToken.TokenHash, token.TokenHash)
+						assert.Equal(t, now.Add(cfg.App.RefreshTokenTTL), token.ExpiresAt)
 						return nil
 					})
 			},
 			expectedError: nil,
 		},
 		{
-			name:         "success with provided ID and time",
-			inputID:      &testUUID,
-			inputCity:    "Казань",
-			inputRegDate: &testTime,
+			name: "unknown token",
 			mockSetup: func() {
-				mockRepo.EXPECT().
-					CreatePVZ(gomock.Any(), models.PVZ{
-						ID:               testUUID,
-						City:             "Казань",
-						RegistrationDate: testTime,
-					}).
-					Return(nil)
+				mockRepo.EXPECT().GetRefreshToken(gomock.Any(), gomock.Any()).Return(nil, db.ErrRefreshTokenNotFound)
 			},
-			expectedPVZ: models.PVZ{
-				ID:               testUUID,
-				City:             "Казань",
-				RegistrationDate: testTime,
+			expectedError: ErrInvalidRefreshToken,
+		},
+		{
+			name: "expired token",
+			mockSetup: func() {
+				mockRepo.EXPECT().GetRefreshToken(gomock.Any(), gomock.Any()).Return(&expiredToken, nil)
 			},
-			expectedError: nil,
+			expectedError: ErrInvalidRefreshToken,
 		},
 		{
-			name:         "duplicate PVZ error",
-			inputID:      &testUUID,
-			inputCity:    "Казань",
-			inputRegDate: &testTime,
+			name: "reused token revokes every session of the user",
 			mockSetup: func() {
-				mockRepo.EXPECT().
-					CreatePVZ(gomock.Any(), gomock.Any()).
-					Return(db.ErrDuplicatePVZ)
+				mockRepo.EXPECT().GetRefreshToken(gomock.Any(), gomock.Any()).Return(&usedToken, nil)
+				mockRepo.EXPECT().RevokeUserRefreshTokens(gomock.Any(), activeToken.UserID).Return(nil)
 			},
-			expectedError: db.ErrDuplicatePVZ,
+			expectedError: ErrInvalidRefreshToken,
 		},
 		{
-			name:         "repository error",
-			inputID:      nil,
-			inputCity:    "Казань",
-			inputRegDate: nil,
+			name: "concurrent reuse revokes every session of the user",
 			mockSetup: func() {
-				mockRepo.EXPECT().
-					CreatePVZ(gomock.Any(), gomock.Any()).
-					Return(ErrRandomError)
+				mockRepo.EXPECT().GetRefreshToken(gomock.Any(), gomock.Any()).Return(activeToken, nil)
+				mockRepo.EXPECT().RotateRefreshToken(gomock.Any(), activeToken.ID, gomock.Any()).Return(db.ErrRefreshTokenRevoked)
+				mockRepo.EXPECT().RevokeUserRefreshTokens(gomock.Any(), activeToken.UserID).Return(nil)
+			},
+			expectedError: ErrInvalidRefreshToken,
+		},
+		{
+			name: "revoke user tokens error",
+			mockSetup: func() {
+				mockRepo.EXPECT().GetRefreshToken(gomock.Any(), gomock.Any()).Return(&usedToken, nil)
+				mockRepo.EXPECT().RevokeUserRefreshTokens(gomock.Any(), activeToken.UserID).Return(ErrRandomError)
+			},
+			expectedError: ErrRandomError,
+		},
+		{
+			name: "rotate error",
+			mockSetup: func() {
+				mockRepo.EXPECT().GetRefreshToken(gomock.Any(), gomock.Any()).Return(activeToken, nil)
+				mockRepo.EXPECT().RotateRefreshToken(gomock.Any(), activeToken.ID, gomock.Any()).Return(ErrRandomError)
 			},
 			expectedError: ErrRandomError,
 		},
@@ -311,83 +735,96 @@ func TestPVZUC_CreatePVZ(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			tt.mockSetup()
 
-			result, err := pvzUC.CreatePVZ(context.Background(), tt.inputID, tt.inputCity, tt.inputRegDate)
+			tokens, err := pvzUC.RefreshTokens(context.Background(), refreshToken)
 
 			if tt.expectedError != nil {
 				assert.ErrorIs(t, err, tt.expectedError)
-				assert.Equal(t, models.PVZ{}, result)
+				assert.Empty(t, tokens)
 			} else {
 				assert.NoError(t, err)
-				if tt.inputID != nil {
-					assert.Equal(t, *tt.inputID, result.ID)
-				} else {
-					assert.NotEqual(t, uuid.Nil, result.ID)
-				}
-				assert.Equal(t, tt.inputCity, result.City)
-				if tt.inputRegDate != nil {
-					assert.Equal(t, *tt.inputRegDate, result.RegistrationDate)
-				} else {
-					assert.False(t, result.RegistrationDate.IsZero())
-				}
+				assert.NotEmpty(t, tokens.AccessToken)
+				assert.NotEqual(t, refreshToken, tokens.RefreshToken)
+
+				parsedToken, err := jwt.Parse(tokens.AccessToken, func(token *jwt.Token) (any, error) {
+					return []byte(cfg.App.JWTSecretKey), nil
+				})
+				assert.NoError(t, err)
+				claims := parsedToken.Claims.(jwt.MapClaims)
+				assert.Equal(t, activeToken.UserID.String(), claims["sub"])
+				assert.Equal(t, activeToken.UserRole, claims["role"])
 			}
 		})
 	}
 }
 
-func TestPVZUC_CreateReception(t *testing.T) {
+func TestPVZUC_Logout(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
 
-	cfg := &config.Config{}
+	cfg := &config.Config{
+		App: config.App{
+			CacheTTL: time.Minute,
+		},
+	}
 
 	mockRepo := mock_pvz.NewMockRepository(ctrl)
 	pvzUC := NewPVZUseCase(cfg, mockRepo)
 
-	testPVZID := uuid.MustParse("a1b2c3d4-e5f6-7890-1234-567890abcdef")
-	testReception := &models.Reception{
-		ID:     uuid.New(),
-		PvzID:  testPVZID,
-		Status: "in_progress",
-	}
+	userID := uuid.New()
+	tokenID := uuid.New()
+	expiresAt := time.Now().Add(time.Minute)
+	refreshToken := "refresh-token"
 
 	tests := []struct {
 		name          string
-		pvzID         uuid.UUID
+		tokenID       uuid.UUID
+		refreshToken  *string
 		mockSetup     func()
-		expected      models.Reception
 		expectedError error
 	}{
 		{
-			name:  "successful reception creation",
-			pvzID: testPVZID,
+			name:         "access and refresh tokens revoked",
+			tokenID:      tokenID,
+			refreshToken: &refreshToken,
 			mockSetup: func() {
-				mockRepo.EXPECT().
-					CreateReception(gomock.Any(), gomock.Any(), testPVZID).
-					Return(testReception, nil)
+				mockRepo.EXPECT().RevokeAccessToken(gomock.Any(), tokenID, expiresAt).Return(nil)
+				mockRepo.EXPECT().RevokeRefreshToken(gomock.Any(), userID, hashRefreshToken(refreshToken)).Return(nil)
 			},
-			expected:      *testReception,
 			expectedError: nil,
 		},
 		{
-			name:  "reception conflict error",
-			pvzID: testPVZID,
+			name:    "only access token revoked",
+			tokenID: tokenID,
 			mockSetup: func() {
-				mockRepo.EXPECT().
-					CreateReception(gomock.Any(), gomock.Any(), testPVZID).
-					Return(nil, db.ErrReceptionConflict)
+				mockRepo.EXPECT().RevokeAccessToken(gomock.Any(), tokenID, expiresAt).Return(nil)
 			},
-			expected:      models.Reception{},
-			expectedError: db.ErrReceptionConflict,
+			expectedError: nil,
 		},
 		{
-			name:  "repository error",
-			pvzID: testPVZID,
+			name:         "token without id",
+			tokenID:      uuid.Nil,
+			refreshToken: &refreshToken,
 			mockSetup: func() {
-				mockRepo.EXPECT().
-					CreateReception(gomock.Any(), gomock.Any(), testPVZID).
-					Return(nil, ErrRandomError)
+				mockRepo.EXPECT().RevokeRefreshToken(gomock.Any(), userID, hashRefreshToken(refreshToken)).Return(nil)
+			},
+			expectedError: nil,
+		},
+		{
+			name:    "revoke access token error",
+			tokenID: tokenID,
+			mockSetup: func() {
+				mockRepo.EXPECT().RevokeAccessToken(gomock.Any(), tokenID, expiresAt).Return(ErrRandomError)
+			},
+			expectedError: ErrRandomError,
+		},
+		{
+			name:         "revoke refresh token error",
+			tokenID:      tokenID,
+			refreshToken: &refreshToken,
+			mockSetup: func() {
+				mockRepo.EXPECT().RevokeAccessToken(gomock.Any(), tokenID, expiresAt).Return(nil)
+				mockRepo.EXPECT().RevokeRefreshToken(gomock.Any(), userID, hashRefreshToken(refreshToken)).Return(ErrRandomError)
 			},
-			expected:      models.Reception{},
 			expectedError: ErrRandomError,
 		},
 	}
@@ -396,82 +833,164 @@ func TestPVZUC_CreateReception(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			tt.mockSetup()
 
-			result, err := pvzUC.CreateReception(context.Background(), tt.pvzID)
+			err := pvzUC.Logout(context.Background(), userID, tt.tokenID, expiresAt, tt.refreshToken)
 
 			if tt.expectedError != nil {
 				assert.ErrorIs(t, err, tt.expectedError)
-				assert.Equal(t, tt.expected, result)
 			} else {
 				assert.NoError(t, err)
-				assert.NotEqual(t, uuid.Nil, result.ID)
-				assert.Equal(t, tt.pvzID, result.PvzID)
-				assert.Equal(t, "in_progress", result.Status)
 			}
 		})
 	}
 }
 
-func TestPVZUC_AddProduct(t *testing.T) {
+func TestPVZUC_IsTokenRevoked(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
 
-	cfg := &config.Config{}
+	cfg := &config.Config{
+		App: config.App{
+			CacheTTL: time.Minute,
+		},
+	}
 
 	mockRepo := mock_pvz.NewMockRepository(ctrl)
 	pvzUC := NewPVZUseCase(cfg, mockRepo)
 
-	testID := uuid.MustParse("a1b2c3d4-e5f6-7890-1234-567890abcdef")
-	testTime := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	revokedID := uuid.New()
+	activeID := uuid.New()
 
-	testProduct := &models.Product{
-		ID:          uuid.New(),
-		Type:        "обувь",
-		DateTime:    testTime,
-		ReceptionID: testID,
+	mockRepo.EXPECT().GetRevokedAccessTokens(gomock.Any()).Return([]uuid.UUID{revokedID}, nil)
+
+	revoked, err := pvzUC.IsTokenRevoked(context.Background(), revokedID)
+	assert.NoError(t, err)
+	assert.True(t, revoked)
+
+	// Served from the cache without hitting the repository again
+	revoked, err = pvzUC.IsTokenRevoked(context.Background(), activeID)
+	assert.NoError(t, err)
+	assert.False(t, revoked)
+
+	// Logout drops the cached denylist so the new entry is seen right away
+	expiresAt := time.Now().Add(time.Minute)
+	mockRepo.EXPECT().RevokeAccessToken(gomock.Any(), activeID, expiresAt).Return(nil)
+	mockRepo.EXPECT().GetRevokedAccessTokens(gomock.Any()).Return([]uuid.UUID{revokedID, activeID}, nil)
+
+	err = pvzUC.Logout(context.Background(), uuid.Nil, activeID, expiresAt, nil)
+	assert.NoError(t, err)
+
+	revoked, err = pvzUC.IsTokenRevoked(context.Background(), activeID)
+	assert.NoError(t, err)
+	assert.True(t, revoked)
+}
+
+func TestPVZUC_HasPermission(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	cfg := &config.Config{
+		App: config.App{
+			CacheTTL: time.Minute,
+		},
+	}
+
+	mockRepo := mock_pvz.NewMockRepository(ctrl)
+	pvzUC := NewPVZUseCase(cfg, mockRepo)
+
+	moderatorID := uuid.New()
+
+	// Permissions of the role are checked without hitting the repository
+	allowed, err := pvzUC.HasPermission(context.Background(), moderatorID, pvzapi.UserRoleModerator, models.PermPVZManage)
+	assert.NoError(t, err)
+	assert.True(t, allowed)
+
+	allowed, err = pvzUC.HasPermission(context.Background(), uuid.Nil, pvzapi.UserRoleModerator, models.PermProductsHandle)
+	assert.NoError(t, err)
+	assert.False(t, allowed)
+
+	allowed, err = pvzUC.HasPermission(context.Background(), uuid.Nil, pvzapi.UserRoleAdmin, models.PermPermissionsManage)
+	assert.NoError(t, err)
+	assert.True(t, allowed)
+
+	mockRepo.EXPECT().GetPermissionGrants(gomock.Any()).Return([]models.PermissionGrant{
+		{UserID: moderatorID, Permission: models.PermProductsHandle},
+	}, nil)
+
+	allowed, err = pvzUC.HasPermission(context.Background(), moderatorID, pvzapi.UserRoleModerator, models.PermProductsHandle)
+	assert.NoError(t, err)
+	assert.True(t, allowed)
+
+	// Served from the cache without hitting the repository again
+	allowed, err = pvzUC.HasPermission(context.Background(), moderatorID, pvzapi.UserRoleModerator, models.PermReceptionsWrite)
+	assert.NoError(t, err)
+	assert.False(t, allowed)
+
+	// Revoking drops the cached grants so the change is seen right away
+	mockRepo.EXPECT().RevokePermission(gomock.Any(), moderatorID, models.PermProductsHandle).Return(nil)
+	mockRepo.EXPECT().GetPermissionGrants(gomock.Any()).Return(nil, nil)
+
+	err = pvzUC.RevokePermission(context.Background(), moderatorID, string(models.PermProductsHandle))
+	assert.NoError(t, err)
+
+	allowed, err = pvzUC.HasPermission(context.Background(), moderatorID, pvzapi.UserRoleModerator, models.PermProductsHandle)
+	assert.NoError(t, err)
+	assert.False(t, allowed)
+}
+
+func TestPVZUC_GrantPermission(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	cfg := &config.Config{}
+
+	mockRepo := mock_pvz.NewMockRepository(ctrl)
+	pvzUC := NewPVZUseCase(cfg, mockRepo)
+
+	adminID := uuid.New()
+	userID := uuid.New()
+	grant := &models.PermissionGrant{
+		UserID:     userID,
+		Permission: models.PermReceptionsReopen,
+		GrantedBy:  &adminID,
+		GrantedAt:  time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC),
 	}
 
 	tests := []struct {
 		name          string
-		pvzID         uuid.UUID
-		productType   string
+		permission    string
 		mockSetup     func()
-		expected      models.Product
+		expectedGrant models.PermissionGrant
 		expectedError error
 	}{
 		{
-			name:        "successful product addition",
-			pvzID:       uuid.New(),
-			productType: "обувь",
+			name:       "successful grant",
+			permission: "receptions:reopen",
 			mockSetup: func() {
-				mockRepo.EXPECT().
-					AddProduct(gomock.Any(), gomock.Any(), gomock.Any(), "обувь").
-					Return(testProduct, nil)
+				mockRepo.EXPECT().GrantPermission(gomock.Any(), userID, models.PermReceptionsReopen, adminID).Return(grant, nil)
 			},
-			expected:      *testProduct,
+			expectedGrant: *grant,
 			expectedError: nil,
 		},
 		{
-			name:        "no open reception error",
-			pvzID:       uuid.New(),
-			productType: "обувь",
+			name:          "unknown permission",
+			permission:    "receptions:delete",
+			mockSetup:     func() {},
+			expectedError: ErrInvalidPermission,
+		},
+		{
+			name:       "user not found",
+			permission: "receptions:reopen",
 			mockSetup: func() {
-				mockRepo.EXPECT().
-					AddProduct(gomock.Any(), gomock.Any(), gomock.Any(), "обувь").
-					Return(nil, db.ErrNoOpenReception)
+				mockRepo.EXPECT().GrantPermission(gomock.Any(), userID, models.PermReceptionsReopen, adminID).Return(nil, db.ErrUserNotFound)
 			},
-			expected:      models.Product{},
-			expectedError: db.ErrNoOpenReception,
+			expectedError: db.ErrUserNotFound,
 		},
 		{
-			name:        "repository error",
-			pvzID:       uuid.New(),
-			productType: "обувь",
+			name:       "repository error",
+			permission: "receptions:reopen",
 			mockSetup: func() {
-				mockRepo.EXPECT().
-					AddProduct(gomock.Any(), gomock.Any(), gomock.Any(), "обувь").
-					Return(nil, ErrRandomError)
+				mockRepo.EXPECT().GrantPermission(gomock.Any(), userID, models.PermReceptionsReopen, adminID).Return(nil, ErrRandomError)
 			},
-			expected:      models.Product{},
 			expectedError: ErrRandomError,
 		},
 	}
@@ -480,22 +999,19 @@ func TestPVZUC_AddProduct(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			tt.mockSetup()
 
-			result, err := pvzUC.AddProduct(context.Background(), tt.pvzID, tt.productType)
+			result, err := pvzUC.GrantPermission(context.Background(), adminID, userID, tt.permission)
 
 			if tt.expectedError != nil {
 				assert.ErrorIs(t, err, tt.expectedError)
-				assert.Equal(t, tt.expected, result)
 			} else {
 				assert.NoError(t, err)
-				assert.NotEqual(t, uuid.Nil, result.ID)
-				assert.Equal(t, tt.productType, result.Type)
-				assert.NotEqual(t, uuid.Nil, result.ReceptionID)
+				assert.Equal(t, tt.expectedGrant, result)
 			}
 		})
 	}
 }
 
-func TestPVZUC_DeleteLastProduct(t *testing.T) {
+func TestPVZUC_RevokePermission(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
 
@@ -504,49 +1020,41 @@ func TestPVZUC_DeleteLastProduct(t *testing.T) {
 	mockRepo := mock_pvz.NewMockRepository(ctrl)
 	pvzUC := NewPVZUseCase(cfg, mockRepo)
 
+	userID := uuid.New()
+
 	tests := []struct {
 		name          string
-		pvzID         uuid.UUID
+		permission    string
 		mockSetup     func()
 		expectedError error
 	}{
 		{
-			name:  "successful product deletion",
-			pvzID: uuid.New(),
+			name:       "successful revoke",
+			permission: "products:handle",
 			mockSetup: func() {
-				mockRepo.EXPECT().
-					DeleteLastProduct(gomock.Any(), gomock.Any()).
-					Return(nil)
+				mockRepo.EXPECT().RevokePermission(gomock.Any(), userID, models.PermProductsHandle).Return(nil)
 			},
 			expectedError: nil,
 		},
 		{
-			name:  "no open reception error",
-			pvzID: uuid.New(),
-			mockSetup: func() {
-				mockRepo.EXPECT().
-					DeleteLastProduct(gomock.Any(), gomock.Any()).
-					Return(db.ErrNoOpenReception)
-			},
-			expectedError: db.ErrNoOpenReception,
+			name:          "unknown permission",
+			permission:    "products:eat",
+			mockSetup:     func() {},
+			expectedError: ErrInvalidPermission,
 		},
 		{
-			name:  "no products error",
-			pvzID: uuid.New(),
+			name:       "not granted",
+			permission: "products:handle",
 			mockSetup: func() {
-				mockRepo.EXPECT().
-					DeleteLastProduct(gomock.Any(), gomock.Any()).
-					Return(db.ErrNoProducts)
+				mockRepo.EXPECT().RevokePermission(gomock.Any(), userID, models.PermProductsHandle).Return(db.ErrPermissionNotGranted)
 			},
-			expectedError: db.ErrNoProducts,
+			expectedError: db.ErrPermissionNotGranted,
 		},
 		{
-			name:  "repository error",
-			pvzID: uuid.New(),
+			name:       "repository error",
+			permission: "products:handle",
 			mockSetup: func() {
-				mockRepo.EXPECT().
-					DeleteLastProduct(gomock.Any(), gomock.Any()).
-					Return(ErrRandomError)
+				mockRepo.EXPECT().RevokePermission(gomock.Any(), userID, models.PermProductsHandle).Return(ErrRandomError)
 			},
 			expectedError: ErrRandomError,
 		},
@@ -556,7 +1064,7 @@ func TestPVZUC_DeleteLastProduct(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			tt.mockSetup()
 
-			err := pvzUC.DeleteLastProduct(context.Background(), tt.pvzID)
+			err := pvzUC.RevokePermission(context.Background(), userID, tt.permission)
 
 			if tt.expectedError != nil {
 				assert.ErrorIs(t, err, tt.expectedError)
@@ -567,7 +1075,7 @@ func TestPVZUC_DeleteLastProduct(t *testing.T) {
 	}
 }
 
-func TestPVZUC_CloseLastReception(t *testing.T) {
+func TestPVZUC_GetUsers(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
 
@@ -576,51 +1084,50 @@ func TestPVZUC_CloseLastReception(t *testing.T) {
 	mockRepo := mock_pvz.NewMockRepository(ctrl)
 	pvzUC := NewPVZUseCase(cfg, mockRepo)
 
-	testReception := &models.Reception{
-		ID:       uuid.New(),
-		PvzID:    uuid.New(),
-		Status:   "in_progress",
-		DateTime: time.Now(),
-	}
+	users := []models.User{{ID: uuid.New(), Email: "test@example.com", Role: "employee"}}
+	page := 3
+	hugeLimit := 1000
+	search := "test"
+	empty := ""
+	role := pvzapi.GetUsersParamsRoleEmployee
+	roleFilter := "employee"
 
 	tests := []struct {
 		name          string
-		pvzID         uuid.UUID
+		params        pvzapi.GetUsersParams
 		mockSetup     func()
-		expected      models.Reception
 		expectedError error
 	}{
 		{
-			name:  "successful reception closing",
-			pvzID: uuid.New(),
+			name:   "defaults",
+			params: pvzapi.GetUsersParams{},
 			mockSetup: func() {
-				mockRepo.EXPECT().
-					CloseLastReception(gomock.Any(), gomock.Any()).
-					Return(testReception, nil)
+				mockRepo.EXPECT().GetUsers(gomock.Any(), nil, nil, uint64(20), uint64(0)).Return(users, nil)
 			},
-			expected:      *testReception,
 			expectedError: nil,
 		},
 		{
-			name:  "no open reception error",
-			pvzID: uuid.New(),
+			name:   "page with filters and limit out of range",
+			params: pvzapi.GetUsersParams{Page: &page, Limit: &hugeLimit, Search: &search, Role: &role},
 			mockSetup: func() {
-				mockRepo.EXPECT().
-					CloseLastReception(gomock.Any(), gomock.Any()).
-					Return(nil, db.ErrNoOpenReception)
+				mockRepo.EXPECT().GetUsers(gomock.Any(), &search, &roleFilter, uint64(20), uint64(40)).Return(users, nil)
 			},
-			expected:      models.Reception{},
-			expectedError: db.ErrNoOpenReception,
+			expectedError: nil,
 		},
 		{
-			name:  "repository error",
-			pvzID: uuid.New(),
+			name:   "empty search is ignored",
+			params: pvzapi.GetUsersParams{Search: &empty},
 			mockSetup: func() {
-				mockRepo.EXPECT().
-					CloseLastReception(gomock.Any(), gomock.Any()).
-					Return(nil, ErrRandomError)
+				mockRepo.EXPECT().GetUsers(gomock.Any(), nil, nil, uint64(20), uint64(0)).Return(users, nil)
+			},
+			expectedError: nil,
+		},
+		{
+			name:   "repository error",
+			params: pvzapi.GetUsersParams{},
+			mockSetup: func() {
+				mockRepo.EXPECT().GetUsers(gomock.Any(), nil, nil, uint64(20), uint64(0)).Return(nil, ErrRandomError)
 			},
-			expected:      models.Reception{},
 			expectedError: ErrRandomError,
 		},
 	}
@@ -629,65 +1136,2628 @@ func TestPVZUC_CloseLastReception(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			tt.mockSetup()
 
-			result, err := pvzUC.CloseLastReception(context.Background(), tt.pvzID)
+			result, err := pvzUC.GetUsers(context.Background(), tt.params)
 
 			if tt.expectedError != nil {
 				assert.ErrorIs(t, err, tt.expectedError)
-				assert.Equal(t, tt.expected, result)
 			} else {
 				assert.NoError(t, err)
-				assert.Equal(t, tt.expected, result)
+				assert.Equal(t, users, result)
 			}
 		})
 	}
 }
 
-func TestPVZUC_GetPVZs(t *testing.T) {
+func TestPVZUC_ChangeUserRole(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
 
 	cfg := &config.Config{}
+
 	mockRepo := mock_pvz.NewMockRepository(ctrl)
 	pvzUC := NewPVZUseCase(cfg, mockRepo)
 
-	now := time.Now()
-	testTime := now.Add(-time.Hour)
+	actorID := uuid.New()
+	userID := uuid.New()
+	employee := &models.User{ID: userID, Email: "test@example.com", Role: "employee"}
+	admin := &models.User{ID: userID, Email: "test@example.com", Role: "admin"}
+	moderator := &models.User{ID: userID, Email: "test@example.com", Role: "moderator"}
 
-	testPVZs := []*models.PVZWithReceptions{
+	tests := []struct {
+		name          string
+		actorID       uuid.UUID
+		actorRole     pvzapi.UserRole
+		role          string
+		mockSetup     func()
+		expectedUser  models.User
+		expectedError error
+	}{
 		{
-			PVZ: models.PVZ{
-				ID:               uuid.New(),
-				City:             "Москва",
-				RegistrationDate: now.AddDate(0, -2, 0),
+			name:      "moderator promotes employee",
+			actorID:   actorID,
+			actorRole: pvzapi.UserRoleModerator,
+			role:      "moderator",
+			mockSetup: func() {
+				mockRepo.EXPECT().GetUser(gomock.Any(), userID).Return(employee, nil)
+				mockRepo.EXPECT().UpdateUserRole(gomock.Any(), userID, "moderator").Return(moderator, nil)
+				mockRepo.EXPECT().RevokeUserRefreshTokens(gomock.Any(), userID).Return(nil)
 			},
-			Receptions: []*models.ReceptionWithProducts{{
-				Reception: models.Reception{
-					ID:       uuid.New(),
-					DateTime: testTime,
-					PvzID:    uuid.New(),
-					Status:   "in_progress",
-				},
-				Products: []*models.Product{{
-					ID:          uuid.New(),
-					Type:        "обувь",
-					DateTime:    testTime,
-					ReceptionID: uuid.New(),
-				}},
-			}},
+			expectedUser:  *moderator,
+			expectedError: nil,
 		},
 		{
-			PVZ: models.PVZ{
-				ID:               uuid.New(),
-				City:             "Москва",
-				RegistrationDate: now.AddDate(0, -1, 0),
+			name:      "admin grants admin role",
+			actorID:   actorID,
+			actorRole: pvzapi.UserRoleAdmin,
+			role:      "admin",
+			mockSetup: func() {
+				mockRepo.EXPECT().GetUser(gomock.Any(), userID).Return(employee, nil)
+				mockRepo.EXPECT().UpdateUserRole(gomock.Any(), userID, "admin").Return(admin, nil)
+				mockRepo.EXPECT().RevokeUserRefreshTokens(gomock.Any(), userID).Return(nil)
 			},
-			Receptions: []*models.ReceptionWithProducts{{
-				Reception: models.Reception{
-					ID:       uuid.New(),
-					DateTime: testTime.Add(-time.Hour),
-					PvzID:    uuid.New(),
-					Status:   "in_progress",
-				},
+			expectedUser:  *admin,
+			expectedError: nil,
+		},
+		{
+			name:          "invalid role",
+			actorID:       actorID,
+			actorRole:     pvzapi.UserRoleModerator,
+			role:          "superuser",
+			mockSetup:     func() {},
+			expectedError: ErrInvalidRole,
+		},
+		{
+			name:          "own role",
+			actorID:       userID,
+			actorRole:     pvzapi.UserRoleModerator,
+			role:          "employee",
+			mockSetup:     func() {},
+			expectedError: ErrSelfManagement,
+		},
+		{
+			name:      "moderator grants admin role",
+			actorID:   uuid.Nil,
+			actorRole: pvzapi.UserRoleModerator,
+			role:      "admin",
+			mockSetup: func() {
+				mockRepo.EXPECT().GetUser(gomock.Any(), userID).Return(employee, nil)
+			},
+			expectedError: ErrAdminRequired,
+		},
+		{
+			name:      "moderator demotes admin",
+			actorID:   uuid.Nil,
+			actorRole: pvzapi.UserRoleModerator,
+			role:      "employee",
+			mockSetup: func() {
+				mockRepo.EXPECT().GetUser(gomock.Any(), userID).Return(admin, nil)
+			},
+			expectedError: ErrAdminRequired,
+		},
+		{
+			name:      "user not found",
+			actorID:   actorID,
+			actorRole: pvzapi.UserRoleModerator,
+			role:      "moderator",
+			mockSetup: func() {
+				mockRepo.EXPECT().GetUser(gomock.Any(), userID).Return(nil, db.ErrUserNotFound)
+			},
+			expectedError: db.ErrUserNotFound,
+		},
+		{
+			name:      "repository error",
+			actorID:   actorID,
+			actorRole: pvzapi.UserRoleModerator,
+			role:      "moderator",
+			mockSetup: func() {
+				mockRepo.EXPECT().GetUser(gomock.Any(), userID).Return(employee, nil)
+				mockRepo.EXPECT().UpdateUserRole(gomock.Any(), userID, "moderator").Return(nil, ErrRandomError)
+			},
+			expectedError: ErrRandomError,
+		},
+		{
+			name:      "revoke refresh tokens error",
+			actorID:   actorID,
+			actorRole: pvzapi.UserRoleModerator,
+			role:      "moderator",
+			mockSetup: func() {
+				mockRepo.EXPECT().GetUser(gomock.Any(), userID).Return(employee, nil)
+				mockRepo.EXPECT().UpdateUserRole(gomock.Any(), userID, "moderator").Return(moderator, nil)
+				mockRepo.EXPECT().RevokeUserRefreshTokens(gomock.Any(), userID).Return(ErrRandomError)
+			},
+			expectedError: ErrRandomError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockSetup()
+
+			result, err := pvzUC.ChangeUserRole(context.Background(), tt.actorID, tt.actorRole, userID, tt.role)
+
+			if tt.expectedError != nil {
+				assert.ErrorIs(t, err, tt.expectedError)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.expectedUser, result)
+			}
+		})
+	}
+}
+
+func TestPVZUC_IsRoleChanged(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	cfg := &config.Config{
+		App: config.App{
+			CacheTTL: time.Minute,
+		},
+	}
+
+	mockRepo := mock_pvz.NewMockRepository(ctrl)
+	pvzUC := NewPVZUseCase(cfg, mockRepo)
+
+	actorID := uuid.New()
+	userID := uuid.New()
+	employee := &models.User{ID: userID, Email: "test@example.com", Role: "employee"}
+	moderator := &models.User{ID: userID, Email: "test@example.com", Role: "moderator"}
+
+	mockRepo.EXPECT().GetUserRoles(gomock.Any()).Return([]models.User{{ID: userID, Role: "employee"}}, nil)
+
+	changed, err := pvzUC.IsRoleChanged(context.Background(), userID, pvzapi.UserRoleEmployee)
+	assert.NoError(t, err)
+	assert.False(t, changed)
+
+	changed, err = pvzUC.IsRoleChanged(context.Background(), uuid.New(), pvzapi.UserRoleEmployee)
+	assert.NoError(t, err)
+	assert.False(t, changed, "unknown users are left to the other checks")
+
+	// Role change drops the cached roles so tokens issued for the old role stop working right away
+	mockRepo.EXPECT().GetUser(gomock.Any(), userID).Return(employee, nil)
+	mockRepo.EXPECT().UpdateUserRole(gomock.Any(), userID, "moderator").Return(moderator, nil)
+	mockRepo.EXPECT().RevokeUserRefreshTokens(gomock.Any(), userID).Return(nil)
+	mockRepo.EXPECT().GetUserRoles(gomock.Any()).Return([]models.User{{ID: userID, Role: "moderator"}}, nil)
+
+	_, err = pvzUC.ChangeUserRole(context.Background(), actorID, pvzapi.UserRoleModerator, userID, "moderator")
+	assert.NoError(t, err)
+
+	changed, err = pvzUC.IsRoleChanged(context.Background(), userID, pvzapi.UserRoleEmployee)
+	assert.NoError(t, err)
+	assert.True(t, changed)
+
+	changed, err = pvzUC.IsRoleChanged(context.Background(), userID, pvzapi.UserRoleModerator)
+	assert.NoError(t, err)
+	assert.False(t, changed)
+
+	mockRepo.EXPECT().GetUserRoles(gomock.Any()).Return(nil, ErrRandomError)
+
+	_, err = NewPVZUseCase(cfg, mockRepo).IsRoleChanged(context.Background(), userID, pvzapi.UserRoleEmployee)
+	assert.ErrorIs(t, err, ErrRandomError)
+}
+
+func TestPVZUC_SetUserActive(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	cfg := &config.Config{
+		App: config.App{
+			CacheTTL: time.Minute,
+		},
+	}
+
+	mockRepo := mock_pvz.NewMockRepository(ctrl)
+	pvzUC := NewPVZUseCase(cfg, mockRepo)
+
+	actorID := uuid.New()
+	userID := uuid.New()
+	deactivatedAt := time.Now()
+	employee := &models.User{ID: userID, Email: "test@example.com", Role: "employee"}
+	deactivated := &models.User{ID: userID, Email: "test@example.com", Role: "employee", DeactivatedAt: &deactivatedAt}
+
+	mockRepo.EXPECT().GetDeactivatedUsers(gomock.Any()).Return([]uuid.UUID{}, nil)
+
+	isDeactivated, err := pvzUC.IsUserDeactivated(context.Background(), userID)
+	assert.NoError(t, err)
+	assert.False(t, isDeactivated)
+
+	// Deactivation ends the sessions and drops the cached list so the user is locked out right away
+	mockRepo.EXPECT().GetUser(gomock.Any(), userID).Return(employee, nil)
+	mockRepo.EXPECT().SetUserDeactivated(gomock.Any(), userID, true).Return(deactivated, nil)
+	mockRepo.EXPECT().RevokeUserRefreshTokens(gomock.Any(), userID).Return(nil)
+	mockRepo.EXPECT().GetDeactivatedUsers(gomock.Any()).Return([]uuid.UUID{userID}, nil)
+
+	user, err := pvzUC.SetUserActive(context.Background(), actorID, pvzapi.UserRoleModerator, userID, false)
+	assert.NoError(t, err)
+	assert.Equal(t, *deactivated, user)
+
+	isDeactivated, err = pvzUC.IsUserDeactivated(context.Background(), userID)
+	assert.NoError(t, err)
+	assert.True(t, isDeactivated)
+
+	mockRepo.EXPECT().GetUser(gomock.Any(), userID).Return(deactivated, nil)
+	mockRepo.EXPECT().SetUserDeactivated(gomock.Any(), userID, false).Return(employee, nil)
+	mockRepo.EXPECT().GetDeactivatedUsers(gomock.Any()).Return([]uuid.UUID{}, nil)
+
+	user, err = pvzUC.SetUserActive(context.Background(), actorID, pvzapi.UserRoleModerator, userID, true)
+	assert.NoError(t, err)
+	assert.Equal(t, *employee, user)
+
+	isDeactivated, err = pvzUC.IsUserDeactivated(context.Background(), userID)
+	assert.NoError(t, err)
+	assert.False(t, isDeactivated)
+
+	_, err = pvzUC.SetUserActive(context.Background(), userID, pvzapi.UserRoleModerator, userID, false)
+	assert.ErrorIs(t, err, ErrSelfManagement)
+
+	mockRepo.EXPECT().GetUser(gomock.Any(), userID).Return(employee, nil)
+	mockRepo.EXPECT().SetUserDeactivated(gomock.Any(), userID, true).Return(nil, ErrRandomError)
+
+	_, err = pvzUC.SetUserActive(context.Background(), actorID, pvzapi.UserRoleModerator, userID, false)
+	assert.ErrorIs(t, err, ErrRandomError)
+}
+
+func TestPVZUC_CreatePVZ(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	cfg := &config.Config{}
+
+	mockRepo := mock_pvz.NewMockRepository(ctrl)
+	pvzUC := NewPVZUseCase(cfg, mockRepo)
+
+	testUUID := uuid.MustParse("a1b2c3d4-e5f6-7890-1234-567890abcdef")
+	testTime := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name          string
+		inputID       *uuid.UUID
+		inputCity     string
+		inputRegDate  *time.Time
+		mockSetup     func()
+		expectedPVZ   models.PVZ
+		expectedError error
+	}{
+		{
+			name:         "success with generated ID and time",
+			inputID:      nil,
+			inputCity:    "Казань",
+			inputRegDate: nil,
+			mockSetup: func() {
+				mockRepo.EXPECT().GetCities(gomock.Any()).Return(testCities, nil)
+				mockRepo.EXPECT().
+					CreatePVZ(gomock.Any(), gomock.Any()).
+					DoAndReturn(func(ctx context.Context, pvz models.PVZ) error {
+						assert.NotEqual(t, uuid.Nil, pvz.ID)
+						assert.Equal(t, "Казань", pvz.City)
+						assert.False(t, pvz.RegistrationDate.IsZero())
+						assert.Equal(t, "active", pvz.Status)
+						return nil
+					})
+			},
+			expectedError: nil,
+		},
+		{
+			name:         "success with provided ID and time",
+			inputID:      &testUUID,
+			inputCity:    "Казань",
+			inputRegDate: &testTime,
+			mockSetup: func() {
+				mockRepo.EXPECT().GetCities(gomock.Any()).Return(testCities, nil)
+				mockRepo.EXPECT().
+					CreatePVZ(gomock.Any(), models.PVZ{
+						ID:               testUUID,
+						City:             "Казань",
+						RegistrationDate: testTime,
+						Status:           "active",
+					}).
+					Return(nil)
+			},
+			expectedPVZ: models.PVZ{
+				ID:               testUUID,
+				City:             "Казань",
+				RegistrationDate: testTime,
+				Status:           "active",
+			},
+			expectedError: nil,
+		},
+		{
+			name:         "duplicate PVZ error",
+			inputID:      &testUUID,
+			inputCity:    "Казань",
+			inputRegDate: &testTime,
+			mockSetup: func() {
+				mockRepo.EXPECT().GetCities(gomock.Any()).Return(testCities, nil)
+				mockRepo.EXPECT().
+					CreatePVZ(gomock.Any(), gomock.Any()).
+					Return(db.ErrDuplicatePVZ)
+			},
+			expectedError: db.ErrDuplicatePVZ,
+		},
+		{
+			name:         "unknown city",
+			inputID:      nil,
+			inputCity:    "Астана",
+			inputRegDate: nil,
+			mockSetup: func() {
+				mockRepo.EXPECT().GetCities(gomock.Any()).Return(testCities, nil)
+			},
+			expectedError: ErrInvalidCity,
+		},
+		{
+			name:         "city deleted concurrently",
+			inputID:      nil,
+			inputCity:    "Казань",
+			inputRegDate: nil,
+			mockSetup: func() {
+				mockRepo.EXPECT().GetCities(gomock.Any()).Return(testCities, nil)
+				mockRepo.EXPECT().
+					CreatePVZ(gomock.Any(), gomock.Any()).
+					Return(db.ErrCityNotFound)
+			},
+			expectedError: ErrInvalidCity,
+		},
+		{
+			name:         "cities loading error",
+			inputID:      nil,
+			inputCity:    "Казань",
+			inputRegDate: nil,
+			mockSetup: func() {
+				mockRepo.EXPECT().GetCities(gomock.Any()).Return(nil, ErrRandomError)
+			},
+			expectedError: ErrRandomError,
+		},
+		{
+			name:         "repository error",
+			inputID:      nil,
+			inputCity:    "Казань",
+			inputRegDate: nil,
+			mockSetup: func() {
+				mockRepo.EXPECT().GetCities(gomock.Any()).Return(testCities, nil)
+				mockRepo.EXPECT().
+					CreatePVZ(gomock.Any(), gomock.Any()).
+					Return(ErrRandomError)
+			},
+			expectedError: ErrRandomError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockSetup()
+
+			result, err := pvzUC.CreatePVZ(context.Background(), tt.inputID, tt.inputCity, tt.inputRegDate)
+
+			if tt.expectedError != nil {
+				assert.ErrorIs(t, err, tt.expectedError)
+				assert.Equal(t, models.PVZ{}, result)
+			} else {
+				assert.NoError(t, err)
+				if tt.inputID != nil {
+					assert.Equal(t, *tt.inputID, result.ID)
+				} else {
+					assert.NotEqual(t, uuid.Nil, result.ID)
+				}
+				assert.Equal(t, tt.inputCity, result.City)
+				if tt.inputRegDate != nil {
+					assert.Equal(t, *tt.inputRegDate, result.RegistrationDate)
+				} else {
+					assert.False(t, result.RegistrationDate.IsZero())
+				}
+			}
+		})
+	}
+}
+
+func TestPVZUC_UpdatePVZ(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	cfg := &config.Config{}
+
+	mockRepo := mock_pvz.NewMockRepository(ctrl)
+	pvzUC := NewPVZUseCase(cfg, mockRepo)
+
+	testPVZID := uuid.MustParse("a1b2c3d4-e5f6-7890-1234-567890abcdef")
+	newStatus := "suspended"
+	testPVZ := &models.PVZ{
+		ID:               testPVZID,
+		City:             "Казань",
+		RegistrationDate: time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC),
+		Status:           newStatus,
+	}
+
+	tests := []struct {
+		name          string
+		mockSetup     func()
+		expected      models.PVZ
+		expectedError error
+	}{
+		{
+			name: "successful update",
+			mockSetup: func() {
+				mockRepo.EXPECT().
+					UpdatePVZ(gomock.Any(), testPVZID, nil, &newStatus).
+					Return(testPVZ, nil)
+			},
+			expected:      *testPVZ,
+			expectedError: nil,
+		},
+		{
+			name: "pvz not found error",
+			mockSetup: func() {
+				mockRepo.EXPECT().
+					UpdatePVZ(gomock.Any(), testPVZID, nil, &newStatus).
+					Return(nil, db.ErrPVZNotFound)
+			},
+			expected:      models.PVZ{},
+			expectedError: db.ErrPVZNotFound,
+		},
+		{
+			name: "repository error",
+			mockSetup: func() {
+				mockRepo.EXPECT().
+					UpdatePVZ(gomock.Any(), testPVZID, nil, &newStatus).
+					Return(nil, ErrRandomError)
+			},
+			expected:      models.PVZ{},
+			expectedError: ErrRandomError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockSetup()
+
+			result, err := pvzUC.UpdatePVZ(context.Background(), testPVZID, nil, &newStatus)
+
+			if tt.expectedError != nil {
+				assert.ErrorIs(t, err, tt.expectedError)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, tt.expected, result)
+		})
+	}
+}
+
+func TestPVZUC_CitiesCache(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	cfg := &config.Config{
+		App: config.App{
+			CacheTTL: time.Hour,
+		},
+	}
+
+	mockRepo := mock_pvz.NewMockRepository(ctrl)
+	pvzUC := NewPVZUseCase(cfg, mockRepo)
+
+	newCity := &models.City{ID: uuid.New(), Name: "Астана"}
+
+	gomock.InOrder(
+		mockRepo.EXPECT().GetCities(gomock.Any()).Return(testCities, nil),
+		mockRepo.EXPECT().CreateCity(gomock.Any(), gomock.Any()).Return(newCity, nil),
+		mockRepo.EXPECT().GetCities(gomock.Any()).Return(append(testCities, *newCity), nil),
+		mockRepo.EXPECT().CreatePVZ(gomock.Any(), gomock.Any()).Return(nil),
+	)
+
+	_, err := pvzUC.CreatePVZ(context.Background(), nil, "Астана", nil)
+	assert.ErrorIs(t, err, ErrInvalidCity)
+
+	_, err = pvzUC.CreatePVZ(context.Background(), nil, "Астана", nil)
+	assert.ErrorIs(t, err, ErrInvalidCity, "unknown city must be served from cache")
+
+	_, err = pvzUC.CreateCity(context.Background(), "Астана")
+	assert.NoError(t, err)
+
+	_, err = pvzUC.CreatePVZ(context.Background(), nil, "Астана", nil)
+	assert.NoError(t, err)
+}
+
+func TestPVZUC_CreateCity(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	cfg := &config.Config{}
+
+	mockRepo := mock_pvz.NewMockRepository(ctrl)
+	pvzUC := NewPVZUseCase(cfg, mockRepo)
+
+	testCity := &models.City{
+		ID:        uuid.New(),
+		Name:      "Астана",
+		CreatedAt: time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC),
+	}
+
+	tests := []struct {
+		name          string
+		mockSetup     func()
+		expected      models.City
+		expectedError error
+	}{
+		{
+			name: "successful creation",
+			mockSetup: func() {
+				mockRepo.EXPECT().
+					CreateCity(gomock.Any(), gomock.Any()).
+					DoAndReturn(func(ctx context.Context, city models.City) (*models.City, error) {
+						assert.NotEqual(t, uuid.Nil, city.ID)
+						assert.Equal(t, "Астана", city.Name)
+						return testCity, nil
+					})
+			},
+			expected:      *testCity,
+			expectedError: nil,
+		},
+		{
+			name: "duplicate city",
+			mockSetup: func() {
+				mockRepo.EXPECT().
+					CreateCity(gomock.Any(), gomock.Any()).
+					Return(nil, db.ErrDuplicateCity)
+			},
+			expected:      models.City{},
+			expectedError: db.ErrDuplicateCity,
+		},
+		{
+			name: "repository error",
+			mockSetup: func() {
+				mockRepo.EXPECT().
+					CreateCity(gomock.Any(), gomock.Any()).
+					Return(nil, ErrRandomError)
+			},
+			expected:      models.City{},
+			expectedError: ErrRandomError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockSetup()
+
+			result, err := pvzUC.CreateCity(context.Background(), "Астана")
+
+			if tt.expectedError != nil {
+				assert.ErrorIs(t, err, tt.expectedError)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, tt.expected, result)
+		})
+	}
+}
+
+func TestPVZUC_UpdateCity(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	cfg := &config.Config{}
+
+	mockRepo := mock_pvz.NewMockRepository(ctrl)
+	pvzUC := NewPVZUseCase(cfg, mockRepo)
+
+	cityID := uuid.New()
+	testCity := &models.City{ID: cityID, Name: "Нур-Султан"}
+
+	tests := []struct {
+		name          string
+		mockSetup     func()
+		expected      models.City
+		expectedError error
+	}{
+		{
+			name: "successful rename",
+			mockSetup: func() {
+				mockRepo.EXPECT().
+					UpdateCity(gomock.Any(), cityID, "Нур-Султан").
+					Return(testCity, nil)
+			},
+			expected:      *testCity,
+			expectedError: nil,
+		},
+		{
+			name: "city not found",
+			mockSetup: func() {
+				mockRepo.EXPECT().
+					UpdateCity(gomock.Any(), cityID, "Нур-Султан").
+					Return(nil, db.ErrCityNotFound)
+			},
+			expected:      models.City{},
+			expectedError: db.ErrCityNotFound,
+		},
+		{
+			name: "duplicate city",
+			mockSetup: func() {
+				mockRepo.EXPECT().
+					UpdateCity(gomock.Any(), cityID, "Нур-Султан").
+					Return(nil, db.ErrDuplicateCity)
+			},
+			expected:      models.City{},
+			expectedError: db.ErrDuplicateCity,
+		},
+		{
+			name: "repository error",
+			mockSetup: func() {
+				mockRepo.EXPECT().
+					UpdateCity(gomock.Any(), cityID, "Нур-Султан").
+					Return(nil, ErrRandomError)
+			},
+			expected:      models.City{},
+			expectedError: ErrRandomError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockSetup()
+
+			result, err := pvzUC.UpdateCity(context.Background(), cityID, "Нур-Султан")
+
+			if tt.expectedError != nil {
+				assert.ErrorIs(t, err, tt.expectedError)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, tt.expected, result)
+		})
+	}
+}
+
+func TestPVZUC_DeleteCity(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	cfg := &config.Config{}
+
+	mockRepo := mock_pvz.NewMockRepository(ctrl)
+	pvzUC := NewPVZUseCase(cfg, mockRepo)
+
+	cityID := uuid.New()
+
+	tests := []struct {
+		name          string
+		mockSetup     func()
+		expectedError error
+	}{
+		{
+			name: "successful deletion",
+			mockSetup: func() {
+				mockRepo.EXPECT().DeleteCity(gomock.Any(), cityID).Return(nil)
+			},
+			expectedError: nil,
+		},
+		{
+			name: "city not found",
+			mockSetup: func() {
+				mockRepo.EXPECT().DeleteCity(gomock.Any(), cityID).Return(db.ErrCityNotFound)
+			},
+			expectedError: db.ErrCityNotFound,
+		},
+		{
+			name: "city in use",
+			mockSetup: func() {
+				mockRepo.EXPECT().DeleteCity(gomock.Any(), cityID).Return(db.ErrCityInUse)
+			},
+			expectedError: db.ErrCityInUse,
+		},
+		{
+			name: "repository error",
+			mockSetup: func() {
+				mockRepo.EXPECT().DeleteCity(gomock.Any(), cityID).Return(ErrRandomError)
+			},
+			expectedError: ErrRandomError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockSetup()
+
+			err := pvzUC.DeleteCity(context.Background(), cityID)
+
+			if tt.expectedError != nil {
+				assert.ErrorIs(t, err, tt.expectedError)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestPVZUC_ProductTypesCache(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	cfg := &config.Config{
+		App: config.App{
+			CacheTTL: time.Hour,
+		},
+	}
+
+	mockRepo := mock_pvz.NewMockRepository(ctrl)
+	pvzUC := NewPVZUseCase(cfg, mockRepo)
+
+	userID := uuid.New()
+	typeID := testProductTypes[0].ID
+	retired := append([]models.ProductType{}, testProductTypes...)
+	retired[0].RetiredAt = &retiredAt
+
+	mockRepo.EXPECT().IsEmployeeAssigned(gomock.Any(), gomock.Any(), userID).Return(true, nil).Times(3)
+
+	gomock.InOrder(
+		mockRepo.EXPECT().GetProductTypes(gomock.Any()).Return(testProductTypes, nil),
+		mockRepo.EXPECT().AddProduct(gomock.Any(), gomock.Any(), gomock.Any(), userID, "обувь", nil, nil).Return(&models.Product{Type: "обувь"}, nil),
+		mockRepo.EXPECT().AddProduct(gomock.Any(), gomock.Any(), gomock.Any(), userID, "обувь", nil, nil).Return(&models.Product{Type: "обувь"}, nil),
+		mockRepo.EXPECT().RetireProductType(gomock.Any(), typeID).Return(nil),
+		mockRepo.EXPECT().GetProductTypes(gomock.Any()).Return(retired, nil),
+	)
+
+	_, err := pvzUC.AddProduct(context.Background(), userID, uuid.New(), "обувь", nil, nil)
+	assert.NoError(t, err)
+
+	_, err = pvzUC.AddProduct(context.Background(), userID, uuid.New(), "обувь", nil, nil)
+	assert.NoError(t, err, "catalog must be served from cache")
+
+	err = pvzUC.RetireProductType(context.Background(), typeID)
+	assert.NoError(t, err)
+
+	_, err = pvzUC.AddProduct(context.Background(), userID, uuid.New(), "обувь", nil, nil)
+	assert.ErrorIs(t, err, ErrInvalidType)
+}
+
+func TestPVZUC_CreateProductType(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	cfg := &config.Config{}
+
+	mockRepo := mock_pvz.NewMockRepository(ctrl)
+	pvzUC := NewPVZUseCase(cfg, mockRepo)
+
+	testType := &models.ProductType{
+		ID:          uuid.New(),
+		Name:        "бытовая техника",
+		DisplayName: "Бытовая техника",
+	}
+
+	tests := []struct {
+		name          string
+		mockSetup     func()
+		expected      models.ProductType
+		expectedError error
+	}{
+		{
+			name: "successful creation",
+			mockSetup: func() {
+				mockRepo.EXPECT().CreateProductType(gomock.Any(), gomock.Any()).Return(testType, nil)
+			},
+			expected:      *testType,
+			expectedError: nil,
+		},
+		{
+			name: "duplicate type",
+			mockSetup: func() {
+				mockRepo.EXPECT().CreateProductType(gomock.Any(), gomock.Any()).Return(nil, db.ErrDuplicateType)
+			},
+			expected:      models.ProductType{},
+			expectedError: db.ErrDuplicateType,
+		},
+		{
+			name: "repository error",
+			mockSetup: func() {
+				mockRepo.EXPECT().CreateProductType(gomock.Any(), gomock.Any()).Return(nil, ErrRandomError)
+			},
+			expected:      models.ProductType{},
+			expectedError: ErrRandomError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockSetup()
+
+			result, err := pvzUC.CreateProductType(context.Background(), testType.Name, testType.DisplayName)
+
+			if tt.expectedError != nil {
+				assert.ErrorIs(t, err, tt.expectedError)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, tt.expected, result)
+		})
+	}
+}
+
+func TestPVZUC_RetireProductType(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	cfg := &config.Config{}
+
+	mockRepo := mock_pvz.NewMockRepository(ctrl)
+	pvzUC := NewPVZUseCase(cfg, mockRepo)
+
+	typeID := uuid.New()
+
+	tests := []struct {
+		name          string
+		mockSetup     func()
+		expectedError error
+	}{
+		{
+			name: "successful retirement",
+			mockSetup: func() {
+				mockRepo.EXPECT().RetireProductType(gomock.Any(), typeID).Return(nil)
+			},
+			expectedError: nil,
+		},
+		{
+			name: "type not found",
+			mockSetup: func() {
+				mockRepo.EXPECT().RetireProductType(gomock.Any(), typeID).Return(db.ErrTypeNotFound)
+			},
+			expectedError: db.ErrTypeNotFound,
+		},
+		{
+			name: "repository error",
+			mockSetup: func() {
+				mockRepo.EXPECT().RetireProductType(gomock.Any(), typeID).Return(ErrRandomError)
+			},
+			expectedError: ErrRandomError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockSetup()
+
+			err := pvzUC.RetireProductType(context.Background(), typeID)
+
+			if tt.expectedError != nil {
+				assert.ErrorIs(t, err, tt.expectedError)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestPVZUC_CheckAssignment(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	cfg := &config.Config{}
+
+	mockRepo := mock_pvz.NewMockRepository(ctrl)
+	uc := NewPVZUseCase(cfg, mockRepo).(*pvzUC)
+
+	userID := uuid.New()
+	pvzID := uuid.New()
+
+	tests := []struct {
+		name          string
+		userID        uuid.UUID
+		mockSetup     func()
+		expectedError error
+	}{
+		{
+			name:   "assigned employee",
+			userID: userID,
+			mockSetup: func() {
+				mockRepo.EXPECT().IsEmployeeAssigned(gomock.Any(), pvzID, userID).Return(true, nil)
+			},
+			expectedError: nil,
+		},
+		{
+			name:   "dummy login employee is not bound to pvzs",
+			userID: uuid.Nil,
+			mockSetup: func() {
+			},
+			expectedError: nil,
+		},
+		{
+			name:   "employee not assigned",
+			userID: userID,
+			mockSetup: func() {
+				mockRepo.EXPECT().IsEmployeeAssigned(gomock.Any(), pvzID, userID).Return(false, nil)
+			},
+			expectedError: ErrPVZAccessDenied,
+		},
+		{
+			name:   "repository error",
+			userID: userID,
+			mockSetup: func() {
+				mockRepo.EXPECT().IsEmployeeAssigned(gomock.Any(), pvzID, userID).Return(false, ErrRandomError)
+			},
+			expectedError: ErrRandomError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockSetup()
+
+			err := uc.checkAssignment(context.Background(), tt.userID, pvzID)
+
+			if tt.expectedError != nil {
+				assert.ErrorIs(t, err, tt.expectedError)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestPVZUC_CreateReception(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	cfg := &config.Config{}
+
+	mockRepo := mock_pvz.NewMockRepository(ctrl)
+	pvzUC := NewPVZUseCase(cfg, mockRepo)
+
+	userID := uuid.New()
+
+	testPVZID := uuid.MustParse("a1b2c3d4-e5f6-7890-1234-567890abcdef")
+	testReception := &models.Reception{
+		ID:     uuid.New(),
+		PvzID:  testPVZID,
+		Status: "in_progress",
+	}
+
+	testManifest := []models.ManifestItem{
+		{Type: "обувь", Count: 3},
+		{Type: "одежда", Count: 1},
+	}
+
+	testBarcodes := []models.ManifestBarcode{
+		{Barcode: "4600000000017", Type: "обувь"},
+		{Barcode: "4600000000024", Type: "одежда"},
+	}
+
+	tests := []struct {
+		name          string
+		pvzID         uuid.UUID
+		manifest      []models.ManifestItem
+		barcodes      []models.ManifestBarcode
+		mockSetup     func()
+		expected      models.Reception
+		expectedError error
+	}{
+		{
+			name:  "successful reception creation",
+			pvzID: testPVZID,
+			mockSetup: func() {
+				mockRepo.EXPECT().IsEmployeeAssigned(gomock.Any(), gomock.Any(), userID).Return(true, nil)
+				mockRepo.EXPECT().
+					CreateReception(gomock.Any(), gomock.Any(), testPVZID, userID, gomock.Nil(), gomock.Nil()).
+					Return(testReception, nil)
+			},
+			expected:      *testReception,
+			expectedError: nil,
+		},
+		{
+			name:  "reception conflict error",
+			pvzID: testPVZID,
+			mockSetup: func() {
+				mockRepo.EXPECT().IsEmployeeAssigned(gomock.Any(), gomock.Any(), userID).Return(true, nil)
+				mockRepo.EXPECT().
+					CreateReception(gomock.Any(), gomock.Any(), testPVZID, userID, gomock.Nil(), gomock.Nil()).
+					Return(nil, db.ErrReceptionConflict)
+			},
+			expected:      models.Reception{},
+			expectedError: db.ErrReceptionConflict,
+		},
+		{
+			name:  "pvz not active error",
+			pvzID: testPVZID,
+			mockSetup: func() {
+				mockRepo.EXPECT().IsEmployeeAssigned(gomock.Any(), gomock.Any(), userID).Return(true, nil)
+				mockRepo.EXPECT().
+					CreateReception(gomock.Any(), gomock.Any(), testPVZID, userID, gomock.Nil(), gomock.Nil()).
+					Return(nil, db.ErrPVZNotActive)
+			},
+			expected:      models.Reception{},
+			expectedError: db.ErrPVZNotActive,
+		},
+		{
+			name:  "repository error",
+			pvzID: testPVZID,
+			mockSetup: func() {
+				mockRepo.EXPECT().IsEmployeeAssigned(gomock.Any(), gomock.Any(), userID).Return(true, nil)
+				mockRepo.EXPECT().
+					CreateReception(gomock.Any(), gomock.Any(), testPVZID, userID, gomock.Nil(), gomock.Nil()).
+					Return(nil, ErrRandomError)
+			},
+			expected:      models.Reception{},
+			expectedError: ErrRandomError,
+		},
+		{
+			name:     "reception with manifest",
+			pvzID:    testPVZID,
+			manifest: testManifest,
+			mockSetup: func() {
+				mockRepo.EXPECT().IsEmployeeAssigned(gomock.Any(), gomock.Any(), userID).Return(true, nil)
+				mockRepo.EXPECT().GetProductTypes(gomock.Any()).Return(testProductTypes, nil).Times(2)
+				mockRepo.EXPECT().
+					CreateReception(gomock.Any(), gomock.Any(), testPVZID, userID, testManifest, gomock.Nil()).
+					Return(testReception, nil)
+			},
+			expected:      *testReception,
+			expectedError: nil,
+		},
+		{
+			name:     "non-positive manifest count",
+			pvzID:    testPVZID,
+			manifest: []models.ManifestItem{{Type: "обувь", Count: 0}},
+			mockSetup: func() {
+				mockRepo.EXPECT().IsEmployeeAssigned(gomock.Any(), gomock.Any(), userID).Return(true, nil)
+			},
+			expected:      models.Reception{},
+			expectedError: ErrInvalidManifest,
+		},
+		{
+			name:  "duplicate manifest type",
+			pvzID: testPVZID,
+			manifest: []models.ManifestItem{
+				{Type: "обувь", Count: 1},
+				{Type: "обувь", Count: 2},
+			},
+			mockSetup: func() {
+				mockRepo.EXPECT().IsEmployeeAssigned(gomock.Any(), gomock.Any(), userID).Return(true, nil)
+				mockRepo.EXPECT().GetProductTypes(gomock.Any()).Return(testProductTypes, nil)
+			},
+			expected:      models.Reception{},
+			expectedError: ErrInvalidManifest,
+		},
+		{
+			name:     "retired manifest type",
+			pvzID:    testPVZID,
+			manifest: []models.ManifestItem{{Type: "посуда", Count: 1}},
+			mockSetup: func() {
+				mockRepo.EXPECT().IsEmployeeAssigned(gomock.Any(), gomock.Any(), userID).Return(true, nil)
+				mockRepo.EXPECT().GetProductTypes(gomock.Any()).Return(testProductTypes, nil)
+			},
+			expected:      models.Reception{},
+			expectedError: ErrInvalidType,
+		},
+		{
+			name:     "reception with barcode manifest",
+			pvzID:    testPVZID,
+			barcodes: testBarcodes,
+			mockSetup: func() {
+				mockRepo.EXPECT().IsEmployeeAssigned(gomock.Any(), gomock.Any(), userID).Return(true, nil)
+				mockRepo.EXPECT().GetProductTypes(gomock.Any()).Return(testProductTypes, nil).Times(2)
+				mockRepo.EXPECT().
+					CreateReception(gomock.Any(), gomock.Any(), testPVZID, userID, gomock.Nil(), testBarcodes).
+					Return(testReception, nil)
+			},
+			expected:      *testReception,
+			expectedError: nil,
+		},
+		{
+			name:  "duplicate manifest barcode",
+			pvzID: testPVZID,
+			barcodes: []models.ManifestBarcode{
+				{Barcode: "4600000000017", Type: "обувь"},
+				{Barcode: "4600000000017", Type: "одежда"},
+			},
+			mockSetup: func() {
+				mockRepo.EXPECT().IsEmployeeAssigned(gomock.Any(), gomock.Any(), userID).Return(true, nil)
+				mockRepo.EXPECT().GetProductTypes(gomock.Any()).Return(testProductTypes, nil)
+			},
+			expected:      models.Reception{},
+			expectedError: ErrInvalidManifest,
+		},
+		{
+			name:     "retired barcode manifest type",
+			pvzID:    testPVZID,
+			barcodes: []models.ManifestBarcode{{Barcode: "4600000000017", Type: "посуда"}},
+			mockSetup: func() {
+				mockRepo.EXPECT().IsEmployeeAssigned(gomock.Any(), gomock.Any(), userID).Return(true, nil)
+				mockRepo.EXPECT().GetProductTypes(gomock.Any()).Return(testProductTypes, nil)
+			},
+			expected:      models.Reception{},
+			expectedError: ErrInvalidType,
+		},
+		{
+			name:  "employee not assigned",
+			pvzID: uuid.New(),
+			mockSetup: func() {
+				mockRepo.EXPECT().IsEmployeeAssigned(gomock.Any(), gomock.Any(), userID).Return(false, nil)
+			},
+			expected:      models.Reception{},
+			expectedError: ErrPVZAccessDenied,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockSetup()
+
+			result, err := pvzUC.CreateReception(context.Background(), userID, tt.pvzID, tt.manifest, tt.barcodes)
+
+			if tt.expectedError != nil {
+				assert.ErrorIs(t, err, tt.expectedError)
+				assert.Equal(t, tt.expected, result)
+			} else {
+				assert.NoError(t, err)
+				assert.NotEqual(t, uuid.Nil, result.ID)
+				assert.Equal(t, tt.pvzID, result.PvzID)
+				assert.Equal(t, "in_progress", result.Status)
+			}
+		})
+	}
+}
+
+func TestPVZUC_AddProduct(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	cfg := &config.Config{}
+
+	mockRepo := mock_pvz.NewMockRepository(ctrl)
+	pvzUC := NewPVZUseCase(cfg, mockRepo)
+
+	userID := uuid.New()
+	barcode := "4600000000017"
+
+	testID := uuid.MustParse("a1b2c3d4-e5f6-7890-1234-567890abcdef")
+	testTime := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	testProduct := &models.Product{
+		ID:          uuid.New(),
+		Type:        "обувь",
+		DateTime:    testTime,
+		ReceptionID: testID,
+	}
+
+	tests := []struct {
+		name          string
+		pvzID         uuid.UUID
+		productType   string
+		mockSetup     func()
+		expected      models.Product
+		expectedError error
+	}{
+		{
+			name:        "successful product addition",
+			pvzID:       uuid.New(),
+			productType: "обувь",
+			mockSetup: func() {
+				mockRepo.EXPECT().IsEmployeeAssigned(gomock.Any(), gomock.Any(), userID).Return(true, nil)
+				mockRepo.EXPECT().GetProductTypes(gomock.Any()).Return(testProductTypes, nil)
+				mockRepo.EXPECT().
+					AddProduct(gomock.Any(), gomock.Any(), gomock.Any(), userID, "обувь", &barcode, nil).
+					Return(testProduct, nil)
+			},
+			expected:      *testProduct,
+			expectedError: nil,
+		},
+		{
+			name:        "no open reception error",
+			pvzID:       uuid.New(),
+			productType: "обувь",
+			mockSetup: func() {
+				mockRepo.EXPECT().IsEmployeeAssigned(gomock.Any(), gomock.Any(), userID).Return(true, nil)
+				mockRepo.EXPECT().GetProductTypes(gomock.Any()).Return(testProductTypes, nil)
+				mockRepo.EXPECT().
+					AddProduct(gomock.Any(), gomock.Any(), gomock.Any(), userID, "обувь", &barcode, nil).
+					Return(nil, db.ErrNoOpenReception)
+			},
+			expected:      models.Product{},
+			expectedError: db.ErrNoOpenReception,
+		},
+		{
+			name:        "pvz not active error",
+			pvzID:       uuid.New(),
+			productType: "обувь",
+			mockSetup: func() {
+				mockRepo.EXPECT().IsEmployeeAssigned(gomock.Any(), gomock.Any(), userID).Return(true, nil)
+				mockRepo.EXPECT().GetProductTypes(gomock.Any()).Return(testProductTypes, nil)
+				mockRepo.EXPECT().
+					AddProduct(gomock.Any(), gomock.Any(), gomock.Any(), userID, "обувь", &barcode, nil).
+					Return(nil, db.ErrPVZNotActive)
+			},
+			expected:      models.Product{},
+			expectedError: db.ErrPVZNotActive,
+		},
+		{
+			name:        "duplicate barcode error",
+			pvzID:       uuid.New(),
+			productType: "обувь",
+			mockSetup: func() {
+				mockRepo.EXPECT().IsEmployeeAssigned(gomock.Any(), gomock.Any(), userID).Return(true, nil)
+				mockRepo.EXPECT().GetProductTypes(gomock.Any()).Return(testProductTypes, nil)
+				mockRepo.EXPECT().
+					AddProduct(gomock.Any(), gomock.Any(), gomock.Any(), userID, "обувь", &barcode, nil).
+					Return(nil, db.ErrDuplicateBarcode)
+			},
+			expected:      models.Product{},
+			expectedError: db.ErrDuplicateBarcode,
+		},
+		{
+			name:        "cell is full error",
+			pvzID:       uuid.New(),
+			productType: "обувь",
+			mockSetup: func() {
+				mockRepo.EXPECT().IsEmployeeAssigned(gomock.Any(), gomock.Any(), userID).Return(true, nil)
+				mockRepo.EXPECT().GetProductTypes(gomock.Any()).Return(testProductTypes, nil)
+				mockRepo.EXPECT().
+					AddProduct(gomock.Any(), gomock.Any(), gomock.Any(), userID, "обувь", &barcode, nil).
+					Return(nil, db.ErrCellFull)
+			},
+			expected:      models.Product{},
+			expectedError: db.ErrCellFull,
+		},
+		{
+			name:        "pvz is full error",
+			pvzID:       uuid.New(),
+			productType: "обувь",
+			mockSetup: func() {
+				mockRepo.EXPECT().IsEmployeeAssigned(gomock.Any(), gomock.Any(), userID).Return(true, nil)
+				mockRepo.EXPECT().GetProductTypes(gomock.Any()).Return(testProductTypes, nil)
+				mockRepo.EXPECT().
+					AddProduct(gomock.Any(), gomock.Any(), gomock.Any(), userID, "обувь", &barcode, nil).
+					Return(nil, db.ErrCapacityExceeded)
+			},
+			expected:      models.Product{},
+			expectedError: db.ErrCapacityExceeded,
+		},
+		{
+			name:        "reception limit error",
+			pvzID:       uuid.New(),
+			productType: "обувь",
+			mockSetup: func() {
+				mockRepo.EXPECT().IsEmployeeAssigned(gomock.Any(), gomock.Any(), userID).Return(true, nil)
+				mockRepo.EXPECT().GetProductTypes(gomock.Any()).Return(testProductTypes, nil)
+				mockRepo.EXPECT().
+					AddProduct(gomock.Any(), gomock.Any(), gomock.Any(), userID, "обувь", &barcode, nil).
+					Return(nil, db.ErrReceptionLimit)
+			},
+			expected:      models.Product{},
+			expectedError: db.ErrReceptionLimit,
+		},
+		{
+			name:        "repository error",
+			pvzID:       uuid.New(),
+			productType: "обувь",
+			mockSetup: func() {
+				mockRepo.EXPECT().IsEmployeeAssigned(gomock.Any(), gomock.Any(), userID).Return(true, nil)
+				mockRepo.EXPECT().GetProductTypes(gomock.Any()).Return(testProductTypes, nil)
+				mockRepo.EXPECT().
+					AddProduct(gomock.Any(), gomock.Any(), gomock.Any(), userID, "обувь", &barcode, nil).
+					Return(nil, ErrRandomError)
+			},
+			expected:      models.Product{},
+			expectedError: ErrRandomError,
+		},
+		{
+			name:        "unknown type",
+			pvzID:       uuid.New(),
+			productType: "косметика",
+			mockSetup: func() {
+				mockRepo.EXPECT().IsEmployeeAssigned(gomock.Any(), gomock.Any(), userID).Return(true, nil)
+				mockRepo.EXPECT().GetProductTypes(gomock.Any()).Return(testProductTypes, nil)
+			},
+			expected:      models.Product{},
+			expectedError: ErrInvalidType,
+		},
+		{
+			name:        "retired type",
+			pvzID:       uuid.New(),
+			productType: "посуда",
+			mockSetup: func() {
+				mockRepo.EXPECT().IsEmployeeAssigned(gomock.Any(), gomock.Any(), userID).Return(true, nil)
+				mockRepo.EXPECT().GetProductTypes(gomock.Any()).Return(testProductTypes, nil)
+			},
+			expected:      models.Product{},
+			expectedError: ErrInvalidType,
+		},
+		{
+			name:        "type retired concurrently",
+			pvzID:       uuid.New(),
+			productType: "обувь",
+			mockSetup: func() {
+				mockRepo.EXPECT().IsEmployeeAssigned(gomock.Any(), gomock.Any(), userID).Return(true, nil)
+				mockRepo.EXPECT().GetProductTypes(gomock.Any()).Return(testProductTypes, nil)
+				mockRepo.EXPECT().
+					AddProduct(gomock.Any(), gomock.Any(), gomock.Any(), userID, "обувь", &barcode, nil).
+					Return(nil, db.ErrTypeNotFound)
+			},
+			expected:      models.Product{},
+			expectedError: ErrInvalidType,
+		},
+		{
+			name:        "catalog error",
+			pvzID:       uuid.New(),
+			productType: "обувь",
+			mockSetup: func() {
+				mockRepo.EXPECT().IsEmployeeAssigned(gomock.Any(), gomock.Any(), userID).Return(true, nil)
+				mockRepo.EXPECT().GetProductTypes(gomock.Any()).Return(nil, ErrRandomError)
+			},
+			expected:      models.Product{},
+			expectedError: ErrRandomError,
+		},
+		{
+			name:        "employee not assigned",
+			pvzID:       uuid.New(),
+			productType: "обувь",
+			mockSetup: func() {
+				mockRepo.EXPECT().IsEmployeeAssigned(gomock.Any(), gomock.Any(), userID).Return(false, nil)
+			},
+			expected:      models.Product{},
+			expectedError: ErrPVZAccessDenied,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockSetup()
+
+			result, err := pvzUC.AddProduct(context.Background(), userID, tt.pvzID, tt.productType, &barcode, nil)
+
+			if tt.expectedError != nil {
+				assert.ErrorIs(t, err, tt.expectedError)
+				assert.Equal(t, tt.expected, result)
+			} else {
+				assert.NoError(t, err)
+				assert.NotEqual(t, uuid.Nil, result.ID)
+				assert.Equal(t, tt.productType, result.Type)
+				assert.NotEqual(t, uuid.Nil, result.ReceptionID)
+			}
+		})
+	}
+}
+
+func TestPVZUC_AddProducts(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	cfg := &config.Config{}
+
+	mockRepo := mock_pvz.NewMockRepository(ctrl)
+	pvzUC := NewPVZUseCase(cfg, mockRepo)
+
+	userID := uuid.New()
+	pvzID := uuid.New()
+	barcode := "4600000000017"
+
+	products := []models.Product{
+		{Type: "обувь", Barcode: &barcode},
+		{Type: "косметика"},
+		{Type: "одежда"},
+	}
+
+	added := &models.Product{ID: uuid.New(), Type: "обувь", Barcode: &barcode}
+
+	tests := []struct {
+		name          string
+		mockSetup     func()
+		expected      []models.ProductBatchResult
+		expectedError error
+	}{
+		{
+			name: "unknown types are reported per item",
+			mockSetup: func() {
+				mockRepo.EXPECT().IsEmployeeAssigned(gomock.Any(), pvzID, userID).Return(true, nil)
+				mockRepo.EXPECT().GetProductTypes(gomock.Any()).Return(testProductTypes, nil)
+				mockRepo.EXPECT().AddProducts(gomock.Any(), pvzID, userID, gomock.Any()).
+					DoAndReturn(func(_ context.Context, _, _ uuid.UUID, valid []models.Product) ([]models.ProductBatchResult, error) {
+						if !assert.Len(t, valid, 2) {
+							return nil, ErrRandomError
+						}
+						assert.Equal(t, "обувь", valid[0].Type)
+						assert.Equal(t, "одежда", valid[1].Type)
+						assert.NotEqual(t, uuid.Nil, valid[0].ID)
+						return []models.ProductBatchResult{{Product: added}, {Err: db.ErrDuplicateBarcode}}, nil
+					})
+			},
+			expected: []models.ProductBatchResult{
+				{Product: added},
+				{Err: ErrInvalidType},
+				{Err: db.ErrDuplicateBarcode},
+			},
+			expectedError: nil,
+		},
+		{
+			name: "employee not assigned",
+			mockSetup: func() {
+				mockRepo.EXPECT().IsEmployeeAssigned(gomock.Any(), pvzID, userID).Return(false, nil)
+			},
+			expected:      nil,
+			expectedError: ErrPVZAccessDenied,
+		},
+		{
+			name: "no open reception",
+			mockSetup: func() {
+				mockRepo.EXPECT().IsEmployeeAssigned(gomock.Any(), pvzID, userID).Return(true, nil)
+				mockRepo.EXPECT().GetProductTypes(gomock.Any()).Return(testProductTypes, nil)
+				mockRepo.EXPECT().AddProducts(gomock.Any(), pvzID, userID, gomock.Any()).Return(nil, db.ErrNoOpenReception)
+			},
+			expected:      nil,
+			expectedError: db.ErrNoOpenReception,
+		},
+		{
+			name: "repository error",
+			mockSetup: func() {
+				mockRepo.EXPECT().IsEmployeeAssigned(gomock.Any(), pvzID, userID).Return(true, nil)
+				mockRepo.EXPECT().GetProductTypes(gomock.Any()).Return(testProductTypes, nil)
+				mockRepo.EXPECT().AddProducts(gomock.Any(), pvzID, userID, gomock.Any()).Return(nil, ErrRandomError)
+			},
+			expected:      nil,
+			expectedError: ErrRandomError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockSetup()
+
+			result, err := pvzUC.AddProducts(context.Background(), userID, pvzID, products)
+
+			if tt.expectedError != nil {
+				assert.ErrorIs(t, err, tt.expectedError)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, tt.expected, result)
+		})
+	}
+}
+
+func TestPVZUC_DeleteLastProduct(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	cfg := &config.Config{}
+
+	mockRepo := mock_pvz.NewMockRepository(ctrl)
+	pvzUC := NewPVZUseCase(cfg, mockRepo)
+
+	userID := uuid.New()
+
+	tests := []struct {
+		name          string
+		pvzID         uuid.UUID
+		mockSetup     func()
+		expectedError error
+	}{
+		{
+			name:  "successful product deletion",
+			pvzID: uuid.New(),
+			mockSetup: func() {
+				mockRepo.EXPECT().IsEmployeeAssigned(gomock.Any(), gomock.Any(), userID).Return(true, nil)
+				mockRepo.EXPECT().
+					DeleteLastProduct(gomock.Any(), gomock.Any()).
+					Return(nil)
+			},
+			expectedError: nil,
+		},
+		{
+			name:  "no open reception error",
+			pvzID: uuid.New(),
+			mockSetup: func() {
+				mockRepo.EXPECT().IsEmployeeAssigned(gomock.Any(), gomock.Any(), userID).Return(true, nil)
+				mockRepo.EXPECT().
+					DeleteLastProduct(gomock.Any(), gomock.Any()).
+					Return(db.ErrNoOpenReception)
+			},
+			expectedError: db.ErrNoOpenReception,
+		},
+		{
+			name:  "no products error",
+			pvzID: uuid.New(),
+			mockSetup: func() {
+				mockRepo.EXPECT().IsEmployeeAssigned(gomock.Any(), gomock.Any(), userID).Return(true, nil)
+				mockRepo.EXPECT().
+					DeleteLastProduct(gomock.Any(), gomock.Any()).
+					Return(db.ErrNoProducts)
+			},
+			expectedError: db.ErrNoProducts,
+		},
+		{
+			name:  "repository error",
+			pvzID: uuid.New(),
+			mockSetup: func() {
+				mockRepo.EXPECT().IsEmployeeAssigned(gomock.Any(), gomock.Any(), userID).Return(true, nil)
+				mockRepo.EXPECT().
+					DeleteLastProduct(gomock.Any(), gomock.Any()).
+					Return(ErrRandomError)
+			},
+			expectedError: ErrRandomError,
+		},
+		{
+			name:  "employee not assigned",
+			pvzID: uuid.New(),
+			mockSetup: func() {
+				mockRepo.EXPECT().IsEmployeeAssigned(gomock.Any(), gomock.Any(), userID).Return(false, nil)
+			},
+			expectedError: ErrPVZAccessDenied,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockSetup()
+
+			err := pvzUC.DeleteLastProduct(context.Background(), userID, tt.pvzID)
+
+			if tt.expectedError != nil {
+				assert.ErrorIs(t, err, tt.expectedError)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestPVZUC_DeleteProduct(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	cfg := &config.Config{}
+
+	mockRepo := mock_pvz.NewMockRepository(ctrl)
+	pvzUC := NewPVZUseCase(cfg, mockRepo)
+
+	userID := uuid.New()
+	pvzID := uuid.New()
+	receptionID := uuid.New()
+	productID := uuid.New()
+
+	tests := []struct {
+		name          string
+		mockSetup     func()
+		expectedError error
+	}{
+		{
+			name: "successful product deletion",
+			mockSetup: func() {
+				mockRepo.EXPECT().GetReceptionPVZID(gomock.Any(), receptionID).Return(pvzID, nil)
+				mockRepo.EXPECT().IsEmployeeAssigned(gomock.Any(), pvzID, userID).Return(true, nil)
+				mockRepo.EXPECT().DeleteProduct(gomock.Any(), receptionID, productID).Return(nil)
+			},
+			expectedError: nil,
+		},
+		{
+			name: "reception not found",
+			mockSetup: func() {
+				mockRepo.EXPECT().GetReceptionPVZID(gomock.Any(), receptionID).Return(uuid.Nil, db.ErrReceptionNotFound)
+			},
+			expectedError: db.ErrReceptionNotFound,
+		},
+		{
+			name: "employee not assigned",
+			mockSetup: func() {
+				mockRepo.EXPECT().GetReceptionPVZID(gomock.Any(), receptionID).Return(pvzID, nil)
+				mockRepo.EXPECT().IsEmployeeAssigned(gomock.Any(), pvzID, userID).Return(false, nil)
+			},
+			expectedError: ErrPVZAccessDenied,
+		},
+		{
+			name: "reception is not open",
+			mockSetup: func() {
+				mockRepo.EXPECT().GetReceptionPVZID(gomock.Any(), receptionID).Return(pvzID, nil)
+				mockRepo.EXPECT().IsEmployeeAssigned(gomock.Any(), pvzID, userID).Return(true, nil)
+				mockRepo.EXPECT().DeleteProduct(gomock.Any(), receptionID, productID).Return(db.ErrReceptionNotOpen)
+			},
+			expectedError: db.ErrReceptionNotOpen,
+		},
+		{
+			name: "product not found",
+			mockSetup: func() {
+				mockRepo.EXPECT().GetReceptionPVZID(gomock.Any(), receptionID).Return(pvzID, nil)
+				mockRepo.EXPECT().IsEmployeeAssigned(gomock.Any(), pvzID, userID).Return(true, nil)
+				mockRepo.EXPECT().DeleteProduct(gomock.Any(), receptionID, productID).Return(db.ErrProductNotFound)
+			},
+			expectedError: db.ErrProductNotFound,
+		},
+		{
+			name: "repository error",
+			mockSetup: func() {
+				mockRepo.EXPECT().GetReceptionPVZID(gomock.Any(), receptionID).Return(pvzID, nil)
+				mockRepo.EXPECT().IsEmployeeAssigned(gomock.Any(), pvzID, userID).Return(true, nil)
+				mockRepo.EXPECT().DeleteProduct(gomock.Any(), receptionID, productID).Return(ErrRandomError)
+			},
+			expectedError: ErrRandomError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockSetup()
+
+			err := pvzUC.DeleteProduct(context.Background(), userID, receptionID, productID)
+
+			if tt.expectedError != nil {
+				assert.ErrorIs(t, err, tt.expectedError)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestPVZUC_IssueProduct(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	cfg := &config.Config{}
+
+	mockRepo := mock_pvz.NewMockRepository(ctrl)
+	pvzUC := NewPVZUseCase(cfg, mockRepo)
+
+	userID := uuid.New()
+	pvzID := uuid.New()
+	productID := uuid.New()
+
+	issued := string(pvzapi.Issued)
+	allowed := []string{string(pvzapi.Stored)}
+	testProduct := &models.Product{ID: productID, Type: "обувь", Status: issued}
+
+	tests := []struct {
+		name          string
+		mockSetup     func()
+		expected      models.Product
+		expectedError error
+	}{
+		{
+			name: "successful issue",
+			mockSetup: func() {
+				mockRepo.EXPECT().GetProductPVZID(gomock.Any(), productID).Return(pvzID, nil)
+				mockRepo.EXPECT().IsEmployeeAssigned(gomock.Any(), pvzID, userID).Return(true, nil)
+				mockRepo.EXPECT().UpdateProductStatus(gomock.Any(), productID, allowed, issued).Return(testProduct, nil)
+			},
+			expected:      *testProduct,
+			expectedError: nil,
+		},
+		{
+			name: "product not found",
+			mockSetup: func() {
+				mockRepo.EXPECT().GetProductPVZID(gomock.Any(), productID).Return(uuid.Nil, db.ErrProductNotFound)
+			},
+			expectedError: db.ErrProductNotFound,
+		},
+		{
+			name: "employee not assigned",
+			mockSetup: func() {
+				mockRepo.EXPECT().GetProductPVZID(gomock.Any(), productID).Return(pvzID, nil)
+				mockRepo.EXPECT().IsEmployeeAssigned(gomock.Any(), pvzID, userID).Return(false, nil)
+			},
+			expectedError: ErrPVZAccessDenied,
+		},
+		{
+			name: "product is not stored",
+			mockSetup: func() {
+				mockRepo.EXPECT().GetProductPVZID(gomock.Any(), productID).Return(pvzID, nil)
+				mockRepo.EXPECT().IsEmployeeAssigned(gomock.Any(), pvzID, userID).Return(true, nil)
+				mockRepo.EXPECT().UpdateProductStatus(gomock.Any(), productID, allowed, issued).Return(nil, db.ErrInvalidTransition)
+			},
+			expectedError: db.ErrInvalidTransition,
+		},
+		{
+			name: "repository error",
+			mockSetup: func() {
+				mockRepo.EXPECT().GetProductPVZID(gomock.Any(), productID).Return(pvzID, nil)
+				mockRepo.EXPECT().IsEmployeeAssigned(gomock.Any(), pvzID, userID).Return(true, nil)
+				mockRepo.EXPECT().UpdateProductStatus(gomock.Any(), productID, allowed, issued).Return(nil, ErrRandomError)
+			},
+			expectedError: ErrRandomError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockSetup()
+
+			result, err := pvzUC.IssueProduct(context.Background(), userID, productID)
+
+			if tt.expectedError != nil {
+				assert.ErrorIs(t, err, tt.expectedError)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, tt.expected, result)
+		})
+	}
+}
+
+func TestPVZUC_ReturnProduct(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	cfg := &config.Config{}
+
+	mockRepo := mock_pvz.NewMockRepository(ctrl)
+	pvzUC := NewPVZUseCase(cfg, mockRepo)
+
+	userID := uuid.New()
+	pvzID := uuid.New()
+	productID := uuid.New()
+
+	returned := string(pvzapi.Returned)
+	allowed := []string{string(pvzapi.Stored), string(pvzapi.Issued)}
+	testProduct := &models.Product{ID: productID, Type: "обувь", Status: returned}
+
+	tests := []struct {
+		name          string
+		mockSetup     func()
+		expected      models.Product
+		expectedError error
+	}{
+		{
+			name: "successful return",
+			mockSetup: func() {
+				mockRepo.EXPECT().GetProductPVZID(gomock.Any(), productID).Return(pvzID, nil)
+				mockRepo.EXPECT().IsEmployeeAssigned(gomock.Any(), pvzID, userID).Return(true, nil)
+				mockRepo.EXPECT().UpdateProductStatus(gomock.Any(), productID, allowed, returned).Return(testProduct, nil)
+			},
+			expected:      *testProduct,
+			expectedError: nil,
+		},
+		{
+			name: "product already returned",
+			mockSetup: func() {
+				mockRepo.EXPECT().GetProductPVZID(gomock.Any(), productID).Return(pvzID, nil)
+				mockRepo.EXPECT().IsEmployeeAssigned(gomock.Any(), pvzID, userID).Return(true, nil)
+				mockRepo.EXPECT().UpdateProductStatus(gomock.Any(), productID, allowed, returned).Return(nil, db.ErrInvalidTransition)
+			},
+			expectedError: db.ErrInvalidTransition,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockSetup()
+
+			result, err := pvzUC.ReturnProduct(context.Background(), userID, productID)
+
+			if tt.expectedError != nil {
+				assert.ErrorIs(t, err, tt.expectedError)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, tt.expected, result)
+		})
+	}
+}
+
+func TestPVZUC_GeneratePickupCode(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	cfg := &config.Config{}
+
+	mockRepo := mock_pvz.NewMockRepository(ctrl)
+	pvzUC := NewPVZUseCase(cfg, mockRepo)
+
+	userID := uuid.New()
+	pvzID := uuid.New()
+	productID := uuid.New()
+
+	var storedHash string
+
+	tests := []struct {
+		name          string
+		mockSetup     func()
+		expectedError error
+	}{
+		{
+			name: "successful generation",
+			mockSetup: func() {
+				mockRepo.EXPECT().GetProductPVZID(gomock.Any(), productID).Return(pvzID, nil)
+				mockRepo.EXPECT().IsEmployeeAssigned(gomock.Any(), pvzID, userID).Return(true, nil)
+				mockRepo.EXPECT().SetPickupCode(gomock.Any(), productID, gomock.Any()).
+					DoAndReturn(func(_ context.Context, _ uuid.UUID, codeHash string) error {
+						storedHash = codeHash
+						return nil
+					})
+			},
+			expectedError: nil,
+		},
+		{
+			name: "product not found",
+			mockSetup: func() {
+				mockRepo.EXPECT().GetProductPVZID(gomock.Any(), productID).Return(uuid.Nil, db.ErrProductNotFound)
+			},
+			expectedError: db.ErrProductNotFound,
+		},
+		{
+			name: "employee not assigned",
+			mockSetup: func() {
+				mockRepo.EXPECT().GetProductPVZID(gomock.Any(), productID).Return(pvzID, nil)
+				mockRepo.EXPECT().IsEmployeeAssigned(gomock.Any(), pvzID, userID).Return(false, nil)
+			},
+			expectedError: ErrPVZAccessDenied,
+		},
+		{
+			name: "product is not stored",
+			mockSetup: func() {
+				mockRepo.EXPECT().GetProductPVZID(gomock.Any(), productID).Return(pvzID, nil)
+				mockRepo.EXPECT().IsEmployeeAssigned(gomock.Any(), pvzID, userID).Return(true, nil)
+				mockRepo.EXPECT().SetPickupCode(gomock.Any(), productID, gomock.Any()).Return(db.ErrInvalidTransition)
+			},
+			expectedError: db.ErrInvalidTransition,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			storedHash = ""
+			tt.mockSetup()
+
+			code, err := pvzUC.GeneratePickupCode(context.Background(), userID, productID)
+
+			if tt.expectedError != nil {
+				assert.ErrorIs(t, err, tt.expectedError)
+				assert.Empty(t, code)
+			} else {
+				assert.NoError(t, err)
+				assert.Len(t, code, 6)
+
+				match, err := argon2id.ComparePasswordAndHash(code, storedHash)
+				assert.NoError(t, err)
+				assert.True(t, match)
+			}
+		})
+	}
+}
+
+func TestPVZUC_PickupProduct(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	cfg := &config.Config{
+		App: config.App{
+			PickupMaxAttempts: 3,
+			PickupLockout:     15 * time.Minute,
+		},
+	}
+
+	mockRepo := mock_pvz.NewMockRepository(ctrl)
+	pvzUC := NewPVZUseCase(cfg, mockRepo).(*pvzUC)
+
+	now := time.Date(2025, 4, 10, 12, 0, 0, 0, time.UTC)
+	pvzUC.now = func() time.Time { return now }
+
+	userID := uuid.New()
+	pvzID := uuid.New()
+	productID := uuid.New()
+
+	code := "042137"
+	codeHash, err := argon2id.CreateHash(code, argon2id.DefaultParams)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	stored := string(pvzapi.Stored)
+	lockedUntil := now.Add(cfg.App.PickupLockout)
+	issuedProduct := &models.Product{ID: productID, Type: "обувь", Status: string(pvzapi.Issued)}
+	claimedProduct := &models.Product{ID: productID, Status: stored, PickupCodeHash: &codeHash, PickupAttempts: 1}
+
+	expectAccess := func() {
+		mockRepo.EXPECT().GetProductPVZID(gomock.Any(), productID).Return(pvzID, nil)
+		mockRepo.EXPECT().IsEmployeeAssigned(gomock.Any(), pvzID, userID).Return(true, nil)
+	}
+
+	tests := []struct {
+		name          string
+		code          string
+		mockSetup     func()
+		expected      models.Product
+		expectedError error
+	}{
+		{
+			name: "successful pickup",
+			code: code,
+			mockSetup: func() {
+				expectAccess()
+				mockRepo.EXPECT().ClaimPickupAttempt(gomock.Any(), productID, 3, now).Return(claimedProduct, nil)
+				mockRepo.EXPECT().IssueWithPickupCode(gomock.Any(), productID, codeHash, now).Return(issuedProduct, nil)
+			},
+			expected:      *issuedProduct,
+			expectedError: nil,
+		},
+		{
+			name: "wrong code",
+			code: "000000",
+			mockSetup: func() {
+				expectAccess()
+				mockRepo.EXPECT().ClaimPickupAttempt(gomock.Any(), productID, 3, now).Return(claimedProduct, nil)
+				mockRepo.EXPECT().FailPickupAttempt(gomock.Any(), productID, 3, lockedUntil).Return(nil, nil)
+			},
+			expectedError: ErrInvalidPickupCode,
+		},
+		{
+			name: "wrong code reaches the limit",
+			code: "000000",
+			mockSetup: func() {
+				expectAccess()
+				mockRepo.EXPECT().ClaimPickupAttempt(gomock.Any(), productID, 3, now).
+					Return(&models.Product{ID: productID, Status: stored, PickupCodeHash: &codeHash, PickupAttempts: 3}, nil)
+				mockRepo.EXPECT().FailPickupAttempt(gomock.Any(), productID, 3, lockedUntil).Return(&lockedUntil, nil)
+			},
+			expectedError: ErrPickupLocked,
+		},
+		{
+			name: "no attempts left",
+			code: code,
+			mockSetup: func() {
+				expectAccess()
+				mockRepo.EXPECT().ClaimPickupAttempt(gomock.Any(), productID, 3, now).Return(nil, db.ErrNoPickupAttemptsLeft)
+			},
+			expectedError: ErrPickupLocked,
+		},
+		{
+			name: "locked while the code was checked",
+			code: code,
+			mockSetup: func() {
+				expectAccess()
+				mockRepo.EXPECT().ClaimPickupAttempt(gomock.Any(), productID, 3, now).Return(claimedProduct, nil)
+				mockRepo.EXPECT().IssueWithPickupCode(gomock.Any(), productID, codeHash, now).Return(nil, db.ErrNoPickupAttemptsLeft)
+			},
+			expectedError: ErrPickupLocked,
+		},
+		{
+			name: "no pickup code",
+			code: code,
+			mockSetup: func() {
+				expectAccess()
+				mockRepo.EXPECT().ClaimPickupAttempt(gomock.Any(), productID, 3, now).Return(nil, db.ErrNoPickupCode)
+			},
+			expectedError: db.ErrNoPickupCode,
+		},
+		{
+			name: "product is not stored",
+			code: code,
+			mockSetup: func() {
+				expectAccess()
+				mockRepo.EXPECT().ClaimPickupAttempt(gomock.Any(), productID, 3, now).Return(nil, db.ErrInvalidTransition)
+			},
+			expectedError: db.ErrInvalidTransition,
+		},
+		{
+			name: "employee not assigned",
+			code: code,
+			mockSetup: func() {
+				mockRepo.EXPECT().GetProductPVZID(gomock.Any(), productID).Return(pvzID, nil)
+				mockRepo.EXPECT().IsEmployeeAssigned(gomock.Any(), pvzID, userID).Return(false, nil)
+			},
+			expectedError: ErrPVZAccessDenied,
+		},
+		{
+			name: "code changed concurrently",
+			code: code,
+			mockSetup: func() {
+				expectAccess()
+				mockRepo.EXPECT().ClaimPickupAttempt(gomock.Any(), productID, 3, now).Return(claimedProduct, nil)
+				mockRepo.EXPECT().IssueWithPickupCode(gomock.Any(), productID, codeHash, now).Return(nil, db.ErrInvalidTransition)
+			},
+			expectedError: db.ErrInvalidTransition,
+		},
+		{
+			name: "claim error",
+			code: code,
+			mockSetup: func() {
+				expectAccess()
+				mockRepo.EXPECT().ClaimPickupAttempt(gomock.Any(), productID, 3, now).Return(nil, ErrRandomError)
+			},
+			expectedError: ErrRandomError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockSetup()
+
+			result, err := pvzUC.PickupProduct(context.Background(), userID, productID, tt.code)
+
+			if tt.expectedError != nil {
+				assert.ErrorIs(t, err, tt.expectedError)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, tt.expected, result)
+		})
+	}
+}
+
+func TestPVZUC_MoveProduct(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	cfg := &config.Config{}
+
+	mockRepo := mock_pvz.NewMockRepository(ctrl)
+	pvzUC := NewPVZUseCase(cfg, mockRepo)
+
+	userID := uuid.New()
+	pvzID := uuid.New()
+	productID := uuid.New()
+	cellNumber := 3
+
+	testProduct := &models.Product{ID: productID, Type: "обувь", Status: string(pvzapi.Stored), CellNumber: &cellNumber}
+
+	tests := []struct {
+		name          string
+		mockSetup     func()
+		expected      models.Product
+		expectedError error
+	}{
+		{
+			name: "successful move",
+			mockSetup: func() {
+				mockRepo.EXPECT().GetProductPVZID(gomock.Any(), productID).Return(pvzID, nil)
+				mockRepo.EXPECT().IsEmployeeAssigned(gomock.Any(), pvzID, userID).Return(true, nil)
+				mockRepo.EXPECT().MoveProduct(gomock.Any(), productID, cellNumber).Return(testProduct, nil)
+			},
+			expected:      *testProduct,
+			expectedError: nil,
+		},
+		{
+			name: "product not found",
+			mockSetup: func() {
+				mockRepo.EXPECT().GetProductPVZID(gomock.Any(), productID).Return(uuid.Nil, db.ErrProductNotFound)
+			},
+			expectedError: db.ErrProductNotFound,
+		},
+		{
+			name: "employee not assigned",
+			mockSetup: func() {
+				mockRepo.EXPECT().GetProductPVZID(gomock.Any(), productID).Return(pvzID, nil)
+				mockRepo.EXPECT().IsEmployeeAssigned(gomock.Any(), pvzID, userID).Return(false, nil)
+			},
+			expectedError: ErrPVZAccessDenied,
+		},
+		{
+			name: "product already left the pvz",
+			mockSetup: func() {
+				mockRepo.EXPECT().GetProductPVZID(gomock.Any(), productID).Return(pvzID, nil)
+				mockRepo.EXPECT().IsEmployeeAssigned(gomock.Any(), pvzID, userID).Return(true, nil)
+				mockRepo.EXPECT().MoveProduct(gomock.Any(), productID, cellNumber).Return(nil, db.ErrInvalidTransition)
+			},
+			expectedError: db.ErrInvalidTransition,
+		},
+		{
+			name: "cell not found",
+			mockSetup: func() {
+				mockRepo.EXPECT().GetProductPVZID(gomock.Any(), productID).Return(pvzID, nil)
+				mockRepo.EXPECT().IsEmployeeAssigned(gomock.Any(), pvzID, userID).Return(true, nil)
+				mockRepo.EXPECT().MoveProduct(gomock.Any(), productID, cellNumber).Return(nil, db.ErrCellNotFound)
+			},
+			expectedError: db.ErrCellNotFound,
+		},
+		{
+			name: "cell is full",
+			mockSetup: func() {
+				mockRepo.EXPECT().GetProductPVZID(gomock.Any(), productID).Return(pvzID, nil)
+				mockRepo.EXPECT().IsEmployeeAssigned(gomock.Any(), pvzID, userID).Return(true, nil)
+				mockRepo.EXPECT().MoveProduct(gomock.Any(), productID, cellNumber).Return(nil, db.ErrCellFull)
+			},
+			expectedError: db.ErrCellFull,
+		},
+		{
+			name: "repository error",
+			mockSetup: func() {
+				mockRepo.EXPECT().GetProductPVZID(gomock.Any(), productID).Return(pvzID, nil)
+				mockRepo.EXPECT().IsEmployeeAssigned(gomock.Any(), pvzID, userID).Return(true, nil)
+				mockRepo.EXPECT().MoveProduct(gomock.Any(), productID, cellNumber).Return(nil, ErrRandomError)
+			},
+			expectedError: ErrRandomError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockSetup()
+
+			result, err := pvzUC.MoveProduct(context.Background(), userID, productID, cellNumber)
+
+			if tt.expectedError != nil {
+				assert.ErrorIs(t, err, tt.expectedError)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, tt.expected, result)
+		})
+	}
+}
+
+func TestPVZUC_TransferProducts(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	cfg := &config.Config{}
+
+	mockRepo := mock_pvz.NewMockRepository(ctrl)
+	pvzUC := NewPVZUseCase(cfg, mockRepo)
+
+	userID := uuid.New()
+	pvzID := uuid.New()
+	toPvzID := uuid.New()
+	receptionID := uuid.New()
+	firstID, secondID := uuid.New(), uuid.New()
+
+	transfers := []models.ProductTransfer{
+		{ID: uuid.New(), ProductID: firstID, FromPvzID: pvzID, ToPvzID: toPvzID, Status: string(pvzapi.InTransit)},
+		{ID: uuid.New(), ProductID: secondID, FromPvzID: pvzID, ToPvzID: toPvzID, Status: string(pvzapi.InTransit)},
+	}
+
+	tests := []struct {
+		name          string
+		toPvzID       uuid.UUID
+		productIDs    []uuid.UUID
+		mockSetup     func()
+		expected      []models.ProductTransfer
+		expectedError error
+	}{
+		{
+			name:       "duplicate products are transferred once",
+			toPvzID:    toPvzID,
+			productIDs: []uuid.UUID{firstID, secondID, firstID},
+			mockSetup: func() {
+				mockRepo.EXPECT().GetReceptionPVZID(gomock.Any(), receptionID).Return(pvzID, nil)
+				mockRepo.EXPECT().IsEmployeeAssigned(gomock.Any(), pvzID, userID).Return(true, nil)
+				mockRepo.EXPECT().CreateTransfers(gomock.Any(), receptionID, toPvzID, userID, []uuid.UUID{firstID, secondID}).
+					Return(transfers, nil)
+			},
+			expected:      transfers,
+			expectedError: nil,
+		},
+		{
+			name:       "reception not found",
+			toPvzID:    toPvzID,
+			productIDs: []uuid.UUID{firstID},
+			mockSetup: func() {
+				mockRepo.EXPECT().GetReceptionPVZID(gomock.Any(), receptionID).Return(uuid.Nil, db.ErrReceptionNotFound)
+			},
+			expectedError: db.ErrReceptionNotFound,
+		},
+		{
+			name:       "employee not assigned",
+			toPvzID:    toPvzID,
+			productIDs: []uuid.UUID{firstID},
+			mockSetup: func() {
+				mockRepo.EXPECT().GetReceptionPVZID(gomock.Any(), receptionID).Return(pvzID, nil)
+				mockRepo.EXPECT().IsEmployeeAssigned(gomock.Any(), pvzID, userID).Return(false, nil)
+			},
+			expectedError: ErrPVZAccessDenied,
+		},
+		{
+			name:       "transfer to the same pvz",
+			toPvzID:    pvzID,
+			productIDs: []uuid.UUID{firstID},
+			mockSetup: func() {
+				mockRepo.EXPECT().GetReceptionPVZID(gomock.Any(), receptionID).Return(pvzID, nil)
+				mockRepo.EXPECT().IsEmployeeAssigned(gomock.Any(), pvzID, userID).Return(true, nil)
+			},
+			expectedError: ErrSameTransferPVZ,
+		},
+		{
+			name:       "product is not stored",
+			toPvzID:    toPvzID,
+			productIDs: []uuid.UUID{firstID},
+			mockSetup: func() {
+				mockRepo.EXPECT().GetReceptionPVZID(gomock.Any(), receptionID).Return(pvzID, nil)
+				mockRepo.EXPECT().IsEmployeeAssigned(gomock.Any(), pvzID, userID).Return(true, nil)
+				mockRepo.EXPECT().CreateTransfers(gomock.Any(), receptionID, toPvzID, userID, []uuid.UUID{firstID}).
+					Return(nil, db.ErrInvalidTransition)
+			},
+			expectedError: db.ErrInvalidTransition,
+		},
+		{
+			name:       "product has no barcode",
+			toPvzID:    toPvzID,
+			productIDs: []uuid.UUID{firstID},
+			mockSetup: func() {
+				mockRepo.EXPECT().GetReceptionPVZID(gomock.Any(), receptionID).Return(pvzID, nil)
+				mockRepo.EXPECT().IsEmployeeAssigned(gomock.Any(), pvzID, userID).Return(true, nil)
+				mockRepo.EXPECT().CreateTransfers(gomock.Any(), receptionID, toPvzID, userID, []uuid.UUID{firstID}).
+					Return(nil, db.ErrNoBarcode)
+			},
+			expectedError: db.ErrNoBarcode,
+		},
+		{
+			name:       "destination pvz not found",
+			toPvzID:    toPvzID,
+			productIDs: []uuid.UUID{firstID},
+			mockSetup: func() {
+				mockRepo.EXPECT().GetReceptionPVZID(gomock.Any(), receptionID).Return(pvzID, nil)
+				mockRepo.EXPECT().IsEmployeeAssigned(gomock.Any(), pvzID, userID).Return(true, nil)
+				mockRepo.EXPECT().CreateTransfers(gomock.Any(), receptionID, toPvzID, userID, []uuid.UUID{firstID}).
+					Return(nil, db.ErrPVZNotFound)
+			},
+			expectedError: db.ErrPVZNotFound,
+		},
+		{
+			name:       "repository error",
+			toPvzID:    toPvzID,
+			productIDs: []uuid.UUID{firstID},
+			mockSetup: func() {
+				mockRepo.EXPECT().GetReceptionPVZID(gomock.Any(), receptionID).Return(pvzID, nil)
+				mockRepo.EXPECT().IsEmployeeAssigned(gomock.Any(), pvzID, userID).Return(true, nil)
+				mockRepo.EXPECT().CreateTransfers(gomock.Any(), receptionID, toPvzID, userID, []uuid.UUID{firstID}).
+					Return(nil, ErrRandomError)
+			},
+			expectedError: ErrRandomError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockSetup()
+
+			result, err := pvzUC.TransferProducts(context.Background(), userID, receptionID, tt.toPvzID, tt.productIDs)
+
+			if tt.expectedError != nil {
+				assert.ErrorIs(t, err, tt.expectedError)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, tt.expected, result)
+		})
+	}
+}
+
+func TestPVZUC_CloseLastReception(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	cfg := &config.Config{}
+
+	mockRepo := mock_pvz.NewMockRepository(ctrl)
+	pvzUC := NewPVZUseCase(cfg, mockRepo)
+
+	userID := uuid.New()
+
+	testReception := &models.Reception{
+		ID:       uuid.New(),
+		PvzID:    uuid.New(),
+		Status:   "in_progress",
+		DateTime: time.Now(),
+	}
+
+	tests := []struct {
+		name          string
+		pvzID         uuid.UUID
+		mockSetup     func()
+		expected      models.Reception
+		expectedError error
+	}{
+		{
+			name:  "successful reception closing",
+			pvzID: uuid.New(),
+			mockSetup: func() {
+				mockRepo.EXPECT().IsEmployeeAssigned(gomock.Any(), gomock.Any(), userID).Return(true, nil)
+				mockRepo.EXPECT().
+					CloseLastReception(gomock.Any(), gomock.Any(), userID).
+					Return(testReception, nil)
+			},
+			expected:      *testReception,
+			expectedError: nil,
+		},
+		{
+			name:  "no open reception error",
+			pvzID: uuid.New(),
+			mockSetup: func() {
+				mockRepo.EXPECT().IsEmployeeAssigned(gomock.Any(), gomock.Any(), userID).Return(true, nil)
+				mockRepo.EXPECT().
+					CloseLastReception(gomock.Any(), gomock.Any(), userID).
+					Return(nil, db.ErrNoOpenReception)
+			},
+			expected:      models.Reception{},
+			expectedError: db.ErrNoOpenReception,
+		},
+		{
+			name:  "repository error",
+			pvzID: uuid.New(),
+			mockSetup: func() {
+				mockRepo.EXPECT().IsEmployeeAssigned(gomock.Any(), gomock.Any(), userID).Return(true, nil)
+				mockRepo.EXPECT().
+					CloseLastReception(gomock.Any(), gomock.Any(), userID).
+					Return(nil, ErrRandomError)
+			},
+			expected:      models.Reception{},
+			expectedError: ErrRandomError,
+		},
+		{
+			name:  "employee not assigned",
+			pvzID: uuid.New(),
+			mockSetup: func() {
+				mockRepo.EXPECT().IsEmployeeAssigned(gomock.Any(), gomock.Any(), userID).Return(false, nil)
+			},
+			expected:      models.Reception{},
+			expectedError: ErrPVZAccessDenied,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockSetup()
+
+			result, err := pvzUC.CloseLastReception(context.Background(), userID, tt.pvzID)
+
+			if tt.expectedError != nil {
+				assert.ErrorIs(t, err, tt.expectedError)
+				assert.Equal(t, tt.expected, result)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.expected, result)
+			}
+		})
+	}
+}
+
+func TestPVZUC_CancelLastReception(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	cfg := &config.Config{}
+
+	mockRepo := mock_pvz.NewMockRepository(ctrl)
+	pvzUC := NewPVZUseCase(cfg, mockRepo)
+
+	userID := uuid.New()
+
+	testReception := &models.Reception{
+		ID:       uuid.New(),
+		PvzID:    uuid.New(),
+		Status:   "cancelled",
+		ClosedBy: &userID,
+	}
+
+	tests := []struct {
+		name          string
+		mockSetup     func()
+		expected      models.Reception
+		expectedError error
+	}{
+		{
+			name: "successful cancellation",
+			mockSetup: func() {
+				mockRepo.EXPECT().IsEmployeeAssigned(gomock.Any(), gomock.Any(), userID).Return(true, nil)
+				mockRepo.EXPECT().
+					CancelLastReception(gomock.Any(), gomock.Any(), userID).
+					Return(testReception, nil)
+			},
+			expected:      *testReception,
+			expectedError: nil,
+		},
+		{
+			name: "no open reception error",
+			mockSetup: func() {
+				mockRepo.EXPECT().IsEmployeeAssigned(gomock.Any(), gomock.Any(), userID).Return(true, nil)
+				mockRepo.EXPECT().
+					CancelLastReception(gomock.Any(), gomock.Any(), userID).
+					Return(nil, db.ErrNoOpenReception)
+			},
+			expected:      models.Reception{},
+			expectedError: db.ErrNoOpenReception,
+		},
+		{
+			name: "repository error",
+			mockSetup: func() {
+				mockRepo.EXPECT().IsEmployeeAssigned(gomock.Any(), gomock.Any(), userID).Return(true, nil)
+				mockRepo.EXPECT().
+					CancelLastReception(gomock.Any(), gomock.Any(), userID).
+					Return(nil, ErrRandomError)
+			},
+			expected:      models.Reception{},
+			expectedError: ErrRandomError,
+		},
+		{
+			name: "employee not assigned",
+			mockSetup: func() {
+				mockRepo.EXPECT().IsEmployeeAssigned(gomock.Any(), gomock.Any(), userID).Return(false, nil)
+			},
+			expected:      models.Reception{},
+			expectedError: ErrPVZAccessDenied,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockSetup()
+
+			result, err := pvzUC.CancelLastReception(context.Background(), userID, uuid.New())
+
+			if tt.expectedError != nil {
+				assert.ErrorIs(t, err, tt.expectedError)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, tt.expected, result)
+		})
+	}
+}
+
+func TestPVZUC_ReopenReception(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	cfg := &config.Config{}
+
+	mockRepo := mock_pvz.NewMockRepository(ctrl)
+	pvzUC := NewPVZUseCase(cfg, mockRepo)
+
+	userID := uuid.New()
+	receptionID := uuid.New()
+	reason := "closed by mistake"
+	testReception := &models.Reception{ID: receptionID, Status: string(pvzapi.InProgress)}
+
+	tests := []struct {
+		name          string
+		mockSetup     func()
+		expected      models.Reception
+		expectedError error
+	}{
+		{
+			name: "successful reopen",
+			mockSetup: func() {
+				mockRepo.EXPECT().ReopenReception(gomock.Any(), receptionID, userID, reason).Return(testReception, nil)
+			},
+			expected:      *testReception,
+			expectedError: nil,
+		},
+		{
+			name: "reception not closed",
+			mockSetup: func() {
+				mockRepo.EXPECT().ReopenReception(gomock.Any(), receptionID, userID, reason).Return(nil, db.ErrReceptionNotClosed)
+			},
+			expected:      models.Reception{},
+			expectedError: db.ErrReceptionNotClosed,
+		},
+		{
+			name: "another reception is open",
+			mockSetup: func() {
+				mockRepo.EXPECT().ReopenReception(gomock.Any(), receptionID, userID, reason).Return(nil, db.ErrReceptionConflict)
+			},
+			expected:      models.Reception{},
+			expectedError: db.ErrReceptionConflict,
+		},
+		{
+			name: "repository error",
+			mockSetup: func() {
+				mockRepo.EXPECT().ReopenReception(gomock.Any(), receptionID, userID, reason).Return(nil, ErrRandomError)
+			},
+			expected:      models.Reception{},
+			expectedError: ErrRandomError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockSetup()
+
+			result, err := pvzUC.ReopenReception(context.Background(), userID, receptionID, reason)
+
+			if tt.expectedError != nil {
+				assert.ErrorIs(t, err, tt.expectedError)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, tt.expected, result)
+		})
+	}
+}
+
+func TestPVZUC_CloseStaleReceptions(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	cfg := &config.Config{
+		App: config.App{
+			ReceptionIdleTimeout: 2 * time.Hour,
+		},
+	}
+
+	mockRepo := mock_pvz.NewMockRepository(ctrl)
+	pvzUC := NewPVZUseCase(cfg, mockRepo)
+
+	closed := []models.Reception{
+		{ID: uuid.New(), PvzID: uuid.New(), Status: "close", AutoClosed: true},
+	}
+
+	checkIdleSince := func(t *testing.T, result []models.Reception, err error) func(context.Context, time.Time) ([]models.Reception, error) {
+		return func(_ context.Context, idleSince time.Time) ([]models.Reception, error) {
+			assert.WithinDuration(t, time.Now().Add(-2*time.Hour), idleSince, time.Minute)
+			return result, err
+		}
+	}
+
+	tests := []struct {
+		name          string
+		mockSetup     func()
+		expected      []models.Reception
+		expectedError error
+	}{
+		{
+			name: "successful closing",
+			mockSetup: func() {
+				mockRepo.EXPECT().CloseStaleReceptions(gomock.Any(), gomock.Any()).DoAndReturn(checkIdleSince(t, closed, nil))
+			},
+			expected:      closed,
+			expectedError: nil,
+		},
+		{
+			name: "repository error",
+			mockSetup: func() {
+				mockRepo.EXPECT().CloseStaleReceptions(gomock.Any(), gomock.Any()).DoAndReturn(checkIdleSince(t, nil, ErrRandomError))
+			},
+			expected:      nil,
+			expectedError: ErrRandomError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockSetup()
+
+			result, err := pvzUC.CloseStaleReceptions(context.Background())
+
+			if tt.expectedError != nil {
+				assert.ErrorIs(t, err, tt.expectedError)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, tt.expected, result)
+		})
+	}
+}
+func TestPVZUC_GetPVZs(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	cfg := &config.Config{}
+	mockRepo := mock_pvz.NewMockRepository(ctrl)
+	pvzUC := NewPVZUseCase(cfg, mockRepo)
+
+	now := time.Now()
+	testTime := now.Add(-time.Hour)
+	excludeCancelled := true
+	productCounts := true
+	productStatus := pvzapi.Stored
+	storedStatus := string(pvzapi.Stored)
+
+	testPVZs := []*models.PVZWithReceptions{
+		{
+			PVZ: models.PVZ{
+				ID:               uuid.New(),
+				City:             "Москва",
+				RegistrationDate: now.AddDate(0, -2, 0),
+			},
+			Receptions: []*models.ReceptionWithProducts{{
+				Reception: models.Reception{
+					ID:       uuid.New(),
+					DateTime: testTime,
+					PvzID:    uuid.New(),
+					Status:   "in_progress",
+				},
+				Products: []*models.Product{{
+					ID:          uuid.New(),
+					Type:        "обувь",
+					DateTime:    testTime,
+					ReceptionID: uuid.New(),
+				}},
+			}},
+		},
+		{
+			PVZ: models.PVZ{
+				ID:               uuid.New(),
+				City:             "Москва",
+				RegistrationDate: now.AddDate(0, -1, 0),
+			},
+			Receptions: []*models.ReceptionWithProducts{{
+				Reception: models.Reception{
+					ID:       uuid.New(),
+					DateTime: testTime.Add(-time.Hour),
+					PvzID:    uuid.New(),
+					Status:   "in_progress",
+				},
 				Products: []*models.Product{{
 					ID:          uuid.New(),
 					Type:        "обувь",
@@ -697,127 +3767,881 @@ func TestPVZUC_GetPVZs(t *testing.T) {
 			}},
 		},
 		{
-			PVZ: models.PVZ{
-				ID:               uuid.New(),
-				City:             "Москва",
-				RegistrationDate: now,
+			PVZ: models.PVZ{
+				ID:               uuid.New(),
+				City:             "Москва",
+				RegistrationDate: now,
+			},
+			Receptions: []*models.ReceptionWithProducts{{
+				Reception: models.Reception{
+					ID:       uuid.New(),
+					DateTime: testTime.Add(-2 * time.Hour),
+					PvzID:    uuid.New(),
+					Status:   "in_progress",
+				},
+				Products: []*models.Product{{
+					ID:          uuid.New(),
+					Type:        "обувь",
+					DateTime:    testTime.Add(-2 * time.Hour),
+					ReceptionID: uuid.New(),
+				}},
+			}},
+		},
+	}
+
+	tests := []struct {
+		name          string
+		params        pvzapi.GetPvzParams
+		mockSetup     func()
+		expectedCount int
+		expectedError error
+	}{
+		{
+			name: "default pagination",
+			params: pvzapi.GetPvzParams{
+				Page:  nil,
+				Limit: nil,
+			},
+			mockSetup: func() {
+				mockRepo.EXPECT().
+					GetPVZs(gomock.Any(), nil, nil, false, nil, false, uint64(10), uint64(0)).
+					Return(testPVZs[:2], nil)
+			},
+			expectedCount: 2,
+			expectedError: nil,
+		},
+		{
+			name: "custom pagination - second page",
+			params: pvzapi.GetPvzParams{
+				Page:  ptrToInt(2),
+				Limit: ptrToInt(2),
+			},
+			mockSetup: func() {
+				mockRepo.EXPECT().
+					GetPVZs(gomock.Any(), nil, nil, false, nil, false, uint64(2), uint64(2)).
+					Return(testPVZs[2:], nil)
+			},
+			expectedCount: 1,
+			expectedError: nil,
+		},
+		{
+			name: "limit exceeds total count",
+			params: pvzapi.GetPvzParams{
+				Page:  ptrToInt(1),
+				Limit: ptrToInt(5),
+			},
+			mockSetup: func() {
+				mockRepo.EXPECT().
+					GetPVZs(gomock.Any(), nil, nil, false, nil, false, uint64(5), uint64(0)).
+					Return(testPVZs, nil)
+			},
+			expectedCount: 3,
+			expectedError: nil,
+		},
+		{
+			name: "page correction",
+			params: pvzapi.GetPvzParams{
+				Page:  ptrToInt(-1),
+				Limit: ptrToInt(5),
+			},
+			mockSetup: func() {
+				mockRepo.EXPECT().
+					GetPVZs(gomock.Any(), nil, nil, false, nil, false, uint64(5), uint64(0)).
+					Return(testPVZs[:2], nil)
+			},
+			expectedCount: 2,
+			expectedError: nil,
+		},
+		{
+			name: "limit correction",
+			params: pvzapi.GetPvzParams{
+				Page:  ptrToInt(2),
+				Limit: ptrToInt(0),
+			},
+			mockSetup: func() {
+				mockRepo.EXPECT().
+					GetPVZs(gomock.Any(), nil, nil, false, nil, false, uint64(10), uint64(10)).
+					Return(testPVZs[2:], nil)
+			},
+			expectedCount: 1,
+			expectedError: nil,
+		},
+		{
+			name: "exclude cancelled receptions",
+			params: pvzapi.GetPvzParams{
+				ExcludeCancelled: &excludeCancelled,
+			},
+			mockSetup: func() {
+				mockRepo.EXPECT().
+					GetPVZs(gomock.Any(), nil, nil, true, nil, false, uint64(10), uint64(0)).
+					Return(testPVZs, nil)
+			},
+			expectedCount: 3,
+			expectedError: nil,
+		},
+		{
+			name: "filter by product status",
+			params: pvzapi.GetPvzParams{
+				ProductStatus: &productStatus,
+			},
+			mockSetup: func() {
+				mockRepo.EXPECT().
+					GetPVZs(gomock.Any(), nil, nil, false, &storedStatus, false, uint64(10), uint64(0)).
+					Return(testPVZs, nil)
+			},
+			expectedCount: 3,
+			expectedError: nil,
+		},
+		{
+			name: "product counts instead of products",
+			params: pvzapi.GetPvzParams{
+				ProductCounts: &productCounts,
+			},
+			mockSetup: func() {
+				mockRepo.EXPECT().
+					GetPVZs(gomock.Any(), nil, nil, false, nil, true, uint64(10), uint64(0)).
+					Return(testPVZs, nil)
+			},
+			expectedCount: 3,
+			expectedError: nil,
+		},
+		{
+			name: "invalid date range",
+			params: pvzapi.GetPvzParams{
+				StartDate: &now,
+				EndDate:   &testTime,
+			},
+			mockSetup:     func() {},
+			expectedCount: 0,
+			expectedError: ErrInvalidDateRange,
+		},
+		{
+			name: "repository error",
+			params: pvzapi.GetPvzParams{
+				Page:  nil,
+				Limit: nil,
+			},
+			mockSetup: func() {
+				mockRepo.EXPECT().
+					GetPVZs(gomock.Any(), nil, nil, false, nil, false, uint64(10), uint64(0)).
+					Return(nil, ErrRandomError)
+			},
+			expectedCount: 0,
+			expectedError: ErrRandomError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockSetup()
+
+			result, err := pvzUC.GetPVZs(context.Background(), tt.params)
+
+			if tt.expectedError != nil {
+				assert.Error(t, err)
+				assert.ErrorIs(t, err, tt.expectedError)
+			} else {
+				assert.NoError(t, err)
+				assert.Len(t, result, tt.expectedCount)
+			}
+		})
+	}
+}
+
+func TestPVZUC_GetPVZList(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	cfg := &config.Config{App: config.App{}}
+	mockRepo := mock_pvz.NewMockRepository(ctrl)
+	pvzUC := NewPVZUseCase(cfg, mockRepo)
+
+	testPVZs := []models.PVZ{
+		{
+			ID:               uuid.New(),
+			City:             "Москва",
+			RegistrationDate: time.Now(),
+		},
+		{
+			ID:               uuid.New(),
+			City:             "Москва",
+			RegistrationDate: time.Now(),
+		},
+	}
+
+	tests := []struct {
+		name          string
+		mockSetup     func()
+		expectedCount int
+		expectedError error
+	}{
+		{
+			name: "success",
+			mockSetup: func() {
+				mockRepo.EXPECT().
+					GetPVZList(gomock.Any()).
+					Return(testPVZs, nil)
+			},
+			expectedCount: 2,
+			expectedError: nil,
+		},
+		{
+			name: "repository error",
+			mockSetup: func() {
+				mockRepo.EXPECT().
+					GetPVZList(gomock.Any()).
+					Return(nil, ErrRandomError)
+			},
+			expectedCount: 0,
+			expectedError: ErrRandomError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockSetup()
+
+			result, err := pvzUC.GetPVZList(context.Background())
+
+			if tt.expectedError != nil {
+				assert.Error(t, err)
+				assert.ErrorIs(t, err, tt.expectedError)
+			} else {
+				assert.NoError(t, err)
+				assert.Len(t, result, tt.expectedCount)
+			}
+		})
+	}
+}
+
+func ptrToInt(i int) *int { return &i }
+
+func TestPVZUC_GetPVZ(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	cfg := &config.Config{}
+
+	mockRepo := mock_pvz.NewMockRepository(ctrl)
+	pvzUC := NewPVZUseCase(cfg, mockRepo)
+
+	pvzID := uuid.New()
+	testDetails := &models.PVZDetails{
+		PVZ:             models.PVZ{ID: pvzID, City: "Москва", Status: string(pvzapi.Active)},
+		ReceptionsCount: 1,
+		ProductsCount:   3,
+	}
+
+	tests := []struct {
+		name          string
+		mockSetup     func()
+		expected      models.PVZDetails
+		expectedError error
+	}{
+		{
+			name: "successful get",
+			mockSetup: func() {
+				mockRepo.EXPECT().GetPVZ(gomock.Any(), pvzID).Return(testDetails, nil)
+			},
+			expected:      *testDetails,
+			expectedError: nil,
+		},
+		{
+			name: "pvz not found",
+			mockSetup: func() {
+				mockRepo.EXPECT().GetPVZ(gomock.Any(), pvzID).Return(nil, db.ErrPVZNotFound)
+			},
+			expected:      models.PVZDetails{},
+			expectedError: db.ErrPVZNotFound,
+		},
+		{
+			name: "repository error",
+			mockSetup: func() {
+				mockRepo.EXPECT().GetPVZ(gomock.Any(), pvzID).Return(nil, ErrRandomError)
+			},
+			expected:      models.PVZDetails{},
+			expectedError: ErrRandomError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockSetup()
+
+			result, err := pvzUC.GetPVZ(context.Background(), pvzID)
+
+			if tt.expectedError != nil {
+				assert.ErrorIs(t, err, tt.expectedError)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, tt.expected, result)
+		})
+	}
+}
+
+func TestPVZUC_GetReception(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	cfg := &config.Config{}
+
+	mockRepo := mock_pvz.NewMockRepository(ctrl)
+	pvzUC := NewPVZUseCase(cfg, mockRepo)
+
+	receptionID := uuid.New()
+	testReception := &models.ReceptionWithProducts{
+		Reception: models.Reception{ID: receptionID, Status: string(pvzapi.InProgress)},
+		Products:  []*models.Product{{ID: uuid.New(), Type: "обувь", ReceptionID: receptionID}},
+	}
+
+	tests := []struct {
+		name          string
+		mockSetup     func()
+		expected      models.ReceptionWithProducts
+		expectedError error
+	}{
+		{
+			name: "successful get",
+			mockSetup: func() {
+				mockRepo.EXPECT().GetReception(gomock.Any(), receptionID).Return(testReception, nil)
+			},
+			expected:      *testReception,
+			expectedError: nil,
+		},
+		{
+			name: "reception not found",
+			mockSetup: func() {
+				mockRepo.EXPECT().GetReception(gomock.Any(), receptionID).Return(nil, db.ErrReceptionNotFound)
+			},
+			expected:      models.ReceptionWithProducts{},
+			expectedError: db.ErrReceptionNotFound,
+		},
+		{
+			name: "repository error",
+			mockSetup: func() {
+				mockRepo.EXPECT().GetReception(gomock.Any(), receptionID).Return(nil, ErrRandomError)
+			},
+			expected:      models.ReceptionWithProducts{},
+			expectedError: ErrRandomError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockSetup()
+
+			result, err := pvzUC.GetReception(context.Background(), receptionID)
+
+			if tt.expectedError != nil {
+				assert.ErrorIs(t, err, tt.expectedError)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, tt.expected, result)
+		})
+	}
+}
+
+func TestPVZUC_FindProductsByBarcode(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	cfg := &config.Config{}
+
+	mockRepo := mock_pvz.NewMockRepository(ctrl)
+	pvzUC := NewPVZUseCase(cfg, mockRepo)
+
+	barcode := "4600000000017"
+	testLocations := []models.ProductLocation{
+		{
+			Product:   models.Product{ID: uuid.New(), Type: "обувь", Barcode: &barcode},
+			Reception: models.Reception{ID: uuid.New(), Status: string(pvzapi.Close)},
+			PVZ:       models.PVZ{ID: uuid.New(), City: "Москва"},
+		},
+	}
+
+	tests := []struct {
+		name          string
+		mockSetup     func()
+		expected      []models.ProductLocation
+		expectedError error
+	}{
+		{
+			name: "successful search",
+			mockSetup: func() {
+				mockRepo.EXPECT().FindProductsByBarcode(gomock.Any(), barcode).Return(testLocations, nil)
+			},
+			expected:      testLocations,
+			expectedError: nil,
+		},
+		{
+			name: "repository error",
+			mockSetup: func() {
+				mockRepo.EXPECT().FindProductsByBarcode(gomock.Any(), barcode).Return(nil, ErrRandomError)
+			},
+			expected:      nil,
+			expectedError: ErrRandomError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockSetup()
+
+			result, err := pvzUC.FindProductsByBarcode(context.Background(), barcode)
+
+			if tt.expectedError != nil {
+				assert.ErrorIs(t, err, tt.expectedError)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, tt.expected, result)
+		})
+	}
+}
+
+func TestPVZUC_GetReceptionHistory(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	cfg := &config.Config{}
+
+	mockRepo := mock_pvz.NewMockRepository(ctrl)
+	pvzUC := NewPVZUseCase(cfg, mockRepo)
+
+	receptionID := uuid.New()
+	testRecords := []models.ReceptionAuditRecord{
+		{ID: uuid.New(), ReceptionID: receptionID, Action: string(pvzapi.Reopen), Reason: "closed by mistake"},
+	}
+
+	tests := []struct {
+		name          string
+		mockSetup     func()
+		expected      []models.ReceptionAuditRecord
+		expectedError error
+	}{
+		{
+			name: "successful get",
+			mockSetup: func() {
+				mockRepo.EXPECT().GetReceptionHistory(gomock.Any(), receptionID).Return(testRecords, nil)
+			},
+			expected:      testRecords,
+			expectedError: nil,
+		},
+		{
+			name: "reception not found",
+			mockSetup: func() {
+				mockRepo.EXPECT().GetReceptionHistory(gomock.Any(), receptionID).Return(nil, db.ErrReceptionNotFound)
+			},
+			expected:      nil,
+			expectedError: db.ErrReceptionNotFound,
+		},
+		{
+			name: "repository error",
+			mockSetup: func() {
+				mockRepo.EXPECT().GetReceptionHistory(gomock.Any(), receptionID).Return(nil, ErrRandomError)
+			},
+			expected:      nil,
+			expectedError: ErrRandomError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockSetup()
+
+			result, err := pvzUC.GetReceptionHistory(context.Background(), receptionID)
+
+			if tt.expectedError != nil {
+				assert.ErrorIs(t, err, tt.expectedError)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, tt.expected, result)
+		})
+	}
+}
+
+func TestPVZUC_AssignEmployee(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	cfg := &config.Config{}
+
+	mockRepo := mock_pvz.NewMockRepository(ctrl)
+	pvzUC := NewPVZUseCase(cfg, mockRepo)
+
+	pvzID := uuid.New()
+	userID := uuid.New()
+	testAssignment := &models.EmployeeAssignment{
+		UserID:     userID,
+		PvzID:      pvzID,
+		AssignedAt: time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC),
+	}
+
+	tests := []struct {
+		name          string
+		mockSetup     func()
+		expected      models.EmployeeAssignment
+		expectedError error
+	}{
+		{
+			name: "successful assignment",
+			mockSetup: func() {
+				mockRepo.EXPECT().AssignEmployee(gomock.Any(), pvzID, userID).Return(testAssignment, nil)
+			},
+			expected:      *testAssignment,
+			expectedError: nil,
+		},
+		{
+			name: "user is not an employee",
+			mockSetup: func() {
+				mockRepo.EXPECT().AssignEmployee(gomock.Any(), pvzID, userID).Return(nil, db.ErrNotEmployee)
+			},
+			expected:      models.EmployeeAssignment{},
+			expectedError: db.ErrNotEmployee,
+		},
+		{
+			name: "already assigned",
+			mockSetup: func() {
+				mockRepo.EXPECT().AssignEmployee(gomock.Any(), pvzID, userID).Return(nil, db.ErrAlreadyAssigned)
+			},
+			expected:      models.EmployeeAssignment{},
+			expectedError: db.ErrAlreadyAssigned,
+		},
+		{
+			name: "pvz not found",
+			mockSetup: func() {
+				mockRepo.EXPECT().AssignEmployee(gomock.Any(), pvzID, userID).Return(nil, db.ErrPVZNotFound)
+			},
+			expected:      models.EmployeeAssignment{},
+			expectedError: db.ErrPVZNotFound,
+		},
+		{
+			name: "repository error",
+			mockSetup: func() {
+				mockRepo.EXPECT().AssignEmployee(gomock.Any(), pvzID, userID).Return(nil, ErrRandomError)
+			},
+			expected:      models.EmployeeAssignment{},
+			expectedError: ErrRandomError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockSetup()
+
+			result, err := pvzUC.AssignEmployee(context.Background(), pvzID, userID)
+
+			if tt.expectedError != nil {
+				assert.ErrorIs(t, err, tt.expectedError)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, tt.expected, result)
+		})
+	}
+}
+
+func TestPVZUC_UnassignEmployee(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	cfg := &config.Config{}
+
+	mockRepo := mock_pvz.NewMockRepository(ctrl)
+	pvzUC := NewPVZUseCase(cfg, mockRepo)
+
+	pvzID := uuid.New()
+	userID := uuid.New()
+
+	tests := []struct {
+		name          string
+		mockSetup     func()
+		expectedError error
+	}{
+		{
+			name: "successful unassignment",
+			mockSetup: func() {
+				mockRepo.EXPECT().UnassignEmployee(gomock.Any(), pvzID, userID).Return(nil)
+			},
+			expectedError: nil,
+		},
+		{
+			name: "not assigned",
+			mockSetup: func() {
+				mockRepo.EXPECT().UnassignEmployee(gomock.Any(), pvzID, userID).Return(db.ErrNotAssigned)
+			},
+			expectedError: db.ErrNotAssigned,
+		},
+		{
+			name: "repository error",
+			mockSetup: func() {
+				mockRepo.EXPECT().UnassignEmployee(gomock.Any(), pvzID, userID).Return(ErrRandomError)
+			},
+			expectedError: ErrRandomError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockSetup()
+
+			err := pvzUC.UnassignEmployee(context.Background(), pvzID, userID)
+
+			if tt.expectedError != nil {
+				assert.ErrorIs(t, err, tt.expectedError)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestPVZUC_CreateCells(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	cfg := &config.Config{}
+
+	mockRepo := mock_pvz.NewMockRepository(ctrl)
+	pvzUC := NewPVZUseCase(cfg, mockRepo)
+
+	pvzID := uuid.New()
+	testCells := []models.StorageCell{
+		{ID: uuid.New(), PvzID: pvzID, Number: 1, Capacity: 20},
+		{ID: uuid.New(), PvzID: pvzID, Number: 2, Capacity: 20},
+	}
+
+	tests := []struct {
+		name          string
+		mockSetup     func()
+		expected      []models.StorageCell
+		expectedError error
+	}{
+		{
+			name: "cells created",
+			mockSetup: func() {
+				mockRepo.EXPECT().CreateCells(gomock.Any(), pvzID, 2, 20).Return(testCells, nil)
+			},
+			expected:      testCells,
+			expectedError: nil,
+		},
+		{
+			name: "pvz not found",
+			mockSetup: func() {
+				mockRepo.EXPECT().CreateCells(gomock.Any(), pvzID, 2, 20).Return(nil, db.ErrPVZNotFound)
+			},
+			expectedError: db.ErrPVZNotFound,
+		},
+		{
+			name: "repository error",
+			mockSetup: func() {
+				mockRepo.EXPECT().CreateCells(gomock.Any(), pvzID, 2, 20).Return(nil, ErrRandomError)
 			},
-			Receptions: []*models.ReceptionWithProducts{{
-				Reception: models.Reception{
-					ID:       uuid.New(),
-					DateTime: testTime.Add(-2 * time.Hour),
-					PvzID:    uuid.New(),
-					Status:   "in_progress",
-				},
-				Products: []*models.Product{{
-					ID:          uuid.New(),
-					Type:        "обувь",
-					DateTime:    testTime.Add(-2 * time.Hour),
-					ReceptionID: uuid.New(),
-				}},
-			}},
+			expectedError: ErrRandomError,
 		},
 	}
 
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockSetup()
+
+			result, err := pvzUC.CreateCells(context.Background(), pvzID, 2, 20)
+
+			if tt.expectedError != nil {
+				assert.ErrorIs(t, err, tt.expectedError)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, tt.expected, result)
+		})
+	}
+}
+
+func TestPVZUC_GetCells(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	cfg := &config.Config{}
+
+	mockRepo := mock_pvz.NewMockRepository(ctrl)
+	pvzUC := NewPVZUseCase(cfg, mockRepo)
+
+	pvzID := uuid.New()
+	testOccupancy := &models.CellOccupancy{
+		Cells:      []models.StorageCell{{ID: uuid.New(), PvzID: pvzID, Number: 1, Capacity: 20, Occupied: 7}},
+		Capacity:   20,
+		Occupied:   7,
+		Unassigned: 2,
+	}
+
 	tests := []struct {
 		name          string
-		params        pvzapi.GetPvzParams
 		mockSetup     func()
-		expectedCount int
+		expected      models.CellOccupancy
 		expectedError error
 	}{
 		{
-			name: "default pagination",
-			params: pvzapi.GetPvzParams{
-				Page:  nil,
-				Limit: nil,
-			},
+			name: "occupancy returned",
 			mockSetup: func() {
-				mockRepo.EXPECT().
-					GetPVZs(gomock.Any(), nil, nil, uint64(10), uint64(0)).
-					Return(testPVZs[:2], nil)
+				mockRepo.EXPECT().GetCells(gomock.Any(), pvzID).Return(testOccupancy, nil)
 			},
-			expectedCount: 2,
+			expected:      *testOccupancy,
 			expectedError: nil,
 		},
 		{
-			name: "custom pagination - second page",
-			params: pvzapi.GetPvzParams{
-				Page:  ptrToInt(2),
-				Limit: ptrToInt(2),
-			},
+			name: "pvz not found",
 			mockSetup: func() {
-				mockRepo.EXPECT().
-					GetPVZs(gomock.Any(), nil, nil, uint64(2), uint64(2)).
-					Return(testPVZs[2:], nil)
+				mockRepo.EXPECT().GetCells(gomock.Any(), pvzID).Return(nil, db.ErrPVZNotFound)
 			},
-			expectedCount: 1,
-			expectedError: nil,
+			expectedError: db.ErrPVZNotFound,
 		},
 		{
-			name: "limit exceeds total count",
-			params: pvzapi.GetPvzParams{
-				Page:  ptrToInt(1),
-				Limit: ptrToInt(5),
+			name: "repository error",
+			mockSetup: func() {
+				mockRepo.EXPECT().GetCells(gomock.Any(), pvzID).Return(nil, ErrRandomError)
 			},
+			expectedError: ErrRandomError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockSetup()
+
+			result, err := pvzUC.GetCells(context.Background(), pvzID)
+
+			if tt.expectedError != nil {
+				assert.ErrorIs(t, err, tt.expectedError)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, tt.expected, result)
+		})
+	}
+}
+
+func TestPVZUC_GetCapacity(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	cfg := &config.Config{}
+
+	mockRepo := mock_pvz.NewMockRepository(ctrl)
+	pvzUC := NewPVZUseCase(cfg, mockRepo)
+
+	pvzID := uuid.New()
+	capacity := 100
+	testCapacity := &models.PVZCapacity{PvzID: pvzID, Capacity: &capacity, Stored: 42}
+
+	tests := []struct {
+		name          string
+		mockSetup     func()
+		expected      models.PVZCapacity
+		expectedError error
+	}{
+		{
+			name: "capacity returned",
 			mockSetup: func() {
-				mockRepo.EXPECT().
-					GetPVZs(gomock.Any(), nil, nil, uint64(5), uint64(0)).
-					Return(testPVZs, nil)
+				mockRepo.EXPECT().GetPVZCapacity(gomock.Any(), pvzID).Return(testCapacity, nil)
 			},
-			expectedCount: 3,
+			expected:      *testCapacity,
 			expectedError: nil,
 		},
 		{
-			name: "page correction",
-			params: pvzapi.GetPvzParams{
-				Page:  ptrToInt(-1),
-				Limit: ptrToInt(5),
-			},
+			name: "pvz not found",
 			mockSetup: func() {
-				mockRepo.EXPECT().
-					GetPVZs(gomock.Any(), nil, nil, uint64(5), uint64(0)).
-					Return(testPVZs[:2], nil)
+				mockRepo.EXPECT().GetPVZCapacity(gomock.Any(), pvzID).Return(nil, db.ErrPVZNotFound)
 			},
-			expectedCount: 2,
-			expectedError: nil,
+			expectedError: db.ErrPVZNotFound,
 		},
 		{
-			name: "limit correction",
-			params: pvzapi.GetPvzParams{
-				Page:  ptrToInt(2),
-				Limit: ptrToInt(0),
+			name: "repository error",
+			mockSetup: func() {
+				mockRepo.EXPECT().GetPVZCapacity(gomock.Any(), pvzID).Return(nil, ErrRandomError)
 			},
+			expectedError: ErrRandomError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockSetup()
+
+			result, err := pvzUC.GetCapacity(context.Background(), pvzID)
+
+			if tt.expectedError != nil {
+				assert.ErrorIs(t, err, tt.expectedError)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, tt.expected, result)
+		})
+	}
+}
+
+func TestPVZUC_SetCapacity(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	cfg := &config.Config{}
+
+	mockRepo := mock_pvz.NewMockRepository(ctrl)
+	pvzUC := NewPVZUseCase(cfg, mockRepo)
+
+	pvzID := uuid.New()
+	capacity, receptionLimit, zero := 100, 20, 0
+	testCapacity := &models.PVZCapacity{PvzID: pvzID, Capacity: &capacity, ReceptionLimit: &receptionLimit, Stored: 7}
+
+	tests := []struct {
+		name           string
+		capacity       *int
+		receptionLimit *int
+		mockSetup      func()
+		expected       models.PVZCapacity
+		expectedError  error
+	}{
+		{
+			name:           "limits set",
+			capacity:       &capacity,
+			receptionLimit: &receptionLimit,
 			mockSetup: func() {
-				mockRepo.EXPECT().
-					GetPVZs(gomock.Any(), nil, nil, uint64(10), uint64(10)).
-					Return(testPVZs[2:], nil)
+				mockRepo.EXPECT().SetPVZCapacity(gomock.Any(), pvzID, &capacity, &receptionLimit).Return(testCapacity, nil)
 			},
-			expectedCount: 1,
+			expected:      *testCapacity,
 			expectedError: nil,
 		},
 		{
-			name: "invalid date range",
-			params: pvzapi.GetPvzParams{
-				StartDate: &now,
-				EndDate:   &testTime,
-			},
+			name:          "zero capacity",
+			capacity:      &zero,
 			mockSetup:     func() {},
-			expectedCount: 0,
-			expectedError: ErrInvalidDateRange,
+			expectedError: ErrInvalidCapacity,
 		},
 		{
-			name: "repository error",
-			params: pvzapi.GetPvzParams{
-				Page:  nil,
-				Limit: nil,
+			name:           "zero reception limit",
+			capacity:       &capacity,
+			receptionLimit: &zero,
+			mockSetup:      func() {},
+			expectedError:  ErrInvalidCapacity,
+		},
+		{
+			name:           "pvz not found",
+			capacity:       &capacity,
+			receptionLimit: &receptionLimit,
+			mockSetup: func() {
+				mockRepo.EXPECT().SetPVZCapacity(gomock.Any(), pvzID, &capacity, &receptionLimit).Return(nil, db.ErrPVZNotFound)
 			},
+			expectedError: db.ErrPVZNotFound,
+		},
+		{
+			name:           "repository error",
+			capacity:       &capacity,
+			receptionLimit: &receptionLimit,
 			mockSetup: func() {
-				mockRepo.EXPECT().
-					GetPVZs(gomock.Any(), nil, nil, uint64(10), uint64(0)).
-					Return(nil, ErrRandomError)
+				mockRepo.EXPECT().SetPVZCapacity(gomock.Any(), pvzID, &capacity, &receptionLimit).Return(nil, ErrRandomError)
 			},
-			expectedCount: 0,
 			expectedError: ErrRandomError,
 		},
 	}
@@ -826,64 +4650,134 @@ func TestPVZUC_GetPVZs(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			tt.mockSetup()
 
-			result, err := pvzUC.GetPVZs(context.Background(), tt.params)
+			result, err := pvzUC.SetCapacity(context.Background(), pvzID, tt.capacity, tt.receptionLimit)
 
 			if tt.expectedError != nil {
-				assert.Error(t, err)
 				assert.ErrorIs(t, err, tt.expectedError)
 			} else {
 				assert.NoError(t, err)
-				assert.Len(t, result, tt.expectedCount)
 			}
+			assert.Equal(t, tt.expected, result)
 		})
 	}
 }
 
-func TestPVZUC_GetPVZList(t *testing.T) {
+func TestPVZUC_GetUtilization(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
 
-	cfg := &config.Config{App: config.App{}}
+	cfg := &config.Config{}
+
 	mockRepo := mock_pvz.NewMockRepository(ctrl)
 	pvzUC := NewPVZUseCase(cfg, mockRepo)
 
-	testPVZs := []models.PVZ{
+	capacity := 100
+	testUtilization := []models.PVZCapacity{{PvzID: uuid.New(), Capacity: &capacity, Stored: 50}}
+
+	tests := []struct {
+		name          string
+		mockSetup     func()
+		expected      []models.PVZCapacity
+		expectedError error
+	}{
 		{
-			ID:               uuid.New(),
-			City:             "Москва",
-			RegistrationDate: time.Now(),
+			name: "utilization returned",
+			mockSetup: func() {
+				mockRepo.EXPECT().GetPVZUtilization(gomock.Any()).Return(testUtilization, nil)
+			},
+			expected:      testUtilization,
+			expectedError: nil,
 		},
 		{
-			ID:               uuid.New(),
-			City:             "Москва",
-			RegistrationDate: time.Now(),
+			name: "repository error",
+			mockSetup: func() {
+				mockRepo.EXPECT().GetPVZUtilization(gomock.Any()).Return(nil, ErrRandomError)
+			},
+			expectedError: ErrRandomError,
 		},
 	}
 
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockSetup()
+
+			result, err := pvzUC.GetUtilization(context.Background())
+
+			if tt.expectedError != nil {
+				assert.ErrorIs(t, err, tt.expectedError)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, tt.expected, result)
+		})
+	}
+}
+
+func TestPVZUC_GetReceptionProducts(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	cfg := &config.Config{}
+
+	mockRepo := mock_pvz.NewMockRepository(ctrl)
+	pvzUC := NewPVZUseCase(cfg, mockRepo)
+
+	receptionID := uuid.New()
+	productType := "обувь"
+	cursor, limit, negative, tooLarge := 5, 2, -1, 1000
+
+	testProducts := []models.Product{
+		{ID: uuid.New(), Type: "обувь", ReceptionID: receptionID, LineNumber: 6},
+		{ID: uuid.New(), Type: "обувь", ReceptionID: receptionID, LineNumber: 8},
+		{ID: uuid.New(), Type: "обувь", ReceptionID: receptionID, LineNumber: 9},
+	}
+	nextCursor := 8
+
 	tests := []struct {
 		name          string
+		params        pvzapi.GetReceptionsReceptionIdProductsParams
 		mockSetup     func()
-		expectedCount int
+		expected      models.ProductPage
 		expectedError error
 	}{
 		{
-			name: "success",
+			name:   "page with next cursor",
+			params: pvzapi.GetReceptionsReceptionIdProductsParams{Type: &productType, Cursor: &cursor, Limit: &limit},
 			mockSetup: func() {
-				mockRepo.EXPECT().
-					GetPVZList(gomock.Any()).
-					Return(testPVZs, nil)
+				mockRepo.EXPECT().GetReceptionProducts(gomock.Any(), receptionID, &productType, 5, 3).Return(testProducts, nil)
 			},
-			expectedCount: 2,
+			expected:      models.ProductPage{Products: testProducts[:2], NextCursor: &nextCursor},
+			expectedError: nil,
+		},
+		{
+			name: "last page",
+			mockSetup: func() {
+				mockRepo.EXPECT().GetReceptionProducts(gomock.Any(), receptionID, nil, 0, 21).Return(testProducts, nil)
+			},
+			expected:      models.ProductPage{Products: testProducts},
+			expectedError: nil,
+		},
+		{
+			name:   "out of range values fall back to defaults",
+			params: pvzapi.GetReceptionsReceptionIdProductsParams{Cursor: &negative, Limit: &tooLarge},
+			mockSetup: func() {
+				mockRepo.EXPECT().GetReceptionProducts(gomock.Any(), receptionID, nil, 0, 21).Return([]models.Product{}, nil)
+			},
+			expected:      models.ProductPage{Products: []models.Product{}},
 			expectedError: nil,
 		},
+		{
+			name: "reception not found",
+			mockSetup: func() {
+				mockRepo.EXPECT().GetReceptionProducts(gomock.Any(), receptionID, nil, 0, 21).Return(nil, db.ErrReceptionNotFound)
+			},
+			expectedError: db.ErrReceptionNotFound,
+		},
 		{
 			name: "repository error",
 			mockSetup: func() {
-				mockRepo.EXPECT().
-					GetPVZList(gomock.Any()).
-					Return(nil, ErrRandomError)
+				mockRepo.EXPECT().GetReceptionProducts(gomock.Any(), receptionID, nil, 0, 21).Return(nil, ErrRandomError)
 			},
-			expectedCount: 0,
 			expectedError: ErrRandomError,
 		},
 	}
@@ -892,17 +4786,14 @@ func TestPVZUC_GetPVZList(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			tt.mockSetup()
 
-			result, err := pvzUC.GetPVZList(context.Background())
+			result, err := pvzUC.GetReceptionProducts(context.Background(), receptionID, tt.params)
 
 			if tt.expectedError != nil {
-				assert.Error(t, err)
 				assert.ErrorIs(t, err, tt.expectedError)
 			} else {
 				assert.NoError(t, err)
-				assert.Len(t, result, tt.expectedCount)
 			}
+			assert.Equal(t, tt.expected, result)
 		})
 	}
 }
-
-func ptrToInt(i int) *int { return &i }
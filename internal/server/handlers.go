@@ -19,6 +19,7 @@ func (s *Server) RegisterHandlers() *echo.Echo {
 	if err != nil {
 		s.logger.Info("CreateMetrics", zap.Error(err))
 	}
+	s.metrics = metrics
 	s.logger.Info("metrics server started",
 		zap.String("available URL", s.config.Metrics.URL),
 		zap.String("service name", s.config.Metrics.ServiceName),
@@ -26,14 +27,18 @@ func (s *Server) RegisterHandlers() *echo.Echo {
 
 	e := echo.New()
 
+	// Client ip is taken from X-Forwarded-For only when the request came through a trusted private network proxy
+	e.IPExtractor = echo.ExtractIPFromXFFHeader()
+
 	e.Use(middleware.Recover())
 
 	pvzRepo := repository.NewPVZRepo(s.db)
 	pvzUC := usecase.NewPVZUseCase(s.config, pvzRepo)
 	pvzHandlers := http.NewPVZHandlers(pvzUC, s.logger, metrics)
 
-	mw := mm.NewManager(s.config, s.logger)
+	mw := mm.NewManager(s.config, s.logger, pvzUC)
 	e.Use(mw.Authenticate)
+	e.Use(mw.Authorize)
 	e.Use(mw.MetricsMiddleware(metrics))
 
 	pvzapi.RegisterHandlers(e, pvzHandlers)
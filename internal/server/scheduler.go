@@ -0,0 +1,169 @@
+package server
+
+import (
+	"context"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/cyansnbrst/pvz-service/internal/pvz"
+	"github.com/cyansnbrst/pvz-service/internal/pvz/repository"
+	"github.com/cyansnbrst/pvz-service/internal/pvz/usecase"
+)
+
+// Run the background worker closing stale receptions until the context is cancelled
+func (s *Server) RunStaleReceptionsCloser(ctx context.Context) {
+	interval := s.config.App.StaleCheckInterval
+	idleTimeout := s.config.App.ReceptionIdleTimeout
+
+	if interval <= 0 || idleTimeout <= 0 {
+		s.logger.Info("stale receptions closer disabled")
+		return
+	}
+
+	pvzRepo := repository.NewPVZRepo(s.db)
+	pvzUC := usecase.NewPVZUseCase(s.config, pvzRepo)
+
+	s.logger.Info("starting stale receptions closer",
+		zap.Duration("interval", interval),
+		zap.Duration("idle_timeout", idleTimeout),
+	)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.closeStaleReceptions(ctx, pvzUC)
+		}
+	}
+}
+
+// Close stale receptions once, logging and counting every closed reception
+func (s *Server) closeStaleReceptions(ctx context.Context, pvzUC pvz.UseCase) {
+	receptions, err := pvzUC.CloseStaleReceptions(ctx)
+	if err != nil {
+		s.logger.Error("failed to close stale receptions", zap.Error(err))
+		return
+	}
+
+	for _, reception := range receptions {
+		s.logger.Info("stale reception closed automatically",
+			zap.String("reception_id", reception.ID.String()),
+			zap.String("pvz_id", reception.PvzID.String()),
+			zap.Int("discrepancies", len(reception.Discrepancies)),
+		)
+
+		if s.metrics != nil {
+			s.metrics.IncReceptionsAutoClosed()
+		}
+	}
+}
+
+// Run the background worker exporting pvz utilization until the context is cancelled
+func (s *Server) RunUtilizationCollector(ctx context.Context) {
+	interval := s.config.App.UtilizationInterval
+
+	if interval <= 0 || s.metrics == nil {
+		s.logger.Info("pvz utilization collector disabled")
+		return
+	}
+
+	pvzRepo := repository.NewPVZRepo(s.db)
+	pvzUC := usecase.NewPVZUseCase(s.config, pvzRepo)
+
+	s.logger.Info("starting pvz utilization collector",
+		zap.Duration("interval", interval),
+	)
+
+	s.collectUtilization(ctx, pvzUC)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.collectUtilization(ctx, pvzUC)
+		}
+	}
+}
+
+// Export utilization of every pvz with a capacity limit once
+func (s *Server) collectUtilization(ctx context.Context, pvzUC pvz.UseCase) {
+	pvzs, err := pvzUC.GetUtilization(ctx)
+	if err != nil {
+		s.logger.Error("failed to collect pvz utilization", zap.Error(err))
+		return
+	}
+
+	utilization := make(map[string]float64, len(pvzs))
+	for _, p := range pvzs {
+		if p.Capacity != nil {
+			utilization[p.PvzID.String()] = float64(p.Stored) / float64(*p.Capacity)
+		}
+	}
+
+	s.metrics.SetPVZUtilization(utilization)
+}
+
+// Run the background worker deleting expired refresh tokens, denylist entries and stale login attempts until the context is cancelled
+func (s *Server) RunExpiredTokensCleaner(ctx context.Context) {
+	interval := s.config.App.TokenCleanupInterval
+
+	if interval <= 0 {
+		s.logger.Info("expired tokens cleaner disabled")
+		return
+	}
+
+	pvzRepo := repository.NewPVZRepo(s.db)
+	pvzUC := usecase.NewPVZUseCase(s.config, pvzRepo)
+
+	s.logger.Info("starting expired tokens cleaner",
+		zap.Duration("interval", interval),
+	)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.deleteExpiredTokens(ctx, pvzUC)
+		}
+	}
+}
+
+// Delete expired tokens and stale login attempts once
+func (s *Server) deleteExpiredTokens(ctx context.Context, pvzUC pvz.UseCase) {
+	deleted, err := pvzUC.DeleteExpiredTokens(ctx)
+	if err != nil {
+		s.logger.Error("failed to delete expired tokens", zap.Error(err))
+		return
+	}
+
+	if deleted > 0 {
+		s.logger.Info("expired tokens deleted",
+			zap.Int64("count", deleted),
+		)
+	}
+
+	deleted, err = pvzUC.DeleteStaleLoginAttempts(ctx)
+	if err != nil {
+		s.logger.Error("failed to delete stale login attempts", zap.Error(err))
+		return
+	}
+
+	if deleted > 0 {
+		s.logger.Info("stale login attempts deleted",
+			zap.Int64("count", deleted),
+		)
+	}
+}
@@ -15,6 +15,8 @@ import (
 	"google.golang.org/grpc"
 
 	"github.com/cyansnbrst/pvz-service/config"
+	"github.com/cyansnbrst/pvz-service/pkg/auth/jwt"
+	"github.com/cyansnbrst/pvz-service/pkg/metric"
 )
 
 // Server struct
@@ -22,6 +24,7 @@ type Server struct {
 	config     *config.Config
 	logger     *zap.Logger
 	db         *pgxpool.Pool
+	metrics    metric.Metrics
 	httpServer *http.Server
 	grpcServer *grpc.Server
 }
@@ -37,6 +40,11 @@ func NewServer(cfg *config.Config, logger *zap.Logger, db *pgxpool.Pool) *Server
 
 // Run server
 func (s *Server) Run() error {
+	// Fail on startup rather than on the first login when the keys are misconfigured
+	if _, err := jwt.LoadKeySet(s.config.App.JWTSecretKey, s.config.App.JWTKeysDir, s.config.App.JWTSigningKeyID, s.config.App.JWTHMACFallback); err != nil {
+		return fmt.Errorf("failed to load JWT keys: %w", err)
+	}
+
 	s.httpServer = &http.Server{
 		Addr:         fmt.Sprintf(":%d", s.config.App.HTTPPort),
 		Handler:      s.RegisterHandlers(),
@@ -53,6 +61,13 @@ func (s *Server) Run() error {
 	s.grpcServer = grpc.NewServer()
 	s.RegisterServices()
 
+	workerCtx, stopWorker := context.WithCancel(context.Background())
+	defer stopWorker()
+
+	go s.RunStaleReceptionsCloser(workerCtx)
+	go s.RunUtilizationCollector(workerCtx)
+	go s.RunExpiredTokensCleaner(workerCtx)
+
 	shutDownError := make(chan error, 2)
 
 	go func() {
@@ -82,6 +97,8 @@ func (s *Server) Run() error {
 			zap.String("signal", sig.String()),
 		)
 
+		stopWorker()
+
 		ctx, cancel := context.WithTimeout(context.Background(), s.config.App.ShutdownTimeout)
 		defer cancel()
 
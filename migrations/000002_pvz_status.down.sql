@@ -0,0 +1 @@
+ALTER TABLE pvzs DROP COLUMN IF EXISTS status;
@@ -0,0 +1,2 @@
+ALTER TABLE pvzs
+    ADD COLUMN status VARCHAR(50) CHECK (status IN ('active', 'suspended', 'closed')) NOT NULL DEFAULT 'active';
@@ -0,0 +1,6 @@
+ALTER TABLE pvzs DROP CONSTRAINT IF EXISTS pvzs_city_fkey;
+
+ALTER TABLE pvzs
+    ADD CONSTRAINT pvzs_city_check CHECK (city IN ('Москва', 'Санкт-Петербург', 'Казань'));
+
+DROP TABLE IF EXISTS cities;
@@ -0,0 +1,12 @@
+CREATE TABLE cities (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    name VARCHAR(50) UNIQUE NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+INSERT INTO cities (name) VALUES ('Москва'), ('Санкт-Петербург'), ('Казань');
+
+ALTER TABLE pvzs DROP CONSTRAINT IF EXISTS pvzs_city_check;
+
+ALTER TABLE pvzs
+    ADD CONSTRAINT pvzs_city_fkey FOREIGN KEY (city) REFERENCES cities(name) ON UPDATE CASCADE;
@@ -0,0 +1,6 @@
+ALTER TABLE products DROP CONSTRAINT IF EXISTS products_type_fkey;
+
+ALTER TABLE products
+    ADD CONSTRAINT products_type_check CHECK (type IN ('электроника', 'одежда', 'обувь'));
+
+DROP TABLE IF EXISTS product_types;
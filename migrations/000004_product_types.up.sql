@@ -0,0 +1,16 @@
+CREATE TABLE product_types (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    name VARCHAR(50) UNIQUE NOT NULL,
+    display_name VARCHAR(100) NOT NULL,
+    retired_at TIMESTAMP WITH TIME ZONE
+);
+
+INSERT INTO product_types (name, display_name) VALUES
+    ('электроника', 'Электроника'),
+    ('одежда', 'Одежда'),
+    ('обувь', 'Обувь');
+
+ALTER TABLE products DROP CONSTRAINT IF EXISTS products_type_check;
+
+ALTER TABLE products
+    ADD CONSTRAINT products_type_fkey FOREIGN KEY (type) REFERENCES product_types(name);
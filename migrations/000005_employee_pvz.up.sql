@@ -0,0 +1,8 @@
+CREATE TABLE employee_pvz (
+    user_id UUID REFERENCES users(id) ON DELETE CASCADE NOT NULL,
+    pvz_id UUID REFERENCES pvzs(id) ON DELETE CASCADE NOT NULL,
+    assigned_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (user_id, pvz_id)
+);
+
+CREATE INDEX idx_employee_pvz_pvz_id ON employee_pvz (pvz_id);
@@ -0,0 +1,6 @@
+ALTER TABLE receptions
+    ADD COLUMN created_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    ADD COLUMN closed_by UUID REFERENCES users(id) ON DELETE SET NULL;
+
+ALTER TABLE products
+    ADD COLUMN created_by UUID REFERENCES users(id) ON DELETE SET NULL;
@@ -0,0 +1,15 @@
+CREATE TABLE reception_manifest (
+    reception_id UUID REFERENCES receptions(id) ON DELETE CASCADE NOT NULL,
+    type VARCHAR(50) REFERENCES product_types(name) NOT NULL,
+    expected_count INTEGER CHECK (expected_count > 0) NOT NULL,
+    PRIMARY KEY (reception_id, type)
+);
+
+CREATE TABLE reception_discrepancies (
+    reception_id UUID REFERENCES receptions(id) ON DELETE CASCADE NOT NULL,
+    type VARCHAR(50) REFERENCES product_types(name) NOT NULL,
+    kind VARCHAR(20) CHECK (kind IN ('missing', 'extra', 'unexpected')) NOT NULL,
+    expected_count INTEGER NOT NULL,
+    actual_count INTEGER NOT NULL,
+    PRIMARY KEY (reception_id, type)
+);
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_products_reception_id_date_time;
+
+ALTER TABLE receptions DROP COLUMN IF EXISTS auto_closed;
@@ -0,0 +1,3 @@
+ALTER TABLE receptions ADD COLUMN auto_closed BOOLEAN DEFAULT FALSE NOT NULL;
+
+CREATE INDEX idx_products_reception_id_date_time ON products (reception_id, date_time);
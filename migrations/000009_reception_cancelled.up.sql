@@ -0,0 +1,4 @@
+ALTER TABLE receptions DROP CONSTRAINT receptions_status_check;
+
+ALTER TABLE receptions
+    ADD CONSTRAINT receptions_status_check CHECK (status IN ('in_progress', 'close', 'cancelled'));
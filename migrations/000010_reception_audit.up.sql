@@ -0,0 +1,10 @@
+CREATE TABLE reception_audit (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    reception_id UUID REFERENCES receptions(id) ON DELETE CASCADE NOT NULL,
+    action VARCHAR(20) CHECK (action IN ('reopen')) NOT NULL,
+    reason TEXT NOT NULL,
+    user_id UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP NOT NULL
+);
+
+CREATE INDEX idx_reception_audit_reception_id ON reception_audit (reception_id);
@@ -0,0 +1,4 @@
+ALTER TABLE products ADD COLUMN barcode VARCHAR(64);
+
+CREATE UNIQUE INDEX unique_reception_barcode ON products (reception_id, barcode);
+CREATE INDEX idx_products_barcode ON products (barcode);
@@ -0,0 +1,10 @@
+ALTER TABLE products
+    ADD COLUMN status VARCHAR(50) NOT NULL DEFAULT 'received'
+    CHECK (status IN ('received', 'stored', 'issued', 'returned'));
+
+UPDATE products pr
+SET status = 'stored'
+FROM receptions r
+WHERE r.id = pr.reception_id AND r.status = 'close';
+
+CREATE INDEX idx_products_status ON products (status);
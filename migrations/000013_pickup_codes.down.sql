@@ -0,0 +1,4 @@
+ALTER TABLE products
+    DROP COLUMN IF EXISTS pickup_locked_until,
+    DROP COLUMN IF EXISTS pickup_attempts,
+    DROP COLUMN IF EXISTS pickup_code_hash;
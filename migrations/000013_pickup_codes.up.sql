@@ -0,0 +1,4 @@
+ALTER TABLE products
+    ADD COLUMN pickup_code_hash TEXT,
+    ADD COLUMN pickup_attempts INT NOT NULL DEFAULT 0,
+    ADD COLUMN pickup_locked_until TIMESTAMP WITH TIME ZONE;
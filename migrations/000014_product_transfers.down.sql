@@ -0,0 +1,8 @@
+DROP TABLE IF EXISTS product_transfers;
+
+UPDATE products SET status = 'returned' WHERE status = 'transferred';
+
+ALTER TABLE products DROP CONSTRAINT products_status_check;
+
+ALTER TABLE products
+    ADD CONSTRAINT products_status_check CHECK (status IN ('received', 'stored', 'issued', 'returned'));
@@ -0,0 +1,21 @@
+ALTER TABLE products DROP CONSTRAINT products_status_check;
+
+ALTER TABLE products
+    ADD CONSTRAINT products_status_check CHECK (status IN ('received', 'stored', 'issued', 'returned', 'transferred'));
+
+CREATE TABLE product_transfers (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    product_id UUID REFERENCES products(id) ON DELETE CASCADE NOT NULL,
+    from_pvz_id UUID REFERENCES pvzs(id) NOT NULL,
+    to_pvz_id UUID REFERENCES pvzs(id) NOT NULL,
+    reception_id UUID REFERENCES receptions(id) ON DELETE SET NULL,
+    delivered_product_id UUID REFERENCES products(id) ON DELETE SET NULL,
+    created_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP NOT NULL,
+    CHECK (from_pvz_id <> to_pvz_id)
+);
+
+CREATE UNIQUE INDEX unique_transfer_product ON product_transfers (product_id);
+CREATE UNIQUE INDEX unique_transfer_delivered_product ON product_transfers (delivered_product_id);
+CREATE INDEX idx_product_transfers_from_pvz_id ON product_transfers (from_pvz_id);
+CREATE INDEX idx_product_transfers_to_pvz_id ON product_transfers (to_pvz_id);
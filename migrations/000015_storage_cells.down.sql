@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS idx_products_cell_id;
+
+ALTER TABLE products DROP COLUMN IF EXISTS cell_id;
+
+DROP TABLE IF EXISTS storage_cells;
@@ -0,0 +1,14 @@
+CREATE TABLE storage_cells (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    pvz_id UUID REFERENCES pvzs(id) ON DELETE CASCADE NOT NULL,
+    number INTEGER CHECK (number > 0) NOT NULL,
+    capacity INTEGER CHECK (capacity > 0) NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP NOT NULL
+);
+
+CREATE UNIQUE INDEX unique_pvz_cell_number ON storage_cells (pvz_id, number);
+
+ALTER TABLE products
+    ADD COLUMN cell_id UUID REFERENCES storage_cells(id) ON DELETE SET NULL;
+
+CREATE INDEX idx_products_cell_id ON products (cell_id);
@@ -0,0 +1,3 @@
+ALTER TABLE pvzs
+    DROP COLUMN IF EXISTS reception_limit,
+    DROP COLUMN IF EXISTS capacity;
@@ -0,0 +1,3 @@
+ALTER TABLE pvzs
+    ADD COLUMN capacity INTEGER CHECK (capacity > 0),
+    ADD COLUMN reception_limit INTEGER CHECK (reception_limit > 0);
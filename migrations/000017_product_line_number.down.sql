@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS unique_reception_line_number;
+
+ALTER TABLE products DROP COLUMN IF EXISTS line_number;
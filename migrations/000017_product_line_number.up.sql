@@ -0,0 +1,13 @@
+ALTER TABLE products ADD COLUMN line_number INTEGER CHECK (line_number > 0);
+
+UPDATE products pr
+SET line_number = numbered.line_number
+FROM (
+    SELECT id, ROW_NUMBER() OVER (PARTITION BY reception_id ORDER BY date_time, id) AS line_number
+    FROM products
+) numbered
+WHERE numbered.id = pr.id;
+
+ALTER TABLE products ALTER COLUMN line_number SET NOT NULL;
+
+CREATE UNIQUE INDEX unique_reception_line_number ON products (reception_id, line_number);
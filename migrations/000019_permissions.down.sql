@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS user_permissions;
+
+ALTER TABLE users DROP CONSTRAINT users_role_check;
+ALTER TABLE users ADD CONSTRAINT users_role_check CHECK (role IN ('employee', 'moderator'));
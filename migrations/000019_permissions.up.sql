@@ -0,0 +1,10 @@
+ALTER TABLE users DROP CONSTRAINT users_role_check;
+ALTER TABLE users ADD CONSTRAINT users_role_check CHECK (role IN ('employee', 'moderator', 'admin'));
+
+CREATE TABLE user_permissions (
+    user_id UUID REFERENCES users(id) ON DELETE CASCADE NOT NULL,
+    permission VARCHAR(50) NOT NULL,
+    granted_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    granted_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP NOT NULL,
+    PRIMARY KEY (user_id, permission)
+);
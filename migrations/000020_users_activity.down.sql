@@ -0,0 +1,7 @@
+DROP INDEX IF EXISTS idx_users_deactivated_at;
+DROP INDEX IF EXISTS idx_users_created_at;
+
+ALTER TABLE users
+    DROP COLUMN IF EXISTS deactivated_at,
+    DROP COLUMN IF EXISTS last_login_at,
+    DROP COLUMN IF EXISTS created_at;
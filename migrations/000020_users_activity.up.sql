@@ -0,0 +1,7 @@
+ALTER TABLE users
+    ADD COLUMN created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP NOT NULL,
+    ADD COLUMN last_login_at TIMESTAMP WITH TIME ZONE,
+    ADD COLUMN deactivated_at TIMESTAMP WITH TIME ZONE;
+
+CREATE INDEX idx_users_created_at ON users (created_at);
+CREATE INDEX idx_users_deactivated_at ON users (deactivated_at) WHERE deactivated_at IS NOT NULL;
@@ -0,0 +1 @@
+ALTER TABLE receptions DROP COLUMN IF EXISTS last_line_number;
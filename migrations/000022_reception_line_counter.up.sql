@@ -0,0 +1,10 @@
+ALTER TABLE receptions ADD COLUMN last_line_number INTEGER DEFAULT 0 NOT NULL;
+
+UPDATE receptions r
+SET last_line_number = numbered.last_line_number
+FROM (
+    SELECT reception_id, MAX(line_number) AS last_line_number
+    FROM products
+    GROUP BY reception_id
+) numbered
+WHERE numbered.reception_id = r.id;
@@ -0,0 +1,15 @@
+CREATE TABLE reception_manifest_barcodes (
+    reception_id UUID REFERENCES receptions(id) ON DELETE CASCADE NOT NULL,
+    barcode VARCHAR(64) NOT NULL,
+    type VARCHAR(50) REFERENCES product_types(name) NOT NULL,
+    PRIMARY KEY (reception_id, barcode)
+);
+
+CREATE TABLE reception_barcode_discrepancies (
+    reception_id UUID REFERENCES receptions(id) ON DELETE CASCADE NOT NULL,
+    barcode VARCHAR(64) NOT NULL,
+    kind VARCHAR(20) CHECK (kind IN ('missing', 'extra', 'mismatched_type')) NOT NULL,
+    expected_type VARCHAR(50) REFERENCES product_types(name),
+    actual_type VARCHAR(50) REFERENCES product_types(name),
+    PRIMARY KEY (reception_id, barcode)
+);
@@ -0,0 +1 @@
+ALTER TABLE receptions ADD COLUMN last_activity_at TIMESTAMP WITH TIME ZONE;
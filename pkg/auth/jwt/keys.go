@@ -0,0 +1,174 @@
+package jwt
+
+import (
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// Minimal accepted RSA key size in bits
+const minRSAKeyBits = 2048
+
+// Signing or verification key identified by kid
+type Key struct {
+	ID      string
+	Method  jwt.SigningMethod
+	Private any
+	Public  any
+}
+
+// Set of keys used to sign and verify tokens
+type KeySet struct {
+	secret       []byte
+	hmacFallback bool
+	signing      *Key
+	keys         map[string]Key
+}
+
+// Public key in the JWK format
+type JWK struct {
+	Kty string
+	Kid string
+	Alg string
+	Crv string
+	N   string
+	E   string
+	X   string
+}
+
+// Load the key set, every <kid>.pem file in dir is a verification key and the signingKeyID one
+// must hold a private key, tokens are signed with the HMAC secret when no signing key is set.
+// With a signing key, tokens without a kid are verified with the secret only if hmacFallback is on.
+func LoadKeySet(secret, dir, signingKeyID string, hmacFallback bool) (*KeySet, error) {
+	ks := &KeySet{
+		secret: []byte(secret),
+		keys:   map[string]Key{},
+	}
+
+	if dir != "" {
+		files, err := filepath.Glob(filepath.Join(dir, "*.pem"))
+		if err != nil {
+			return nil, err
+		}
+
+		for _, file := range files {
+			kid := strings.TrimSuffix(filepath.Base(file), ".pem")
+
+			key, err := loadKey(kid, file)
+			if err != nil {
+				return nil, fmt.Errorf("key %q: %w", kid, err)
+			}
+			ks.keys[kid] = key
+		}
+	}
+
+	if signingKeyID != "" {
+		key, ok := ks.keys[signingKeyID]
+		if !ok {
+			return nil, fmt.Errorf("signing key %q not found", signingKeyID)
+		}
+		if key.Private == nil {
+			return nil, fmt.Errorf("signing key %q has no private key", signingKeyID)
+		}
+		ks.signing = &key
+	} else if len(ks.secret) == 0 {
+		return nil, errors.New("neither a signing key nor a secret is set")
+	}
+
+	if hmacFallback && len(ks.secret) == 0 {
+		return nil, errors.New("hmac fallback requires a secret")
+	}
+	ks.hmacFallback = ks.signing == nil || hmacFallback
+
+	return ks, nil
+}
+
+// Get the public keys in the JWK format ordered by kid
+func (ks *KeySet) JWKS() []JWK {
+	jwks := make([]JWK, 0, len(ks.keys))
+	for _, key := range ks.keys {
+		jwk := JWK{
+			Kid: key.ID,
+			Alg: key.Method.Alg(),
+		}
+
+		switch pub := key.Public.(type) {
+		case *rsa.PublicKey:
+			jwk.Kty = "RSA"
+			jwk.N = base64.RawURLEncoding.EncodeToString(pub.N.Bytes())
+			jwk.E = base64.RawURLEncoding.EncodeToString(big.NewInt(int64(pub.E)).Bytes())
+		case ed25519.PublicKey:
+			jwk.Kty = "OKP"
+			jwk.Crv = "Ed25519"
+			jwk.X = base64.RawURLEncoding.EncodeToString(pub)
+		}
+
+		jwks = append(jwks, jwk)
+	}
+
+	sort.Slice(jwks, func(i, j int) bool {
+		return jwks[i].Kid < jwks[j].Kid
+	})
+
+	return jwks
+}
+
+// Load a PEM encoded RSA or Ed25519 key, private keys also provide the public key
+func loadKey(kid, file string) (Key, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return Key{}, err
+	}
+
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return Key{}, errors.New("no PEM data found")
+	}
+
+	var parsed any
+	switch block.Type {
+	case "PRIVATE KEY":
+		parsed, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	case "RSA PRIVATE KEY":
+		parsed, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	case "PUBLIC KEY":
+		parsed, err = x509.ParsePKIXPublicKey(block.Bytes)
+	default:
+		return Key{}, fmt.Errorf("unsupported PEM block %q", block.Type)
+	}
+	if err != nil {
+		return Key{}, err
+	}
+
+	key := Key{ID: kid}
+
+	switch k := parsed.(type) {
+	case *rsa.PrivateKey:
+		key.Method, key.Private, key.Public = jwt.SigningMethodRS256, k, &k.PublicKey
+	case *rsa.PublicKey:
+		key.Method, key.Public = jwt.SigningMethodRS256, k
+	case ed25519.PrivateKey:
+		key.Method, key.Private, key.Public = jwt.SigningMethodEdDSA, k, k.Public()
+	case ed25519.PublicKey:
+		key.Method, key.Public = jwt.SigningMethodEdDSA, k
+	default:
+		return Key{}, fmt.Errorf("unsupported key type %T", parsed)
+	}
+
+	if pub, ok := key.Public.(*rsa.PublicKey); ok && pub.N.BitLen() < minRSAKeyBits {
+		return Key{}, fmt.Errorf("RSA key must be at least %d bits", minRSAKeyBits)
+	}
+
+	return key, nil
+}
@@ -3,36 +3,90 @@ package jwt
 import (
 	"errors"
 	"fmt"
+	"time"
 
 	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
 
 	"github.com/cyansnbrst/pvz-service/gen/pvzapi"
 	"github.com/cyansnbrst/pvz-service/pkg/auth"
 )
 
-// Parse JWT token (HMAC)
-func ParseJWT(tokenString, secret string) (pvzapi.UserRole, error) {
+// Token claims struct
+type Claims struct {
+	ID        uuid.UUID
+	UserID    uuid.UUID
+	Role      pvzapi.UserRole
+	ExpiresAt time.Time
+}
+
+// Parse JWT token, tokens with a kid are verified with that key of the set and tokens
+// without one with the HMAC secret, unless the set signs with a key and the fallback is off
+func ParseJWT(tokenString string, keys *KeySet) (Claims, error) {
 	token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+		kid, ok := token.Header["kid"].(string)
+		if !ok {
+			if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok || !keys.hmacFallback {
+				return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+			}
+			return keys.secret, nil
+		}
+
+		key, ok := keys.keys[kid]
+		if !ok {
+			return nil, fmt.Errorf("unknown key id: %s", kid)
+		}
+		if token.Method.Alg() != key.Method.Alg() {
 			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
 		}
-		return []byte(secret), nil
+		return key.Public, nil
 	})
 
 	if err != nil {
-		if errors.Is(err, jwt.ErrTokenMalformed) || errors.Is(err, jwt.ErrTokenExpired) || errors.Is(err, jwt.ErrSignatureInvalid) {
-			return "", auth.ErrInvalidToken
+		if errors.Is(err, jwt.ErrTokenMalformed) || errors.Is(err, jwt.ErrTokenExpired) ||
+			errors.Is(err, jwt.ErrTokenSignatureInvalid) || errors.Is(err, jwt.ErrTokenUnverifiable) ||
+			errors.Is(err, jwt.ErrTokenNotValidYet) || errors.Is(err, jwt.ErrSignatureInvalid) {
+			return Claims{}, auth.ErrInvalidToken
 		}
-		return "", err
+		return Claims{}, err
 	}
 
 	if claims, ok := token.Claims.(jwt.MapClaims); ok && token.Valid {
 		role, ok := claims["role"].(string)
 		if !ok {
-			return "", auth.ErrInvalidToken
+			return Claims{}, auth.ErrInvalidToken
 		}
-		return pvzapi.UserRole(role), nil
+
+		// Tokens issued by /dummyLogin carry no subject
+		userID := uuid.Nil
+		if sub, ok := claims["sub"].(string); ok {
+			userID, err = uuid.Parse(sub)
+			if err != nil {
+				return Claims{}, auth.ErrInvalidToken
+			}
+		}
+
+		// Tokens issued before revocation was introduced carry no id
+		tokenID := uuid.Nil
+		if jti, ok := claims["jti"].(string); ok {
+			tokenID, err = uuid.Parse(jti)
+			if err != nil {
+				return Claims{}, auth.ErrInvalidToken
+			}
+		}
+
+		var expiresAt time.Time
+		if exp, err := claims.GetExpirationTime(); err == nil && exp != nil {
+			expiresAt = exp.Time
+		}
+
+		return Claims{
+			ID:        tokenID,
+			UserID:    userID,
+			Role:      pvzapi.UserRole(role),
+			ExpiresAt: expiresAt,
+		}, nil
 	}
 
-	return "", auth.ErrInvalidToken
+	return Claims{}, auth.ErrInvalidToken
 }
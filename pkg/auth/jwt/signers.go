@@ -0,0 +1,17 @@
+package jwt
+
+import (
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// Sign JWT token with the signing key of the set (kid header) or the HMAC secret
+func SignJWT(claims jwt.MapClaims, keys *KeySet) (string, error) {
+	if keys.signing == nil {
+		return jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(keys.secret)
+	}
+
+	token := jwt.NewWithClaims(keys.signing.Method, claims)
+	token.Header["kid"] = keys.signing.ID
+
+	return token.SignedString(keys.signing.Private)
+}
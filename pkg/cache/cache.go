@@ -0,0 +1,71 @@
+package cache
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Loader fetches a fresh value for the cache
+type Loader[T any] func(ctx context.Context) (T, error)
+
+// In-process cache of a single lazily loaded value
+type Value[T any] struct {
+	mu       sync.RWMutex
+	ttl      time.Duration
+	load     Loader[T]
+	value    T
+	loadedAt time.Time
+	valid    bool
+}
+
+// Cached value constructor, non-positive ttl disables caching
+func NewValue[T any](ttl time.Duration, load Loader[T]) *Value[T] {
+	return &Value[T]{
+		ttl:  ttl,
+		load: load,
+	}
+}
+
+// Get the cached value, loading it if it is missing or expired
+func (c *Value[T]) Get(ctx context.Context) (T, error) {
+	c.mu.RLock()
+	if c.fresh() {
+		value := c.value
+		c.mu.RUnlock()
+		return value, nil
+	}
+	c.mu.RUnlock()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.fresh() {
+		return c.value, nil
+	}
+
+	value, err := c.load(ctx)
+	if err != nil {
+		var zero T
+		return zero, err
+	}
+
+	c.value = value
+	c.loadedAt = time.Now()
+	c.valid = true
+
+	return value, nil
+}
+
+// Drop the cached value so the next Get reloads it
+func (c *Value[T]) Invalidate() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.valid = false
+}
+
+// Check whether the cached value can be served (must be called under lock)
+func (c *Value[T]) fresh() bool {
+	return c.valid && time.Since(c.loadedAt) < c.ttl
+}
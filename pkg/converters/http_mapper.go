@@ -6,43 +6,323 @@ import (
 	"github.com/cyansnbrst/pvz-service/gen/pvzapi"
 	"github.com/cyansnbrst/pvz-service/internal/dtos"
 	"github.com/cyansnbrst/pvz-service/internal/models"
+	"github.com/cyansnbrst/pvz-service/pkg/auth/jwt"
 )
 
 // User model to user response
 func ToResponseUser(m models.User) pvzapi.User {
-	return pvzapi.User{
-		Email: openapi_types.Email(m.Email),
-		Id:    &m.ID,
-		Role:  pvzapi.UserRole(m.Role),
+	resp := pvzapi.User{
+		Email:         openapi_types.Email(m.Email),
+		Id:            &m.ID,
+		Role:          pvzapi.UserRole(m.Role),
+		LastLoginAt:   m.LastLoginAt,
+		DeactivatedAt: m.DeactivatedAt,
 	}
+	if !m.CreatedAt.IsZero() {
+		resp.CreatedAt = &m.CreatedAt
+	}
+
+	return resp
+}
+
+// User models to user responses
+func ToResponseUsers(m []models.User) []pvzapi.User {
+	resp := make([]pvzapi.User, len(m))
+	for i, u := range m {
+		resp[i] = ToResponseUser(u)
+	}
+
+	return resp
 }
 
 // PVZ model to PVZ response
 func ToResponsePVZ(m models.PVZ) pvzapi.PVZ {
+	status := pvzapi.PVZStatus(m.Status)
 	return pvzapi.PVZ{
 		Id:               &m.ID,
-		City:             pvzapi.PVZCity(m.City),
+		City:             m.City,
 		RegistrationDate: &m.RegistrationDate,
+		Status:           &status,
+	}
+}
+
+// City model to city response
+func ToResponseCity(m models.City) pvzapi.City {
+	return pvzapi.City{
+		Id:        &m.ID,
+		Name:      m.Name,
+		CreatedAt: &m.CreatedAt,
+	}
+}
+
+// Product type model to product type response
+func ToResponseProductType(m models.ProductType) pvzapi.ProductType {
+	return pvzapi.ProductType{
+		Id:          &m.ID,
+		Name:        m.Name,
+		DisplayName: m.DisplayName,
+		RetiredAt:   m.RetiredAt,
+	}
+}
+
+// Employee assignment model to employee assignment response
+func ToResponseEmployeeAssignment(m models.EmployeeAssignment) pvzapi.EmployeeAssignment {
+	return pvzapi.EmployeeAssignment{
+		PvzId:      m.PvzID,
+		UserId:     m.UserID,
+		AssignedAt: &m.AssignedAt,
 	}
 }
 
 // Reception model to reception response
 func ToResponseReception(m models.Reception) pvzapi.Reception {
-	return pvzapi.Reception{
-		Id:       &m.ID,
-		DateTime: m.DateTime,
-		PvzId:    m.PvzID,
-		Status:   pvzapi.ReceptionStatus(m.Status),
+	resp := pvzapi.Reception{
+		Id:         &m.ID,
+		DateTime:   m.DateTime,
+		PvzId:      m.PvzID,
+		Status:     pvzapi.ReceptionStatus(m.Status),
+		CreatedBy:  m.CreatedBy,
+		ClosedBy:   m.ClosedBy,
+		AutoClosed: &m.AutoClosed,
+	}
+
+	if m.Manifest != nil {
+		manifest := make([]pvzapi.ManifestItem, len(m.Manifest))
+		for i, item := range m.Manifest {
+			manifest[i] = pvzapi.ManifestItem{Type: item.Type, Count: item.Count}
+		}
+		resp.Manifest = &manifest
+	}
+
+	if m.Discrepancies != nil {
+		discrepancies := make([]pvzapi.Discrepancy, len(m.Discrepancies))
+		for i, d := range m.Discrepancies {
+			discrepancies[i] = pvzapi.Discrepancy{
+				Type:     d.Type,
+				Kind:     pvzapi.DiscrepancyKind(d.Kind),
+				Expected: d.Expected,
+				Actual:   d.Actual,
+			}
+		}
+		resp.Discrepancies = &discrepancies
+	}
+
+	if m.ExpectedBarcodes != nil {
+		barcodes := make([]pvzapi.ManifestBarcode, len(m.ExpectedBarcodes))
+		for i, item := range m.ExpectedBarcodes {
+			barcodes[i] = pvzapi.ManifestBarcode{Barcode: item.Barcode, Type: item.Type}
+		}
+		resp.ExpectedBarcodes = &barcodes
+	}
+
+	if m.BarcodeDiscrepancies != nil {
+		discrepancies := make([]pvzapi.BarcodeDiscrepancy, len(m.BarcodeDiscrepancies))
+		for i, d := range m.BarcodeDiscrepancies {
+			discrepancies[i] = pvzapi.BarcodeDiscrepancy{
+				Barcode:      d.Barcode,
+				Kind:         pvzapi.DiscrepancyKind(d.Kind),
+				ExpectedType: d.ExpectedType,
+				ActualType:   d.ActualType,
+			}
+		}
+		resp.BarcodeDiscrepancies = &discrepancies
+	}
+
+	return resp
+}
+
+// Reception audit record model to audit record response
+func ToResponseReceptionAuditRecord(m models.ReceptionAuditRecord) pvzapi.ReceptionAuditRecord {
+	return pvzapi.ReceptionAuditRecord{
+		Id:          &m.ID,
+		ReceptionId: m.ReceptionID,
+		Action:      pvzapi.ReceptionAuditRecordAction(m.Action),
+		Reason:      m.Reason,
+		UserId:      m.UserID,
+		CreatedAt:   &m.CreatedAt,
+	}
+}
+
+// Manifest request to manifest model
+func ToManifest(items *[]pvzapi.ManifestItem) []models.ManifestItem {
+	if items == nil {
+		return nil
+	}
+
+	manifest := make([]models.ManifestItem, len(*items))
+	for i, item := range *items {
+		manifest[i] = models.ManifestItem{Type: item.Type, Count: item.Count}
 	}
+
+	return manifest
+}
+
+// Barcode manifest request to barcode manifest model
+func ToManifestBarcodes(items *[]pvzapi.ManifestBarcode) []models.ManifestBarcode {
+	if items == nil {
+		return nil
+	}
+
+	barcodes := make([]models.ManifestBarcode, len(*items))
+	for i, item := range *items {
+		barcodes[i] = models.ManifestBarcode{Barcode: item.Barcode, Type: item.Type}
+	}
+
+	return barcodes
 }
 
 // Product model to product response
 func ToResponseProduct(m models.Product) pvzapi.Product {
+	status := pvzapi.ProductStatus(m.Status)
+
 	return pvzapi.Product{
 		Id:          &m.ID,
 		DateTime:    &m.DateTime,
 		ReceptionId: m.ReceptionID,
-		Type:        pvzapi.ProductType(m.Type),
+		LineNumber:  &m.LineNumber,
+		Type:        m.Type,
+		CreatedBy:   m.CreatedBy,
+		Barcode:     m.Barcode,
+		Status:      &status,
+		CellNumber:  m.CellNumber,
+	}
+}
+
+// Batch items request to product models
+func ToBatchProducts(items []pvzapi.ProductBatchItem) []models.Product {
+	products := make([]models.Product, len(items))
+	for i, item := range items {
+		products[i] = models.Product{Type: item.Type, Barcode: item.Barcode, CellNumber: item.CellNumber}
+	}
+
+	return products
+}
+
+// Batch result model to batch result response
+func ToResponseProductBatchResult(m models.ProductBatchResult) pvzapi.ProductBatchResult {
+	var resp pvzapi.ProductBatchResult
+
+	if m.Err != nil {
+		msg := m.Err.Error()
+		resp.Error = &msg
+	}
+
+	if m.Product != nil {
+		product := ToResponseProduct(*m.Product)
+		resp.Product = &product
+	}
+
+	return resp
+}
+
+// Product location model to product location response
+func ToResponseProductLocation(m models.ProductLocation) pvzapi.ProductLocation {
+	return pvzapi.ProductLocation{
+		Product:   ToResponseProduct(m.Product),
+		Reception: ToResponseReception(m.Reception),
+		Pvz:       ToResponsePVZ(m.PVZ),
+	}
+}
+
+// Product transfer model to product transfer response
+func ToResponseProductTransfer(m models.ProductTransfer) pvzapi.ProductTransfer {
+	return pvzapi.ProductTransfer{
+		Id:                 &m.ID,
+		ProductId:          m.ProductID,
+		Type:               m.Type,
+		Barcode:            m.Barcode,
+		FromPvzId:          m.FromPvzID,
+		ToPvzId:            m.ToPvzID,
+		Status:             pvzapi.ProductTransferStatus(m.Status),
+		ReceptionId:        m.ReceptionID,
+		DeliveredProductId: m.DeliveredProductID,
+		CreatedBy:          m.CreatedBy,
+		CreatedAt:          &m.CreatedAt,
+	}
+}
+
+// Product transfer models to product transfer responses
+func ToResponseProductTransfers(m []models.ProductTransfer) []pvzapi.ProductTransfer {
+	resp := make([]pvzapi.ProductTransfer, len(m))
+	for i, t := range m {
+		resp[i] = ToResponseProductTransfer(t)
+	}
+
+	return resp
+}
+
+// PVZ capacity model to PVZ capacity response
+func ToResponsePVZCapacity(m models.PVZCapacity) pvzapi.PVZCapacity {
+	return pvzapi.PVZCapacity{
+		PvzId:          m.PvzID,
+		Capacity:       m.Capacity,
+		ReceptionLimit: m.ReceptionLimit,
+		Stored:         m.Stored,
+	}
+}
+
+// Storage cell model to storage cell response
+func ToResponseStorageCell(m models.StorageCell) pvzapi.StorageCell {
+	return pvzapi.StorageCell{
+		Id:        &m.ID,
+		PvzId:     m.PvzID,
+		Number:    m.Number,
+		Capacity:  m.Capacity,
+		Occupied:  m.Occupied,
+		CreatedAt: &m.CreatedAt,
+	}
+}
+
+// Storage cell models to storage cell responses
+func ToResponseStorageCells(m []models.StorageCell) []pvzapi.StorageCell {
+	resp := make([]pvzapi.StorageCell, len(m))
+	for i, c := range m {
+		resp[i] = ToResponseStorageCell(c)
+	}
+
+	return resp
+}
+
+// Cell occupancy model to cell occupancy response
+func ToResponseCellOccupancy(m models.CellOccupancy) pvzapi.CellOccupancy {
+	return pvzapi.CellOccupancy{
+		Cells:      ToResponseStorageCells(m.Cells),
+		Capacity:   m.Capacity,
+		Occupied:   m.Occupied,
+		Unassigned: m.Unassigned,
+	}
+}
+
+// PVZ details model to PVZ details response
+func ToResponsePVZDetails(m *models.PVZDetails) dtos.PVZDetails {
+	resp := dtos.PVZDetails{
+		PVZ:               ToResponsePVZ(m.PVZ),
+		ReceptionsCount:   m.ReceptionsCount,
+		ProductsCount:     m.ProductsCount,
+		InboundTransfers:  ToResponseProductTransfers(m.InboundTransfers),
+		OutboundTransfers: ToResponseProductTransfers(m.OutboundTransfers),
+	}
+
+	if m.OpenReception != nil {
+		resp.OpenReception = &dtos.OpenReception{
+			Reception:     ToResponseReception(*m.OpenReception),
+			ProductsCount: m.OpenReceptionProductsCount,
+		}
+	}
+
+	return resp
+}
+
+// Reception with products model to reception with products response
+func ToResponseReceptionWithProducts(m *models.ReceptionWithProducts) dtos.ReceptionWithProducts {
+	products := make([]pvzapi.Product, len(m.Products))
+	for i, p := range m.Products {
+		products[i] = ToResponseProduct(*p)
+	}
+
+	return dtos.ReceptionWithProducts{
+		Reception: ToResponseReception(m.Reception),
+		Products:  products,
 	}
 }
 
@@ -52,21 +332,86 @@ func ToResponsePVZWithReceptions(m *models.PVZWithReceptions) dtos.PVZWithRecept
 
 	receptions := make([]dtos.ReceptionWithProducts, len(m.Receptions))
 	for i, r := range m.Receptions {
-		reception := ToResponseReception(r.Reception)
+		receptions[i] = ToResponseReceptionWithProducts(r)
+	}
 
-		products := make([]pvzapi.Product, len(r.Products))
-		for j, p := range r.Products {
-			products[j] = ToResponseProduct(*p)
-		}
+	return dtos.PVZWithReceptions{
+		PVZ:        pvz,
+		Receptions: receptions,
+	}
+}
 
-		receptions[i] = dtos.ReceptionWithProducts{
-			Reception: reception,
-			Products:  products,
+// PVZ with receptions model to PVZ with reception product counts response
+func ToResponsePVZWithReceptionCounts(m *models.PVZWithReceptions) dtos.PVZWithReceptionCounts {
+	receptions := make([]dtos.ReceptionWithCount, len(m.Receptions))
+	for i, r := range m.Receptions {
+		receptions[i] = dtos.ReceptionWithCount{
+			Reception:     ToResponseReception(r.Reception),
+			ProductsCount: r.ProductsCount,
 		}
 	}
 
-	return dtos.PVZWithReceptions{
-		PVZ:        pvz,
+	return dtos.PVZWithReceptionCounts{
+		PVZ:        ToResponsePVZ(m.PVZ),
 		Receptions: receptions,
 	}
 }
+
+// Product page model to product page response
+func ToResponseProductPage(m models.ProductPage) pvzapi.ProductPage {
+	products := make([]pvzapi.Product, len(m.Products))
+	for i, p := range m.Products {
+		products[i] = ToResponseProduct(p)
+	}
+
+	return pvzapi.ProductPage{
+		Products:   products,
+		NextCursor: m.NextCursor,
+	}
+}
+
+// Public keys to JWKS response, key parameters that do not apply to the key type are omitted
+func ToResponseJWKS(keys []jwt.JWK) pvzapi.JWKS {
+	optional := func(v string) *string {
+		if v == "" {
+			return nil
+		}
+		return &v
+	}
+
+	jwks := make([]pvzapi.JWK, len(keys))
+	for i, k := range keys {
+		jwks[i] = pvzapi.JWK{
+			Kty: k.Kty,
+			Kid: k.Kid,
+			Alg: k.Alg,
+			Use: "sig",
+			N:   optional(k.N),
+			E:   optional(k.E),
+			Crv: optional(k.Crv),
+			X:   optional(k.X),
+		}
+	}
+
+	return pvzapi.JWKS{Keys: jwks}
+}
+
+// Permission grant model to permission grant response
+func ToResponsePermissionGrant(m models.PermissionGrant) pvzapi.PermissionGrant {
+	return pvzapi.PermissionGrant{
+		UserId:     m.UserID,
+		Permission: string(m.Permission),
+		GrantedBy:  m.GrantedBy,
+		GrantedAt:  m.GrantedAt,
+	}
+}
+
+// Permission grant models to permission grant responses
+func ToResponsePermissionGrants(m []models.PermissionGrant) []pvzapi.PermissionGrant {
+	resp := make([]pvzapi.PermissionGrant, len(m))
+	for i, g := range m {
+		resp[i] = ToResponsePermissionGrant(g)
+	}
+
+	return resp
+}
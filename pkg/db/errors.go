@@ -1,12 +1,62 @@
 package db
 
-import "errors"
+import (
+	"errors"
+
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// Postgres error codes
+const (
+	pgUniqueViolation     = "23505"
+	pgForeignKeyViolation = "23503"
+)
 
 var (
-	ErrUserNotFound      = errors.New("user not found")
-	ErrDuplicateEmail    = errors.New("duplicate email")
-	ErrDuplicatePVZ      = errors.New("duplicate pvz")
-	ErrReceptionConflict = errors.New("either pvz not found or previous reception still open")
-	ErrNoOpenReception   = errors.New("no opened reception for the pvz was found")
-	ErrNoProducts        = errors.New("no products in the reception")
+	ErrUserNotFound         = errors.New("user not found")
+	ErrDuplicateEmail       = errors.New("duplicate email")
+	ErrDuplicatePVZ         = errors.New("duplicate pvz")
+	ErrReceptionConflict    = errors.New("either pvz not found or previous reception still open")
+	ErrNoOpenReception      = errors.New("no opened reception for the pvz was found")
+	ErrReceptionNotFound    = errors.New("reception not found")
+	ErrReceptionNotClosed   = errors.New("reception is not closed")
+	ErrNoProducts           = errors.New("no products in the reception")
+	ErrProductNotFound      = errors.New("product not found in the reception")
+	ErrReceptionNotOpen     = errors.New("reception is not open")
+	ErrProductsLeftPVZ      = errors.New("reception products have already been issued or returned")
+	ErrInvalidTransition    = errors.New("product status does not allow this operation")
+	ErrNoPickupCode         = errors.New("pickup code was not generated for the product")
+	ErrNoPickupAttemptsLeft = errors.New("no pickup code attempts left for the product")
+	ErrPVZNotFound          = errors.New("pvz not found")
+	ErrPVZNotActive         = errors.New("pvz is suspended or closed")
+	ErrCityNotFound         = errors.New("city not found")
+	ErrDuplicateCity        = errors.New("duplicate city")
+	ErrCityInUse            = errors.New("city has pvzs")
+	ErrTypeNotFound         = errors.New("product type not found")
+	ErrDuplicateBarcode     = errors.New("product with this barcode is already in the reception")
+	ErrDuplicateType        = errors.New("duplicate product type")
+	ErrNotEmployee          = errors.New("user is not an employee")
+	ErrAlreadyAssigned      = errors.New("employee is already assigned to the pvz")
+	ErrNotAssigned          = errors.New("employee is not assigned to the pvz")
+	ErrCellNotFound         = errors.New("storage cell not found in the pvz")
+	ErrCellFull             = errors.New("storage cell is full")
+	ErrCapacityExceeded     = errors.New("pvz has no room for more products")
+	ErrReceptionLimit       = errors.New("reception product limit reached")
+	ErrRefreshTokenNotFound = errors.New("refresh token not found")
+	ErrRefreshTokenRevoked  = errors.New("refresh token has already been used or revoked")
+	ErrPermissionNotGranted = errors.New("permission is not granted to the user")
+	ErrNoLoginAttemptsLeft  = errors.New("no login attempts left")
+	ErrNoBarcode            = errors.New("product has no barcode and cannot be transferred")
 )
+
+// Check if the error is a unique constraint violation
+func IsUniqueViolation(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == pgUniqueViolation
+}
+
+// Check if the error is a foreign key violation
+func IsForeignKeyViolation(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == pgForeignKeyViolation
+}
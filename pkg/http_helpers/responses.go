@@ -12,6 +12,7 @@ import (
 const (
 	msgServerError  = "the server encountered a problem and could not process your request"
 	msgAccessDenied = "access denied"
+	msgNotFound     = "the requested resource could not be found"
 )
 
 // Log an error
@@ -39,7 +40,27 @@ func BadRequestResponse(c echo.Context, err error) error {
 	return errorResponse(c, http.StatusBadRequest, err.Error())
 }
 
+// Unauthorized response (401)
+func UnauthorizedResponse(c echo.Context, err error) error {
+	return errorResponse(c, http.StatusUnauthorized, err.Error())
+}
+
 // Access denied (403)
 func AccessDeniedResponse(c echo.Context) error {
 	return errorResponse(c, http.StatusForbidden, msgAccessDenied)
 }
+
+// Not found response (404)
+func NotFoundResponse(c echo.Context) error {
+	return errorResponse(c, http.StatusNotFound, msgNotFound)
+}
+
+// Conflict response (409)
+func ConflictResponse(c echo.Context, err error) error {
+	return errorResponse(c, http.StatusConflict, err.Error())
+}
+
+// Too many requests response (429)
+func TooManyRequestsResponse(c echo.Context, err error) error {
+	return errorResponse(c, http.StatusTooManyRequests, err.Error())
+}
@@ -17,6 +17,10 @@ type Metrics interface {
 	IncPVZCreated()
 	IncReceptionsCreated()
 	IncProductsAdded()
+	IncReceptionsAutoClosed()
+	IncProductsIssued(method string)
+	IncLoginFailures(reason string)
+	SetPVZUtilization(utilization map[string]float64)
 }
 
 // Prometheus metrics struct
@@ -27,6 +31,10 @@ type PrometheusMetrics struct {
 	PVZCreated        prometheus.Counter
 	ReceptionsCreated prometheus.Counter
 	ProductsAdded     prometheus.Counter
+	AutoClosed        prometheus.Counter
+	ProductsIssued    *prometheus.CounterVec
+	LoginFailures     *prometheus.CounterVec
+	PVZUtilization    *prometheus.GaugeVec
 }
 
 // Create metrics with address and name
@@ -87,6 +95,47 @@ func CreateMetrics(address, name string) (Metrics, error) {
 		return nil, err
 	}
 
+	metr.AutoClosed = prometheus.NewCounter(prometheus.CounterOpts{
+		Name: name + "_receptions_auto_closed_total",
+		Help: "Total number of stale receptions closed automatically",
+	})
+	if err := prometheus.Register(metr.AutoClosed); err != nil {
+		return nil, err
+	}
+
+	metr.ProductsIssued = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: name + "_products_issued_total",
+			Help: "Number of products issued to customers partitioned by issuance method",
+		},
+		[]string{"method"},
+	)
+	if err := prometheus.Register(metr.ProductsIssued); err != nil {
+		return nil, err
+	}
+
+	metr.LoginFailures = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: name + "_login_failures_total",
+			Help: "Number of failed logins partitioned by failure reason",
+		},
+		[]string{"reason"},
+	)
+	if err := prometheus.Register(metr.LoginFailures); err != nil {
+		return nil, err
+	}
+
+	metr.PVZUtilization = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: name + "_pvz_utilization_ratio",
+			Help: "Share of the pvz capacity taken by received and stored products partitioned by pvz",
+		},
+		[]string{"pvz_id"},
+	)
+	if err := prometheus.Register(metr.PVZUtilization); err != nil {
+		return nil, err
+	}
+
 	if err := prometheus.Register(collectors.NewBuildInfoCollector()); err != nil {
 		return nil, err
 	}
@@ -128,3 +177,26 @@ func (metr *PrometheusMetrics) IncReceptionsCreated() {
 func (metr *PrometheusMetrics) IncProductsAdded() {
 	metr.ProductsAdded.Inc()
 }
+
+// Inc receptions auto-closed
+func (metr *PrometheusMetrics) IncReceptionsAutoClosed() {
+	metr.AutoClosed.Inc()
+}
+
+// Inc products issued
+func (metr *PrometheusMetrics) IncProductsIssued(method string) {
+	metr.ProductsIssued.WithLabelValues(method).Inc()
+}
+
+// Inc failed logins
+func (metr *PrometheusMetrics) IncLoginFailures(reason string) {
+	metr.LoginFailures.WithLabelValues(reason).Inc()
+}
+
+// Set utilization of the pvzs with a capacity limit, dropping the others
+func (metr *PrometheusMetrics) SetPVZUtilization(utilization map[string]float64) {
+	metr.PVZUtilization.Reset()
+	for pvzID, ratio := range utilization {
+		metr.PVZUtilization.WithLabelValues(pvzID).Set(ratio)
+	}
+}
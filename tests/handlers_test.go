@@ -8,11 +8,13 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"net/url"
+	"sync"
 	"testing"
 	"time"
 
 	"github.com/alexedwards/argon2id"
 	"github.com/google/uuid"
+	openapi_types "github.com/oapi-codegen/runtime/types"
 	"github.com/stretchr/testify/suite"
 	"go.uber.org/zap"
 
@@ -56,6 +58,14 @@ func (s *HandlersTestSuite) TestPostDummyLogin() {
 		},
 		{
 			name: "invalid role",
+			payload: pvzapi.PostDummyLoginJSONRequestBody{
+				Role: "superuser",
+			},
+			expectedStatus: http.StatusBadRequest,
+			wantErr:        true,
+		},
+		{
+			name: "admin role is not issued",
 			payload: pvzapi.PostDummyLoginJSONRequestBody{
 				Role: "admin",
 			},
@@ -250,12 +260,12 @@ func (s *HandlersTestSuite) TestPostLogin() {
 			expectedStatus: http.StatusOK,
 		},
 		{
-			name: "invalid email",
+			name: "unknown email",
 			payload: pvzapi.PostLoginJSONRequestBody{
 				Email:    "adfasdfsa@test.com",
 				Password: testPassword,
 			},
-			expectedStatus: http.StatusBadRequest,
+			expectedStatus: http.StatusUnauthorized,
 			wantErr:        true,
 		},
 		{
@@ -264,7 +274,7 @@ func (s *HandlersTestSuite) TestPostLogin() {
 				Email:    "test@test.com",
 				Password: "wrongpassword",
 			},
-			expectedStatus: http.StatusBadRequest,
+			expectedStatus: http.StatusUnauthorized,
 			wantErr:        true,
 		},
 		{
@@ -441,7 +451,7 @@ func (s *HandlersTestSuite) TestPostPvz() {
 	}
 }
 
-func (s *HandlersTestSuite) TestPostReceptions() {
+func (s *HandlersTestSuite) TestPatchPvzPvzId() {
 	app := server.NewServer(s.cfg, zap.NewNop(), s.dbPool)
 	ts := httptest.NewServer(app.RegisterHandlers())
 	defer ts.Close()
@@ -456,78 +466,96 @@ func (s *HandlersTestSuite) TestPostReceptions() {
 	s.Require().NoError(err)
 
 	tests := []struct {
-		name           string
-		token          string
-		payload        any
-		prepareDB      func()
-		expectedStatus int
-		wantErr        bool
+		name                string
+		token               string
+		pvzID               uuid.UUID
+		payload             any
+		expectedStatus      int
+		expectedStatusValue pvzapi.PVZStatus
+		wantErr             bool
 	}{
 		{
-			name:  "successful reception creation",
-			token: employeeToken,
-			payload: pvzapi.PostReceptionsJSONRequestBody{
-				PvzId: pvzID,
+			name:  "successful suspension",
+			token: moderatorToken,
+			pvzID: pvzID,
+			payload: map[string]any{
+				"status": "suspended",
 			},
-			expectedStatus: http.StatusCreated,
+			expectedStatus:      http.StatusOK,
+			expectedStatusValue: pvzapi.Suspended,
 		},
 		{
-			name:  "access denied for other user",
+			name:  "successful city change",
 			token: moderatorToken,
-			payload: pvzapi.PostReceptionsJSONRequestBody{
-				PvzId: pvzID,
+			pvzID: pvzID,
+			payload: map[string]any{
+				"city":   "Казань",
+				"status": "active",
 			},
-			expectedStatus: http.StatusForbidden,
-			wantErr:        true,
+			expectedStatus:      http.StatusOK,
+			expectedStatusValue: pvzapi.Active,
 		},
 		{
-			name:  "missing id",
+			name:  "access denied for other user",
 			token: employeeToken,
-			payload: pvzapi.PostReceptionsJSONRequestBody{
-				PvzId: uuid.Nil,
+			pvzID: pvzID,
+			payload: map[string]any{
+				"status": "closed",
 			},
+			expectedStatus: http.StatusForbidden,
+			wantErr:        true,
+		},
+		{
+			name:           "missing fields",
+			token:          moderatorToken,
+			pvzID:          pvzID,
+			payload:        map[string]any{},
 			expectedStatus: http.StatusBadRequest,
 			wantErr:        true,
 		},
 		{
-			name:  "reception conflict",
-			token: employeeToken,
-			payload: pvzapi.PostReceptionsJSONRequestBody{
-				PvzId: pvzID,
-			},
-			prepareDB: func() {
-				_, err := s.dbPool.Exec(context.Background(),
-					"INSERT INTO receptions (pvz_id) VALUES ($1)",
-					pvzID)
-				s.Require().NoError(err)
+			name:  "invalid status",
+			token: moderatorToken,
+			pvzID: pvzID,
+			payload: map[string]any{
+				"status": "deleted",
 			},
 			expectedStatus: http.StatusBadRequest,
 			wantErr:        true,
 		},
 		{
-			name:           "invalid json",
-			token:          employeeToken,
-			payload:        "invalid json",
+			name:  "invalid city",
+			token: moderatorToken,
+			pvzID: pvzID,
+			payload: map[string]any{
+				"city": "Астана",
+			},
 			expectedStatus: http.StatusBadRequest,
 			wantErr:        true,
 		},
+		{
+			name:  "pvz not found",
+			token: moderatorToken,
+			pvzID: uuid.New(),
+			payload: map[string]any{
+				"status": "closed",
+			},
+			expectedStatus: http.StatusNotFound,
+			wantErr:        true,
+		},
 	}
 
 	for _, tt := range tests {
 		s.Run(tt.name, func() {
-			_, err := s.dbPool.Exec(context.Background(), "DELETE FROM receptions")
+			body, err := json.Marshal(tt.payload)
 			s.Require().NoError(err)
 
-			if tt.prepareDB != nil {
-				tt.prepareDB()
-			}
-
-			body, _ := json.Marshal(tt.payload)
-			req, _ := http.NewRequest(
-				http.MethodPost,
-				ts.URL+"/receptions",
+			req, err := http.NewRequest(
+				http.MethodPatch,
+				fmt.Sprintf("%s/pvz/%s", ts.URL, tt.pvzID),
 				bytes.NewReader(body),
 			)
+			s.Require().NoError(err)
 			req.Header.Set("Content-Type", "application/json")
 			req.Header.Set("Authorization", "Bearer "+tt.token)
 
@@ -537,51 +565,24 @@ func (s *HandlersTestSuite) TestPostReceptions() {
 
 			s.Equal(tt.expectedStatus, resp.StatusCode)
 
-			if !tt.wantErr {
-				var reception pvzapi.Reception
-				s.NoError(json.NewDecoder(resp.Body).Decode(&reception))
-				s.Equal(pvzID, reception.PvzId)
+			if tt.wantErr {
+				var errResp pvzapi.Error
+				err = json.NewDecoder(resp.Body).Decode(&errResp)
+				s.NoError(err)
+				return
 			}
-		})
-	}
-}
-
-func (s *HandlersTestSuite) TestInvalidToken() {
-	app := server.NewServer(s.cfg, zap.NewNop(), s.dbPool)
-	ts := httptest.NewServer(app.RegisterHandlers())
-	defer ts.Close()
-
-	token := "Bearer token"
 
-	tests := []struct {
-		name           string
-		token          string
-		expectedStatus int
-	}{
-		{
-			name:           "invalid token structure",
-			token:          token,
-			expectedStatus: http.StatusForbidden,
-		},
-	}
-
-	for _, tt := range tests {
-		s.Run(tt.name, func() {
-			url := fmt.Sprintf("%s/pvz", ts.URL)
-
-			req, _ := http.NewRequest(http.MethodGet, url, nil)
-			req.Header.Set("Authorization", "Bearer "+tt.token)
-
-			resp, err := http.DefaultClient.Do(req)
-			s.Require().NoError(err)
-			defer resp.Body.Close()
-
-			s.Equal(tt.expectedStatus, resp.StatusCode)
+			var pvzResp pvzapi.PVZ
+			err = json.NewDecoder(resp.Body).Decode(&pvzResp)
+			s.NoError(err)
+			s.Equal(tt.pvzID, *pvzResp.Id)
+			s.Require().NotNil(pvzResp.Status)
+			s.Equal(tt.expectedStatusValue, *pvzResp.Status)
 		})
 	}
 }
 
-func (s *HandlersTestSuite) TestPostProducts() {
+func (s *HandlersTestSuite) TestPostCities() {
 	app := server.NewServer(s.cfg, zap.NewNop(), s.dbPool)
 	ts := httptest.NewServer(app.RegisterHandlers())
 	defer ts.Close()
@@ -589,79 +590,43 @@ func (s *HandlersTestSuite) TestPostProducts() {
 	moderatorToken := s.Login(ts, "moderator")
 	employeeToken := s.Login(ts, "employee")
 
-	pvzID := uuid.New()
-
-	_, err := s.dbPool.Exec(context.Background(),
-		"INSERT INTO pvzs (id, city) VALUES ($1, $2)",
-		pvzID, "Москва")
-	s.Require().NoError(err)
-
 	tests := []struct {
 		name           string
 		token          string
 		payload        any
-		prepareDB      func()
 		expectedStatus int
 		wantErr        bool
 	}{
 		{
-			name:  "successful product addition",
-			token: employeeToken,
-			prepareDB: func() {
-				_, err := s.dbPool.Exec(context.Background(),
-					"INSERT INTO receptions (pvz_id) VALUES ($1)",
-					pvzID)
-				s.Require().NoError(err)
-			},
-			payload: pvzapi.PostProductsJSONRequestBody{
-				PvzId: pvzID,
-				Type:  pvzapi.PostProductsJSONBodyTypeОдежда,
+			name:  "successful city creation",
+			token: moderatorToken,
+			payload: pvzapi.PostCitiesJSONRequestBody{
+				Name: "Екатеринбург",
 			},
 			expectedStatus: http.StatusCreated,
 		},
 		{
-			name:  "access denied for moderator",
+			name:  "duplicate city",
 			token: moderatorToken,
-			payload: map[string]any{
-				"pvz_id": pvzID,
-				"type":   pvzapi.PostProductsJSONBodyTypeОдежда,
-			},
-			expectedStatus: http.StatusForbidden,
-			wantErr:        true,
-		},
-		{
-			name:  "missing pvz_id",
-			token: employeeToken,
-			payload: map[string]any{
-				"type": pvzapi.PostProductsJSONBodyTypeОдежда,
-			},
-			expectedStatus: http.StatusBadRequest,
-			wantErr:        true,
-		},
-		{
-			name:  "invalid product type",
-			token: employeeToken,
-			payload: pvzapi.PostProductsJSONRequestBody{
-				PvzId: pvzID,
-				Type:  "косметика",
+			payload: pvzapi.PostCitiesJSONRequestBody{
+				Name: "Москва",
 			},
 			expectedStatus: http.StatusBadRequest,
 			wantErr:        true,
 		},
 		{
-			name:  "no open reception",
+			name:  "access denied for other user",
 			token: employeeToken,
-			payload: pvzapi.PostProductsJSONRequestBody{
-				PvzId: pvzID,
-				Type:  pvzapi.PostProductsJSONBodyTypeОдежда,
+			payload: pvzapi.PostCitiesJSONRequestBody{
+				Name: "Новосибирск",
 			},
-			expectedStatus: http.StatusBadRequest,
+			expectedStatus: http.StatusForbidden,
 			wantErr:        true,
 		},
 		{
-			name:           "invalid json",
-			token:          employeeToken,
-			payload:        "invalid json",
+			name:           "missing name",
+			token:          moderatorToken,
+			payload:        map[string]any{},
 			expectedStatus: http.StatusBadRequest,
 			wantErr:        true,
 		},
@@ -669,23 +634,10 @@ func (s *HandlersTestSuite) TestPostProducts() {
 
 	for _, tt := range tests {
 		s.Run(tt.name, func() {
-			_, err := s.dbPool.Exec(context.Background(), "DELETE FROM products")
-			s.Require().NoError(err)
-			_, err = s.dbPool.Exec(context.Background(), "DELETE FROM receptions")
-			s.Require().NoError(err)
-
-			if tt.prepareDB != nil {
-				tt.prepareDB()
-			}
-
 			body, err := json.Marshal(tt.payload)
 			s.Require().NoError(err)
 
-			req, err := http.NewRequest(
-				http.MethodPost,
-				fmt.Sprintf("%s/products", ts.URL),
-				bytes.NewReader(body),
-			)
+			req, err := http.NewRequest(http.MethodPost, ts.URL+"/cities", bytes.NewReader(body))
 			s.Require().NoError(err)
 			req.Header.Set("Content-Type", "application/json")
 			req.Header.Set("Authorization", "Bearer "+tt.token)
@@ -698,200 +650,307 @@ func (s *HandlersTestSuite) TestPostProducts() {
 
 			if tt.wantErr {
 				var errResp pvzapi.Error
-				err = json.NewDecoder(resp.Body).Decode(&errResp)
-				s.NoError(err)
-			} else {
-				var product pvzapi.Product
-				err = json.NewDecoder(resp.Body).Decode(&product)
-				s.NoError(err)
-				s.NotEmpty(product.Id)
+				s.NoError(json.NewDecoder(resp.Body).Decode(&errResp))
+				return
 			}
+
+			var city pvzapi.City
+			s.NoError(json.NewDecoder(resp.Body).Decode(&city))
+			s.NotNil(city.Id)
 		})
 	}
+
+	pvzReq, err := json.Marshal(pvzapi.PostPvzJSONRequestBody{City: "Екатеринбург"})
+	s.Require().NoError(err)
+
+	req, err := http.NewRequest(http.MethodPost, ts.URL+"/pvz", bytes.NewReader(pvzReq))
+	s.Require().NoError(err)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+moderatorToken)
+
+	resp, err := http.DefaultClient.Do(req)
+	s.Require().NoError(err)
+	defer resp.Body.Close()
+
+	s.Equal(http.StatusCreated, resp.StatusCode, "new city must be accepted right after creation")
 }
 
-func (s *HandlersTestSuite) TestPostPvzPvzIdDeleteLastProduct() {
+func (s *HandlersTestSuite) TestDeleteCitiesCityId() {
 	app := server.NewServer(s.cfg, zap.NewNop(), s.dbPool)
 	ts := httptest.NewServer(app.RegisterHandlers())
 	defer ts.Close()
 
 	moderatorToken := s.Login(ts, "moderator")
-	employeeToken := s.Login(ts, "employee")
-
-	pvzID := uuid.New()
 
+	freeCityID := uuid.New()
 	_, err := s.dbPool.Exec(context.Background(),
-		"INSERT INTO pvzs (id, city) VALUES ($1, $2)",
-		pvzID, "Москва")
+		"INSERT INTO cities (id, name) VALUES ($1, $2)",
+		freeCityID, "Тверь")
 	s.Require().NoError(err)
 
+	usedCityID := uuid.New()
 	_, err = s.dbPool.Exec(context.Background(),
-		"INSERT INTO receptions (pvz_id) VALUES ($1)",
-		pvzID)
+		"INSERT INTO cities (id, name) VALUES ($1, $2)",
+		usedCityID, "Самара")
 	s.Require().NoError(err)
 
 	_, err = s.dbPool.Exec(context.Background(),
-		"INSERT INTO products (reception_id, type) VALUES ((SELECT id FROM receptions WHERE pvz_id = $1), $2)",
-		pvzID, "обувь")
+		"INSERT INTO pvzs (city) VALUES ($1)",
+		"Самара")
 	s.Require().NoError(err)
+	defer func() {
+		_, err := s.dbPool.Exec(context.Background(), "DELETE FROM pvzs WHERE city = $1", "Самара")
+		s.Require().NoError(err)
+	}()
 
 	tests := []struct {
 		name           string
-		token          string
-		pvzId          uuid.UUID
-		prepareDB      func()
+		cityID         uuid.UUID
 		expectedStatus int
-		wantErr        bool
 	}{
 		{
-			name:           "successful product deletion",
-			token:          employeeToken,
-			pvzId:          pvzID,
-			expectedStatus: http.StatusOK,
-		},
-		{
-			name:           "access denied",
-			token:          moderatorToken,
-			pvzId:          pvzID,
-			expectedStatus: http.StatusForbidden,
-			wantErr:        true,
+			name:           "successful deletion",
+			cityID:         freeCityID,
+			expectedStatus: http.StatusNoContent,
 		},
 		{
-			name:           "no open reception",
-			token:          employeeToken,
-			pvzId:          uuid.New(),
+			name:           "city has pvzs",
+			cityID:         usedCityID,
 			expectedStatus: http.StatusBadRequest,
-			wantErr:        true,
 		},
 		{
-			name:  "no products in reception",
-			token: employeeToken,
-			pvzId: pvzID,
-			prepareDB: func() {
-				_, err := s.dbPool.Exec(context.Background(),
-					"DELETE FROM products WHERE reception_id = (SELECT id FROM receptions WHERE pvz_id = $1)",
-					pvzID)
-				s.Require().NoError(err)
-			},
-			expectedStatus: http.StatusBadRequest,
-			wantErr:        true,
+			name:           "city not found",
+			cityID:         uuid.New(),
+			expectedStatus: http.StatusNotFound,
 		},
 	}
 
 	for _, tt := range tests {
 		s.Run(tt.name, func() {
-			if tt.prepareDB != nil {
-				tt.prepareDB()
-			}
-
-			req, err := http.NewRequest(
-				http.MethodPost,
-				fmt.Sprintf("%s/pvz/%s/delete_last_product", ts.URL, tt.pvzId),
-				nil,
-			)
+			req, err := http.NewRequest(http.MethodDelete, fmt.Sprintf("%s/cities/%s", ts.URL, tt.cityID), nil)
 			s.Require().NoError(err)
-			req.Header.Set("Authorization", "Bearer "+tt.token)
+			req.Header.Set("Authorization", "Bearer "+moderatorToken)
 
 			resp, err := http.DefaultClient.Do(req)
 			s.Require().NoError(err)
 			defer resp.Body.Close()
 
 			s.Equal(tt.expectedStatus, resp.StatusCode)
-
-			if tt.wantErr {
-				var errResp pvzapi.Error
-				s.NoError(json.NewDecoder(resp.Body).Decode(&errResp))
-			} else {
-				var count int
-				err := s.dbPool.QueryRow(context.Background(),
-					"SELECT COUNT(*) FROM products WHERE reception_id = (SELECT id FROM receptions WHERE pvz_id = $1)",
-					pvzID).Scan(&count)
-				s.Require().NoError(err)
-				s.Equal(0, count)
-			}
 		})
 	}
 }
 
-func (s *HandlersTestSuite) TestPostPvzPvzIdCloseLastReception() {
+func (s *HandlersTestSuite) TestProductTypes() {
 	app := server.NewServer(s.cfg, zap.NewNop(), s.dbPool)
 	ts := httptest.NewServer(app.RegisterHandlers())
 	defer ts.Close()
 
 	moderatorToken := s.Login(ts, "moderator")
-	employeeToken := s.Login(ts, "employee")
+	employeeToken, employeeID := s.LoginEmployee(ts)
 
 	pvzID := uuid.New()
-
 	_, err := s.dbPool.Exec(context.Background(),
 		"INSERT INTO pvzs (id, city) VALUES ($1, $2)",
 		pvzID, "Москва")
 	s.Require().NoError(err)
 
+	s.AssignEmployee(employeeID, pvzID)
+
 	_, err = s.dbPool.Exec(context.Background(),
 		"INSERT INTO receptions (pvz_id) VALUES ($1)",
 		pvzID)
 	s.Require().NoError(err)
 
+	do := func(method, path, token string, payload any) *http.Response {
+		var body []byte
+		if payload != nil {
+			body, err = json.Marshal(payload)
+			s.Require().NoError(err)
+		}
+
+		req, err := http.NewRequest(method, ts.URL+path, bytes.NewReader(body))
+		s.Require().NoError(err)
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", "Bearer "+token)
+
+		resp, err := http.DefaultClient.Do(req)
+		s.Require().NoError(err)
+
+		return resp
+	}
+
+	newType := pvzapi.PostProductTypesJSONRequestBody{
+		Name:        "бытовая техника",
+		DisplayName: "Бытовая техника",
+	}
+	product := pvzapi.PostProductsJSONRequestBody{
+		PvzId: pvzID,
+		Type:  newType.Name,
+	}
+
+	resp := do(http.MethodPost, "/product_types", employeeToken, newType)
+	resp.Body.Close()
+	s.Equal(http.StatusForbidden, resp.StatusCode)
+
+	resp = do(http.MethodPost, "/products", employeeToken, product)
+	resp.Body.Close()
+	s.Equal(http.StatusBadRequest, resp.StatusCode, "unknown type must be rejected")
+
+	resp = do(http.MethodPost, "/product_types", moderatorToken, newType)
+	var created pvzapi.ProductType
+	s.NoError(json.NewDecoder(resp.Body).Decode(&created))
+	resp.Body.Close()
+	s.Equal(http.StatusCreated, resp.StatusCode)
+	s.Require().NotNil(created.Id)
+
+	resp = do(http.MethodPost, "/product_types", moderatorToken, newType)
+	resp.Body.Close()
+	s.Equal(http.StatusBadRequest, resp.StatusCode, "duplicate type must be rejected")
+
+	resp = do(http.MethodPost, "/products", employeeToken, product)
+	resp.Body.Close()
+	s.Equal(http.StatusCreated, resp.StatusCode, "new type must be accepted right after creation")
+
+	resp = do(http.MethodDelete, fmt.Sprintf("/product_types/%s", *created.Id), moderatorToken, nil)
+	resp.Body.Close()
+	s.Equal(http.StatusNoContent, resp.StatusCode)
+
+	resp = do(http.MethodDelete, fmt.Sprintf("/product_types/%s", uuid.New()), moderatorToken, nil)
+	resp.Body.Close()
+	s.Equal(http.StatusNotFound, resp.StatusCode)
+
+	resp = do(http.MethodPost, "/products", employeeToken, product)
+	resp.Body.Close()
+	s.Equal(http.StatusBadRequest, resp.StatusCode, "retired type must be rejected")
+
+	resp = do(http.MethodGet, "/product_types", employeeToken, nil)
+	var types []pvzapi.ProductType
+	s.NoError(json.NewDecoder(resp.Body).Decode(&types))
+	resp.Body.Close()
+	s.Equal(http.StatusOK, resp.StatusCode)
+	s.Len(types, 4)
+}
+
+func (s *HandlersTestSuite) TestPostReceptions() {
+	app := server.NewServer(s.cfg, zap.NewNop(), s.dbPool)
+	ts := httptest.NewServer(app.RegisterHandlers())
+	defer ts.Close()
+
+	moderatorToken := s.Login(ts, "moderator")
+	employeeToken, employeeID := s.LoginEmployee(ts)
+
+	pvzID := uuid.New()
+	_, err := s.dbPool.Exec(context.Background(),
+		"INSERT INTO pvzs (id, city) VALUES ($1, $2)",
+		pvzID, "Москва")
+	s.Require().NoError(err)
+
+	suspendedPVZID := uuid.New()
+	_, err = s.dbPool.Exec(context.Background(),
+		"INSERT INTO pvzs (id, city, status) VALUES ($1, $2, $3)",
+		suspendedPVZID, "Москва", "suspended")
+	s.Require().NoError(err)
+
+	s.AssignEmployee(employeeID, pvzID, suspendedPVZID)
+
+	foreignPVZID := uuid.New()
+	_, err = s.dbPool.Exec(context.Background(),
+		"INSERT INTO pvzs (id, city) VALUES ($1, $2)",
+		foreignPVZID, "Москва")
+	s.Require().NoError(err)
+
 	tests := []struct {
 		name           string
 		token          string
-		pvzId          uuid.UUID
+		payload        any
 		prepareDB      func()
 		expectedStatus int
 		wantErr        bool
 	}{
 		{
-			name:           "successful reception closing",
-			token:          employeeToken,
-			pvzId:          pvzID,
-			expectedStatus: http.StatusOK,
+			name:  "successful reception creation",
+			token: employeeToken,
+			payload: pvzapi.PostReceptionsJSONRequestBody{
+				PvzId: pvzID,
+			},
+			expectedStatus: http.StatusCreated,
 		},
 		{
-			name:           "access denied for moderator",
-			token:          moderatorToken,
-			pvzId:          pvzID,
+			name:  "access denied for other user",
+			token: moderatorToken,
+			payload: pvzapi.PostReceptionsJSONRequestBody{
+				PvzId: pvzID,
+			},
 			expectedStatus: http.StatusForbidden,
 			wantErr:        true,
 		},
 		{
-			name:           "no open reception",
-			token:          employeeToken,
-			pvzId:          uuid.New(),
+			name:  "employee not assigned to the pvz",
+			token: employeeToken,
+			payload: pvzapi.PostReceptionsJSONRequestBody{
+				PvzId: foreignPVZID,
+			},
+			expectedStatus: http.StatusForbidden,
+			wantErr:        true,
+		},
+		{
+			name:  "missing id",
+			token: employeeToken,
+			payload: pvzapi.PostReceptionsJSONRequestBody{
+				PvzId: uuid.Nil,
+			},
 			expectedStatus: http.StatusBadRequest,
 			wantErr:        true,
 		},
 		{
-			name:  "already closed reception",
+			name:  "reception conflict",
 			token: employeeToken,
-			pvzId: pvzID,
+			payload: pvzapi.PostReceptionsJSONRequestBody{
+				PvzId: pvzID,
+			},
 			prepareDB: func() {
 				_, err := s.dbPool.Exec(context.Background(),
-					"UPDATE receptions SET status = 'close' WHERE pvz_id = $1",
+					"INSERT INTO receptions (pvz_id) VALUES ($1)",
 					pvzID)
 				s.Require().NoError(err)
 			},
 			expectedStatus: http.StatusBadRequest,
 			wantErr:        true,
 		},
+		{
+			name:  "pvz is suspended",
+			token: employeeToken,
+			payload: pvzapi.PostReceptionsJSONRequestBody{
+				PvzId: suspendedPVZID,
+			},
+			expectedStatus: http.StatusBadRequest,
+			wantErr:        true,
+		},
+		{
+			name:           "invalid json",
+			token:          employeeToken,
+			payload:        "invalid json",
+			expectedStatus: http.StatusBadRequest,
+			wantErr:        true,
+		},
 	}
 
 	for _, tt := range tests {
 		s.Run(tt.name, func() {
-			_, err := s.dbPool.Exec(context.Background(),
-				"UPDATE receptions SET status = 'in_progress' WHERE pvz_id = $1", pvzID)
+			_, err := s.dbPool.Exec(context.Background(), "DELETE FROM receptions")
 			s.Require().NoError(err)
 
 			if tt.prepareDB != nil {
 				tt.prepareDB()
 			}
 
-			req, err := http.NewRequest(
+			body, _ := json.Marshal(tt.payload)
+			req, _ := http.NewRequest(
 				http.MethodPost,
-				fmt.Sprintf("%s/pvz/%s/close_last_reception", ts.URL, tt.pvzId),
-				nil,
+				ts.URL+"/receptions",
+				bytes.NewReader(body),
 			)
-			s.Require().NoError(err)
+			req.Header.Set("Content-Type", "application/json")
 			req.Header.Set("Authorization", "Bearer "+tt.token)
 
 			resp, err := http.DefaultClient.Do(req)
@@ -900,137 +959,277 @@ func (s *HandlersTestSuite) TestPostPvzPvzIdCloseLastReception() {
 
 			s.Equal(tt.expectedStatus, resp.StatusCode)
 
-			if tt.wantErr {
-				var errResp pvzapi.Error
-				s.NoError(json.NewDecoder(resp.Body).Decode(&errResp))
-			} else {
+			if !tt.wantErr {
 				var reception pvzapi.Reception
 				s.NoError(json.NewDecoder(resp.Body).Decode(&reception))
-				s.Equal(pvzapi.Close, reception.Status)
+				s.Equal(pvzID, reception.PvzId)
 			}
 		})
 	}
 }
 
-func (s *HandlersTestSuite) TestGetPvz() {
+func (s *HandlersTestSuite) TestInvalidToken() {
 	app := server.NewServer(s.cfg, zap.NewNop(), s.dbPool)
 	ts := httptest.NewServer(app.RegisterHandlers())
 	defer ts.Close()
 
-	employeeToken := s.Login(ts, "employee")
+	token := "Bearer token"
 
-	now := time.Now()
-	dates := []time.Time{
-		now.Add(-72 * time.Hour),
-		now.Add(-48 * time.Hour),
-		now.Add(-24 * time.Hour),
-		now,
-		now.Add(24 * time.Hour),
+	tests := []struct {
+		name           string
+		token          string
+		expectedStatus int
+	}{
+		{
+			name:           "invalid token structure",
+			token:          token,
+			expectedStatus: http.StatusForbidden,
+		},
 	}
 
-	pvzIDs := make([]uuid.UUID, 5)
-	for i := 0; i < 5; i++ {
-		pvzIDs[i] = uuid.New()
-		_, err := s.dbPool.Exec(context.Background(),
-			"INSERT INTO pvzs (id, city) VALUES ($1, $2)",
-			pvzIDs[i], "Москва")
-		s.Require().NoError(err)
-	}
+	for _, tt := range tests {
+		s.Run(tt.name, func() {
+			url := fmt.Sprintf("%s/pvz", ts.URL)
 
-	for i, pvzID := range pvzIDs {
-		_, err := s.dbPool.Exec(context.Background(),
-			`INSERT INTO receptions (pvz_id, date_time) 
-			 VALUES ($1, $2)`,
-			pvzID, dates[i])
-		s.Require().NoError(err)
+			req, _ := http.NewRequest(http.MethodGet, url, nil)
+			req.Header.Set("Authorization", "Bearer "+tt.token)
+
+			resp, err := http.DefaultClient.Do(req)
+			s.Require().NoError(err)
+			defer resp.Body.Close()
+
+			s.Equal(tt.expectedStatus, resp.StatusCode)
+		})
 	}
+}
+
+func (s *HandlersTestSuite) TestPostProducts() {
+	app := server.NewServer(s.cfg, zap.NewNop(), s.dbPool)
+	ts := httptest.NewServer(app.RegisterHandlers())
+	defer ts.Close()
+
+	moderatorToken := s.Login(ts, "moderator")
+	employeeToken, employeeID := s.LoginEmployee(ts)
+
+	pvzID := uuid.New()
+
+	_, err := s.dbPool.Exec(context.Background(),
+		"INSERT INTO pvzs (id, city) VALUES ($1, $2)",
+		pvzID, "Москва")
+	s.Require().NoError(err)
+
+	s.AssignEmployee(employeeID, pvzID)
 
 	tests := []struct {
 		name           string
 		token          string
-		queryParams    url.Values
-		expectedCount  int
+		payload        any
+		prepareDB      func()
 		expectedStatus int
 		wantErr        bool
 	}{
 		{
-			name:           "no filters",
-			token:          employeeToken,
-			expectedCount:  5,
-			expectedStatus: http.StatusOK,
+			name:  "successful product addition",
+			token: employeeToken,
+			prepareDB: func() {
+				_, err := s.dbPool.Exec(context.Background(),
+					"INSERT INTO receptions (pvz_id) VALUES ($1)",
+					pvzID)
+				s.Require().NoError(err)
+			},
+			payload: pvzapi.PostProductsJSONRequestBody{
+				PvzId: pvzID,
+				Type:  "одежда",
+			},
+			expectedStatus: http.StatusCreated,
 		},
 		{
-			name:  "filter by date range",
-			token: employeeToken,
-			queryParams: url.Values{
-				"startDate": []string{dates[1].Format(time.RFC3339)},
-				"endDate":   []string{dates[3].Format(time.RFC3339)},
+			name:  "access denied for moderator",
+			token: moderatorToken,
+			payload: map[string]any{
+				"pvz_id": pvzID,
+				"type":   "одежда",
 			},
-			expectedCount:  2,
-			expectedStatus: http.StatusOK,
+			expectedStatus: http.StatusForbidden,
+			wantErr:        true,
 		},
 		{
-			name:  "invalid date range",
+			name:  "missing pvz_id",
 			token: employeeToken,
-			queryParams: url.Values{
-				"startDate": []string{dates[3].Format(time.RFC3339)},
-				"endDate":   []string{dates[1].Format(time.RFC3339)},
+			payload: map[string]any{
+				"type": "одежда",
 			},
 			expectedStatus: http.StatusBadRequest,
 			wantErr:        true,
 		},
 		{
-			name:  "pagination - first page",
+			name:  "invalid product type",
 			token: employeeToken,
-			queryParams: url.Values{
-				"page":  []string{"1"},
-				"limit": []string{"2"},
+			payload: pvzapi.PostProductsJSONRequestBody{
+				PvzId: pvzID,
+				Type:  "косметика",
 			},
-			expectedCount:  2,
-			expectedStatus: http.StatusOK,
+			expectedStatus: http.StatusBadRequest,
+			wantErr:        true,
 		},
 		{
-			name:  "pagination - second page",
+			name:  "no open reception",
 			token: employeeToken,
-			queryParams: url.Values{
-				"page":  []string{"2"},
-				"limit": []string{"2"},
+			payload: pvzapi.PostProductsJSONRequestBody{
+				PvzId: pvzID,
+				Type:  "одежда",
 			},
-			expectedCount:  2,
-			expectedStatus: http.StatusOK,
+			expectedStatus: http.StatusBadRequest,
+			wantErr:        true,
 		},
 		{
-			name:  "pagination - last page",
-			token: employeeToken,
-			queryParams: url.Values{
-				"page":  []string{"3"},
-				"limit": []string{"2"},
-			},
-			expectedCount:  1,
+			name:           "invalid json",
+			token:          employeeToken,
+			payload:        "invalid json",
+			expectedStatus: http.StatusBadRequest,
+			wantErr:        true,
+		},
+	}
+
+	for _, tt := range tests {
+		s.Run(tt.name, func() {
+			_, err := s.dbPool.Exec(context.Background(), "DELETE FROM products")
+			s.Require().NoError(err)
+			_, err = s.dbPool.Exec(context.Background(), "DELETE FROM receptions")
+			s.Require().NoError(err)
+
+			if tt.prepareDB != nil {
+				tt.prepareDB()
+			}
+
+			body, err := json.Marshal(tt.payload)
+			s.Require().NoError(err)
+
+			req, err := http.NewRequest(
+				http.MethodPost,
+				fmt.Sprintf("%s/products", ts.URL),
+				bytes.NewReader(body),
+			)
+			s.Require().NoError(err)
+			req.Header.Set("Content-Type", "application/json")
+			req.Header.Set("Authorization", "Bearer "+tt.token)
+
+			resp, err := http.DefaultClient.Do(req)
+			s.Require().NoError(err)
+			defer resp.Body.Close()
+
+			s.Equal(tt.expectedStatus, resp.StatusCode)
+
+			if tt.wantErr {
+				var errResp pvzapi.Error
+				err = json.NewDecoder(resp.Body).Decode(&errResp)
+				s.NoError(err)
+			} else {
+				var product pvzapi.Product
+				err = json.NewDecoder(resp.Body).Decode(&product)
+				s.NoError(err)
+				s.NotEmpty(product.Id)
+				s.Require().NotNil(product.CreatedBy)
+				s.Equal(employeeID, *product.CreatedBy)
+			}
+		})
+	}
+}
+
+func (s *HandlersTestSuite) TestPostPvzPvzIdDeleteLastProduct() {
+	app := server.NewServer(s.cfg, zap.NewNop(), s.dbPool)
+	ts := httptest.NewServer(app.RegisterHandlers())
+	defer ts.Close()
+
+	moderatorToken := s.Login(ts, "moderator")
+	employeeToken, employeeID := s.LoginEmployee(ts)
+
+	pvzID := uuid.New()
+
+	_, err := s.dbPool.Exec(context.Background(),
+		"INSERT INTO pvzs (id, city) VALUES ($1, $2)",
+		pvzID, "Москва")
+	s.Require().NoError(err)
+
+	emptyPVZID := uuid.New()
+	_, err = s.dbPool.Exec(context.Background(),
+		"INSERT INTO pvzs (id, city) VALUES ($1, $2)",
+		emptyPVZID, "Москва")
+	s.Require().NoError(err)
+
+	s.AssignEmployee(employeeID, pvzID, emptyPVZID)
+
+	_, err = s.dbPool.Exec(context.Background(),
+		"INSERT INTO receptions (pvz_id) VALUES ($1)",
+		pvzID)
+	s.Require().NoError(err)
+
+	_, err = s.dbPool.Exec(context.Background(),
+		"INSERT INTO products (reception_id, type, line_number) VALUES ((SELECT id FROM receptions WHERE pvz_id = $1), $2, 1)",
+		pvzID, "обувь")
+	s.Require().NoError(err)
+
+	tests := []struct {
+		name           string
+		token          string
+		pvzId          uuid.UUID
+		prepareDB      func()
+		expectedStatus int
+		wantErr        bool
+	}{
+		{
+			name:           "successful product deletion",
+			token:          employeeToken,
+			pvzId:          pvzID,
 			expectedStatus: http.StatusOK,
 		},
 		{
-			name:  "combined filters",
+			name:           "access denied",
+			token:          moderatorToken,
+			pvzId:          pvzID,
+			expectedStatus: http.StatusForbidden,
+			wantErr:        true,
+		},
+		{
+			name:           "employee not assigned to the pvz",
+			token:          employeeToken,
+			pvzId:          uuid.New(),
+			expectedStatus: http.StatusForbidden,
+			wantErr:        true,
+		},
+		{
+			name:           "no open reception",
+			token:          employeeToken,
+			pvzId:          emptyPVZID,
+			expectedStatus: http.StatusBadRequest,
+			wantErr:        true,
+		},
+		{
+			name:  "no products in reception",
 			token: employeeToken,
-			queryParams: url.Values{
-				"startDate": []string{dates[0].Format(time.RFC3339)},
-				"endDate":   []string{dates[2].Format(time.RFC3339)},
-				"page":      []string{"1"},
-				"limit":     []string{"2"},
+			pvzId: pvzID,
+			prepareDB: func() {
+				_, err := s.dbPool.Exec(context.Background(),
+					"DELETE FROM products WHERE reception_id = (SELECT id FROM receptions WHERE pvz_id = $1)",
+					pvzID)
+				s.Require().NoError(err)
 			},
-			expectedCount:  2,
-			expectedStatus: http.StatusOK,
+			expectedStatus: http.StatusBadRequest,
+			wantErr:        true,
 		},
 	}
 
 	for _, tt := range tests {
 		s.Run(tt.name, func() {
-			url := fmt.Sprintf("%s/pvz", ts.URL)
-			if len(tt.queryParams) > 0 {
-				url += "?" + tt.queryParams.Encode()
+			if tt.prepareDB != nil {
+				tt.prepareDB()
 			}
 
-			req, err := http.NewRequest(http.MethodGet, url, nil)
+			req, err := http.NewRequest(
+				http.MethodPost,
+				fmt.Sprintf("%s/pvz/%s/delete_last_product", ts.URL, tt.pvzId),
+				nil,
+			)
 			s.Require().NoError(err)
 			req.Header.Set("Authorization", "Bearer "+tt.token)
 
@@ -1043,12 +1242,2427 @@ func (s *HandlersTestSuite) TestGetPvz() {
 			if tt.wantErr {
 				var errResp pvzapi.Error
 				s.NoError(json.NewDecoder(resp.Body).Decode(&errResp))
-				return
+			} else {
+				var count int
+				err := s.dbPool.QueryRow(context.Background(),
+					"SELECT COUNT(*) FROM products WHERE reception_id = (SELECT id FROM receptions WHERE pvz_id = $1)",
+					pvzID).Scan(&count)
+				s.Require().NoError(err)
+				s.Equal(0, count)
 			}
-
-			var pvzs []dtos.PVZWithReceptions
-			s.NoError(json.NewDecoder(resp.Body).Decode(&pvzs))
-			s.Len(pvzs, tt.expectedCount)
 		})
 	}
 }
+
+func (s *HandlersTestSuite) TestPostPvzPvzIdCancelLastReception() {
+	app := server.NewServer(s.cfg, zap.NewNop(), s.dbPool)
+	ts := httptest.NewServer(app.RegisterHandlers())
+	defer ts.Close()
+
+	moderatorToken := s.Login(ts, "moderator")
+	employeeToken, employeeID := s.LoginEmployee(ts)
+
+	pvzID := uuid.New()
+	_, err := s.dbPool.Exec(context.Background(),
+		"INSERT INTO pvzs (id, city) VALUES ($1, $2)",
+		pvzID, "Москва")
+	s.Require().NoError(err)
+
+	s.AssignEmployee(employeeID, pvzID)
+
+	receptionID := uuid.New()
+	_, err = s.dbPool.Exec(context.Background(),
+		"INSERT INTO receptions (id, pvz_id) VALUES ($1, $2)",
+		receptionID, pvzID)
+	s.Require().NoError(err)
+
+	_, err = s.dbPool.Exec(context.Background(),
+		"INSERT INTO products (type, reception_id, line_number) VALUES ('обувь', $1, 1), ('одежда', $1, 2)",
+		receptionID)
+	s.Require().NoError(err)
+
+	cancel := func(token string, pvzID uuid.UUID) *http.Response {
+		req, err := http.NewRequest(http.MethodPost, fmt.Sprintf("%s/pvz/%s/cancel_last_reception", ts.URL, pvzID), nil)
+		s.Require().NoError(err)
+		req.Header.Set("Authorization", "Bearer "+token)
+
+		resp, err := http.DefaultClient.Do(req)
+		s.Require().NoError(err)
+
+		return resp
+	}
+
+	resp := cancel(moderatorToken, pvzID)
+	resp.Body.Close()
+	s.Equal(http.StatusForbidden, resp.StatusCode)
+
+	resp = cancel(employeeToken, uuid.New())
+	resp.Body.Close()
+	s.Equal(http.StatusForbidden, resp.StatusCode)
+
+	resp = cancel(employeeToken, pvzID)
+	s.Require().Equal(http.StatusOK, resp.StatusCode)
+
+	var reception pvzapi.Reception
+	s.NoError(json.NewDecoder(resp.Body).Decode(&reception))
+	resp.Body.Close()
+	s.Equal(pvzapi.Cancelled, reception.Status)
+	s.Require().NotNil(reception.ClosedBy)
+	s.Equal(employeeID, *reception.ClosedBy)
+
+	var productsCount int
+	err = s.dbPool.QueryRow(context.Background(),
+		"SELECT COUNT(*) FROM products WHERE reception_id = $1", receptionID).Scan(&productsCount)
+	s.Require().NoError(err)
+	s.Zero(productsCount, "products of the cancelled reception must be deleted")
+
+	resp = cancel(employeeToken, pvzID)
+	resp.Body.Close()
+	s.Equal(http.StatusBadRequest, resp.StatusCode, "nothing left to cancel")
+
+	listPVZs := func(excludeCancelled bool) []dtos.PVZWithReceptions {
+		query := url.Values{}
+		query.Set("startDate", reception.DateTime.Format(time.RFC3339Nano))
+		query.Set("endDate", reception.DateTime.Format(time.RFC3339Nano))
+		query.Set("excludeCancelled", fmt.Sprint(excludeCancelled))
+
+		req, err := http.NewRequest(http.MethodGet, ts.URL+"/pvz?"+query.Encode(), nil)
+		s.Require().NoError(err)
+		req.Header.Set("Authorization", "Bearer "+moderatorToken)
+
+		resp, err := http.DefaultClient.Do(req)
+		s.Require().NoError(err)
+		defer resp.Body.Close()
+		s.Require().Equal(http.StatusOK, resp.StatusCode)
+
+		var pvzs []dtos.PVZWithReceptions
+		s.Require().NoError(json.NewDecoder(resp.Body).Decode(&pvzs))
+		return pvzs
+	}
+
+	s.Len(listPVZs(false), 1)
+	s.Empty(listPVZs(true))
+}
+
+func (s *HandlersTestSuite) TestPostPvzPvzIdCloseLastReception() {
+	app := server.NewServer(s.cfg, zap.NewNop(), s.dbPool)
+	ts := httptest.NewServer(app.RegisterHandlers())
+	defer ts.Close()
+
+	moderatorToken := s.Login(ts, "moderator")
+	employeeToken, employeeID := s.LoginEmployee(ts)
+
+	pvzID := uuid.New()
+
+	_, err := s.dbPool.Exec(context.Background(),
+		"INSERT INTO pvzs (id, city) VALUES ($1, $2)",
+		pvzID, "Москва")
+	s.Require().NoError(err)
+
+	emptyPVZID := uuid.New()
+	_, err = s.dbPool.Exec(context.Background(),
+		"INSERT INTO pvzs (id, city) VALUES ($1, $2)",
+		emptyPVZID, "Москва")
+	s.Require().NoError(err)
+
+	s.AssignEmployee(employeeID, pvzID, emptyPVZID)
+
+	_, err = s.dbPool.Exec(context.Background(),
+		"INSERT INTO receptions (pvz_id) VALUES ($1)",
+		pvzID)
+	s.Require().NoError(err)
+
+	tests := []struct {
+		name           string
+		token          string
+		pvzId          uuid.UUID
+		prepareDB      func()
+		expectedStatus int
+		wantErr        bool
+	}{
+		{
+			name:           "successful reception closing",
+			token:          employeeToken,
+			pvzId:          pvzID,
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "access denied for moderator",
+			token:          moderatorToken,
+			pvzId:          pvzID,
+			expectedStatus: http.StatusForbidden,
+			wantErr:        true,
+		},
+		{
+			name:           "employee not assigned to the pvz",
+			token:          employeeToken,
+			pvzId:          uuid.New(),
+			expectedStatus: http.StatusForbidden,
+			wantErr:        true,
+		},
+		{
+			name:           "no open reception",
+			token:          employeeToken,
+			pvzId:          emptyPVZID,
+			expectedStatus: http.StatusBadRequest,
+			wantErr:        true,
+		},
+		{
+			name:  "already closed reception",
+			token: employeeToken,
+			pvzId: pvzID,
+			prepareDB: func() {
+				_, err := s.dbPool.Exec(context.Background(),
+					"UPDATE receptions SET status = 'close' WHERE pvz_id = $1",
+					pvzID)
+				s.Require().NoError(err)
+			},
+			expectedStatus: http.StatusBadRequest,
+			wantErr:        true,
+		},
+	}
+
+	for _, tt := range tests {
+		s.Run(tt.name, func() {
+			_, err := s.dbPool.Exec(context.Background(),
+				"UPDATE receptions SET status = 'in_progress' WHERE pvz_id = $1", pvzID)
+			s.Require().NoError(err)
+
+			if tt.prepareDB != nil {
+				tt.prepareDB()
+			}
+
+			req, err := http.NewRequest(
+				http.MethodPost,
+				fmt.Sprintf("%s/pvz/%s/close_last_reception", ts.URL, tt.pvzId),
+				nil,
+			)
+			s.Require().NoError(err)
+			req.Header.Set("Authorization", "Bearer "+tt.token)
+
+			resp, err := http.DefaultClient.Do(req)
+			s.Require().NoError(err)
+			defer resp.Body.Close()
+
+			s.Equal(tt.expectedStatus, resp.StatusCode)
+
+			if tt.wantErr {
+				var errResp pvzapi.Error
+				s.NoError(json.NewDecoder(resp.Body).Decode(&errResp))
+			} else {
+				var reception pvzapi.Reception
+				s.NoError(json.NewDecoder(resp.Body).Decode(&reception))
+				s.Equal(pvzapi.Close, reception.Status)
+			}
+		})
+	}
+}
+
+func (s *HandlersTestSuite) TestGetPvz() {
+	app := server.NewServer(s.cfg, zap.NewNop(), s.dbPool)
+	ts := httptest.NewServer(app.RegisterHandlers())
+	defer ts.Close()
+
+	employeeToken := s.Login(ts, "employee")
+
+	now := time.Now()
+	dates := []time.Time{
+		now.Add(-72 * time.Hour),
+		now.Add(-48 * time.Hour),
+		now.Add(-24 * time.Hour),
+		now,
+		now.Add(24 * time.Hour),
+	}
+
+	pvzIDs := make([]uuid.UUID, 5)
+	for i := 0; i < 5; i++ {
+		pvzIDs[i] = uuid.New()
+		_, err := s.dbPool.Exec(context.Background(),
+			"INSERT INTO pvzs (id, city) VALUES ($1, $2)",
+			pvzIDs[i], "Москва")
+		s.Require().NoError(err)
+	}
+
+	for i, pvzID := range pvzIDs {
+		_, err := s.dbPool.Exec(context.Background(),
+			`INSERT INTO receptions (pvz_id, date_time) 
+			 VALUES ($1, $2)`,
+			pvzID, dates[i])
+		s.Require().NoError(err)
+	}
+
+	tests := []struct {
+		name           string
+		token          string
+		queryParams    url.Values
+		expectedCount  int
+		expectedStatus int
+		wantErr        bool
+	}{
+		{
+			name:           "no filters",
+			token:          employeeToken,
+			expectedCount:  5,
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:  "filter by date range",
+			token: employeeToken,
+			queryParams: url.Values{
+				"startDate": []string{dates[1].Format(time.RFC3339)},
+				"endDate":   []string{dates[3].Format(time.RFC3339)},
+			},
+			expectedCount:  2,
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:  "invalid date range",
+			token: employeeToken,
+			queryParams: url.Values{
+				"startDate": []string{dates[3].Format(time.RFC3339)},
+				"endDate":   []string{dates[1].Format(time.RFC3339)},
+			},
+			expectedStatus: http.StatusBadRequest,
+			wantErr:        true,
+		},
+		{
+			name:  "pagination - first page",
+			token: employeeToken,
+			queryParams: url.Values{
+				"page":  []string{"1"},
+				"limit": []string{"2"},
+			},
+			expectedCount:  2,
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:  "pagination - second page",
+			token: employeeToken,
+			queryParams: url.Values{
+				"page":  []string{"2"},
+				"limit": []string{"2"},
+			},
+			expectedCount:  2,
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:  "pagination - last page",
+			token: employeeToken,
+			queryParams: url.Values{
+				"page":  []string{"3"},
+				"limit": []string{"2"},
+			},
+			expectedCount:  1,
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:  "combined filters",
+			token: employeeToken,
+			queryParams: url.Values{
+				"startDate": []string{dates[0].Format(time.RFC3339)},
+				"endDate":   []string{dates[2].Format(time.RFC3339)},
+				"page":      []string{"1"},
+				"limit":     []string{"2"},
+			},
+			expectedCount:  2,
+			expectedStatus: http.StatusOK,
+		},
+	}
+
+	for _, tt := range tests {
+		s.Run(tt.name, func() {
+			url := fmt.Sprintf("%s/pvz", ts.URL)
+			if len(tt.queryParams) > 0 {
+				url += "?" + tt.queryParams.Encode()
+			}
+
+			req, err := http.NewRequest(http.MethodGet, url, nil)
+			s.Require().NoError(err)
+			req.Header.Set("Authorization", "Bearer "+tt.token)
+
+			resp, err := http.DefaultClient.Do(req)
+			s.Require().NoError(err)
+			defer resp.Body.Close()
+
+			s.Equal(tt.expectedStatus, resp.StatusCode)
+
+			if tt.wantErr {
+				var errResp pvzapi.Error
+				s.NoError(json.NewDecoder(resp.Body).Decode(&errResp))
+				return
+			}
+
+			var pvzs []dtos.PVZWithReceptions
+			s.NoError(json.NewDecoder(resp.Body).Decode(&pvzs))
+			s.Len(pvzs, tt.expectedCount)
+		})
+	}
+}
+
+func (s *HandlersTestSuite) TestGetPvzPvzId() {
+	app := server.NewServer(s.cfg, zap.NewNop(), s.dbPool)
+	ts := httptest.NewServer(app.RegisterHandlers())
+	defer ts.Close()
+
+	employeeToken := s.Login(ts, "employee")
+
+	pvzID := uuid.New()
+	_, err := s.dbPool.Exec(context.Background(),
+		"INSERT INTO pvzs (id, city) VALUES ($1, $2)",
+		pvzID, "Москва")
+	s.Require().NoError(err)
+
+	_, err = s.dbPool.Exec(context.Background(),
+		"INSERT INTO receptions (pvz_id, status) VALUES ($1, 'close')",
+		pvzID)
+	s.Require().NoError(err)
+
+	openReceptionID := uuid.New()
+	_, err = s.dbPool.Exec(context.Background(),
+		"INSERT INTO receptions (id, pvz_id) VALUES ($1, $2)",
+		openReceptionID, pvzID)
+	s.Require().NoError(err)
+
+	_, err = s.dbPool.Exec(context.Background(),
+		"INSERT INTO products (type, reception_id, line_number) VALUES ('обувь', $1, 1), ('одежда', $1, 2)",
+		openReceptionID)
+	s.Require().NoError(err)
+
+	tests := []struct {
+		name           string
+		pvzID          uuid.UUID
+		expectedStatus int
+	}{
+		{
+			name:           "successful get",
+			pvzID:          pvzID,
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "pvz not found",
+			pvzID:          uuid.New(),
+			expectedStatus: http.StatusNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		s.Run(tt.name, func() {
+			req, err := http.NewRequest(http.MethodGet, fmt.Sprintf("%s/pvz/%s", ts.URL, tt.pvzID), nil)
+			s.Require().NoError(err)
+			req.Header.Set("Authorization", "Bearer "+employeeToken)
+
+			resp, err := http.DefaultClient.Do(req)
+			s.Require().NoError(err)
+			defer resp.Body.Close()
+
+			s.Equal(tt.expectedStatus, resp.StatusCode)
+
+			if tt.expectedStatus != http.StatusOK {
+				return
+			}
+
+			var details dtos.PVZDetails
+			s.NoError(json.NewDecoder(resp.Body).Decode(&details))
+			s.Equal(tt.pvzID, *details.PVZ.Id)
+			s.Require().NotNil(details.OpenReception)
+			s.Equal(openReceptionID, *details.OpenReception.Reception.Id)
+			s.Equal(2, details.OpenReception.ProductsCount)
+			s.Equal(2, details.ReceptionsCount)
+			s.Equal(2, details.ProductsCount)
+		})
+	}
+}
+
+func (s *HandlersTestSuite) TestGetReceptionsReceptionId() {
+	app := server.NewServer(s.cfg, zap.NewNop(), s.dbPool)
+	ts := httptest.NewServer(app.RegisterHandlers())
+	defer ts.Close()
+
+	moderatorToken := s.Login(ts, "moderator")
+
+	pvzID := uuid.New()
+	_, err := s.dbPool.Exec(context.Background(),
+		"INSERT INTO pvzs (id, city) VALUES ($1, $2)",
+		pvzID, "Казань")
+	s.Require().NoError(err)
+
+	receptionID := uuid.New()
+	_, err = s.dbPool.Exec(context.Background(),
+		"INSERT INTO receptions (id, pvz_id) VALUES ($1, $2)",
+		receptionID, pvzID)
+	s.Require().NoError(err)
+
+	_, err = s.dbPool.Exec(context.Background(),
+		"INSERT INTO products (type, reception_id, line_number) VALUES ('электроника', $1, 1)",
+		receptionID)
+	s.Require().NoError(err)
+
+	tests := []struct {
+		name           string
+		receptionID    uuid.UUID
+		expectedStatus int
+	}{
+		{
+			name:           "successful get",
+			receptionID:    receptionID,
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "reception not found",
+			receptionID:    uuid.New(),
+			expectedStatus: http.StatusNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		s.Run(tt.name, func() {
+			req, err := http.NewRequest(http.MethodGet, fmt.Sprintf("%s/receptions/%s", ts.URL, tt.receptionID), nil)
+			s.Require().NoError(err)
+			req.Header.Set("Authorization", "Bearer "+moderatorToken)
+
+			resp, err := http.DefaultClient.Do(req)
+			s.Require().NoError(err)
+			defer resp.Body.Close()
+
+			s.Equal(tt.expectedStatus, resp.StatusCode)
+
+			if tt.expectedStatus != http.StatusOK {
+				return
+			}
+
+			var reception dtos.ReceptionWithProducts
+			s.NoError(json.NewDecoder(resp.Body).Decode(&reception))
+			s.Equal(tt.receptionID, *reception.Reception.Id)
+			s.Len(reception.Products, 1)
+		})
+	}
+}
+
+func (s *HandlersTestSuite) TestPvzPvzIdEmployees() {
+	app := server.NewServer(s.cfg, zap.NewNop(), s.dbPool)
+	ts := httptest.NewServer(app.RegisterHandlers())
+	defer ts.Close()
+
+	moderatorToken := s.Login(ts, "moderator")
+	employeeToken, employeeID := s.LoginEmployee(ts)
+
+	pvzID := uuid.New()
+	_, err := s.dbPool.Exec(context.Background(),
+		"INSERT INTO pvzs (id, city) VALUES ($1, $2)",
+		pvzID, "Москва")
+	s.Require().NoError(err)
+
+	do := func(method, path, token string, payload any) *http.Response {
+		var body []byte
+		if payload != nil {
+			body, err = json.Marshal(payload)
+			s.Require().NoError(err)
+		}
+
+		req, err := http.NewRequest(method, ts.URL+path, bytes.NewReader(body))
+		s.Require().NoError(err)
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", "Bearer "+token)
+
+		resp, err := http.DefaultClient.Do(req)
+		s.Require().NoError(err)
+
+		return resp
+	}
+
+	employeesPath := fmt.Sprintf("/pvz/%s/employees", pvzID)
+	assign := pvzapi.PostPvzPvzIdEmployeesJSONRequestBody{UserId: employeeID}
+	reception := pvzapi.PostReceptionsJSONRequestBody{PvzId: pvzID}
+
+	resp := do(http.MethodPost, "/receptions", employeeToken, reception)
+	resp.Body.Close()
+	s.Equal(http.StatusForbidden, resp.StatusCode, "unassigned employee must be rejected")
+
+	resp = do(http.MethodPost, employeesPath, employeeToken, assign)
+	resp.Body.Close()
+	s.Equal(http.StatusForbidden, resp.StatusCode)
+
+	resp = do(http.MethodPost, employeesPath, moderatorToken, assign)
+	resp.Body.Close()
+	s.Equal(http.StatusCreated, resp.StatusCode)
+
+	resp = do(http.MethodPost, employeesPath, moderatorToken, assign)
+	resp.Body.Close()
+	s.Equal(http.StatusBadRequest, resp.StatusCode, "duplicate assignment must be rejected")
+
+	resp = do(http.MethodPost, fmt.Sprintf("/pvz/%s/employees", uuid.New()), moderatorToken, assign)
+	resp.Body.Close()
+	s.Equal(http.StatusNotFound, resp.StatusCode)
+
+	resp = do(http.MethodGet, employeesPath, moderatorToken, nil)
+	var employees []pvzapi.User
+	s.NoError(json.NewDecoder(resp.Body).Decode(&employees))
+	resp.Body.Close()
+	s.Equal(http.StatusOK, resp.StatusCode)
+	s.Require().Len(employees, 1)
+	s.Equal(employeeID, *employees[0].Id)
+
+	resp = do(http.MethodPost, "/receptions", employeeToken, reception)
+	resp.Body.Close()
+	s.Equal(http.StatusCreated, resp.StatusCode)
+
+	resp = do(http.MethodDelete, fmt.Sprintf("%s/%s", employeesPath, employeeID), moderatorToken, nil)
+	resp.Body.Close()
+	s.Equal(http.StatusNoContent, resp.StatusCode)
+
+	resp = do(http.MethodDelete, fmt.Sprintf("%s/%s", employeesPath, employeeID), moderatorToken, nil)
+	resp.Body.Close()
+	s.Equal(http.StatusNotFound, resp.StatusCode)
+
+	resp = do(http.MethodPost, fmt.Sprintf("/pvz/%s/close_last_reception", pvzID), employeeToken, nil)
+	resp.Body.Close()
+	s.Equal(http.StatusForbidden, resp.StatusCode, "unassigned employee must be rejected")
+}
+
+func (s *HandlersTestSuite) TestReceptionManifest() {
+	app := server.NewServer(s.cfg, zap.NewNop(), s.dbPool)
+	ts := httptest.NewServer(app.RegisterHandlers())
+	defer ts.Close()
+
+	employeeToken, employeeID := s.LoginEmployee(ts)
+
+	pvzID := uuid.New()
+	_, err := s.dbPool.Exec(context.Background(),
+		"INSERT INTO pvzs (id, city) VALUES ($1, $2)",
+		pvzID, "Казань")
+	s.Require().NoError(err)
+
+	s.AssignEmployee(employeeID, pvzID)
+
+	do := func(method, path string, payload any) *http.Response {
+		var body []byte
+		if payload != nil {
+			body, err = json.Marshal(payload)
+			s.Require().NoError(err)
+		}
+
+		req, err := http.NewRequest(method, ts.URL+path, bytes.NewReader(body))
+		s.Require().NoError(err)
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", "Bearer "+employeeToken)
+
+		resp, err := http.DefaultClient.Do(req)
+		s.Require().NoError(err)
+
+		return resp
+	}
+
+	invalidManifests := map[string][]pvzapi.ManifestItem{
+		"zero count":     {{Type: "обувь", Count: 0}},
+		"duplicate type": {{Type: "обувь", Count: 1}, {Type: "обувь", Count: 2}},
+		"unknown type":   {{Type: "мебель", Count: 1}},
+		"missing type":   {{Count: 1}},
+	}
+	for name, manifest := range invalidManifests {
+		resp := do(http.MethodPost, "/receptions", pvzapi.PostReceptionsJSONRequestBody{
+			PvzId:    pvzID,
+			Manifest: &manifest,
+		})
+		resp.Body.Close()
+		s.Equal(http.StatusBadRequest, resp.StatusCode, name)
+	}
+
+	manifest := []pvzapi.ManifestItem{
+		{Type: "обувь", Count: 2},
+		{Type: "одежда", Count: 1},
+	}
+	resp := do(http.MethodPost, "/receptions", pvzapi.PostReceptionsJSONRequestBody{
+		PvzId:    pvzID,
+		Manifest: &manifest,
+	})
+	s.Require().Equal(http.StatusCreated, resp.StatusCode)
+
+	var opened pvzapi.Reception
+	s.NoError(json.NewDecoder(resp.Body).Decode(&opened))
+	resp.Body.Close()
+	s.Require().NotNil(opened.Manifest)
+	s.ElementsMatch(manifest, *opened.Manifest)
+	s.Nil(opened.Discrepancies)
+
+	for _, productType := range []string{"обувь", "электроника"} {
+		resp = do(http.MethodPost, "/products", pvzapi.PostProductsJSONRequestBody{
+			PvzId: pvzID,
+			Type:  productType,
+		})
+		resp.Body.Close()
+		s.Require().Equal(http.StatusCreated, resp.StatusCode)
+	}
+
+	resp = do(http.MethodPost, fmt.Sprintf("/pvz/%s/close_last_reception", pvzID), nil)
+	s.Require().Equal(http.StatusOK, resp.StatusCode)
+
+	var closed pvzapi.Reception
+	s.NoError(json.NewDecoder(resp.Body).Decode(&closed))
+	resp.Body.Close()
+
+	expected := []pvzapi.Discrepancy{
+		{Type: "обувь", Kind: pvzapi.Missing, Expected: 2, Actual: 1},
+		{Type: "одежда", Kind: pvzapi.Missing, Expected: 1, Actual: 0},
+		{Type: "электроника", Kind: pvzapi.Unexpected, Expected: 0, Actual: 1},
+	}
+	s.Require().NotNil(closed.Discrepancies)
+	s.ElementsMatch(expected, *closed.Discrepancies)
+
+	resp = do(http.MethodGet, fmt.Sprintf("/receptions/%s", *opened.Id), nil)
+	s.Require().Equal(http.StatusOK, resp.StatusCode)
+
+	var stored dtos.ReceptionWithProducts
+	s.NoError(json.NewDecoder(resp.Body).Decode(&stored))
+	resp.Body.Close()
+	s.Require().NotNil(stored.Reception.Discrepancies)
+	s.ElementsMatch(expected, *stored.Reception.Discrepancies)
+}
+
+func (s *HandlersTestSuite) TestReceptionBarcodeManifest() {
+	app := server.NewServer(s.cfg, zap.NewNop(), s.dbPool)
+	ts := httptest.NewServer(app.RegisterHandlers())
+	defer ts.Close()
+
+	employeeToken, employeeID := s.LoginEmployee(ts)
+
+	pvzID := uuid.New()
+	_, err := s.dbPool.Exec(context.Background(),
+		"INSERT INTO pvzs (id, city) VALUES ($1, $2)",
+		pvzID, "Казань")
+	s.Require().NoError(err)
+
+	s.AssignEmployee(employeeID, pvzID)
+
+	do := func(method, path string, payload any) *http.Response {
+		var body []byte
+		if payload != nil {
+			body, err = json.Marshal(payload)
+			s.Require().NoError(err)
+		}
+
+		req, err := http.NewRequest(method, ts.URL+path, bytes.NewReader(body))
+		s.Require().NoError(err)
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", "Bearer "+employeeToken)
+
+		resp, err := http.DefaultClient.Do(req)
+		s.Require().NoError(err)
+
+		return resp
+	}
+
+	received, mismatched, missing, extra := "4600000000017", "4600000000024", "4600000000031", "4600000000048"
+
+	invalidManifests := map[string][]pvzapi.ManifestBarcode{
+		"duplicate barcode": {{Barcode: received, Type: "обувь"}, {Barcode: received, Type: "одежда"}},
+		"unknown type":      {{Barcode: received, Type: "мебель"}},
+		"missing type":      {{Barcode: received}},
+		"blank barcode":     {{Barcode: "  ", Type: "обувь"}},
+	}
+	for name, barcodes := range invalidManifests {
+		resp := do(http.MethodPost, "/receptions", pvzapi.PostReceptionsJSONRequestBody{
+			PvzId:            pvzID,
+			ExpectedBarcodes: &barcodes,
+		})
+		resp.Body.Close()
+		s.Equal(http.StatusBadRequest, resp.StatusCode, name)
+	}
+
+	barcodes := []pvzapi.ManifestBarcode{
+		{Barcode: received, Type: "обувь"},
+		{Barcode: mismatched, Type: "одежда"},
+		{Barcode: missing, Type: "обувь"},
+	}
+	resp := do(http.MethodPost, "/receptions", pvzapi.PostReceptionsJSONRequestBody{
+		PvzId:            pvzID,
+		ExpectedBarcodes: &barcodes,
+	})
+	s.Require().Equal(http.StatusCreated, resp.StatusCode)
+
+	var opened pvzapi.Reception
+	s.NoError(json.NewDecoder(resp.Body).Decode(&opened))
+	resp.Body.Close()
+	s.Require().NotNil(opened.ExpectedBarcodes)
+	s.ElementsMatch(barcodes, *opened.ExpectedBarcodes)
+	s.Nil(opened.BarcodeDiscrepancies)
+
+	for _, product := range []pvzapi.PostProductsJSONRequestBody{
+		{PvzId: pvzID, Type: "обувь", Barcode: &received},
+		{PvzId: pvzID, Type: "электроника", Barcode: &mismatched},
+		{PvzId: pvzID, Type: "обувь", Barcode: &extra},
+		{PvzId: pvzID, Type: "одежда"},
+	} {
+		resp = do(http.MethodPost, "/products", product)
+		resp.Body.Close()
+		s.Require().Equal(http.StatusCreated, resp.StatusCode)
+	}
+
+	resp = do(http.MethodPost, fmt.Sprintf("/pvz/%s/close_last_reception", pvzID), nil)
+	s.Require().Equal(http.StatusOK, resp.StatusCode)
+
+	var closed pvzapi.Reception
+	s.NoError(json.NewDecoder(resp.Body).Decode(&closed))
+	resp.Body.Close()
+	s.Nil(closed.Discrepancies, "no counts manifest, no counts report")
+
+	shoes, clothes, electronics := "обувь", "одежда", "электроника"
+	expected := []pvzapi.BarcodeDiscrepancy{
+		{Barcode: mismatched, Kind: pvzapi.MismatchedType, ExpectedType: &clothes, ActualType: &electronics},
+		{Barcode: missing, Kind: pvzapi.Missing, ExpectedType: &shoes},
+		{Barcode: extra, Kind: pvzapi.Extra, ActualType: &shoes},
+	}
+	s.Require().NotNil(closed.BarcodeDiscrepancies)
+	s.Equal(expected, *closed.BarcodeDiscrepancies)
+
+	resp = do(http.MethodGet, fmt.Sprintf("/receptions/%s", *opened.Id), nil)
+	s.Require().Equal(http.StatusOK, resp.StatusCode)
+
+	var stored dtos.ReceptionWithProducts
+	s.NoError(json.NewDecoder(resp.Body).Decode(&stored))
+	resp.Body.Close()
+	s.Require().NotNil(stored.Reception.ExpectedBarcodes)
+	s.ElementsMatch(barcodes, *stored.Reception.ExpectedBarcodes)
+	s.Require().NotNil(stored.Reception.BarcodeDiscrepancies)
+	s.Equal(expected, *stored.Reception.BarcodeDiscrepancies)
+}
+
+func (s *HandlersTestSuite) TestStaleReceptionsCloser() {
+	cfg := *s.cfg
+	cfg.App.StaleCheckInterval = 50 * time.Millisecond
+	cfg.App.ReceptionIdleTimeout = time.Hour
+
+	stalePVZID, activePVZID, reopenedPVZID := uuid.New(), uuid.New(), uuid.New()
+	_, err := s.dbPool.Exec(context.Background(),
+		"INSERT INTO pvzs (id, city) VALUES ($1, $4), ($2, $4), ($3, $4)",
+		stalePVZID, activePVZID, reopenedPVZID, "Москва")
+	s.Require().NoError(err)
+
+	staleID, activeID := uuid.New(), uuid.New()
+	_, err = s.dbPool.Exec(context.Background(),
+		"INSERT INTO receptions (id, pvz_id, date_time) VALUES ($1, $2, $3), ($4, $5, $3)",
+		staleID, stalePVZID, time.Now().Add(-3*time.Hour), activeID, activePVZID)
+	s.Require().NoError(err)
+
+	_, err = s.dbPool.Exec(context.Background(),
+		"INSERT INTO products (type, reception_id, date_time, line_number) VALUES ('обувь', $1, $2, 1), ('обувь', $3, $4, 1)",
+		staleID, time.Now().Add(-2*time.Hour), activeID, time.Now())
+	s.Require().NoError(err)
+
+	reopenedID := uuid.New()
+	_, err = s.dbPool.Exec(context.Background(),
+		"INSERT INTO receptions (id, pvz_id, date_time, status) VALUES ($1, $2, $3, 'close')",
+		reopenedID, reopenedPVZID, time.Now().Add(-3*time.Hour))
+	s.Require().NoError(err)
+
+	app := server.NewServer(&cfg, zap.NewNop(), s.dbPool)
+	ts := httptest.NewServer(app.RegisterHandlers())
+	defer ts.Close()
+
+	body, err := json.Marshal(pvzapi.PostReceptionsReceptionIdReopenJSONRequestBody{Reason: "closed by mistake"})
+	s.Require().NoError(err)
+
+	req, err := http.NewRequest(http.MethodPost, fmt.Sprintf("%s/receptions/%s/reopen", ts.URL, reopenedID), bytes.NewReader(body))
+	s.Require().NoError(err)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+s.Login(ts, "moderator"))
+
+	resp, err := http.DefaultClient.Do(req)
+	s.Require().NoError(err)
+	resp.Body.Close()
+	s.Require().Equal(http.StatusOK, resp.StatusCode)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	go app.RunStaleReceptionsCloser(ctx)
+
+	receptionState := func(id uuid.UUID) (string, bool) {
+		var (
+			status     string
+			autoClosed bool
+		)
+		err := s.dbPool.QueryRow(context.Background(),
+			"SELECT status, auto_closed FROM receptions WHERE id = $1", id).Scan(&status, &autoClosed)
+		s.Require().NoError(err)
+		return status, autoClosed
+	}
+
+	s.Eventually(func() bool {
+		status, autoClosed := receptionState(staleID)
+		return status == string(pvzapi.Close) && autoClosed
+	}, 5*time.Second, 50*time.Millisecond)
+
+	status, autoClosed := receptionState(activeID)
+	s.Equal(string(pvzapi.InProgress), status, "reception with recent products must stay open")
+	s.False(autoClosed)
+
+	status, autoClosed = receptionState(reopenedID)
+	s.Equal(string(pvzapi.InProgress), status, "reopened reception must stay open")
+	s.False(autoClosed)
+}
+
+func (s *HandlersTestSuite) TestReceptionsReceptionIdReopen() {
+	app := server.NewServer(s.cfg, zap.NewNop(), s.dbPool)
+	ts := httptest.NewServer(app.RegisterHandlers())
+	defer ts.Close()
+
+	moderatorToken := s.Login(ts, "moderator")
+	employeeToken := s.Login(ts, "employee")
+
+	pvzID := uuid.New()
+	_, err := s.dbPool.Exec(context.Background(),
+		"INSERT INTO pvzs (id, city) VALUES ($1, $2)",
+		pvzID, "Москва")
+	s.Require().NoError(err)
+
+	firstID, secondID := uuid.New(), uuid.New()
+	_, err = s.dbPool.Exec(context.Background(),
+		"INSERT INTO receptions (id, pvz_id, status) VALUES ($1, $3, 'close'), ($2, $3, 'close')",
+		firstID, secondID, pvzID)
+	s.Require().NoError(err)
+
+	do := func(method, path, token string, payload any) *http.Response {
+		var body []byte
+		if payload != nil {
+			body, err = json.Marshal(payload)
+			s.Require().NoError(err)
+		}
+
+		req, err := http.NewRequest(method, ts.URL+path, bytes.NewReader(body))
+		s.Require().NoError(err)
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", "Bearer "+token)
+
+		resp, err := http.DefaultClient.Do(req)
+		s.Require().NoError(err)
+
+		return resp
+	}
+
+	reopenPath := func(id uuid.UUID) string {
+		return fmt.Sprintf("/receptions/%s/reopen", id)
+	}
+	historyPath := func(id uuid.UUID) string {
+		return fmt.Sprintf("/receptions/%s/history", id)
+	}
+
+	reason := pvzapi.PostReceptionsReceptionIdReopenJSONRequestBody{Reason: "closed by mistake"}
+
+	resp := do(http.MethodPost, reopenPath(firstID), employeeToken, reason)
+	resp.Body.Close()
+	s.Equal(http.StatusForbidden, resp.StatusCode)
+
+	resp = do(http.MethodPost, reopenPath(firstID), moderatorToken,
+		pvzapi.PostReceptionsReceptionIdReopenJSONRequestBody{Reason: "  "})
+	resp.Body.Close()
+	s.Equal(http.StatusBadRequest, resp.StatusCode, "reason is required")
+
+	resp = do(http.MethodPost, reopenPath(uuid.New()), moderatorToken, reason)
+	resp.Body.Close()
+	s.Equal(http.StatusNotFound, resp.StatusCode)
+
+	resp = do(http.MethodPost, reopenPath(firstID), moderatorToken, reason)
+	s.Require().Equal(http.StatusOK, resp.StatusCode)
+
+	var reception pvzapi.Reception
+	s.NoError(json.NewDecoder(resp.Body).Decode(&reception))
+	resp.Body.Close()
+	s.Equal(pvzapi.InProgress, reception.Status)
+	s.Nil(reception.ClosedBy)
+
+	resp = do(http.MethodPost, reopenPath(firstID), moderatorToken, reason)
+	resp.Body.Close()
+	s.Equal(http.StatusBadRequest, resp.StatusCode, "reception is already open")
+
+	resp = do(http.MethodPost, reopenPath(secondID), moderatorToken, reason)
+	resp.Body.Close()
+	s.Equal(http.StatusBadRequest, resp.StatusCode, "another reception of the pvz is open")
+
+	resp = do(http.MethodGet, historyPath(firstID), employeeToken, nil)
+	resp.Body.Close()
+	s.Equal(http.StatusForbidden, resp.StatusCode)
+
+	resp = do(http.MethodGet, historyPath(uuid.New()), moderatorToken, nil)
+	resp.Body.Close()
+	s.Equal(http.StatusNotFound, resp.StatusCode)
+
+	resp = do(http.MethodGet, historyPath(firstID), moderatorToken, nil)
+	s.Require().Equal(http.StatusOK, resp.StatusCode)
+
+	var history []pvzapi.ReceptionAuditRecord
+	s.NoError(json.NewDecoder(resp.Body).Decode(&history))
+	resp.Body.Close()
+	s.Require().Len(history, 1)
+	s.Equal(pvzapi.Reopen, history[0].Action)
+	s.Equal(reason.Reason, history[0].Reason)
+	s.Equal(firstID, history[0].ReceptionId)
+
+	resp = do(http.MethodGet, historyPath(secondID), moderatorToken, nil)
+	s.Require().Equal(http.StatusOK, resp.StatusCode)
+
+	history = nil
+	s.NoError(json.NewDecoder(resp.Body).Decode(&history))
+	resp.Body.Close()
+	s.Empty(history)
+}
+
+func (s *HandlersTestSuite) TestProductBarcodes() {
+	app := server.NewServer(s.cfg, zap.NewNop(), s.dbPool)
+	ts := httptest.NewServer(app.RegisterHandlers())
+	defer ts.Close()
+
+	moderatorToken := s.Login(ts, "moderator")
+	employeeToken, employeeID := s.LoginEmployee(ts)
+
+	pvzID := uuid.New()
+	_, err := s.dbPool.Exec(context.Background(),
+		"INSERT INTO pvzs (id, city) VALUES ($1, $2)",
+		pvzID, "Москва")
+	s.Require().NoError(err)
+
+	s.AssignEmployee(employeeID, pvzID)
+
+	receptionID := uuid.New()
+	_, err = s.dbPool.Exec(context.Background(),
+		"INSERT INTO receptions (id, pvz_id) VALUES ($1, $2)",
+		receptionID, pvzID)
+	s.Require().NoError(err)
+
+	do := func(method, path, token string, payload any) *http.Response {
+		var body []byte
+		if payload != nil {
+			body, err = json.Marshal(payload)
+			s.Require().NoError(err)
+		}
+
+		req, err := http.NewRequest(method, ts.URL+path, bytes.NewReader(body))
+		s.Require().NoError(err)
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", "Bearer "+token)
+
+		resp, err := http.DefaultClient.Do(req)
+		s.Require().NoError(err)
+
+		return resp
+	}
+
+	barcode := fmt.Sprintf("46%011d", time.Now().UnixNano()%1e11)
+	product := pvzapi.PostProductsJSONRequestBody{PvzId: pvzID, Type: "обувь", Barcode: &barcode}
+
+	resp := do(http.MethodPost, "/products", employeeToken, product)
+	s.Require().Equal(http.StatusCreated, resp.StatusCode)
+
+	var created pvzapi.Product
+	s.NoError(json.NewDecoder(resp.Body).Decode(&created))
+	resp.Body.Close()
+	s.Require().NotNil(created.Barcode)
+	s.Equal(barcode, *created.Barcode)
+
+	resp = do(http.MethodPost, "/products", employeeToken, product)
+	resp.Body.Close()
+	s.Equal(http.StatusBadRequest, resp.StatusCode, "barcode is already in the reception")
+
+	blank := " "
+	resp = do(http.MethodPost, "/products", employeeToken,
+		pvzapi.PostProductsJSONRequestBody{PvzId: pvzID, Type: "обувь", Barcode: &blank})
+	resp.Body.Close()
+	s.Equal(http.StatusBadRequest, resp.StatusCode)
+
+	resp = do(http.MethodPost, "/products", employeeToken,
+		pvzapi.PostProductsJSONRequestBody{PvzId: pvzID, Type: "обувь"})
+	resp.Body.Close()
+	s.Equal(http.StatusCreated, resp.StatusCode, "barcode is optional")
+
+	resp = do(http.MethodGet, "/products", moderatorToken, nil)
+	resp.Body.Close()
+	s.Equal(http.StatusBadRequest, resp.StatusCode, "barcode is required for search")
+
+	for _, token := range []string{employeeToken, moderatorToken} {
+		resp = do(http.MethodGet, "/products?barcode="+url.QueryEscape(barcode), token, nil)
+		s.Require().Equal(http.StatusOK, resp.StatusCode)
+
+		var locations []pvzapi.ProductLocation
+		s.NoError(json.NewDecoder(resp.Body).Decode(&locations))
+		resp.Body.Close()
+		s.Require().Len(locations, 1)
+		s.Equal(*created.Id, *locations[0].Product.Id)
+		s.Equal(receptionID, *locations[0].Reception.Id)
+		s.Equal(pvzID, *locations[0].Pvz.Id)
+	}
+
+	resp = do(http.MethodGet, "/products?barcode=unknown", employeeToken, nil)
+	s.Require().Equal(http.StatusOK, resp.StatusCode)
+
+	var locations []pvzapi.ProductLocation
+	s.NoError(json.NewDecoder(resp.Body).Decode(&locations))
+	resp.Body.Close()
+	s.Empty(locations)
+}
+
+func (s *HandlersTestSuite) TestReceptionsReceptionIdProductsProductId() {
+	app := server.NewServer(s.cfg, zap.NewNop(), s.dbPool)
+	ts := httptest.NewServer(app.RegisterHandlers())
+	defer ts.Close()
+
+	moderatorToken := s.Login(ts, "moderator")
+	employeeToken, employeeID := s.LoginEmployee(ts)
+	otherToken, _ := s.LoginEmployee(ts)
+
+	pvzID := uuid.New()
+	_, err := s.dbPool.Exec(context.Background(),
+		"INSERT INTO pvzs (id, city) VALUES ($1, $2)",
+		pvzID, "Москва")
+	s.Require().NoError(err)
+
+	s.AssignEmployee(employeeID, pvzID)
+
+	openID, closedID := uuid.New(), uuid.New()
+	_, err = s.dbPool.Exec(context.Background(),
+		"INSERT INTO receptions (id, pvz_id, status) VALUES ($1, $3, 'in_progress'), ($2, $3, 'close')",
+		openID, closedID, pvzID)
+	s.Require().NoError(err)
+
+	firstID, middleID, lastID, closedProductID := uuid.New(), uuid.New(), uuid.New(), uuid.New()
+	_, err = s.dbPool.Exec(context.Background(),
+		`INSERT INTO products (id, type, reception_id, line_number) VALUES
+			($1, 'обувь', $5, 1),
+			($2, 'одежда', $5, 2),
+			($3, 'обувь', $5, 3),
+			($4, 'обувь', $6, 1)`,
+		firstID, middleID, lastID, closedProductID, openID, closedID)
+	s.Require().NoError(err)
+
+	deleteProduct := func(token string, receptionID, productID uuid.UUID) int {
+		req, err := http.NewRequest(http.MethodDelete,
+			fmt.Sprintf("%s/receptions/%s/products/%s", ts.URL, receptionID, productID), nil)
+		s.Require().NoError(err)
+		req.Header.Set("Authorization", "Bearer "+token)
+
+		resp, err := http.DefaultClient.Do(req)
+		s.Require().NoError(err)
+		resp.Body.Close()
+
+		return resp.StatusCode
+	}
+
+	s.Equal(http.StatusForbidden, deleteProduct(moderatorToken, openID, middleID))
+	s.Equal(http.StatusForbidden, deleteProduct(otherToken, openID, middleID), "employee is not assigned to the pvz")
+	s.Equal(http.StatusNotFound, deleteProduct(employeeToken, uuid.New(), middleID))
+	s.Equal(http.StatusNotFound, deleteProduct(employeeToken, openID, closedProductID), "product belongs to another reception")
+	s.Equal(http.StatusBadRequest, deleteProduct(employeeToken, closedID, closedProductID), "reception is closed")
+
+	s.Equal(http.StatusNoContent, deleteProduct(employeeToken, openID, middleID))
+	s.Equal(http.StatusNotFound, deleteProduct(employeeToken, openID, middleID), "product is already deleted")
+
+	rows, err := s.dbPool.Query(context.Background(),
+		"SELECT id FROM products WHERE reception_id = $1 ORDER BY line_number", openID)
+	s.Require().NoError(err)
+	defer rows.Close()
+
+	var remaining []uuid.UUID
+	for rows.Next() {
+		var id uuid.UUID
+		s.Require().NoError(rows.Scan(&id))
+		remaining = append(remaining, id)
+	}
+	s.Require().NoError(rows.Err())
+	s.Equal([]uuid.UUID{firstID, lastID}, remaining)
+}
+
+func (s *HandlersTestSuite) TestProductsBatch() {
+	app := server.NewServer(s.cfg, zap.NewNop(), s.dbPool)
+	ts := httptest.NewServer(app.RegisterHandlers())
+	defer ts.Close()
+
+	employeeToken, employeeID := s.LoginEmployee(ts)
+
+	pvzID := uuid.New()
+	_, err := s.dbPool.Exec(context.Background(),
+		"INSERT INTO pvzs (id, city) VALUES ($1, $2)",
+		pvzID, "Москва")
+	s.Require().NoError(err)
+
+	s.AssignEmployee(employeeID, pvzID)
+
+	batch := func(payload pvzapi.PostProductsBatchJSONRequestBody) *http.Response {
+		body, err := json.Marshal(payload)
+		s.Require().NoError(err)
+
+		req, err := http.NewRequest(http.MethodPost, ts.URL+"/products/batch", bytes.NewReader(body))
+		s.Require().NoError(err)
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", "Bearer "+employeeToken)
+
+		resp, err := http.DefaultClient.Do(req)
+		s.Require().NoError(err)
+
+		return resp
+	}
+
+	existing, fresh := "batch-existing-"+pvzID.String(), "batch-fresh-"+pvzID.String()
+	items := []pvzapi.ProductBatchItem{
+		{Type: "обувь", Barcode: &fresh},
+		{Type: "одежда", Barcode: &existing},
+		{Type: "косметика"},
+		{Type: "обувь", Barcode: &fresh},
+		{Type: "электроника"},
+	}
+
+	resp := batch(pvzapi.PostProductsBatchJSONRequestBody{PvzId: pvzID, Items: items})
+	resp.Body.Close()
+	s.Equal(http.StatusBadRequest, resp.StatusCode, "no open reception")
+
+	receptionID := uuid.New()
+	_, err = s.dbPool.Exec(context.Background(),
+		"INSERT INTO receptions (id, pvz_id, last_line_number) VALUES ($1, $2, 1)",
+		receptionID, pvzID)
+	s.Require().NoError(err)
+
+	_, err = s.dbPool.Exec(context.Background(),
+		"INSERT INTO products (type, reception_id, barcode, line_number) VALUES ('одежда', $1, $2, 1)",
+		receptionID, existing)
+	s.Require().NoError(err)
+
+	resp = batch(pvzapi.PostProductsBatchJSONRequestBody{PvzId: pvzID})
+	resp.Body.Close()
+	s.Equal(http.StatusBadRequest, resp.StatusCode, "items are required")
+
+	resp = batch(pvzapi.PostProductsBatchJSONRequestBody{PvzId: pvzID, Items: items})
+	s.Require().Equal(http.StatusOK, resp.StatusCode)
+
+	var results []pvzapi.ProductBatchResult
+	s.NoError(json.NewDecoder(resp.Body).Decode(&results))
+	resp.Body.Close()
+	s.Require().Len(results, len(items))
+
+	for i, ok := range []bool{true, false, false, false, true} {
+		if ok {
+			s.Nil(results[i].Error, "item %d", i)
+			s.Require().NotNil(results[i].Product, "item %d", i)
+			s.Equal(receptionID, results[i].Product.ReceptionId)
+			s.Equal(items[i].Type, results[i].Product.Type)
+			s.Require().NotNil(results[i].Product.LineNumber, "item %d", i)
+		} else {
+			s.NotNil(results[i].Error, "item %d", i)
+			s.Nil(results[i].Product, "item %d", i)
+		}
+	}
+
+	s.Equal(2, *results[0].Product.LineNumber, "line numbers continue after existing products")
+	s.Equal(3, *results[4].Product.LineNumber)
+
+	var productsCount int
+	err = s.dbPool.QueryRow(context.Background(),
+		"SELECT COUNT(*) FROM products WHERE reception_id = $1", receptionID).Scan(&productsCount)
+	s.Require().NoError(err)
+	s.Equal(3, productsCount)
+
+	req, err := http.NewRequest(http.MethodPost, fmt.Sprintf("%s/pvz/%s/delete_last_product", ts.URL, pvzID), nil)
+	s.Require().NoError(err)
+	req.Header.Set("Authorization", "Bearer "+employeeToken)
+
+	resp, err = http.DefaultClient.Do(req)
+	s.Require().NoError(err)
+	resp.Body.Close()
+	s.Require().Equal(http.StatusOK, resp.StatusCode)
+
+	var lastType string
+	err = s.dbPool.QueryRow(context.Background(),
+		"SELECT type FROM products WHERE reception_id = $1 ORDER BY line_number DESC LIMIT 1", receptionID).Scan(&lastType)
+	s.Require().NoError(err)
+	s.Equal("обувь", lastType, "the last batch item must be deleted first")
+
+	resp = batch(pvzapi.PostProductsBatchJSONRequestBody{PvzId: pvzID, Items: items[4:]})
+	s.Require().Equal(http.StatusOK, resp.StatusCode)
+
+	results = nil
+	s.NoError(json.NewDecoder(resp.Body).Decode(&results))
+	resp.Body.Close()
+	s.Require().Len(results, 1)
+	s.Require().NotNil(results[0].Product)
+	s.Equal(4, *results[0].Product.LineNumber, "line number of the deleted product is not reused")
+}
+
+func (s *HandlersTestSuite) TestProductLifecycle() {
+	app := server.NewServer(s.cfg, zap.NewNop(), s.dbPool)
+	ts := httptest.NewServer(app.RegisterHandlers())
+	defer ts.Close()
+
+	moderatorToken := s.Login(ts, "moderator")
+	employeeToken, employeeID := s.LoginEmployee(ts)
+	otherToken, _ := s.LoginEmployee(ts)
+
+	pvzID := uuid.New()
+	_, err := s.dbPool.Exec(context.Background(),
+		"INSERT INTO pvzs (id, city) VALUES ($1, $2)",
+		pvzID, "Москва")
+	s.Require().NoError(err)
+
+	s.AssignEmployee(employeeID, pvzID)
+
+	do := func(method, path, token string) *http.Response {
+		req, err := http.NewRequest(method, ts.URL+path, nil)
+		s.Require().NoError(err)
+		req.Header.Set("Authorization", "Bearer "+token)
+
+		resp, err := http.DefaultClient.Do(req)
+		s.Require().NoError(err)
+
+		return resp
+	}
+
+	changeStatus := func(token string, productID uuid.UUID, action string) (int, pvzapi.Product) {
+		resp := do(http.MethodPost, fmt.Sprintf("/products/%s/%s", productID, action), token)
+		defer resp.Body.Close()
+
+		var product pvzapi.Product
+		if resp.StatusCode == http.StatusOK {
+			s.Require().NoError(json.NewDecoder(resp.Body).Decode(&product))
+		}
+
+		return resp.StatusCode, product
+	}
+
+	receptionID := uuid.New()
+	_, err = s.dbPool.Exec(context.Background(),
+		"INSERT INTO receptions (id, pvz_id) VALUES ($1, $2)",
+		receptionID, pvzID)
+	s.Require().NoError(err)
+
+	issuedID, returnedID := uuid.New(), uuid.New()
+	_, err = s.dbPool.Exec(context.Background(),
+		"INSERT INTO products (id, type, reception_id, line_number) VALUES ($1, 'обувь', $3, 1), ($2, 'одежда', $3, 2)",
+		issuedID, returnedID, receptionID)
+	s.Require().NoError(err)
+
+	code, _ := changeStatus(employeeToken, issuedID, "issue")
+	s.Equal(http.StatusBadRequest, code, "product of the open reception is not stored yet")
+
+	resp := do(http.MethodPost, fmt.Sprintf("/pvz/%s/close_last_reception", pvzID), employeeToken)
+	resp.Body.Close()
+	s.Require().Equal(http.StatusOK, resp.StatusCode)
+
+	code, _ = changeStatus(moderatorToken, issuedID, "issue")
+	s.Equal(http.StatusForbidden, code)
+
+	code, _ = changeStatus(otherToken, issuedID, "issue")
+	s.Equal(http.StatusForbidden, code, "employee is not assigned to the pvz")
+
+	code, _ = changeStatus(employeeToken, uuid.New(), "issue")
+	s.Equal(http.StatusNotFound, code)
+
+	code, product := changeStatus(employeeToken, issuedID, "issue")
+	s.Require().Equal(http.StatusOK, code)
+	s.Require().NotNil(product.Status)
+	s.Equal(pvzapi.Issued, *product.Status)
+
+	code, _ = changeStatus(employeeToken, issuedID, "issue")
+	s.Equal(http.StatusBadRequest, code, "product is already issued")
+
+	code, product = changeStatus(employeeToken, returnedID, "return")
+	s.Require().Equal(http.StatusOK, code)
+	s.Equal(pvzapi.Returned, *product.Status)
+
+	code, _ = changeStatus(employeeToken, returnedID, "return")
+	s.Equal(http.StatusBadRequest, code, "product is already returned")
+
+	resp = do(http.MethodGet, fmt.Sprintf("/pvz?productStatus=%s&limit=30", pvzapi.Issued), employeeToken)
+	s.Require().Equal(http.StatusOK, resp.StatusCode)
+
+	var pvzs []dtos.PVZWithReceptions
+	s.NoError(json.NewDecoder(resp.Body).Decode(&pvzs))
+	resp.Body.Close()
+
+	for _, p := range pvzs {
+		s.NotEmpty(p.Receptions, "pvzs without products in the status are filtered out")
+		for _, reception := range p.Receptions {
+			s.NotEmpty(reception.Products, "receptions without products in the status are filtered out")
+			for _, product := range reception.Products {
+				s.Require().NotNil(product.Status)
+				s.Equal(pvzapi.Issued, *product.Status)
+			}
+		}
+	}
+
+	resp = do(http.MethodGet, "/pvz?productStatus=lost", employeeToken)
+	resp.Body.Close()
+	s.Equal(http.StatusBadRequest, resp.StatusCode)
+
+	body, err := json.Marshal(pvzapi.PostReceptionsReceptionIdReopenJSONRequestBody{Reason: "closed by mistake"})
+	s.Require().NoError(err)
+
+	req, err := http.NewRequest(http.MethodPost, fmt.Sprintf("%s/receptions/%s/reopen", ts.URL, receptionID), bytes.NewReader(body))
+	s.Require().NoError(err)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+moderatorToken)
+
+	resp, err = http.DefaultClient.Do(req)
+	s.Require().NoError(err)
+	resp.Body.Close()
+	s.Equal(http.StatusBadRequest, resp.StatusCode, "products of the reception have already left the pvz")
+}
+
+func (s *HandlersTestSuite) TestProductPickupCode() {
+	app := server.NewServer(s.cfg, zap.NewNop(), s.dbPool)
+	ts := httptest.NewServer(app.RegisterHandlers())
+	defer ts.Close()
+
+	moderatorToken := s.Login(ts, "moderator")
+	employeeToken, employeeID := s.LoginEmployee(ts)
+	otherToken, _ := s.LoginEmployee(ts)
+
+	pvzID := uuid.New()
+	_, err := s.dbPool.Exec(context.Background(),
+		"INSERT INTO pvzs (id, city) VALUES ($1, $2)",
+		pvzID, "Москва")
+	s.Require().NoError(err)
+
+	s.AssignEmployee(employeeID, pvzID)
+
+	receptionID := uuid.New()
+	_, err = s.dbPool.Exec(context.Background(),
+		"INSERT INTO receptions (id, pvz_id, status) VALUES ($1, $2, 'close')",
+		receptionID, pvzID)
+	s.Require().NoError(err)
+
+	productID := uuid.New()
+	_, err = s.dbPool.Exec(context.Background(),
+		"INSERT INTO products (id, type, reception_id, status, line_number) VALUES ($1, 'обувь', $2, 'stored', 1)",
+		productID, receptionID)
+	s.Require().NoError(err)
+
+	generateCode := func(token string, productID uuid.UUID) (int, string) {
+		req, err := http.NewRequest(http.MethodPost, fmt.Sprintf("%s/products/%s/pickup_code", ts.URL, productID), nil)
+		s.Require().NoError(err)
+		req.Header.Set("Authorization", "Bearer "+token)
+
+		resp, err := http.DefaultClient.Do(req)
+		s.Require().NoError(err)
+		defer resp.Body.Close()
+
+		var pickupCode pvzapi.PickupCode
+		if resp.StatusCode == http.StatusCreated {
+			s.Require().NoError(json.NewDecoder(resp.Body).Decode(&pickupCode))
+		}
+
+		return resp.StatusCode, pickupCode.Code
+	}
+
+	pickup := func(token string, productID uuid.UUID, code string) (int, pvzapi.Product) {
+		body, err := json.Marshal(pvzapi.PostProductsProductIdPickupJSONRequestBody{Code: code})
+		s.Require().NoError(err)
+
+		req, err := http.NewRequest(http.MethodPost, fmt.Sprintf("%s/products/%s/pickup", ts.URL, productID), bytes.NewReader(body))
+		s.Require().NoError(err)
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", "Bearer "+token)
+
+		resp, err := http.DefaultClient.Do(req)
+		s.Require().NoError(err)
+		defer resp.Body.Close()
+
+		var product pvzapi.Product
+		if resp.StatusCode == http.StatusOK {
+			s.Require().NoError(json.NewDecoder(resp.Body).Decode(&product))
+		}
+
+		return resp.StatusCode, product
+	}
+
+	status, _ := pickup(employeeToken, productID, "123456")
+	s.Equal(http.StatusBadRequest, status, "pickup code was not generated yet")
+
+	status, _ = generateCode(moderatorToken, productID)
+	s.Equal(http.StatusForbidden, status)
+
+	status, _ = generateCode(otherToken, productID)
+	s.Equal(http.StatusForbidden, status, "employee is not assigned to the pvz")
+
+	status, _ = generateCode(employeeToken, uuid.New())
+	s.Equal(http.StatusNotFound, status)
+
+	status, code := generateCode(employeeToken, productID)
+	s.Require().Equal(http.StatusCreated, status)
+	s.Len(code, 6)
+
+	wrongCode := "000000"
+	if code == wrongCode {
+		wrongCode = "000001"
+	}
+
+	status, _ = pickup(employeeToken, productID, "")
+	s.Equal(http.StatusBadRequest, status)
+
+	status, _ = pickup(otherToken, productID, code)
+	s.Equal(http.StatusForbidden, status, "employee is not assigned to the pvz")
+
+	for i := 1; i < s.cfg.App.PickupMaxAttempts; i++ {
+		status, _ = pickup(employeeToken, productID, wrongCode)
+		s.Equal(http.StatusBadRequest, status)
+	}
+
+	status, _ = pickup(employeeToken, productID, wrongCode)
+	s.Equal(http.StatusTooManyRequests, status, "last allowed attempt locks the product")
+
+	status, _ = pickup(employeeToken, productID, code)
+	s.Equal(http.StatusTooManyRequests, status, "even the correct code is rejected while locked")
+
+	status, code = generateCode(employeeToken, productID)
+	s.Require().Equal(http.StatusCreated, status)
+
+	status, product := pickup(employeeToken, productID, code)
+	s.Require().Equal(http.StatusOK, status)
+	s.Require().NotNil(product.Status)
+	s.Equal(pvzapi.Issued, *product.Status)
+
+	status, _ = pickup(employeeToken, productID, code)
+	s.Equal(http.StatusBadRequest, status, "product is already issued")
+
+	status, _ = generateCode(employeeToken, productID)
+	s.Equal(http.StatusBadRequest, status, "code is generated only for stored products")
+
+	// Parallel wrong codes cannot be checked more times than the limit allows
+	burstProductID := uuid.New()
+	_, err = s.dbPool.Exec(context.Background(),
+		"INSERT INTO products (id, type, reception_id, status, line_number) VALUES ($1, 'обувь', $2, 'stored', 2)",
+		burstProductID, receptionID)
+	s.Require().NoError(err)
+
+	status, code = generateCode(employeeToken, burstProductID)
+	s.Require().Equal(http.StatusCreated, status)
+
+	wrongCode = "000000"
+	if code == wrongCode {
+		wrongCode = "000001"
+	}
+	body, err := json.Marshal(pvzapi.PostProductsProductIdPickupJSONRequestBody{Code: wrongCode})
+	s.Require().NoError(err)
+
+	statuses := make(chan int, 4*s.cfg.App.PickupMaxAttempts)
+	var wg sync.WaitGroup
+	for i := 0; i < cap(statuses); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			req, err := http.NewRequest(http.MethodPost, fmt.Sprintf("%s/products/%s/pickup", ts.URL, burstProductID), bytes.NewReader(body))
+			if err != nil {
+				statuses <- 0
+				return
+			}
+			req.Header.Set("Content-Type", "application/json")
+			req.Header.Set("Authorization", "Bearer "+employeeToken)
+
+			resp, err := http.DefaultClient.Do(req)
+			if err != nil {
+				statuses <- 0
+				return
+			}
+			resp.Body.Close()
+			statuses <- resp.StatusCode
+		}()
+	}
+	wg.Wait()
+	close(statuses)
+
+	rejected := 0
+	for status := range statuses {
+		s.Contains([]int{http.StatusBadRequest, http.StatusTooManyRequests}, status)
+		if status == http.StatusBadRequest {
+			rejected++
+		}
+	}
+	s.Less(rejected, s.cfg.App.PickupMaxAttempts, "only attempts below the limit report a wrong code")
+
+	status, _ = pickup(employeeToken, burstProductID, code)
+	s.Equal(http.StatusTooManyRequests, status, "the burst locked the product")
+}
+
+func (s *HandlersTestSuite) TestProductTransfers() {
+	app := server.NewServer(s.cfg, zap.NewNop(), s.dbPool)
+	ts := httptest.NewServer(app.RegisterHandlers())
+	defer ts.Close()
+
+	senderToken, senderID := s.LoginEmployee(ts)
+	receiverToken, receiverID := s.LoginEmployee(ts)
+
+	fromPvzID, toPvzID := uuid.New(), uuid.New()
+	_, err := s.dbPool.Exec(context.Background(),
+		"INSERT INTO pvzs (id, city) VALUES ($1, 'Москва'), ($2, 'Казань')",
+		fromPvzID, toPvzID)
+	s.Require().NoError(err)
+
+	s.AssignEmployee(senderID, fromPvzID)
+	s.AssignEmployee(receiverID, toPvzID)
+
+	receptionID := uuid.New()
+	_, err = s.dbPool.Exec(context.Background(),
+		"INSERT INTO receptions (id, pvz_id, status) VALUES ($1, $2, 'close')",
+		receptionID, fromPvzID)
+	s.Require().NoError(err)
+
+	shoesID, clothesID, unlabeledID := uuid.New(), uuid.New(), uuid.New()
+	barcode := "TRANSFER-0001"
+	_, err = s.dbPool.Exec(context.Background(),
+		`INSERT INTO products (id, type, reception_id, barcode, status, line_number)
+		VALUES ($1, 'обувь', $4, $5, 'stored', 1), ($2, 'одежда', $4, 'TRANSFER-0002', 'stored', 2),
+			($3, 'электроника', $4, NULL, 'stored', 3)`,
+		shoesID, clothesID, unlabeledID, receptionID, barcode)
+	s.Require().NoError(err)
+
+	do := func(method, path, token string, payload any) *http.Response {
+		var body []byte
+		if payload != nil {
+			body, err = json.Marshal(payload)
+			s.Require().NoError(err)
+		}
+
+		req, err := http.NewRequest(method, ts.URL+path, bytes.NewReader(body))
+		s.Require().NoError(err)
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", "Bearer "+token)
+
+		resp, err := http.DefaultClient.Do(req)
+		s.Require().NoError(err)
+
+		return resp
+	}
+
+	transfer := func(token string, toPvzID uuid.UUID, productIDs ...uuid.UUID) int {
+		resp := do(http.MethodPost, fmt.Sprintf("/receptions/%s/transfers", receptionID), token,
+			pvzapi.PostReceptionsReceptionIdTransfersJSONRequestBody{ToPvzId: toPvzID, ProductIds: productIDs})
+		resp.Body.Close()
+
+		return resp.StatusCode
+	}
+
+	getDetails := func(pvzID uuid.UUID) dtos.PVZDetails {
+		resp := do(http.MethodGet, fmt.Sprintf("/pvz/%s", pvzID), senderToken, nil)
+		defer resp.Body.Close()
+		s.Require().Equal(http.StatusOK, resp.StatusCode)
+
+		var details dtos.PVZDetails
+		s.Require().NoError(json.NewDecoder(resp.Body).Decode(&details))
+
+		return details
+	}
+
+	s.Equal(http.StatusBadRequest, transfer(senderToken, toPvzID), "no products")
+	s.Equal(http.StatusForbidden, transfer(receiverToken, toPvzID, shoesID), "employee is not assigned to the source pvz")
+	s.Equal(http.StatusBadRequest, transfer(senderToken, fromPvzID, shoesID), "transfer to the same pvz")
+	s.Equal(http.StatusBadRequest, transfer(senderToken, uuid.New(), shoesID), "unknown destination pvz")
+	s.Equal(http.StatusNotFound, transfer(senderToken, toPvzID, shoesID, uuid.New()), "product is not in the reception")
+	s.Equal(http.StatusBadRequest, transfer(senderToken, toPvzID, shoesID, unlabeledID), "product without a barcode cannot be confirmed by the receiver")
+
+	resp := do(http.MethodPost, fmt.Sprintf("/receptions/%s/transfers", receptionID), senderToken,
+		pvzapi.PostReceptionsReceptionIdTransfersJSONRequestBody{ToPvzId: toPvzID, ProductIds: []uuid.UUID{shoesID, clothesID}})
+	s.Require().Equal(http.StatusCreated, resp.StatusCode)
+
+	var transfers []pvzapi.ProductTransfer
+	s.NoError(json.NewDecoder(resp.Body).Decode(&transfers))
+	resp.Body.Close()
+	s.Require().Len(transfers, 2)
+	s.Equal(shoesID, transfers[0].ProductId)
+	s.Equal(pvzapi.InTransit, transfers[0].Status)
+
+	s.Equal(http.StatusBadRequest, transfer(senderToken, toPvzID, shoesID), "product has already left the pvz")
+
+	s.Len(getDetails(fromPvzID).OutboundTransfers, 2)
+	s.Len(getDetails(toPvzID).InboundTransfers, 2)
+
+	resp = do(http.MethodPost, "/receptions", receiverToken, pvzapi.PostReceptionsJSONRequestBody{PvzId: toPvzID})
+	s.Require().Equal(http.StatusCreated, resp.StatusCode)
+
+	var opened pvzapi.Reception
+	s.NoError(json.NewDecoder(resp.Body).Decode(&opened))
+	resp.Body.Close()
+	s.Require().NotNil(opened.Manifest)
+	s.ElementsMatch([]pvzapi.ManifestItem{
+		{Type: "обувь", Count: 1},
+		{Type: "одежда", Count: 1},
+	}, *opened.Manifest)
+
+	resp = do(http.MethodPost, "/products", receiverToken, pvzapi.PostProductsJSONRequestBody{
+		PvzId:   toPvzID,
+		Type:    "обувь",
+		Barcode: &barcode,
+	})
+	resp.Body.Close()
+	s.Require().Equal(http.StatusCreated, resp.StatusCode)
+
+	inbound := getDetails(toPvzID).InboundTransfers
+	s.Require().Len(inbound, 1, "scanned product confirms its transfer")
+	s.Equal(clothesID, inbound[0].ProductId)
+	s.Require().NotNil(inbound[0].ReceptionId)
+	s.Equal(*opened.Id, *inbound[0].ReceptionId)
+	s.Len(getDetails(fromPvzID).OutboundTransfers, 1)
+
+	resp = do(http.MethodPost, fmt.Sprintf("/pvz/%s/close_last_reception", toPvzID), receiverToken, nil)
+	s.Require().Equal(http.StatusOK, resp.StatusCode)
+
+	var closed pvzapi.Reception
+	s.NoError(json.NewDecoder(resp.Body).Decode(&closed))
+	resp.Body.Close()
+	s.Require().NotNil(closed.Discrepancies)
+	s.Equal([]pvzapi.Discrepancy{
+		{Type: "одежда", Kind: pvzapi.Missing, Expected: 1, Actual: 0},
+	}, *closed.Discrepancies)
+}
+
+func (s *HandlersTestSuite) TestStorageCells() {
+	app := server.NewServer(s.cfg, zap.NewNop(), s.dbPool)
+	ts := httptest.NewServer(app.RegisterHandlers())
+	defer ts.Close()
+
+	moderatorToken := s.Login(ts, "moderator")
+	employeeToken, employeeID := s.LoginEmployee(ts)
+
+	pvzID := uuid.New()
+	_, err := s.dbPool.Exec(context.Background(), "INSERT INTO pvzs (id, city) VALUES ($1, 'Москва')", pvzID)
+	s.Require().NoError(err)
+
+	s.AssignEmployee(employeeID, pvzID)
+
+	do := func(method, path, token string, payload any) *http.Response {
+		var body []byte
+		if payload != nil {
+			body, err = json.Marshal(payload)
+			s.Require().NoError(err)
+		}
+
+		req, err := http.NewRequest(method, ts.URL+path, bytes.NewReader(body))
+		s.Require().NoError(err)
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", "Bearer "+token)
+
+		resp, err := http.DefaultClient.Do(req)
+		s.Require().NoError(err)
+
+		return resp
+	}
+
+	addProduct := func(cellNumber *int) (int, pvzapi.Product) {
+		resp := do(http.MethodPost, "/products", employeeToken, pvzapi.PostProductsJSONRequestBody{
+			PvzId:      pvzID,
+			Type:       "обувь",
+			CellNumber: cellNumber,
+		})
+		defer resp.Body.Close()
+
+		var product pvzapi.Product
+		if resp.StatusCode == http.StatusCreated {
+			s.Require().NoError(json.NewDecoder(resp.Body).Decode(&product))
+		}
+
+		return resp.StatusCode, product
+	}
+
+	getCells := func() pvzapi.CellOccupancy {
+		resp := do(http.MethodGet, fmt.Sprintf("/pvz/%s/cells", pvzID), moderatorToken, nil)
+		defer resp.Body.Close()
+		s.Require().Equal(http.StatusOK, resp.StatusCode)
+
+		var occupancy pvzapi.CellOccupancy
+		s.Require().NoError(json.NewDecoder(resp.Body).Decode(&occupancy))
+
+		return occupancy
+	}
+
+	cellsBody := pvzapi.PostPvzPvzIdCellsJSONRequestBody{Count: 2, Capacity: 1}
+
+	resp := do(http.MethodPost, fmt.Sprintf("/pvz/%s/cells", pvzID), employeeToken, cellsBody)
+	resp.Body.Close()
+	s.Equal(http.StatusForbidden, resp.StatusCode, "employee cannot create cells")
+
+	resp = do(http.MethodPost, fmt.Sprintf("/pvz/%s/cells", uuid.New()), moderatorToken, cellsBody)
+	resp.Body.Close()
+	s.Equal(http.StatusNotFound, resp.StatusCode)
+
+	resp = do(http.MethodPost, fmt.Sprintf("/pvz/%s/cells", pvzID), moderatorToken, cellsBody)
+	s.Require().Equal(http.StatusCreated, resp.StatusCode)
+
+	var cells []pvzapi.StorageCell
+	s.NoError(json.NewDecoder(resp.Body).Decode(&cells))
+	resp.Body.Close()
+	s.Require().Len(cells, 2)
+	s.Equal(1, cells[0].Number)
+	s.Equal(2, cells[1].Number)
+
+	resp = do(http.MethodPost, "/receptions", employeeToken, pvzapi.PostReceptionsJSONRequestBody{PvzId: pvzID})
+	resp.Body.Close()
+	s.Require().Equal(http.StatusCreated, resp.StatusCode)
+
+	second := 2
+	status, product := addProduct(&second)
+	s.Require().Equal(http.StatusCreated, status)
+	s.Require().NotNil(product.CellNumber)
+	s.Equal(2, *product.CellNumber)
+
+	status, _ = addProduct(&second)
+	s.Equal(http.StatusBadRequest, status, "requested cell is full")
+
+	missing := 3
+	status, _ = addProduct(&missing)
+	s.Equal(http.StatusBadRequest, status, "requested cell does not exist")
+
+	status, auto := addProduct(nil)
+	s.Require().Equal(http.StatusCreated, status)
+	s.Require().NotNil(auto.CellNumber)
+	s.Equal(1, *auto.CellNumber, "first cell with free space is picked")
+
+	status, unassigned := addProduct(nil)
+	s.Require().Equal(http.StatusCreated, status, "product is accepted when all cells are full")
+	s.Nil(unassigned.CellNumber)
+
+	occupancy := getCells()
+	s.Equal(2, occupancy.Capacity)
+	s.Equal(2, occupancy.Occupied)
+	s.Equal(1, occupancy.Unassigned)
+
+	resp = do(http.MethodPost, fmt.Sprintf("/products/%s/move", *product.Id), employeeToken,
+		pvzapi.PostProductsProductIdMoveJSONRequestBody{CellNumber: 1})
+	resp.Body.Close()
+	s.Equal(http.StatusBadRequest, resp.StatusCode, "target cell is full")
+
+	resp = do(http.MethodPost, fmt.Sprintf("/pvz/%s/cells", pvzID), moderatorToken,
+		pvzapi.PostPvzPvzIdCellsJSONRequestBody{Count: 1, Capacity: 5})
+	s.Require().Equal(http.StatusCreated, resp.StatusCode)
+	s.NoError(json.NewDecoder(resp.Body).Decode(&cells))
+	resp.Body.Close()
+	s.Require().Len(cells, 1)
+	s.Equal(3, cells[0].Number, "numbering continues after existing cells")
+
+	resp = do(http.MethodPost, fmt.Sprintf("/products/%s/move", *unassigned.Id), employeeToken,
+		pvzapi.PostProductsProductIdMoveJSONRequestBody{CellNumber: 3})
+	s.Require().Equal(http.StatusOK, resp.StatusCode)
+
+	var moved pvzapi.Product
+	s.NoError(json.NewDecoder(resp.Body).Decode(&moved))
+	resp.Body.Close()
+	s.Require().NotNil(moved.CellNumber)
+	s.Equal(3, *moved.CellNumber)
+
+	resp = do(http.MethodPost, fmt.Sprintf("/products/%s/move", uuid.New()), employeeToken,
+		pvzapi.PostProductsProductIdMoveJSONRequestBody{CellNumber: 3})
+	resp.Body.Close()
+	s.Equal(http.StatusNotFound, resp.StatusCode)
+
+	occupancy = getCells()
+	s.Require().Len(occupancy.Cells, 3)
+	s.Equal(7, occupancy.Capacity)
+	s.Equal(3, occupancy.Occupied)
+	s.Equal(0, occupancy.Unassigned)
+	s.Equal(1, occupancy.Cells[2].Occupied)
+}
+
+func (s *HandlersTestSuite) TestPvzCapacity() {
+	app := server.NewServer(s.cfg, zap.NewNop(), s.dbPool)
+	ts := httptest.NewServer(app.RegisterHandlers())
+	defer ts.Close()
+
+	moderatorToken := s.Login(ts, "moderator")
+	employeeToken, employeeID := s.LoginEmployee(ts)
+
+	pvzID := uuid.New()
+	_, err := s.dbPool.Exec(context.Background(), "INSERT INTO pvzs (id, city) VALUES ($1, 'Москва')", pvzID)
+	s.Require().NoError(err)
+
+	s.AssignEmployee(employeeID, pvzID)
+
+	do := func(method, path, token string, payload any) *http.Response {
+		var body []byte
+		if payload != nil {
+			body, err = json.Marshal(payload)
+			s.Require().NoError(err)
+		}
+
+		req, err := http.NewRequest(method, ts.URL+path, bytes.NewReader(body))
+		s.Require().NoError(err)
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", "Bearer "+token)
+
+		resp, err := http.DefaultClient.Do(req)
+		s.Require().NoError(err)
+
+		return resp
+	}
+
+	addProduct := func() int {
+		resp := do(http.MethodPost, "/products", employeeToken, pvzapi.PostProductsJSONRequestBody{
+			PvzId: pvzID,
+			Type:  "обувь",
+		})
+		resp.Body.Close()
+
+		return resp.StatusCode
+	}
+
+	getCapacity := func() pvzapi.PVZCapacity {
+		resp := do(http.MethodGet, fmt.Sprintf("/pvz/%s/capacity", pvzID), employeeToken, nil)
+		defer resp.Body.Close()
+		s.Require().Equal(http.StatusOK, resp.StatusCode)
+
+		var capacity pvzapi.PVZCapacity
+		s.Require().NoError(json.NewDecoder(resp.Body).Decode(&capacity))
+
+		return capacity
+	}
+
+	capacity := getCapacity()
+	s.Nil(capacity.Capacity, "pvz is unlimited by default")
+	s.Nil(capacity.ReceptionLimit)
+	s.Equal(0, capacity.Stored)
+
+	three, two, zero := 3, 2, 0
+	limits := pvzapi.PutPvzPvzIdCapacityJSONRequestBody{Capacity: &three, ReceptionLimit: &two}
+
+	resp := do(http.MethodPut, fmt.Sprintf("/pvz/%s/capacity", pvzID), employeeToken, limits)
+	resp.Body.Close()
+	s.Equal(http.StatusForbidden, resp.StatusCode, "employee cannot set capacity")
+
+	resp = do(http.MethodPut, fmt.Sprintf("/pvz/%s/capacity", pvzID), moderatorToken,
+		pvzapi.PutPvzPvzIdCapacityJSONRequestBody{Capacity: &zero})
+	resp.Body.Close()
+	s.Equal(http.StatusBadRequest, resp.StatusCode)
+
+	resp = do(http.MethodPut, fmt.Sprintf("/pvz/%s/capacity", uuid.New()), moderatorToken, limits)
+	resp.Body.Close()
+	s.Equal(http.StatusNotFound, resp.StatusCode)
+
+	resp = do(http.MethodPut, fmt.Sprintf("/pvz/%s/capacity", pvzID), moderatorToken, limits)
+	s.Require().Equal(http.StatusOK, resp.StatusCode)
+	s.NoError(json.NewDecoder(resp.Body).Decode(&capacity))
+	resp.Body.Close()
+	s.Require().NotNil(capacity.Capacity)
+	s.Require().NotNil(capacity.ReceptionLimit)
+	s.Equal(3, *capacity.Capacity)
+	s.Equal(2, *capacity.ReceptionLimit)
+
+	resp = do(http.MethodPost, "/receptions", employeeToken, pvzapi.PostReceptionsJSONRequestBody{PvzId: pvzID})
+	resp.Body.Close()
+	s.Require().Equal(http.StatusCreated, resp.StatusCode)
+
+	s.Require().Equal(http.StatusCreated, addProduct())
+	s.Require().Equal(http.StatusCreated, addProduct())
+	s.Equal(http.StatusConflict, addProduct(), "reception limit reached")
+
+	resp = do(http.MethodPost, fmt.Sprintf("/pvz/%s/close_last_reception", pvzID), employeeToken, nil)
+	resp.Body.Close()
+	s.Require().Equal(http.StatusOK, resp.StatusCode)
+
+	resp = do(http.MethodPost, "/receptions", employeeToken, pvzapi.PostReceptionsJSONRequestBody{PvzId: pvzID})
+	resp.Body.Close()
+	s.Require().Equal(http.StatusCreated, resp.StatusCode)
+
+	resp = do(http.MethodPost, "/products/batch", employeeToken, pvzapi.PostProductsBatchJSONRequestBody{
+		PvzId: pvzID,
+		Items: []pvzapi.ProductBatchItem{{Type: "обувь"}, {Type: "обувь"}},
+	})
+	s.Require().Equal(http.StatusOK, resp.StatusCode)
+
+	var results []pvzapi.ProductBatchResult
+	s.NoError(json.NewDecoder(resp.Body).Decode(&results))
+	resp.Body.Close()
+	s.Require().Len(results, 2)
+	s.Nil(results[0].Error)
+	s.NotNil(results[1].Error, "pvz is full")
+
+	capacity = getCapacity()
+	s.Equal(3, capacity.Stored)
+
+	resp = do(http.MethodPut, fmt.Sprintf("/pvz/%s/capacity", pvzID), moderatorToken, pvzapi.PutPvzPvzIdCapacityJSONRequestBody{})
+	resp.Body.Close()
+	s.Require().Equal(http.StatusOK, resp.StatusCode)
+
+	s.Equal(http.StatusCreated, addProduct(), "limits removed")
+}
+
+func (s *HandlersTestSuite) TestReceptionProductsPages() {
+	app := server.NewServer(s.cfg, zap.NewNop(), s.dbPool)
+	ts := httptest.NewServer(app.RegisterHandlers())
+	defer ts.Close()
+
+	moderatorToken := s.Login(ts, "moderator")
+
+	pvzID, receptionID := uuid.New(), uuid.New()
+	receptionDate := time.Date(2001, 2, 3, 4, 5, 6, 0, time.UTC)
+	_, err := s.dbPool.Exec(context.Background(), "INSERT INTO pvzs (id, city) VALUES ($1, 'Москва')", pvzID)
+	s.Require().NoError(err)
+
+	_, err = s.dbPool.Exec(context.Background(),
+		"INSERT INTO receptions (id, pvz_id, date_time) VALUES ($1, $2, $3)",
+		receptionID, pvzID, receptionDate)
+	s.Require().NoError(err)
+
+	_, err = s.dbPool.Exec(context.Background(),
+		`INSERT INTO products (type, reception_id, line_number) VALUES
+			('обувь', $1, 1), ('одежда', $1, 2), ('обувь', $1, 3), ('обувь', $1, 4), ('электроника', $1, 5)`,
+		receptionID)
+	s.Require().NoError(err)
+
+	get := func(path, token string) *http.Response {
+		req, err := http.NewRequest(http.MethodGet, ts.URL+path, nil)
+		s.Require().NoError(err)
+		req.Header.Set("Authorization", "Bearer "+token)
+
+		resp, err := http.DefaultClient.Do(req)
+		s.Require().NoError(err)
+
+		return resp
+	}
+
+	getPage := func(query string) pvzapi.ProductPage {
+		resp := get(fmt.Sprintf("/receptions/%s/products?%s", receptionID, query), moderatorToken)
+		defer resp.Body.Close()
+		s.Require().Equal(http.StatusOK, resp.StatusCode)
+
+		var page pvzapi.ProductPage
+		s.Require().NoError(json.NewDecoder(resp.Body).Decode(&page))
+
+		return page
+	}
+
+	lines := func(page pvzapi.ProductPage) []int {
+		result := make([]int, len(page.Products))
+		for i, product := range page.Products {
+			s.Require().NotNil(product.LineNumber)
+			result[i] = *product.LineNumber
+		}
+		return result
+	}
+
+	resp := get(fmt.Sprintf("/receptions/%s/products", uuid.New()), moderatorToken)
+	resp.Body.Close()
+	s.Equal(http.StatusNotFound, resp.StatusCode)
+
+	page := getPage("limit=2")
+	s.Equal([]int{1, 2}, lines(page))
+	s.Require().NotNil(page.NextCursor)
+
+	page = getPage(fmt.Sprintf("limit=2&cursor=%d", *page.NextCursor))
+	s.Equal([]int{3, 4}, lines(page))
+	s.Require().NotNil(page.NextCursor)
+
+	page = getPage(fmt.Sprintf("limit=2&cursor=%d", *page.NextCursor))
+	s.Equal([]int{5}, lines(page))
+	s.Nil(page.NextCursor)
+
+	page = getPage("type=" + url.QueryEscape("обувь") + "&cursor=1")
+	s.Equal([]int{3, 4}, lines(page))
+	s.Nil(page.NextCursor)
+
+	date := url.QueryEscape(receptionDate.Format(time.RFC3339))
+	resp = get(fmt.Sprintf("/pvz?productCounts=true&startDate=%s&endDate=%s", date, date), moderatorToken)
+	s.Require().Equal(http.StatusOK, resp.StatusCode)
+
+	var pvzs []dtos.PVZWithReceptionCounts
+	s.NoError(json.NewDecoder(resp.Body).Decode(&pvzs))
+	resp.Body.Close()
+
+	var found bool
+	for _, pvz := range pvzs {
+		for _, reception := range pvz.Receptions {
+			if reception.Reception.Id != nil && *reception.Reception.Id == receptionID {
+				found = true
+				s.Equal(5, reception.ProductsCount)
+			}
+		}
+	}
+	s.True(found, "reception must be listed with its product count")
+}
+
+func (s *HandlersTestSuite) TestTokenRefreshAndLogout() {
+	app := server.NewServer(s.cfg, zap.NewNop(), s.dbPool)
+	ts := httptest.NewServer(app.RegisterHandlers())
+	defer ts.Close()
+
+	email := fmt.Sprintf("refresh-%s@example.com", uuid.New())
+
+	post := func(path, token string, body any) *http.Response {
+		payload, err := json.Marshal(body)
+		s.Require().NoError(err)
+
+		req, err := http.NewRequest(http.MethodPost, ts.URL+path, bytes.NewReader(payload))
+		s.Require().NoError(err)
+		req.Header.Set("Content-Type", "application/json")
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+
+		resp, err := http.DefaultClient.Do(req)
+		s.Require().NoError(err)
+
+		return resp
+	}
+
+	decodeTokens := func(resp *http.Response) pvzapi.TokenPair {
+		defer resp.Body.Close()
+		s.Require().Equal(http.StatusOK, resp.StatusCode)
+
+		var tokens pvzapi.TokenPair
+		s.Require().NoError(json.NewDecoder(resp.Body).Decode(&tokens))
+		s.Require().NotEmpty(tokens.Token)
+		s.Require().NotEmpty(tokens.RefreshToken)
+
+		return tokens
+	}
+
+	resp := post("/register", "", pvzapi.PostRegisterJSONBody{
+		Email:    openapi_types.Email(email),
+		Password: "password",
+		Role:     pvzapi.Moderator,
+	})
+	resp.Body.Close()
+	s.Require().Equal(http.StatusCreated, resp.StatusCode)
+
+	first := decodeTokens(post("/login", "", pvzapi.PostLoginJSONBody{
+		Email:    openapi_types.Email(email),
+		Password: "password",
+	}))
+
+	// The refresh token is rotated and the new access token works
+	second := decodeTokens(post("/token/refresh", "", pvzapi.PostTokenRefreshJSONBody{RefreshToken: first.RefreshToken}))
+	s.NotEqual(first.RefreshToken, second.RefreshToken)
+
+	resp = post("/pvz", second.Token, pvzapi.PostPvzJSONRequestBody{City: "Москва"})
+	resp.Body.Close()
+	s.Equal(http.StatusCreated, resp.StatusCode)
+
+	// Reusing the rotated token ends every session of the user
+	resp = post("/token/refresh", "", pvzapi.PostTokenRefreshJSONBody{RefreshToken: first.RefreshToken})
+	resp.Body.Close()
+	s.Equal(http.StatusUnauthorized, resp.StatusCode)
+
+	resp = post("/token/refresh", "", pvzapi.PostTokenRefreshJSONBody{RefreshToken: second.RefreshToken})
+	resp.Body.Close()
+	s.Equal(http.StatusUnauthorized, resp.StatusCode)
+
+	resp = post("/token/refresh", "", pvzapi.PostTokenRefreshJSONBody{RefreshToken: "unknown"})
+	resp.Body.Close()
+	s.Equal(http.StatusUnauthorized, resp.StatusCode)
+
+	// Logout revokes both the access token and the refresh token
+	third := decodeTokens(post("/login", "", pvzapi.PostLoginJSONBody{
+		Email:    openapi_types.Email(email),
+		Password: "password",
+	}))
+
+	resp = post("/logout", third.Token, pvzapi.PostLogoutJSONBody{RefreshToken: &third.RefreshToken})
+	resp.Body.Close()
+	s.Equal(http.StatusNoContent, resp.StatusCode)
+
+	resp = post("/pvz", third.Token, pvzapi.PostPvzJSONRequestBody{City: "Москва"})
+	resp.Body.Close()
+	s.Equal(http.StatusForbidden, resp.StatusCode)
+
+	resp = post("/token/refresh", "", pvzapi.PostTokenRefreshJSONBody{RefreshToken: third.RefreshToken})
+	resp.Body.Close()
+	s.Equal(http.StatusUnauthorized, resp.StatusCode)
+
+	// Dummy tokens can be revoked as well
+	dummyToken := s.Login(ts, "moderator")
+
+	resp = post("/logout", dummyToken, pvzapi.PostLogoutJSONBody{})
+	resp.Body.Close()
+	s.Equal(http.StatusNoContent, resp.StatusCode)
+
+	resp = post("/pvz", dummyToken, pvzapi.PostPvzJSONRequestBody{City: "Москва"})
+	resp.Body.Close()
+	s.Equal(http.StatusForbidden, resp.StatusCode)
+}
+
+func (s *HandlersTestSuite) TestJwks() {
+	app := server.NewServer(s.cfg, zap.NewNop(), s.dbPool)
+	ts := httptest.NewServer(app.RegisterHandlers())
+	defer ts.Close()
+
+	resp, err := http.Get(ts.URL + "/.well-known/jwks.json")
+	s.Require().NoError(err)
+	defer resp.Body.Close()
+
+	s.Require().Equal(http.StatusOK, resp.StatusCode)
+
+	// The local config signs with the HMAC secret only, so there are no public keys
+	var jwks pvzapi.JWKS
+	s.Require().NoError(json.NewDecoder(resp.Body).Decode(&jwks))
+	s.NotNil(jwks.Keys)
+	s.Empty(jwks.Keys)
+}
+
+func (s *HandlersTestSuite) TestUserPermissions() {
+	app := server.NewServer(s.cfg, zap.NewNop(), s.dbPool)
+	ts := httptest.NewServer(app.RegisterHandlers())
+	defer ts.Close()
+
+	adminToken, adminID := s.LoginAdmin(ts)
+	moderatorToken := s.Login(ts, "moderator")
+	employeeToken, employeeID := s.LoginEmployee(ts)
+
+	do := func(method, path, token string) *http.Response {
+		req, err := http.NewRequest(method, ts.URL+path, nil)
+		s.Require().NoError(err)
+		req.Header.Set("Authorization", "Bearer "+token)
+
+		resp, err := http.DefaultClient.Do(req)
+		s.Require().NoError(err)
+
+		return resp
+	}
+
+	historyPath := fmt.Sprintf("/receptions/%s/history", uuid.New())
+	grantPath := fmt.Sprintf("/users/%s/permissions/receptions:history", employeeID)
+
+	resp := do(http.MethodGet, historyPath, employeeToken)
+	resp.Body.Close()
+	s.Equal(http.StatusForbidden, resp.StatusCode, "employees cannot read the history by default")
+
+	resp = do(http.MethodPut, grantPath, moderatorToken)
+	resp.Body.Close()
+	s.Equal(http.StatusForbidden, resp.StatusCode, "only admins manage permissions")
+
+	resp = do(http.MethodPut, fmt.Sprintf("/users/%s/permissions/receptions:delete", employeeID), adminToken)
+	resp.Body.Close()
+	s.Equal(http.StatusBadRequest, resp.StatusCode)
+
+	resp = do(http.MethodPut, fmt.Sprintf("/users/%s/permissions/receptions:history", uuid.New()), adminToken)
+	resp.Body.Close()
+	s.Equal(http.StatusNotFound, resp.StatusCode)
+
+	resp = do(http.MethodPut, grantPath, adminToken)
+	var grant pvzapi.PermissionGrant
+	s.Require().NoError(json.NewDecoder(resp.Body).Decode(&grant))
+	resp.Body.Close()
+	s.Require().Equal(http.StatusOK, resp.StatusCode)
+	s.Equal(employeeID, grant.UserId)
+	s.Equal("receptions:history", grant.Permission)
+	s.Require().NotNil(grant.GrantedBy)
+	s.Equal(adminID, *grant.GrantedBy)
+
+	resp = do(http.MethodGet, fmt.Sprintf("/users/%s/permissions", employeeID), adminToken)
+	var grants []pvzapi.PermissionGrant
+	s.Require().NoError(json.NewDecoder(resp.Body).Decode(&grants))
+	resp.Body.Close()
+	s.Equal(http.StatusOK, resp.StatusCode)
+	s.Require().Len(grants, 1)
+	s.Equal("receptions:history", grants[0].Permission)
+
+	resp = do(http.MethodGet, historyPath, employeeToken)
+	resp.Body.Close()
+	s.Equal(http.StatusNotFound, resp.StatusCode, "granted permission lets the employee through")
+
+	resp = do(http.MethodDelete, grantPath, adminToken)
+	resp.Body.Close()
+	s.Equal(http.StatusNoContent, resp.StatusCode)
+
+	resp = do(http.MethodDelete, grantPath, adminToken)
+	resp.Body.Close()
+	s.Equal(http.StatusNotFound, resp.StatusCode)
+
+	resp = do(http.MethodGet, historyPath, employeeToken)
+	resp.Body.Close()
+	s.Equal(http.StatusForbidden, resp.StatusCode, "revoked permission is no longer honored")
+}
+
+func (s *HandlersTestSuite) TestUserManagement() {
+	app := server.NewServer(s.cfg, zap.NewNop(), s.dbPool)
+	ts := httptest.NewServer(app.RegisterHandlers())
+	defer ts.Close()
+
+	moderatorToken := s.Login(ts, "moderator")
+	employeeToken, employeeID := s.LoginEmployee(ts)
+
+	do := func(method, path, token string, payload any) *http.Response {
+		var body []byte
+		if payload != nil {
+			var err error
+			body, err = json.Marshal(payload)
+			s.Require().NoError(err)
+		}
+
+		req, err := http.NewRequest(method, ts.URL+path, bytes.NewReader(body))
+		s.Require().NoError(err)
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", "Bearer "+token)
+
+		resp, err := http.DefaultClient.Do(req)
+		s.Require().NoError(err)
+
+		return resp
+	}
+
+	userPath := fmt.Sprintf("/users/%s", employeeID)
+
+	resp := do(http.MethodGet, "/users", employeeToken, nil)
+	resp.Body.Close()
+	s.Equal(http.StatusForbidden, resp.StatusCode)
+
+	resp = do(http.MethodGet, userPath, moderatorToken, nil)
+	var user pvzapi.User
+	s.Require().NoError(json.NewDecoder(resp.Body).Decode(&user))
+	resp.Body.Close()
+	s.Require().Equal(http.StatusOK, resp.StatusCode)
+	s.NotNil(user.CreatedAt)
+	s.NotNil(user.LastLoginAt)
+	s.Nil(user.DeactivatedAt)
+
+	resp = do(http.MethodGet, "/users?role=employee&search="+url.QueryEscape(string(user.Email)), moderatorToken, nil)
+	var users []pvzapi.User
+	s.Require().NoError(json.NewDecoder(resp.Body).Decode(&users))
+	resp.Body.Close()
+	s.Equal(http.StatusOK, resp.StatusCode)
+	s.Require().Len(users, 1)
+	s.Equal(employeeID, *users[0].Id)
+
+	resp = do(http.MethodGet, fmt.Sprintf("/users/%s", uuid.New()), moderatorToken, nil)
+	resp.Body.Close()
+	s.Equal(http.StatusNotFound, resp.StatusCode)
+
+	resp = do(http.MethodPatch, userPath, moderatorToken, map[string]string{"role": "superuser"})
+	resp.Body.Close()
+	s.Equal(http.StatusBadRequest, resp.StatusCode)
+
+	resp = do(http.MethodPatch, userPath, moderatorToken, pvzapi.PatchUsersUserIdJSONRequestBody{Role: pvzapi.PatchUsersUserIdJSONBodyRoleAdmin})
+	resp.Body.Close()
+	s.Equal(http.StatusForbidden, resp.StatusCode, "only admins hand out the admin role")
+
+	resp = do(http.MethodPost, userPath+"/deactivate", moderatorToken, nil)
+	s.Require().NoError(json.NewDecoder(resp.Body).Decode(&user))
+	resp.Body.Close()
+	s.Equal(http.StatusOK, resp.StatusCode)
+	s.NotNil(user.DeactivatedAt)
+
+	resp = do(http.MethodGet, "/pvz", employeeToken, nil)
+	resp.Body.Close()
+	s.Equal(http.StatusForbidden, resp.StatusCode, "issued tokens stop working after deactivation")
+
+	loginBody, err := json.Marshal(pvzapi.PostLoginJSONBody{Email: user.Email, Password: "password"})
+	s.Require().NoError(err)
+
+	resp, err = http.Post(ts.URL+"/login", "application/json", bytes.NewReader(loginBody))
+	s.Require().NoError(err)
+	resp.Body.Close()
+	s.Equal(http.StatusForbidden, resp.StatusCode, "deactivated users cannot log in")
+
+	resp = do(http.MethodPost, userPath+"/activate", moderatorToken, nil)
+	s.Require().NoError(json.NewDecoder(resp.Body).Decode(&user))
+	resp.Body.Close()
+	s.Equal(http.StatusOK, resp.StatusCode)
+	s.Nil(user.DeactivatedAt)
+
+	resp = do(http.MethodGet, "/pvz", employeeToken, nil)
+	resp.Body.Close()
+	s.Equal(http.StatusOK, resp.StatusCode)
+
+	resp = do(http.MethodPatch, userPath, moderatorToken, pvzapi.PatchUsersUserIdJSONRequestBody{Role: pvzapi.PatchUsersUserIdJSONBodyRoleModerator})
+	s.Require().NoError(json.NewDecoder(resp.Body).Decode(&user))
+	resp.Body.Close()
+	s.Equal(http.StatusOK, resp.StatusCode)
+	s.Equal(pvzapi.UserRoleModerator, user.Role)
+
+	resp = do(http.MethodGet, "/pvz", employeeToken, nil)
+	resp.Body.Close()
+	s.Equal(http.StatusForbidden, resp.StatusCode, "tokens issued for the old role stop working")
+}
+
+func (s *HandlersTestSuite) TestLoginLockout() {
+	app := server.NewServer(s.cfg, zap.NewNop(), s.dbPool)
+	ts := httptest.NewServer(app.RegisterHandlers())
+	defer ts.Close()
+
+	testPassword := "password"
+	hashedPassword, err := argon2id.CreateHash(testPassword, argon2id.DefaultParams)
+	s.Require().NoError(err)
+
+	email := fmt.Sprintf("lockout-%s@test.com", uuid.New())
+	_, err = s.dbPool.Exec(
+		context.Background(),
+		"INSERT INTO users (email, password_hash, role) VALUES ($1, $2, $3)",
+		email,
+		hashedPassword,
+		pvzapi.Employee,
+	)
+	s.Require().NoError(err)
+
+	login := func(email, password string) (int, pvzapi.Error) {
+		body, err := json.Marshal(pvzapi.PostLoginJSONRequestBody{
+			Email:    openapi_types.Email(email),
+			Password: password,
+		})
+		s.Require().NoError(err)
+
+		resp, err := http.Post(ts.URL+"/login", "application/json", bytes.NewReader(body))
+		s.Require().NoError(err)
+		defer resp.Body.Close()
+
+		var errResp pvzapi.Error
+		if resp.StatusCode != http.StatusOK {
+			s.Require().NoError(json.NewDecoder(resp.Body).Decode(&errResp))
+		}
+
+		return resp.StatusCode, errResp
+	}
+
+	// Unknown emails and wrong passwords are indistinguishable
+	unknownStatus, unknownErr := login(fmt.Sprintf("unknown-%s@test.com", uuid.New()), testPassword)
+	s.Equal(http.StatusUnauthorized, unknownStatus)
+
+	for i := 1; i < s.cfg.App.LoginMaxAttempts; i++ {
+		status, errResp := login(email, "wrongpassword")
+		s.Equal(http.StatusUnauthorized, status)
+		s.Equal(unknownErr, errResp)
+	}
+
+	status, _ := login(email, "wrongpassword")
+	s.Equal(http.StatusTooManyRequests, status)
+
+	status, _ = login(email, testPassword)
+	s.Equal(http.StatusTooManyRequests, status)
+
+	// Parallel wrong passwords cannot be checked more times than the limit allows
+	burstEmail := fmt.Sprintf("lockout-%s@test.com", uuid.New())
+	_, err = s.dbPool.Exec(
+		context.Background(),
+		"INSERT INTO users (email, password_hash, role) VALUES ($1, $2, $3)",
+		burstEmail,
+		hashedPassword,
+		pvzapi.Employee,
+	)
+	s.Require().NoError(err)
+
+	body, err := json.Marshal(pvzapi.PostLoginJSONRequestBody{
+		Email:    openapi_types.Email(burstEmail),
+		Password: "wrongpassword",
+	})
+	s.Require().NoError(err)
+
+	statuses := make(chan int, 4*s.cfg.App.LoginMaxAttempts)
+	var wg sync.WaitGroup
+	for i := 0; i < cap(statuses); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			resp, err := http.Post(ts.URL+"/login", "application/json", bytes.NewReader(body))
+			if err != nil {
+				statuses <- 0
+				return
+			}
+			resp.Body.Close()
+			statuses <- resp.StatusCode
+		}()
+	}
+	wg.Wait()
+	close(statuses)
+
+	rejected := 0
+	for status := range statuses {
+		s.Contains([]int{http.StatusUnauthorized, http.StatusTooManyRequests}, status)
+		if status == http.StatusUnauthorized {
+			rejected++
+		}
+	}
+	s.Less(rejected, s.cfg.App.LoginMaxAttempts, "only attempts below the limit report invalid credentials")
+
+	status, _ = login(burstEmail, testPassword)
+	s.Equal(http.StatusTooManyRequests, status, "the burst locked the account")
+}
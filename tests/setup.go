@@ -15,9 +15,11 @@ import (
 	"github.com/golang-migrate/migrate/v4"
 	"github.com/golang-migrate/migrate/v4/database/postgres"
 	_ "github.com/golang-migrate/migrate/v4/source/file"
+	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5/pgxpool"
 	_ "github.com/jackc/pgx/v5/stdlib"
 	"github.com/joho/godotenv"
+	openapi_types "github.com/oapi-codegen/runtime/types"
 	"github.com/ory/dockertest/v3"
 	"github.com/ory/dockertest/v3/docker"
 	"github.com/stretchr/testify/suite"
@@ -144,3 +146,72 @@ func (s *BaseTestSuite) Login(ts *httptest.Server, role string) string {
 
 	return authResp.Value
 }
+
+// Register a new employee and log in, the employee is not assigned to any pvz
+func (s *BaseTestSuite) LoginEmployee(ts *httptest.Server) (string, uuid.UUID) {
+	email := fmt.Sprintf("employee-%s@example.com", uuid.New())
+	password := "password"
+
+	registerBody, err := json.Marshal(pvzapi.PostRegisterJSONBody{
+		Email:    openapi_types.Email(email),
+		Password: password,
+		Role:     pvzapi.Employee,
+	})
+	s.Require().NoError(err)
+
+	resp, err := http.Post(ts.URL+"/register", "application/json", bytes.NewReader(registerBody))
+	s.Require().NoError(err)
+	defer resp.Body.Close()
+
+	s.Require().Equal(http.StatusCreated, resp.StatusCode)
+
+	var user pvzapi.User
+	s.Require().NoError(json.NewDecoder(resp.Body).Decode(&user))
+	s.Require().NotNil(user.Id)
+
+	return s.loginUser(ts, email, password), *user.Id
+}
+
+// Register a new user, promote it to admin in the database and log in, admins cannot be created through the API
+func (s *BaseTestSuite) LoginAdmin(ts *httptest.Server) (string, uuid.UUID) {
+	_, userID := s.LoginEmployee(ts)
+
+	var email string
+	err := s.dbPool.QueryRow(context.Background(),
+		"UPDATE users SET role = 'admin' WHERE id = $1 RETURNING email",
+		userID).Scan(&email)
+	s.Require().NoError(err)
+
+	return s.loginUser(ts, email, "password"), userID
+}
+
+// Log in with the email and password, returns the access token
+func (s *BaseTestSuite) loginUser(ts *httptest.Server, email, password string) string {
+	loginBody, err := json.Marshal(pvzapi.PostLoginJSONBody{
+		Email:    openapi_types.Email(email),
+		Password: password,
+	})
+	s.Require().NoError(err)
+
+	resp, err := http.Post(ts.URL+"/login", "application/json", bytes.NewReader(loginBody))
+	s.Require().NoError(err)
+	defer resp.Body.Close()
+
+	s.Require().Equal(http.StatusOK, resp.StatusCode)
+
+	var authResp dtos.Token
+	s.Require().NoError(json.NewDecoder(resp.Body).Decode(&authResp))
+	s.Require().NotEmpty(authResp.Value)
+
+	return authResp.Value
+}
+
+// Assign the employee to the pvzs directly in the database
+func (s *BaseTestSuite) AssignEmployee(userID uuid.UUID, pvzIDs ...uuid.UUID) {
+	for _, pvzID := range pvzIDs {
+		_, err := s.dbPool.Exec(context.Background(),
+			"INSERT INTO employee_pvz (user_id, pvz_id) VALUES ($1, $2)",
+			userID, pvzID)
+		s.Require().NoError(err)
+	}
+}